@@ -0,0 +1,86 @@
+package optimize
+
+import (
+	"testing"
+)
+
+// raceEnabled is set by race_test.go: the race detector drops items of
+// sync.Pool at random, so that allocations can't be counted
+var raceEnabled bool
+
+// fit3 is the sum of squares of a 3-parameter exponential fit
+func fit3(x []float64) float64 {
+	s := 0.
+	for i := 0; i < 8; i++ {
+		t := float64(i) / 4
+		r := x[0]*(1-t*x[1]*(1-t*x[1]/2)) + x[2] - (2*(1-t*.5*(1-t*.25)) + 1)
+		s += r * r
+	}
+	return s
+}
+
+func TestZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted with the race detector")
+	}
+	quartic := func(x []float64) float64 {
+		s := 0.
+		for i, xi := range x {
+			s += float64(i+1)*(xi-1)*(xi-1) + xi*xi*xi*xi
+		}
+		return s
+	}
+	// the allocations of a run don't depend on its number of iterations
+	for dim := 1; dim <= 16; dim++ {
+		x0 := make([]float64, dim)
+		for i := range x0 {
+			x0[i] = float64(i%3) - 2
+		}
+		pm := NewPowellMinimizer()
+		pm.Xtol, pm.Ftol = 0, 0
+		allocs := func(iter int) float64 {
+			return testing.AllocsPerRun(10, func() {
+				pm.MaxIter, pm.MaxFev = iter, 0
+				pm.Minimize(quartic, x0)
+			})
+		}
+		if a1, a3 := allocs(1), allocs(3); a3 != a1 {
+			t.Errorf("dim %d: %g allocations for 1 iteration, %g for 3", dim, a1, a3)
+		}
+	}
+
+	g := func(x float64) float64 { return (x - 1) * (x - 1) }
+	zero := func(x float64) float64 { return x - 1 }
+	var bm BrentMinimizer
+	for name, fn := range map[string]func(){
+		"Brent":      func() { Brent(-3, 5, 1e-8, zero, nil) },
+		"Bissection": func() { Bissection(-3, 5, 1e-8, zero, nil) },
+		"Gss":        func() { Gss(g, -3, 5, 1e-8, nil) },
+		"BrentMinimizer": func() {
+			bm.init(g, 1e-8, 500, nil)
+			bm.Optimize()
+		},
+	} {
+		if a := testing.AllocsPerRun(10, fn); a != 0 {
+			t.Errorf("%s: %g allocations", name, a)
+		}
+	}
+}
+
+func BenchmarkPowellFit3(b *testing.B) {
+	b.ReportAllocs()
+	x0 := []float64{1, 1, 1}
+	pm := NewPowellMinimizer()
+	for i := 0; i < b.N; i++ {
+		pm.MaxIter, pm.MaxFev = 0, 0
+		pm.Minimize(fit3, x0)
+	}
+}
+
+func BenchmarkBrentMinimizer(b *testing.B) {
+	b.ReportAllocs()
+	g := func(x float64) float64 { return (x - 1) * (x - 1) }
+	for i := 0; i < b.N; i++ {
+		NewBrentMinimizer(g, 1e-8, 500, nil).Optimize()
+	}
+}
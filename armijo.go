@@ -0,0 +1,91 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+var _ LineSearcher = &Backtracking{}
+
+// Backtracking is the Armijo line search, contracting the step from the
+// initial one until the sufficient decrease
+//
+//	phi(alpha) <= phi(0) + C1 alpha phi'(0)
+//
+// where alpha phi'(0) is the Model of the LineProblem if set, eg the
+// projected gradient step of NewProjectedLineProblem for bound constrained
+// steps. It doesn't need Deriv.
+type Backtracking struct {
+	// C1 is the sufficient decrease constant in (0, 1), 1e-4 if 0
+	C1 float64
+	// Contraction is the factor in (0, 1) of the step after each failure of
+	// the condition, 0.5 if 0
+	Contraction float64
+	// MaxIter is the limit of evaluations of Func, 50 if 0
+	MaxIter int
+}
+
+// Search returns the first step of the contractions satisfying the
+// sufficient decrease condition
+func (bt *Backtracking) Search(p LineProblem, step float64) (LineSearchResult, error) {
+	ls := newLineSearch(p)
+	if !(p.Deriv0 < 0) {
+		return ls.best, errNotDescent
+	}
+	c1, rho, maxIter := bt.C1, bt.Contraction, bt.MaxIter
+	if c1 <= 0 {
+		c1 = 1e-4
+	}
+	if rho <= 0 || rho >= 1 {
+		rho = .5
+	}
+	if maxIter <= 0 {
+		maxIter = 50
+	}
+	for alpha := step; ls.best.NFev < maxIter; alpha *= rho {
+		if f := ls.f(alpha); f <= p.F0+c1*p.model(alpha) {
+			return ls.result(alpha, f, math.NaN()), nil
+		}
+	}
+	return ls.best, errors.New("optimize: backtracking: too many contractions")
+}
+
+// NewProjectedLineProblem returns the LineProblem of f along the projected
+// path P(x + alpha d) on the box, whose Model is the change
+// g.(P(x + alpha d) - x) predicted by the gradient g at x. grad is
+// evaluated at x only, a central difference if it is nil. Deriv is nil, so
+// that the problem is for Backtracking.
+func NewProjectedLineProblem(f func([]float64) float64, grad func(grad, x []float64), x, d []float64, box Box) LineProblem {
+	g := make([]float64, len(x))
+	if grad != nil {
+		grad(g, x)
+	} else {
+		copy(g, Gradient(f, x, &GradientOptions{Formula: FDCentral, Xmin: box.Xmin, Xmax: box.Xmax}))
+	}
+	// the slope of the first segment of the path, whose components
+	// leaving the box at x are 0
+	slope := 0.
+	for i, di := range d {
+		if (di < 0 && i < len(box.Xmin) && x[i] <= box.Xmin[i]) || (di > 0 && i < len(box.Xmax) && x[i] >= box.Xmax[i]) {
+			continue
+		}
+		slope += g[i] * di
+	}
+	xa := make([]float64, len(x))
+	point := func(alpha float64) []float64 {
+		floats.AddScaledTo(xa, x, alpha, d)
+		box.Project(xa)
+		return xa
+	}
+	return LineProblem{
+		Func:   func(alpha float64) float64 { return f(point(alpha)) },
+		F0:     f(x),
+		Deriv0: slope,
+		Model: func(alpha float64) float64 {
+			floats.Sub(point(alpha), x)
+			return floats.Dot(g, xa)
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleBacktracking() {
+	// a projected gradient step on the Rosenbrock function under x <= 0.5
+	x := []float64{0.4, 0}
+	d := make([]float64, 2)
+	rosenGrad(d, x)
+	for i := range d {
+		d[i] = -d[i]
+	}
+	box := Box{Xmax: []float64{.5, .5}}
+	p := NewProjectedLineProblem(rosen, rosenGrad, x, d, box)
+	r, err := (&Backtracking{}).Search(p, 1)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("step %.4g f %.4f -> %.4f\n", r.Step, p.F0, r.F)
+	// Output:
+	// step 0.003906 f 2.9200 -> 0.5869
+}
+
+func TestBacktracking(t *testing.T) {
+	quadratic := func(x []float64) float64 { return (x[0] - 3) * (x[0] - 3) }
+	quadraticGrad := func(g, x []float64) { g[0] = 2 * (x[0] - 3) }
+	p := NewLineProblem(quadratic, nil, []float64{0}, []float64{1})
+	// phi(alpha) = (alpha-3)^2 <= 9 - 6 C1 alpha for alpha <= 6(1-C1)
+	for _, c := range []struct {
+		bt   Backtracking
+		step float64
+		want float64
+	}{
+		{Backtracking{}, 1, 1},
+		{Backtracking{}, 100, 100. / 32},
+		{Backtracking{Contraction: .1}, 100, 1},
+		{Backtracking{C1: .5, Contraction: .9}, 10, 10 * math.Pow(.9, 12)},
+	} {
+		r, err := c.bt.Search(p, c.step)
+		if err != nil || math.Abs(r.Step-c.want) > 1e-12 || r.F != quadratic([]float64{r.Step}) || !math.IsNaN(r.Deriv) {
+			t.Errorf("%+v from %g: got %+v %v, want step %g", c.bt, c.step, r, err, c.want)
+		}
+	}
+	if _, err := (&Backtracking{MaxIter: 3}).Search(p, 1e6); err == nil {
+		t.Error("expected an error after MaxIter contractions")
+	}
+	if _, err := (&Backtracking{}).Search(NewLineProblem(quadratic, nil, []float64{0}, []float64{-1}), 1); err != errNotDescent {
+		t.Errorf("ascent direction: got %v", err)
+	}
+
+	// the projected path stops at the bound 1, where the step 4 is accepted
+	// though (4-3)^2 > 9 - 6 C1 4 for the unprojected model
+	box := Box{Xmax: []float64{1}}
+	p = NewProjectedLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{1}, box)
+	r, err := (&Backtracking{C1: .5}).Search(p, 4)
+	if err != nil || r.Step != 4 || r.F != 4 {
+		t.Errorf("projected: got %+v %v", r, err)
+	}
+	// at the bound, the direction leaving the box is not a descent direction
+	p = NewProjectedLineProblem(quadratic, nil, []float64{1}, []float64{1}, box)
+	if _, err := (&Backtracking{}).Search(p, 1); err != errNotDescent {
+		t.Errorf("direction leaving the box: got %v", err)
+	}
+}
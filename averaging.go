@@ -0,0 +1,58 @@
+package optimize
+
+// AveragingMethod selects how iterates are averaged
+type AveragingMethod int
+
+const (
+	// PolyakAveraging is the arithmetic mean of iterates (Polyak-Ruppert)
+	PolyakAveraging AveragingMethod = iota
+	// EMAAveraging is the exponential moving average of iterates
+	EMAAveraging
+)
+
+// Averaging averages the iterates of stochastic methods, which usually
+// reduces the variance of the returned solution.
+type Averaging struct {
+	Method AveragingMethod
+	// Start is the number of iterations ignored before averaging begins
+	Start int
+	// Decay is the EMA factor: avg = Decay*avg + (1-Decay)*x. defaults to 0.99
+	Decay float64
+	mean  []float64
+	n     int
+}
+
+func (av *Averaging) init(dim int) {
+	av.mean = resize(av.mean, dim)
+	av.n = 0
+}
+
+// add adds iterate x obtained at iteration iter (1 based)
+func (av *Averaging) add(iter int, x []float64) {
+	if iter <= av.Start {
+		return
+	}
+	av.n++
+	if av.n == 1 {
+		copy(av.mean, x)
+		return
+	}
+	w := 1 / float64(av.n)
+	if av.Method == EMAAveraging {
+		w = 1 - av.Decay
+		if av.Decay == 0 {
+			w = .01
+		}
+	}
+	for i, xi := range x {
+		av.mean[i] += w * (xi - av.mean[i])
+	}
+}
+
+// X returns a copy of the averaged iterate, or nil if nothing was averaged
+func (av *Averaging) X() []float64 {
+	if av.n == 0 {
+		return nil
+	}
+	return append([]float64(nil), av.mean...)
+}
@@ -0,0 +1,39 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+)
+
+func ExampleAveraging() {
+	sg := &StochasticGradient{
+		Method:       &SGD{},
+		LearningRate: ConstantRate(.02),
+		MaxIter:      2000,
+		Averaging:    &Averaging{Method: PolyakAveraging, Start: 1000},
+	}
+	res := sg.Minimize(noisyQuadratic{rand.New(rand.NewSource(1))}, []float64{0, 0})
+	fmt.Printf("raw:%.3f averaged:%.3f\n", res.X, res.Extra.(*Averaged).X)
+	// Output:
+	// raw:[0.993 -1.996] averaged:[0.998 -2.000]
+}
+
+func TestAveragingSPSA(t *testing.T) {
+	noise := rand.New(rand.NewSource(3))
+	f := func(x []float64) float64 {
+		return (x[0]-1)*(x[0]-1) + (x[1]-2)*(x[1]-2) + .1*noise.NormFloat64()
+	}
+	opt := []float64{1, 2}
+	for _, av := range []*Averaging{{Method: PolyakAveraging, Start: 500}, {Method: EMAAveraging, Decay: .995}} {
+		sp := NewSPSA()
+		sp.Src = rand.NewSource(1)
+		sp.Averaging = av
+		avg, ok := sp.Minimize(f, []float64{0, 0}).Extra.(*Averaged)
+		if !ok || floats.Distance(avg.X, opt, 2) > .05 {
+			t.Errorf("averaging %d: bad averaged solution %v", av.Method, avg)
+		}
+	}
+}
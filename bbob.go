@@ -0,0 +1,215 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// BBOBFunctions are the functions of the noiseless BBOB suite implemented
+// by BBOBProblem: separable sphere, ellipsoid, Rastrigin and linear slope,
+// Rosenbrock, and the rotated ellipsoid, discus, bent cigar, different
+// powers and Rastrigin.
+var BBOBFunctions = []int{1, 2, 3, 5, 8, 10, 11, 12, 14, 15}
+
+// BBOBProblem is an instance of a function of the noiseless BBOB suite of
+// COCO, with the transformations of its definition: an optimum XOpt in
+// [-4,4]^Dim of value FOpt, rotations, and the oscillation and asymmetry
+// transformations. The instances are drawn from a seed of the function, the
+// instance and the dimension: they are instances in the sense of BBOB, but
+// not the very ones of the C code of COCO, so the results are comparable
+// to the published ones statistically only.
+type BBOBProblem struct {
+	Function, Instance, Dim int
+	XOpt                    []float64
+	FOpt                    float64
+
+	r, q [][]float64
+}
+
+// NewBBOBProblem returns the instance of function, one of BBOBFunctions,
+// in dimension dim >= 2
+func NewBBOBProblem(function, instance, dim int) (*BBOBProblem, error) {
+	known := false
+	for _, f := range BBOBFunctions {
+		known = known || f == function
+	}
+	if !known {
+		return nil, fmt.Errorf("optimize: BBOB function %d is not implemented", function)
+	}
+	if dim < 2 {
+		return nil, errors.New("optimize: BBOB dimension must be at least 2")
+	}
+	rnd := rand.New(rand.NewSource(uint64(1000000*function + 1000*instance + dim)))
+	p := &BBOBProblem{Function: function, Instance: instance, Dim: dim, XOpt: make([]float64, dim)}
+	for i := range p.XOpt {
+		p.XOpt[i] = math.Round(1e4*(8*rnd.Float64()-4)) / 1e4
+		switch function {
+		case 5:
+			p.XOpt[i] = 5
+			if rnd.Float64() < .5 {
+				p.XOpt[i] = -5
+			}
+		case 8:
+			p.XOpt[i] *= .75
+		}
+	}
+	p.FOpt = math.Round(100*math.Max(-1000, math.Min(1000, 100*rnd.NormFloat64()/math.Abs(rnd.NormFloat64())))) / 100
+	p.r, p.q = randomRotation(dim, rnd), randomRotation(dim, rnd)
+	return p, nil
+}
+
+// Name returns the COCO identifier of the problem, eg bbob_f001_i01_d02
+func (p *BBOBProblem) Name() string {
+	return fmt.Sprintf("bbob_f%03d_i%02d_d%02d", p.Function, p.Instance, p.Dim)
+}
+
+// Func returns the value of x
+func (p *BBOBProblem) Func(x []float64) float64 {
+	d := p.Dim
+	z := make([]float64, d)
+	for i := range z {
+		z[i] = x[i] - p.XOpt[i]
+	}
+	// ratio returns (i-1)/(D-1) of the definitions, for index i
+	ratio := func(i int) float64 { return float64(i) / float64(d-1) }
+	ellipsoid := func(z []float64) float64 {
+		s := 0.
+		for i, zi := range z {
+			s += math.Pow(1e6, ratio(i)) * zi * zi
+		}
+		return s
+	}
+	rastrigin := func(z []float64) float64 {
+		s := 10 * float64(d)
+		for _, zi := range z {
+			s += zi*zi - 10*math.Cos(2*math.Pi*zi)
+		}
+		return s
+	}
+	lambda := func(z []float64, alpha float64) []float64 {
+		for i := range z {
+			z[i] *= math.Pow(alpha, ratio(i)/2)
+		}
+		return z
+	}
+	var f float64
+	switch p.Function {
+	case 1:
+		for _, zi := range z {
+			f += zi * zi
+		}
+	case 2:
+		f = ellipsoid(tosz(z))
+	case 3:
+		f = rastrigin(lambda(tasy(tosz(z), .2), 10))
+	case 5:
+		for i, xi := range x {
+			s := math.Copysign(math.Pow(10, ratio(i)), p.XOpt[i])
+			zi := xi
+			if xi*p.XOpt[i] >= 25 {
+				zi = p.XOpt[i]
+			}
+			f += 5*math.Abs(s) - s*zi
+		}
+	case 8:
+		c := math.Max(1, math.Sqrt(float64(d))/8)
+		for i := range z {
+			z[i] = c*z[i] + 1
+		}
+		for i := 0; i < d-1; i++ {
+			f += 100*(z[i]*z[i]-z[i+1])*(z[i]*z[i]-z[i+1]) + (z[i]-1)*(z[i]-1)
+		}
+	case 10:
+		f = ellipsoid(tosz(rotate(p.r, z)))
+	case 11:
+		z = tosz(rotate(p.r, z))
+		f = 1e6 * z[0] * z[0]
+		for _, zi := range z[1:] {
+			f += zi * zi
+		}
+	case 12:
+		z = rotate(p.r, tasy(rotate(p.r, z), .5))
+		f = z[0] * z[0]
+		for _, zi := range z[1:] {
+			f += 1e6 * zi * zi
+		}
+	case 14:
+		for i, zi := range rotate(p.r, z) {
+			f += math.Pow(math.Abs(zi), 2+4*ratio(i))
+		}
+		f = math.Sqrt(f)
+	case 15:
+		f = rastrigin(rotate(p.r, lambda(rotate(p.q, tasy(tosz(rotate(p.r, z)), .2)), 10)))
+	}
+	return f + p.FOpt
+}
+
+// tosz is the oscillation transformation of BBOB, in place
+func tosz(x []float64) []float64 {
+	for i, xi := range x {
+		if xi == 0 {
+			continue
+		}
+		c1, c2 := 5.5, 3.1
+		if xi > 0 {
+			c1, c2 = 10, 7.9
+		}
+		h := math.Log(math.Abs(xi))
+		x[i] = math.Copysign(math.Exp(h+.049*(math.Sin(c1*h)+math.Sin(c2*h))), xi)
+	}
+	return x
+}
+
+// tasy is the asymmetry transformation of BBOB of parameter beta, in place
+func tasy(x []float64, beta float64) []float64 {
+	for i, xi := range x {
+		if xi > 0 {
+			x[i] = math.Pow(xi, 1+beta*float64(i)/float64(len(x)-1)*math.Sqrt(xi))
+		}
+	}
+	return x
+}
+
+// rotate returns the product of the rows of r by x
+func rotate(r [][]float64, x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i, ri := range r {
+		for j, rij := range ri {
+			y[i] += rij * x[j]
+		}
+	}
+	return y
+}
+
+// randomRotation returns the rows of an orthogonal matrix, the
+// orthonormalized rows of a normal random matrix
+func randomRotation(n int, rnd *rand.Rand) [][]float64 {
+	r := make([][]float64, n)
+	for i := range r {
+		r[i] = make([]float64, n)
+		for j := range r[i] {
+			r[i][j] = rnd.NormFloat64()
+		}
+		for _, rk := range r[:i] {
+			dot := 0.
+			for j := range rk {
+				dot += rk[j] * r[i][j]
+			}
+			for j := range rk {
+				r[i][j] -= dot * rk[j]
+			}
+		}
+		norm := 0.
+		for _, v := range r[i] {
+			norm += v * v
+		}
+		norm = math.Sqrt(norm)
+		for j := range r[i] {
+			r[i][j] /= norm
+		}
+	}
+	return r
+}
@@ -0,0 +1,47 @@
+package optimize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBBOBProblem(t *testing.T) {
+	for _, fn := range BBOBFunctions {
+		for _, dim := range []int{2, 5} {
+			p, err := NewBBOBProblem(fn, 1, dim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f := p.Func(p.XOpt); math.Abs(f-p.FOpt) > 1e-12 {
+				t.Errorf("%s: f(XOpt)=%g, expected %g", p.Name(), f, p.FOpt)
+			}
+			// XOpt is a minimum along the coordinates
+			x := append([]float64(nil), p.XOpt...)
+			for i := range x {
+				for _, h := range []float64{-1e-3, 1e-3, -.5, .5} {
+					x[i] = p.XOpt[i] + h
+					if fn == 5 && x[i]*p.XOpt[i] >= 25 {
+						continue
+					}
+					if f := p.Func(x); !(f > p.FOpt) {
+						t.Errorf("%s: f(%v)=%g <= FOpt=%g", p.Name(), x, f, p.FOpt)
+					}
+				}
+				x[i] = p.XOpt[i]
+			}
+			q, _ := NewBBOBProblem(fn, 2, dim)
+			if q.FOpt == p.FOpt || q.XOpt[0] == p.XOpt[0] && fn != 5 {
+				t.Errorf("%s: instances 1 and 2 are identical", p.Name())
+			}
+		}
+	}
+	if p, _ := NewBBOBProblem(1, 3, 2); p.Name() != "bbob_f001_i03_d02" {
+		t.Errorf("unexpected name %s", p.Name())
+	}
+	if _, err := NewBBOBProblem(4, 1, 2); err == nil {
+		t.Error("expected f4 to be missing")
+	}
+	if _, err := NewBBOBProblem(1, 1, 1); err == nil {
+		t.Error("expected an error in dimension 1")
+	}
+}
@@ -0,0 +1,175 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Bilevel minimizes over u the outer objective F(u, y*(u)), y*(u) being the
+// minimizer of the inner objective g(u, y), eg the calibration of the
+// hyperparameters u of an inner calibration. Each inner solve starts from
+// the previous inner solution. It is not safe for concurrent use.
+type Bilevel struct {
+	// Outer is F(u, y) and Inner is g(u, y)
+	Outer, Inner func(u, y []float64) float64
+	// Y0 is the starting point of the first inner solve
+	Y0 []float64
+	// InnerMinimizer solves the inner problem to tolerance, gonum BFGS with
+	// finite difference gradients if nil
+	InnerMinimizer Minimizer
+	// Options are the options of Minimize for the outer problem, whose
+	// Method defaults to "bfgs" with the gradient of Hypergradient
+	Options Options
+	// GradTol stops a gradient method of the outer problem with Status
+	// GradientThreshold when the norm of the hypergradient, approximate
+	// by the errors of the inner solutions, is at most GradTol, 1e-6 if 0
+	GradTol float64
+	// Implicit selects the implicit function hypergradient
+	// F_u - g_uy g_yy^-1 F_y, from finite differences at y*(u) only,
+	// instead of finite differences of the outer value, each one solving
+	// the inner problem. It needs a smooth inner problem with a positive
+	// definite g_yy at its solution.
+	Implicit bool
+	// Gradient are the settings of the finite differences of Hypergradient,
+	// by default central differences of relative step 1e-4, larger than the
+	// errors of the inner solutions
+	Gradient *GradientOptions
+
+	y             []float64
+	lastU         string
+	solves, nfev  int
+	innerFailures int
+	gradNorm      float64
+	defaultInner  Minimizer
+}
+
+// BilevelInfo is the Extra of the Result of Bilevel.Minimize
+type BilevelInfo struct {
+	// Y is the inner solution at the solution u
+	Y []float64
+	// InnerSolves is the number of inner solves, InnerNFev their evaluations
+	// of g, and InnerFailures the number of solves with Status Failure
+	InnerSolves, InnerNFev, InnerFailures int
+}
+
+// Solve returns the inner solution y*(u)
+func (b *Bilevel) Solve(u []float64) []float64 {
+	key := floatsKey(u)
+	if b.y != nil && key == b.lastU {
+		return b.y
+	}
+	y0 := b.y
+	if y0 == nil {
+		y0 = b.Y0
+	}
+	inner := b.InnerMinimizer
+	if inner == nil {
+		if b.defaultInner == nil {
+			b.defaultInner = &MethodMinimizer{Method: &optimize.BFGS{}}
+		}
+		inner = b.defaultInner
+	}
+	uk := append([]float64(nil), u...)
+	res := inner.Minimize(func(y []float64) float64 { return b.Inner(uk, y) }, append([]float64(nil), y0...))
+	b.solves++
+	b.nfev += res.NFev
+	if res.Status == optimize.Failure {
+		b.innerFailures++
+	}
+	b.y, b.lastU = res.X, key
+	return b.y
+}
+
+// Value returns the outer value F(u, y*(u))
+func (b *Bilevel) Value(u []float64) float64 {
+	return b.Outer(u, b.Solve(u))
+}
+
+// Hypergradient stores in grad the gradient of the outer value at u
+func (b *Bilevel) Hypergradient(grad, u []float64) {
+	fd := b.Gradient
+	if fd == nil {
+		fd = &GradientOptions{Formula: FDCentral, RelStep: 1e-4}
+	}
+	defer func() { b.gradNorm = floats.Norm(grad, 2) }()
+	if !b.Implicit {
+		copy(grad, Gradient(b.Value, u, fd))
+		return
+	}
+	y := append([]float64(nil), b.Solve(u)...)
+	u = append([]float64(nil), u...)
+	fu := Gradient(func(u []float64) float64 { return b.Outer(u, y) }, u, fd)
+	fy := Gradient(func(y []float64) float64 { return b.Outer(u, y) }, y, fd)
+	var chol mat.Cholesky
+	if !chol.Factorize(Hessian(func(y []float64) float64 { return b.Inner(u, y) }, y, nil)) {
+		// g_yy is not positive definite at y: differences of the value
+		copy(grad, Gradient(b.Value, u, fd))
+		return
+	}
+	var v mat.VecDense
+	if err := chol.SolveVecTo(&v, mat.NewVecDense(len(fy), fy)); err != nil {
+		copy(grad, Gradient(b.Value, u, fd))
+		return
+	}
+	// g_uy v is the derivative along v of the gradient of g in u
+	eps := 1e-4 * math.Max(1, floats.Norm(y, 2)) / math.Max(mat.Norm(&v, 2), 1e-300)
+	yp, ym := make([]float64, len(y)), make([]float64, len(y))
+	for i := range y {
+		yp[i], ym[i] = y[i]+eps*v.AtVec(i), y[i]-eps*v.AtVec(i)
+	}
+	gp := Gradient(func(u []float64) float64 { return b.Inner(u, yp) }, u, fd)
+	gm := Gradient(func(u []float64) float64 { return b.Inner(u, ym) }, u, fd)
+	for i := range grad {
+		grad[i] = fu[i] - (gp[i]-gm[i])/(2*eps)
+	}
+}
+
+// Minimize minimizes the outer value from u0. The Result counts the
+// evaluations of the outer value, and its Extra is a *BilevelInfo.
+func (b *Bilevel) Minimize(u0 []float64) (*Result, error) {
+	if b.Outer == nil || b.Inner == nil {
+		return nil, errors.New("optimize: Bilevel without Outer or Inner")
+	}
+	if len(b.Y0) == 0 {
+		return nil, errors.New("optimize: Bilevel without Y0")
+	}
+	b.y, b.lastU, b.solves, b.nfev, b.innerFailures, b.gradNorm = nil, "", 0, 0, 0, math.Inf(1)
+	opts := b.Options
+	if opts.Method == "" && opts.GonumMethod == nil {
+		opts.Method = "bfgs"
+	}
+	gradTol := b.GradTol
+	if gradTol <= 0 {
+		gradTol = 1e-6
+	}
+	var stop StopCondition = hypergradientStop{b: b, tol: gradTol}
+	if opts.Stop != nil {
+		stop = Or(opts.Stop, stop)
+	}
+	opts.Stop = stop
+	res, err := Minimize(optimize.Problem{Func: b.Value, Grad: b.Hypergradient}, u0, &opts)
+	if err != nil {
+		return nil, err
+	}
+	res.Extra = &BilevelInfo{Y: append([]float64(nil), b.Solve(res.X)...), InnerSolves: b.solves, InnerNFev: b.nfev, InnerFailures: b.innerFailures}
+	return res, nil
+}
+
+// hypergradientStop stops when the last hypergradient of b is below tol
+type hypergradientStop struct {
+	b   *Bilevel
+	tol float64
+}
+
+func (hs hypergradientStop) Init() {}
+
+func (hs hypergradientStop) Stop(it *Iteration) optimize.Status {
+	if hs.b.gradNorm <= hs.tol {
+		return optimize.GradientThreshold
+	}
+	return optimize.NotTerminated
+}
@@ -0,0 +1,70 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleBilevel() {
+	// the strength exp(u) of a ridge regression fitted on a training set is
+	// chosen by the error on a validation set
+	train := [][2]float64{{1, 2.3}, {2, 3.9}, {3, 6.4}, {4, 7.7}}
+	validation := [][2]float64{{1.5, 2.9}, {2.5, 4.8}, {3.5, 6.6}}
+	sse := func(data [][2]float64, w []float64) float64 {
+		s := 0.
+		for _, d := range data {
+			r := w[0]*d[0] - d[1]
+			s += r * r
+		}
+		return s
+	}
+	b := &Bilevel{
+		Inner: func(u, w []float64) float64 { return sse(train, w) + math.Exp(u[0])*w[0]*w[0] },
+		Outer: func(u, w []float64) float64 { return sse(validation, w) },
+		Y0:    []float64{0},
+	}
+	res, err := b.Minimize([]float64{0})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s u=%.3f w=%.4f validation error %.4f\n", res.Status, res.X, res.Extra.(*BilevelInfo).Y, res.F)
+	// Output:
+	// GradientThreshold u=[0.500] w=[1.8990] validation error 0.0076
+}
+
+func TestBilevel(t *testing.T) {
+	// y*(u) = u/2 and the outer value (u/2-1)^2 + u^2/4 is minimum at u=1
+	newBilevel := func(implicit bool) *Bilevel {
+		return &Bilevel{
+			Inner:    func(u, y []float64) float64 { return (y[0]-u[0])*(y[0]-u[0]) + y[0]*y[0] },
+			Outer:    func(u, y []float64) float64 { return (y[0]-1)*(y[0]-1) + u[0]*u[0]/4 },
+			Y0:       []float64{0},
+			Implicit: implicit,
+		}
+	}
+	solves := map[bool]int{}
+	for _, implicit := range []bool{false, true} {
+		b := newBilevel(implicit)
+		grad := make([]float64, 1)
+		b.Hypergradient(grad, []float64{3})
+		if math.Abs(grad[0]-2) > 1e-5 {
+			t.Errorf("implicit %v: hypergradient %g, want 2", implicit, grad[0])
+		}
+		res, err := b.Minimize([]float64{3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := res.Extra.(*BilevelInfo)
+		if math.Abs(res.X[0]-1) > 1e-5 || math.Abs(info.Y[0]-.5) > 1e-5 || math.Abs(res.F-.5) > 1e-9 || info.InnerSolves == 0 || info.InnerFailures != 0 {
+			t.Errorf("implicit %v: got %v %+v", implicit, res, info)
+		}
+		solves[implicit] = info.InnerSolves
+	}
+	if solves[true] >= solves[false] {
+		t.Errorf("the implicit hypergradient should need less inner solves: %v", solves)
+	}
+	if _, err := (&Bilevel{}).Minimize([]float64{0}); err == nil {
+		t.Error("expected an error without objectives")
+	}
+}
@@ -0,0 +1,186 @@
+package optimize
+
+import (
+	"container/heap"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// BoundedMinimizeFunc minimizes fun over the box xmin <= x <= xmax from x0 and
+// returns the solution and its value
+type BoundedMinimizeFunc func(fun func([]float64) float64, x0, xmin, xmax []float64) ([]float64, float64)
+
+// ProjectedPowellMinimize is a BoundedMinimizeFunc running PowellMinimize on
+// f(P(x)) + |x-P(x)|^2, P being the projection on the box, and returning P(x)
+func ProjectedPowellMinimize(fun func([]float64) float64, x0, xmin, xmax []float64) ([]float64, float64) {
+	box := Box{Xmin: xmin, Xmax: xmax}
+	px := make([]float64, len(x0))
+	projected := func(x []float64) float64 {
+		copy(px, x)
+		box.Project(px)
+		d := 0.
+		for i := range x {
+			d += (x[i] - px[i]) * (x[i] - px[i])
+		}
+		return fun(px) + d
+	}
+	start := append([]float64(nil), x0...)
+	box.Project(start)
+	x := PowellMinimize(projected, start)
+	box.Project(x)
+	return x, fun(x)
+}
+
+// BranchAndBound minimizes a mixed-integer problem: it solves continuous
+// relaxations over boxes with Solve, and branches on the most fractional
+// integer variable, exploring the node of lowest relaxed value first.
+// The relaxed values are lower bounds only for convex problems, otherwise
+// it is a heuristic.
+type BranchAndBound struct {
+	Func func([]float64) float64
+	// Variables are the types of the dimensions. Categorical variables are
+	// relaxed as integers in 0..Levels-1.
+	Variables  Variables
+	Xmin, Xmax []float64
+	// Solve defaults to ProjectedPowellMinimize
+	Solve BoundedMinimizeFunc
+	// IntTol is the integrality tolerance. defaults to 1e-6
+	IntTol float64
+	// Gap and AbsGap are the relative and absolute gaps between the incumbent
+	// and the best bound of open nodes at which the search stops.
+	// Gap defaults to 1e-4
+	Gap, AbsGap float64
+	// MaxNodes defaults to 1000
+	MaxNodes int
+}
+
+// BranchAndBoundInfo is the Extra of the Result of BranchAndBound.
+// LowerBound is the best bound of the remaining nodes and Nodes the number
+// of solved relaxations.
+type BranchAndBoundInfo struct {
+	LowerBound float64
+	Nodes      int
+}
+
+type bnbNode struct {
+	xmin, xmax []float64
+	x          []float64
+	f          float64
+}
+
+type bnbQueue []*bnbNode
+
+func (q bnbQueue) Len() int            { return len(q) }
+func (q bnbQueue) Less(i, j int) bool  { return q[i].f < q[j].f }
+func (q bnbQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *bnbQueue) Push(x interface{}) { *q = append(*q, x.(*bnbNode)) }
+func (q *bnbQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// Minimize runs the search from x0 and returns the best integer-feasible
+// solution found. Status is Failure, X nil and F +Inf if none was found.
+// NIter is the number of solved relaxations.
+func (bb *BranchAndBound) Minimize(x0 []float64) *Result {
+	start := time.Now()
+	n := len(x0)
+	solve, tol, gap, maxNodes := bb.Solve, bb.IntTol, bb.Gap, bb.MaxNodes
+	if solve == nil {
+		solve = ProjectedPowellMinimize
+	}
+	if tol <= 0 {
+		tol = 1e-6
+	}
+	if gap <= 0 {
+		gap = 1e-4
+	}
+	if maxNodes <= 0 {
+		maxNodes = 1000
+	}
+	xmin, xmax := make([]float64, n), make([]float64, n)
+	for i := range xmin {
+		xmin[i], xmax[i] = math.Inf(-1), math.Inf(1)
+		if i < len(bb.Xmin) {
+			xmin[i] = bb.Xmin[i]
+		}
+		if i < len(bb.Xmax) {
+			xmax[i] = bb.Xmax[i]
+		}
+		if v := bb.Variables.kind(i); v.Kind == Categorical {
+			xmin[i], xmax[i] = math.Max(xmin[i], 0), math.Min(xmax[i], float64(v.Levels-1))
+		}
+	}
+	res := &Result{F: math.Inf(1), Status: optimize.MethodConverge}
+	nfev := 0
+	var timer evalTimer
+	f := timer.wrap(bb.Func)
+	fun := func(x []float64) float64 {
+		nfev++
+		return f(x)
+	}
+	q := &bnbQueue{}
+	push := func(xmin, xmax, start []float64) {
+		for i := range xmin {
+			if xmin[i] > xmax[i] {
+				return
+			}
+		}
+		x, f := solve(fun, start, xmin, xmax)
+		res.NIter++
+		if f == f && f < res.F {
+			heap.Push(q, &bnbNode{xmin: xmin, xmax: xmax, x: x, f: f})
+		}
+	}
+	push(xmin, xmax, x0)
+	for q.Len() > 0 {
+		if res.NIter >= maxNodes {
+			res.Status = optimize.IterationLimit
+			break
+		}
+		node := heap.Pop(q).(*bnbNode)
+		if node.f >= res.F-math.Max(bb.AbsGap, gap*math.Abs(res.F)) {
+			// best-first: no remaining node can improve enough
+			heap.Push(q, node)
+			break
+		}
+		// branch on the most fractional variable
+		branch, frac := -1, tol
+		for i, xi := range node.x {
+			if bb.Variables.kind(i).Kind == Continuous {
+				continue
+			}
+			if d := math.Abs(xi - math.Round(xi)); d > frac {
+				branch, frac = i, d
+			}
+		}
+		if branch < 0 {
+			x := append([]float64(nil), node.x...)
+			bb.Variables.Project(x)
+			if f := fun(x); f < res.F {
+				res.X, res.F = x, f
+			}
+			continue
+		}
+		lo := append([]float64(nil), node.xmax...)
+		lo[branch] = math.Floor(node.x[branch])
+		push(node.xmin, lo, node.x)
+		hi := append([]float64(nil), node.xmin...)
+		hi[branch] = math.Ceil(node.x[branch])
+		push(hi, node.xmax, node.x)
+	}
+	info := &BranchAndBoundInfo{LowerBound: res.F, Nodes: res.NIter}
+	for _, node := range *q {
+		info.LowerBound = math.Min(info.LowerBound, node.f)
+	}
+	if res.X == nil {
+		res.Status, res.Message = optimize.Failure, "no integer-feasible solution"
+	}
+	res.NFev, res.Extra = nfev, info
+	res.Evals.FuncTime = timer.elapsed()
+	return res.done(start)
+}
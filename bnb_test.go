@@ -0,0 +1,50 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleBranchAndBound() {
+	bb := &BranchAndBound{
+		Func: func(x []float64) float64 {
+			return (x[0]-2.4)*(x[0]-2.4) + (x[1]-1.6)*(x[1]-1.6) + (x[2]-.5)*(x[2]-.5) + .5*x[0]*x[1]
+		},
+		Variables: Variables{{Kind: Integer}, {Kind: Integer}},
+		Xmin:      []float64{-5, -5, -5},
+		Xmax:      []float64{5, 5, 5},
+	}
+	res := bb.Minimize([]float64{0, 0, 0})
+	fmt.Printf("%.3f %.3f\n", res.X, res.F)
+	// Output:
+	// [2.000 1.000 0.500] 1.520
+}
+
+func TestBranchAndBound(t *testing.T) {
+	// convex quadratic with coupling: compare with enumeration
+	f := func(x []float64) float64 {
+		return (x[0]-1.3)*(x[0]-1.3) + 2*(x[1]+0.7)*(x[1]+0.7) + (x[0]-x[1])*(x[0]-x[1])/3 + (x[2]-x[0]/2)*(x[2]-x[0]/2)
+	}
+	best := math.Inf(1)
+	for i := -3.; i <= 3; i++ {
+		for j := -3.; j <= 3; j++ {
+			best = math.Min(best, f([]float64{i, j, i / 2}))
+		}
+	}
+	bb := &BranchAndBound{Func: f, Variables: Variables{{Kind: Integer}, {Kind: Integer}}, Xmin: []float64{-3, -3}, Xmax: []float64{3, 3}}
+	res := bb.Minimize([]float64{0, 0, 0})
+	if math.Abs(res.F-best) > 1e-6 || res.Status != optimize.MethodConverge {
+		t.Errorf("expected %g got %g %s", best, res.F, res.Status)
+	}
+	if info := res.Extra.(*BranchAndBoundInfo); info.LowerBound > res.F+1e-9 || info.Nodes < 3 || info.Nodes != res.NIter {
+		t.Errorf("bad bound %g or nodes %d", info.LowerBound, info.Nodes)
+	}
+	// infeasible: integer variable in (0.2, 0.8)
+	bb = &BranchAndBound{Func: f, Variables: Variables{{Kind: Integer}}, Xmin: []float64{.2}, Xmax: []float64{.8}}
+	if res := bb.Minimize([]float64{.5, 0, 0}); res.X != nil || !math.IsInf(res.F, 1) || res.Status != optimize.Failure {
+		t.Errorf("expected no solution, got %v %g %s", res.X, res.F, res.Status)
+	}
+}
@@ -1,7 +1,10 @@
 package optimize
 
 import (
+	"errors"
 	"math"
+
+	"gonum.org/v1/gonum/optimize"
 )
 
 type bracketer struct {
@@ -14,8 +17,9 @@ type bracketer struct {
 // downhill direction (as defined by the initital points) and return
 // new points xa, xb, xc that bracket the minimum of the function
 // f(xa) > f(xb) < f(xc). It doesn't always mean that obtained
-// solution will satisfy xa<=x<=xb
-func (b bracketer) bracket(f func(float64) float64, xa0, xb0 float64) (xa, xb, xc, fa, fb, fc float64, funcalls int) {
+// solution will satisfy xa<=x<=xb. An error is returned after maxIter
+// steps without bracketing, eg if f is unbounded below.
+func (b bracketer) bracket(f func(float64) float64, xa0, xb0 float64) (xa, xb, xc, fa, fb, fc float64, funcalls int, err error) {
 	var (
 		tmp1, tmp2, val, denom, w, wlim, fw float64
 		iter                                int
@@ -44,7 +48,8 @@ func (b bracketer) bracket(f func(float64) float64, xa0, xb0 float64) (xa, xb, x
 		w = xb - ((xb-xc)*tmp2-(xb-xa)*tmp1)/denom
 		wlim = xb + b.growLimit*(xc-xb)
 		if iter > b.maxIter {
-			panic("bracket: Too many iterations.")
+			err = errors.New("optimize: bracket: too many iterations")
+			return
 		}
 		iter++
 		if (w-xc)*(xb-w) > 0.0 {
@@ -55,11 +60,11 @@ func (b bracketer) bracket(f func(float64) float64, xa0, xb0 float64) (xa, xb, x
 				xb = w
 				fa = fb
 				fb = fw
-				return xa, xb, xc, fa, fb, fc, funcalls
+				return xa, xb, xc, fa, fb, fc, funcalls, nil
 			} else if fw > fb {
 				xc = w
 				fc = fw
-				return xa, xb, xc, fa, fb, fc, funcalls
+				return xa, xb, xc, fa, fb, fc, funcalls, nil
 			}
 			w = xc + _gold*(xc-xb)
 			fw = f(w)
@@ -92,7 +97,7 @@ func (b bracketer) bracket(f func(float64) float64, xa0, xb0 float64) (xa, xb, x
 		fb = fc
 		fc = fw
 	}
-	return xa, xb, xc, fa, fb, fc, funcalls
+	return xa, xb, xc, fa, fb, fc, funcalls, nil
 }
 
 // BrentMinimizer is the translation of class Brent in scipy/optimize/optimize.py
@@ -109,11 +114,25 @@ type BrentMinimizer struct {
 	Brack          []float64
 	bracketer
 	FnMaxFev func(int) bool
+	// Observer, if not nil, receives the events of Optimize, with x as a
+	// slice of length 1
+	Observer Observer
+	// Err is set by Optimize if Brack is invalid or the minimum could not be
+	// bracketed. Optimize then returns the best point evaluated.
+	Err error
 }
 
 // NewBrentMinimizer returns an initialized *BrentMinimizer
 func NewBrentMinimizer(fun func(float64) float64, tol float64, maxiter int, fnMaxFev func(int) bool) *BrentMinimizer {
-	return &BrentMinimizer{
+	bm := &BrentMinimizer{}
+	bm.init(fun, tol, maxiter, fnMaxFev)
+	return bm
+}
+
+// init resets bm to a new BrentMinimizer, so that a BrentMinimizer held by
+// value is reused without allocation
+func (bm *BrentMinimizer) init(fun func(float64) float64, tol float64, maxiter int, fnMaxFev func(int) bool) {
+	*bm = BrentMinimizer{
 		Func:      fun,
 		Tol:       tol,
 		Maxiter:   maxiter,
@@ -124,46 +143,67 @@ func NewBrentMinimizer(fun func(float64) float64, tol float64, maxiter int, fnMa
 	}
 }
 
-// SetBracket can be used to set initial bracket of BrentMinimizer. len(brack) must be between 1 and 3 inclusive.
+// SetBracket can be used to set initial bracket of BrentMinimizer. len(brack) must be 2 or 3.
 func (bm *BrentMinimizer) SetBracket(brack []float64) {
 	bm.Brack = make([]float64, len(brack))
 	copy(bm.Brack, brack)
 }
-func (bm *BrentMinimizer) getBracketInfo() (float64, float64, float64, float64, float64, float64, int) {
-	fun := bm.Func
+func (bm *BrentMinimizer) getBracketInfo(fun func(float64) float64) (xa, xb, xc, fa, fb, fc float64, funcalls int, err error) {
 	brack := bm.Brack
-	var xa, xb, xc float64
-	var fa, fb, fc float64
-	var funcalls int
 	switch len(brack) {
 	case 0:
-		xa, xb, xc, fa, fb, fc, funcalls = bm.bracketer.bracket(fun, 0, 1)
+		return bm.bracketer.bracket(fun, 0, 1)
 	case 2:
-		xa, xb, xc, fa, fb, fc, funcalls = bm.bracketer.bracket(fun, brack[0], brack[1])
+		return bm.bracketer.bracket(fun, brack[0], brack[1])
 	case 3:
 		xa, xb, xc = brack[0], brack[1], brack[2]
 		if xa > xc {
 			xa, xc = xc, xa
 		}
 		fa, fb, fc = fun(xa), fun(xb), fun(xc)
+		funcalls = 3
 		if !((fb < fa) && (fb < fc)) {
-			panic("getBracketInfo: not a brackeding interval")
+			err = errors.New("optimize: brent: not a bracketing interval")
 		}
-		funcalls = 3
+		return
 	}
-	return xa, xb, xc, fa, fb, fc, funcalls
+	err = errors.New("optimize: brent: len(Brack) must be 0, 2 or 3")
+	return
 }
 
 // Optimize search the value of X minimizing bm.Func
 func (bm *BrentMinimizer) Optimize() (x, fx float64, iter, funcalls int) {
-	var xa, xb, xc, fb, _mintol, _cg, v, fv, w, fw, a, b, deltax, tol1, tol2, xmid, rat, tmp1, tmp2, p, dxTemp, u, fu float64
+	var xa, xb, xc, fa, fb, fc, _mintol, _cg, v, fv, w, fw, a, b, deltax, tol1, tol2, xmid, rat, tmp1, tmp2, p, dxTemp, u, fu float64
 	if bm.FnMaxFev == nil {
 		bm.FnMaxFev = func(int) bool { return false }
 	}
 	//# set up for optimization
 	f := bm.Func
+	// the monitor is only allocated for an Observer
+	var mon *monitor
+	if bm.Observer != nil {
+		mon = newMonitor(nil, bm.Observer, "brent", bm.Brack)
+		f = func(x float64) float64 {
+			y := bm.Func(x)
+			mon.evaluated([]float64{x}, y)
+			return y
+		}
+	}
 
-	xa, xb, xc, _, fb, _, funcalls = bm.getBracketInfo()
+	xa, xb, xc, fa, fb, fc, funcalls, bm.Err = bm.getBracketInfo(f)
+	if bm.Err != nil {
+		x, fx = math.NaN(), math.NaN()
+		for _, p := range [][2]float64{{xa, fa}, {xb, fb}, {xc, fc}} {
+			if funcalls > 0 && (p[1] < fx || fx != fx) {
+				x, fx = p[0], p[1]
+			}
+		}
+		bm.Xmin, bm.Fval, bm.Iter, bm.Funcalls = x, fx, 0, funcalls
+		if bm.Observer != nil {
+			mon.done((&Result{X: []float64{x}, F: fx, Status: optimize.Failure, Message: bm.Err.Error(), NFev: funcalls}).done(mon.start))
+		}
+		return
+	}
 	_mintol = bm.mintol
 	_cg = bm.cg
 	// #################################
@@ -275,10 +315,22 @@ func (bm *BrentMinimizer) Optimize() (x, fx float64, iter, funcalls int) {
 			fx = fu
 		}
 		iter++
+		if bm.Observer != nil {
+			mon.iterate(iter, funcalls, []float64{x}, nil)
+		}
 	}
 	// #################################
 	// #END CORE ALGORITHM
 	// #################################
 	bm.Xmin, bm.Fval, bm.Iter, bm.Funcalls = x, fx, iter, funcalls
+	if bm.Observer != nil {
+		res := &Result{X: []float64{x}, F: fx, Status: optimize.MethodConverge, Reason: ReasonTolX, NIter: iter, NFev: funcalls}
+		if iter >= bm.Maxiter {
+			res.Status, res.Reason = optimize.IterationLimit, ReasonMaxIter
+		} else if bm.FnMaxFev(funcalls) {
+			res.Status, res.Reason = optimize.FunctionEvaluationLimit, ReasonMaxFev
+		}
+		mon.done(res.done(mon.start))
+	}
 	return
 }
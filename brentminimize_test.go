@@ -2,6 +2,8 @@ package optimize
 
 import (
 	"fmt"
+	"math"
+	"testing"
 )
 
 func ExampleBrentMinimizer() {
@@ -22,3 +24,25 @@ func ExampleBrentMinimizer() {
 	// x: -2.7755576e-17, fx: 7.7037198e-34, nIter: 5, nFev: 9
 
 }
+
+func TestBrentMinimizerErr(t *testing.T) {
+	bm := NewBrentMinimizer(func(x float64) float64 { return x * x }, 1e-8, 500, nil)
+	bm.Brack = []float64{-1, 2, 0.5}
+	x, fx, _, nFev := bm.Optimize()
+	if bm.Err == nil || x != 0.5 || fx != 0.25 || nFev != 3 {
+		t.Errorf("not a bracket: %g %g %d %v", x, fx, nFev, bm.Err)
+	}
+	bm.Brack = []float64{1}
+	if x, _, _, _ := bm.Optimize(); bm.Err == nil || !math.IsNaN(x) {
+		t.Errorf("len 1: %g %v", x, bm.Err)
+	}
+	bm = NewBrentMinimizer(func(x float64) float64 { return -x }, 1e-8, 500, nil)
+	bm.bracketer.maxIter = 10
+	if x, fx, _, _ := bm.Optimize(); bm.Err == nil || fx != -x || x < 100 {
+		t.Errorf("unbounded: %g %g %v", x, fx, bm.Err)
+	}
+	bm.Func = func(x float64) float64 { return x * x }
+	if x, _, _, _ := bm.Optimize(); bm.Err != nil || math.Abs(x) > 1e-8 {
+		t.Errorf("Err not reset: %g %v", x, bm.Err)
+	}
+}
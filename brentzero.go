@@ -11,6 +11,17 @@ import (
 // see https://en.wikipedia.org/wiki/Brent%27s_method
 // logger may be nil
 func Brent(a, b, tol float64, f func(float64) float64, logger *log.Logger) (float64, error) {
+	var trace func(it int, a, fa, b, fb float64)
+	if logger != nil {
+		trace = func(it int, a, fa, b, fb float64) {
+			logger.Printf("%d (a%d,f(a%d))=(%.5g, %.5g) and  (b%d,f(b%d))=%.5g,%.5g ", it+1, it, it, a, fa, it, it, b, fb)
+		}
+	}
+	return brent(a, b, tol, f, trace)
+}
+
+// brent is Brent calling trace, if not nil, with the bracket at each iteration
+func brent(a, b, tol float64, f func(float64) float64, trace func(it int, a, fa, b, fb float64)) (float64, error) {
 	type float = float64
 
 	abs := func(x float) float {
@@ -38,8 +49,8 @@ func Brent(a, b, tol float64, f func(float64) float64, logger *log.Logger) (floa
 	mflag := true
 	// répéter jusqu'à ce que f(b) = 0 ou |b − a| soit suffisamment petit (convergence)
 	for fb != 0 && abs(b-a) > tol {
-		if logger != nil {
-			logger.Printf("%d (a%d,f(a%d))=(%.5g, %.5g) and  (b%d,f(b%d))=%.5g,%.5g ", it+1, it, it, a, fa, it, it, b, fb)
+		if trace != nil {
+			trace(it, a, fa, b, fb)
 		}
 		it++
 		if it == 1000 {
@@ -99,8 +110,8 @@ func Brent(a, b, tol float64, f func(float64) float64, logger *log.Logger) (floa
 		}
 		// fin répéte
 	}
-	if logger != nil {
-		logger.Printf("%d (a%d,f(a%d))=(%.5g, %.5g) and  (b%d,f(b%d))=%.5g,%.5g ", it+1, it, it, a, fa, it, it, b, fb)
+	if trace != nil {
+		trace(it, a, fa, b, fb)
 	}
 	// sortir b (renvoie de la racine)
 	return b, nil
@@ -109,31 +120,119 @@ func Brent(a, b, tol float64, f func(float64) float64, logger *log.Logger) (floa
 // Bissection find zero of f using Bissection's method
 // logger may be nil
 func Bissection(a, b, tol float64, f func(float64) float64, logger *log.Logger) (float64, error) {
-	type float = float64
+	res, err := BissectionWith(a, b, f, &BissectionOptions{XTol: tol, Logger: logger})
+	return res.X, err
+}
+
+// BissectionOptions are the settings of BissectionWith. A nil
+// *BissectionOptions is the default.
+type BissectionOptions struct {
+	// XTol and RTol stop the search when the bracket is at most
+	// XTol+RTol*|x|. It also stops when the bracket is two consecutive
+	// floats, so they may be 0.
+	XTol, RTol float64
+	// MaxIter is the maximum number of iterations, no limit if 0
+	MaxIter int
+	// Logger, if not nil, logs the bracket at each iteration
+	Logger *log.Logger
+}
+
+// RootResult is the root found by a root finder
+type RootResult struct {
+	// X is the root, F its value
+	X, F float64
+	// NIter and NFev are the numbers of iterations and of evaluations of f
+	NIter, NFev int
+	// Converged tells whether f(X) is 0 or the bracket met the tolerance
+	// or can't be split, false if the search stopped at MaxIter
+	Converged bool
+}
+
+var (
+	errNoSignChange = errors.New("brent: f(a) f(b) >= 0")
+	errRootNaN      = errors.New("optimize: Bissection: f is NaN")
+	errRootBracket  = errors.New("optimize: Bissection needs a finite bracket")
+)
+
+// BissectionWith finds a zero of f in [a, b] by bissection, f(a) and f(b)
+// of opposite signs or one of them 0. At a discontinuity where f changes
+// its sign, eg a jump, X is one end of the smallest bracket containing it.
+// It returns an error for an infinite bound, without a sign change, or if
+// f is NaN.
+func BissectionWith(a, b float64, f func(float64) float64, opts *BissectionOptions) (RootResult, error) {
+	var trace func(it int, a, fa, b, fb float64)
+	if opts != nil && opts.Logger != nil {
+		logger := opts.Logger
+		trace = func(it int, a, fa, b, fb float64) {
+			logger.Printf("%d a,fa=%.5g, %.5g b,fb=%.5g,%.5g\n", it, a, fa, b, fb)
+		}
+	}
+	return bissection(a, b, f, opts, trace)
+}
+
+// bissection is BissectionWith calling trace, if not nil, with the bracket at each iteration
+func bissection(a, b float64, f func(float64) float64, opts *BissectionOptions, trace func(it int, a, fa, b, fb float64)) (RootResult, error) {
+	var o BissectionOptions
+	if opts != nil {
+		o = *opts
+	}
 	abs, NaN := math.Abs, math.NaN()
-	it := 0
+	res := RootResult{X: NaN, F: NaN}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) || a != a || b != b {
+		return res, errRootBracket
+	}
 	// calculer f(a)
 	// calculer f(b)
 	fa, fb := f(a), f(b)
-	// si f(a) f(b) >= 0 alors sortie (erreur) fin si
-	if fa*fb >= 0 {
-		return NaN, errors.New("brent: f(a) f(b) >= 0")
+	res.NFev = 2
+	switch {
+	case fa != fa || fb != fb:
+		return res, errRootNaN
+	case fa == 0 || fb == 0:
+		res.X, res.F, res.Converged = b, fb, true
+		if fa == 0 {
+			res.X, res.F = a, fa
+		}
+		return res, nil
+	case (fa < 0) == (fb < 0):
+		// sans former f(a) f(b), qui peut valoir 0 ou l'infini
+		return res, errNoSignChange
 	}
 	// si |f(a)| < |f(b)| alors échanger (a,b) fin si
 	if abs(fa) < abs(fb) {
 		a, fa, b, fb = b, fb, a, fa
 	}
-	var s, fs float
 	// répéter jusqu'à ce que f(b) = 0 ou |b − a| soit suffisamment petit (convergence)
-	for fb != 0 && abs(b-a) > tol {
-		if logger != nil {
-			logger.Printf("%d a,fa=%.5g, %.5g b,fb=%.5g,%.5g\n", it, a, fa, b, fb)
+	for {
+		if trace != nil {
+			trace(res.NIter, a, fa, b, fb)
+		}
+		res.X, res.F = b, fb
+		if fb == 0 || abs(b-a) <= o.XTol+o.RTol*abs(b) {
+			res.Converged = true
+			break
+		}
+		if o.MaxIter > 0 && res.NIter >= o.MaxIter {
+			break
+		}
+		s := (a + b) / 2
+		if math.IsInf(s, 0) {
+			s = a/2 + b/2
+		}
+		// a et b consécutifs : l'intervalle ne peut plus être divisé
+		if !(math.Min(a, b) < s && s < math.Max(a, b)) {
+			res.Converged = true
+			break
+		}
+		res.NIter++
+		fs := f(s)
+		res.NFev++
+		if fs != fs {
+			res.X, res.F = s, fs
+			return res, errRootNaN
 		}
-		it++
-		s = (a + b) / 2
-		fs = f(s)
 		//     si f(a) f(s) < 0 alors b := s sinon a := s fin si
-		if fa*fs < 0 {
+		if fs != 0 && (fa < 0) != (fs < 0) {
 			b, fb = s, fs
 		} else {
 			a, fa = s, fs
@@ -144,9 +243,6 @@ func Bissection(a, b, tol float64, f func(float64) float64, logger *log.Logger)
 		}
 		// fin répéte
 	}
-	if logger != nil {
-		logger.Printf("%d a,fa=%.5g, %.5g b,fb=%.5g,%.5g\n", it, a, fa, b, fb)
-	}
 	// sortir b (renvoie de la racine)
-	return b, nil
+	return res, nil
 }
@@ -3,7 +3,9 @@ package optimize
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"testing"
 )
 
 func ExampleBrent() {
@@ -55,3 +57,62 @@ func ExampleBissection() {
 	// 3 a,fa=-3.3333, -6.2593 b,fb=-2.6667,4.4815
 	// 4 a,fa=-2.6667, 4.4815 b,fb=-3,0
 }
+
+func ExampleBissectionWith() {
+	// the cube root of 2 to a relative tolerance, and at most 10 iterations
+	f := func(x float64) float64 { return x*x*x - 2 }
+	for _, opts := range []*BissectionOptions{{RTol: 1e-6}, {MaxIter: 10}} {
+		res, err := BissectionWith(0, 2, f, opts)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%.6f converged %v after %d iterations, %d evaluations\n", res.X, res.Converged, res.NIter, res.NFev)
+	}
+	// Output:
+	// 1.259921 converged true after 21 iterations, 23 evaluations
+	// 1.259766 converged false after 10 iterations, 12 evaluations
+}
+
+func TestBissectionWith(t *testing.T) {
+	// a jump at the root ends at consecutive floats, without a tolerance
+	step := func(x float64) float64 {
+		if x < 1./3 {
+			return -1
+		}
+		return 1
+	}
+	res, err := BissectionWith(0, 1, step, nil)
+	if err != nil || !res.Converged || math.Abs(res.X-1./3) > 1e-16 || res.NIter > 60 || res.NFev != res.NIter+2 {
+		t.Errorf("step: %+v %v", res, err)
+	}
+	// a pole at the root
+	pole := func(x float64) float64 { return 1 / (x - .7) }
+	if res, err := BissectionWith(0, 1, pole, &BissectionOptions{XTol: 1e-12}); err != nil || !res.Converged || math.Abs(res.X-.7) > 1e-12 {
+		t.Errorf("pole: %+v %v", res, err)
+	}
+	// huge values, whose product overflows, and a wide bracket
+	huge := func(x float64) float64 { return 1e200 * (x - 3) }
+	if res, err := BissectionWith(-math.MaxFloat64, math.MaxFloat64, huge, nil); err != nil || !res.Converged || res.X != 3 {
+		t.Errorf("huge: %+v %v", res, err)
+	}
+	if res, _ := BissectionWith(-1, 5, step, &BissectionOptions{MaxIter: 4}); res.Converged || res.NIter != 4 {
+		t.Errorf("MaxIter: %+v", res)
+	}
+	if res, err := BissectionWith(0, 2, func(x float64) float64 { return x - 2 }, nil); err != nil || res.X != 2 || res.NIter != 0 || !res.Converged {
+		t.Errorf("root at an end: %+v %v", res, err)
+	}
+	nan := func(x float64) float64 {
+		if x >= .5 && x <= .6 {
+			return math.NaN()
+		}
+		return x - .8
+	}
+	for _, c := range []struct {
+		a, b float64
+		f    func(float64) float64
+	}{{0, 1, nan}, {0, .7, nan}, {2, 3, step}, {0, math.Inf(1), step}, {math.NaN(), 1, step}} {
+		if res, err := BissectionWith(c.a, c.b, c.f, nil); err == nil {
+			t.Errorf("[%g, %g]: expected an error, got %+v", c.a, c.b, res)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package optimize
+
+import (
+	"math"
+	"sync"
+)
+
+// Cache memoizes a function, returning the cached value for points already
+// evaluated. Direct search methods and restarts often evaluate the same
+// points again on quantized spaces. It is safe for concurrent use if Func is;
+// concurrent evaluations of a new point may then all call Func.
+type Cache struct {
+	Func func([]float64) float64
+	// Tolerance, if positive, rounds the components of the keys to multiples
+	// of Tolerance: the points of a cell get the value of the first one
+	// evaluated
+	Tolerance float64
+	// MaxSize, if positive, is the maximum number of values. The oldest ones
+	// are then evicted.
+	MaxSize int
+
+	mu     sync.Mutex
+	values map[string]float64
+	order  []string
+	// Hits and Misses are the number of cached and evaluated calls
+	Hits, Misses int
+}
+
+// NewCache returns a Cache of f with tolerance tol
+func NewCache(f func([]float64) float64, tol float64) *Cache {
+	return &Cache{Func: f, Tolerance: tol}
+}
+
+// key returns the key of x
+func (c *Cache) key(x []float64) string {
+	if c.Tolerance <= 0 {
+		return floatsKey(x)
+	}
+	r := make([]float64, len(x))
+	for i, xi := range x {
+		// +0 makes -0 and 0 the same key
+		r[i] = math.Round(xi/c.Tolerance) + 0
+	}
+	return floatsKey(r)
+}
+
+// get returns the value of key, if cached, counting a hit or a miss
+func (c *Cache) get(key string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	y, ok := c.values[key]
+	if ok {
+		c.Hits++
+	} else {
+		c.Misses++
+	}
+	return y, ok
+}
+
+// put caches the value of key
+func (c *Cache) put(key string, y float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]float64)
+	}
+	if _, ok := c.values[key]; ok {
+		return
+	}
+	c.values[key] = y
+	if c.MaxSize <= 0 {
+		return
+	}
+	c.order = append(c.order, key)
+	if len(c.order) > c.MaxSize {
+		delete(c.values, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// Eval returns f(x), cached if x has been evaluated
+func (c *Cache) Eval(x []float64) float64 {
+	key := c.key(x)
+	if y, ok := c.get(key); ok {
+		return y
+	}
+	y := c.Func(x)
+	c.put(key, y)
+	return y
+}
+
+// Batch returns a BatchFunc calling batch with the points not cached. Func
+// is unused.
+func (c *Cache) Batch(batch BatchFunc) BatchFunc {
+	return func(xs [][]float64) []float64 {
+		ys := make([]float64, len(xs))
+		var (
+			keys []string
+			idx  []int
+			miss [][]float64
+		)
+		for i, x := range xs {
+			key := c.key(x)
+			if y, ok := c.get(key); ok {
+				ys[i] = y
+				continue
+			}
+			keys, idx, miss = append(keys, key), append(idx, i), append(miss, x)
+		}
+		if len(miss) == 0 {
+			return ys
+		}
+		vals := batch(miss)
+		if len(vals) != len(miss) {
+			// let the caller see the wrong number of values
+			return vals
+		}
+		for j, y := range vals {
+			ys[idx[j]] = y
+			c.put(keys[j], y)
+		}
+		return ys
+	}
+}
+
+// Len returns the number of cached values
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.values)
+}
+
+// Reset empties the cache and its counters
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values, c.order, c.Hits, c.Misses = nil, nil, 0, 0
+}
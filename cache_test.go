@@ -0,0 +1,70 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleCache() {
+	// a simulation on a grid of step .5
+	f := func(x []float64) float64 {
+		return math.Abs(math.Round(2*x[0])/2-3) + math.Abs(math.Round(2*x[1])/2+2)
+	}
+	cache := NewCache(f, .5)
+	ls := NewLatticeSearch()
+	ls.Minimize(cache.Eval, []float64{0, 0})
+	// restart from the solution
+	ls.Minimize(cache.Eval, []float64{3, -2})
+	fmt.Println(cache.Misses, cache.Hits)
+	// Output:
+	// 19 5
+}
+
+func TestCache(t *testing.T) {
+	calls := 0
+	f := func(x []float64) float64 { calls++; return x[0] }
+	c := NewCache(f, 0)
+	c.Eval([]float64{1})
+	c.Eval([]float64{1})
+	c.Eval([]float64{1 + 1e-12})
+	if calls != 2 || c.Hits != 1 || c.Misses != 2 || c.Len() != 2 {
+		t.Errorf("calls %d, hits %d, misses %d, len %d", calls, c.Hits, c.Misses, c.Len())
+	}
+
+	c = NewCache(f, .1)
+	if y := c.Eval([]float64{1.01}); y != 1.01 || c.Eval([]float64{.99}) != 1.01 || c.Eval([]float64{-.01}) != c.Eval([]float64{.01}) {
+		t.Errorf("rounding: %g", y)
+	}
+
+	c = NewCache(f, 0)
+	c.MaxSize = 2
+	for _, x := range []float64{1, 2, 3} {
+		c.Eval([]float64{x})
+	}
+	calls = 0
+	if c.Eval([]float64{3}); calls != 0 || c.Len() != 2 {
+		t.Errorf("3 should be cached, len %d", c.Len())
+	}
+	if c.Eval([]float64{1}); calls != 1 {
+		t.Error("1 should have been evicted")
+	}
+	c.Reset()
+	if c.Len() != 0 || c.Hits != 0 || c.Misses != 0 {
+		t.Error("Reset should empty the cache")
+	}
+
+	points := 0
+	batch := c.Batch(func(xs [][]float64) []float64 {
+		points += len(xs)
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = 10 * x[0]
+		}
+		return ys
+	})
+	batch([][]float64{{1}, {2}})
+	if ys := batch([][]float64{{2}, {3}}); points != 3 || ys[0] != 20 || ys[1] != 30 {
+		t.Errorf("batch %g after %d points", ys, points)
+	}
+}
@@ -0,0 +1,186 @@
+package optimize
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// Checkpointer is implemented by iterative solvers whose state can be saved
+// during a run and restored before another run to resume it, so that long
+// runs survive restarts. It is implemented by PowellMinimizer, SPSA and
+// CmaEsCholB. Snapshot must be called between iterations, eg by an Observer.
+// The random source is part of the state if it implements
+// encoding.BinaryMarshaler, like the sources of rand.NewSource.
+type Checkpointer interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+var (
+	_ Checkpointer = &PowellMinimizer{}
+	_ Checkpointer = &SPSA{}
+	_ Checkpointer = &CmaEsCholB{}
+)
+
+var errNoState = errors.New("optimize: no state to snapshot before the first iteration")
+
+// encodeState encodes the state of solver
+func encodeState(solver string, state interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(solver); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeState decodes in state a snapshot of solver
+func decodeState(solver string, data []byte, state interface{}) error {
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	var name string
+	if err := dec.Decode(&name); err != nil {
+		return fmt.Errorf("optimize: invalid checkpoint: %v", err)
+	}
+	if name != solver {
+		return fmt.Errorf("optimize: checkpoint of %s, expected %s", name, solver)
+	}
+	if err := dec.Decode(state); err != nil {
+		return fmt.Errorf("optimize: invalid checkpoint: %v", err)
+	}
+	return nil
+}
+
+// marshalSource returns the state of src, nil if it can't be saved
+func marshalSource(src rand.Source) ([]byte, error) {
+	if m, ok := src.(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	return nil, nil
+}
+
+// unmarshalSource restores the state of src, if any
+func unmarshalSource(src rand.Source, data []byte) error {
+	if data == nil {
+		return nil
+	}
+	u, ok := src.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errors.New("optimize: the random source can't be restored")
+	}
+	return u.UnmarshalBinary(data)
+}
+
+// CheckpointObserver is an Observer writing a snapshot of Checkpointer to W
+// every Every iterations. Snapshots are length-prefixed, ReadCheckpoint
+// returns the last one. Err is the first error, after which nothing is written.
+type CheckpointObserver struct {
+	Checkpointer Checkpointer
+	W            io.Writer
+	// Every defaults to 1
+	Every int
+	Err   error
+}
+
+var _ Observer = &CheckpointObserver{}
+
+// OnStart does nothing
+func (co *CheckpointObserver) OnStart(*Start) {}
+
+// OnIteration writes a snapshot every Every iterations
+func (co *CheckpointObserver) OnIteration(it *Iteration) {
+	every := co.Every
+	if every <= 0 {
+		every = 1
+	}
+	if co.Err != nil || it.Iter%every != 0 {
+		return
+	}
+	data, err := co.Checkpointer.Snapshot()
+	if err == nil {
+		err = WriteCheckpoint(co.W, data)
+	}
+	co.Err = err
+}
+
+// OnEvaluation does nothing
+func (co *CheckpointObserver) OnEvaluation(*Evaluation) {}
+
+// OnDone does nothing
+func (co *CheckpointObserver) OnDone(*Result) {}
+
+// WriteCheckpoint writes the length-prefixed snapshot data to w
+func WriteCheckpoint(w io.Writer, data []byte) error {
+	var n [binary.MaxVarintLen64]byte
+	if _, err := w.Write(n[:binary.PutUvarint(n[:], uint64(len(data)))]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadCheckpoint returns the last snapshot written to r by WriteCheckpoint.
+// A truncated last snapshot, eg after a crash while writing, is ignored, as
+// are the following bytes if its length is corrupt.
+func ReadCheckpoint(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	var last []byte
+	for {
+		n, err := binary.ReadUvarint(br)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			break
+		}
+		// the buffer grows with the data read, not with a corrupt length
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, br, int64(n)); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, err
+		}
+		last = data.Bytes()
+	}
+	if last == nil {
+		return nil, errors.New("optimize: no checkpoint")
+	}
+	return last, nil
+}
+
+// WithCheckpoint writes a snapshot of the solver to w every every
+// iterations, in addition to the Observer set before
+func WithCheckpoint(w io.Writer, every int) Option {
+	return func(s configurable) error {
+		if every < 0 {
+			return errors.New("optimize: WithCheckpoint: negative value")
+		}
+		c, ok := s.(Checkpointer)
+		f := s.fields()
+		if !ok || f.observer == nil {
+			return unsupported("WithCheckpoint", s)
+		}
+		co := &CheckpointObserver{Checkpointer: c, W: w, Every: every}
+		if *f.observer == nil {
+			*f.observer = co
+		} else {
+			*f.observer = Observers{*f.observer, co}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package optimize
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleCheckpointer() {
+	buf := new(bytes.Buffer)
+	// a run interrupted after 3 iterations, writing a checkpoint at each iteration
+	pm, _ := NewPowellMinimizerWith(WithMaxIter(3), WithCheckpoint(buf, 1))
+	pm.Minimize(rosen, []float64{-1.2, 1})
+
+	// resume it
+	data, err := ReadCheckpoint(buf)
+	if err != nil {
+		panic(err)
+	}
+	pm = NewPowellMinimizer()
+	if err := pm.Restore(data); err != nil {
+		panic(err)
+	}
+	res := pm.Minimize(rosen, nil)
+	fmt.Printf("%s %.3f\n", res.Status, res.X)
+	// Output:
+	// MethodConverge [1.000 1.000]
+}
+
+func sameResult(a, b *Result) bool {
+	if a.F != b.F || a.NIter != b.NIter || a.NFev != b.NFev || len(a.X) != len(b.X) {
+		return false
+	}
+	for i := range a.X {
+		if a.X[i] != b.X[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCheckpointPowell(t *testing.T) {
+	x0 := []float64{-1.2, 1}
+	want := NewPowellMinimizer().Minimize(rosen, x0)
+
+	buf := new(bytes.Buffer)
+	pm, err := NewPowellMinimizerWith(WithMaxIter(4), WithCheckpoint(buf, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.Snapshot(); err == nil {
+		t.Error("expected an error before the first iteration")
+	}
+	pm.Minimize(rosen, x0)
+	data, err := ReadCheckpoint(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewPowellMinimizer()
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := resumed.Minimize(rosen, nil); !sameResult(got, want) {
+		t.Errorf("resumed %+v, expected %+v", got, want)
+	}
+	if err := NewSPSA().Restore(data); err == nil {
+		t.Error("expected an error restoring a powell checkpoint in SPSA")
+	}
+}
+
+func TestCheckpointSPSA(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	x0 := []float64{0, 0}
+	newSPSA := func(maxIter int) *SPSA {
+		sp := NewSPSA()
+		sp.MaxIter, sp.Stability, sp.Src = maxIter, 10, rand.NewSource(1)
+		sp.Averaging = &Averaging{}
+		return sp
+	}
+	want := newSPSA(100).Minimize(f, x0)
+
+	sp := newSPSA(40)
+	buf := new(bytes.Buffer)
+	sp.Observer = &CheckpointObserver{Checkpointer: sp, W: buf, Every: 20}
+	sp.Minimize(f, x0)
+	data, err := ReadCheckpoint(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := newSPSA(100)
+	resumed.Src = rand.NewSource(2)
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	got := resumed.Minimize(f, nil)
+	if !sameResult(got, want) || got.Extra.(*Averaged).F != want.Extra.(*Averaged).F {
+		t.Errorf("resumed %+v, expected %+v", got, want)
+	}
+}
+
+func TestCheckpointCmaEsCholB(t *testing.T) {
+	problem := optimize.Problem{Func: rosen}
+	x0 := []float64{-1.2, 1}
+	want, err := optimize.Minimize(problem, x0, &optimize.Settings{MajorIterations: 30}, &CmaEsCholB{Src: rand.NewSource(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	cma, err := NewCmaEsCholBWith(WithRNG(rand.NewSource(1)), WithCheckpoint(buf, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := optimize.Minimize(problem, x0, &optimize.Settings{MajorIterations: 10}, cma); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadCheckpoint(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := &CmaEsCholB{Src: rand.NewSource(2)}
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := optimize.Minimize(problem, x0, &optimize.Settings{MajorIterations: 20}, resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.F != want.F || got.X[0] != want.X[0] || got.X[1] != want.X[1] {
+		t.Errorf("resumed %g %g, expected %g %g", got.F, got.X, want.F, want.X)
+	}
+
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := optimize.Minimize(problem, []float64{1, 2, 3}, nil, resumed); err == nil {
+		t.Error("expected an error restoring a checkpoint of another dimension")
+	}
+}
+
+func TestReadCheckpoint(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if _, err := ReadCheckpoint(buf); err == nil {
+		t.Error("expected an error without checkpoint")
+	}
+	for _, data := range []string{"first", "second"} {
+		if err := WriteCheckpoint(buf, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// truncated third checkpoint
+	buf.Write([]byte{10, 't'})
+	if data, err := ReadCheckpoint(buf); err != nil || string(data) != "second" {
+		t.Errorf("got %q %v", data, err)
+	}
+	// corrupt length: the buffer is not allocated at once
+	buf.Reset()
+	if err := WriteCheckpoint(buf, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 'x'})
+	if data, err := ReadCheckpoint(buf); err != nil || string(data) != "first" {
+		t.Errorf("corrupt length: got %q %v", data, err)
+	}
+	for _, s := range []configurable{&Options{}, &LatticeSearch{}} {
+		if err := WithCheckpoint(buf, 1)(s); err == nil {
+			t.Errorf("expected WithCheckpoint to be unsupported by %T", s)
+		}
+	}
+}
@@ -2,7 +2,7 @@
 
 // Copyright ©2017 The Gonum Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
-/* BSD license for code copied from gonum/optimize/cmaes.go (all except sendTask,ensureBounds)
+/* BSD license for code copied from gonum/optimize/cmaes.go (all except sample,sendTask,ensureBounds)
 Copyright ©2013 The Gonum Authors. All rights reserved.
 
 Redistribution and use in source and binary forms, with or without
@@ -31,8 +31,11 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package optimize
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"sort"
+	"sync/atomic"
 
 	"gonum.org/v1/gonum/optimize"
 
@@ -40,20 +43,19 @@ import (
 
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/stat/distmv"
 )
 
 // CmaEsCholB is optimize.CmaEsChol with xmin,xmax constraints
-// only sendTask,ensureBounds are different
+// only sample,sendTask,ensureBounds are different
 type CmaEsCholB struct {
 	//optimize.CmaEsChol
 	// InitStepSize sets the initial size of the covariance matrix adaptation.
 	// If InitStepSize is 0, a default value of 0.5 is used. InitStepSize cannot
-	// be negative, or CmaEsCholB will panic.
+	// be negative, see Validate.
 	InitStepSize float64
 	// Population sets the population size for the algorithm. If Population is
 	// 0, a default value of 4 + math.Floor(3*math.Log(float64(dim))) is used.
-	// Population cannot be negative or CmaEsCholB will panic.
+	// Population cannot be negative, see Validate.
 	Population int
 	// InitCholesky specifies the Cholesky decomposition of the covariance
 	// matrix for the initial sampling distribution. If InitCholesky is nil,
@@ -69,6 +71,17 @@ type CmaEsCholB struct {
 	// If StopLogDet is NaN, the stopping criterion is not used, though
 	// this can cause numeric instabilities in the algorithm.
 	StopLogDet float64
+	// MaxCondition is the threshold on the condition number of the
+	// covariance, estimated at each generation as the squared ratio of the
+	// extreme diagonal elements of its Cholesky factor, a lower bound of the
+	// condition number. Beyond it, the run stops with Status ConditionCov.
+	// If MaxCondition is 0, a default value of 1e14 is used. If it is NaN,
+	// the condition number is not tested.
+	MaxCondition float64
+	// RepairCondition, instead of stopping the run, clips the eigenvalues of
+	// the covariance to at least its largest one over MaxCondition, keeping
+	// the run alive on badly scaled problems.
+	RepairCondition bool
 	// ForgetBest, when true, does not track the best overall function value found,
 	// instead returning the new best sample in each iteration. If ForgetBest
 	// is false, then the minimum value returned will be the lowest across all
@@ -76,7 +89,94 @@ type CmaEsCholB struct {
 	ForgetBest bool
 	// Src allows a random number generator to be supplied for generating samples.
 	// If Src is nil the generator in golang.org/x/math/rand is used.
+	// Samples are drawn in index order, so that with a given Src the result
+	// doesn't depend on Settings.Concurrent or GOMAXPROCS.
 	Src rand.Source
+	// Projection, if not nil, is applied to samples and to the mean after
+	// Xmin,Xmax are enforced, for feasible sets which are not boxes. Its
+	// Validate method, if any, is checked by Validate.
+	Projection Projection
+	// Comparator, if not nil, is used to rank samples instead of their
+	// function values, eg a *NoisyObjective for noisy problems. As Compare
+	// may not be transitive, the samples are ranked by the number of samples
+	// they beat in the comparisons of all pairs, then by function value.
+	Comparator Comparator
+	// Ranker, if not nil, ranks the population to select the elite samples,
+	// eg a *Racing for noisy problems. It takes precedence over Comparator.
+	Ranker Ranker
+	// MaxFev, if positive, stops the run with Status FunctionEvaluationLimit
+	// at the end of the generation where the evaluations of the tasks and the
+	// ones of Comparator or Ranker, see RankEvaluations, reach MaxFev.
+	// gonum's Settings.FuncEvaluations only counts the tasks.
+	MaxFev int
+	// budget is the MaxFev of Minimize, used if MaxFev is 0
+	budget         int
+	nfev, rankNFev int
+	// CRN, if not nil, gets a new seed at the beginning of each generation so
+	// that samples of a generation use common random numbers. Problem.Func
+	// should then be CRN.Eval.
+	CRN *CommonRandomNumbers
+	// Variables, if not nil, are the types of the dimensions of a mixed
+	// problem. Samples are repaired before evaluation, while the continuous
+	// samples drive the adaptation. MinStepSize keeps discrete variables moving.
+	Variables Variables
+	// Repair, if not nil, is applied to samples before evaluation, after Variables
+	Repair Repair
+	// Initializer, if not nil, gives the first generation of a run instead of
+	// samples of the initial distribution, eg a LatinHypercube of the box.
+	// The distribution is adapted from x0 to its best points.
+	Initializer Initializer
+	initial     bool
+
+	// Tracking enables the dynamic mode for drifting objectives: the run
+	// never ends with MethodConverge, and EnvironmentChanged may be called to
+	// restore exploration. gonum's Settings.Converger should then be
+	// optimize.NeverTerminate{}.
+	Tracking bool
+	// MinStepSize, if positive, is a floor on the standard deviation of
+	// samples along each coordinate, so that the distribution can follow a
+	// moving optimum. Samples are drawn from the covariance, which carries the
+	// scale of the distribution.
+	MinStepSize float64
+	// ChangeStepSize, if positive, is the standard deviation of samples
+	// restored when EnvironmentChanged is called: the covariance is reset to
+	// ChangeStepSize^2 I. If 0, it is reset to its initial value.
+	ChangeStepSize float64
+	// KeepCovarianceOnChange keeps the learned covariance when
+	// EnvironmentChanged is called.
+	KeepCovarianceOnChange bool
+	// Immigrants, if positive, is the number of samples of a generation
+	// replaced by random immigrants during the ImmigrantGenerations
+	// generations after EnvironmentChanged, 1 if 0, to restore the diversity
+	// of the population: uniform points of the box along the bounded
+	// coordinates, points around the mean with ChangeStepSize, or the initial
+	// step size, as standard deviation along the others.
+	Immigrants, ImmigrantGenerations int
+	changed                          int32
+	immigrate                        int
+	// TargetF, if not nil, stops the run with Status FunctionThreshold at the
+	// end of the first generation reaching f <= *TargetF. Other gonum
+	// methods can use a TargetConverger.
+	TargetF *float64
+	lastF   float64
+	// StallIterations, if positive, stops the run with Status
+	// FunctionConvergence when the best value has not decreased by more than
+	// StallTolerance over the last StallIterations generations.
+	StallIterations int
+	StallTolerance  float64
+	stall           *stallWatch
+	generation      int
+	stalled         bool
+	// Observer, if not nil, receives the events of Run. Diagnostics of
+	// iterations are "sigma", the step size, "logdet", the log
+	// determinant of the covariance, and "cond", the estimate of its
+	// condition number. The Status of the done event is the one
+	// of the method, NotTerminated if gonum stopped the run.
+	Observer Observer
+	mon      *monitor
+	restored *cmaState
+	resumed  bool
+	warm     *WarmStart
 
 	// Fixed algorithm parameters.
 	dim                 int
@@ -89,12 +189,19 @@ type CmaEsCholB struct {
 	// Function data.
 	xs *mat.Dense
 	fs []float64
+	// zs are the standard normal samples of a generation
+	zs *mat.Dense
 
 	// Adaptive algorithm parameters.
 	invSigma float64 // inverse of the sigma parameter
 	pc, ps   []float64
 	mean     []float64
 	chol     mat.Cholesky
+	// temporaries of updateCovariance
+	cov     mat.SymDense
+	cholTmp mat.Cholesky
+	// eye is the identity factor of resetChol
+	eye *mat.TriDense
 
 	// Overall best.
 	bestX, Xmin, Xmax []float64
@@ -105,35 +212,149 @@ type CmaEsCholB struct {
 	receivedIdx int
 	operation   chan<- optimize.Task
 	updateErr   error
+
+	// notes of the run, see Warnings
+	warnings []string
 }
 
+// ConditionCov is the Status of a CmaEsCholB run stopped by an ill
+// conditioned covariance, see MaxCondition
+var ConditionCov = optimize.NewStatus("ConditionCov", true, errors.New("optimize: cma-es-chol: ill conditioned covariance"))
+
 var (
 	_ optimize.Statuser = (*CmaEsCholB)(nil)
 	_ optimize.Method   = (*CmaEsCholB)(nil)
 )
 
-// Needs ...
+// Needs for Needser, for gonum versions before Uses
 func (cma *CmaEsCholB) Needs() struct{ Gradient, Hessian bool } {
 	return struct{ Gradient, Hessian bool }{false, false}
 }
 
-// Uses ...
+// Uses for gonum optimize.Method
 func (cma *CmaEsCholB) Uses(has optimize.Available) (optimize.Available, error) {
 	return optimize.Available{}, nil
 }
 
 func (cma *CmaEsCholB) methodConverged() optimize.Status {
+	status, _ := cma.stop()
+	return status
+}
+
+// stop returns the status of the stopping criteria of the method and the
+// one which fired
+func (cma *CmaEsCholB) stop() (optimize.Status, StopReason) {
+	if cma.TargetF != nil && cma.lastF <= *cma.TargetF {
+		return optimize.FunctionThreshold, ReasonTarget
+	}
+	if maxFev := cma.maxFev(); maxFev > 0 && cma.nfev+cma.rankNFev >= maxFev {
+		return optimize.FunctionEvaluationLimit, ReasonMaxFev
+	}
+	if cma.Tracking {
+		return optimize.NotTerminated, ReasonUnknown
+	}
+	if cma.stalled {
+		return optimize.FunctionConvergence, ReasonStagnation
+	}
 	sd := cma.StopLogDet
 	switch {
 	case math.IsNaN(sd):
-		return optimize.NotTerminated
+		return optimize.NotTerminated, ReasonUnknown
 	case sd == 0:
 		sd = float64(cma.dim) * -36.8413614879 // ln(1e-16)
 	}
 	if cma.chol.LogDet() < sd {
-		return optimize.MethodConverge
+		return optimize.MethodConverge, ReasonTolLogDet
 	}
-	return optimize.NotTerminated
+	if !cma.RepairCondition && cma.condition() > cma.maxCondition() {
+		return ConditionCov, ReasonConditionCov
+	}
+	return optimize.NotTerminated, ReasonUnknown
+}
+
+func (cma *CmaEsCholB) maxFev() int {
+	if cma.MaxFev > 0 {
+		return cma.MaxFev
+	}
+	return cma.budget
+}
+
+// RankEvaluations returns the number of evaluations of the objective made by
+// Comparator or Ranker during the last run, for those which count them like
+// NoisyObjective and Racing. They are not part of gonum's Stats.
+func (cma *CmaEsCholB) RankEvaluations() int {
+	return cma.rankNFev
+}
+
+// Reason returns the criterion of the method which stopped the run,
+// ReasonUnknown if gonum stopped it, eg on a limit
+func (cma *CmaEsCholB) Reason() StopReason {
+	if cma.updateErr != nil {
+		return ReasonFailure
+	}
+	_, reason := cma.stop()
+	return reason
+}
+
+// Warnings returns the notes of the last run, eg its starting point
+// projected on the bounds
+func (cma *CmaEsCholB) Warnings() []string {
+	return cma.warnings
+}
+
+// maxCondition returns MaxCondition or its default
+func (cma *CmaEsCholB) maxCondition() float64 {
+	if cma.MaxCondition == 0 {
+		return 1e14
+	}
+	return cma.MaxCondition
+}
+
+// condition returns the estimate of the condition number of the covariance,
+// the squared ratio of the extreme diagonal elements of its Cholesky factor
+func (cma *CmaEsCholB) condition() float64 {
+	if cma.chol.IsEmpty() {
+		return 1
+	}
+	u := cma.chol.RawU()
+	lo, hi := math.Inf(1), 0.
+	for i := 0; i < cma.dim; i++ {
+		d := math.Abs(u.At(i, i))
+		lo, hi = math.Min(lo, d), math.Max(hi, d)
+	}
+	return hi * hi / (lo * lo)
+}
+
+// clipEigenvalues raises the eigenvalues of the covariance to at least its
+// largest one over maxCond. It returns false, leaving the covariance
+// unchanged, if the decomposition fails.
+func (cma *CmaEsCholB) clipEigenvalues(maxCond float64) bool {
+	cma.chol.ToSym(&cma.cov)
+	var eig mat.EigenSym
+	if !eig.Factorize(&cma.cov, true) {
+		return false
+	}
+	vals := eig.Values(nil)
+	var vecs mat.Dense
+	eig.VectorsTo(&vecs)
+	floor := floats.Max(vals) / maxCond
+	for i, v := range vals {
+		vals[i] = math.Max(v, floor)
+	}
+	// C = V diag(vals) V^T
+	for j, v := range vals {
+		col := mat.NewVecDense(cma.dim, mat.Col(nil, j, &vecs))
+		if j == 0 {
+			cma.cov.SymOuterK(v, col)
+			continue
+		}
+		cma.cov.SymRankOne(&cma.cov, v, col)
+	}
+	if !cma.cholTmp.Factorize(&cma.cov) {
+		return false
+	}
+	cma.chol, cma.cholTmp = cma.cholTmp, cma.chol
+	return true
 }
 
 // Status returns the status of the method.
@@ -144,13 +365,41 @@ func (cma *CmaEsCholB) Status() (optimize.Status, error) {
 	return cma.methodConverged(), nil
 }
 
+// Validate returns an error if the settings of cma are invalid for a
+// problem of dimension dim, eg the typed errors of ValidateBounds. An invalid CmaEsCholB passed to optimize.Minimize
+// stops at once with Status Failure and this error.
+func (cma *CmaEsCholB) Validate(dim int) error {
+	switch {
+	case dim <= 0:
+		return errors.New(nonpositiveDimension)
+	case cma.Population < 0:
+		return errors.New("optimize: cma-es-chol: negative population size")
+	case cma.InitStepSize < 0 || math.IsNaN(cma.InitStepSize) || math.IsInf(cma.InitStepSize, 0):
+		return errors.New("optimize: cma-es-chol: invalid initial step size")
+	case cma.MaxCondition < 0:
+		return errors.New("optimize: cma-es-chol: negative MaxCondition")
+	case cma.Immigrants < 0 || cma.ImmigrantGenerations < 0:
+		return errors.New("optimize: cma-es-chol: negative Immigrants")
+	case cma.InitCholesky != nil && symmetricDim(cma.InitCholesky) != dim:
+		return fmt.Errorf("optimize: cma-es-chol: InitCholesky size %d, expected %d", symmetricDim(cma.InitCholesky), dim)
+	}
+	if v, ok := cma.Projection.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return ValidateBounds(dim, cma.Xmin, cma.Xmax)
+}
+
 // Init ...
 func (cma *CmaEsCholB) Init(dim, tasks int) int {
-	if dim <= 0 {
-		panic(nonpositiveDimension)
+	cma.warnings = nil
+	cma.updateErr = cma.Validate(dim)
+	if cma.updateErr == nil && tasks < 0 {
+		cma.updateErr = errors.New(negativeTasks)
 	}
-	if tasks < 0 {
-		panic(negativeTasks)
+	if cma.updateErr != nil {
+		return 1
 	}
 
 	// Set fixed algorithm parameters.
@@ -160,8 +409,6 @@ func (cma *CmaEsCholB) Init(dim, tasks int) int {
 	n := float64(dim)
 	if cma.pop == 0 {
 		cma.pop = 4 + int(3*math.Log(n)) // Note the implicit floor.
-	} else if cma.pop < 0 {
-		panic("cma-es-chol: negative population size")
 	}
 	mu := cma.pop / 2
 	cma.weights = resize(cma.weights, mu)
@@ -186,15 +433,18 @@ func (cma *CmaEsCholB) Init(dim, tasks int) int {
 	cma.eChi = math.Sqrt(n) * (1 - 1.0/(4*n) + 1/(21*n*n))
 
 	// Allocate memory for function data.
-	cma.xs = mat.NewDense(cma.pop, dim, nil)
+	// the matrices of a previous run of the same size are reused
+	cma.xs = reuseDense(cma.xs, cma.pop, dim)
+	cma.zs = reuseDense(cma.zs, cma.pop, dim)
+	if !cma.cov.IsEmpty() && symmetricDim(&cma.cov) != dim {
+		cma.cov.Reset()
+	}
 	cma.fs = resize(cma.fs, cma.pop)
 
 	// Allocate and initialize adaptive parameters.
 	cma.invSigma = 1 / cma.InitStepSize
 	if cma.InitStepSize == 0 {
 		cma.invSigma = 10.0 / 3
-	} else if cma.InitStepSize < 0 {
-		panic("cma-es-chol: negative initial step size")
 	}
 	cma.pc = resize(cma.pc, dim)
 	for i := range cma.pc {
@@ -206,36 +456,236 @@ func (cma *CmaEsCholB) Init(dim, tasks int) int {
 	}
 	cma.mean = resize(cma.mean, dim) // mean location initialized at the start of Run
 
-	if cma.InitCholesky != nil {
-		if cma.InitCholesky.Symmetric() != dim {
-			panic("cma-es-chol: incorrect InitCholesky size")
-		}
-		cma.chol.Clone(cma.InitCholesky)
-	} else {
-		// Set the initial Cholesky to I.
-		b := mat.NewDiagDense(dim, nil)
-		for i := 0; i < dim; i++ {
-			b.SetDiag(i, 1)
-		}
-		var chol mat.Cholesky
-		ok := chol.Factorize(b)
-		if !ok {
-			panic("cma-es-chol: bad cholesky. shouldn't happen")
-		}
-		cma.chol = chol
-	}
+	cma.resetChol()
 
 	cma.bestX = resize(cma.bestX, dim)
 	cma.bestF = math.Inf(1)
+	cma.lastF = math.Inf(1)
+	cma.stall = newStallWatch(cma.StallIterations, cma.StallTolerance)
+	cma.generation, cma.stalled = 0, false
+	cma.nfev, cma.rankNFev = 0, 0
 
 	cma.sentIdx = 0
 	cma.receivedIdx = 0
 	cma.operation = nil
-	cma.updateErr = nil
+	atomic.StoreInt32(&cma.changed, 0)
+	cma.immigrate = 0
+	cma.resumed = false
+	cma.initial = false
+	if state := cma.restored; state != nil {
+		cma.restored = nil
+		if cma.updateErr = cma.restore(state); cma.updateErr != nil {
+			return 1
+		}
+		cma.resumed = true
+	} else if warm := cma.warm; warm != nil && len(warm.X) == dim && len(warm.Cholesky) == dim*dim && warm.StepSize > 0 {
+		// the generation and the evolution paths start again, and the
+		// covariance, small at the end of a converged run, is scaled so
+		// that the largest standard deviation of the samples is the
+		// initial step size
+		u := append([]float64(nil), warm.Cholesky...)
+		sd := 0.
+		for j := 0; j < dim; j++ {
+			// the variance of coordinate j is the squared norm of the column j of U
+			v := 0.
+			for k := 0; k <= j; k++ {
+				v += u[k*dim+j] * u[k*dim+j]
+			}
+			sd = math.Max(sd, math.Sqrt(v))
+		}
+		if sd > 0 && !math.IsInf(sd, 0) {
+			floats.Scale(1/sd, u)
+			copy(cma.mean, warm.X)
+			cma.chol.SetFromU(mat.NewTriDense(dim, mat.Upper, u))
+			cma.resumed = true
+		}
+	}
+	cma.warm = nil
 	t := min(tasks, cma.pop)
 	return t
 }
 
+// cmaState is the state of CmaEsCholB at the end of a generation
+type cmaState struct {
+	Dim, Generation        int
+	Mean, PC, PS, BestX, U []float64
+	InvSigma, BestF, LastF float64
+	Src                    []byte
+}
+
+// Snapshot returns the state of the current or last run
+func (cma *CmaEsCholB) Snapshot() ([]byte, error) {
+	if cma.dim == 0 || cma.generation == 0 {
+		return nil, errNoState
+	}
+	src, err := marshalSource(cma.Src)
+	if err != nil {
+		return nil, err
+	}
+	var u mat.TriDense
+	cma.chol.UTo(&u)
+	state := &cmaState{Dim: cma.dim, Generation: cma.generation, Mean: cma.mean, PC: cma.pc, PS: cma.ps, BestX: cma.bestX,
+		U: make([]float64, 0, cma.dim*cma.dim), InvSigma: cma.invSigma, BestF: cma.bestF, LastF: cma.lastF, Src: src}
+	for i := 0; i < cma.dim; i++ {
+		for j := 0; j < cma.dim; j++ {
+			state.U = append(state.U, u.At(i, j))
+		}
+	}
+	return encodeState("cmaes", state)
+}
+
+// Restore sets the state from which the next run resumes, ignoring the
+// initial location. The limits of optimize.Settings don't include the
+// restored run.
+func (cma *CmaEsCholB) Restore(data []byte) error {
+	state := &cmaState{}
+	if err := decodeState("cmaes", data, state); err != nil {
+		return err
+	}
+	if state.Dim <= 0 || len(state.Mean) != state.Dim || len(state.U) != state.Dim*state.Dim {
+		return errors.New("optimize: invalid checkpoint")
+	}
+	cma.restored = state
+	return nil
+}
+
+// WarmStart primes the next run with the mean and the shape of the
+// covariance of prev, a Result of Minimize with Method "cmaes" or with the
+// Warm of WarmState. The step size is the initial one.
+func (cma *CmaEsCholB) WarmStart(prev *Result) error {
+	warm, err := warmState("cmaes", prev)
+	if err != nil {
+		return err
+	}
+	cma.warm = warm
+	return nil
+}
+
+// WarmState returns the state at the end of the last run, nil before the
+// first generation
+func (cma *CmaEsCholB) WarmState() *WarmStart {
+	if cma.dim == 0 || cma.generation == 0 {
+		return nil
+	}
+	var u mat.TriDense
+	cma.chol.UTo(&u)
+	warm := &WarmStart{Method: "cmaes", X: append([]float64(nil), cma.mean...), StepSize: 1 / cma.invSigma,
+		Cholesky: make([]float64, 0, cma.dim*cma.dim), Iter: cma.generation}
+	for i := 0; i < cma.dim; i++ {
+		for j := 0; j < cma.dim; j++ {
+			warm.Cholesky = append(warm.Cholesky, u.At(i, j))
+		}
+	}
+	return warm
+}
+
+// restore applies the restored state after Init
+func (cma *CmaEsCholB) restore(state *cmaState) error {
+	if state.Dim != cma.dim {
+		return fmt.Errorf("optimize: checkpoint of dimension %d, expected %d", state.Dim, cma.dim)
+	}
+	if err := unmarshalSource(cma.Src, state.Src); err != nil {
+		return err
+	}
+	copy(cma.mean, state.Mean)
+	copy(cma.pc, state.PC)
+	copy(cma.ps, state.PS)
+	copy(cma.bestX, state.BestX)
+	cma.chol.SetFromU(mat.NewTriDense(cma.dim, mat.Upper, state.U))
+	cma.invSigma, cma.bestF, cma.lastF, cma.generation = state.InvSigma, state.BestF, state.LastF, state.Generation
+	return nil
+}
+
+// resetChol sets the Cholesky to InitCholesky or I
+func (cma *CmaEsCholB) resetChol() {
+	if cma.InitCholesky != nil {
+		cma.chol.Clone(cma.InitCholesky)
+		return
+	}
+	// Set the initial Cholesky to I.
+	if cma.eye == nil || cma.eye.RawTriangular().N != cma.dim {
+		cma.eye = mat.NewTriDense(cma.dim, mat.Upper, nil)
+		for i := 0; i < cma.dim; i++ {
+			cma.eye.SetTri(i, i, 1)
+		}
+	}
+	if !cma.chol.IsEmpty() && symmetricDim(&cma.chol) != cma.dim {
+		cma.chol.Reset()
+	}
+	cma.chol.SetFromU(cma.eye)
+}
+
+// reuseDense returns m if it is r×c, else m resized to r×c, reusing its
+// storage if large enough
+func reuseDense(m *mat.Dense, r, c int) *mat.Dense {
+	if m == nil {
+		return mat.NewDense(r, c, nil)
+	}
+	if mr, mc := m.Dims(); mr != r || mc != c {
+		m.Reset()
+		m.ReuseAs(r, c)
+	}
+	return m
+}
+
+// floorVariances raises the diagonal of the covariance to at least v
+func (cma *CmaEsCholB) floorVariances(v float64) {
+	u := cma.chol.RawU()
+	e := mat.NewVecDense(cma.dim, nil)
+	for i := 0; i < cma.dim; i++ {
+		// C_ii is the squared norm of the column i of U
+		cii := 0.
+		for k := 0; k <= i; k++ {
+			cii += u.At(k, i) * u.At(k, i)
+		}
+		if cii < v {
+			e.SetVec(i, 1)
+			cma.chol.SymRankOne(&cma.chol, v-cii, e)
+			e.SetVec(i, 0)
+			u = cma.chol.RawU()
+		}
+	}
+}
+
+// EnvironmentChanged notifies a Tracking run that the objective has changed.
+// At the end of the current generation, the covariance, step size, evolution
+// paths and best point are reset. It is safe to call it from another goroutine.
+func (cma *CmaEsCholB) EnvironmentChanged() {
+	atomic.StoreInt32(&cma.changed, 1)
+}
+
+func (cma *CmaEsCholB) applyEnvironmentChange() {
+	if !atomic.CompareAndSwapInt32(&cma.changed, 1, 0) {
+		return
+	}
+	cma.invSigma = 1 / cma.InitStepSize
+	if cma.InitStepSize <= 0 {
+		cma.invSigma = 10.0 / 3
+	}
+	for i := range cma.pc {
+		cma.pc[i], cma.ps[i] = 0, 0
+	}
+	switch {
+	case cma.KeepCovarianceOnChange:
+	case cma.ChangeStepSize > 0:
+		d := mat.NewDiagDense(cma.dim, nil)
+		for i := 0; i < cma.dim; i++ {
+			d.SetDiag(i, cma.ChangeStepSize*cma.ChangeStepSize)
+		}
+		cma.chol.Factorize(d)
+	default:
+		cma.resetChol()
+	}
+	cma.bestF = math.Inf(1)
+	cma.stall = newStallWatch(cma.StallIterations, cma.StallTolerance)
+	if cma.Immigrants > 0 {
+		cma.immigrate = cma.ImmigrantGenerations
+		if cma.immigrate == 0 {
+			cma.immigrate = 1
+		}
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -244,49 +694,143 @@ func min(a, b int) int {
 }
 
 func (cma *CmaEsCholB) sendInitTasks(tasks []optimize.Task) {
+	if cma.CRN != nil {
+		cma.CRN.NextSeed()
+	}
+	if cma.initial {
+		cma.initial = false
+	} else {
+		cma.sample()
+	}
 	for i, task := range tasks {
 		cma.sendTask(i, task)
 	}
 	cma.sentIdx = len(tasks)
 }
 
+// ensureBounds moves x within Xmin, Xmax, checked by Validate to be empty or of
+// the dimension
 func (cma *CmaEsCholB) ensureBounds(x []float64) {
+	hasMin, hasMax := len(cma.Xmin) != 0, len(cma.Xmax) != 0
 	nBounded := 0
 	for i := range x {
-		if (i < len(cma.Xmin) && x[i] <= cma.Xmin[i]) || (i < len(cma.Xmax) && x[i] >= cma.Xmax[i]) {
+		if (hasMin && x[i] <= cma.Xmin[i]) || (hasMax && x[i] >= cma.Xmax[i]) {
 			nBounded++
 		}
 	}
 	for i := range x {
-		if i < len(cma.Xmin) && x[i] < cma.Xmin[i] {
+		if hasMin && x[i] < cma.Xmin[i] {
 			if nBounded < len(x) {
 				x[i] = cma.Xmin[i]
 			} else {
-				for x[i] < cma.Xmin[i] {
+				// halve the distance to the feasible mean, x may be the mean
+				for k := 0; k < 64 && x[i] < cma.Xmin[i]; k++ {
 					x[i] = (x[i] + cma.mean[i]) / 2
 				}
+				x[i] = math.Max(x[i], cma.Xmin[i])
 			}
 		}
-		if i < len(cma.Xmax) && x[i] > cma.Xmax[i] {
+		if hasMax && x[i] > cma.Xmax[i] {
 			if nBounded < len(x) {
 				x[i] = cma.Xmax[i]
 			} else {
-				for x[i] > cma.Xmax[i] {
+				for k := 0; k < 64 && x[i] > cma.Xmax[i]; k++ {
 					x[i] = (x[i] + cma.mean[i]) / 2
 				}
+				x[i] = math.Min(x[i], cma.Xmax[i])
 			}
 		}
 	}
+	if cma.Projection != nil {
+		cma.Projection.Project(x)
+	}
+}
+
+// repair applies Variables and Repair to a sample
+func (cma *CmaEsCholB) repair(x []float64) {
+	if cma.Variables != nil {
+		cma.Variables.Project(x)
+	}
+	if cma.Repair != nil {
+		cma.Repair.Repair(x)
+	}
 }
 
-// sendTask generates a sample and sends the task. It does not update the cma index.
+// sample draws the samples of a generation in the rows of xs as one
+// product Z U + mean, U being the upper Cholesky factor of the covariance,
+// instead of a distmv.NormalRand per sample. The normal numbers are drawn
+// in the same order, so the samples are the ones of distmv.NormalRand.
+func (cma *CmaEsCholB) sample() {
+	z := cma.zs.RawMatrix().Data
+	if cma.Src == nil {
+		for i := range z {
+			z[i] = rand.NormFloat64()
+		}
+	} else {
+		rnd := rand.New(cma.Src)
+		for i := range z {
+			z[i] = rnd.NormFloat64()
+		}
+	}
+	cma.xs.Mul(cma.zs, cma.chol.RawU())
+	for i := 0; i < cma.pop; i++ {
+		floats.Add(cma.xs.RawRowView(i), cma.mean)
+	}
+	if cma.immigrate > 0 {
+		cma.immigrate--
+		cma.immigrants()
+	}
+}
+
+// immigrants replaces the last Immigrants samples by random points
+func (cma *CmaEsCholB) immigrants() {
+	rnd := newRand(cma.Src)
+	sd := cma.ChangeStepSize
+	if sd <= 0 {
+		sd = cma.InitStepSize
+	}
+	if sd <= 0 {
+		sd = .3
+	}
+	for k := max(cma.pop-cma.Immigrants, 0); k < cma.pop; k++ {
+		x := cma.xs.RawRowView(k)
+		for i := range x {
+			if len(cma.Xmin) > 0 && len(cma.Xmax) > 0 && !math.IsInf(cma.Xmin[i], 0) && !math.IsInf(cma.Xmax[i], 0) {
+				x[i] = cma.Xmin[i] + rnd.Float64()*(cma.Xmax[i]-cma.Xmin[i])
+			} else {
+				x[i] = cma.mean[i] + sd*rnd.NormFloat64()
+			}
+		}
+	}
+}
+
+// initialize stores the points of Initializer in xs, the first generation
+func (cma *CmaEsCholB) initialize() error {
+	xs, err := cma.Initializer.Init(cma.pop, cma.mean, cma.Xmin, cma.Xmax, newRand(cma.Src))
+	if err != nil {
+		return err
+	}
+	if len(xs) != cma.pop {
+		return errors.New("optimize: cma-es-chol: Initializer returned a wrong number of points")
+	}
+	for i, x := range xs {
+		if len(x) != cma.dim {
+			return errors.New("optimize: cma-es-chol: Initializer returned a point of another dimension")
+		}
+		copy(cma.xs.RawRowView(i), x)
+	}
+	cma.initial = true
+	return nil
+}
+
+// sendTask sends the task of the sample idx. It does not update the cma index.
 // this method differs of original cmaes in using ensureBounds
 func (cma *CmaEsCholB) sendTask(idx int, task optimize.Task) {
 	task.ID = idx
 	task.Op = optimize.FuncEvaluation
-	distmv.NormalRand(cma.xs.RawRowView(idx), cma.mean, &cma.chol, cma.Src)
 	cma.ensureBounds(cma.xs.RawRowView(idx))
 	copy(task.X, cma.xs.RawRowView(idx))
+	cma.repair(task.X)
 	cma.operation <- task
 }
 
@@ -331,17 +875,47 @@ func (cma *CmaEsCholB) findBestAndUpdateTask(task optimize.Task) optimize.Task {
 		task.F = cma.bestF
 		copy(task.X, cma.bestX)
 	}
+	// the evaluated point
+	cma.repair(task.X)
+	cma.lastF = task.F
+	cma.stall.observe(task.F)
+	cma.stalled = cma.stall.stalled(cma.generation)
+	cma.generation++
 	return task
 }
 
 // Run ...
 func (cma *CmaEsCholB) Run(operations chan<- optimize.Task, results <-chan optimize.Task, tasks []optimize.Task) {
-	copy(cma.mean, tasks[0].X)
+	if !cma.resumed && cma.updateErr == nil {
+		// the distribution is centered on the projection of an infeasible
+		// start, a non-finite one is an error
+		mean, note, err := feasibleStart(tasks[0].X, cma.Xmin, cma.Xmax, nil)
+		if cma.updateErr = err; note != "" {
+			cma.warnings = append(cma.warnings, note)
+		}
+		copy(cma.mean, mean)
+		if cma.Initializer != nil && cma.updateErr == nil {
+			cma.updateErr = cma.initialize()
+		}
+	}
+	if cma.updateErr != nil {
+		// invalid settings: stop at once, Status reports the error
+		operations <- optimize.Task{Op: optimize.MethodDone, Location: tasks[0].Location}
+		for range results {
+		}
+		close(operations)
+		return
+	}
 	cma.operation = operations
+	cma.mon = newMonitor(nil, cma.Observer, "cmaes", tasks[0].X)
 	// Send the initial tasks. We know there are at most as many tasks as elements
 	// of the population.
 	cma.sendInitTasks(tasks)
 
+	// kept is the number of samples of the last generation considered after
+	// the stop, the ones a serial run would have evaluated, so that the result
+	// doesn't depend on Settings.Concurrent
+	kept := 0
 Loop:
 	for {
 		result := <-results
@@ -349,14 +923,24 @@ Loop:
 		default:
 			panic("unknown operation")
 		case optimize.PostIteration:
+			// the evaluation which triggered the stop is sent after PostIteration
+			kept = cma.receivedIdx + 1
 			break Loop
 		case optimize.MajorIteration:
+			if cma.methodConverged() == optimize.FunctionThreshold {
+				// the major iteration reaching TargetF has been recorded
+				result.Op = optimize.MethodDone
+				operations <- result
+				continue Loop
+			}
 			// The last thing we did was update all of the tasks and send the
 			// major iteration. Now we can send a group of tasks again.
 			cma.sendInitTasks(tasks)
 		case optimize.FuncEvaluation:
 			cma.receivedIdx++
+			cma.nfev++
 			cma.fs[result.ID] = result.F
+			cma.mon.evaluated(result.X, result.F)
 			switch {
 			case cma.sentIdx < cma.pop:
 				// There are still tasks to evaluate. Send the next.
@@ -377,16 +961,21 @@ Loop:
 				task := cma.findBestAndUpdateTask(result)
 				// Update the parameters and send a MajorIteration or a convergence.
 				err := cma.update()
+				cma.mon.iterate(cma.generation, 0, task.X, func() map[string]float64 {
+					return map[string]float64{"sigma": 1 / cma.invSigma, "logdet": cma.chol.LogDet(), "cond": cma.condition()}
+				})
 				// Kill the existing data.
 				for i := range cma.fs {
 					cma.fs[i] = math.NaN()
 					cma.xs.Set(i, 0, math.NaN())
 				}
+				// FunctionThreshold stops after the major iteration is recorded
+				status := cma.methodConverged()
 				switch {
 				case err != nil:
 					cma.updateErr = err
 					task.Op = optimize.MethodDone
-				case cma.methodConverged() != optimize.NotTerminated:
+				case status != optimize.NotTerminated && status != optimize.FunctionThreshold:
 					task.Op = optimize.MethodDone
 				default:
 					task.Op = optimize.MajorIteration
@@ -409,6 +998,9 @@ Loop:
 			panic("unknown operation")
 		}
 	}
+	for i := kept; i < len(cma.fs); i++ {
+		cma.fs[i] = math.NaN()
+	}
 	// Send the new best value if the evaluation is better than any we've
 	// found so far. Keep this separate from findBestAndUpdateTask so that
 	// we only send an iteration if we find a better location.
@@ -418,27 +1010,65 @@ Loop:
 			task := tasks[0]
 			task.F = cma.fs[best]
 			copy(task.X, cma.xs.RawRowView(best))
+			cma.repair(task.X)
 			task.Op = optimize.MajorIteration
 			task.ID = -1
 			operations <- task
 		}
 	}
+	status, _ := cma.Status()
+	res := &Result{X: append([]float64(nil), cma.bestX...), F: cma.bestF, Status: status, Reason: cma.Reason(), NIter: cma.generation, NFev: cma.mon.nfev + cma.rankNFev}
+	cma.mon.done(res.done(cma.mon.start))
 	close(operations)
 }
 
 // update computes the new parameters (mean, cholesky, etc.). Does not update
 // any of the synchronization parameters (taskIdx).
 func (cma *CmaEsCholB) update() error {
+	ws := getWorkspace()
+	defer putWorkspace(ws)
 	// Sort the function values to find the elite samples.
-	ftmp := make([]float64, cma.pop)
+	ftmp := ws.floats(cma.pop)
 	copy(ftmp, cma.fs)
-	indexes := make([]int, cma.pop)
+	indexes := ws.ints(cma.pop)
 	for i := range indexes {
 		indexes[i] = i
 	}
-	sort.Sort(bestSorter{F: ftmp, Idx: indexes})
+	if cma.Ranker != nil {
+		rows := make([][]float64, cma.pop)
+		for i := range rows {
+			rows[i] = cma.xs.RawRowView(i)
+		}
+		n0 := evaluationsOf(cma.Ranker)
+		indexes = cma.Ranker.Rank(rows, cma.fs, len(cma.weights))
+		cma.rankNFev += evaluationsOf(cma.Ranker) - n0
+	} else if cma.Comparator != nil {
+		// ranking by wins is a total order, unlike a sort with Compare
+		wins := ws.ints(cma.pop)
+		cc := countingComparator{cmp: cma.Comparator}
+		for i := 0; i < cma.pop; i++ {
+			for j := i + 1; j < cma.pop; j++ {
+				switch cc.Compare(cma.xs.RawRowView(i), cma.xs.RawRowView(j)) {
+				case -1:
+					wins[i]++
+				case 1:
+					wins[j]++
+				}
+			}
+		}
+		cma.rankNFev += cc.n
+		sort.SliceStable(indexes, func(a, b int) bool {
+			i, j := indexes[a], indexes[b]
+			if wins[i] != wins[j] {
+				return wins[i] > wins[j]
+			}
+			return cma.fs[i] < cma.fs[j]
+		})
+	} else {
+		sort.Sort(bestSorter{F: ftmp, Idx: indexes})
+	}
 
-	meanOld := make([]float64, len(cma.mean))
+	meanOld := ws.floats(len(cma.mean))
 	copy(meanOld, cma.mean)
 
 	// m_{t+1} = \sum_{i=1}^mu w_i x_i
@@ -450,7 +1080,7 @@ func (cma *CmaEsCholB) update() error {
 		floats.AddScaled(cma.mean, w, cma.xs.RawRowView(idx))
 	}
 	cma.ensureBounds(cma.mean)
-	meanDiff := make([]float64, len(cma.mean))
+	meanDiff := ws.floats(len(cma.mean))
 	floats.SubTo(meanDiff, cma.mean, meanOld)
 
 	// p_{c,t+1} = (1-c_c) p_{c,t} + \sqrt(c_c*(2-c_c)*mueff) (m_{t+1}-m_t)/sigma_t
@@ -461,7 +1091,7 @@ func (cma *CmaEsCholB) update() error {
 	// p_{sigma, t+1} = (1-c_sigma) p_{sigma,t} + \sqrt(c_s*(2-c_s)*mueff) A_t^-1 (m_{t+1}-m_t)/sigma_t
 	floats.Scale(1-cma.cs, cma.ps)
 	// First compute A_t^-1 (m_{t+1}-m_t), then add the scaled vector.
-	tmp := make([]float64, cma.dim)
+	tmp := ws.floats(cma.dim)
 	tmpVec := mat.NewVecDense(cma.dim, tmp)
 	diffVec := mat.NewVecDense(cma.dim, meanDiff)
 	err := tmpVec.SolveVec(cma.chol.RawU().T(), diffVec)
@@ -476,20 +1106,53 @@ func (cma *CmaEsCholB) update() error {
 	if scaleChol == 0 {
 		scaleChol = math.SmallestNonzeroFloat64 // enough to kill the old data, but still non-zero.
 	}
-	cma.chol.Scale(scaleChol, &cma.chol)
-	cma.chol.SymRankOne(&cma.chol, cma.c1, mat.NewVecDense(cma.dim, cma.pc))
-	for i, w := range cma.weights {
-		idx := indexes[i]
-		floats.SubTo(tmp, cma.xs.RawRowView(idx), meanOld)
-		cma.chol.SymRankOne(&cma.chol, cma.cmu*w*cma.invSigma, tmpVec)
+	if !cma.updateCovariance(indexes, meanOld, scaleChol, ws) {
+		cma.chol.Scale(scaleChol, &cma.chol)
+		cma.chol.SymRankOne(&cma.chol, cma.c1, mat.NewVecDense(cma.dim, cma.pc))
+		for i, w := range cma.weights {
+			idx := indexes[i]
+			floats.SubTo(tmp, cma.xs.RawRowView(idx), meanOld)
+			cma.chol.SymRankOne(&cma.chol, cma.cmu*w*cma.invSigma, tmpVec)
+		}
 	}
 
 	// sigma_{t+1} = sigma_t exp(c_sigma/d_sigma * norm(p_{sigma,t+1}/ E[chi] -1)
 	normPs := floats.Norm(cma.ps, 2)
 	cma.invSigma /= math.Exp(cma.cs / cma.ds * (normPs/cma.eChi - 1))
+	if cma.MinStepSize > 0 {
+		cma.floorVariances(cma.MinStepSize * cma.MinStepSize)
+	}
+	if mc := cma.maxCondition(); cma.RepairCondition && cma.condition() > mc {
+		cma.clipEigenvalues(mc)
+	}
+	cma.applyEnvironmentChange()
 	return nil
 }
 
+// updateCovariance computes the update of the covariance as one blocked
+// rank-mu product and a factorization instead of mu rank one updates of the
+// Cholesky factor, faster for large populations. It returns false, leaving
+// the factor unchanged, if the updated covariance is not numerically
+// positive definite.
+func (cma *CmaEsCholB) updateCovariance(indexes []int, meanOld []float64, scaleChol float64, ws *workspace) bool {
+	// the weighted deviations of the elite samples, C += Y^T Y
+	y := mat.NewDense(len(cma.weights), cma.dim, ws.floats(len(cma.weights)*cma.dim))
+	for i, w := range cma.weights {
+		row := y.RawRowView(i)
+		floats.SubTo(row, cma.xs.RawRowView(indexes[i]), meanOld)
+		floats.Scale(math.Sqrt(cma.cmu*w*cma.invSigma), row)
+	}
+	cma.chol.ToSym(&cma.cov)
+	cma.cov.ScaleSym(scaleChol, &cma.cov)
+	cma.cov.SymRankOne(&cma.cov, cma.c1, mat.NewVecDense(cma.dim, cma.pc))
+	cma.cov.SymRankK(&cma.cov, 1, y.T())
+	if !cma.cholTmp.Factorize(&cma.cov) {
+		return false
+	}
+	cma.chol, cma.cholTmp = cma.cholTmp, cma.chol
+	return true
+}
+
 type bestSorter struct {
 	F   []float64
 	Idx []int
@@ -3,10 +3,15 @@ package optimize
 import (
 	"fmt"
 	"math"
+	"runtime"
+	"testing"
 
 	"golang.org/x/exp/rand"
 
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distmv"
 )
 
 func ExampleCmaEsCholB() {
@@ -31,3 +36,339 @@ func ExampleCmaEsCholB() {
 	}
 	// Output:
 }
+
+func ExampleCmaEsCholB_EnvironmentChanged() {
+	method := &CmaEsCholB{Tracking: true, MinStepSize: 1e-3, Src: rand.NewSource(1)}
+	center := []float64{1, 1}
+	evals := 0
+	var last []float64
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			evals++
+			if evals == 1500 {
+				// the optimum moves
+				center[0], center[1] = -2, 3
+				method.EnvironmentChanged()
+			}
+			last = append(last[:0], x...)
+			return (x[0]-center[0])*(x[0]-center[0]) + (x[1]-center[1])*(x[1]-center[1])
+		},
+	}
+	settings := &optimize.Settings{FuncEvaluations: 3000, Concurrent: 1, Converger: optimize.NeverTerminate{}}
+	// the best location of the Result may belong to the previous environment
+	_, err := optimize.Minimize(problem, []float64{0, 0}, settings, method)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.1f\n", last)
+	// Output:
+	// [-2.0 3.0]
+}
+
+func TestCmaEsCholBMinStepSize(t *testing.T) {
+	// with the learned covariance kept, the step size floor alone lets the run follow the optimum
+	method := &CmaEsCholB{Tracking: true, MinStepSize: .05, KeepCovarianceOnChange: true, Src: rand.NewSource(1)}
+	center := []float64{1, 1}
+	evals := 0
+	var last []float64
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			evals++
+			if evals == 1500 {
+				center[0], center[1] = -2, 3
+				method.EnvironmentChanged()
+			}
+			last = append(last[:0], x...)
+			return (x[0]-center[0])*(x[0]-center[0]) + (x[1]-center[1])*(x[1]-center[1])
+		},
+	}
+	settings := &optimize.Settings{FuncEvaluations: 3000, Concurrent: 1, Converger: optimize.NeverTerminate{}}
+	if _, err := optimize.Minimize(problem, []float64{0, 0}, settings, method); err != nil {
+		t.Fatal(err)
+	}
+	if d := math.Hypot(last[0]+2, last[1]-3); d > .5 {
+		t.Errorf("expected to track the optimum, got %v", last)
+	}
+}
+
+func TestCmaEsCholBImmigrants(t *testing.T) {
+	// after the change, the old optimum is a local minimum far from the new
+	// one: the restored step size alone does not leave it
+	for _, immigrants := range []int{0, 3} {
+		method := &CmaEsCholB{Xmin: []float64{0, 0}, Xmax: []float64{10, 10}, Tracking: true, MinStepSize: 1e-3,
+			Immigrants: immigrants, ImmigrantGenerations: 5, Src: rand.NewSource(1)}
+		evals := 0
+		var last []float64
+		problem := optimize.Problem{
+			Func: func(x []float64) float64 {
+				evals++
+				if evals == 1500 {
+					method.EnvironmentChanged()
+				}
+				last = append(last[:0], x...)
+				old := (x[0]-1)*(x[0]-1) + (x[1]-1)*(x[1]-1)
+				if evals < 1500 {
+					return old
+				}
+				return math.Min(old+1, ((x[0]-8)*(x[0]-8)+(x[1]-8)*(x[1]-8))/4)
+			},
+		}
+		settings := &optimize.Settings{FuncEvaluations: 3000, Concurrent: 1, Converger: optimize.NeverTerminate{}}
+		if _, err := optimize.Minimize(problem, []float64{5, 5}, settings, method); err != nil {
+			t.Fatal(err)
+		}
+		if d := math.Hypot(last[0]-8, last[1]-8); (immigrants > 0) != (d < .5) {
+			t.Errorf("%d immigrants: ended at %v", immigrants, last)
+		}
+	}
+	if err := (&CmaEsCholB{Immigrants: -1}).Validate(2); err == nil {
+		t.Error("expected an error for negative Immigrants")
+	}
+}
+
+func TestCmaEsCholBValidate(t *testing.T) {
+	var chol mat.Cholesky
+	chol.Factorize(mat.NewSymDense(3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}))
+	for _, c := range []struct {
+		cma *CmaEsCholB
+		err string
+	}{
+		{&CmaEsCholB{Population: -1}, "optimize: cma-es-chol: negative population size"},
+		{&CmaEsCholB{InitStepSize: -1}, "optimize: cma-es-chol: invalid initial step size"},
+		{&CmaEsCholB{InitStepSize: math.NaN()}, "optimize: cma-es-chol: invalid initial step size"},
+		{&CmaEsCholB{InitCholesky: &chol}, "optimize: cma-es-chol: InitCholesky size 3, expected 2"},
+		{&CmaEsCholB{MaxCondition: -1}, "optimize: cma-es-chol: negative MaxCondition"},
+		{&CmaEsCholB{Xmin: []float64{0}}, "optimize: len(Xmin) = 1, expected 2"},
+		{&CmaEsCholB{Xmin: []float64{0, 1}, Xmax: []float64{1, 0}}, "optimize: Xmin[1] = 1 > Xmax[1] = 0"},
+	} {
+		if err := c.cma.Validate(2); err == nil || err.Error() != c.err {
+			t.Errorf("Validate: got %v, expected %s", err, c.err)
+		}
+		_, err := optimize.Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, nil, c.cma)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("Minimize: got %v, expected %s", err, c.err)
+		}
+	}
+	if err := (&CmaEsCholB{}).Validate(0); err == nil {
+		t.Error("expected an error for dimension 0")
+	}
+}
+
+func TestCmaEsCholBConcurrent(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	problem := optimize.Problem{Func: rosen}
+	x0 := []float64{-1.2, 1, .5}
+	for _, settings := range []optimize.Settings{{FuncEvaluations: 29}, {FuncEvaluations: 45}, {FuncEvaluations: 72}, {MajorIterations: 20}} {
+		var want *optimize.Result
+		for _, procs := range []int{1, 4} {
+			runtime.GOMAXPROCS(procs)
+			for _, concurrent := range []int{1, 2, 3, 8} {
+				s := settings
+				s.Concurrent = concurrent
+				res, err := optimize.Minimize(problem, x0, &s, &CmaEsCholB{Src: rand.NewSource(1)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want == nil {
+					want = res
+				} else if res.F != want.F || !floats.Equal(res.X, want.X) {
+					t.Errorf("%+v GOMAXPROCS=%d: got %g at %g, expected %g at %g", s, procs, res.F, res.X, want.F, want.X)
+				}
+			}
+		}
+	}
+}
+
+func TestCmaEsCholBSample(t *testing.T) {
+	const dim = 5
+	a := mat.NewDense(dim, dim, nil)
+	rnd := rand.New(rand.NewSource(2))
+	for i := range a.RawMatrix().Data {
+		a.RawMatrix().Data[i] = rnd.NormFloat64()
+	}
+	var cov mat.SymDense
+	cov.SymOuterK(1, a)
+	var chol mat.Cholesky
+	if !chol.Factorize(&cov) {
+		t.Fatal("covariance not positive definite")
+	}
+	cma := &CmaEsCholB{InitCholesky: &chol, Population: 12, Src: rand.NewSource(3)}
+	cma.Init(dim, 1)
+	for i := range cma.mean {
+		cma.mean[i] = float64(i)
+	}
+	cma.sample()
+	src := rand.NewSource(3)
+	want := make([]float64, dim)
+	for i := 0; i < cma.pop; i++ {
+		distmv.NormalRand(want, cma.mean, &chol, src)
+		if !floats.EqualApprox(cma.xs.RawRowView(i), want, 1e-12) {
+			t.Errorf("sample %d: got %g, expected %g", i, cma.xs.RawRowView(i), want)
+		}
+	}
+}
+
+func BenchmarkCmaEsCholBSample(b *testing.B) {
+	for _, dim := range []int{10, 100} {
+		b.Run(fmt.Sprint(dim), func(b *testing.B) {
+			cma := &CmaEsCholB{Population: 4 * dim, Src: rand.NewSource(1)}
+			cma.Init(dim, 1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cma.sample()
+			}
+		})
+	}
+}
+
+func TestCmaEsCholBUpdateCovariance(t *testing.T) {
+	const dim = 6
+	cma := &CmaEsCholB{Population: 20, Src: rand.NewSource(4)}
+	cma.Init(dim, 1)
+	for i := range cma.pc {
+		cma.pc[i] = float64(i) / dim
+	}
+	cma.sample()
+	indexes := make([]int, cma.pop)
+	for i := range indexes {
+		indexes[i] = cma.pop - 1 - i
+	}
+	meanOld := append([]float64(nil), cma.mean...)
+	meanOld[0] += .1
+	scale := 1 - cma.c1 - cma.cmu
+
+	// the rank one updates of the Cholesky factor
+	var want mat.Cholesky
+	want.Scale(scale, &cma.chol)
+	want.SymRankOne(&want, cma.c1, mat.NewVecDense(dim, cma.pc))
+	tmp := make([]float64, dim)
+	for i, w := range cma.weights {
+		floats.SubTo(tmp, cma.xs.RawRowView(indexes[i]), meanOld)
+		want.SymRankOne(&want, cma.cmu*w*cma.invSigma, mat.NewVecDense(dim, tmp))
+	}
+	ws := getWorkspace()
+	defer putWorkspace(ws)
+	if !cma.updateCovariance(indexes, meanOld, scale, ws) {
+		t.Fatal("covariance not positive definite")
+	}
+	var got, expected mat.SymDense
+	cma.chol.ToSym(&got)
+	want.ToSym(&expected)
+	if !mat.EqualApprox(&got, &expected, 1e-12) {
+		t.Errorf("got\n%v\nexpected\n%v", mat.Formatted(&got), mat.Formatted(&expected))
+	}
+}
+
+func BenchmarkCmaEsCholBUpdate(b *testing.B) {
+	for _, dim := range []int{10, 100} {
+		b.Run(fmt.Sprint(dim), func(b *testing.B) {
+			cma := &CmaEsCholB{Population: 4 * dim, Src: rand.NewSource(1)}
+			cma.Init(dim, 1)
+			cma.sample()
+			for i := range cma.fs {
+				cma.fs[i] = float64(i)
+			}
+			mean := append([]float64(nil), cma.mean...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// the same generation from the same state
+				cma.resetChol()
+				cma.invSigma = 1
+				copy(cma.mean, mean)
+				for j := range cma.pc {
+					cma.pc[j], cma.ps[j] = 0, 0
+				}
+				if err := cma.update(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCmaEsCholBReuse(t *testing.T) {
+	cma := &CmaEsCholB{Src: rand.NewSource(1)}
+	problem := optimize.Problem{Func: rosen}
+	settings := &optimize.Settings{MajorIterations: 5}
+	var want *optimize.Result
+	var data *float64
+	for k := 0; k < 3; k++ {
+		res, err := optimize.Minimize(problem, []float64{-1.2, 1, .5}, settings, cma)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k > 0 && &cma.xs.RawMatrix().Data[0] != data {
+			t.Errorf("run %d: the samples are reallocated", k)
+		}
+		data = &cma.xs.RawMatrix().Data[0]
+		if k == 0 {
+			want = res
+		}
+		cma.Src = rand.NewSource(1)
+	}
+	// the same run from the same source
+	res, _ := optimize.Minimize(problem, []float64{-1.2, 1, .5}, settings, cma)
+	if res.F != want.F || !floats.Equal(res.X, want.X) {
+		t.Errorf("got %g at %g, expected %g at %g", res.F, res.X, want.F, want.X)
+	}
+	// other dimensions
+	for _, x0 := range [][]float64{{-1.2, 1}, {-1.2, 1, .5, .5, .5, .5}, {-1.2, 1, .5}} {
+		if _, err := optimize.Minimize(problem, x0, settings, cma); err != nil {
+			t.Fatal(err)
+		}
+		if r, c := cma.xs.Dims(); r != cma.pop || c != len(x0) || symmetricDim(&cma.chol) != len(x0) {
+			t.Errorf("dim %d: xs is %d×%d, the covariance %d", len(x0), r, c, symmetricDim(&cma.chol))
+		}
+	}
+
+	if raceEnabled {
+		t.Skip("allocations are not counted with the race detector")
+	}
+	// the remaining allocations are the stall watch and the workspaces of gonum
+	if allocs := testing.AllocsPerRun(10, func() { cma.Init(3, 1) }); allocs > 3 {
+		t.Errorf("Init of the same dimension allocates %g times", allocs)
+	}
+}
+
+func TestCmaEsCholBConditionCov(t *testing.T) {
+	// an ellipsoid of condition number 1e12
+	problem := optimize.Problem{Func: func(x []float64) float64 {
+		s := 0.
+		for i, xi := range x {
+			s += math.Pow(1e12, float64(i)/float64(len(x)-1)) * xi * xi
+		}
+		return s
+	}}
+	x0 := []float64{1, 1, 1, 1}
+	settings := &optimize.Settings{FuncEvaluations: 20000}
+	cma := &CmaEsCholB{MaxCondition: 1e4, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(problem, x0, settings, cma)
+	if err != nil || res.Status != ConditionCov || !res.Status.Early() || cma.condition() <= 1e4 {
+		t.Errorf("got %s %v, condition %g", res.Status, err, cma.condition())
+	}
+	stopped := res.FuncEvaluations
+
+	maxCond := 0.
+	cma = &CmaEsCholB{MaxCondition: 1e4, RepairCondition: true, Src: rand.NewSource(1), Observer: ObserverFuncs{
+		Iteration: func(it *Iteration) { maxCond = math.Max(maxCond, it.Diagnostics["cond"]) },
+	}}
+	res, err = optimize.Minimize(problem, x0, settings, cma)
+	if err != nil || res.Status == ConditionCov || res.FuncEvaluations <= stopped || maxCond > 1e4*(1+1e-9) {
+		t.Errorf("got %s %v, max condition %g", res.Status, err, maxCond)
+	}
+
+	// diag(1, 1e-8) is clipped to diag(1, 1e-4)
+	cma = &CmaEsCholB{dim: 2}
+	cma.chol.Factorize(mat.NewSymDense(2, []float64{1, 0, 0, 1e-8}))
+	if c := cma.condition(); math.Abs(c-1e8) > 1e-4 {
+		t.Errorf("condition %g, expected 1e8", c)
+	}
+	if !cma.clipEigenvalues(1e4) {
+		t.Fatal("clipEigenvalues failed")
+	}
+	var cov mat.SymDense
+	cma.chol.ToSym(&cov)
+	if !mat.EqualApprox(&cov, mat.NewSymDense(2, []float64{1, 0, 0, 1e-4}), 1e-12) {
+		t.Errorf("unexpected covariance %v", mat.Formatted(&cov))
+	}
+}
@@ -0,0 +1,311 @@
+// Command optimize minimizes a function with the methods of
+// github.com/pa-m/optimize and writes the result as JSON.
+//
+// The function is a benchmark of the testfunctions package (-problem), in
+// its search domain unless -xmin or -xmax is given, the Func symbol of a Go
+// plugin (-plugin), or an external command (-cmd) reading points on its
+// standard input, one per line with space separated components, and writing
+// their values on its standard output, one per line.
+//
+//	optimize -problem rosenbrock -dim 4 -method cmaes -maxfev 5000
+//	optimize -cmd ./simulate -x0 1,2 -xmin 0,0 -xmax 10,10 -trace trace.jsonl
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"plugin"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+
+	opt "github.com/pa-m/optimize"
+	"github.com/pa-m/optimize/testfunctions"
+)
+
+// checkDim returns a usage error if dim is not a dimension of fn
+func checkDim(fn *testfunctions.Function, dim int) error {
+	switch {
+	case fn.Dim != 0 && dim != fn.Dim:
+		return fmt.Errorf("dimension %d, expected %d", dim, fn.Dim)
+	case !fn.ValidDim(dim) || dim < 1:
+		return fmt.Errorf("dimension %d, expected at least %d", dim, max(fn.MinDim, 1))
+	}
+	return nil
+}
+
+// start returns the default starting point of fn in dimension dim, at a
+// quarter of the search domain from its lower bounds
+func start(fn *testfunctions.Function, dim int) []float64 {
+	xmin, xmax := fn.Bounds(dim)
+	for i := range xmin {
+		xmin[i] += (xmax[i] - xmin[i]) / 4
+	}
+	return xmin
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "optimize:", err)
+		os.Exit(1)
+	}
+}
+
+type output struct {
+	X []float64 `json:"x"`
+	F *float64  `json:"f"`
+	// FOpt is the known global minimum of a -problem
+	FOpt    *float64 `json:"fopt,omitempty"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	NIter   int      `json:"nit"`
+	NFev    int      `json:"nfev"`
+	NGrad   int      `json:"njev"`
+	Elapsed float64  `json:"elapsed"`
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("optimize", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		problem  = fs.String("problem", "", "built-in benchmark of the testfunctions package: "+strings.Join(benchmarkNames(), ", "))
+		plug     = fs.String("plugin", "", "Go plugin exporting Func func([]float64) float64")
+		command  = fs.String("cmd", "", "external command evaluating the points of its stdin, run by sh -c")
+		dim      = fs.Int("dim", 2, "dimension of the benchmarks of any dimension")
+		x0s      = fs.String("x0", "", "comma separated starting point")
+		xmins    = fs.String("xmin", "", "comma separated lower bounds, the search domain of -problem by default")
+		xmaxs    = fs.String("xmax", "", "comma separated upper bounds, the search domain of -problem by default")
+		method   = fs.String("method", "powell", "method: "+strings.Join(opt.Methods, ", "))
+		maxIter  = fs.Int("maxiter", 0, "maximum number of iterations")
+		maxFev   = fs.Int("maxfev", 0, "maximum number of evaluations")
+		seed     = fs.Uint64("seed", 1, "seed of stochastic methods")
+		trace    = fs.String("trace", "", "file receiving a JSON Lines trace of the iterations, - for stderr")
+		traceX   = fs.Bool("tracex", false, "add the iterates to the trace")
+		indented = fs.Bool("indent", false, "indent the JSON result")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	x0, err := parseFloats(*x0s)
+	if err != nil {
+		return fmt.Errorf("-x0: %v", err)
+	}
+	opts := &opt.Options{Method: *method, MaxIter: *maxIter, MaxFev: *maxFev, Src: rand.NewSource(*seed)}
+	if opts.Xmin, err = parseFloats(*xmins); err != nil {
+		return fmt.Errorf("-xmin: %v", err)
+	}
+	if opts.Xmax, err = parseFloats(*xmaxs); err != nil {
+		return fmt.Errorf("-xmax: %v", err)
+	}
+
+	var (
+		f    func([]float64) float64
+		grad func(grad, x []float64)
+		ext  *external
+		fopt *float64
+	)
+	switch {
+	case *problem != "":
+		fn, ok := testfunctions.ByName(strings.ToLower(*problem))
+		if !ok {
+			return fmt.Errorf("unknown problem %s, expected one of %s", *problem, strings.Join(benchmarkNames(), ", "))
+		}
+		f, grad = fn.Func, fn.Grad
+		if x0 == nil {
+			n := *dim
+			if fn.Dim != 0 && !isSet(fs, "dim") {
+				n = fn.Dim
+			}
+			if err := checkDim(&fn, n); err != nil {
+				return fmt.Errorf("-dim: %v", err)
+			}
+			x0 = start(&fn, n)
+		}
+		if err := checkDim(&fn, len(x0)); err != nil {
+			return fmt.Errorf("-problem %s: %v", *problem, err)
+		}
+		xmin, xmax := fn.Bounds(len(x0))
+		if opts.Xmin == nil {
+			opts.Xmin = xmin
+		}
+		if opts.Xmax == nil {
+			opts.Xmax = xmax
+		}
+		_, fo := fn.Minimum(len(x0))
+		fopt = &fo
+	case *plug != "":
+		if f, err = loadPlugin(*plug); err != nil {
+			return err
+		}
+	case *command != "":
+		if ext, err = startCommand(*command, stderr); err != nil {
+			return err
+		}
+		defer ext.close()
+		f = ext.eval
+	default:
+		return errors.New("one of -problem, -plugin or -cmd is required")
+	}
+	if x0 == nil {
+		return errors.New("-x0 is required")
+	}
+
+	if *trace != "" {
+		w := stderr
+		if *trace != "-" {
+			file, err := os.Create(*trace)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		tr := opt.NewTraceRecorder(w, opt.TraceJSONL)
+		tr.X = *traceX
+		opts.Observer = tr
+	}
+	res, err := opt.Minimize(optimize.Problem{Func: f, Grad: grad}, x0, opts)
+	if ext != nil && ext.err != nil {
+		err = fmt.Errorf("-cmd: %v", ext.err)
+	}
+	if res == nil {
+		return err
+	}
+	out := output{X: res.X, Status: res.Status.String(), Message: res.Message,
+		NIter: res.NIter, NFev: res.NFev, NGrad: res.NGrad, Elapsed: res.Elapsed.Seconds(), FOpt: fopt}
+	if !math.IsNaN(res.F) && !math.IsInf(res.F, 0) {
+		out.F = &res.F
+	}
+	enc := json.NewEncoder(stdout)
+	if *indented {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return err
+}
+
+// benchmarkNames returns the names of the functions of testfunctions.All,
+// sorted
+func benchmarkNames() []string {
+	var names []string
+	for _, fn := range testfunctions.All() {
+		names = append(names, fn.Name)
+	}
+	return names
+}
+
+// isSet tells whether the flag name was given on the command line
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
+// parseFloats parses comma separated values, nil for an empty string
+func parseFloats(s string) ([]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	fields := strings.Split(s, ",")
+	x := make([]float64, len(fields))
+	for i, field := range fields {
+		var err error
+		if x[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+// loadPlugin returns the Func symbol of the plugin at path
+func loadPlugin(path string) (func([]float64) float64, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Func")
+	if err != nil {
+		return nil, err
+	}
+	switch f := sym.(type) {
+	case func([]float64) float64:
+		return f, nil
+	case *func([]float64) float64:
+		return *f, nil
+	}
+	return nil, fmt.Errorf("%s: Func is a %T, expected a func([]float64) float64", path, sym)
+}
+
+// external evaluates points with a long running command
+type external struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Scanner
+	err error
+}
+
+func startCommand(command string, stderr io.Writer) (*external, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &external{cmd: cmd, in: in, out: bufio.NewScanner(out)}, nil
+}
+
+// eval writes x and reads its value. Errors give NaN values.
+func (e *external) eval(x []float64) float64 {
+	if e.err != nil {
+		return math.NaN()
+	}
+	fields := make([]string, len(x))
+	for i, xi := range x {
+		fields[i] = strconv.FormatFloat(xi, 'g', -1, 64)
+	}
+	if _, e.err = fmt.Fprintln(e.in, strings.Join(fields, " ")); e.err != nil {
+		return math.NaN()
+	}
+	if !e.out.Scan() {
+		if e.err = e.out.Err(); e.err == nil {
+			e.err = io.ErrUnexpectedEOF
+		}
+		return math.NaN()
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(e.out.Text()), 64)
+	if err != nil {
+		e.err = err
+		return math.NaN()
+	}
+	return y
+}
+
+func (e *external) close() {
+	e.in.Close()
+	e.cmd.Wait()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the test binary as an external objective if OPTIMIZE_WORKER is set
+func TestMain(m *testing.M) {
+	if os.Getenv("OPTIMIZE_WORKER") != "" {
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			s := 0.
+			for i, field := range strings.Fields(sc.Text()) {
+				xi, _ := strconv.ParseFloat(field, 64)
+				s += (xi - float64(i+1)) * (xi - float64(i+1))
+			}
+			fmt.Println(s)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRun(t *testing.T) {
+	var out, errOut bytes.Buffer
+	parse := func() output {
+		var res output
+		if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+			t.Fatal(err, errOut.String())
+		}
+		return res
+	}
+	if err := run([]string{"-problem", "sphere", "-dim", "3", "-trace", "-"}, &out, &errOut); err != nil {
+		t.Fatal(err)
+	}
+	if res := parse(); len(res.X) != 3 || res.F == nil || *res.F > 1e-10 || res.FOpt == nil || *res.FOpt != 0 || res.Status != "MethodConverge" {
+		t.Errorf("sphere: %s", out.String())
+	}
+	if !strings.Contains(errOut.String(), `"iter":1`) {
+		t.Errorf("expected a trace, got %s", errOut.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"-problem", "Rosenbrock", "-dim", "8", "-maxfev", "10"}, &out, &errOut); err != nil {
+		t.Errorf("rosenbrock in dimension 8: %v", err)
+	}
+
+	out.Reset()
+	if err := run([]string{"-problem", "branin"}, &out, &errOut); err != nil {
+		t.Fatal(err)
+	}
+	if res := parse(); len(res.X) != 2 || res.FOpt == nil || math.Abs(*res.F-*res.FOpt) > 1e-6 {
+		t.Errorf("branin: %s", out.String())
+	}
+
+	out.Reset()
+	os.Setenv("OPTIMIZE_WORKER", "1")
+	defer os.Unsetenv("OPTIMIZE_WORKER")
+	cmd := strconv.Quote(os.Args[0])
+	if err := run([]string{"-cmd", cmd, "-x0", "0,0", "-xmax", "5,1.5", "-method", "cmaes", "-maxfev", "2000"}, &out, &errOut); err != nil {
+		t.Fatal(err)
+	}
+	if res := parse(); math.Abs(res.X[0]-1) > 1e-3 || math.Abs(res.X[1]-1.5) > 1e-3 || res.NFev == 0 {
+		t.Errorf("cmd: %s", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"-problem", "unknown"},
+		{"-problem", "rosenbrock", "-dim", "1"},
+		{"-problem", "beale", "-dim", "3"},
+		{"-problem", "beale", "-x0", "1,2,3"},
+		{"-problem", "sphere", "-dim", "0"},
+		{"-x0", "1,a", "-problem", "sphere"},
+		{"-cmd", "true"},
+		{"-cmd", "exit 0", "-x0", "1"},
+		{"-plugin", "missing.so", "-x0", "1"},
+	} {
+		if err := run(args, &out, &errOut); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/rand"
+)
+
+// BBOBTargets are the target precisions f-FOpt of COCO, 10^2 to 10^-8 in
+// steps of 10^0.2
+var BBOBTargets = func() []float64 {
+	t := make([]float64, 51)
+	for k := range t {
+		t[k] = math.Pow(10, 2-float64(k)/5)
+	}
+	return t
+}()
+
+// BBOBExperiment runs a registered algorithm on BBOB problems with the
+// conventions of COCO: independent restarts from uniform points of
+// [-5,5]^Dim until the final target precision is reached or the budget is
+// spent, and the logging of the runs in the data format of COCO, read by
+// its post-processing cocopp to compare the algorithm with the published
+// results of CMA-ES, DE and others.
+type BBOBExperiment struct {
+	// Algorithm is the name of a registered algorithm, see NewAlgorithm
+	Algorithm string
+	// Options configure the algorithm, the target and the budget being set
+	// by the experiment. The evaluations are sequential: the options must
+	// not include an Evaluator.
+	Options []Option
+	// Functions default to BBOBFunctions, Dims to 2, 3, 5, 10 and Instances
+	// to 1..15
+	Functions, Dims, Instances []int
+	// Budget is the number of evaluations per dimension of a problem, 1000
+	// by default
+	Budget int
+	// Precision is the final target precision, 1e-8 by default
+	Precision float64
+	// Dir, if not empty, is the directory of the COCO data files:
+	// bbobexp_f<function>.info and data_f<function>/bbobexp_f<function>_DIM<dim>.dat
+	// and .tdat
+	Dir string
+	// Src is the random source of the starting points
+	Src rand.Source
+}
+
+// BBOBRun is the run of an algorithm on a BBOB problem
+type BBOBRun struct {
+	Function, Instance, Dim int
+	// Evals is the number of evaluations and DeltaF the best f-FOpt
+	Evals  int
+	DeltaF float64
+	// Hits are the numbers of evaluations to reach the BBOBTargets, 0 for
+	// the targets not reached
+	Hits []int
+	// Result is the result of the restarts
+	Result *Result
+}
+
+// Run runs the algorithm on the problems, in the order of the functions,
+// dimensions and instances, and writes the data files in Dir
+func (ex *BBOBExperiment) Run() ([]*BBOBRun, error) {
+	if _, ok := LookupAlgorithm(ex.Algorithm); !ok {
+		return nil, errors.New("optimize: unknown algorithm " + ex.Algorithm)
+	}
+	functions, dims, instances := ex.Functions, ex.Dims, ex.Instances
+	if functions == nil {
+		functions = BBOBFunctions
+	}
+	if dims == nil {
+		dims = []int{2, 3, 5, 10}
+	}
+	if instances == nil {
+		for i := 1; i <= 15; i++ {
+			instances = append(instances, i)
+		}
+	}
+	budget, precision := ex.Budget, ex.Precision
+	if budget <= 0 {
+		budget = 1000
+	}
+	if precision <= 0 {
+		precision = 1e-8
+	}
+	rnd := newRand(ex.Src)
+	var runs []*BBOBRun
+	for _, fn := range functions {
+		var info *strings.Builder
+		if ex.Dir != "" {
+			if err := os.MkdirAll(filepath.Join(ex.Dir, fmt.Sprintf("data_f%d", fn)), 0777); err != nil {
+				return runs, err
+			}
+			info = &strings.Builder{}
+		}
+		for _, dim := range dims {
+			var dimRuns []*BBOBRun
+			dat, tdat := &strings.Builder{}, &strings.Builder{}
+			for _, inst := range instances {
+				p, err := NewBBOBProblem(fn, inst, dim)
+				if err != nil {
+					return runs, err
+				}
+				run, err := ex.run(p, budget*dim, precision, rnd, dat, tdat)
+				if err != nil {
+					return runs, err
+				}
+				dimRuns = append(dimRuns, run)
+			}
+			runs = append(runs, dimRuns...)
+			if info == nil {
+				continue
+			}
+			data := fmt.Sprintf("data_f%d/bbobexp_f%d_DIM%d", fn, fn, dim)
+			for ext, s := range map[string]string{".dat": dat.String(), ".tdat": tdat.String()} {
+				if err := ioutil.WriteFile(filepath.Join(ex.Dir, data+ext), []byte(s), 0666); err != nil {
+					return runs, err
+				}
+			}
+			fmt.Fprintf(info, "funcId = %d, DIM = %d, Precision = %.3e, algId = '%s'\n%%\n%s.dat", fn, dim, precision, ex.Algorithm, data)
+			for _, r := range dimRuns {
+				fmt.Fprintf(info, ", %d:%d|%.1e", r.Instance, r.Evals, r.DeltaF)
+			}
+			fmt.Fprintln(info)
+		}
+		if info != nil {
+			if err := ioutil.WriteFile(filepath.Join(ex.Dir, fmt.Sprintf("bbobexp_f%d.info", fn)), []byte(info.String()), 0666); err != nil {
+				return runs, err
+			}
+		}
+	}
+	return runs, nil
+}
+
+// run runs the algorithm on p, appending the records of the run to dat and tdat
+func (ex *BBOBExperiment) run(p *BBOBProblem, maxFev int, precision float64, rnd *rand.Rand, dat, tdat *strings.Builder) (*BBOBRun, error) {
+	m, err := NewAlgorithm(ex.Algorithm, append(append([]Option(nil), ex.Options...), WithTargetF(p.FOpt+precision))...)
+	if err != nil {
+		return nil, err
+	}
+	run := &BBOBRun{Function: p.Function, Instance: p.Instance, Dim: p.Dim, DeltaF: math.Inf(1), Hits: make([]int, len(BBOBTargets))}
+	header := fmt.Sprintf("%% function evaluation | noise-free fitness - Fopt (%.12e) | best noise-free fitness - Fopt | measured fitness | best measured fitness | x1 | x2...\n", p.FOpt)
+	dat.WriteString(header)
+	tdat.WriteString(header)
+	var (
+		bestF = math.Inf(1)
+		// next is the index of the next target in BBOBTargets, nextEvals
+		// the exponent of the next number of evaluations logged in tdat
+		next, nextEvals = 0, 0
+	)
+	record := func(w *strings.Builder, x []float64, f float64) {
+		fmt.Fprintf(w, "%d %+10.9e %+10.9e %+10.9e %+10.9e", run.Evals, f-p.FOpt, bestF-p.FOpt, f, bestF)
+		for _, xi := range x {
+			fmt.Fprintf(w, " %+5.4e", xi)
+		}
+		w.WriteString("\n")
+	}
+	var last []float64
+	f := func(x []float64) float64 {
+		y := p.Func(x)
+		run.Evals++
+		last = append(last[:0], x...)
+		if y < bestF {
+			bestF = y
+			run.DeltaF = y - p.FOpt
+		}
+		if next < len(BBOBTargets) && run.DeltaF <= BBOBTargets[next] {
+			for next < len(BBOBTargets) && run.DeltaF <= BBOBTargets[next] {
+				run.Hits[next] = run.Evals
+				next++
+			}
+			record(dat, x, y)
+		}
+		// 20 records per decade of evaluations
+		if float64(run.Evals) >= math.Floor(math.Pow(10, float64(nextEvals)/20)) {
+			for float64(run.Evals) >= math.Floor(math.Pow(10, float64(nextEvals)/20)) {
+				nextEvals++
+			}
+			record(tdat, x, y)
+		}
+		return y
+	}
+	xmin, xmax := make([]float64, p.Dim), make([]float64, p.Dim)
+	for i := range xmin {
+		xmin[i], xmax[i] = -5, 5
+	}
+	policy := &RandomRestarts{RestartLimit: RestartLimit{MaxRestarts: maxFev}, Xmin: xmin, Xmax: xmax}
+	rs := &Restarts{Minimizer: m, Policy: policy, MaxFev: maxFev, Src: rand.NewSource(rnd.Uint64())}
+	x0 := policy.Start(nil, rnd)
+	if run.Result, err = rs.Minimize(f, x0); err != nil {
+		return nil, err
+	}
+	if last != nil {
+		// the last evaluation closes the records of the run
+		y := p.Func(last)
+		record(dat, last, y)
+		record(tdat, last, y)
+	}
+	return run, nil
+}
@@ -0,0 +1,80 @@
+package optimize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleBBOBExperiment() {
+	ex := &BBOBExperiment{Algorithm: "LN_POWELL", Functions: []int{1, 8}, Dims: []int{2}, Instances: []int{1}, Src: rand.NewSource(1)}
+	runs, err := ex.Run()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, r := range runs {
+		fmt.Printf("f%d solved: %v, %d evaluations, %d runs\n", r.Function, r.DeltaF <= 1e-8, r.Evals, len(r.Result.Extra.([]*Result)))
+	}
+	// Output:
+	// f1 solved: true, 16 evaluations, 1 runs
+	// f8 solved: true, 247 evaluations, 1 runs
+}
+
+func TestBBOBExperiment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ex := &BBOBExperiment{Algorithm: "LN_SPSA", Functions: []int{3}, Dims: []int{2, 3}, Instances: []int{1, 2, 3}, Budget: 100, Dir: dir, Src: rand.NewSource(1)}
+	runs, err := ex.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 6 {
+		t.Fatalf("expected 6 runs, got %d", len(runs))
+	}
+	for _, r := range runs {
+		if r.Evals < r.Result.NFev || r.Evals < 100*r.Dim || r.Evals > 110*r.Dim {
+			t.Errorf("f%d i%d d%d: %d evaluations, result %d", r.Function, r.Instance, r.Dim, r.Evals, r.Result.NFev)
+		}
+		for k, hit := range r.Hits {
+			if (hit > 0) != (r.DeltaF <= BBOBTargets[k]) || k > 0 && hit > 0 && hit < r.Hits[k-1] {
+				t.Errorf("f%d i%d d%d: hits %v for DeltaF %g", r.Function, r.Instance, r.Dim, r.Hits, r.DeltaF)
+				break
+			}
+		}
+	}
+	info, err := ioutil.ReadFile(filepath.Join(dir, "bbobexp_f3.info"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(info)), "\n")
+	if len(lines) != 6 || lines[0] != "funcId = 3, DIM = 2, Precision = 1.000e-08, algId = 'LN_SPSA'" ||
+		!strings.HasPrefix(lines[2], "data_f3/bbobexp_f3_DIM2.dat, 1:") || strings.Count(lines[5], "|") != 3 {
+		t.Errorf("unexpected info file\n%s", info)
+	}
+	for _, ext := range []string{".dat", ".tdat"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "data_f3", "bbobexp_f3_DIM3"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(data), "% function evaluation"); n != 3 {
+			t.Errorf("%s: expected 3 runs, got %d", ext, n)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if !strings.HasPrefix(line, "%") && len(strings.Fields(line)) != 5+3 {
+				t.Errorf("%s: unexpected line %q", ext, line)
+			}
+		}
+	}
+	if _, err := (&BBOBExperiment{Algorithm: "GN_DIRECT"}).Run(); err == nil {
+		t.Error("expected an unknown algorithm")
+	}
+}
@@ -0,0 +1,35 @@
+package optimize
+
+import (
+	"gonum.org/v1/gonum/optimize"
+)
+
+// This file keeps the package working across the API changes of gonum,
+// without build tags: the methods which were renamed are called through
+// interfaces, so that the package builds with the versions on both sides.
+
+// Needser is implemented by the methods of gonum versions before
+// optimize.Method.Uses, which declared their needs with Needs. CmaEsCholB
+// and Powell implement both, for any gonum version.
+type Needser interface {
+	Needs() struct{ Gradient, Hessian bool }
+}
+
+var (
+	_ Needser           = (*CmaEsCholB)(nil)
+	_ Needser           = (*Powell)(nil)
+	_ optimize.Method   = (*Powell)(nil)
+	_ optimize.Statuser = (*Powell)(nil)
+)
+
+// symmetricDim returns the dimension of a symmetric matrix or of a
+// mat.Cholesky: SymmetricDim since gonum v0.9, Symmetric before
+func symmetricDim(m interface{}) int {
+	switch m := m.(type) {
+	case interface{ SymmetricDim() int }:
+		return m.SymmetricDim()
+	case interface{ Symmetric() int }:
+		return m.Symmetric()
+	}
+	panic("optimize: not a symmetric matrix")
+}
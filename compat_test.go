@@ -0,0 +1,29 @@
+package optimize
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// symmetricDimMatrix has the method of gonum versions since v0.9
+type symmetricDimMatrix struct{ n int }
+
+func (m symmetricDimMatrix) SymmetricDim() int { return m.n }
+
+func TestCompat(t *testing.T) {
+	var chol mat.Cholesky
+	if !chol.Factorize(mat.NewSymDense(2, []float64{2, 1, 1, 2})) {
+		t.Fatal("factorization failed")
+	}
+	for _, m := range []interface{}{mat.NewSymDense(2, nil), &chol, symmetricDimMatrix{2}} {
+		if n := symmetricDim(m); n != 2 {
+			t.Errorf("%T: expected 2, got %d", m, n)
+		}
+	}
+	for _, m := range []Needser{&CmaEsCholB{}, &Powell{}} {
+		if needs := m.Needs(); needs.Gradient || needs.Hessian {
+			t.Errorf("%T: unexpected needs %v", m, needs)
+		}
+	}
+}
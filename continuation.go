@@ -0,0 +1,145 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Continuation tracks the minimizer of a parameterized objective f(x, t) as
+// t sweeps from T0 to T1, each solve starting from the extrapolation of the
+// previous solutions instead of a cold start. A root of g(x, t) is tracked
+// by minimizing |g|^2.
+//
+// The step in t grows while the solutions are close to their predictions
+// and is halved when a solution moves by more than MaxCorrection, or when
+// the solve fails.
+type Continuation struct {
+	// Minimizer solves each problem, eg a solver of NewAlgorithm. If it is
+	// a WarmStarter or a method of Minimize, it is primed with the Result of
+	// the previous solve.
+	Minimizer Minimizer
+	T0, T1    float64
+	// Step is the initial step in t, (T1-T0)/10 if 0. MinStep and MaxStep
+	// bound its magnitude, 1e-6|T1-T0| and |T1-T0| if 0.
+	Step, MinStep, MaxStep float64
+	// MaxCorrection is the largest distance from a solution to its
+	// prediction relative to max(1, |prediction|), 0.1 if 0
+	MaxCorrection float64
+	// Callback, if not nil, is called after each accepted solve
+	Callback func(p ContinuationPoint)
+}
+
+// ContinuationPoint is an accepted solve of a Continuation
+type ContinuationPoint struct {
+	T      float64
+	Result *Result
+	// Jump is true if the solution moved by more than MaxCorrection at the
+	// smallest step, eg at a fold where the tracked minimum disappears
+	Jump bool
+}
+
+// Track solves the problems from x0 at T0 to T1 and returns the accepted
+// points. A solve failing at the smallest step is returned as an error,
+// with the points accepted before.
+func (c *Continuation) Track(f func(x []float64, t float64) float64, x0 []float64) ([]ContinuationPoint, error) {
+	if c.Minimizer == nil {
+		return nil, errors.New("optimize: Continuation without Minimizer")
+	}
+	span := math.Abs(c.T1 - c.T0)
+	step, minStep, maxStep, maxCorr := math.Abs(c.Step), c.MinStep, c.MaxStep, c.MaxCorrection
+	if step == 0 {
+		step = span / 10
+	}
+	if minStep <= 0 {
+		minStep = 1e-6 * span
+	}
+	if maxStep <= 0 {
+		maxStep = span
+	}
+	if maxCorr <= 0 {
+		maxCorr = .1
+	}
+	dir := 1.
+	if c.T1 < c.T0 {
+		dir = -1
+	}
+	if om, ok := c.Minimizer.(*optionsMinimizer); ok {
+		defer func(warm *Result) { om.Options.Warm = warm }(om.Options.Warm)
+	}
+
+	solve := func(t float64, x []float64, prev *Result) *Result {
+		if prev != nil {
+			c.prime(prev, x)
+		}
+		return c.Minimizer.Minimize(func(x []float64) float64 { return f(x, t) }, append([]float64(nil), x...))
+	}
+	points := make([]ContinuationPoint, 0, 16)
+	accept := func(p ContinuationPoint) {
+		points = append(points, p)
+		if c.Callback != nil {
+			c.Callback(p)
+		}
+	}
+
+	res := solve(c.T0, x0, nil)
+	if res.Status == optimize.Failure {
+		return nil, fmt.Errorf("optimize: continuation failed at t=%g: %s", c.T0, res.Message)
+	}
+	accept(ContinuationPoint{T: c.T0, Result: res})
+	for t := c.T0; dir*(c.T1-t) > 0; {
+		h := math.Min(step, dir*(c.T1-t))
+		last := points[len(points)-1]
+		// secant predictor from the last two points
+		pred := append([]float64(nil), last.Result.X...)
+		if n := len(points); n > 1 && !last.Jump {
+			before := points[n-2]
+			floats.AddScaled(pred, h/math.Abs(last.T-before.T), last.Result.X)
+			floats.AddScaled(pred, -h/math.Abs(last.T-before.T), before.Result.X)
+		}
+		tn := t + dir*h
+		if h == dir*(c.T1-t) {
+			tn = c.T1
+		}
+		res := solve(tn, pred, last.Result)
+		corr := floats.Distance(res.X, pred, 2) / math.Max(1, floats.Norm(pred, 2))
+		if res.Status == optimize.Failure || corr > maxCorr {
+			if h > minStep {
+				step = math.Max(h/2, minStep)
+				continue
+			}
+			if res.Status == optimize.Failure {
+				return points, fmt.Errorf("optimize: continuation failed at t=%g: %s", tn, res.Message)
+			}
+		}
+		accept(ContinuationPoint{T: tn, Result: res, Jump: corr > maxCorr})
+		if corr < maxCorr/4 {
+			step = math.Min(2*h, maxStep)
+		}
+		t = tn
+	}
+	return points, nil
+}
+
+// prime primes the Minimizer with prev moved to the prediction x
+func (c *Continuation) prime(prev *Result, x []float64) {
+	p := *prev
+	p.X = x
+	if prev.Warm != nil {
+		warm := *prev.Warm
+		warm.X = x
+		p.Warm = &warm
+	}
+	switch m := c.Minimizer.(type) {
+	case WarmStarter:
+		if p.Warm != nil {
+			// a state of another method is a cold start from x
+			_ = m.WarmStart(&p)
+		}
+	case *optionsMinimizer:
+		m.Options.Warm = &p
+	}
+}
@@ -0,0 +1,77 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleContinuation() {
+	// the minimum of the shifted Rosenbrock function is (a, a^2)
+	f := func(x []float64, a float64) float64 {
+		return (a-x[0])*(a-x[0]) + 100*(x[1]-x[0]*x[0])*(x[1]-x[0]*x[0])
+	}
+	c := &Continuation{Minimizer: NewPowellMinimizer(), T0: 1, T1: 3}
+	points, err := c.Track(f, []float64{-1.2, 1})
+	if err != nil {
+		panic(err)
+	}
+	nfev := 0
+	for _, p := range points {
+		nfev += p.Result.NFev
+	}
+	last := points[len(points)-1]
+	fmt.Printf("%d points, t=%g x=%.4f nfev=%d\n", len(points), last.T, last.Result.X, nfev)
+	// Output:
+	// 10 points, t=3 x=[3.0000 9.0000] nfev=2445
+}
+
+func TestContinuation(t *testing.T) {
+	// the left minimum of the tilted double well x^4/4 - x^2/2 - t x,
+	// tracked from t = -1, disappears at t = 2/sqrt(27). The solver may leave
+	// its shallow basin before.
+	well := func(x []float64, t float64) float64 { return x[0]*x[0]*x[0]*x[0]/4 - x[0]*x[0]/2 - t*x[0] }
+	m, err := NewAlgorithm("LD_BFGS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Continuation{Minimizer: m, T0: -1, T1: 1, MinStep: 1e-3}
+	points, err := c.Track(well, []float64{-1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jumps := 0
+	for _, p := range points {
+		x := p.Result.X[0]
+		if g := x*x*x - x - p.T; math.Abs(g) > 1e-4 {
+			t.Errorf("t=%g: x=%g is not a stationary point", p.T, x)
+		}
+		if p.Jump {
+			jumps++
+			if fold := 2 / math.Sqrt(27); p.T < 0 || p.T > fold+.01 || x < 0 {
+				t.Errorf("jump at t=%g to x=%g, expected to the right minimum before %g", p.T, x, fold)
+			}
+		} else if jumps == 0 && x > 0 {
+			t.Errorf("t=%g: x=%g left the tracked minimum without a jump", p.T, x)
+		}
+	}
+	if last := points[len(points)-1]; jumps != 1 || last.T != 1 || math.Abs(last.Result.X[0]-1.324718) > 1e-4 {
+		t.Errorf("got %d jumps, last t=%g x=%g", jumps, last.T, last.Result.X)
+	}
+
+	// a decreasing sweep with a method of Minimize, whose Warm is restored
+	m, err = NewAlgorithm("LN_POWELL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = &Continuation{Minimizer: m, T0: 1, T1: .5}
+	if points, err = c.Track(well, []float64{1.5}); err != nil {
+		t.Fatal(err)
+	}
+	if last := points[len(points)-1]; last.T != .5 || math.Abs(last.Result.X[0]-1.191487884) > 1e-4 || m.(*optionsMinimizer).Options.Warm != nil {
+		t.Errorf("decreasing sweep: last t=%g x=%g", last.T, last.Result.X)
+	}
+	if _, err := (&Continuation{T1: 1}).Track(well, []float64{0}); err == nil {
+		t.Error("expected an error without Minimizer")
+	}
+}
@@ -0,0 +1,59 @@
+package optimize
+
+import (
+	"sync"
+
+	"golang.org/x/exp/rand"
+)
+
+// SeededFunc is a stochastic objective drawing its random numbers from seed.
+// Evaluations with the same seed use common random numbers, which reduces the
+// noise of comparisons between candidates.
+type SeededFunc func(x []float64, seed uint64) float64
+
+// CommonRandomNumbers adapts a SeededFunc to a plain objective evaluated with
+// the current seed. Methods supporting common random numbers (SPSA, CmaEsCholB,
+// Racing) call NextSeed at the beginning of each block of evaluations to be
+// compared, ie each gradient estimate, generation or round.
+// It is safe for concurrent use if Func is.
+type CommonRandomNumbers struct {
+	Func SeededFunc
+	// Src generates the seeds. if nil, a source seeded from golang.org/x/exp/rand is used.
+	Src rand.Source
+
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	seed uint64
+}
+
+// NextSeed draws a new seed and returns it
+func (crn *CommonRandomNumbers) NextSeed() uint64 {
+	crn.mu.Lock()
+	defer crn.mu.Unlock()
+	if crn.rnd == nil {
+		crn.rnd = newRand(crn.Src)
+	}
+	crn.seed = crn.rnd.Uint64()
+	return crn.seed
+}
+
+// Seed returns the current seed
+func (crn *CommonRandomNumbers) Seed() uint64 {
+	crn.mu.Lock()
+	defer crn.mu.Unlock()
+	return crn.seed
+}
+
+// Eval evaluates Func at x with the current seed
+func (crn *CommonRandomNumbers) Eval(x []float64) float64 {
+	return crn.Func(x, crn.Seed())
+}
+
+// replicationSeed returns the seed of the k-th replication
+func replicationSeed(k int) uint64 {
+	// splitmix64 of k
+	z := uint64(k+1) * 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
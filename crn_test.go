@@ -0,0 +1,68 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+)
+
+// simulation is a noisy objective whose noise is mostly common to nearby points
+func simulation(x []float64, seed uint64) float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	shock := rnd.NormFloat64()
+	s := 0.
+	for i, xi := range x {
+		d := xi - float64(i+1)
+		s += d * d * (1 + .2*shock)
+	}
+	return s + shock + .001*rnd.NormFloat64()
+}
+
+func ExampleCommonRandomNumbers() {
+	crn := &CommonRandomNumbers{Func: simulation, Src: rand.NewSource(1)}
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	sp.CRN = crn
+	res := sp.Minimize(crn.Eval, []float64{0, 0})
+	fmt.Printf("%.2f\n", res.X)
+	// Output:
+	// [1.00 2.00]
+}
+
+func TestCommonRandomNumbers(t *testing.T) {
+	opt := []float64{1, 2}
+	dist := func(useCRN bool) float64 {
+		crn := &CommonRandomNumbers{Func: simulation, Src: rand.NewSource(1)}
+		sp := NewSPSA()
+		sp.Src = rand.NewSource(1)
+		sp.MaxIter = 200
+		f := crn.Eval
+		if useCRN {
+			sp.CRN = crn
+		} else {
+			f = func(x []float64) float64 { return simulation(x, crn.NextSeed()) }
+		}
+		return floats.Distance(sp.Minimize(f, []float64{0, 0}).X, opt, 2)
+	}
+	if with, without := dist(true), dist(false); with >= without {
+		t.Errorf("CRN should help: distance with %g without %g", with, without)
+	}
+
+	// paired replications need less samples to distinguish close points
+	evals := func(seeded bool) int {
+		src := rand.New(rand.NewSource(1))
+		no := &NoisyObjective{MaxSamples: 1000}
+		if seeded {
+			no.Seeded = simulation
+		} else {
+			no.Func = func(x []float64) float64 { return simulation(x, src.Uint64()) }
+		}
+		no.Compare([]float64{1, 2}, []float64{1.5, 2})
+		return no.Evaluations
+	}
+	if with, without := evals(true), evals(false); with >= without {
+		t.Errorf("seeded replications should need less evaluations: %d vs %d", with, without)
+	}
+}
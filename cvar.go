@@ -0,0 +1,79 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// CVaRObjective is the auxiliary variable formulation of Rockafellar and
+// Uryasev of the minimization of the conditional value at risk of a
+// stochastic objective: CVaR(x) is the minimum in eta of
+//
+//	F(x, eta) = eta + mean(max(f_k(x) - eta, 0))/(1-Alpha)
+//
+// over the samples f_k(x) of the Scenarios, and the minimum of F in
+// z = (x, eta) is a risk-averse solution x, eta being its value at risk.
+// F is convex in z if the samples are convex in x, and the standard
+// minimizers, eg the solvers of NewAlgorithm, minimize it as any objective.
+type CVaRObjective struct {
+	// Scenarios samples the objective, with their Alpha, their Risk being
+	// ignored
+	Scenarios *ScenarioObjective
+}
+
+// CVaRInfo is the Extra of the Result of CVaRObjective.Minimize
+type CVaRInfo struct {
+	// Eta is the auxiliary variable at the solution, CVaR and VaR the
+	// estimates at the solution x
+	Eta, CVaR, VaR float64
+}
+
+// Eval returns F(x, eta) for z = (x, eta)
+func (co *CVaRObjective) Eval(z []float64) float64 {
+	n := len(z) - 1
+	return ruFunction(co.Scenarios.Values(z[:n:n]), z[n], co.Scenarios.alpha())
+}
+
+// Start returns the starting point (x0, VaR(x0)) of the minimization of Eval
+func (co *CVaRObjective) Start(x0 []float64) []float64 {
+	return append(append([]float64(nil), x0...), co.Quantile(x0))
+}
+
+// Quantile returns the estimate of the value at risk of level Alpha at x
+func (co *CVaRObjective) Quantile(x []float64) float64 {
+	return quantile(co.Scenarios.Values(x), co.Scenarios.alpha())
+}
+
+// CVaR returns the estimate of the conditional value at risk of level
+// Alpha at x
+func (co *CVaRObjective) CVaR(x []float64) float64 {
+	return cvar(co.Scenarios.Values(x), co.Scenarios.alpha())
+}
+
+// Minimize minimizes Eval with m from Start(x0). X and F of the Result are
+// x and its CVaR, and its Extra is a *CVaRInfo, but for a Result with
+// Status Failure.
+func (co *CVaRObjective) Minimize(m Minimizer, x0 []float64) (*Result, error) {
+	if co.Scenarios == nil || co.Scenarios.Func == nil {
+		return nil, errors.New("optimize: CVaRObjective without Scenarios")
+	}
+	if alpha := co.Scenarios.alpha(); alpha < 0 || alpha >= 1 || math.IsNaN(alpha) {
+		return nil, errors.New("optimize: CVaR level Alpha outside [0, 1)")
+	}
+	start := time.Now()
+	res := m.Minimize(co.Eval, co.Start(x0))
+	n := len(x0)
+	if res.Status == optimize.Failure || len(res.X) != n+1 {
+		return res, nil
+	}
+	eta := res.X[n]
+	res.X, res.Warm = res.X[:n:n], nil
+	v := co.Scenarios.Values(res.X)
+	info := &CVaRInfo{Eta: eta, CVaR: cvar(v, co.Scenarios.alpha()), VaR: quantile(v, co.Scenarios.alpha())}
+	res.F, res.Extra = info.CVaR, info
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleCVaRObjective() {
+	// the loss of a newsvendor ordering x items at 1 sold at 2, for an
+	// exponential demand of mean 100
+	loss := ScenarioFunc(func(x, demand []float64) float64 {
+		return x[0] - 2*math.Min(x[0], demand[0])
+	}, func(rnd *rand.Rand) []float64 {
+		return []float64{100 * rnd.ExpFloat64()}
+	})
+	scenarios := &ScenarioObjective{Func: loss, Samples: 1000, Alpha: .9}
+	mean := NewPowellMinimizer().Minimize(scenarios.Eval, []float64{50})
+	co := &CVaRObjective{Scenarios: scenarios}
+	res, err := co.Minimize(NewPowellMinimizer(), []float64{50})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("order %.1f for the mean loss %.2f, order %.1f for the CVaR %.2f\n", mean.X[0], mean.F, res.X[0], res.F)
+	// Output:
+	// order 68.8 for the mean loss -31.68, order 3.4 for the CVaR -2.27
+}
+
+func TestCVaRObjective(t *testing.T) {
+	f := ScenarioFunc(func(x, s []float64) float64 { return (x[0] - s[0]) * (x[0] - s[0]) }, func(rnd *rand.Rand) []float64 {
+		return []float64{rnd.NormFloat64()}
+	})
+	co := &CVaRObjective{Scenarios: &ScenarioObjective{Func: f, Samples: 50}}
+	x := []float64{.3}
+	// the minimum of Eval in eta is the CVaR, reached at the VaR
+	z := co.Start(x)
+	if d := co.Eval(z) - co.CVaR(x); math.Abs(d) > 1e-12 {
+		t.Errorf("F(x, VaR) - CVaR = %g", d)
+	}
+	for _, eta := range []float64{z[1] - .1, z[1] + .1} {
+		if co.Eval([]float64{x[0], eta}) < co.CVaR(x)-1e-12 {
+			t.Errorf("F(x, %g) below the CVaR", eta)
+		}
+	}
+
+	res, err := co.Minimize(NewPowellMinimizer(), []float64{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := res.Extra.(*CVaRInfo)
+	// the CVaR at the solution is below the CVaR near it
+	for _, dx := range []float64{-.05, .05} {
+		if c := co.CVaR([]float64{res.X[0] + dx}); c < res.F-1e-9 {
+			t.Errorf("CVaR %g at %g below the solution %v", c, res.X[0]+dx, res)
+		}
+	}
+	if len(res.X) != 1 || math.Abs(info.Eta-info.VaR) > 1e-3 || res.F != info.CVaR {
+		t.Errorf("got %v %+v", res, info)
+	}
+
+	if _, err := (&CVaRObjective{Scenarios: &ScenarioObjective{Func: f, Alpha: 1}}).Minimize(NewPowellMinimizer(), x); err == nil {
+		t.Error("expected an error for Alpha 1")
+	}
+}
@@ -0,0 +1,182 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Verdict is the heuristic interpretation of a run by Diagnose
+type Verdict int
+
+const (
+	// VerdictConverged runs stopped on a convergence test with decaying steps or
+	// improvements
+	VerdictConverged Verdict = iota
+	// VerdictStalled runs stopped, or failed, without improving recently
+	VerdictStalled
+	// VerdictBudgetLimited runs reached a limit while still improving: a larger
+	// budget would likely improve the result
+	VerdictBudgetLimited
+	// VerdictLikelyLocalMinimum runs converged, but restarts found different minima
+	// and the best one only once
+	VerdictLikelyLocalMinimum
+)
+
+var verdicts = []string{"converged", "stalled", "budget-limited", "likely local minimum"}
+
+func (v Verdict) String() string {
+	if v < 0 || int(v) >= len(verdicts) {
+		return fmt.Sprintf("Verdict(%d)", int(v))
+	}
+	return verdicts[v]
+}
+
+// Diagnosis is the convergence report of Diagnose
+type Diagnosis struct {
+	Verdict Verdict
+	// StepDecay and ImprovementDecay are the mean ratios of the successive
+	// step norms and of the successive decreases of f over the last quarter
+	// of the iterations, below 1 when they decay, NaN if unknown
+	StepDecay, ImprovementDecay float64
+	// Condition is the estimate of the local condition number: the "cond"
+	// diagnostic of the last iteration, eg of CmaEsCholB, else the one of a
+	// gradient descent decreasing f at the rate ImprovementDecay. NaN if
+	// unknown. Hessian at the result gives a better one.
+	Condition float64
+	// Notes explain the verdict
+	Notes []string
+}
+
+// String is a report of the diagnosis
+func (d *Diagnosis) String() string {
+	s := "diagnosis: " + d.Verdict.String()
+	if len(d.Notes) > 0 {
+		s += ". " + strings.Join(d.Notes, ". ")
+	}
+	return s
+}
+
+// Diagnose interprets the result of a run and its History, which may be
+// nil, the verdict being a heuristic for non-expert users. A Result of
+// Restarts also compares the minima of its runs.
+func Diagnose(res *Result, h *History) *Diagnosis {
+	d := &Diagnosis{StepDecay: math.NaN(), ImprovementDecay: math.NaN(), Condition: math.NaN()}
+	improving := false
+	if h != nil {
+		d.StepDecay, d.ImprovementDecay = historyDecays(h.Iterations)
+		if c, ok := h.Diagnostics["cond"]; ok {
+			d.Condition = c
+		} else if r := d.ImprovementDecay; r > 0 && r < 1 {
+			// steepest descent decreases f by ((k-1)/(k+1))^2 at each step
+			d.Condition = (1 + math.Sqrt(r)) / (1 - math.Sqrt(r))
+		}
+		// improving if f decreased over the last fifth of the evaluations
+		if n := len(h.Points); n > 1 && h.NFev > 0 {
+			improving = h.Points[n-1].NFev > h.NFev*4/5
+		}
+		if !math.IsNaN(d.StepDecay) {
+			d.Notes = append(d.Notes, fmt.Sprintf("ratio of successive steps %.3g", d.StepDecay))
+		}
+		if !math.IsNaN(d.ImprovementDecay) {
+			d.Notes = append(d.Notes, fmt.Sprintf("ratio of successive improvements %.3g", d.ImprovementDecay))
+		}
+		switch {
+		case d.Condition > 1e8:
+			d.Notes = append(d.Notes, fmt.Sprintf("ill conditioned (condition ~%.2g): scaling the variables may help", d.Condition))
+		case d.Condition > 0:
+			d.Notes = append(d.Notes, fmt.Sprintf("condition ~%.2g", d.Condition))
+		}
+	}
+	switch {
+	case res.Status == optimize.Failure:
+		d.Verdict = VerdictStalled
+		d.Notes = append([]string{"the method failed: " + res.Message}, d.Notes...)
+	case res.Status.Early() || res.Status == optimize.NotTerminated:
+		d.Verdict = VerdictStalled
+		if improving || h == nil && res.Status.Early() {
+			d.Verdict = VerdictBudgetLimited
+			d.Notes = append([]string{res.Status.String() + " reached while f was improving: increase the budget"}, d.Notes...)
+		} else {
+			d.Notes = append([]string{res.Status.String() + " reached without recent improvement"}, d.Notes...)
+		}
+	case d.StepDecay >= 1 && d.ImprovementDecay >= 1:
+		d.Verdict = VerdictStalled
+		d.Notes = append([]string{res.Status.String() + " without decaying steps or improvements"}, d.Notes...)
+	default:
+		d.Verdict = VerdictConverged
+		if minima, hits := restartMinima(res); minima > 1 && hits == 1 {
+			d.Verdict = VerdictLikelyLocalMinimum
+			d.Notes = append([]string{fmt.Sprintf("the restarts found %d different minima, the best one once: more restarts may find a better one", minima)}, d.Notes...)
+		}
+	}
+	return d
+}
+
+// historyDecays returns the geometric means of the ratios of the successive
+// steps and of the successive decreases of f over the last quarter of the
+// iterations, at least 3, ignoring the zero steps and decreases
+func historyDecays(its []HistoryIteration) (stepDecay, fDecay float64) {
+	w := len(its) / 4
+	if w < 3 {
+		w = 3
+	}
+	if w > len(its) {
+		w = len(its)
+	}
+	its = its[len(its)-w:]
+	var steps, decreases []float64
+	for i, it := range its {
+		if it.Step > 0 {
+			steps = append(steps, it.Step)
+		}
+		if i > 0 {
+			if df := its[i-1].F - it.F; df > 0 {
+				decreases = append(decreases, df)
+			}
+		}
+	}
+	return meanRatio(steps), meanRatio(decreases)
+}
+
+// meanRatio returns the geometric mean of the ratios of the successive
+// values of v, NaN for less than 2 values
+func meanRatio(v []float64) float64 {
+	if len(v) < 2 {
+		return math.NaN()
+	}
+	return math.Pow(v[len(v)-1]/v[0], 1/float64(len(v)-1))
+}
+
+// restartMinima returns the number of different minima of the converged
+// runs of a Result of Restarts, and the number of runs reaching the best one
+func restartMinima(res *Result) (minima, hits int) {
+	runs, ok := res.Extra.([]*Result)
+	if !ok {
+		return 0, 0
+	}
+	var fs []float64
+	for _, r := range runs {
+		if r.Status.Early() || r.Status == optimize.Failure || r.Status == optimize.NotTerminated {
+			continue
+		}
+		if sameMinimum(r.F, res.F) {
+			hits++
+		}
+		found := false
+		for _, f := range fs {
+			found = found || sameMinimum(f, r.F)
+		}
+		if !found {
+			fs = append(fs, r.F)
+		}
+	}
+	return len(fs), hits
+}
+
+// sameMinimum returns whether the values a and b are equal up to 1e-6
+func sameMinimum(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-6*(1+math.Min(math.Abs(a), math.Abs(b)))
+}
@@ -0,0 +1,73 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleDiagnose() {
+	var h History
+	pm, _ := NewPowellMinimizerWith(WithHistory(&h), WithMaxFev(100))
+	res := pm.Minimize(rosen, []float64{-1.2, 1})
+	d := Diagnose(res, &h)
+	fmt.Println(d.Verdict)
+	pm, _ = NewPowellMinimizerWith(WithHistory(&h))
+	res = pm.Minimize(rosen, []float64{-1.2, 1})
+	d = Diagnose(res, &h)
+	fmt.Println(d.Verdict, d.StepDecay < 1, d.ImprovementDecay < 1)
+	// Output:
+	// budget-limited
+	// converged true true
+}
+
+func TestDiagnose(t *testing.T) {
+	// geometric decay of the steps and of f at rate 1/4
+	h := &History{NFev: 40}
+	for i := 0; i < 20; i++ {
+		h.Iterations = append(h.Iterations, HistoryIteration{Iter: i + 1, NFev: 2 * i, F: math.Pow(.25, float64(i)), Step: math.Pow(.5, float64(i))})
+		h.Points = append(h.Points, HistoryPoint{NFev: 2 * i, F: math.Pow(.25, float64(i))})
+	}
+	d := Diagnose(&Result{Status: optimize.FunctionConvergence}, h)
+	if d.Verdict != VerdictConverged || math.Abs(d.StepDecay-.5) > 1e-12 || math.Abs(d.ImprovementDecay-.25) > 1e-12 || math.Abs(d.Condition-3) > 1e-12 {
+		t.Errorf("unexpected %+v", d)
+	}
+	if d := Diagnose(&Result{Status: optimize.IterationLimit}, h); d.Verdict != VerdictBudgetLimited {
+		t.Errorf("unexpected %s", d)
+	}
+	// no improvement over the last evaluations
+	h.NFev = 100
+	if d := Diagnose(&Result{Status: optimize.IterationLimit}, h); d.Verdict != VerdictStalled {
+		t.Errorf("unexpected %s", d)
+	}
+	h.Diagnostics = map[string]float64{"cond": 1e10}
+	if d := Diagnose(&Result{Status: optimize.Failure, Message: "boom"}, h); d.Verdict != VerdictStalled || d.Condition != 1e10 || d.Notes[0] != "the method failed: boom" {
+		t.Errorf("unexpected %s", d)
+	}
+	// growing steps
+	for i := range h.Iterations {
+		h.Iterations[i].Step = float64(i)
+		h.Iterations[i].F = -float64(i)
+	}
+	if d := Diagnose(&Result{Status: optimize.MethodConverge}, h); d.Verdict != VerdictStalled {
+		t.Errorf("unexpected %s", d)
+	}
+
+	runs := []*Result{{F: 2, Status: optimize.MethodConverge}, {F: 1, Status: optimize.MethodConverge}, {F: 0, Status: optimize.IterationLimit}}
+	res := &Result{F: 1, Status: optimize.MethodConverge, Extra: runs}
+	if d := Diagnose(res, nil); d.Verdict != VerdictLikelyLocalMinimum || !math.IsNaN(d.StepDecay) {
+		t.Errorf("unexpected %s", d)
+	}
+	runs[0].F = 1 + 1e-9
+	if d := Diagnose(res, nil); d.Verdict != VerdictConverged {
+		t.Errorf("unexpected %s", d)
+	}
+	if d := Diagnose(&Result{Status: optimize.RuntimeLimit}, nil); d.Verdict != VerdictBudgetLimited {
+		t.Errorf("unexpected %s", d)
+	}
+	if s := Verdict(7).String(); s != "Verdict(7)" {
+		t.Errorf("got %s", s)
+	}
+}
@@ -0,0 +1,80 @@
+package optimize
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Differentiable is an objective providing its gradient. It is consumed by
+// gradient based methods so that gradients computed by automatic
+// differentiation libraries don't have to go through finite differences.
+type Differentiable interface {
+	Value(x []float64) float64
+	Gradient(grad, x []float64)
+}
+
+// HessVecer is optionally implemented by a Differentiable able to compute
+// Hessian-vector products hv = H(x) v
+type HessVecer interface {
+	HessVec(hv, x, v []float64)
+}
+
+// DiffFunc is a Differentiable from a function and its gradient.
+// If Grad is nil, the gradient is estimated by finite differences using Options.
+type DiffFunc struct {
+	Func    func(x []float64) float64
+	Grad    func(grad, x []float64)
+	Options *GradientOptions
+}
+
+var _ Differentiable = DiffFunc{}
+
+// NewDifferentiable returns a Differentiable from f and grad.
+// grad may be nil, in which case central finite differences are used.
+func NewDifferentiable(f func([]float64) float64, grad func(grad, x []float64)) Differentiable {
+	return DiffFunc{Func: f, Grad: grad}
+}
+
+// Value returns Func(x)
+func (df DiffFunc) Value(x []float64) float64 { return df.Func(x) }
+
+// Gradient computes the gradient at x in grad
+func (df DiffFunc) Gradient(grad, x []float64) {
+	if df.Grad != nil {
+		df.Grad(grad, x)
+		return
+	}
+	copy(grad, Gradient(df.Func, x, df.Options))
+}
+
+// HessVec computes hv = H(x) v using d's HessVec if d implements HessVecer,
+// or a central difference of gradients along v otherwise
+func HessVec(d Differentiable, hv, x, v []float64) {
+	if hvr, ok := d.(HessVecer); ok {
+		hvr.HessVec(hv, x, v)
+		return
+	}
+	nv := floats.Norm(v, 2)
+	if nv == 0 {
+		for i := range hv {
+			hv[i] = 0
+		}
+		return
+	}
+	n := len(x)
+	eps := math.Cbrt(2.220446049250313e-16) * math.Max(1, floats.Norm(x, 2)) / nv
+	g0, g1, xe := make([]float64, n), make([]float64, n), make([]float64, n)
+	floats.AddScaledTo(xe, x, -eps, v)
+	d.Gradient(g0, xe)
+	floats.AddScaledTo(xe, x, eps, v)
+	d.Gradient(g1, xe)
+	floats.SubTo(hv, g1, g0)
+	floats.Scale(1/(2*eps), hv)
+}
+
+// DiffProblem returns a gonum optimize.Problem using d for Func and Grad
+func DiffProblem(d Differentiable) optimize.Problem {
+	return optimize.Problem{Func: d.Value, Grad: d.Gradient}
+}
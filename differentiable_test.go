@@ -0,0 +1,34 @@
+package optimize
+
+import (
+	"fmt"
+)
+
+type quadratic struct{}
+
+func (quadratic) Value(x []float64) float64 { return x[0]*x[0] + 3*x[0]*x[1] + 5*x[1]*x[1] }
+func (quadratic) Gradient(grad, x []float64) {
+	grad[0] = 2*x[0] + 3*x[1]
+	grad[1] = 3*x[0] + 10*x[1]
+}
+func (quadratic) HessVec(hv, x, v []float64) {
+	hv[0] = 2*v[0] + 3*v[1]
+	hv[1] = 3*v[0] + 10*v[1]
+}
+
+func ExampleDifferentiable() {
+	x, v, hv, grad := []float64{1, 2}, []float64{1, -1}, make([]float64, 2), make([]float64, 2)
+	for _, d := range []Differentiable{
+		quadratic{},
+		NewDifferentiable(quadratic{}.Value, quadratic{}.Gradient),
+		NewDifferentiable(quadratic{}.Value, nil),
+	} {
+		d.Gradient(grad, x)
+		HessVec(d, hv, x, v)
+		fmt.Printf("f:%.4f grad:%.4f hv:%.4f\n", d.Value(x), grad, hv)
+	}
+	// Output:
+	// f:27.0000 grad:[8.0000 23.0000] hv:[-1.0000 -7.0000]
+	// f:27.0000 grad:[8.0000 23.0000] hv:[-1.0000 -7.0000]
+	// f:27.0000 grad:[8.0000 23.0000] hv:[-1.0000 -7.0000]
+}
@@ -0,0 +1,256 @@
+package optimize
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// EvalRecord is an evaluation stored in an EvalDB
+type EvalRecord struct {
+	X []float64 `json:"x"`
+	// F is stored as null if it is NaN, as "+Inf" or "-Inf" if it is infinite
+	F    float64           `json:"-"`
+	Meta map[string]string `json:"meta,omitempty"`
+	Time time.Time         `json:"time"`
+}
+
+// evalRecordJSON is the encoding of an EvalRecord, F being null if it is
+// NaN and a string if it is infinite
+type evalRecordJSON struct {
+	X    []float64         `json:"x"`
+	F    json.RawMessage   `json:"f"`
+	Meta map[string]string `json:"meta,omitempty"`
+	Time time.Time         `json:"time"`
+}
+
+// MarshalJSON encodes F as null if it is NaN, as "+Inf" or "-Inf" if it is
+// infinite
+func (r EvalRecord) MarshalJSON() ([]byte, error) {
+	rj := evalRecordJSON{X: r.X, Meta: r.Meta, Time: r.Time}
+	switch {
+	case math.IsNaN(r.F):
+		rj.F = json.RawMessage("null")
+	case math.IsInf(r.F, 1):
+		rj.F = json.RawMessage(`"+Inf"`)
+	case math.IsInf(r.F, -1):
+		rj.F = json.RawMessage(`"-Inf"`)
+	default:
+		data, err := json.Marshal(r.F)
+		if err != nil {
+			return nil, err
+		}
+		rj.F = data
+	}
+	return json.Marshal(rj)
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON, a missing F being NaN
+func (r *EvalRecord) UnmarshalJSON(data []byte) error {
+	var rj evalRecordJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	*r = EvalRecord{X: rj.X, F: math.NaN(), Meta: rj.Meta, Time: rj.Time}
+	switch string(rj.F) {
+	case "", "null":
+	case `"+Inf"`:
+		r.F = math.Inf(1)
+	case `"-Inf"`:
+		r.F = math.Inf(-1)
+	default:
+		return json.Unmarshal(rj.F, &r.F)
+	}
+	return nil
+}
+
+// EvalDB is a persistent database of evaluations, stored as JSON Lines
+// appended to a file, so that the evaluations of an expensive study are
+// never lost. Func returns the stored values of the points already
+// evaluated: running a deterministic method again, eg with the same seed,
+// resumes the study. The records warm-start other methods. It is safe for
+// concurrent use.
+type EvalDB struct {
+	// Sync, if true, syncs the file after each record
+	Sync bool
+
+	mu      sync.Mutex
+	w       io.Writer
+	file    *os.File
+	records []EvalRecord
+	index   map[string]int
+	err     error
+}
+
+// OpenEvalDB opens or creates the database at path. A truncated last
+// record, eg after a crash while writing, is removed.
+func OpenEvalDB(path string) (*EvalDB, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	db := &EvalDB{w: file, file: file, index: make(map[string]int)}
+	n, err := db.load(file)
+	if err == nil {
+		err = file.Truncate(n)
+	}
+	if err == nil {
+		_, err = file.Seek(n, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewEvalDB returns a database reading its records from r, which may be
+// nil, and appending the new ones to w. A truncated last record is ignored.
+func NewEvalDB(r io.Reader, w io.Writer) (*EvalDB, error) {
+	db := &EvalDB{w: w, index: make(map[string]int)}
+	if r == nil {
+		return db, nil
+	}
+	if _, err := db.load(r); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// load reads the records of r and returns the length of the complete ones
+func (db *EvalDB) load(r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	var n int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			// a line without newline is a truncated record
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n += int64(len(line))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var rec EvalRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return n, err
+		}
+		db.insert(rec)
+	}
+}
+
+func (db *EvalDB) insert(rec EvalRecord) {
+	db.index[floatsKey(rec.X)] = len(db.records)
+	db.records = append(db.records, rec)
+}
+
+// Add stores an evaluation
+func (db *EvalDB) Add(x []float64, f float64, meta map[string]string) error {
+	rec := EvalRecord{X: append([]float64(nil), x...), F: f, Meta: meta, Time: time.Now()}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.err != nil {
+		return db.err
+	}
+	if _, err = db.w.Write(append(data, '\n')); err == nil && db.Sync && db.file != nil {
+		err = db.file.Sync()
+	}
+	if err != nil {
+		db.err = err
+		return err
+	}
+	db.insert(rec)
+	return nil
+}
+
+// Lookup returns the stored value of x
+func (db *EvalDB) Lookup(x []float64) (float64, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	i, ok := db.index[floatsKey(x)]
+	if !ok {
+		return math.NaN(), false
+	}
+	return db.records[i].F, true
+}
+
+// Func returns f reading the values of the stored points and storing the
+// new evaluations. Write errors are returned by Err.
+func (db *EvalDB) Func(f func([]float64) float64) func([]float64) float64 {
+	return func(x []float64) float64 {
+		if y, ok := db.Lookup(x); ok {
+			return y
+		}
+		y := f(x)
+		db.Add(x, y, nil)
+		return y
+	}
+}
+
+// Records returns the stored evaluations, in order
+func (db *EvalDB) Records() []EvalRecord {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]EvalRecord(nil), db.records...)
+}
+
+// Data returns the points and values of the stored evaluations with a
+// finite value, to warm-start a method or fit a surrogate
+func (db *EvalDB) Data() ([][]float64, []float64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var (
+		xs [][]float64
+		fs []float64
+	)
+	for _, rec := range db.records {
+		if !math.IsNaN(rec.F) && !math.IsInf(rec.F, 0) {
+			xs, fs = append(xs, rec.X), append(fs, rec.F)
+		}
+	}
+	return xs, fs
+}
+
+// Best returns the stored evaluation with the lowest value, false if there
+// is none
+func (db *EvalDB) Best() (EvalRecord, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	best := -1
+	for i, rec := range db.records {
+		if rec.F == rec.F && (best < 0 || rec.F < db.records[best].F) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return EvalRecord{}, false
+	}
+	return db.records[best], true
+}
+
+// Err returns the first write error
+func (db *EvalDB) Err() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.err
+}
+
+// Close closes the file of OpenEvalDB
+func (db *EvalDB) Close() error {
+	if db.file == nil {
+		return nil
+	}
+	return db.file.Close()
+}
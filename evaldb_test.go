@@ -0,0 +1,102 @@
+package optimize
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func ExampleEvalDB() {
+	dir, _ := ioutil.TempDir("", "evaldb")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "study.jsonl")
+	calls := 0
+	simulation := func(x []float64) float64 {
+		calls++
+		return rosen(x)
+	}
+
+	// a study interrupted after 100 evaluations
+	db, _ := OpenEvalDB(path)
+	pm := NewPowellMinimizer()
+	pm.MaxFev = 100
+	pm.Minimize(db.Func(simulation), []float64{-1.2, 1})
+	db.Close()
+
+	// resume it: the completed evaluations are read from the database
+	db, _ = OpenEvalDB(path)
+	defer db.Close()
+	calls = 0
+	res := NewPowellMinimizer().Minimize(db.Func(simulation), []float64{-1.2, 1})
+	fmt.Printf("%.3f %d %d\n", res.X, res.NFev, calls)
+	// Output:
+	// [1.000 1.000] 589 427
+}
+
+func TestEvalDB(t *testing.T) {
+	buf := new(bytes.Buffer)
+	db, err := NewEvalDB(nil, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Add([]float64{1, 2}, 3, map[string]string{"run": "a"})
+	db.Add([]float64{0, 0}, math.Inf(1), nil)
+	db.Add([]float64{2, 2}, 1, nil)
+	db.Add([]float64{3, 3}, math.NaN(), nil)
+	db.Add([]float64{4, 4}, math.Inf(-1), nil)
+	if xs, fs := db.Data(); len(xs) != 2 || fs[1] != 1 {
+		t.Errorf("data before reload %v %v", xs, fs)
+	}
+	buf.WriteString(`{"x":[5,5],"f":`)
+
+	db, err = NewEvalDB(bytes.NewReader(buf.Bytes()), new(bytes.Buffer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := db.Records()
+	if len(recs) != 5 || recs[0].Meta["run"] != "a" || !math.IsInf(recs[1].F, 1) || !math.IsNaN(recs[3].F) || !math.IsInf(recs[4].F, -1) || recs[0].Time.IsZero() {
+		t.Errorf("records %+v", recs)
+	}
+	if f, ok := db.Lookup([]float64{1, 2}); !ok || f != 3 {
+		t.Errorf("lookup %g %v", f, ok)
+	}
+	if _, ok := db.Lookup([]float64{5, 5}); ok {
+		t.Error("the truncated record should be ignored")
+	}
+	if best, ok := db.Best(); !ok || !math.IsInf(best.F, -1) {
+		t.Errorf("best %+v", best)
+	}
+	if xs, fs := db.Data(); len(xs) != 2 || fs[1] != 1 {
+		t.Errorf("data %v %v", xs, fs)
+	}
+
+	dir, err := ioutil.TempDir("", "evaldb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.jsonl")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fdb, err := OpenEvalDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fdb.Sync = true
+	if err := fdb.Add([]float64{7}, 7, nil); err != nil {
+		t.Fatal(err)
+	}
+	fdb.Close()
+	if fdb, err = OpenEvalDB(path); err != nil {
+		t.Fatal(err)
+	}
+	defer fdb.Close()
+	if f, ok := fdb.Lookup([]float64{7}); !ok || f != 7 || len(fdb.Records()) != 6 {
+		t.Errorf("the record after a truncated one should be read, got %g %v", f, ok)
+	}
+}
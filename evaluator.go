@@ -0,0 +1,266 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// BatchFunc evaluates a function at several points in one call, eg on a GPU
+// or a vectorized simulator. It returns one value per point.
+type BatchFunc func(xs [][]float64) []float64
+
+// Func returns the function evaluating one point with b
+func (b BatchFunc) Func() func([]float64) float64 {
+	return func(x []float64) float64 {
+		return b([][]float64{x})[0]
+	}
+}
+
+// Evaluator evaluates a function at several points with a pool of Workers
+// goroutines, or with a single call of Batch. Values are stored in the order of the points, so that the
+// results of the methods using an Evaluator (SPSA, LatticeSearch, Racing,
+// Gradient and Hessian) don't depend on Workers or on the scheduling.
+// A nil *Evaluator evaluates in the calling goroutine. gonum methods, such
+// as CmaEsCholB, use optimize.Settings.Concurrent instead.
+type Evaluator struct {
+	// Workers is the number of goroutines. if > 1, the function must be
+	// safe for concurrent use.
+	Workers int
+	// Recover, if true, recovers the panics of evaluations: their value is
+	// NaN, and the first panic is returned as a *PanicError.
+	Recover bool
+	// Rate, if positive, is the maximum number of evaluations per second,
+	// of calls for Batch
+	Rate float64
+	// Batch, if not nil, is called by Eval with all the points instead of
+	// the function. Workers is then unused. Do, used by Hessian, ignores it.
+	Batch BatchFunc
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewEvaluator returns an Evaluator with workers goroutines
+func NewEvaluator(workers int) *Evaluator {
+	return &Evaluator{Workers: workers}
+}
+
+// WithEvaluator sets the Evaluator of a solver
+func WithEvaluator(ev *Evaluator) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.evaluator == nil {
+			return unsupported("WithEvaluator", s)
+		}
+		*f.evaluator = ev
+		return nil
+	}
+}
+
+// NewBatchEvaluator returns an Evaluator calling batch
+func NewBatchEvaluator(batch BatchFunc) *Evaluator {
+	return &Evaluator{Batch: batch}
+}
+
+// PanicError is a panic recovered by an Evaluator
+type PanicError struct {
+	// X is the evaluated point, nil for Do and Batch
+	X     []float64
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("optimize: panic in evaluation: %v", e.Value)
+}
+
+// wait blocks until the next evaluation is allowed by Rate
+func (ev *Evaluator) wait() {
+	if ev.Rate <= 0 {
+		return
+	}
+	ev.mu.Lock()
+	now := time.Now()
+	if ev.next.Before(now) {
+		ev.next = now
+	}
+	t := ev.next
+	ev.next = ev.next.Add(time.Duration(float64(time.Second) / ev.Rate))
+	ev.mu.Unlock()
+	time.Sleep(t.Sub(now))
+}
+
+// Do calls fn(j) for j in [0,n). fn must store its result at index j. If
+// Recover is set, the first recovered panic is returned.
+func (ev *Evaluator) Do(n int, fn func(j int)) error {
+	return ev.do(n, func(j int) []float64 { return nil }, fn)
+}
+
+// Eval returns f(xs[i]) in ys, which is allocated if its length is not len(xs).
+// If Batch is set, it is called instead of f. If Recover is set, the first
+// recovered panic is returned.
+func (ev *Evaluator) Eval(f func([]float64) float64, xs [][]float64, ys []float64) ([]float64, error) {
+	if len(ys) != len(xs) {
+		ys = make([]float64, len(xs))
+	}
+	if ev != nil && ev.Batch != nil {
+		return ys, ev.batch(xs, ys)
+	}
+	err := ev.do(len(xs), func(j int) []float64 { return xs[j] }, func(j int) {
+		ys[j] = math.NaN()
+		ys[j] = f(xs[j])
+	})
+	return ys, err
+}
+
+// batch evaluates xs with Batch in ys
+func (ev *Evaluator) batch(xs [][]float64, ys []float64) (err error) {
+	for i := range ys {
+		ys[i] = math.NaN()
+	}
+	if len(xs) == 0 {
+		return nil
+	}
+	ev.wait()
+	if ev.Recover {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r}
+			}
+		}()
+	}
+	vals := ev.Batch(xs)
+	if len(vals) != len(xs) {
+		return fmt.Errorf("optimize: batch returned %d values for %d points", len(vals), len(xs))
+	}
+	copy(ys, vals)
+	return nil
+}
+
+func (ev *Evaluator) do(n int, point func(j int) []float64, fn func(j int)) error {
+	if ev == nil {
+		for j := 0; j < n; j++ {
+			fn(j)
+		}
+		return nil
+	}
+	var (
+		errMu sync.Mutex
+		err   error
+	)
+	call := func(j int) {
+		ev.wait()
+		if ev.Recover {
+			defer func() {
+				if r := recover(); r != nil {
+					errMu.Lock()
+					if err == nil {
+						err = &PanicError{X: point(j), Value: r}
+					}
+					errMu.Unlock()
+				}
+			}()
+		}
+		fn(j)
+	}
+	if ev.Workers <= 1 {
+		for j := 0; j < n; j++ {
+			call(j)
+		}
+		return err
+	}
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < ev.Workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				call(j)
+			}
+		}()
+	}
+	for j := 0; j < n; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// replay evaluates points with an Evaluator, then passes their values in
+// order through the wrappers of a method (monitor, targetWatch...), which
+// are not safe for concurrent use. Its fn is the innermost function of
+// the wrappers. repair is the Repair applied by f, applied to the points
+// given to a Batch.
+type replay struct {
+	ev      *Evaluator
+	f       func([]float64) float64
+	repair  Repair
+	pending []float64
+	err     error
+
+	timer           evalTimer
+	batches, points int
+}
+
+// newReplay returns a replay evaluating f, timed, with ev
+func newReplay(ev *Evaluator, f func([]float64) float64, repair Repair) *replay {
+	rp := &replay{ev: ev, repair: repair}
+	rp.f = rp.timer.wrap(f)
+	return rp
+}
+
+// stats sets the statistics of the evaluations in st
+func (rp *replay) stats(st *EvalStats) {
+	st.Batches, st.BatchPoints, st.FuncTime = rp.batches, rp.points, rp.timer.elapsed()
+}
+
+// fn returns the value of x, replayed if it has been evaluated by eval
+func (rp *replay) fn(x []float64) float64 {
+	if len(rp.pending) > 0 {
+		y := rp.pending[0]
+		rp.pending = rp.pending[1:]
+		return y
+	}
+	return rp.f(x)
+}
+
+// eval evaluates xs with the Evaluator and returns wrapped(xs[i]) in ys,
+// wrapped being a wrapper of fn. The first error is kept in err.
+func (rp *replay) eval(wrapped func([]float64) float64, xs [][]float64, ys []float64) []float64 {
+	if len(ys) != len(xs) {
+		ys = make([]float64, len(xs))
+	}
+	if rp.ev == nil {
+		for i, x := range xs {
+			ys[i] = wrapped(x)
+		}
+		return ys
+	}
+	pts := xs
+	if rp.ev.Batch != nil && rp.repair != nil {
+		pts = make([][]float64, len(xs))
+		for i, x := range xs {
+			pts[i] = append([]float64(nil), x...)
+			rp.repair.Repair(pts[i])
+		}
+	}
+	rp.batches++
+	rp.points += len(pts)
+	start := time.Now()
+	vals, err := rp.ev.Eval(rp.f, pts, nil)
+	if rp.ev.Batch != nil {
+		rp.timer.since(start)
+	}
+	if err != nil && rp.err == nil {
+		rp.err = err
+	}
+	rp.pending = vals
+	for i, x := range xs {
+		ys[i] = wrapped(x)
+	}
+	rp.pending = nil
+	return ys
+}
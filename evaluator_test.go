@@ -0,0 +1,157 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleEvaluator() {
+	f := func(x []float64) float64 { return x[0] * x[0] }
+	ys, err := NewEvaluator(4).Eval(f, [][]float64{{1}, {2}, {3}}, nil)
+	fmt.Println(ys, err)
+	// Output:
+	// [1 4 9] <nil>
+}
+
+func TestEvaluator(t *testing.T) {
+	xs := make([][]float64, 20)
+	for i := range xs {
+		xs[i] = []float64{float64(i)}
+	}
+	f := func(x []float64) float64 {
+		if x[0] == 7 {
+			panic("seven")
+		}
+		return 2 * x[0]
+	}
+	ev := &Evaluator{Workers: 4, Recover: true}
+	ys, err := ev.Eval(f, xs, nil)
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.X[0] != 7 || pe.Value != "seven" {
+		t.Fatalf("expected a PanicError at 7, got %v", err)
+	}
+	for i, y := range ys {
+		if want := 2 * float64(i); i == 7 && !math.IsNaN(y) || i != 7 && y != want {
+			t.Errorf("ys[%d]=%g", i, y)
+		}
+	}
+	if err := ev.Do(3, func(j int) { panic(j) }); err == nil {
+		t.Error("expected an error from Do")
+	}
+
+	ev = &Evaluator{Workers: 2, Rate: 200}
+	start := time.Now()
+	if _, err := ev.Eval(func(x []float64) float64 { return 0 }, xs[:5], nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("5 evaluations at 200/s took %s", elapsed)
+	}
+}
+
+func ExampleBatchFunc() {
+	calls := 0
+	// a vectorized objective evaluating all the points of a call at once
+	var batch BatchFunc = func(xs [][]float64) []float64 {
+		calls++
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = math.Abs(x[0]-3) + math.Abs(x[1]+2)
+		}
+		return ys
+	}
+	ls := NewLatticeSearch()
+	ls.Evaluator = NewBatchEvaluator(batch)
+	res := ls.Minimize(batch.Func(), []float64{0, 0})
+	fmt.Println(res.X, res.NFev, calls)
+	// Output:
+	// [3 -2] 19 7
+}
+
+func TestBatchFunc(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	var calls, points int
+	var batch BatchFunc = func(xs [][]float64) []float64 {
+		calls++
+		points += len(xs)
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = f(x)
+		}
+		return ys
+	}
+	newSPSA := func(ev *Evaluator) *SPSA {
+		sp := NewSPSA()
+		sp.MaxIter, sp.Src, sp.Evaluator, sp.GradAvg = 20, rand.NewSource(1), ev, 2
+		sp.Repair = RepairFunc(func(x []float64) { x[0] = math.Max(x[0], 0) })
+		return sp
+	}
+	want := newSPSA(nil).Minimize(f, []float64{0, 0})
+	if got := newSPSA(NewBatchEvaluator(batch)).Minimize(batch.Func(), []float64{0, 0}); !sameResult(got, want) {
+		t.Errorf("spsa with batch %+v, expected %+v", got, want)
+	}
+	// one call per iteration and for the calibration, plus x0
+	if calls != 20+1+1 || points != want.NFev {
+		t.Errorf("%d calls for %d points, %d evaluations", calls, points, want.NFev)
+	}
+
+	calls = 0
+	g := Gradient(f, []float64{0, 0}, &GradientOptions{Evaluator: NewBatchEvaluator(batch)})
+	if calls != 1 || math.Abs(g[0]+2) > 1e-6 || math.Abs(g[1]-4) > 1e-6 {
+		t.Errorf("gradient %g in %d calls", g, calls)
+	}
+
+	bad := &Evaluator{Batch: func(xs [][]float64) []float64 { return nil }}
+	if ys, err := bad.Eval(nil, [][]float64{{1}}, nil); err == nil || !math.IsNaN(ys[0]) {
+		t.Errorf("expected an error for a wrong number of values, got %v %v", ys, err)
+	}
+	bad = &Evaluator{Recover: true, Batch: func(xs [][]float64) []float64 { panic("gpu") }}
+	if _, err := bad.Eval(nil, [][]float64{{1}}, nil); err == nil {
+		t.Error("expected a PanicError")
+	}
+}
+
+func TestEvaluatorSolvers(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	newSPSA := func(ev *Evaluator) *SPSA {
+		sp := NewSPSA()
+		sp.MaxIter, sp.Src, sp.Evaluator = 50, rand.NewSource(1), ev
+		sp.GradAvg = 3
+		return sp
+	}
+	want := newSPSA(nil).Minimize(f, []float64{0, 0})
+	if got := newSPSA(NewEvaluator(4)).Minimize(f, []float64{0, 0}); !sameResult(got, want) {
+		t.Errorf("spsa with evaluator %+v, expected %+v", got, want)
+	}
+
+	lin := func(x []float64) float64 { return math.Abs(x[0]-7) + math.Abs(x[1]+3) + .1*x[0]*x[1] }
+	want = NewLatticeSearch().Minimize(lin, []float64{0, 0})
+	ls, err := NewLatticeSearchWith(WithEvaluator(NewEvaluator(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ls.Minimize(lin, []float64{0, 0}); !sameResult(got, want) {
+		t.Errorf("lattice with evaluator %+v, expected %+v", got, want)
+	}
+	ls.Evaluator = &Evaluator{Recover: true}
+	// x0 is evaluated in the calling goroutine, its neighbors by the Evaluator
+	boom := func(x []float64) float64 {
+		if x[0] != 0 || x[1] != 0 {
+			panic("boom")
+		}
+		return 0
+	}
+	if res := ls.Minimize(boom, []float64{0, 0}); res.Status != optimize.Failure {
+		t.Errorf("expected Failure, got %s", res.Status)
+	}
+
+	if err := WithEvaluator(nil)(&PowellMinimizer{}); err == nil {
+		t.Error("expected WithEvaluator to be unsupported by PowellMinimizer")
+	}
+}
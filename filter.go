@@ -0,0 +1,105 @@
+package optimize
+
+import "math"
+
+// Filter implements the Fletcher-Leyffer filter used as globalization strategy
+// by constrained solvers as an alternative to merit/penalty functions, eg
+// SQP.Filter.
+// A trial point with objective f and constraint violation h is acceptable if it
+// is not dominated by any pair (f,h) stored in the filter, ie it improves
+// either the objective or the constraint violation of each entry.
+// see R. Fletcher, S. Leyffer, Nonlinear programming without a penalty function, 2002
+type Filter struct {
+	// Beta is the envelope factor on constraint violation. A point is acceptable
+	// relative to entry (fk,hk) if h <= Beta*hk. Defaults to 0.99 if 0.
+	Beta float64
+	// Gamma is the envelope factor on the objective. A point is acceptable
+	// relative to entry (fk,hk) if f <= fk - Gamma*h. Defaults to 1e-5 if 0.
+	Gamma float64
+	// HMax is an upper bound on constraint violation. Points with h > HMax are
+	// never acceptable. HMax <= 0 means no upper bound.
+	HMax float64
+
+	F, H []float64
+}
+
+// NewFilter returns a *Filter with default envelope factors
+func NewFilter() *Filter {
+	return &Filter{Beta: .99, Gamma: 1e-5}
+}
+
+func (flt *Filter) factors() (beta, gamma float64) {
+	beta, gamma = flt.Beta, flt.Gamma
+	if beta == 0 {
+		beta = .99
+	}
+	if gamma == 0 {
+		gamma = 1e-5
+	}
+	return
+}
+
+// Len returns the number of entries in the filter
+func (flt *Filter) Len() int { return len(flt.F) }
+
+// Reset removes all entries from the filter
+func (flt *Filter) Reset() {
+	flt.F, flt.H = flt.F[:0], flt.H[:0]
+}
+
+// Acceptable returns true if (f,h) is acceptable to the filter
+func (flt *Filter) Acceptable(f, h float64) bool {
+	if math.IsNaN(f) || math.IsNaN(h) || h < 0 {
+		return false
+	}
+	if flt.HMax > 0 && h > flt.HMax {
+		return false
+	}
+	beta, gamma := flt.factors()
+	for k, fk := range flt.F {
+		hk := flt.H[k]
+		if !(h <= beta*hk || f <= fk-gamma*h) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add inserts (f,h) in the filter and removes the entries it dominates
+func (flt *Filter) Add(f, h float64) {
+	n := 0
+	for k, fk := range flt.F {
+		hk := flt.H[k]
+		if f <= fk && h <= hk {
+			continue
+		}
+		flt.F[n], flt.H[n] = fk, hk
+		n++
+	}
+	flt.F, flt.H = append(flt.F[:n], f), append(flt.H[:n], h)
+}
+
+// AcceptAndAdd adds (f,h) to the filter if it is acceptable and returns true
+// in this case.
+func (flt *Filter) AcceptAndAdd(f, h float64) bool {
+	if !flt.Acceptable(f, h) {
+		return false
+	}
+	flt.Add(f, h)
+	return true
+}
+
+// Violation returns the l1 constraint violation of inequality constraints
+// c(x) <= 0 and equality constraints ceq(x) = 0 given their values
+func Violation(c, ceq []float64) float64 {
+	h := 0.
+	for _, v := range c {
+		if v > 0 {
+			h += v
+		}
+	}
+	for _, v := range ceq {
+		h += math.Abs(v)
+	}
+	return h
+}
@@ -0,0 +1,39 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleFilter() {
+	flt := NewFilter()
+	fmt.Println(flt.AcceptAndAdd(10, 1))
+	fmt.Println(flt.AcceptAndAdd(11, 2))
+	fmt.Println(flt.AcceptAndAdd(12, .5))
+	fmt.Println(flt.AcceptAndAdd(9, 2))
+	fmt.Println(flt.AcceptAndAdd(8, .1), flt.Len())
+	// Output:
+	// true
+	// false
+	// true
+	// true
+	// true 1
+}
+
+func TestFilter(t *testing.T) {
+	flt := &Filter{HMax: 1}
+	if flt.Acceptable(0, 2) {
+		t.Error("h>HMax should not be acceptable")
+	}
+	flt.Add(1, .5)
+	if flt.Acceptable(1, .5) {
+		t.Error("same point should not be acceptable")
+	}
+	flt.Reset()
+	if flt.Len() != 0 || !flt.Acceptable(1, .5) {
+		t.Error("Reset failed")
+	}
+	if h := Violation([]float64{-1, 2}, []float64{-3}); h != 5 {
+		t.Errorf("Violation: got %g", h)
+	}
+}
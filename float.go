@@ -0,0 +1,251 @@
+//go:build go1.18
+// +build go1.18
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Float is the constraint of the generic scalar routines: float32 or
+// float64, or a type based on them
+type Float interface {
+	~float32 | ~float64
+}
+
+func absF[F Float](x F) F {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// epsilon returns the machine epsilon of F
+func epsilon[F Float]() F {
+	one, eps := F(1), F(1)
+	for one+eps/2 > one {
+		eps /= 2
+	}
+	return eps
+}
+
+// BrentGeneric is Brent in the precision of F, eg float32 for a single
+// precision pipeline, without converting f to float64. It stops when the
+// bracket can't shrink in F, even if tol is smaller than its spacing.
+func BrentGeneric[F Float](a, b, tol F, f func(F) F) (F, error) {
+	nan := F(math.NaN())
+	fa, fb := f(a), f(b)
+	if fa*fb >= 0 {
+		return nan, errors.New("brent: f(a) f(b) >= 0")
+	}
+	if absF(fa) < absF(fb) {
+		a, fa, b, fb = b, fb, a, fa
+	}
+	c, fc := a, fa
+	var d, s, fs F
+	mflag := true
+	for it := 0; fb != 0 && absF(b-a) > tol; {
+		it++
+		if it == 1000 {
+			return nan, fmt.Errorf("brent: it=%d", it)
+		}
+		if fa != fc && fb != fc {
+			// inverse quadratic interpolation
+			s = a*fb*fc/(fa-fb)/(fa-fc) +
+				b*fa*fc/(fb-fa)/(fb-fc) +
+				c*fa*fb/(fc-fa)/(fc-fb)
+		} else {
+			// secant
+			s = b - fb*(b-a)/(fb-fa)
+		}
+		between := ((3*a+b)/4 <= s && s <= b) || ((3*a+b)/4 >= s && s >= b)
+		var ineq bool
+		if between {
+			if mflag {
+				ineq = absF(s-b) < absF(b-c)/2
+			} else {
+				ineq = absF(s-b) < absF(c-d)/2
+			}
+		}
+		if !between || !ineq {
+			s = (a + b) / 2
+			mflag = true
+		} else {
+			mflag = false
+		}
+		if s == a || s == b {
+			// a and b are adjacent in F
+			break
+		}
+		fs = f(s)
+		d = c
+		c, fc = b, fb
+		if fa*fs < 0 {
+			b, fb = s, fs
+		} else {
+			a, fa = s, fs
+		}
+		if absF(fa) < absF(fb) {
+			a, fa, b, fb = b, fb, a, fa
+		}
+	}
+	return b, nil
+}
+
+// BissectionGeneric is Bissection in the precision of F, see BrentGeneric
+func BissectionGeneric[F Float](a, b, tol F, f func(F) F) (F, error) {
+	fa, fb := f(a), f(b)
+	switch {
+	case fa == 0:
+		return a, nil
+	case fb == 0:
+		return b, nil
+	case fa != fa || fb != fb || (fa < 0) == (fb < 0):
+		return F(math.NaN()), errNoSignChange
+	}
+	if absF(fa) < absF(fb) {
+		a, fa, b, fb = b, fb, a, fa
+	}
+	for fb != 0 && absF(b-a) > tol {
+		s := (a + b) / 2
+		if s == a || s == b {
+			break
+		}
+		fs := f(s)
+		if fs != 0 && (fa < 0) != (fs < 0) {
+			b, fb = s, fs
+		} else {
+			a, fa = s, fs
+		}
+		if absF(fa) < absF(fb) {
+			a, fa, b, fb = b, fb, a, fa
+		}
+	}
+	return b, nil
+}
+
+// GssGeneric is Gss in the precision of F
+func GssGeneric[F Float](f func(F) F, a, b, tol F) (F, F) {
+	if a > b {
+		a, b = b, a
+	}
+	invphi, invphi2 := F(invphi), F(invphi2)
+	h := b - a
+	c, d := a+invphi2*h, a+invphi*h
+	fc, fd := f(c), f(d)
+	for h >= tol && c < d {
+		h *= invphi
+		if fc < fd {
+			b, d, fd = d, c, fc
+			c = a + invphi2*h
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invphi*h
+			fd = f(d)
+		}
+	}
+	return a, b
+}
+
+// BrentMinimizeGeneric minimizes f on [a,b] in the precision of F, with the
+// golden section and parabolic steps of BrentMinimizer, starting from the
+// golden section point of [a,b] instead of searching a bracket. The
+// relative tolerance tol is floored at the square root of the epsilon of F.
+// It returns the minimum, its value, and the numbers of iterations and of
+// evaluations.
+func BrentMinimizeGeneric[F Float](f func(F) F, a, b, tol F, maxiter int) (x, fx F, iter, funcalls int) {
+	const cg = 0.3819660
+	if a > b {
+		a, b = b, a
+	}
+	eps := epsilon[F]()
+	if sqrtEps := F(math.Sqrt(float64(eps))); tol < sqrtEps {
+		tol = sqrtEps
+	}
+	mintol := eps * 10
+	x = a + cg*(b-a)
+	fx = f(x)
+	funcalls = 1
+	v, w, fv, fw := x, x, fx, fx
+	var deltax, rat F
+	for ; iter < maxiter; iter++ {
+		tol1 := tol*absF(x) + mintol
+		tol2 := 2 * tol1
+		xmid := (a + b) / 2
+		if absF(x-xmid) < tol2-(b-a)/2 {
+			break
+		}
+		if absF(deltax) <= tol1 {
+			// golden section step
+			if x >= xmid {
+				deltax = a - x
+			} else {
+				deltax = b - x
+			}
+			rat = cg * deltax
+		} else {
+			tmp1 := (x - w) * (fx - fv)
+			tmp2 := (x - v) * (fx - fw)
+			p := (x-v)*tmp2 - (x-w)*tmp1
+			tmp2 = 2 * (tmp2 - tmp1)
+			if tmp2 > 0 {
+				p = -p
+			}
+			tmp2 = absF(tmp2)
+			dxTemp := deltax
+			deltax = rat
+			if p > tmp2*(a-x) && p < tmp2*(b-x) && absF(p) < absF(tmp2*dxTemp/2) {
+				// parabolic step
+				rat = p / tmp2
+				if u := x + rat; u-a < tol2 || b-u < tol2 {
+					if xmid-x >= 0 {
+						rat = tol1
+					} else {
+						rat = -tol1
+					}
+				}
+			} else {
+				if x >= xmid {
+					deltax = a - x
+				} else {
+					deltax = b - x
+				}
+				rat = cg * deltax
+			}
+		}
+		var u F
+		switch {
+		case absF(rat) >= tol1:
+			u = x + rat
+		case rat >= 0:
+			u = x + tol1
+		default:
+			u = x - tol1
+		}
+		fu := f(u)
+		funcalls++
+		if fu > fx {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			if fu <= fw || w == x {
+				v, w, fv, fw = w, u, fw, fu
+			} else if fu <= fv || v == x || v == w {
+				v, fv = u, fu
+			}
+		} else {
+			if u >= x {
+				a = x
+			} else {
+				b = x
+			}
+			v, w, x, fv, fw, fx = w, x, u, fw, fx, fu
+		}
+	}
+	return
+}
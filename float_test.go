@@ -0,0 +1,70 @@
+//go:build go1.18
+// +build go1.18
+
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleBrentGeneric() {
+	// a single precision pipeline
+	f := func(x float32) float32 { return (x + 3) * (x - 1) * (x - 1) }
+	x, err := BrentGeneric(-4, 4./3, 1e-9, f)
+	fmt.Printf("%T %.4g %v\n", x, x, err)
+	xmin, fmin, _, _ := BrentMinimizeGeneric(func(x float32) float32 { return (x - 2) * (x - 2) }, 0, 5, 1e-6, 500)
+	fmt.Printf("%.4g %.2g\n", xmin, fmin)
+	// Output:
+	// float32 -3 <nil>
+	// 2 0
+}
+
+func TestFloatGeneric(t *testing.T) {
+	root := func(x float64) float64 { return x*x*x - 2 }
+	root32 := func(x float32) float32 { return x*x*x - 2 }
+	want := math.Cbrt(2)
+	for name, solve := range map[string]func(float32, float32, float32, func(float32) float32) (float32, error){
+		"brent": BrentGeneric[float32], "bissection": BissectionGeneric[float32],
+	} {
+		// tol below the spacing of float32 must not loop
+		x, err := solve(0, 2, 1e-12, root32)
+		if err != nil || math.Abs(float64(x)-want) > 1e-6 {
+			t.Errorf("%s: got %g %v, expected %g", name, x, err, want)
+		}
+		if _, err := solve(2, 3, 1e-6, root32); err == nil {
+			t.Errorf("%s: expected an error without a sign change", name)
+		}
+	}
+	x64, _ := Brent(0, 2, 1e-12, root, nil)
+	if x, _ := BrentGeneric(0, 2, 1e-12, root); x != x64 {
+		t.Errorf("BrentGeneric[float64] got %g, Brent %g", x, x64)
+	}
+	x64, _ = Bissection(0, 2, 1e-12, root, nil)
+	if x, _ := BissectionGeneric(0, 2, 1e-12, root); x != x64 {
+		t.Errorf("BissectionGeneric[float64] got %g, Bissection %g", x, x64)
+	}
+
+	quad := func(x float32) float32 { return (x - 2) * (x - 2) }
+	if a, b := GssGeneric(quad, 5, 1, 1e-9); a > 2 || b < 2 || b-a > 1e-5 {
+		t.Errorf("GssGeneric got [%g,%g]", a, b)
+	}
+	a64, b64 := Gss(func(x float64) float64 { return (x - 2) * (x - 2) }, 1, 5, 1e-6, nil)
+	if a, b := GssGeneric(func(x float64) float64 { return (x - 2) * (x - 2) }, 1, 5, 1e-6); math.Abs(a-a64) > 1e-12 || math.Abs(b-b64) > 1e-12 {
+		t.Errorf("GssGeneric[float64] got [%g,%g], Gss [%g,%g]", a, b, a64, b64)
+	}
+
+	cosine := func(x float64) float64 { return -math.Cos(x - 1) }
+	x, fx, iter, nfev := BrentMinimizeGeneric(cosine, -1, 2, 1e-10, 500)
+	if math.Abs(x-1) > 1e-6 || fx != cosine(x) || iter == 0 || nfev != iter+1 {
+		t.Errorf("BrentMinimizeGeneric[float64] got f(%g)=%g in %d iterations, %d evaluations", x, fx, iter, nfev)
+	}
+	x32, _, _, _ := BrentMinimizeGeneric(func(x float32) float32 { return float32(cosine(float64(x))) }, -1, 2, 0, 500)
+	if math.Abs(float64(x32)-1) > 1e-3 {
+		t.Errorf("BrentMinimizeGeneric[float32] got %g", x32)
+	}
+	if _, _, iter, _ := BrentMinimizeGeneric(cosine, -1, 2, 1e-10, 3); iter != 3 {
+		t.Errorf("expected 3 iterations, got %d", iter)
+	}
+}
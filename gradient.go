@@ -0,0 +1,215 @@
+package optimize
+
+import (
+	"math"
+)
+
+// FDFormula is a finite difference scheme
+type FDFormula int
+
+const (
+	// FDForward is the first order forward difference (f(x+h)-f(x))/h
+	FDForward FDFormula = iota
+	// FDCentral is the second order central difference (f(x+h)-f(x-h))/2h
+	FDCentral
+	// FDCentral4 is the fourth order five points central difference
+	FDCentral4
+)
+
+// GradientOptions are finite difference settings for Gradient.
+// A nil *GradientOptions means FDCentral with default step.
+type GradientOptions struct {
+	Formula FDFormula
+	// Step is the absolute step per coordinate. if nil, the step of coordinate i
+	// is RelStep*max(|x[i]|,1).
+	Step []float64
+	// RelStep defaults to eps^(1/2) for FDForward, eps^(1/3) for FDCentral and
+	// eps^(1/5) for FDCentral4.
+	RelStep float64
+	// Xmin and Xmax are optional bounds. Near a bound, one-sided differences
+	// are used so that f is never evaluated outside of [Xmin,Xmax], with a
+	// smaller step in a box narrower than the stencil. The derivative along
+	// a fixed coordinate, Xmin[i] == Xmax[i], is 0.
+	Xmin, Xmax []float64
+	// OriginKnown indicates that OriginValue is f(x)
+	OriginKnown bool
+	OriginValue float64
+	// Concurrent is the number of goroutines evaluating f. if > 1, f must be
+	// safe for concurrent use. The gradient doesn't depend on Concurrent.
+	Concurrent int
+	// Evaluator, if not nil, evaluates f instead of Concurrent goroutines,
+	// eg with its Batch
+	Evaluator *Evaluator
+}
+
+// evaluator returns the Evaluator of opts, nil for serial evaluations
+func (opts *GradientOptions) evaluator() *Evaluator {
+	if opts.Evaluator == nil && opts.Concurrent > 1 {
+		return NewEvaluator(opts.Concurrent)
+	}
+	return opts.Evaluator
+}
+
+type fdPoint struct {
+	k, coeff float64
+}
+
+var (
+	fdForward   = []fdPoint{{1, 1}, {0, -1}}
+	fdBackward  = []fdPoint{{0, 1}, {-1, -1}}
+	fdCentral   = []fdPoint{{-1, -.5}, {1, .5}}
+	fdCentral4  = []fdPoint{{-2, 1. / 12}, {-1, -8. / 12}, {1, 8. / 12}, {2, -1. / 12}}
+	fdForward2  = []fdPoint{{0, -1.5}, {1, 2}, {2, -.5}}
+	fdBackward2 = []fdPoint{{0, 1.5}, {-1, -2}, {-2, .5}}
+)
+
+func (opts *GradientOptions) relStep() float64 {
+	if opts.RelStep > 0 {
+		return opts.RelStep
+	}
+	const eps = 2.220446049250313e-16
+	switch opts.Formula {
+	case FDForward:
+		return math.Sqrt(eps)
+	case FDCentral4:
+		return math.Pow(eps, 1./5)
+	default:
+		return math.Cbrt(eps)
+	}
+}
+
+// step returns the step for coordinate i of x
+func (opts *GradientOptions) step(x []float64, i int) float64 {
+	if i < len(opts.Step) && opts.Step[i] > 0 {
+		return opts.Step[i]
+	}
+	h := opts.relStep() * math.Max(math.Abs(x[i]), 1)
+	// make h exactly representable
+	return (x[i] + h) - x[i]
+}
+
+// stencil returns the difference scheme for coordinate i and its step,
+// taking bounds into account: in a box narrower than the stencil, a
+// one-sided scheme on the side with more room and a step fitting in it.
+// Outside of the box, a one-sided scheme steps towards it.
+func (opts *GradientOptions) stencil(x []float64, i int, h float64) ([]fdPoint, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if i < len(opts.Xmin) {
+		lo = opts.Xmin[i]
+	}
+	if i < len(opts.Xmax) {
+		hi = opts.Xmax[i]
+	}
+	var st []fdPoint
+	switch opts.Formula {
+	case FDForward:
+		st = fdForward
+	case FDCentral4:
+		st = fdCentral4
+	default:
+		st = fdCentral
+	}
+	feasible := func(st []fdPoint, h float64) bool {
+		for _, p := range st {
+			if xk := x[i] + p.k*h; xk < lo || xk > hi {
+				return false
+			}
+		}
+		return true
+	}
+	forward, backward, width := fdForward2, fdBackward2, 2.
+	if opts.Formula == FDForward {
+		forward, backward, width = fdForward, fdBackward, 1
+	}
+	switch {
+	case x[i] < lo || x[i] > hi:
+		// outside of the box no stencil is feasible: step towards it,
+		// without crossing the other bound
+		room, st := hi-x[i], forward
+		if x[i] > hi {
+			room, st = x[i]-lo, backward
+		}
+		if !(room > 0) {
+			return nil, h
+		}
+		return st, math.Min(h, room/width)
+	case feasible(st, h):
+		return st, h
+	}
+	fallbacks := [][]fdPoint{fdForward2, fdBackward2, fdForward, fdBackward}
+	if opts.Formula == FDForward {
+		fallbacks = [][]fdPoint{fdBackward}
+	}
+	for _, st = range fallbacks {
+		if feasible(st, h) {
+			return st, h
+		}
+	}
+	room, st := hi-x[i], forward
+	if x[i]-lo > room {
+		room, st = x[i]-lo, backward
+	}
+	if !(room > 0) {
+		// a fixed coordinate
+		return nil, h
+	}
+	for h = room / width; !feasible(st, h); h = math.Nextafter(h, 0) {
+	}
+	return st, h
+}
+
+// Gradient estimates the gradient of f at x using finite differences.
+// opts may be nil.
+func Gradient(f func([]float64) float64, x []float64, opts *GradientOptions) []float64 {
+	if opts == nil {
+		opts = &GradientOptions{Formula: FDCentral}
+	}
+	n := len(x)
+	type eval struct {
+		i       int
+		k, step float64
+		coeff   float64
+		y       float64
+	}
+	var evals []eval
+	needOrigin := false
+	steps := make([]float64, n)
+	for i := range x {
+		var st []fdPoint
+		st, steps[i] = opts.stencil(x, i, opts.step(x, i))
+		for _, p := range st {
+			if p.k == 0 {
+				needOrigin = true
+			}
+			evals = append(evals, eval{i: i, k: p.k, step: steps[i], coeff: p.coeff})
+		}
+	}
+	f0 := opts.OriginValue
+	if needOrigin && !opts.OriginKnown {
+		f0 = f(x)
+	}
+	var xs [][]float64
+	for _, e := range evals {
+		if e.k != 0 {
+			xk := append([]float64(nil), x...)
+			xk[e.i] += e.k * e.step
+			xs = append(xs, xk)
+		}
+	}
+	ys, _ := opts.evaluator().Eval(f, xs, nil)
+	for j := range evals {
+		if evals[j].k == 0 {
+			evals[j].y = f0
+		} else {
+			evals[j].y, ys = ys[0], ys[1:]
+		}
+	}
+	grad := make([]float64, n)
+	for _, e := range evals {
+		grad[e.i] += e.coeff * e.y
+	}
+	for i := range grad {
+		grad[i] /= steps[i]
+	}
+	return grad
+}
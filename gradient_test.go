@@ -0,0 +1,121 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func rosen(x []float64) float64 {
+	s := 0.
+	for i := 0; i < len(x)-1; i++ {
+		a, b := 1-x[i], x[i+1]-x[i]*x[i]
+		s += a*a + 100*b*b
+	}
+	return s
+}
+
+func rosenGrad(grad, x []float64) {
+	for i := range grad {
+		grad[i] = 0
+	}
+	for i := 0; i < len(x)-1; i++ {
+		b := x[i+1] - x[i]*x[i]
+		grad[i] += -2*(1-x[i]) - 400*x[i]*b
+		grad[i+1] += 200 * b
+	}
+}
+
+func ExampleGradient() {
+	x := []float64{-1.2, 1, 1.5}
+	for _, opts := range []*GradientOptions{
+		{Formula: FDForward},
+		{Formula: FDCentral},
+		{Formula: FDCentral4, Concurrent: 3},
+		{Formula: FDCentral, Xmax: []float64{-1.2, 1, 1.5}},
+	} {
+		fmt.Printf("%.3f\n", Gradient(rosen, x, opts))
+	}
+	// Output:
+	// [-215.600 -288.000 100.000]
+	// [-215.600 -288.000 100.000]
+	// [-215.600 -288.000 100.000]
+	// [-215.600 -288.000 100.000]
+}
+
+func TestGradientBounds(t *testing.T) {
+	xmin, xmax := []float64{0, 0}, []float64{1, 1}
+	f := func(x []float64) float64 {
+		for i := range x {
+			if x[i] < xmin[i] || x[i] > xmax[i] {
+				t.Errorf("f evaluated out of bounds at %g", x)
+			}
+		}
+		return math.Sqrt(x[0]) + x[1]*x[1]
+	}
+	for _, formula := range []FDFormula{FDForward, FDCentral, FDCentral4} {
+		g := Gradient(f, []float64{1, 0}, &GradientOptions{Formula: formula, Xmin: xmin, Xmax: xmax})
+		if math.Abs(g[0]-.5) > 1e-5 || math.Abs(g[1]) > 1e-5 {
+			t.Errorf("formula %d: bad gradient %g", formula, g)
+		}
+	}
+}
+
+func TestGradientNarrowBox(t *testing.T) {
+	// boxes narrower than the steps, and a fixed coordinate
+	xmin, xmax := []float64{1, -1e-9, 2}, []float64{1 + 1e-9, 1e-9, 2}
+	f := func(x []float64) float64 {
+		for i := range x {
+			if x[i] < xmin[i] || x[i] > xmax[i] {
+				t.Errorf("f evaluated out of bounds at %g", x)
+			}
+		}
+		return x[0]*x[0] + 3*x[1] + x[2]
+	}
+	for _, formula := range []FDFormula{FDForward, FDCentral, FDCentral4} {
+		for _, x := range [][]float64{{1, 0, 2}, {1 + 1e-9, 1e-9, 2}, {1 + 4e-10, -1e-9, 2}} {
+			g := Gradient(f, x, &GradientOptions{Formula: formula, Xmin: xmin, Xmax: xmax})
+			if math.Abs(g[0]-2) > 1e-5 || math.Abs(g[1]-3) > 1e-5 || g[2] != 0 {
+				t.Errorf("formula %d at %g: bad gradient %g", formula, x, g)
+			}
+		}
+	}
+}
+
+func TestGradientOutsideBox(t *testing.T) {
+	f := func(x []float64) float64 {
+		if x[0] > 1e-9 {
+			t.Errorf("f evaluated beyond the upper bound at %g", x)
+		}
+		return x[0] * x[0]
+	}
+	for _, formula := range []FDFormula{FDForward, FDCentral, FDCentral4} {
+		for _, x0 := range []float64{-1, 2} {
+			g := Gradient(func(x []float64) float64 { return x[0] * x[0] }, []float64{x0}, &GradientOptions{Formula: formula, Xmin: []float64{0}, Xmax: []float64{1}})
+			if math.Abs(g[0]-2*x0) > 1e-5 {
+				t.Errorf("formula %d at %g: bad gradient %g", formula, x0, g)
+			}
+		}
+		// the stencil doesn't cross the other bound
+		if g := Gradient(f, []float64{-1.5}, &GradientOptions{Formula: formula, Xmin: []float64{0}, Xmax: []float64{1e-9}}); math.Abs(g[0]+3) > 1e-5 {
+			t.Errorf("formula %d: bad gradient %g", formula, g)
+		}
+	}
+}
+
+func TestGradientConcurrent(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	x := []float64{-1.2, 1, 1.5, .3, -.7}
+	want := Gradient(rosen, x, &GradientOptions{Formula: FDCentral4})
+	for _, procs := range []int{1, 4} {
+		runtime.GOMAXPROCS(procs)
+		for _, concurrent := range []int{2, 3, 16} {
+			if g := Gradient(rosen, x, &GradientOptions{Formula: FDCentral4, Concurrent: concurrent}); !floats.Equal(g, want) {
+				t.Errorf("Concurrent=%d GOMAXPROCS=%d: got %g, expected %g", concurrent, procs, g, want)
+			}
+		}
+	}
+}
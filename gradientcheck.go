@@ -0,0 +1,83 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+)
+
+// GradientCheckOptions are the settings of CheckGradient
+type GradientCheckOptions struct {
+	// A component fails if both its absolute error exceeds AbsTol and its
+	// relative error exceeds RelTol. defaults are 1e-8 and 1e-5.
+	AbsTol, RelTol float64
+	// Gradient are the finite difference settings. nil means FDCentral.
+	Gradient *GradientOptions
+}
+
+// GradientCheck is the result of CheckGradient
+type GradientCheck struct {
+	Analytic, Numeric []float64
+	AbsErr, RelErr    []float64
+	// Failed are the indices of the components exceeding tolerances
+	Failed []int
+	// Worst is the index of the component with the largest relative error
+	Worst int
+}
+
+// OK returns true if no component failed
+func (gc *GradientCheck) OK() bool { return len(gc.Failed) == 0 }
+
+// String is a report of the worst offender
+func (gc *GradientCheck) String() string {
+	status := "ok"
+	if !gc.OK() {
+		status = fmt.Sprintf("%d/%d components failed", len(gc.Failed), len(gc.Analytic))
+	}
+	if len(gc.Analytic) == 0 {
+		return "gradient check: " + status
+	}
+	w := gc.Worst
+	return fmt.Sprintf("gradient check: %s. worst component %d: analytic=%.6g numeric=%.6g abs.err=%.3g rel.err=%.3g",
+		status, w, gc.Analytic[w], gc.Numeric[w], gc.AbsErr[w], gc.RelErr[w])
+}
+
+// CheckGradient compares grad with a finite difference estimate of the gradient of f at x.
+// opts may be nil.
+func CheckGradient(f func([]float64) float64, grad func(grad, x []float64), x []float64, opts *GradientCheckOptions) *GradientCheck {
+	if opts == nil {
+		opts = &GradientCheckOptions{}
+	}
+	absTol, relTol := opts.AbsTol, opts.RelTol
+	if absTol <= 0 {
+		absTol = 1e-8
+	}
+	if relTol <= 0 {
+		relTol = 1e-5
+	}
+	n := len(x)
+	gc := &GradientCheck{
+		Analytic: make([]float64, n),
+		Numeric:  Gradient(f, x, opts.Gradient),
+		AbsErr:   make([]float64, n),
+		RelErr:   make([]float64, n),
+	}
+	grad(gc.Analytic, x)
+	for i, a := range gc.Analytic {
+		num := gc.Numeric[i]
+		gc.AbsErr[i] = math.Abs(a - num)
+		scale := math.Max(math.Abs(a), math.Abs(num))
+		if scale > 0 {
+			gc.RelErr[i] = gc.AbsErr[i] / scale
+		}
+		if math.IsNaN(a) {
+			gc.AbsErr[i], gc.RelErr[i] = math.Inf(1), math.Inf(1)
+		}
+		if gc.AbsErr[i] > absTol && gc.RelErr[i] > relTol {
+			gc.Failed = append(gc.Failed, i)
+		}
+		if gc.RelErr[i] > gc.RelErr[gc.Worst] {
+			gc.Worst = i
+		}
+	}
+	return gc
+}
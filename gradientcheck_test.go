@@ -0,0 +1,19 @@
+package optimize
+
+import (
+	"fmt"
+)
+
+func ExampleCheckGradient() {
+	x := []float64{-1.2, 1, 1.5}
+	fmt.Println(CheckGradient(rosen, rosenGrad, x, nil).OK())
+
+	badGrad := func(grad, x []float64) {
+		rosenGrad(grad, x)
+		grad[1] *= 1.01
+	}
+	fmt.Println(CheckGradient(rosen, badGrad, x, nil))
+	// Output:
+	// true
+	// gradient check: 1/3 components failed. worst component 1: analytic=-290.88 numeric=-288 abs.err=2.88 rel.err=0.0099
+}
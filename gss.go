@@ -32,17 +32,21 @@ var (
 // (1.9999959837979107, 2.0000050911830893)
 // '''
 func Gss(f func(float64) float64, a, b, tol float64, logger *log.Logger) (float64, float64) {
-	return gss(f, a, b, tol, nan, nan, nan, nan, nan, logger)
+	var trace func(it int, a, b float64)
+	if logger != nil {
+		trace = func(it int, a, b float64) { logger.Printf("%d\t%9.6g\t%9.6g\n", it, a, b) }
+	}
+	return gss(f, a, b, tol, nan, nan, nan, nan, nan, trace)
 }
-func gss(f func(float64) float64, a, b, tol, h, c, d, fc, fd float64, logger *log.Logger) (float64, float64) {
+func gss(f func(float64) float64, a, b, tol, h, c, d, fc, fd float64, trace func(it int, a, b float64)) (float64, float64) {
 	if a > b {
 		a, b = b, a
 	}
 	h = b - a
 	it := 0
 	for {
-		if logger != nil {
-			logger.Printf("%d\t%9.6g\t%9.6g\n", it, a, b)
+		if trace != nil {
+			trace(it, a, b)
 		}
 		it++
 		if h < tol {
@@ -0,0 +1,293 @@
+package optimize
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// HessianOptions are finite difference settings for Hessian.
+// A nil *HessianOptions means differences of function values with default step.
+type HessianOptions struct {
+	// Grad, if not nil, is used to compute the Hessian by central differences
+	// of gradients instead of differences of function values.
+	Grad func(grad, x []float64)
+	// Step is the absolute step per coordinate. if nil, the step of coordinate i
+	// is RelStep*max(|x[i]|,1).
+	Step []float64
+	// RelStep defaults to eps^(1/3) if Grad is set and eps^(1/4) otherwise.
+	RelStep float64
+	// Concurrent is the number of goroutines evaluating f or Grad. if > 1,
+	// they must be safe for concurrent use. The Hessian doesn't depend on Concurrent.
+	Concurrent int
+	// Evaluator, if not nil, evaluates f or Grad instead of Concurrent goroutines
+	Evaluator *Evaluator
+	// Sparsity, if not nil, is the Hessian sparsity pattern: Sparsity[i] lists
+	// the indices j != i for which H[i][j] may be non zero. Other entries are
+	// assumed zero and are not estimated. With Grad, structurally orthogonal
+	// columns are grouped so that a pair of gradient evaluations is needed per
+	// group rather than per column.
+	Sparsity [][]int
+}
+
+func (opts *HessianOptions) evaluator() *Evaluator {
+	if opts.Evaluator == nil && opts.Concurrent > 1 {
+		return NewEvaluator(opts.Concurrent)
+	}
+	return opts.Evaluator
+}
+
+func (opts *HessianOptions) step(x []float64, i int) float64 {
+	if i < len(opts.Step) && opts.Step[i] > 0 {
+		return opts.Step[i]
+	}
+	const eps = 2.220446049250313e-16
+	rel := opts.RelStep
+	if rel <= 0 {
+		if opts.Grad != nil {
+			rel = math.Cbrt(eps)
+		} else {
+			rel = math.Pow(eps, .25)
+		}
+	}
+	h := rel * math.Max(math.Abs(x[i]), 1)
+	return (x[i] + h) - x[i]
+}
+
+// Hessian estimates the Hessian of f at x using central finite differences.
+// The result is symmetrized. opts may be nil.
+func Hessian(f func([]float64) float64, x []float64, opts *HessianOptions) *mat.SymDense {
+	if opts == nil {
+		opts = &HessianOptions{}
+	}
+	n := len(x)
+	h := make([]float64, n)
+	for i := range h {
+		h[i] = opts.step(x, i)
+	}
+	hess := mat.NewSymDense(n, nil)
+	if opts.Grad != nil && opts.Sparsity != nil {
+		return sparseHessianGrad(hess, x, h, opts)
+	}
+	if opts.Grad != nil {
+		// column j is (g(x+hj ej)-g(x-hj ej))/2hj
+		grads := make([][]float64, 2*n)
+		opts.evaluator().Do(2*n, func(k int) {
+			j, sign := k/2, float64(1-2*(k%2))
+			xk := make([]float64, n)
+			copy(xk, x)
+			xk[j] += sign * h[j]
+			grads[k] = make([]float64, n)
+			opts.Grad(grads[k], xk)
+		})
+		for i := 0; i < n; i++ {
+			for j := i; j < n; j++ {
+				hij := (grads[2*j][i] - grads[2*j+1][i]) / (2 * h[j])
+				hji := (grads[2*i][j] - grads[2*i+1][j]) / (2 * h[i])
+				hess.SetSym(i, j, (hij+hji)/2)
+			}
+		}
+		return hess
+	}
+	// each evaluation is f(x + si hi ei + sj hj ej)
+	type eval struct {
+		i, j   int
+		si, sj float64
+		y      float64
+	}
+	var evals []eval
+	for i := 0; i < n; i++ {
+		evals = append(evals, eval{i: i, j: -1, si: 1}, eval{i: i, j: -1, si: -1})
+		for j := i + 1; j < n; j++ {
+			if opts.Sparsity != nil && !hasIndex(opts.Sparsity[i], j) {
+				continue
+			}
+			evals = append(evals,
+				eval{i: i, j: j, si: 1, sj: 1}, eval{i: i, j: j, si: 1, sj: -1},
+				eval{i: i, j: j, si: -1, sj: 1}, eval{i: i, j: j, si: -1, sj: -1})
+		}
+	}
+	f0 := f(x)
+	opts.evaluator().Do(len(evals), func(k int) {
+		e := &evals[k]
+		xk := make([]float64, n)
+		copy(xk, x)
+		xk[e.i] += e.si * h[e.i]
+		if e.j >= 0 {
+			xk[e.j] += e.sj * h[e.j]
+		}
+		e.y = f(xk)
+	})
+	for _, e := range evals {
+		if e.j < 0 {
+			hess.SetSym(e.i, e.i, hess.At(e.i, e.i)+e.y/(h[e.i]*h[e.i]))
+		} else {
+			hess.SetSym(e.i, e.j, hess.At(e.i, e.j)+e.si*e.sj*e.y/(4*h[e.i]*h[e.j]))
+		}
+	}
+	for i := 0; i < n; i++ {
+		hess.SetSym(i, i, hess.At(i, i)-2*f0/(h[i]*h[i]))
+	}
+	return hess
+}
+
+func hasIndex(idx []int, j int) bool {
+	for _, i := range idx {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
+
+// colorColumns groups the columns of a symmetric sparsity pattern so that no
+// two columns of a group have a non zero in the same row (Curtis, Powell, Reid).
+// It returns the groups.
+func colorColumns(sparsity [][]int) [][]int {
+	n := len(sparsity)
+	rows := func(j int) []int { return append([]int{j}, sparsity[j]...) }
+	// rowsOf[i] are the columns having a non zero in row i
+	rowsOf := make([][]int, n)
+	for j := 0; j < n; j++ {
+		for _, i := range rows(j) {
+			rowsOf[i] = append(rowsOf[i], j)
+		}
+	}
+	color := make([]int, n)
+	var groups [][]int
+	for j := 0; j < n; j++ {
+		used := map[int]bool{}
+		for _, i := range rows(j) {
+			for _, k := range rowsOf[i] {
+				if k < j {
+					used[color[k]] = true
+				}
+			}
+		}
+		c := 0
+		for used[c] {
+			c++
+		}
+		color[j] = c
+		if c == len(groups) {
+			groups = append(groups, nil)
+		}
+		groups[c] = append(groups[c], j)
+	}
+	return groups
+}
+
+func sparseHessianGrad(hess *mat.SymDense, x, h []float64, opts *HessianOptions) *mat.SymDense {
+	n := len(x)
+	groups := colorColumns(opts.Sparsity)
+	grads := make([][]float64, 2*len(groups))
+	opts.evaluator().Do(2*len(groups), func(k int) {
+		g, sign := groups[k/2], float64(1-2*(k%2))
+		xk := make([]float64, n)
+		copy(xk, x)
+		for _, j := range g {
+			xk[j] += sign * h[j]
+		}
+		grads[k] = make([]float64, n)
+		opts.Grad(grads[k], xk)
+	})
+	// col(c,i,j) estimates H[i][j] from the gradients of group c containing column j
+	col := func(c, i, j int) float64 {
+		return (grads[2*c][i] - grads[2*c+1][i]) / (2 * h[j])
+	}
+	colorOf := make([]int, n)
+	for c, g := range groups {
+		for _, j := range g {
+			colorOf[j] = c
+		}
+	}
+	for j := 0; j < n; j++ {
+		hess.SetSym(j, j, col(colorOf[j], j, j))
+		for _, i := range opts.Sparsity[j] {
+			if i < j {
+				hess.SetSym(i, j, (col(colorOf[j], i, j)+col(colorOf[i], j, i))/2)
+			}
+		}
+	}
+	return hess
+}
+
+// BFGSHessian accumulates a BFGS approximation of the Hessian from the
+// iterates and gradients of a quasi-Newton run.
+type BFGSHessian struct {
+	H            *mat.SymDense
+	xPrev, gPrev []float64
+	s, y         []float64
+}
+
+// NewBFGSHessian returns a *BFGSHessian initialized with scale*I
+func NewBFGSHessian(dim int, scale float64) *BFGSHessian {
+	return &BFGSHessian{H: scaledIdentity(dim, scale)}
+}
+
+func scaledIdentity(dim int, scale float64) *mat.SymDense {
+	h := mat.NewSymDense(dim, nil)
+	for i := 0; i < dim; i++ {
+		h.SetSym(i, i, scale)
+	}
+	return h
+}
+
+// Update applies the BFGS update with step s=x_{k+1}-x_k and gradient change
+// y=g_{k+1}-g_k. The update is skipped and false returned if the curvature
+// condition s.y > 0 does not hold. If H is nil, the first update starts from
+// the identity scaled by y.y/s.y (Nocedal & Wright 6.20).
+func (bh *BFGSHessian) Update(s, y []float64) bool {
+	sy := floats.Dot(s, y)
+	if !(sy > 1e-10*floats.Norm(s, 2)*floats.Norm(y, 2)) {
+		return false
+	}
+	n := len(s)
+	if bh.H == nil {
+		scale := floats.Dot(y, y) / sy
+		if math.IsInf(scale, 0) {
+			scale = 1
+		}
+		bh.H = scaledIdentity(n, scale)
+	}
+	sv, yv := mat.NewVecDense(n, s), mat.NewVecDense(n, y)
+	var hs mat.VecDense
+	hs.MulVec(bh.H, sv)
+	shs := mat.Dot(sv, &hs)
+	// H = H - (Hs)(Hs)ᵀ/(sᵀHs) + yyᵀ/(sᵀy)
+	bh.H.SymRankOne(bh.H, -1/shs, &hs)
+	bh.H.SymRankOne(bh.H, 1/sy, yv)
+	return true
+}
+
+// Observe records the iterate x and its gradient grad and updates the
+// approximation using the previous observation if any.
+func (bh *BFGSHessian) Observe(x, grad []float64) bool {
+	ok := false
+	if bh.xPrev != nil {
+		bh.s = resize(bh.s, len(x))
+		bh.y = resize(bh.y, len(x))
+		floats.SubTo(bh.s, x, bh.xPrev)
+		floats.SubTo(bh.y, grad, bh.gPrev)
+		ok = bh.Update(bh.s, bh.y)
+	}
+	bh.xPrev = append(bh.xPrev[:0], x...)
+	bh.gPrev = append(bh.gPrev[:0], grad...)
+	return ok
+}
+
+// Hessian returns a copy of the current approximation. Before the first
+// update, it is the identity of the dimension of the observations, nil
+// without observation.
+func (bh *BFGSHessian) Hessian() *mat.SymDense {
+	if bh.H == nil {
+		if bh.xPrev == nil {
+			return nil
+		}
+		return scaledIdentity(len(bh.xPrev), 1)
+	}
+	h := mat.NewSymDense(symmetricDim(bh.H), nil)
+	h.CopySym(bh.H)
+	return h
+}
@@ -0,0 +1,123 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleHessian() {
+	x := []float64{1, 1}
+	h := Hessian(rosen, x, nil)
+	fmt.Printf("%.2f\n", mat.Formatted(h))
+	h = Hessian(rosen, x, &HessianOptions{Grad: rosenGrad, Concurrent: 2})
+	fmt.Printf("%.2f\n", mat.Formatted(h))
+	// Output:
+	// ⎡ 802.00  -400.00⎤
+	// ⎣-400.00   200.00⎦
+	// ⎡ 802.00  -400.00⎤
+	// ⎣-400.00   200.00⎦
+}
+
+func TestBFGSHessian(t *testing.T) {
+	// iterates of a quadratic with Hessian A: the secant equation H s = y must hold
+	A := mat.NewSymDense(2, []float64{3, 1, 1, 2})
+	grad := func(x []float64) []float64 {
+		g := mat.NewVecDense(2, nil)
+		g.MulVec(A, mat.NewVecDense(2, x))
+		return g.RawVector().Data
+	}
+	bh := &BFGSHessian{}
+	xs := [][]float64{{1, 1}, {.5, .8}, {.1, -.2}}
+	for _, x := range xs {
+		bh.Observe(x, grad(x))
+	}
+	s := []float64{.1 - .5, -.2 - .8}
+	var hs mat.VecDense
+	hs.MulVec(bh.Hessian(), mat.NewVecDense(2, s))
+	y := grad(s)
+	if !floats.EqualApprox(hs.RawVector().Data, y, 1e-10) {
+		t.Errorf("secant equation: got %g want %g", hs.RawVector().Data, y)
+	}
+	if bh.Update([]float64{1, 0}, []float64{-1, 0}) {
+		t.Error("update with negative curvature should be skipped")
+	}
+	if math.IsNaN(bh.H.At(0, 0)) {
+		t.Error("NaN in approximation")
+	}
+}
+
+func TestBFGSHessianBeforeUpdate(t *testing.T) {
+	bh := &BFGSHessian{}
+	if h := bh.Hessian(); h != nil {
+		t.Errorf("expected nil without observation, got %v", h)
+	}
+	bh.Observe([]float64{1, 1}, []float64{1, 2})
+	if h := bh.Hessian(); h == nil || symmetricDim(h) != 2 || h.At(0, 0) != 1 || h.At(0, 1) != 0 {
+		t.Errorf("expected the identity after one observation, got %v", h)
+	}
+	bh = &BFGSHessian{}
+	if !bh.Update([]float64{1, 0}, []float64{2, 0}) {
+		t.Fatal("update with positive curvature should be applied")
+	}
+	var hs mat.VecDense
+	hs.MulVec(bh.Hessian(), mat.NewVecDense(2, []float64{1, 0}))
+	if !floats.Equal(hs.RawVector().Data, []float64{2, 0}) {
+		t.Errorf("secant equation of the first update: got %g", hs.RawVector().Data)
+	}
+}
+
+func TestSparseHessian(t *testing.T) {
+	const n = 50
+	sparsity := make([][]int, n)
+	for i := range sparsity {
+		if i > 0 {
+			sparsity[i] = append(sparsity[i], i-1)
+		}
+		if i < n-1 {
+			sparsity[i] = append(sparsity[i], i+1)
+		}
+	}
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = math.Sin(float64(i))
+	}
+	nGrad, nFunc := 0, 0
+	grad := func(g, x []float64) { nGrad++; rosenGrad(g, x) }
+	f := func(x []float64) float64 { nFunc++; return rosen(x) }
+	dense := Hessian(rosen, x, &HessianOptions{Grad: rosenGrad})
+	sparse := Hessian(rosen, x, &HessianOptions{Grad: grad, Sparsity: sparsity})
+	if !mat.EqualApprox(dense, sparse, 1e-6) {
+		t.Error("sparse Hessian from gradients differs from dense one")
+	}
+	if nGrad != 6 {
+		t.Errorf("expected 3 column groups, got %d gradient evaluations", nGrad)
+	}
+	sparseF := Hessian(f, x, &HessianOptions{Sparsity: sparsity})
+	if !mat.EqualApprox(dense, sparseF, 1e-3) {
+		t.Error("sparse Hessian from function values differs from dense one")
+	}
+	if want := 1 + 2*n + 4*(n-1); nFunc != want {
+		t.Errorf("expected %d function evaluations, got %d", want, nFunc)
+	}
+}
+
+func TestHessianConcurrent(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	x := []float64{-1.2, 1, 1.5, .3}
+	for _, grad := range []func([]float64, []float64){nil, rosenGrad} {
+		want := Hessian(rosen, x, &HessianOptions{Grad: grad})
+		for _, procs := range []int{1, 4} {
+			runtime.GOMAXPROCS(procs)
+			for _, concurrent := range []int{2, 3, 16} {
+				if h := Hessian(rosen, x, &HessianOptions{Grad: grad, Concurrent: concurrent}); !mat.Equal(h, want) {
+					t.Errorf("Concurrent=%d GOMAXPROCS=%d: got %v, expected %v", concurrent, procs, mat.Formatted(h), mat.Formatted(want))
+				}
+			}
+		}
+	}
+}
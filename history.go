@@ -0,0 +1,207 @@
+package optimize
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// History is an Observer collecting the convergence curve of a run: the
+// best value versus the number of evaluations and versus time. It records
+// a point at each improvement, from the evaluations, or from the
+// iterations for methods not sending evaluations. It also records the
+// iterations, for Diagnose.
+type History struct {
+	Points     []HistoryPoint
+	Iterations []HistoryIteration
+	// Diagnostics are the ones of the last iteration
+	Diagnostics map[string]float64
+	// NFev and Elapsed are the totals of the run
+	NFev    int
+	Elapsed time.Duration
+
+	start time.Time
+	lastX []float64
+}
+
+// HistoryPoint is an improvement of the best value F after NFev
+// evaluations and Elapsed
+type HistoryPoint struct {
+	NFev    int
+	F       float64
+	Elapsed time.Duration
+}
+
+// HistoryIteration is the state after iteration Iter: F is the best value
+// and Step the norm of the move of the iterate, NaN at the first iteration
+type HistoryIteration struct {
+	Iter, NFev int
+	F, Step    float64
+}
+
+var _ Observer = &History{}
+
+// WithHistory collects the convergence curve of the solver in h, in
+// addition to the Observer set before
+func WithHistory(h *History) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.observer == nil {
+			return unsupported("WithHistory", s)
+		}
+		if *f.observer == nil {
+			*f.observer = h
+		} else {
+			*f.observer = Observers{*f.observer, h}
+		}
+		return nil
+	}
+}
+
+// OnStart resets the history
+func (h *History) OnStart(*Start) {
+	h.Points, h.Iterations, h.NFev, h.Elapsed, h.start = h.Points[:0], h.Iterations[:0], 0, 0, time.Now()
+	h.lastX = h.lastX[:0]
+	for k := range h.Diagnostics {
+		delete(h.Diagnostics, k)
+	}
+}
+
+func (h *History) add(nfev int, f float64, elapsed time.Duration) {
+	if f != f {
+		return
+	}
+	if n := len(h.Points); n == 0 || f < h.Points[n-1].F {
+		h.Points = append(h.Points, HistoryPoint{NFev: nfev, F: f, Elapsed: elapsed})
+	}
+}
+
+// OnIteration records the iteration and its best value
+func (h *History) OnIteration(it *Iteration) {
+	if it.NFev > h.NFev {
+		h.NFev = it.NFev
+	}
+	h.add(it.NFev, it.F, it.Elapsed)
+	step := math.NaN()
+	if len(h.lastX) == len(it.X) && len(it.X) > 0 {
+		step = 0
+		for i, xi := range it.X {
+			step = math.Hypot(step, xi-h.lastX[i])
+		}
+	}
+	h.lastX = append(h.lastX[:0], it.X...)
+	h.Iterations = append(h.Iterations, HistoryIteration{Iter: it.Iter, NFev: it.NFev, F: it.F, Step: step})
+	if len(it.Diagnostics) > 0 && h.Diagnostics == nil {
+		h.Diagnostics = make(map[string]float64, len(it.Diagnostics))
+	}
+	for k := range h.Diagnostics {
+		delete(h.Diagnostics, k)
+	}
+	for k, v := range it.Diagnostics {
+		h.Diagnostics[k] = v
+	}
+}
+
+// OnEvaluation records an improvement
+func (h *History) OnEvaluation(e *Evaluation) {
+	h.NFev = e.N
+	h.add(e.N, e.F, time.Since(h.start))
+}
+
+// OnDone records the totals of the run
+func (h *History) OnDone(res *Result) {
+	if res.NFev > h.NFev {
+		h.NFev = res.NFev
+	}
+	h.Elapsed = res.Elapsed
+}
+
+// BestAt returns the best value after nfev evaluations, NaN before the first
+func (h *History) BestAt(nfev int) float64 {
+	i := sort.Search(len(h.Points), func(i int) bool { return h.Points[i].NFev > nfev })
+	if i == 0 {
+		return math.NaN()
+	}
+	return h.Points[i-1].F
+}
+
+// BestAtTime returns the best value after d, NaN before the first evaluation
+func (h *History) BestAtTime(d time.Duration) float64 {
+	i := sort.Search(len(h.Points), func(i int) bool { return h.Points[i].Elapsed > d })
+	if i == 0 {
+		return math.NaN()
+	}
+	return h.Points[i-1].F
+}
+
+// Resample returns the best values after the numbers of evaluations of grid
+func (h *History) Resample(grid []int) []float64 {
+	fs := make([]float64, len(grid))
+	for i, n := range grid {
+		fs[i] = h.BestAt(n)
+	}
+	return fs
+}
+
+// ResampleTime returns the best values after the durations of grid
+func (h *History) ResampleTime(grid []time.Duration) []float64 {
+	fs := make([]float64, len(grid))
+	for i, d := range grid {
+		fs[i] = h.BestAtTime(d)
+	}
+	return fs
+}
+
+// Reached returns the number of evaluations after which the best value is
+// <= target, -1 if it was not reached
+func (h *History) Reached(target float64) int {
+	for _, p := range h.Points {
+		if p.F <= target {
+			return p.NFev
+		}
+	}
+	return -1
+}
+
+// LogGrid returns n numbers of evaluations from 1 to maxFev, evenly spaced
+// on a log scale, without duplicates
+func LogGrid(maxFev, n int) []int {
+	var grid []int
+	for i := 0; i < n; i++ {
+		t := 1.
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		v := int(math.Round(math.Pow(float64(maxFev), t)))
+		if len(grid) == 0 || v > grid[len(grid)-1] {
+			grid = append(grid, v)
+		}
+	}
+	return grid
+}
+
+// ECDF returns, for each number of evaluations of grid, the fraction of the
+// (history, target) pairs whose target is reached, ie the empirical
+// cumulative distribution of the runtimes to reach the targets. It is nil
+// without histories or targets.
+func ECDF(histories []*History, targets []float64, grid []int) []float64 {
+	if len(histories) == 0 || len(targets) == 0 {
+		return nil
+	}
+	var runtimes []int
+	for _, h := range histories {
+		for _, target := range targets {
+			if n := h.Reached(target); n >= 0 {
+				runtimes = append(runtimes, n)
+			}
+		}
+	}
+	sort.Ints(runtimes)
+	total := float64(len(histories) * len(targets))
+	ecdf := make([]float64, len(grid))
+	for i, n := range grid {
+		reached := sort.Search(len(runtimes), func(k int) bool { return runtimes[k] > n })
+		ecdf[i] = float64(reached) / total
+	}
+	return ecdf
+}
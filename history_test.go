@@ -0,0 +1,68 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleHistory() {
+	// compare Powell and SPSA on the evaluations budget
+	var powell, spsa History
+	pm, _ := NewPowellMinimizerWith(WithHistory(&powell))
+	pm.Minimize(rosen, []float64{-1.2, 1})
+	sp, _ := NewSPSAWith(WithHistory(&spsa), WithRNG(rand.NewSource(1)), WithMaxIter(200))
+	sp.Minimize(rosen, []float64{-1.2, 1})
+	grid := LogGrid(400, 4)
+	fmt.Println(grid)
+	fmt.Printf("%.2g\n%.2g\n", powell.Resample(grid), spsa.Resample(grid))
+	// Output:
+	// [1 7 54 400]
+	// [24 4 3.9 0.022]
+	// [5.6 5.6 4.7 4.4]
+}
+
+func TestHistory(t *testing.T) {
+	h := &History{}
+	h.OnStart(nil)
+	for i, f := range []float64{5, 6, 3, math.NaN(), 3, 1} {
+		h.OnEvaluation(&Evaluation{N: i + 1, F: f})
+	}
+	h.OnDone(&Result{NFev: 6})
+	if len(h.Points) != 3 || h.NFev != 6 {
+		t.Errorf("points %+v", h.Points)
+	}
+	if got := h.Resample([]int{0, 1, 2, 3, 6, 10}); !math.IsNaN(got[0]) || got[1] != 5 || got[2] != 5 || got[3] != 3 || got[4] != 1 || got[5] != 1 {
+		t.Errorf("resampled %g", got)
+	}
+	if h.Reached(3) != 3 || h.Reached(0) != -1 {
+		t.Errorf("reached %d %d", h.Reached(3), h.Reached(0))
+	}
+	if !math.IsNaN(h.BestAtTime(-1)) || h.BestAtTime(math.MaxInt64) != 1 {
+		t.Error("bad BestAtTime")
+	}
+	if got := LogGrid(1000, 4); fmt.Sprint(got) != "[1 10 100 1000]" {
+		t.Errorf("grid %v", got)
+	}
+	hi := &History{}
+	hi.OnStart(nil)
+	for i, x := range [][]float64{{0, 0}, {3, 4}, {3, 4}} {
+		hi.OnIteration(&Iteration{Iter: i + 1, NFev: i, X: x, F: 1, Diagnostics: map[string]float64{"step": float64(i)}})
+	}
+	if its := hi.Iterations; len(its) != 3 || !math.IsNaN(its[0].Step) || its[1].Step != 5 || its[2].Step != 0 || hi.Diagnostics["step"] != 2 {
+		t.Errorf("iterations %+v %v", its, hi.Diagnostics)
+	}
+	h2 := &History{Points: []HistoryPoint{{NFev: 1, F: 2}}}
+	if got := ECDF([]*History{h, h2}, []float64{3, 1}, []int{1, 3, 6}); got[0] != .25 || got[1] != .5 || got[2] != .75 {
+		t.Errorf("ecdf %g", got)
+	}
+	if got := ECDF(nil, []float64{3}, []int{1}); got != nil {
+		t.Errorf("ecdf without histories %g", got)
+	}
+	if got := ECDF([]*History{h}, nil, []int{1}); got != nil {
+		t.Errorf("ecdf without targets %g", got)
+	}
+
+}
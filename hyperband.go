@@ -0,0 +1,313 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/rand"
+)
+
+// Sampler proposes the configurations of a Space and learns from their
+// values, in the ask and tell style of the samplers of hyperparameter
+// tuning. Configurations are points of the unit cube of the Space.
+type Sampler interface {
+	// Ask returns a new configuration
+	Ask(rnd *rand.Rand) []float64
+	// Tell records the value f of x evaluated with budget
+	Tell(x []float64, budget, f float64)
+}
+
+// RandomSampler samples uniform random configurations, or the points of
+// Sequence if not nil, and learns nothing
+type RandomSampler struct {
+	Dim      int
+	Sequence QuasiRandom
+}
+
+// Ask for Sampler
+func (rs *RandomSampler) Ask(rnd *rand.Rand) []float64 {
+	x := make([]float64, rs.Dim)
+	if rs.Sequence != nil {
+		rs.Sequence.Next(x)
+		return x
+	}
+	for i := range x {
+		x[i] = rnd.Float64()
+	}
+	return x
+}
+
+// Tell for Sampler
+func (rs *RandomSampler) Tell(x []float64, budget, f float64) {}
+
+// Trial is the evaluation of a configuration with a budget, asked by a
+// Hyperband. Its evaluation may Report its intermediate values to the
+// Pruner of the Hyperband.
+type Trial struct {
+	Reporter
+	// X is the configuration in the unit cube of the Space, Values its values
+	X      []float64
+	Values Values
+	// Budget is the fidelity of the evaluation, eg a number of epochs
+	Budget float64
+	// Bracket and Rung are the indices of the successive halving and of
+	// its step
+	Bracket, Rung int
+	// F is the value told, NaN before
+	F    float64
+	told bool
+}
+
+// Hyperband is the budgeted tuning scheduler of Li et al.: brackets of
+// successive halving, each evaluating many configurations with a small
+// budget and the best 1/Eta of them with Eta times the budget, up to
+// MaxBudget, the brackets trading the number of configurations for their
+// first budget. Its Ask and Tell may be called concurrently by workers:
+// the trials of a rung are asked without waiting, the next rung once they
+// are all told. A Hyperband runs once.
+type Hyperband struct {
+	Space *Space
+	// Sampler proposes the configurations of the first rungs, a
+	// RandomSampler if nil. It is told the values of the trials not pruned.
+	Sampler Sampler
+	// Pruner stops trials early from the values they report, if not nil.
+	// The pruned trials of a rung rank after the others.
+	Pruner Pruner
+	// MinBudget and MaxBudget are the smallest and largest budgets of a
+	// trial, 0 < MinBudget <= MaxBudget
+	MinBudget, MaxBudget float64
+	// Eta is the factor of the budgets of consecutive rungs, 3 if 0
+	Eta int
+	// Brackets is the number of brackets, from the most aggressive one, all
+	// of them if 0. 1 is a successive halving from MinBudget.
+	Brackets int
+	// Src is the random source of the Sampler. if nil, a source seeded
+	// from golang.org/x/exp/rand is used.
+	Src rand.Source
+
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	eta      float64
+	sMax     int
+	brackets []*hbBracket
+	trials   []*Trial
+	err      error
+}
+
+// hbBracket is a successive halving of s+1 rungs from n configurations
+type hbBracket struct {
+	index, s, n int
+	rung        int
+	// configs are the configurations of a rung after the first one
+	configs [][]float64
+	// asked and told are the numbers of trials of the rung
+	asked, told int
+	trials      []*Trial
+}
+
+// init checks the settings and prepares the brackets
+func (hb *Hyperband) init() error {
+	if hb.rnd != nil || hb.err != nil {
+		return hb.err
+	}
+	switch {
+	case hb.Space == nil || hb.Space.Dim() == 0:
+		hb.err = errors.New("optimize: Hyperband needs a Space")
+	case hb.Space.Validate() != nil:
+		hb.err = hb.Space.Validate()
+	case !(hb.MinBudget > 0) || !(hb.MaxBudget >= hb.MinBudget) || math.IsInf(hb.MaxBudget, 1):
+		hb.err = errors.New("optimize: Hyperband needs 0 < MinBudget <= MaxBudget")
+	case hb.Eta == 1 || hb.Eta < 0:
+		hb.err = errors.New("optimize: Hyperband Eta must be at least 2")
+	}
+	if hb.err != nil {
+		return hb.err
+	}
+	hb.rnd = newRand(hb.Src)
+	if hb.Sampler == nil {
+		hb.Sampler = &RandomSampler{Dim: hb.Space.Dim()}
+	}
+	hb.eta = float64(hb.Eta)
+	if hb.Eta == 0 {
+		hb.eta = 3
+	}
+	hb.sMax = int(math.Floor(math.Log(hb.MaxBudget/hb.MinBudget)/math.Log(hb.eta) + 1e-9))
+	brackets := hb.Brackets
+	if brackets <= 0 || brackets > hb.sMax+1 {
+		brackets = hb.sMax + 1
+	}
+	for k := 0; k < brackets; k++ {
+		s := hb.sMax - k
+		n := int(math.Ceil(float64(hb.sMax+1) / float64(s+1) * math.Pow(hb.eta, float64(s))))
+		hb.brackets = append(hb.brackets, &hbBracket{index: k, s: s, n: n})
+	}
+	return nil
+}
+
+// size returns the number of trials of the rung of b
+func (hb *Hyperband) size(b *hbBracket) int {
+	return int(math.Floor(float64(b.n)*math.Pow(hb.eta, -float64(b.rung)) + 1e-9))
+}
+
+// budget returns the budget of the trials of the rung of b
+func (hb *Hyperband) budget(b *hbBracket) float64 {
+	return hb.MaxBudget * math.Pow(hb.eta, -float64(b.s-b.rung))
+}
+
+// done returns whether b has no more trials
+func (hb *Hyperband) done(b *hbBracket) bool {
+	return b.rung > b.s || hb.size(b) == 0
+}
+
+// Ask returns the next trial, false if none is ready: the trials asked
+// wait to be told, or the Hyperband is Done. It returns an invalid
+// setting as error.
+func (hb *Hyperband) Ask() (*Trial, bool, error) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	if err := hb.init(); err != nil {
+		return nil, false, err
+	}
+	for _, b := range hb.brackets {
+		if hb.done(b) || b.asked == hb.size(b) {
+			continue
+		}
+		t := &Trial{Reporter: Reporter{ID: len(hb.trials), Step: math.NaN(), Value: math.NaN(), pruner: hb.Pruner}, Budget: hb.budget(b), Bracket: b.index, Rung: b.rung, F: math.NaN()}
+		if b.rung == 0 {
+			t.X = hb.Sampler.Ask(hb.rnd)
+		} else {
+			t.X = b.configs[b.asked]
+		}
+		t.Values = hb.Space.Decode(t.X)
+		b.asked++
+		b.trials = append(b.trials, t)
+		hb.trials = append(hb.trials, t)
+		return t, true, nil
+	}
+	return nil, false, nil
+}
+
+// Tell records the value of t, a NaN value ranking last, and starts the
+// next rung of its bracket when all the trials of its rung are told
+func (hb *Hyperband) Tell(t *Trial, f float64) error {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	if t == nil || t.ID >= len(hb.trials) || hb.trials[t.ID] != t {
+		return errors.New("optimize: Hyperband.Tell of an unknown trial")
+	}
+	if t.told {
+		return errors.New("optimize: Hyperband.Tell of a trial already told")
+	}
+	t.F, t.told = f, true
+	if !t.Pruned {
+		hb.Sampler.Tell(t.X, t.Budget, f)
+	}
+	b := hb.brackets[t.Bracket]
+	if b.told++; b.told < hb.size(b) {
+		return nil
+	}
+	// promote the best 1/eta of the rung
+	sort.SliceStable(b.trials, func(i, j int) bool { return b.trials[i].before(b.trials[j]) })
+	b.rung++
+	b.configs = b.configs[:0]
+	if !hb.done(b) {
+		for _, tr := range b.trials[:hb.size(b)] {
+			b.configs = append(b.configs, tr.X)
+		}
+	}
+	b.asked, b.told, b.trials = 0, 0, nil
+	return nil
+}
+
+// before orders the trials by value, NaN and pruned trials last
+func (t *Trial) before(u *Trial) bool {
+	if t.Pruned != u.Pruned {
+		return u.Pruned
+	}
+	return t.F < u.F || (u.F != u.F && t.F == t.F)
+}
+
+// Done returns whether all the trials have been asked and told
+func (hb *Hyperband) Done() bool {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	if hb.init() != nil {
+		return true
+	}
+	for _, b := range hb.brackets {
+		if !hb.done(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// Trials returns the trials asked, in their order
+func (hb *Hyperband) Trials() []*Trial {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	return append([]*Trial(nil), hb.trials...)
+}
+
+// Best returns the best trial told and not pruned of the largest budget,
+// nil before any
+func (hb *Hyperband) Best() *Trial {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	var best *Trial
+	for _, t := range hb.trials {
+		if !t.told || t.Pruned {
+			continue
+		}
+		if best == nil || t.Budget > best.Budget || (t.Budget == best.Budget && t.before(best)) {
+			best = t
+		}
+	}
+	return best
+}
+
+// Run evaluates the trials with f, by workers goroutines if workers > 1,
+// until Done, and returns the Best trial
+func (hb *Hyperband) Run(f func(v Values, budget float64) float64, workers int) (*Trial, error) {
+	return hb.RunTrials(func(t *Trial) float64 { return f(t.Values, t.Budget) }, workers)
+}
+
+// RunTrials is Run with an objective of the trials, which may Report their
+// intermediate values and return early when pruned
+func (hb *Hyperband) RunTrials(f func(t *Trial) float64, workers int) (*Trial, error) {
+	hb.mu.Lock()
+	err := hb.init()
+	hb.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	work := make(chan *Trial)
+	// told wakes the loop waiting for the end of a rung
+	told := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range work {
+				hb.Tell(t, f(t))
+				select {
+				case told <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+	for !hb.Done() {
+		if t, ok, _ := hb.Ask(); ok {
+			work <- t
+		} else {
+			<-told
+		}
+	}
+	close(work)
+	wg.Wait()
+	return hb.Best(), nil
+}
@@ -0,0 +1,217 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleHyperband() {
+	// a learning rate tuned with up to 27 epochs, the loss of a configuration
+	// decreasing with its epochs to its final value
+	space := NewSpace(LogUniformParam("learning_rate", 1e-4, 1), IntParam("layers", 1, 8))
+	hb := &Hyperband{Space: space, MinBudget: 1, MaxBudget: 27, Src: rand.NewSource(1)}
+	best, err := hb.Run(func(v Values, epochs float64) float64 {
+		final := math.Pow(math.Log10(v.Float("learning_rate"))+2, 2) + math.Abs(float64(v.Int("layers")-3))/4
+		return final + 1/epochs
+	}, 4)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%d trials, best %.2g %d\n", len(hb.Trials()), best.Values.Float("learning_rate"), best.Values.Int("layers"))
+	// Output:
+	// 69 trials, best 0.0046 3
+}
+
+func TestHyperband(t *testing.T) {
+	space := NewSpace(UniformParam("x", 0, 1))
+	f := func(v Values, budget float64) float64 { return math.Abs(v.Float("x")-.3) + 1/budget }
+
+	// the brackets of R/r = 27 and eta = 3, asked and told in turn
+	hb := &Hyperband{Space: space, MinBudget: 1, MaxBudget: 27, Src: rand.NewSource(1)}
+	for !hb.Done() {
+		tr, ok, err := hb.Ask()
+		if err != nil || !ok {
+			t.Fatalf("Ask: %v %v", ok, err)
+		}
+		if err := hb.Tell(tr, f(tr.Values, tr.Budget)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sizes := map[[2]int]int{}
+	budgets := map[[2]int]float64{}
+	for _, tr := range hb.Trials() {
+		sizes[[2]int{tr.Bracket, tr.Rung}]++
+		budgets[[2]int{tr.Bracket, tr.Rung}] = tr.Budget
+	}
+	for k, want := range [][]int{{27, 9, 3, 1}, {12, 4, 1}, {6, 2}, {4}} {
+		for r, n := range want {
+			if sizes[[2]int{k, r}] != n || budgets[[2]int{k, r}] != 27/math.Pow(3, float64(len(want)-1-r)) {
+				t.Errorf("bracket %d rung %d: %d trials of budget %g", k, r, sizes[[2]int{k, r}], budgets[[2]int{k, r}])
+			}
+		}
+	}
+	if n := len(hb.Trials()); n != 40+17+8+4 {
+		t.Errorf("%d trials", n)
+	}
+	// the promoted configurations are the best ones of their rung
+	for k := 0; k < 3; k++ {
+		worst := math.Inf(-1)
+		var promoted [][]float64
+		for _, tr := range hb.Trials() {
+			if tr.Bracket == k && tr.Rung == 1 {
+				promoted = append(promoted, tr.X)
+			}
+		}
+		for _, tr := range hb.Trials() {
+			if tr.Bracket == k && tr.Rung == 0 {
+				for _, x := range promoted {
+					if &x[0] == &tr.X[0] {
+						worst = math.Max(worst, tr.F)
+					}
+				}
+			}
+		}
+		for _, tr := range hb.Trials() {
+			if tr.Bracket == k && tr.Rung == 0 && tr.F < worst {
+				in := false
+				for _, x := range promoted {
+					in = in || &x[0] == &tr.X[0]
+				}
+				if !in {
+					t.Errorf("bracket %d: %v of value %g not promoted", k, tr.X, tr.F)
+				}
+			}
+		}
+	}
+	if best := hb.Best(); best.Budget != 27 || math.Abs(best.Values.Float("x")-.3) > .05 {
+		t.Errorf("best %+v", best)
+	}
+	if tr, ok, _ := hb.Ask(); ok || tr != nil {
+		t.Errorf("Ask after Done: %v", tr)
+	}
+
+	// a rung waits for all its trials, a NaN value ranking last
+	sh := &Hyperband{Space: space, MinBudget: 1, MaxBudget: 4, Eta: 2, Brackets: 1, Src: rand.NewSource(1)}
+	var rung []*Trial
+	for {
+		tr, ok, _ := sh.Ask()
+		if !ok {
+			break
+		}
+		rung = append(rung, tr)
+	}
+	if len(rung) != 4 || rung[0].Budget != 1 {
+		t.Fatalf("successive halving: %d trials of budget %g", len(rung), rung[0].Budget)
+	}
+	for i, tr := range rung {
+		v := float64(i)
+		if i == 0 {
+			v = math.NaN()
+		}
+		sh.Tell(tr, v)
+	}
+	next, _, _ := sh.Ask()
+	if next.Budget != 2 || (&next.X[0] != &rung[1].X[0] && &next.X[0] != &rung[2].X[0]) {
+		t.Errorf("promoted %+v", next)
+	}
+	if err := sh.Tell(next, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Tell(next, 0); err == nil {
+		t.Error("expected an error for a trial told twice")
+	}
+	if err := sh.Tell(&Trial{}, 0); err == nil {
+		t.Error("expected an error for an unknown trial")
+	}
+
+	for _, bad := range []*Hyperband{
+		{MinBudget: 1, MaxBudget: 9},
+		{Space: NewSpace(CategoricalParam("c")), MinBudget: 1, MaxBudget: 9},
+		{Space: space, MaxBudget: 9},
+		{Space: space, MinBudget: 2, MaxBudget: 1},
+		{Space: space, MinBudget: 1, MaxBudget: 9, Eta: 1},
+	} {
+		if _, err := bad.Run(f, 1); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestHyperbandRun(t *testing.T) {
+	space := NewSpace(UniformParam("x", -1, 1), UniformParam("y", -1, 1))
+	var mu sync.Mutex
+	evals := 0
+	f := func(v Values, budget float64) float64 {
+		mu.Lock()
+		evals++
+		mu.Unlock()
+		return v.Float("x")*v.Float("x") + v.Float("y")*v.Float("y") + 1/budget
+	}
+	for _, workers := range []int{1, 8} {
+		sobol, _ := NewSobol(2, nil)
+		evals = 0
+		hb := &Hyperband{Space: space, Sampler: &RandomSampler{Dim: 2, Sequence: sobol}, MinBudget: 1, MaxBudget: 81, Src: rand.NewSource(1)}
+		best, err := hb.Run(f, workers)
+		if err != nil || evals != len(hb.Trials()) || !hb.Done() {
+			t.Fatalf("%d workers: %d evaluations of %d trials, %v", workers, evals, len(hb.Trials()), err)
+		}
+		if best.Budget != 81 || best.F > .1 {
+			t.Errorf("%d workers: best %+v", workers, best)
+		}
+	}
+}
+
+// countingSampler is a RandomSampler counting its tells
+type countingSampler struct {
+	RandomSampler
+	tells int
+}
+
+func (cs *countingSampler) Tell(x []float64, budget, f float64) { cs.tells++ }
+
+func TestHyperbandPruner(t *testing.T) {
+	space := NewSpace(UniformParam("x", 0, 1))
+	sampler := &countingSampler{RandomSampler: RandomSampler{Dim: 1}}
+	hb := &Hyperband{Space: space, Sampler: sampler, Pruner: &MedianPruner{Warmup: 1}, MinBudget: 1, MaxBudget: 27, Src: rand.NewSource(1)}
+	best, err := hb.RunTrials(func(tr *Trial) float64 {
+		loss := 0.
+		for epoch := 1.; epoch <= tr.Budget; epoch++ {
+			loss = math.Abs(tr.Values.Float("x")-.3) + 1/epoch
+			if tr.Report(epoch, loss) {
+				break
+			}
+		}
+		return loss
+	}, 4)
+	if err != nil || best == nil || best.Pruned || best.Budget != 27 {
+		t.Fatalf("best %+v, %v", best, err)
+	}
+	pruned := 0
+	for _, tr := range hb.Trials() {
+		if tr.Pruned {
+			pruned++
+			if tr == best || !(tr.Step < tr.Budget || tr.Budget == 1) {
+				t.Errorf("pruned trial %+v", tr)
+			}
+		}
+	}
+	if pruned == 0 || sampler.tells != len(hb.Trials())-pruned {
+		t.Errorf("%d pruned trials of %d, %d tells", pruned, len(hb.Trials()), sampler.tells)
+	}
+
+	// the pruned trials of a rung are promoted last
+	sh := &Hyperband{Space: space, MinBudget: 1, MaxBudget: 2, Eta: 2, Brackets: 1,
+		Pruner: PrunerFunc(func(id int, step, value float64) bool { return id == 0 }), Src: rand.NewSource(1)}
+	a, _, _ := sh.Ask()
+	b, _, _ := sh.Ask()
+	a.Report(1, 0)
+	sh.Tell(a, 0)
+	sh.Tell(b, 1)
+	if next, _, _ := sh.Ask(); next == nil || &next.X[0] != &b.X[0] {
+		t.Errorf("promoted %+v instead of %+v", next, b)
+	}
+}
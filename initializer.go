@@ -0,0 +1,138 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// Initializer produces the initial points of a population method, eg the
+// first generation of CmaEsCholB
+type Initializer interface {
+	// Init returns n points, given the starting point x0 of the run and
+	// its optional bounds xmin and xmax
+	Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error)
+}
+
+// InitializerFunc is an adapter to allow the use of ordinary functions,
+// eg a design of the application, as Initializer
+type InitializerFunc func(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error)
+
+// Init calls fn
+func (fn InitializerFunc) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	return fn(n, x0, xmin, xmax, rnd)
+}
+
+// errInitBounds is the error of the initializers of the box without finite
+// bounds
+var errInitBounds = errors.New("optimize: Initializer needs finite bounds of the dimension")
+
+// checkInitBounds returns errInitBounds if xmin and xmax are not finite
+// bounds of the dimension of x0
+func checkInitBounds(x0, xmin, xmax []float64) error {
+	if len(xmin) != len(x0) || len(xmax) != len(x0) {
+		return errInitBounds
+	}
+	for i := range xmin {
+		if math.IsInf(xmin[i], 0) || math.IsInf(xmax[i], 0) {
+			return errInitBounds
+		}
+	}
+	return nil
+}
+
+// UniformInit draws uniform random points of the box
+type UniformInit struct{}
+
+// Init for Initializer
+func (UniformInit) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	if err := checkInitBounds(x0, xmin, xmax); err != nil {
+		return nil, err
+	}
+	xs := make([][]float64, n)
+	for k := range xs {
+		xs[k] = make([]float64, len(x0))
+		for i := range x0 {
+			xs[k][i] = xmin[i] + rnd.Float64()*(xmax[i]-xmin[i])
+		}
+	}
+	return xs, nil
+}
+
+// Init returns a latin hypercube design of the box, for Initializer
+func (lh LatinHypercube) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	if err := checkInitBounds(x0, xmin, xmax); err != nil {
+		return nil, err
+	}
+	return lh.Sample(n, xmin, xmax, rnd), nil
+}
+
+// QuasiRandomInit returns the points of a QuasiRandom sequence of the box,
+// a Sobol sequence scrambled with the random numbers of Init if Sequence
+// is nil. A Sequence continues at each call.
+type QuasiRandomInit struct {
+	Sequence QuasiRandom
+}
+
+// Init for Initializer
+func (qi QuasiRandomInit) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	if err := checkInitBounds(x0, xmin, xmax); err != nil {
+		return nil, err
+	}
+	seq := qi.Sequence
+	if seq == nil {
+		var err error
+		if seq, err = NewSobol(len(x0), &QuasiRandomOptions{Scramble: true, Src: rand.NewSource(rnd.Uint64())}); err != nil {
+			return nil, err
+		}
+	} else if seq.Dim() != len(x0) {
+		return nil, errors.New("optimize: QuasiRandomInit Sequence of another dimension")
+	}
+	return QuasiRandomPoints(seq, n, xmin, xmax), nil
+}
+
+// GaussianInit draws points around x0, perturbed by a normal noise of
+// standard deviation Scale, Scale[i] for component i if its length is the
+// dimension, 1 if nil, projected on the optional bounds. The first point
+// is x0.
+type GaussianInit struct {
+	Scale []float64
+}
+
+// Init for Initializer
+func (gi GaussianInit) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	pr := &PerturbedRestarts{Scale: gi.Scale, Xmin: xmin, Xmax: xmax}
+	best := &Result{X: x0}
+	xs := make([][]float64, n)
+	for k := range xs {
+		if k == 0 {
+			xs[k] = append([]float64(nil), x0...)
+			Box{Xmin: xmin, Xmax: xmax}.Project(xs[k])
+			continue
+		}
+		xs[k] = pr.Start(best, rnd)
+	}
+	return xs, nil
+}
+
+// WithInitializer sets the Initializer of the first population of a
+// population method
+func WithInitializer(init Initializer) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.initializer == nil {
+			return unsupported("WithInitializer", s)
+		}
+		*f.initializer = init
+		return nil
+	}
+}
+
+var (
+	_ Initializer = UniformInit{}
+	_ Initializer = LatinHypercube{}
+	_ Initializer = QuasiRandomInit{}
+	_ Initializer = GaussianInit{}
+	_ Initializer = InitializerFunc(nil)
+)
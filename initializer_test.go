@@ -0,0 +1,103 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleInitializer() {
+	// the first generation of CMA-ES, a centered latin hypercube of the box
+	// instead of samples around x0
+	xmin, xmax := []float64{-4, -4}, []float64{4, 4}
+	gen := 0
+	obs := ObserverFuncs{Evaluation: func(e *Evaluation) {
+		if gen++; gen <= 4 {
+			fmt.Printf("%.0f\n", e.X)
+		}
+	}}
+	m, _ := NewAlgorithm("GN_CMAES", WithBounds(xmin, xmax), WithInitializer(LatinHypercube{Centered: true}),
+		WithObserver(obs), WithRNG(rand.NewSource(1)))
+	res := m.Minimize(func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }, []float64{3, 3})
+	fmt.Printf("%.3f\n", res.X)
+	// Output:
+	// [2 -1]
+	// [1 1]
+	// [-3 2]
+	// [-2 -3]
+	// [1.000 -2.000]
+}
+
+func TestInitializer(t *testing.T) {
+	x0, xmin, xmax := []float64{0, 5}, []float64{-1, 2}, []float64{1, 4}
+	user := InitializerFunc(func(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+		xs := make([][]float64, n)
+		for k := range xs {
+			xs[k] = []float64{float64(k) / float64(n), 3}
+		}
+		return xs, nil
+	})
+	for _, init := range []Initializer{UniformInit{}, LatinHypercube{}, QuasiRandomInit{}, GaussianInit{Scale: []float64{.5}}, user} {
+		xs, err := init.Init(12, x0, xmin, xmax, rand.New(rand.NewSource(1)))
+		if err != nil || len(xs) != 12 {
+			t.Fatalf("%T: %d points, %v", init, len(xs), err)
+		}
+		for _, x := range xs {
+			if len(x) != 2 || x[0] < -1 || x[0] > 1 || x[1] < 2 || x[1] > 4 {
+				t.Errorf("%T: point %v outside of the box", init, x)
+			}
+		}
+	}
+	if xs, _ := (GaussianInit{}).Init(3, x0, nil, nil, rand.New(rand.NewSource(1))); xs[0][1] != 5 || xs[1][1] == 5 {
+		t.Errorf("GaussianInit without bounds: %v", xs)
+	}
+	for _, init := range []Initializer{UniformInit{}, LatinHypercube{}, QuasiRandomInit{}} {
+		if _, err := init.Init(4, x0, xmin, []float64{1, math.Inf(1)}, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("%T: expected an error without finite bounds", init)
+		}
+	}
+	h, _ := NewHalton(3, nil)
+	if _, err := (QuasiRandomInit{Sequence: h}).Init(4, x0, xmin, xmax, nil); err == nil {
+		t.Error("expected an error for a Sequence of another dimension")
+	}
+	if _, err := (QuasiRandomInit{}).Init(4, nil, nil, nil, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("expected an error in dimension 0")
+	}
+
+	// the first generation of CMA-ES is the one of the Initializer
+	var evaluated [][]float64
+	obs := ObserverFuncs{Evaluation: func(e *Evaluation) { evaluated = append(evaluated, append([]float64(nil), e.X...)) }}
+	cma := &CmaEsCholB{Xmin: xmin, Xmax: xmax, Population: 6, Initializer: user, Src: rand.NewSource(1), Observer: obs}
+	f := func(x []float64) float64 { return x[0]*x[0] + (x[1]-3)*(x[1]-3) }
+	res, err := optimize.Minimize(optimize.Problem{Func: f}, x0, &optimize.Settings{FuncEvaluations: 600, Concurrent: 1}, cma)
+	if err != nil || res.F > 1e-6 {
+		t.Fatalf("CMA-ES from the Initializer: %v %v", res, err)
+	}
+	for k := 0; k < 6; k++ {
+		if evaluated[k][0] != float64(k)/6 || evaluated[k][1] != 3 {
+			t.Errorf("evaluation %d at %v", k, evaluated[k])
+		}
+	}
+	if evaluated[6][1] == 3 {
+		t.Errorf("the second generation should be sampled: %v", evaluated[6])
+	}
+
+	// errors of the Initializer are errors of the run
+	short := InitializerFunc(func(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+		return [][]float64{x0}, nil
+	})
+	for _, init := range []Initializer{UniformInit{}, short} {
+		if _, err := Minimize(optimize.Problem{Func: f}, x0, &Options{Method: "cmaes", Initializer: init}); err == nil {
+			t.Errorf("%T: expected an error", init)
+		}
+	}
+	if _, err := NewAlgorithm("LN_POWELL", WithInitializer(UniformInit{})); err != nil {
+		t.Errorf("Minimize options take an Initializer: %v", err)
+	}
+	if _, err := NewSPSAWith(WithInitializer(UniformInit{})); err == nil {
+		t.Error("expected WithInitializer to be unsupported by SPSA")
+	}
+}
@@ -0,0 +1,260 @@
+package optimize
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// LatticeSearch minimizes a function over bounded integer vectors by
+// neighborhood search: coordinate moves of ±1, optional pattern moves, and an
+// optional tabu memory to escape local minima. It is meant to polish a
+// rounded solution of a continuous relaxation. x components are integer
+// valued float64s.
+type LatticeSearch struct {
+	Xmin, Xmax []float64
+	// Pattern enables Hooke-Jeeves pattern moves along the last improving step
+	Pattern bool
+	// TabuSize, if positive, is the number of recently visited points which
+	// can't be visited again. The search then moves to the best non-tabu
+	// neighbor even if it is worse, and stops after MaxStall moves without
+	// improvement on the best point (defaults to 2*dim).
+	TabuSize, MaxStall int
+	MaxIter, MaxFev    int
+	Callback           func([]float64)
+	// Repair, if not nil, is applied to candidates before evaluation, eg to
+	// enforce problem-specific constraints. Repaired candidates must stay integer.
+	Repair Repair
+	// TargetF, if not nil, stops the search with Status FunctionThreshold at
+	// the first point where f <= *TargetF
+	TargetF *float64
+	// StallIterations, if positive, stops the search with Status
+	// FunctionConvergence when the best value has not decreased by more than
+	// StallTolerance over the last StallIterations iterations.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of each iteration
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run
+	Observer Observer
+	// Evaluator, if not nil, evaluates the neighbors of an iteration in
+	// parallel, or in one call of its Batch. An evaluation error stops the
+	// search with Status Failure.
+	Evaluator *Evaluator
+}
+
+// NewLatticeSearch returns a LatticeSearch with default limits
+func NewLatticeSearch() *LatticeSearch {
+	return &LatticeSearch{MaxIter: 1000, MaxFev: 10000}
+}
+
+// Minimize minimizes f from x0, which is rounded, and returns the best point.
+// Status is MethodConverge at a local minimum, or after MaxStall moves of a tabu search.
+// An x0 outside the bounds is projected on them with a note in Warnings,
+// invalid bounds or a non-finite x0 are the Message of a Result with Status
+// Failure.
+func (ls *LatticeSearch) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{}
+	x0, note, err := feasibleStart(x0, ls.Xmin, ls.Xmax, nil)
+	if err != nil {
+		res.X, res.F, res.Status, res.Message = append([]float64(nil), x0...), math.NaN(), optimize.Failure, err.Error()
+		return res.done(start)
+	}
+	if note != "" {
+		res.Warnings = []string{note}
+	}
+	n := len(x0)
+	maxIter, maxFev, maxStall := ls.MaxIter, ls.MaxFev, ls.MaxStall
+	if maxIter <= 0 {
+		maxIter = 1000
+	}
+	if maxFev <= 0 {
+		maxFev = 10000
+	}
+	if maxStall <= 0 {
+		maxStall = 2 * n
+	}
+	box := Box{Xmin: ls.Xmin, Xmax: ls.Xmax}
+	cache := make(map[string]float64)
+	tw := &targetWatch{target: ls.TargetF}
+	sw := newStallWatch(ls.StallIterations, ls.StallTolerance)
+	st := newMonitor(ls.Stop, ls.Observer, "lattice", x0)
+	rp := newReplay(ls.Evaluator, f, nil)
+	f = st.wrap(sw.wrap(tw.wrap(rp.fn)))
+	store := func(key string, fx float64) float64 {
+		res.NFev++
+		if fx != fx {
+			fx = math.Inf(1)
+		}
+		cache[key] = fx
+		return fx
+	}
+	eval := func(x []float64) float64 {
+		if ls.Repair != nil {
+			ls.Repair.Repair(x)
+		}
+		key := floatsKey(x)
+		if fx, ok := cache[key]; ok {
+			res.Evals.CacheHits++
+			return fx
+		}
+		return store(key, f(x))
+	}
+	inBox := func(x []float64) bool {
+		for i, xi := range x {
+			if (i < len(ls.Xmin) && xi < ls.Xmin[i]) || (i < len(ls.Xmax) && xi > ls.Xmax[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	// prefetch evaluates with the Evaluator the points of xs which are not
+	// cached, so that the following calls of eval are cache hits
+	var keys []string
+	prefetch := func(xs [][]float64) {
+		keys = keys[:0]
+		batch := xs[:0]
+		for _, x := range xs {
+			if ls.Repair != nil {
+				ls.Repair.Repair(x)
+			}
+			key := floatsKey(x)
+			if _, ok := cache[key]; ok {
+				continue
+			}
+			cache[key] = math.NaN()
+			keys, batch = append(keys, key), append(batch, x)
+		}
+		for i, fx := range rp.eval(f, batch, nil) {
+			store(keys[i], fx)
+		}
+		// the prefetched points are not hits of the following calls of eval
+		res.Evals.CacheHits -= len(batch)
+	}
+	var tabu []string
+	isTabu := func(x []float64) bool {
+		key := floatsKey(x)
+		for _, t := range tabu {
+			if t == key {
+				return true
+			}
+		}
+		return false
+	}
+	visit := func(x []float64) {
+		if ls.TabuSize <= 0 {
+			return
+		}
+		tabu = append(tabu, floatsKey(x))
+		if len(tabu) > ls.TabuSize {
+			tabu = tabu[1:]
+		}
+	}
+
+	x := append([]float64(nil), x0...)
+	for i := range x {
+		x[i] = math.Round(x[i])
+	}
+	box.Project(x)
+	fx := eval(x)
+	visit(x)
+	res.X, res.F = append([]float64(nil), x...), fx
+	var step []float64
+	cand, next := make([]float64, n), make([]float64, n)
+	stall := 0
+	for ; res.NIter < maxIter && res.NFev < maxFev && !tw.reached; res.NIter++ {
+		if sw.stalled(res.NIter) {
+			res.Status, res.Reason = optimize.FunctionConvergence, ReasonStagnation
+			break
+		}
+		// best neighbor
+		if ls.Evaluator != nil {
+			var xs [][]float64
+			for i := 0; i < n; i++ {
+				for _, d := range []float64{-1, 1} {
+					copy(cand, x)
+					cand[i] += d
+					if inBox(cand) && !isTabu(cand) {
+						xs = append(xs, append([]float64(nil), cand...))
+					}
+				}
+			}
+			if prefetch(xs); rp.err != nil {
+				res.Status, res.Message = optimize.Failure, rp.err.Error()
+				break
+			}
+		}
+		fnext := math.Inf(1)
+		found := false
+		for i := 0; i < n; i++ {
+			for _, d := range []float64{-1, 1} {
+				copy(cand, x)
+				cand[i] += d
+				if !inBox(cand) || isTabu(cand) {
+					continue
+				}
+				if fc := eval(cand); fc < fnext {
+					fnext, found = fc, true
+					copy(next, cand)
+				}
+			}
+		}
+		if !found || (ls.TabuSize <= 0 && fnext >= fx) {
+			res.Status = optimize.MethodConverge
+			break
+		}
+		// pattern move: keep going in the direction of the last step
+		if ls.Pattern && fnext < fx {
+			if step == nil {
+				step = make([]float64, n)
+			}
+			for i := range step {
+				step[i] = next[i] - x[i]
+			}
+			for {
+				for i := range cand {
+					cand[i] = next[i] + step[i]
+				}
+				if !inBox(cand) || isTabu(cand) || res.NFev >= maxFev {
+					break
+				}
+				fc := eval(cand)
+				if fc >= fnext {
+					break
+				}
+				copy(next, cand)
+				fnext = fc
+			}
+		}
+		copy(x, next)
+		fx = fnext
+		visit(x)
+		if fx < res.F {
+			copy(res.X, x)
+			res.F = fx
+			stall = 0
+		} else if stall++; stall >= maxStall {
+			res.Status, res.Reason = optimize.MethodConverge, ReasonStagnation
+			break
+		}
+		if ls.Callback != nil {
+			ls.Callback(x)
+		}
+		if st.iterate(res.NIter+1, res.NFev, x, nil) {
+			res.NIter++
+			break
+		}
+	}
+	st.apply(res)
+	tw.apply(res)
+	if res.Status == optimize.NotTerminated {
+		res.Status = optimize.IterationLimit
+		if res.NFev >= maxFev {
+			res.Status = optimize.FunctionEvaluationLimit
+		}
+	}
+	rp.stats(&res.Evals)
+	return st.done(res.done(start))
+}
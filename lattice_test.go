@@ -0,0 +1,51 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleLatticeSearch() {
+	// polish the rounded solution of the continuous relaxation
+	f := func(x []float64) float64 {
+		return math.Abs(3*x[0]+5*x[1]-17) + .1*(x[0]*x[0]+x[1]*x[1])
+	}
+	relaxed := PowellMinimize(f, []float64{0, 0})
+	ls := NewLatticeSearch()
+	ls.Xmin, ls.Xmax = []float64{0, 0}, []float64{10, 10}
+	// the rounded relaxation is a local minimum for ±1 moves: use a tabu memory
+	ls.TabuSize = 10
+	res := ls.Minimize(f, relaxed)
+	fmt.Printf("%.0f %.1f\n", res.X, res.F)
+	// Output:
+	// [4 1] 1.7
+}
+
+func TestLatticeSearch(t *testing.T) {
+	// values at 0..4: local minimum at 1, global one at 3
+	values := []float64{5, 3, 4, 1, 2}
+	f := func(x []float64) float64 { return values[int(x[0])] }
+	bounds := func(ls *LatticeSearch) *LatticeSearch {
+		ls.Xmin, ls.Xmax = []float64{0}, []float64{4}
+		return ls
+	}
+	if res := bounds(NewLatticeSearch()).Minimize(f, []float64{0}); res.X[0] != 1 || res.Status != optimize.MethodConverge {
+		t.Errorf("descent should stop at local minimum, got %v %s", res.X, res.Status)
+	}
+	ls := bounds(NewLatticeSearch())
+	ls.TabuSize = 3
+	if res := ls.Minimize(f, []float64{0}); res.X[0] != 3 || res.F != 1 {
+		t.Errorf("tabu search should escape, got %v %g", res.X, res.F)
+	}
+	// pattern moves accelerate long descents
+	lin := func(x []float64) float64 { return math.Abs(x[0]-40) + math.Abs(x[1]-40) }
+	plain, pattern := NewLatticeSearch(), NewLatticeSearch()
+	pattern.Pattern = true
+	rplain := plain.Minimize(lin, []float64{0, 0})
+	if res := pattern.Minimize(lin, []float64{0, 0}); res.X[0] != 40 || res.X[1] != 40 || res.NIter >= rplain.NIter {
+		t.Errorf("pattern: %v in %d iterations vs %d", res.X, res.NIter, rplain.NIter)
+	}
+}
@@ -0,0 +1,115 @@
+package optimize
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// LatinHypercube is a latin hypercube sampling plan: n points of a box, one
+// in each of the n slices of every coordinate, for the initial points of
+// population methods and the starts of LHSRestarts
+type LatinHypercube struct {
+	// Centered places the points at the centers of their slices, at a
+	// random position in the slice otherwise
+	Centered bool
+	// Maximin, if positive, is the number of iterations of the maximin
+	// optimization of the plan of Morris and Mitchell: the exchange of a
+	// coordinate of two random points is kept if it decreases phi_p, a
+	// smooth measure of the smallest distance between the points, which
+	// spreads the points over the box. The plan stays a latin hypercube.
+	Maximin int
+}
+
+// maximinP is the exponent of phi_p = (sum over the pairs of d^-p)^(1/p),
+// large enough for the smallest distances to dominate
+const maximinP = 15
+
+// Sample returns n points of the box of xmin and xmax drawn with rnd
+func (lh LatinHypercube) Sample(n int, xmin, xmax []float64, rnd *rand.Rand) [][]float64 {
+	xs := make([][]float64, n)
+	for k := range xs {
+		xs[k] = make([]float64, len(xmin))
+	}
+	for i := range xmin {
+		for k, slice := range rnd.Perm(n) {
+			u := .5
+			if !lh.Centered {
+				u = rnd.Float64()
+			}
+			xs[k][i] = (float64(slice) + u) / float64(n)
+		}
+	}
+	if lh.Maximin > 0 && n > 2 && len(xmin) > 0 {
+		maximin(xs, lh.Maximin, rnd)
+	}
+	for _, x := range xs {
+		for i := range x {
+			x[i] = xmin[i] + x[i]*(xmax[i]-xmin[i])
+		}
+	}
+	return xs
+}
+
+// maximin improves the plan us of the unit cube by iter exchanges of a
+// coordinate of two points, which leave their own distance unchanged
+func maximin(us [][]float64, iter int, rnd *rand.Rand) {
+	n := len(us)
+	term := func(a, b int) float64 {
+		d := 0.
+		for i := range us[a] {
+			d += (us[a][i] - us[b][i]) * (us[a][i] - us[b][i])
+		}
+		return math.Pow(d, -maximinP/2.)
+	}
+	terms := make([][]float64, n)
+	for a := range terms {
+		terms[a] = make([]float64, n)
+		for b := 0; b < a; b++ {
+			terms[a][b] = term(a, b)
+			terms[b][a] = terms[a][b]
+		}
+	}
+	ta, tb := make([]float64, n), make([]float64, n)
+	for it := 0; it < iter; it++ {
+		a, b, i := rnd.Intn(n), rnd.Intn(n-1), rnd.Intn(len(us[0]))
+		if b >= a {
+			b++
+		}
+		us[a][i], us[b][i] = us[b][i], us[a][i]
+		delta := 0.
+		for k := range us {
+			if k == a || k == b {
+				continue
+			}
+			ta[k], tb[k] = term(a, k), term(b, k)
+			delta += ta[k] + tb[k] - terms[a][k] - terms[b][k]
+		}
+		if delta >= 0 {
+			us[a][i], us[b][i] = us[b][i], us[a][i]
+			continue
+		}
+		for k := range us {
+			if k != a && k != b {
+				terms[a][k], terms[k][a] = ta[k], ta[k]
+				terms[b][k], terms[k][b] = tb[k], tb[k]
+			}
+		}
+	}
+}
+
+// MinDistance returns the smallest Euclidean distance between two points
+// of xs, +Inf for less than two points, eg to compare sampling plans
+func MinDistance(xs [][]float64) float64 {
+	dmin := math.Inf(1)
+	for a := range xs {
+		for b := 0; b < a; b++ {
+			d := 0.
+			for i := range xs[a] {
+				d += (xs[a][i] - xs[b][i]) * (xs[a][i] - xs[b][i])
+			}
+			dmin = math.Min(dmin, d)
+		}
+	}
+	return math.Sqrt(dmin)
+}
@@ -0,0 +1,63 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleLatinHypercube() {
+	// 10 points of the unit square, the maximin optimization spreading them
+	xmin, xmax := []float64{0, 0}, []float64{1, 1}
+	plain := LatinHypercube{Centered: true}.Sample(10, xmin, xmax, rand.New(rand.NewSource(1)))
+	spread := LatinHypercube{Centered: true, Maximin: 1000}.Sample(10, xmin, xmax, rand.New(rand.NewSource(1)))
+	fmt.Printf("smallest distance %.3f, maximin %.3f\n", MinDistance(plain), MinDistance(spread))
+	// Output:
+	// smallest distance 0.141, maximin 0.283
+}
+
+func TestLatinHypercube(t *testing.T) {
+	xmin, xmax := []float64{0, -1, 10}, []float64{10, 1, 11}
+	for _, lh := range []LatinHypercube{{}, {Centered: true}, {Maximin: 2000}} {
+		xs := lh.Sample(20, xmin, xmax, rand.New(rand.NewSource(1)))
+		if len(xs) != 20 {
+			t.Fatalf("%+v: %d points", lh, len(xs))
+		}
+		// one point in each slice of each coordinate
+		for i := range xmin {
+			slices := make(map[int]bool)
+			for _, x := range xs {
+				u := (x[i] - xmin[i]) / (xmax[i] - xmin[i]) * 20
+				if u < 0 || u >= 20 {
+					t.Fatalf("%+v: point %v outside of the box", lh, x)
+				}
+				if lh.Centered && math.Abs(u-math.Floor(u)-.5) > 1e-9 {
+					t.Errorf("%+v: point %v not centered", lh, x)
+				}
+				slices[int(u)] = true
+			}
+			if len(slices) != 20 {
+				t.Errorf("%+v: coordinate %d in %d slices", lh, i, len(slices))
+			}
+		}
+	}
+
+	// the maximin optimization increases the smallest distance on average
+	plain, spread := 0., 0.
+	for seed := uint64(0); seed < 20; seed++ {
+		plain += MinDistance(LatinHypercube{}.Sample(20, []float64{0, 0, 0}, []float64{1, 1, 1}, rand.New(rand.NewSource(seed))))
+		spread += MinDistance(LatinHypercube{Maximin: 2000}.Sample(20, []float64{0, 0, 0}, []float64{1, 1, 1}, rand.New(rand.NewSource(seed))))
+	}
+	if spread < 1.5*plain {
+		t.Errorf("mean smallest distance %g with maximin, %g without", spread/20, plain/20)
+	}
+
+	if d := MinDistance([][]float64{{0, 0}}); !math.IsInf(d, 1) {
+		t.Errorf("MinDistance of one point %g", d)
+	}
+	if xs := (LatinHypercube{Maximin: 10}).Sample(2, []float64{0}, []float64{1}, rand.New(rand.NewSource(1))); len(xs) != 2 {
+		t.Errorf("2 points: %v", xs)
+	}
+}
@@ -0,0 +1,103 @@
+package optimize
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// LineMinimizer is the directional line search of PowellMinimizer: it
+// minimizes phi(alpha) = f(p + alpha d) with BrentMinimizer, from a bracket
+// of the minimum searched from the initial steps, for direct search
+// methods. Unlike the LineSearchers, it needs no descent direction and no
+// derivative, and locates the minimum to tolerance.
+type LineMinimizer struct {
+	// Bracket are the initial steps, the Brack of BrentMinimizer: 2 steps
+	// from which a bracket is searched, or 3 steps bracketing the minimum.
+	// If nil, the bracket is searched from the steps 0 and 1.
+	Bracket []float64
+	// Tol is the relative tolerance of the step, 1e-2 if 0, the tolerance
+	// of the line searches of PowellMinimizer with its default Xtol
+	Tol float64
+	// AlphaMin and AlphaMax bound the steps if they differ, eg 0 and +Inf
+	// for positive steps. Beyond them, phi is the value at the bound plus
+	// the squared distance to it.
+	AlphaMin, AlphaMax float64
+	// MaxIter is the limit of iterations of BrentMinimizer, 500 if 0.
+	// MaxFev, if positive, limits the evaluations of f.
+	MaxIter, MaxFev int
+}
+
+// LineMinimum is the result of LineMinimizer
+type LineMinimum struct {
+	// Alpha is the step, X = p + Alpha d and F = f(X)
+	Alpha, F float64
+	X        []float64
+	// NIter and NFev are the numbers of iterations and of evaluations of f
+	NIter, NFev int
+}
+
+// Minimize minimizes f along d from p. The error is the Err of
+// BrentMinimizer, then the Result is the best step evaluated.
+func (lm *LineMinimizer) Minimize(f func([]float64) float64, p, d []float64) (LineMinimum, error) {
+	l := *lm
+	if l.Tol <= 0 {
+		l.Tol = 1e-2
+	}
+	if l.MaxIter <= 0 {
+		l.MaxIter = 500
+	}
+	// the evaluations are counted here, the count of BrentMinimizer
+	// including the reuse of the bracket midpoint
+	nfev := 0
+	var fnMaxFev func(int) bool
+	if l.MaxFev > 0 {
+		fnMaxFev = func(int) bool { return nfev >= l.MaxFev }
+	}
+	x := make([]float64, len(p))
+	phi := func(alpha float64) float64 {
+		nfev++
+		floats.AddScaledTo(x, p, alpha, d)
+		return f(x)
+	}
+	var bm BrentMinimizer
+	alpha, fa, iter, _ := l.minimize(&bm, phi, fnMaxFev)
+	if math.IsNaN(alpha) {
+		alpha = 0
+	}
+	floats.AddScaledTo(x, p, alpha, d)
+	return LineMinimum{Alpha: alpha, F: fa, X: x, NIter: iter, NFev: nfev}, bm.Err
+}
+
+// minimize minimizes phi with bm, initialized with the settings of lm
+// as they are, and returns the step clamped on the bounds. It doesn't
+// allocate without Bracket and bounds.
+func (lm *LineMinimizer) minimize(bm *BrentMinimizer, phi func(float64) float64, fnMaxFev func(int) bool) (alpha, f float64, iter, funcalls int) {
+	lo, hi := math.Min(lm.AlphaMin, lm.AlphaMax), math.Max(lm.AlphaMin, lm.AlphaMax)
+	bounded := lo < hi
+	fun := phi
+	if bounded {
+		fun = penalizedLine(phi, lo, hi)
+	}
+	bm.init(fun, lm.Tol, lm.MaxIter, fnMaxFev)
+	if lm.Bracket != nil {
+		bm.Brack = lm.Bracket
+	}
+	alpha, f, iter, funcalls = bm.Optimize()
+	if a := clampStep(alpha, lo, hi); bounded && !math.IsNaN(alpha) && a != alpha {
+		alpha, f = a, phi(a)
+		funcalls++
+	}
+	return alpha, f, iter, funcalls
+}
+
+func clampStep(alpha, lo, hi float64) float64 { return math.Max(lo, math.Min(hi, alpha)) }
+
+// penalizedLine returns phi at the step clamped on [lo, hi] plus the
+// squared distance to the bound
+func penalizedLine(phi func(float64) float64, lo, hi float64) func(float64) float64 {
+	return func(alpha float64) float64 {
+		a := clampStep(alpha, lo, hi)
+		return phi(a) + (alpha-a)*(alpha-a)
+	}
+}
@@ -0,0 +1,53 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleLineMinimizer() {
+	// the minimum of the Rosenbrock function along the first axis from (-1.2, 1)
+	lm := &LineMinimizer{Tol: 1e-6}
+	r, err := lm.Minimize(rosen, []float64{-1.2, 1}, []float64{1, 0})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("alpha %.5f x %.5f f %.5f\n", r.Alpha, r.X, r.F)
+	// steps bounded by 0.1
+	lm.AlphaMin, lm.AlphaMax = 0, .1
+	r, _ = lm.Minimize(rosen, []float64{-1.2, 1}, []float64{1, 0})
+	fmt.Printf("alpha %.5f x %.5f f %.5f\n", r.Alpha, r.X, r.F)
+	// Output:
+	// alpha 0.20503 x [-0.99497 1.00000] f 3.98997
+	// alpha 0.10000 x [-1.10000 1.00000] f 8.82000
+}
+
+func TestLineMinimizer(t *testing.T) {
+	quadratic := func(x []float64) float64 { return (x[0] - 3) * (x[0] - 3) }
+	p, d := []float64{1}, []float64{2}
+	for _, c := range []struct {
+		name string
+		lm   LineMinimizer
+		want float64
+	}{
+		{"default", LineMinimizer{}, 1},
+		{"bracket", LineMinimizer{Bracket: []float64{0, .5, 10}, Tol: 1e-8}, 1},
+		{"bounds", LineMinimizer{AlphaMin: -1, AlphaMax: .25, Tol: 1e-8}, .25},
+		{"negative bounds", LineMinimizer{AlphaMin: -5, AlphaMax: -1, Tol: 1e-8}, -1},
+	} {
+		r, err := c.lm.Minimize(quadratic, p, d)
+		if err != nil || math.Abs(r.Alpha-c.want) > 1e-2 || r.X[0] != 1+2*r.Alpha || r.F != quadratic(r.X) || r.NFev == 0 {
+			t.Errorf("%s: got %+v %v, want alpha %g", c.name, r, err, c.want)
+		}
+	}
+	n := 0
+	counted := func(x []float64) float64 { n++; return quadratic(x) }
+	r, _ := (&LineMinimizer{Tol: 1e-12, MaxFev: 6}).Minimize(counted, p, d)
+	if n > 7 || r.NFev != n || r.NFev < 6 {
+		t.Errorf("MaxFev 6: %d evaluations, NFev %d", n, r.NFev)
+	}
+	if _, err := (&LineMinimizer{Bracket: []float64{0, 5, 10}}).Minimize(quadratic, p, d); err == nil {
+		t.Error("expected an error for an invalid bracket")
+	}
+}
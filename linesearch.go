@@ -0,0 +1,237 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// LineProblem is the minimization of phi(alpha) = f(x + alpha d) over the
+// steps alpha > 0 along a descent direction d
+type LineProblem struct {
+	// Func is phi
+	Func func(alpha float64) float64
+	// Deriv, if not nil, is the derivative of phi, the directional
+	// derivative of f. It is needed by the line searches testing the
+	// curvature, eg StrongWolfe.
+	Deriv func(alpha float64) float64
+	// F0 and Deriv0 are phi(0) and phi'(0), which must be negative
+	F0, Deriv0 float64
+	// Model, if not nil, is the first order model of phi(alpha) - phi(0)
+	// of the sufficient decrease conditions instead of alpha Deriv0, eg
+	// for the projected steps of NewProjectedLineProblem
+	Model func(alpha float64) float64
+}
+
+// model returns the first order model of phi(alpha) - phi(0)
+func (p *LineProblem) model(alpha float64) float64 {
+	if p.Model != nil {
+		return p.Model(alpha)
+	}
+	return alpha * p.Deriv0
+}
+
+// NewLineProblem returns the LineProblem of f from x along d. If grad is
+// nil, Deriv is nil and Deriv0 is a central difference of phi.
+func NewLineProblem(f func([]float64) float64, grad func(grad, x []float64), x, d []float64) LineProblem {
+	xa := make([]float64, len(x))
+	phi := func(alpha float64) float64 {
+		floats.AddScaledTo(xa, x, alpha, d)
+		return f(xa)
+	}
+	p := LineProblem{Func: phi, F0: f(x)}
+	if grad == nil {
+		h := math.Cbrt(2.220446049250313e-16) * math.Max(1, floats.Norm(x, 2)) / math.Max(floats.Norm(d, 2), 1e-300)
+		p.Deriv0 = (phi(h) - phi(-h)) / (2 * h)
+		return p
+	}
+	g := make([]float64, len(x))
+	p.Deriv = func(alpha float64) float64 {
+		floats.AddScaledTo(xa, x, alpha, d)
+		grad(g, xa)
+		return floats.Dot(g, d)
+	}
+	p.Deriv0 = p.Deriv(0)
+	return p
+}
+
+// LineSearchResult is the step found by a LineSearcher
+type LineSearchResult struct {
+	// Step is the step alpha, F its value phi(alpha) and Deriv its
+	// derivative, NaN if it was not evaluated
+	Step, F, Deriv float64
+	// NFev and NDeriv are the numbers of evaluations of Func and Deriv
+	NFev, NDeriv int
+}
+
+// LineSearcher is implemented by the line searches, which search from the
+// initial step a step along a LineProblem satisfying their conditions. A
+// search failing them returns an error with the best step found, which
+// decreases phi if any evaluated step did.
+type LineSearcher interface {
+	Search(p LineProblem, step float64) (LineSearchResult, error)
+}
+
+var _ LineSearcher = &StrongWolfe{}
+
+var (
+	errNotDescent = errors.New("optimize: line search: not a descent direction")
+	errNoDeriv    = errors.New("optimize: line search: nil Deriv")
+)
+
+// StrongWolfe is the line search of Nocedal and Wright (Numerical
+// Optimization, algorithms 3.5 and 3.6) of a step satisfying the strong
+// Wolfe conditions, the sufficient decrease
+//
+//	phi(alpha) <= phi(0) + C1 alpha phi'(0)
+//
+// and the curvature condition |phi'(alpha)| <= -C2 phi'(0), which the
+// quasi-Newton methods need to keep positive definite updates. The steps
+// are extrapolated by doubling until a step brackets a minimum, and the
+// bracket is shrunk by safeguarded cubic interpolation. It needs Deriv.
+type StrongWolfe struct {
+	// C1 defaults to 1e-4 and C2 to 0.9, 0 < C1 < C2 < 1. A C2 of 0.1 is
+	// usual for the nonlinear conjugate gradients.
+	C1, C2 float64
+	// MaxStep bounds the steps, +Inf if 0
+	MaxStep float64
+	// MaxIter is the limit of evaluations of Func, 20 if 0
+	MaxIter int
+}
+
+func (sw *StrongWolfe) params() (c1, c2, maxStep float64, maxIter int) {
+	c1, c2, maxStep, maxIter = sw.C1, sw.C2, sw.MaxStep, sw.MaxIter
+	if c1 <= 0 {
+		c1 = 1e-4
+	}
+	if c2 <= 0 {
+		c2 = .9
+	}
+	if maxStep <= 0 {
+		maxStep = math.Inf(1)
+	}
+	if maxIter <= 0 {
+		maxIter = 20
+	}
+	return
+}
+
+// lineSearch counts the evaluations of a LineProblem and keeps the best
+// step decreasing phi
+type lineSearch struct {
+	p    LineProblem
+	best LineSearchResult
+}
+
+func newLineSearch(p LineProblem) *lineSearch {
+	return &lineSearch{p: p, best: LineSearchResult{F: p.F0, Deriv: p.Deriv0}}
+}
+
+func (ls *lineSearch) f(alpha float64) float64 {
+	ls.best.NFev++
+	f := ls.p.Func(alpha)
+	if f < ls.best.F {
+		ls.best.Step, ls.best.F, ls.best.Deriv = alpha, f, math.NaN()
+	}
+	return f
+}
+
+func (ls *lineSearch) deriv(alpha float64) float64 {
+	ls.best.NDeriv++
+	d := ls.p.Deriv(alpha)
+	if alpha == ls.best.Step {
+		ls.best.Deriv = d
+	}
+	return d
+}
+
+// result returns the step alpha of value f and derivative d
+func (ls *lineSearch) result(alpha, f, d float64) LineSearchResult {
+	return LineSearchResult{Step: alpha, F: f, Deriv: d, NFev: ls.best.NFev, NDeriv: ls.best.NDeriv}
+}
+
+// Search returns a step satisfying the strong Wolfe conditions
+func (sw *StrongWolfe) Search(p LineProblem, step float64) (LineSearchResult, error) {
+	ls := newLineSearch(p)
+	if p.Deriv == nil {
+		return ls.best, errNoDeriv
+	}
+	if !(p.Deriv0 < 0) {
+		return ls.best, errNotDescent
+	}
+	c1, c2, maxStep, maxIter := sw.params()
+	// (lo, fLo, dLo) is the previous step, 0 at the start
+	lo, fLo, dLo := 0., p.F0, p.Deriv0
+	alpha := math.Min(step, maxStep)
+	for ls.best.NFev < maxIter {
+		f := ls.f(alpha)
+		if f > p.F0+c1*p.model(alpha) || (lo > 0 && f >= fLo) {
+			return sw.zoom(ls, lo, fLo, dLo, alpha, f, math.NaN(), maxIter)
+		}
+		d := ls.deriv(alpha)
+		if math.Abs(d) <= -c2*p.Deriv0 {
+			return ls.result(alpha, f, d), nil
+		}
+		if d >= 0 {
+			return sw.zoom(ls, alpha, f, d, lo, fLo, dLo, maxIter)
+		}
+		if alpha >= maxStep {
+			return ls.result(alpha, f, d), errors.New("optimize: strong Wolfe: the steps reach MaxStep")
+		}
+		lo, fLo, dLo = alpha, f, d
+		alpha = math.Min(2*alpha, maxStep)
+	}
+	return ls.best, errors.New("optimize: strong Wolfe: too many evaluations")
+}
+
+// zoom shrinks the bracket of a step satisfying the strong Wolfe
+// conditions between lo, satisfying the sufficient decrease, and hi. dHi
+// is NaN if the derivative at hi is unknown.
+func (sw *StrongWolfe) zoom(ls *lineSearch, lo, fLo, dLo, hi, fHi, dHi float64, maxIter int) (LineSearchResult, error) {
+	c1, c2, _, _ := sw.params()
+	p := ls.p
+	for ls.best.NFev < maxIter {
+		alpha := interpolate(lo, fLo, dLo, hi, fHi, dHi)
+		if alpha == lo || alpha == hi {
+			break
+		}
+		f := ls.f(alpha)
+		if f > p.F0+c1*p.model(alpha) || f >= fLo {
+			hi, fHi, dHi = alpha, f, math.NaN()
+			continue
+		}
+		d := ls.deriv(alpha)
+		if math.Abs(d) <= -c2*p.Deriv0 {
+			return ls.result(alpha, f, d), nil
+		}
+		if d*(hi-lo) >= 0 {
+			hi, fHi, dHi = lo, fLo, dLo
+		}
+		lo, fLo, dLo = alpha, f, d
+	}
+	return ls.best, errors.New("optimize: strong Wolfe: the bracket can't be shrunk")
+}
+
+// interpolate returns the minimizer of the cubic interpolating phi at lo
+// and hi, or of the quadratic if dHi is NaN, safeguarded within the 10%
+// to 90% of the bracket
+func interpolate(lo, fLo, dLo, hi, fHi, dHi float64) float64 {
+	delta := hi - lo
+	var alpha float64
+	if math.IsNaN(dHi) {
+		alpha = lo - dLo*delta*delta/(2*(fHi-fLo-dLo*delta))
+	} else {
+		d1 := dLo + dHi - 3*(fLo-fHi)/(lo-hi)
+		alpha = math.NaN()
+		if r := d1*d1 - dLo*dHi; r >= 0 {
+			d2 := math.Copysign(math.Sqrt(r), delta)
+			alpha = hi - delta*(dHi+d2-d1)/(dHi-dLo+2*d2)
+		}
+	}
+	a, b := math.Min(lo+.1*delta, lo+.9*delta), math.Max(lo+.1*delta, lo+.9*delta)
+	if math.IsNaN(alpha) || alpha < a || alpha > b {
+		alpha = lo + delta/2
+	}
+	return alpha
+}
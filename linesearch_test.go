@@ -0,0 +1,76 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleStrongWolfe() {
+	// a step along the steepest descent direction of the Rosenbrock function
+	x := []float64{-1.2, 1}
+	d := make([]float64, 2)
+	rosenGrad(d, x)
+	for i := range d {
+		d[i] = -d[i]
+	}
+	p := NewLineProblem(rosen, rosenGrad, x, d)
+	r, err := (&StrongWolfe{}).Search(p, 1)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("step %.5f f %.4f -> %.4f, %d evaluations\n", r.Step, p.F0, r.F, r.NFev)
+	// Output:
+	// step 0.00146 f 24.2000 -> 15.3771, 9 evaluations
+}
+
+// wolfe reports whether r satisfies the strong Wolfe conditions of p
+func wolfe(p LineProblem, r LineSearchResult, c1, c2 float64) bool {
+	return r.Step > 0 && r.F <= p.F0+c1*r.Step*p.Deriv0 && math.Abs(p.Deriv(r.Step)) <= -c2*p.Deriv0
+}
+
+func TestStrongWolfe(t *testing.T) {
+	quadratic := func(x []float64) float64 { return (x[0] - 3) * (x[0] - 3) }
+	quadraticGrad := func(g, x []float64) { g[0] = 2 * (x[0] - 3) }
+	x := []float64{-1.2, 1}
+	d := make([]float64, 2)
+	rosenGrad(d, x)
+	for i := range d {
+		d[i] = -d[i]
+	}
+	for _, c := range []struct {
+		name string
+		p    LineProblem
+		step float64
+	}{
+		{"rosenbrock", NewLineProblem(rosen, rosenGrad, x, d), 1},
+		{"rosenbrock small step", NewLineProblem(rosen, rosenGrad, x, d), 1e-8},
+		{"quadratic extrapolation", NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{1}), 1e-3},
+		{"quadratic interpolation", NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{1}), 100},
+	} {
+		for _, c2 := range []float64{.9, .1} {
+			sw := &StrongWolfe{C2: c2, MaxIter: 50}
+			r, err := sw.Search(c.p, c.step)
+			if err != nil || !wolfe(c.p, r, 1e-4, c2) {
+				t.Errorf("%s C2=%g: got %+v %v", c.name, c2, r, err)
+			}
+		}
+	}
+
+	p := NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{-1})
+	if _, err := (&StrongWolfe{}).Search(p, 1); err != errNotDescent {
+		t.Errorf("ascent direction: got %v", err)
+	}
+	p = NewLineProblem(quadratic, nil, []float64{0}, []float64{1})
+	if math.Abs(p.Deriv0+6) > 1e-6 {
+		t.Errorf("finite difference Deriv0 %g, want -6", p.Deriv0)
+	}
+	if _, err := (&StrongWolfe{}).Search(p, 1); err != errNoDeriv {
+		t.Errorf("nil Deriv: got %v", err)
+	}
+	// the steps satisfying the curvature condition are beyond MaxStep
+	p = NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{1})
+	if r, err := (&StrongWolfe{C2: .1, MaxStep: 1}).Search(p, 1); err == nil || r.Step != 1 {
+		t.Errorf("MaxStep: got %+v %v", r, err)
+	}
+}
@@ -0,0 +1,54 @@
+package optimize
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// MethodMinimizer is a Minimizer running any gonum optimize.Method with
+// Minimize and Options, without the Task machinery of gonum: bounds are
+// handled by a BoxTransform, and the Result is the one of the package. It
+// can be a Stage of a Pipeline or the Minimizer of Restarts.
+type MethodMinimizer struct {
+	// Method defaults to optimize.NelderMead
+	Method optimize.Method
+	// Options are the options of Minimize, their Method and GonumMethod
+	// being ignored
+	Options Options
+}
+
+var _ Minimizer = &MethodMinimizer{}
+
+// NewMethodMinimizerWith returns a MethodMinimizer of method configured by opts
+func NewMethodMinimizerWith(method optimize.Method, opts ...Option) (*MethodMinimizer, error) {
+	mm := &MethodMinimizer{Method: method}
+	if err := configure(mm, opts); err != nil {
+		return nil, err
+	}
+	return mm, nil
+}
+
+func (mm *MethodMinimizer) fields() solverFields {
+	f := mm.Options.fields()
+	f.method = nil
+	return f
+}
+
+// Minimize minimizes f from x0. An error of Minimize is the Message of a
+// Result with Status Failure.
+func (mm *MethodMinimizer) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	opts := mm.Options
+	opts.GonumMethod = mm.Method
+	if mm.Method == nil {
+		opts.GonumMethod = &optimize.NelderMead{}
+	}
+	res, err := Minimize(optimize.Problem{Func: f}, x0, &opts)
+	if res == nil {
+		res = &Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure}
+	}
+	if err != nil {
+		res.Status, res.Message = optimize.Failure, err.Error()
+	}
+	return res
+}
@@ -0,0 +1,41 @@
+package optimize
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestGonumMethod(t *testing.T) {
+	// bounded BFGS on Rosenbrock with its gradient, the minimum on the bound
+	res, err := Minimize(optimize.Problem{Func: rosen, Grad: rosenGrad}, []float64{-1.2, 1}, &Options{
+		GonumMethod: &optimize.BFGS{}, Xmin: []float64{-2, -2}, Xmax: []float64{.5, 2}})
+	if err != nil || math.Abs(res.X[0]-.5) > 1e-4 || math.Abs(res.X[1]-.25) > 1e-3 || res.NGrad == 0 {
+		t.Errorf("unexpected result %+v %v", res, err)
+	}
+	// a method using the Hessian without Problem.Hess
+	if _, err := Minimize(optimize.Problem{Func: rosen, Grad: rosenGrad}, []float64{-1.2, 1}, &Options{GonumMethod: &optimize.Newton{}}); err == nil {
+		t.Error("expected an error without Hess")
+	}
+
+	// MethodMinimizer as a stage of a Pipeline
+	mm, err := NewMethodMinimizerWith(&optimize.NelderMead{}, WithBounds([]float64{0, 0}, []float64{3, 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{Stages: []Stage{{Minimizer: mm, Fraction: .5}, {Minimizer: NewPowellMinimizer()}}, MaxFev: 2000}
+	res, err = p.Minimize(optimize.Problem{Func: rosen}, []float64{2, 2})
+	if err != nil || math.Abs(res.X[0]-1) > 1e-3 || math.Abs(res.X[1]-1) > 1e-3 {
+		t.Errorf("unexpected result %+v %v", res, err)
+	}
+	if stage := res.Extra.([]*Result)[0]; stage.NFev > 1000 {
+		t.Errorf("expected at most 1000 evaluations, got %d", stage.NFev)
+	}
+	if _, err := NewMethodMinimizerWith(nil, WithMethod("cmaes")); err == nil {
+		t.Error("expected WithMethod to be unsupported")
+	}
+	if res := (&MethodMinimizer{Method: &optimize.Newton{}}).Minimize(rosen, []float64{0, 0}); res.Status != optimize.Failure || res.Message == "" {
+		t.Errorf("expected a Failure, got %+v", res)
+	}
+}
@@ -0,0 +1,186 @@
+package optimize
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics are the values exported by a MetricsObserver
+type Metrics struct {
+	// Runs is the number of runs started, Restarts the runs started after the
+	// first one, eg by Restarts, and Running the runs not done
+	Runs, Restarts, Running int
+	Evaluations, Iterations int
+	// BestF is the best value of all the runs, +Inf before the first evaluation
+	BestF float64
+	// IterationRate is the number of iterations per second of the last run
+	IterationRate float64
+}
+
+// MetricsObserver is an Observer exporting the Metrics of the runs it
+// observes, for long running optimization services: with expvar by
+// Publish, and in the text exposition format of Prometheus by ServeHTTP,
+// without depending on the Prometheus client. It can observe several
+// concurrent runs.
+type MetricsObserver struct {
+	// Namespace prefixes the names of the Prometheus metrics, "optimize" if empty
+	Namespace string
+	// Labels are the constant labels of the Prometheus metrics
+	Labels map[string]string
+
+	mu      sync.Mutex
+	m       Metrics
+	hasBest bool
+	start   time.Time
+	runIter int
+}
+
+var _ Observer = &MetricsObserver{}
+
+// NewMetricsObserver returns a MetricsObserver with the constant labels labels
+func NewMetricsObserver(labels map[string]string) *MetricsObserver {
+	return &MetricsObserver{Labels: labels}
+}
+
+// best records a value, mo.mu being locked
+func (mo *MetricsObserver) best(f float64) {
+	if !math.IsNaN(f) && (!mo.hasBest || f < mo.m.BestF) {
+		mo.m.BestF, mo.hasBest = f, true
+	}
+}
+
+// OnStart counts a run
+func (mo *MetricsObserver) OnStart(s *Start) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	if mo.m.Runs > 0 {
+		mo.m.Restarts++
+	}
+	mo.m.Runs++
+	mo.m.Running++
+	mo.start, mo.runIter = time.Now(), 0
+}
+
+// OnIteration counts an iteration
+func (mo *MetricsObserver) OnIteration(it *Iteration) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	mo.m.Iterations++
+	mo.runIter++
+	mo.best(it.F)
+	if d := time.Since(mo.start).Seconds(); d > 0 {
+		mo.m.IterationRate = float64(mo.runIter) / d
+	}
+}
+
+// OnEvaluation counts an evaluation
+func (mo *MetricsObserver) OnEvaluation(e *Evaluation) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	mo.m.Evaluations++
+	mo.best(e.F)
+}
+
+// OnDone counts the end of a run
+func (mo *MetricsObserver) OnDone(res *Result) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	if mo.m.Running > 0 {
+		mo.m.Running--
+	}
+	mo.best(res.F)
+}
+
+// Metrics returns the current metrics
+func (mo *MetricsObserver) Metrics() Metrics {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	m := mo.m
+	if !mo.hasBest {
+		m.BestF = math.Inf(1)
+	}
+	return m
+}
+
+// Publish exports the metrics as the expvar variable name, a map of runs,
+// restarts, running, evaluations, iterations, best_f (null if not finite)
+// and iteration_rate. Like expvar.Publish, it panics if name is already
+// published.
+func (mo *MetricsObserver) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		m := mo.Metrics()
+		return map[string]interface{}{"runs": m.Runs, "restarts": m.Restarts, "running": m.Running,
+			"evaluations": m.Evaluations, "iterations": m.Iterations,
+			"best_f": jsonFloat(m.BestF), "iteration_rate": jsonFloat(m.IterationRate)}
+	}))
+}
+
+// WritePrometheus writes the metrics in the text exposition format of
+// Prometheus: the counters <namespace>_runs_total, _restarts_total,
+// _evaluations_total and _iterations_total, and the gauges _running,
+// _best_f and _iteration_rate
+func (mo *MetricsObserver) WritePrometheus(w io.Writer) error {
+	m := mo.Metrics()
+	ns := mo.Namespace
+	if ns == "" {
+		ns = "optimize"
+	}
+	keys := make([]string, 0, len(mo.Labels))
+	for k := range mo.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var labels string
+	if len(keys) > 0 {
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = k + "=" + strconv.Quote(mo.Labels[k])
+		}
+		labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"runs_total", "counter", "Number of runs started.", float64(m.Runs)},
+		{"restarts_total", "counter", "Number of runs started after the first one.", float64(m.Restarts)},
+		{"evaluations_total", "counter", "Number of function evaluations.", float64(m.Evaluations)},
+		{"iterations_total", "counter", "Number of iterations.", float64(m.Iterations)},
+		{"running", "gauge", "Number of runs in progress.", float64(m.Running)},
+		{"best_f", "gauge", "Best function value.", m.BestF},
+		{"iteration_rate", "gauge", "Iterations per second of the last run.", m.IterationRate},
+	} {
+		name := ns + "_" + metric.name
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s%s %s\n", name, metric.help, name, metric.kind, name, labels, promFloat(metric.value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics to Prometheus, see WritePrometheus
+func (mo *MetricsObserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	mo.WritePrometheus(w)
+}
+
+// promFloat formats v for Prometheus, which spells the infinities +Inf and -Inf
+func promFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
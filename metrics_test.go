@@ -0,0 +1,78 @@
+package optimize
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleMetricsObserver() {
+	mo := NewMetricsObserver(map[string]string{"study": "rosen"})
+	m, _ := NewAlgorithm("LN_POWELL", WithObserver(mo))
+	rs := &Restarts{Minimizer: m, Policy: &RandomRestarts{RestartLimit: RestartLimit{MaxRestarts: 2}, Xmin: []float64{-2, -2}, Xmax: []float64{2, 2}}, Src: rand.NewSource(1)}
+	rs.Minimize(rosen, []float64{-1.2, 1})
+	metrics := mo.Metrics()
+	fmt.Println(metrics.Runs, metrics.Restarts, metrics.Running, metrics.BestF < 1e-6)
+	// eg served at /metrics with http.Handle("/metrics", mo)
+	var b strings.Builder
+	mo.WritePrometheus(&b)
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.Contains(line, "restarts") {
+			fmt.Println(line)
+		}
+	}
+	// Output:
+	// 3 2 0 true
+	// # HELP optimize_restarts_total Number of runs started after the first one.
+	// # TYPE optimize_restarts_total counter
+	// optimize_restarts_total{study="rosen"} 2
+}
+
+func TestMetricsObserver(t *testing.T) {
+	mo := &MetricsObserver{}
+	if m := mo.Metrics(); m.Runs != 0 || !math.IsInf(m.BestF, 1) {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+	m, _ := NewAlgorithm("LN_POWELL", WithObserver(mo))
+	rs := &Restarts{Minimizer: m, Policy: &RandomRestarts{RestartLimit: RestartLimit{MaxRestarts: 2}, Xmin: []float64{-2, -2}, Xmax: []float64{2, 2}}, Src: rand.NewSource(1)}
+	res, _ := rs.Minimize(rosen, []float64{-1.2, 1})
+	got := mo.Metrics()
+	if got.Runs != 3 || got.Restarts != 2 || got.Running != 0 || got.Evaluations < res.NFev-3 || got.Iterations == 0 || got.BestF > res.F || got.IterationRate <= 0 {
+		t.Errorf("unexpected metrics %+v for %+v", got, res)
+	}
+
+	rec := httptest.NewRecorder()
+	mo.Labels = map[string]string{"b": "2", "a": `"1"`}
+	mo.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") ||
+		!strings.Contains(body, "# TYPE optimize_evaluations_total counter\n") ||
+		!strings.Contains(body, `optimize_restarts_total{a="\"1\"",b="2"} 2`+"\n") {
+		t.Errorf("unexpected exposition\n%s", body)
+	}
+
+	// expvar names can't be published twice, eg with -count=2
+	name := fmt.Sprintf("optimize_test_metrics_%p", mo)
+	mo.Publish(name)
+	var vars map[string]interface{}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &vars); err != nil || vars["runs"] != 3. || vars["restarts"] != 2. {
+		t.Errorf("unexpected expvar %v %v", vars, err)
+	}
+	empty := &MetricsObserver{}
+	name = fmt.Sprintf("optimize_test_empty_%p", empty)
+	empty.Publish(name)
+	if s := expvar.Get(name).String(); !strings.Contains(s, `"best_f":null`) {
+		t.Errorf("expected a null best_f, got %s", s)
+	}
+	rec = httptest.NewRecorder()
+	empty.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "optimize_best_f +Inf\n") {
+		t.Errorf("unexpected exposition\n%s", rec.Body)
+	}
+}
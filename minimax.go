@@ -0,0 +1,146 @@
+package optimize
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Minimax minimizes the worst case max_i f_i(x) of the scenarios of F under
+// bounds, eg for a robust design over operating conditions. The maximum is
+// not differentiable where several scenarios are active, so Minimax
+// solves instead the smooth epigraph problem min t under f_i(x) <= t with
+// SQP, whose multipliers are the weights of the active scenarios.
+type Minimax struct {
+	// F returns the values f_i(x) of the scenarios at x
+	F func(x []float64) []float64
+	// Xmin, Xmax are optional bounds, checked by ValidateStart
+	Xmin, Xmax []float64
+	// Tol is the tolerance of SQP, 1e-8 if 0
+	Tol float64
+	// MaxIter is the limit of iterations, 100 if 0. MaxFev, if positive,
+	// limits the evaluations of F, checked at the end of each iteration.
+	MaxIter, MaxFev int
+	Callback        func([]float64)
+	Logger          *log.Logger
+}
+
+// MinimaxInfo is the Extra of the Result of Minimax
+type MinimaxInfo struct {
+	// Values are the values of the scenarios at the solution
+	Values []float64
+	// Weights are the multipliers of the scenarios, summing to 1 at a
+	// solution of the problem, and positive for the active ones
+	Weights []float64
+}
+
+// fevLimit stops a run after max evaluations counted by n
+type fevLimit struct {
+	n   *int
+	max int
+}
+
+func (fl fevLimit) Init() {}
+
+func (fl fevLimit) Stop(it *Iteration) optimize.Status {
+	if *fl.n >= fl.max {
+		return optimize.FunctionEvaluationLimit
+	}
+	return optimize.NotTerminated
+}
+
+// Minimize minimizes the worst case from x0 and returns the solution, F
+// being its worst case value and NFev the number of evaluations of F.
+// The Status is Failure if F has no scenario or a non-finite value at x0.
+func (mm *Minimax) Minimize(x0 []float64) *Result {
+	start := time.Now()
+	x0, note, err := feasibleStart(x0, mm.Xmin, mm.Xmax, mm.Logger)
+	if err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
+	n := len(x0)
+	nfev := 0
+	values := func(x []float64) []float64 {
+		nfev++
+		return mm.F(x)
+	}
+	worst := func(v []float64) float64 {
+		t := math.Inf(-1)
+		for _, vi := range v {
+			t = math.Max(t, vi)
+		}
+		return t
+	}
+
+	v0 := values(x0)
+	for _, vi := range v0 {
+		if math.IsNaN(vi) || math.IsInf(vi, 0) {
+			err = errors.New("optimize: minimax: non-finite scenario value at x0")
+		}
+	}
+	if len(v0) == 0 {
+		err = errors.New("optimize: minimax: no scenario")
+	}
+	if err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error(), NFev: nfev}).done(start)
+	}
+
+	// the variables of the epigraph problem are z = (x, t)
+	z0 := append(append([]float64(nil), x0...), worst(v0))
+	extend := func(b []float64, inf float64) []float64 {
+		if len(b) == 0 {
+			return nil
+		}
+		return append(append([]float64(nil), b...), inf)
+	}
+	sq := &SQP{
+		Ineq: func(z []float64) []float64 {
+			v := append([]float64(nil), values(z[:n])...)
+			for i := range v {
+				v[i] -= z[n]
+			}
+			return v
+		},
+		Grad: func(grad, z []float64) {
+			for i := range grad {
+				grad[i] = 0
+			}
+			grad[n] = 1
+		},
+		Xmin: extend(mm.Xmin, math.Inf(-1)), Xmax: extend(mm.Xmax, math.Inf(1)),
+		Tol: mm.Tol, MaxIter: mm.MaxIter, Logger: mm.Logger,
+	}
+	if mm.MaxFev > 0 {
+		sq.Stop = fevLimit{n: &nfev, max: mm.MaxFev}
+	}
+	if mm.Callback != nil {
+		sq.Callback = func(z []float64) { mm.Callback(z[:n]) }
+	}
+	r := sq.Minimize(func(z []float64) float64 { return z[n] }, z0)
+
+	res := &Result{X: r.X[:n:n], Status: r.Status, Reason: r.Reason, NIter: r.NIter, Warnings: r.Warnings}
+	if r.Status == optimize.Failure {
+		res.Message = r.Message
+	}
+	if res.Status == optimize.FunctionEvaluationLimit {
+		res.Reason = ReasonMaxFev
+	}
+	if note != "" {
+		res.Warnings = append([]string{note}, res.Warnings...)
+	}
+	mult, ok := r.Extra.(*Multipliers)
+	if !ok {
+		// SQP failed before its first iteration
+		res.Status, res.F, res.NFev = optimize.Failure, math.NaN(), nfev
+		if res.Message == "" {
+			res.Message = "optimize: minimax: no multipliers"
+		}
+		return res.done(start)
+	}
+	info := &MinimaxInfo{Values: values(res.X), Weights: mult.Ineq}
+	res.F, res.NFev, res.Extra = worst(info.Values), nfev, info
+	return res.done(start)
+}
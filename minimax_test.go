@@ -0,0 +1,63 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleMinimax() {
+	// problem CB2 of Charalambous and Conn
+	mm := &Minimax{F: func(x []float64) []float64 {
+		return []float64{
+			x[0]*x[0] + math.Pow(x[1], 4),
+			(2-x[0])*(2-x[0]) + (2-x[1])*(2-x[1]),
+			2 * math.Exp(x[1]-x[0]),
+		}
+	}}
+	res := mm.Minimize([]float64{1, -0.1})
+	info := res.Extra.(*MinimaxInfo)
+	fmt.Printf("%s x=%.4f worst case %.6f, scenarios %.4f, weights %.3f\n", res.Status, res.X, res.F, info.Values, info.Weights)
+	// Output:
+	// MethodConverge x=[1.1390 0.8996] worst case 1.952224, scenarios [1.9522 1.9522 1.5741], weights [0.430 0.570 0.000]
+}
+
+func TestMinimax(t *testing.T) {
+	two := &Minimax{F: func(x []float64) []float64 { return []float64{x[0] * x[0], (x[0] - 2) * (x[0] - 2)} }}
+	res := two.Minimize([]float64{-3})
+	info := res.Extra.(*MinimaxInfo)
+	if res.Status != optimize.MethodConverge || math.Abs(res.X[0]-1) > 1e-6 || math.Abs(res.F-1) > 1e-6 ||
+		math.Abs(info.Weights[0]-.5) > 1e-6 || math.Abs(info.Weights[1]-.5) > 1e-6 || res.NFev == 0 {
+		t.Errorf("got %v %v", res, info)
+	}
+
+	// the worst case is (x+1)^2 under x <= 0
+	bounded := &Minimax{F: func(x []float64) []float64 { return []float64{(x[0] - 3) * (x[0] - 3), (x[0] + 1) * (x[0] + 1)} }, Xmax: []float64{0}}
+	res = bounded.Minimize([]float64{-2})
+	if res.Status == optimize.Failure || math.Abs(res.X[0]) > 1e-6 || math.Abs(res.F-9) > 1e-5 {
+		t.Errorf("bounded: got %v", res)
+	}
+	// an infeasible start is projected with a warning
+	res = bounded.Minimize([]float64{5})
+	if res.Status == optimize.Failure || math.Abs(res.X[0]) > 1e-6 || len(res.Warnings) == 0 {
+		t.Errorf("infeasible start: got %v", res)
+	}
+
+	counted := &Minimax{F: two.F, MaxFev: 3}
+	if res = counted.Minimize([]float64{-3}); res.Reason != ReasonMaxFev || res.NFev < 3 {
+		t.Errorf("MaxFev: got %v", res)
+	}
+	if res = two.Minimize([]float64{math.NaN()}); res.Status != optimize.Failure {
+		t.Errorf("NaN start: got %v", res)
+	}
+	for name, f := range map[string]func([]float64) []float64{
+		"NaN scenario": func(x []float64) []float64 { return []float64{x[0] * x[0], math.NaN()} },
+		"no scenario":  func(x []float64) []float64 { return nil },
+	} {
+		if res = (&Minimax{F: f}).Minimize([]float64{1}); res.Status != optimize.Failure || res.Message == "" {
+			t.Errorf("%s: got %v", name, res)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package optimize
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Options are the options of Minimize, common to all methods
+type Options struct {
+	// Method is one of "powell" (default), "cmaes", "spsa", "neldermead",
+	// "bfgs", "lbfgs", "cg" or "sqp". The last four use Problem.Grad, or
+	// finite differences if it is nil.
+	Method string
+	// GonumMethod, if not nil, is a gonum optimize.Method used instead of
+	// Method, eg a configured optimize.NelderMead or a user method. The
+	// bounds are handled by a BoxTransform. Problem.Grad and Problem.Hess
+	// are used if the method uses them, the gradient is approximated by
+	// finite differences if Grad is nil.
+	GonumMethod optimize.Method
+	// Xmin, Xmax are optional bounds, checked by ValidateStart. An x0
+	// outside them is projected on them, with a warning to Logger and in
+	// Result.Warnings. Methods
+	// not handling bounds natively minimize f(P(x)) + |x-P(x)|^2, P being
+	// the projection on the box.
+	Xmin, Xmax []float64
+	// Ineq and Eq, if not nil, return the values of constraints c(x) <= 0 and
+	// ceq(x) = 0. sqp handles them natively, the other methods by an exact
+	// l1 penalty of weight Penalty, which defaults to 1e3.
+	Ineq, Eq func(x []float64) []float64
+	Penalty  float64
+	// Repair, if not nil, is applied to points before evaluation
+	Repair Repair
+	// Callback is called with each iterate
+	Callback func([]float64)
+	// MaxIter and MaxFev limit iterations and function evaluations. 0 means the method's default.
+	MaxIter, MaxFev int
+	// Xtol and Ftol are the tolerances of "powell", Xtol the Tol of "sqp"
+	Xtol, Ftol float64
+	// Tolerances, if not nil, are the convergence tolerances of "powell",
+	// and the tolerances on f of the gonum methods but "cmaes", see
+	// Tolerances. The test on f of gonum methods is over 100 iterations, or
+	// StallIterations if positive.
+	Tolerances *Tolerances
+	// TargetF, if not nil, stops methods with Status FunctionThreshold as soon
+	// as the penalized objective is <= *TargetF. gonum methods check it at
+	// major iterations, sqp at the iterates where the constraints hold.
+	TargetF *float64
+	// StallIterations, if positive, stops methods with Status
+	// FunctionConvergence when the best value has not decreased by more than
+	// StallTolerance over the last StallIterations iterations.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of
+	// each iteration, at major iterations for gonum methods
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run. The iterations
+	// of gonum methods are their major iterations.
+	Observer Observer
+	// Evaluator, if not nil, is used by the methods evaluating several
+	// points per iteration, currently spsa
+	Evaluator *Evaluator
+	// Initializer, if not nil, gives the first population of "cmaes"
+	Initializer Initializer
+	// Warm, if not nil, is the Result of a previous run on a slightly
+	// changed objective. powell, spsa and cmaes start from its Warm, see
+	// WarmStarter, the other methods from its X.
+	Warm *Result
+	// EvalTimeout, if positive, bounds the wall time of each evaluation of
+	// problem.Func, see TimeoutFunc. Evaluations which time out are NaN.
+	EvalTimeout time.Duration
+	// Src is the random source of stochastic methods
+	Src    rand.Source
+	Logger *log.Logger
+}
+
+// Methods are the names of the methods of Minimize
+var Methods = []string{"powell", "cmaes", "spsa", "neldermead", "bfgs", "lbfgs", "cg", "sqp"}
+
+// Minimize minimizes problem.Func from x0 with the method and options of
+// opts, which may be nil. It is the single entry point to the methods of the
+// package, like scipy.optimize.minimize. Reaching MaxIter or MaxFev is not an
+// error, Result.Status tells why the method stopped. An x0 outside the
+// bounds is projected on them, with a note in Result.Warnings, and invalid
+// bounds or a non-finite x0 are the errors of ValidateStart.
+func Minimize(problem optimize.Problem, x0 []float64, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if problem.Func == nil {
+		return nil, errors.New("optimize: nil Func")
+	}
+	if len(x0) == 0 {
+		return nil, errors.New(nonpositiveDimension)
+	}
+	method := strings.ToLower(opts.Method)
+	if method == "" {
+		method = "powell"
+	}
+	if opts.GonumMethod != nil {
+		method = "gonum"
+	}
+	known := method == "gonum"
+	for _, m := range Methods {
+		known = known || m == method
+	}
+	if !known {
+		return nil, errors.New("optimize: unknown method " + opts.Method + ", expected one of " + strings.Join(Methods, ", "))
+	}
+	if opts.Tolerances != nil {
+		if err := opts.Tolerances.validate(); err != nil {
+			return nil, err
+		}
+	}
+	x0, note, err := feasibleStart(x0, opts.Xmin, opts.Xmax, opts.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if opts.EvalTimeout > 0 {
+		problem.Func = NewTimeoutFunc(problem.Func, opts.EvalTimeout).Eval
+	}
+	penalty := opts.Penalty
+	if penalty <= 0 {
+		penalty = 1e3
+	}
+	start := time.Now()
+	res := &Result{}
+	if note != "" {
+		res.Warnings = []string{note}
+	}
+	mon := newMonitor(nil, opts.Observer, method, x0)
+	var inner Observer
+	if opts.Observer != nil {
+		inner = innerObserver{opts.Observer}
+	}
+
+	// objective seen by methods: repaired and penalized
+	var (
+		timer evalTimer
+		// nfev is atomic, for the parallel evaluations of Evaluator
+		nfev int64
+		// rankNFev are the evaluations of the Comparator or Ranker of a CmaEsCholB
+		rankNFev int
+	)
+	objective := timer.wrap(problem.Func)
+	fun := func(x []float64) float64 {
+		atomic.AddInt64(&nfev, 1)
+		y := objective(x)
+		if opts.Ineq != nil || opts.Eq != nil {
+			var c, ceq []float64
+			if opts.Ineq != nil {
+				c = opts.Ineq(x)
+			}
+			if opts.Eq != nil {
+				ceq = opts.Eq(x)
+			}
+			y += penalty * Violation(c, ceq)
+		}
+		return y
+	}
+	fun = RepairedFunc(opts.Repair, fun)
+	bounded := opts.Xmin != nil || opts.Xmax != nil
+	box := Box{Xmin: opts.Xmin, Xmax: opts.Xmax}
+	// projected returns fun for methods not handling bounds
+	projected := func() func([]float64) float64 {
+		if !bounded {
+			return fun
+		}
+		return func(x []float64) float64 {
+			px := append([]float64(nil), x...)
+			box.Project(px)
+			d := 0.
+			for i := range x {
+				d += (x[i] - px[i]) * (x[i] - px[i])
+			}
+			return fun(px) + d
+		}
+	}
+	callback := func(x []float64) {
+		res.NIter++
+		if opts.Callback != nil {
+			opts.Callback(x)
+		}
+	}
+
+	var x []float64
+	switch method {
+	case "powell":
+		pm := NewPowellMinimizer()
+		if opts.Xtol > 0 {
+			pm.Xtol = opts.Xtol
+		}
+		if opts.Ftol > 0 {
+			pm.Ftol = opts.Ftol
+		}
+		pm.MaxIter, pm.MaxFev, pm.Logger = opts.MaxIter, opts.MaxFev, opts.Logger
+		pm.Callback, pm.TargetF = callback, opts.TargetF
+		pm.StallIterations, pm.StallTolerance, pm.Stop = opts.StallIterations, opts.StallTolerance, opts.Stop
+		pm.Observer, pm.Tolerances = inner, opts.Tolerances
+		if err := warmStart(pm, opts.Warm); err != nil {
+			return nil, err
+		}
+		xs := append([]float64(nil), x0...)
+		box.Project(xs)
+		r := pm.Minimize(projected(), xs)
+		x, res.Status, res.Reason, res.Warm = r.X, r.Status, r.Reason, r.Warm
+	case "spsa":
+		sp := NewSPSA()
+		sp.Xmin, sp.Xmax, sp.Src, sp.Logger = opts.Xmin, opts.Xmax, opts.Src, opts.Logger
+		if opts.MaxIter > 0 {
+			sp.MaxIter = opts.MaxIter
+		}
+		sp.MaxFev = opts.MaxFev
+		sp.Callback, sp.TargetF = callback, opts.TargetF
+		sp.StallIterations, sp.StallTolerance, sp.Stop = opts.StallIterations, opts.StallTolerance, opts.Stop
+		sp.Observer, sp.Evaluator = inner, opts.Evaluator
+		if err := warmStart(sp, opts.Warm); err != nil {
+			return nil, err
+		}
+		r := sp.Minimize(fun, x0)
+		x, res.Status, res.Reason, res.Warm = r.X, r.Status, r.Reason, r.Warm
+		res.Evals.Batches, res.Evals.BatchPoints = r.Evals.Batches, r.Evals.BatchPoints
+	case "sqp":
+		sq := NewSQP()
+		sq.Ineq, sq.Eq, sq.Xmin, sq.Xmax = opts.Ineq, opts.Eq, opts.Xmin, opts.Xmax
+		if problem.Grad != nil && opts.Repair == nil {
+			sq.Grad = func(grad, x []float64) {
+				defer timer.since(time.Now())
+				problem.Grad(grad, x)
+			}
+		}
+		if opts.Xtol > 0 {
+			sq.Tol = opts.Xtol
+		}
+		if opts.MaxIter > 0 {
+			sq.MaxIter = opts.MaxIter
+		}
+		sq.MaxFev, sq.Logger, sq.Callback = opts.MaxFev, opts.Logger, callback
+		sq.Stop, sq.Observer = opts.Stop, inner
+		sq.TargetF, sq.StallIterations, sq.StallTolerance = opts.TargetF, opts.StallIterations, opts.StallTolerance
+		// the constraints are not penalized
+		r := sq.Minimize(RepairedFunc(opts.Repair, func(x []float64) float64 {
+			atomic.AddInt64(&nfev, 1)
+			return objective(x)
+		}), x0)
+		x, res.Status, res.Reason, res.Extra = r.X, r.Status, r.Reason, r.Extra
+		res.NGrad, res.Warnings = r.NGrad, append(res.Warnings, r.Warnings...)
+		if r.Status == optimize.Failure {
+			res.Message = r.Message
+		}
+	default:
+		var m optimize.Method
+		// the iterations and evaluations of gonum methods are observed here
+		p := optimize.Problem{Func: mon.wrap(projected())}
+		recorder := callbackRecorder(func(x []float64) {
+			callback(x)
+			mon.iterate(res.NIter, 0, x, nil)
+		})
+		var (
+			cma *CmaEsCholB
+			tr  *BoxTransform
+		)
+		switch method {
+		case "gonum":
+			m = opts.GonumMethod
+			plain := opts.Repair == nil && opts.Ineq == nil && opts.Eq == nil
+			uses, err := m.Uses(optimize.Available{Grad: true, Hess: problem.Hess != nil && plain && !bounded})
+			if err != nil {
+				return nil, err
+			}
+			if bounded {
+				// the method minimizes over the variables of the transform
+				tr = &BoxTransform{Xmin: opts.Xmin, Xmax: opts.Xmax}
+				p.Func = tr.Func(mon.wrap(fun))
+				recorder = callbackRecorder(func(z []float64) {
+					x := tr.ToBox(nil, z)
+					callback(x)
+					mon.iterate(res.NIter, 0, x, nil)
+				})
+			}
+			if uses.Grad {
+				if problem.Grad != nil && plain {
+					grad := func(grad, x []float64) {
+						defer timer.since(time.Now())
+						problem.Grad(grad, x)
+					}
+					if p.Grad = grad; tr != nil {
+						p.Grad = tr.Grad(grad)
+					}
+				} else {
+					f := p.Func
+					p.Grad = func(grad, x []float64) {
+						copy(grad, Gradient(f, x, nil))
+					}
+				}
+			}
+			if uses.Hess {
+				p.Hess = problem.Hess
+			}
+		case "cmaes":
+			cma = &CmaEsCholB{Xmin: opts.Xmin, Xmax: opts.Xmax, Src: opts.Src, TargetF: opts.TargetF,
+				StallIterations: opts.StallIterations, StallTolerance: opts.StallTolerance, Observer: inner, Initializer: opts.Initializer}
+			if err := warmStart(cma, opts.Warm); err != nil {
+				return nil, err
+			}
+			m = cma
+			p.Func, recorder = fun, callbackRecorder(callback)
+		case "neldermead":
+			m = &optimize.NelderMead{}
+		default:
+			if problem.Grad != nil && !bounded && opts.Repair == nil && opts.Ineq == nil && opts.Eq == nil {
+				p.Grad = func(grad, x []float64) {
+					defer timer.since(time.Now())
+					problem.Grad(grad, x)
+				}
+			} else {
+				f := p.Func
+				p.Grad = func(grad, x []float64) {
+					copy(grad, Gradient(f, x, nil))
+				}
+			}
+			switch method {
+			case "bfgs":
+				m = &optimize.BFGS{}
+			case "lbfgs":
+				m = &optimize.LBFGS{}
+			default:
+				m = &optimize.CG{}
+			}
+		}
+		settings := &optimize.Settings{
+			MajorIterations: opts.MaxIter,
+			FuncEvaluations: opts.MaxFev,
+			Recorder:        recorder,
+		}
+		if method != "cmaes" {
+			switch {
+			case opts.Tolerances != nil:
+				iterations := opts.StallIterations
+				if iterations <= 0 {
+					iterations = 100
+				}
+				settings.Converger = &toleranceConverger{Tolerances: *opts.Tolerances, Iterations: iterations}
+			case opts.StallIterations > 0:
+				settings.Converger = &optimize.FunctionConverge{Absolute: opts.StallTolerance, Iterations: opts.StallIterations}
+			}
+			if opts.TargetF != nil {
+				settings.Converger = &TargetConverger{Target: *opts.TargetF, Converger: settings.Converger}
+			}
+		}
+		if opts.Stop != nil {
+			settings.Converger = &StopConverger{Stop: opts.Stop, Converger: settings.Converger}
+		}
+		if opts.Warm != nil && cma == nil && len(opts.Warm.X) == len(x0) {
+			x0 = opts.Warm.X
+		}
+		if tr != nil {
+			x0 = tr.FromBox(nil, x0)
+		}
+		c, _ := m.(*CmaEsCholB)
+		if c != nil {
+			// the evaluations of its Comparator or Ranker count in MaxFev
+			c.budget = opts.MaxFev
+		}
+		r, err := optimize.Minimize(p, x0, settings, m)
+		if c != nil {
+			c.budget, rankNFev = 0, c.RankEvaluations()
+		}
+		if r == nil {
+			return nil, err
+		}
+		if res.Status = r.Status; res.Status == optimize.Failure && err != nil {
+			return nil, err
+		}
+		x, res.NGrad, res.NHess = r.X, r.Stats.GradEvaluations, r.Stats.HessEvaluations
+		if c != nil {
+			res.Warnings = append(res.Warnings, c.Warnings()...)
+		}
+		if tr != nil {
+			x = tr.ToBox(nil, x)
+		}
+		if cma != nil {
+			res.Warm = cma.WarmState()
+			if status, reason := cma.stop(); status == res.Status {
+				res.Reason = reason
+			}
+		}
+	}
+	res.X = append([]float64(nil), x...)
+	if bounded {
+		box.Project(res.X)
+	}
+	if opts.Repair != nil {
+		opts.Repair.Repair(res.X)
+	}
+	// the value at the solution, unpenalized, is an evaluation of the run
+	atomic.AddInt64(&nfev, 1)
+	res.F = objective(res.X)
+	res.NFev = int(atomic.LoadInt64(&nfev)) + rankNFev
+	if opts.Observer != nil {
+		opts.Observer.OnEvaluation(&Evaluation{X: res.X, F: res.F, N: res.NFev})
+	}
+	res.Evals.FuncTime = timer.elapsed()
+	mon.done(res.done(start))
+	if math.IsNaN(res.F) {
+		return res, errors.New("optimize: NaN function value at solution")
+	}
+	return res, nil
+}
+
+// warmStart primes ws with prev, if not nil
+func warmStart(ws WarmStarter, prev *Result) error {
+	if prev == nil {
+		return nil
+	}
+	return ws.WarmStart(prev)
+}
+
+// callbackRecorder is an optimize.Recorder calling fn at major iterations
+type callbackRecorder func([]float64)
+
+// Init for optimize.Recorder
+func (callbackRecorder) Init() error { return nil }
+
+// Record calls fn at major iterations
+func (fn callbackRecorder) Record(loc *optimize.Location, op optimize.Operation, _ *optimize.Stats) error {
+	if op == optimize.MajorIteration {
+		fn(loc.X)
+	}
+	return nil
+}
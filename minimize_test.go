@@ -0,0 +1,80 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleMinimize() {
+	problem := optimize.Problem{Func: rosen}
+	for _, method := range []string{"powell", "neldermead", "bfgs"} {
+		res, err := Minimize(problem, []float64{-1.2, 1}, &Options{Method: method, Src: rand.NewSource(1)})
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s %.3f %s\n", method, res.X, res.Status)
+	}
+	// Output:
+	// powell [1.000 1.000] MethodConverge
+	// neldermead [1.000 1.000] FunctionConvergence
+	// bfgs [1.000 1.000] GradientThreshold
+}
+
+func ExampleMinimize_constrained() {
+	// minimize x0+x1 subject to x0^2+x1^2 <= 1 and x1 >= -0.5
+	problem := optimize.Problem{Func: func(x []float64) float64 { return x[0] + x[1] }}
+	opts := &Options{
+		Ineq:   func(x []float64) []float64 { return []float64{x[0]*x[0] + x[1]*x[1] - 1} },
+		Xmin:   []float64{math.Inf(-1), -.5},
+		Method: "cmaes",
+		Src:    rand.NewSource(1),
+	}
+	res, err := Minimize(problem, []float64{0, 0}, opts)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.3f %.3f\n", res.X, res.F)
+	// Output:
+	// [-0.866 -0.500] -1.366
+}
+
+func TestMinimize(t *testing.T) {
+	problem := optimize.Problem{Func: rosen, Grad: rosenGrad}
+	for _, method := range []string{"powell", "cmaes", "spsa", "neldermead"} {
+		opts := &Options{Method: method, Src: rand.NewSource(1), Xmin: []float64{-2, -2}, Xmax: []float64{2, .5}}
+		if method == "spsa" {
+			opts.MaxIter = 20000
+		}
+		iters, calls := 0, 0
+		opts.Callback = func([]float64) { iters++ }
+		counted := optimize.Problem{Func: func(x []float64) float64 { calls++; return rosen(x) }, Grad: rosenGrad}
+		res, err := Minimize(counted, []float64{-1.2, 0}, opts)
+		if err != nil {
+			t.Errorf("%s: %v", method, err)
+			continue
+		}
+		// bounded optimum of rosenbrock with x1 <= .5
+		want := []float64{.7079, .5}
+		if !floats.EqualApprox(res.X, want, .02) {
+			t.Errorf("%s: expected %.4f, got %.4f", method, want, res.X)
+		}
+		// NFev includes the evaluation of the solution
+		if res.NFev != calls || res.NIter != iters || res.Message == "" {
+			t.Errorf("%s: bad counts %+v %d", method, res, iters)
+		}
+	}
+	for _, method := range []string{"bfgs", "lbfgs", "cg"} {
+		res, err := Minimize(problem, []float64{-1.2, 1}, &Options{Method: method})
+		if err != nil || !floats.EqualApprox(res.X, []float64{1, 1}, 1e-4) {
+			t.Errorf("%s: %v %v", method, res, err)
+		}
+	}
+	if _, err := Minimize(problem, []float64{0, 0}, &Options{Method: "de"}); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
@@ -0,0 +1,180 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// MinimizerMethod is a gonum optimize.Method running a Minimizer, eg SPSA
+// or LatticeSearch, so that the derivative-free solvers of the package can
+// be used by optimize.Minimize with its Settings, Recorder and Converger.
+// The evaluations of the Minimizer are gonum tasks. If it has a Stop field,
+// like the solvers of the package, the end of each of its iterations is a
+// major iteration at the best point evaluated, and the Minimizer stops when
+// gonum converges; otherwise its only major iteration is its result. The
+// limits of the Minimizer apply along with the ones of Settings.
+type MinimizerMethod struct {
+	Minimizer Minimizer
+
+	status optimize.Status
+	err    error
+	res    *Result
+}
+
+var (
+	_ optimize.Method   = (*MinimizerMethod)(nil)
+	_ optimize.Statuser = (*MinimizerMethod)(nil)
+	_ Needser           = (*MinimizerMethod)(nil)
+)
+
+// Needs for Needser, for gonum versions before Uses
+func (mm *MinimizerMethod) Needs() struct{ Gradient, Hessian bool } {
+	return struct{ Gradient, Hessian bool }{false, false}
+}
+
+// Uses for gonum optimize.Method
+func (mm *MinimizerMethod) Uses(has optimize.Available) (optimize.Available, error) {
+	return optimize.Available{}, nil
+}
+
+// Init for gonum optimize.Method. Invalid settings stop the run at once
+// with Status Failure.
+func (mm *MinimizerMethod) Init(dim, tasks int) int {
+	mm.status, mm.err, mm.res = optimize.NotTerminated, nil, nil
+	switch {
+	case dim <= 0:
+		mm.err = errors.New(nonpositiveDimension)
+	case tasks < 0:
+		mm.err = errors.New(negativeTasks)
+	case mm.Minimizer == nil:
+		mm.err = errors.New("optimize: MinimizerMethod without Minimizer")
+	}
+	if mm.err != nil {
+		mm.status = optimize.Failure
+	}
+	return 1
+}
+
+// Run for gonum optimize.Method
+func (mm *MinimizerMethod) Run(operation chan<- optimize.Task, result <-chan optimize.Task, tasks []optimize.Task) {
+	if mm.err != nil {
+		operation <- optimize.Task{Op: optimize.MethodDone, Location: tasks[0].Location}
+		for range result {
+		}
+		close(operation)
+		return
+	}
+	// the Minimizer runs in its goroutine, each of its tasks waiting for
+	// its reply, closed when gonum stops
+	reply := make(chan optimize.Task, 1)
+	done := make(chan struct{})
+	go mm.minimize(operation, reply, done, tasks[0].Location.X)
+	closed := false
+	for task := range result {
+		switch task.Op {
+		case optimize.PostIteration:
+			if !closed {
+				close(reply)
+				closed = true
+			}
+		case optimize.FuncEvaluation, optimize.MajorIteration:
+			if !closed {
+				reply <- task
+			}
+		}
+	}
+	if !closed {
+		close(reply)
+	}
+	<-done
+	close(operation)
+}
+
+// minimize runs the Minimizer from x0, sending its tasks on operation
+func (mm *MinimizerMethod) minimize(operation chan<- optimize.Task, reply <-chan optimize.Task, done chan<- struct{}, x0 []float64) {
+	defer close(done)
+	stopped := false
+	bestX, bestF := append([]float64(nil), x0...), math.Inf(1)
+	// send sends a task and returns its reply, false once gonum has stopped
+	send := func(task optimize.Task) (optimize.Task, bool) {
+		if stopped {
+			return task, false
+		}
+		operation <- task
+		r, ok := <-reply
+		stopped = !ok
+		return r, ok
+	}
+	majorF := math.Inf(1)
+	majorTask := func() optimize.Task {
+		majorF = bestF
+		return optimize.Task{Op: optimize.MajorIteration, Location: &optimize.Location{X: append([]float64(nil), bestX...), F: bestF}}
+	}
+	major := func() bool {
+		_, ok := send(majorTask())
+		return ok
+	}
+	f := func(x []float64) float64 {
+		r, ok := send(optimize.Task{Op: optimize.FuncEvaluation, Location: &optimize.Location{X: append([]float64(nil), x...)}})
+		if !ok {
+			// the evaluations after the stop are never improvements
+			return math.Inf(1)
+		}
+		if y := r.Location.F; y < bestF {
+			copy(bestX, x)
+			bestF = y
+		}
+		return r.Location.F
+	}
+
+	var stop *StopCondition
+	if c, ok := mm.Minimizer.(configurable); ok {
+		stop = c.fields().stop
+	}
+	if stop != nil {
+		// the major iterations are sent at the end of the iterations
+		prev := *stop
+		var cond StopCondition = StopFunc(func(*Iteration) bool { return !major() })
+		if prev != nil {
+			cond = Or(prev, cond)
+		}
+		*stop = cond
+		defer func() { *stop = prev }()
+	}
+	res := mm.Minimizer.Minimize(f, x0)
+	mm.res = res
+	if stopped {
+		if bestF < majorF {
+			// gonum updates its optimum with the major iterations sent
+			// after the stop, without reply
+			operation <- majorTask()
+		}
+		return
+	}
+	if res.F < bestF {
+		bestX, bestF = res.X, res.F
+	}
+	if !major() {
+		return
+	}
+	if mm.status = res.Status; mm.status == optimize.NotTerminated {
+		mm.status = optimize.MethodConverge
+	}
+	if res.Status == optimize.Failure {
+		mm.err = errors.New(res.Message)
+	}
+	operation <- optimize.Task{Op: optimize.MethodDone}
+}
+
+// Status for gonum optimize.Statuser
+func (mm *MinimizerMethod) Status() (optimize.Status, error) {
+	return mm.status, mm.err
+}
+
+// Result returns the Result of the Minimizer in the last run, nil if it
+// didn't start
+func (mm *MinimizerMethod) Result() *Result {
+	return mm.res
+}
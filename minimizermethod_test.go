@@ -0,0 +1,91 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleMinimizerMethod() {
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	// gonum stops SPSA after 100 major iterations
+	res, err := optimize.Minimize(optimize.Problem{Func: func(x []float64) float64 {
+		return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2)
+	}}, []float64{0, 0}, &optimize.Settings{MajorIterations: 100}, &MinimizerMethod{Minimizer: sp})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%s %.2f\n", res.Status, res.X)
+	// Output:
+	// IterationLimit [1.01 -2.00]
+}
+
+func TestMinimizerMethod(t *testing.T) {
+	quad := func(x []float64) float64 { return (x[0]-3)*(x[0]-3) + (x[1]+1)*(x[1]+1) }
+
+	// LatticeSearch converges: the Status of MethodDone
+	ls := NewLatticeSearch()
+	stop := StopFunc(func(*Iteration) bool { return false })
+	ls.Stop = stop
+	mm := &MinimizerMethod{Minimizer: ls}
+	rec := &countRecorder{}
+	res, err := optimize.Minimize(optimize.Problem{Func: quad}, []float64{0, 0}, &optimize.Settings{Recorder: rec}, mm)
+	if err != nil || res.Status != optimize.MethodConverge || res.F != 0 || res.X[0] != 3 || res.X[1] != -1 {
+		t.Fatalf("unexpected result %+v %v", res, err)
+	}
+	if lr := mm.Result(); lr == nil || res.Stats.FuncEvaluations != lr.NFev || res.Stats.MajorIterations != lr.NIter+1 || rec.major != res.Stats.MajorIterations {
+		t.Errorf("expected %d evaluations and %d major iterations, got %+v, %d recorded", lr.NFev, lr.NIter+1, res.Stats, rec.major)
+	}
+	if _, ok := ls.Stop.(StopFunc); !ok {
+		t.Error("expected Stop to be restored")
+	}
+
+	// gonum limits stop the Minimizer at its next iteration
+	pm := NewPowellMinimizer()
+	mm = &MinimizerMethod{Minimizer: pm}
+	res, err = optimize.Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &optimize.Settings{FuncEvaluations: 50}, mm)
+	if err != nil || res.Status != optimize.FunctionEvaluationLimit || res.Stats.FuncEvaluations != 50 || res.F > rosen([]float64{-1.2, 1}) {
+		t.Errorf("unexpected result %+v %v", res, err)
+	}
+	if r := mm.Result(); r == nil || r.F < res.F {
+		t.Errorf("expected the best point of the evaluations, got %+v", r)
+	}
+
+	// a Minimizer without Stop has a major iteration at its result
+	fn := MinimizerFunc(func(f func([]float64) float64, x0 []float64) *Result {
+		x := []float64{3, -1}
+		return &Result{X: x, F: f(x), Status: optimize.Success}
+	})
+	res, err = optimize.Minimize(optimize.Problem{Func: quad}, []float64{0, 0}, nil, &MinimizerMethod{Minimizer: fn})
+	if err != nil || res.Status != optimize.Success || res.F != 0 || res.Stats.MajorIterations != 1 {
+		t.Errorf("unexpected result %+v %v", res, err)
+	}
+
+	// failures
+	_, err = optimize.Minimize(optimize.Problem{Func: quad}, []float64{0, 0}, nil, &MinimizerMethod{})
+	if err == nil {
+		t.Error("expected an error without Minimizer")
+	}
+	pm = NewPowellMinimizer()
+	pm.Xtol = -1
+	res, err = optimize.Minimize(optimize.Problem{Func: quad}, []float64{0, 0}, nil, &MinimizerMethod{Minimizer: pm})
+	if err == nil || res.Status != optimize.Failure {
+		t.Errorf("expected the error of Validate, got %v %v", res.Status, err)
+	}
+}
+
+// countRecorder counts the major iterations
+type countRecorder struct{ major int }
+
+func (r *countRecorder) Init() error { return nil }
+
+func (r *countRecorder) Record(_ *optimize.Location, op optimize.Operation, _ *optimize.Stats) error {
+	if op == optimize.MajorIteration {
+		r.major++
+	}
+	return nil
+}
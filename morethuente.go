@@ -0,0 +1,235 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+)
+
+var _ LineSearcher = &MoreThuente{}
+
+// MoreThuente is the line search of Moré and Thuente (Line search
+// algorithms with guaranteed sufficient decrease, 1994), the dcsrch of
+// MINPACK-2, of a step satisfying the strong Wolfe conditions, the usual
+// line search of L-BFGS. The interval of uncertainty is updated by the
+// minimizers of cubic and quadratic interpolations of phi and its
+// derivative, safeguarded so that its width decreases. It needs Deriv, and
+// ignores the Model of the LineProblem.
+type MoreThuente struct {
+	// C1 defaults to 1e-4 and C2 to 0.9, 0 < C1 < C2 < 1
+	C1, C2 float64
+	// XTol is the relative width of the interval of uncertainty stopping
+	// the search with an error, 1e-10 if 0
+	XTol float64
+	// MinStep and MaxStep bound the steps, 0 and +Inf if 0
+	MinStep, MaxStep float64
+	// MaxIter is the limit of evaluations of Func, 20 if 0
+	MaxIter int
+}
+
+// Search returns a step satisfying the strong Wolfe conditions
+func (mt *MoreThuente) Search(p LineProblem, step float64) (LineSearchResult, error) {
+	ls := newLineSearch(p)
+	if p.Deriv == nil {
+		return ls.best, errNoDeriv
+	}
+	if !(p.Deriv0 < 0) {
+		return ls.best, errNotDescent
+	}
+	c1, c2, xtol, stpmin, stpmax, maxIter := mt.C1, mt.C2, mt.XTol, mt.MinStep, mt.MaxStep, mt.MaxIter
+	if c1 <= 0 {
+		c1 = 1e-4
+	}
+	if c2 <= 0 {
+		c2 = .9
+	}
+	if xtol <= 0 {
+		xtol = 1e-10
+	}
+	if stpmax <= 0 {
+		stpmax = math.Inf(1)
+	}
+	if maxIter <= 0 {
+		maxIter = 20
+	}
+	const xtrapl, xtrapu = 1.1, 4.
+	finit, ginit := p.F0, p.Deriv0
+	gtest := c1 * ginit
+	width := stpmax - stpmin
+	width1 := 2 * width
+	// x is the best step, y the other end of the interval of uncertainty
+	x := mtPoint{f: finit, d: ginit}
+	y := x
+	stage1, brackt := true, false
+	stp := math.Min(math.Max(step, stpmin), stpmax)
+	stmin, stmax := 0., stp+xtrapu*stp
+
+	for ls.best.NFev < maxIter {
+		f := ls.f(stp)
+		g := ls.deriv(stp)
+		ftest := finit + stp*gtest
+		if stage1 && f <= ftest && g >= 0 {
+			stage1 = false
+		}
+		switch {
+		case f <= ftest && math.Abs(g) <= -c2*ginit:
+			return ls.result(stp, f, g), nil
+		case brackt && (stp <= stmin || stp >= stmax):
+			return ls.best, errors.New("optimize: Moré-Thuente: rounding errors prevent progress")
+		case brackt && stmax-stmin <= xtol*stmax:
+			return ls.best, errors.New("optimize: Moré-Thuente: the interval of uncertainty is below XTol")
+		case stp == stpmax && f <= ftest && g <= gtest:
+			return ls.result(stp, f, g), errors.New("optimize: Moré-Thuente: the steps reach MaxStep")
+		case stp == stpmin && (f > ftest || g >= gtest):
+			return ls.best, errors.New("optimize: Moré-Thuente: the steps reach MinStep")
+		}
+
+		cur := mtPoint{stp: stp, f: f, d: g}
+		if stage1 && f <= x.f && f > ftest {
+			// the modified function phi(alpha) - alpha gtest, until a step
+			// has a sufficient decrease and a nonnegative derivative
+			mod := func(p mtPoint) mtPoint { return mtPoint{stp: p.stp, f: p.f - p.stp*gtest, d: p.d - gtest} }
+			xm, ym, cm := mod(x), mod(y), mod(cur)
+			stp = mtStep(&xm, &ym, cm, &brackt, stmin, stmax)
+			x = mtPoint{stp: xm.stp, f: xm.f + xm.stp*gtest, d: xm.d + gtest}
+			y = mtPoint{stp: ym.stp, f: ym.f + ym.stp*gtest, d: ym.d + gtest}
+		} else {
+			stp = mtStep(&x, &y, cur, &brackt, stmin, stmax)
+		}
+
+		// a bisection step if the interval doesn't shrink enough
+		if brackt {
+			if math.Abs(y.stp-x.stp) >= .66*width1 {
+				stp = x.stp + .5*(y.stp-x.stp)
+			}
+			width1, width = width, math.Abs(y.stp-x.stp)
+			stmin, stmax = math.Min(x.stp, y.stp), math.Max(x.stp, y.stp)
+		} else {
+			stmin, stmax = stp+xtrapl*(stp-x.stp), stp+xtrapu*(stp-x.stp)
+		}
+		stp = math.Min(math.Max(stp, stpmin), stpmax)
+		if brackt && (stp <= stmin || stp >= stmax || stmax-stmin <= xtol*stmax) {
+			stp = x.stp
+		}
+	}
+	return ls.best, errors.New("optimize: Moré-Thuente: too many evaluations")
+}
+
+// mtPoint is a step, its value and its derivative
+type mtPoint struct {
+	stp, f, d float64
+}
+
+// mtStep is the dcstep of MINPACK-2: it updates the interval of
+// uncertainty of ends x, the best step, and y with the step p, and
+// returns the next step, within [stpmin, stpmax] if not bracketed
+func mtStep(x, y *mtPoint, p mtPoint, brackt *bool, stpmin, stpmax float64) float64 {
+	sgnd := p.d * math.Copysign(1, x.d)
+	max3 := func(a, b, c float64) float64 { return math.Max(math.Abs(a), math.Max(math.Abs(b), math.Abs(c))) }
+	var stpf float64
+	switch {
+	case p.f > x.f:
+		// a higher value: the minimum is bracketed. The step is the cubic
+		// step if it is closer to x than the quadratic one, else their
+		// midpoint.
+		theta := 3*(x.f-p.f)/(p.stp-x.stp) + x.d + p.d
+		s := max3(theta, x.d, p.d)
+		gamma := s * math.Sqrt((theta/s)*(theta/s)-(x.d/s)*(p.d/s))
+		if p.stp < x.stp {
+			gamma = -gamma
+		}
+		pp, q := (gamma-x.d)+theta, ((gamma-x.d)+gamma)+p.d
+		stpc := x.stp + pp/q*(p.stp-x.stp)
+		stpq := x.stp + x.d/((x.f-p.f)/(p.stp-x.stp)+x.d)/2*(p.stp-x.stp)
+		if math.Abs(stpc-x.stp) < math.Abs(stpq-x.stp) {
+			stpf = stpc
+		} else {
+			stpf = stpc + (stpq-stpc)/2
+		}
+		*brackt = true
+	case sgnd < 0:
+		// derivatives of opposite signs: the minimum is bracketed. The step
+		// is the farthest from p of the cubic and secant steps.
+		theta := 3*(x.f-p.f)/(p.stp-x.stp) + x.d + p.d
+		s := max3(theta, x.d, p.d)
+		gamma := s * math.Sqrt((theta/s)*(theta/s)-(x.d/s)*(p.d/s))
+		if p.stp > x.stp {
+			gamma = -gamma
+		}
+		pp, q := (gamma-p.d)+theta, ((gamma-p.d)+gamma)+x.d
+		stpc := p.stp + pp/q*(x.stp-p.stp)
+		stpq := p.stp + p.d/(p.d-x.d)*(x.stp-p.stp)
+		if math.Abs(stpc-p.stp) > math.Abs(stpq-p.stp) {
+			stpf = stpc
+		} else {
+			stpf = stpq
+		}
+		*brackt = true
+	case math.Abs(p.d) < math.Abs(x.d):
+		// a lower value and a derivative decreasing in magnitude: the cubic
+		// step is used if it goes towards x
+		theta := 3*(x.f-p.f)/(p.stp-x.stp) + x.d + p.d
+		s := max3(theta, x.d, p.d)
+		gamma := s * math.Sqrt(math.Max(0, (theta/s)*(theta/s)-(x.d/s)*(p.d/s)))
+		if p.stp > x.stp {
+			gamma = -gamma
+		}
+		pp, q := (gamma-p.d)+theta, (gamma+(x.d-p.d))+gamma
+		var stpc float64
+		switch r := pp / q; {
+		case r < 0 && gamma != 0:
+			stpc = p.stp + r*(x.stp-p.stp)
+		case p.stp > x.stp:
+			stpc = stpmax
+		default:
+			stpc = stpmin
+		}
+		stpq := p.stp + p.d/(p.d-x.d)*(x.stp-p.stp)
+		if *brackt {
+			if math.Abs(stpc-p.stp) < math.Abs(stpq-p.stp) {
+				stpf = stpc
+			} else {
+				stpf = stpq
+			}
+			if p.stp > x.stp {
+				stpf = math.Min(p.stp+.66*(y.stp-p.stp), stpf)
+			} else {
+				stpf = math.Max(p.stp+.66*(y.stp-p.stp), stpf)
+			}
+		} else {
+			if math.Abs(stpc-p.stp) > math.Abs(stpq-p.stp) {
+				stpf = stpc
+			} else {
+				stpf = stpq
+			}
+			stpf = math.Max(stpmin, math.Min(stpmax, stpf))
+		}
+	default:
+		// a lower value and a derivative not decreasing in magnitude: the
+		// cubic step between p and y if bracketed, else the bound
+		switch {
+		case *brackt:
+			theta := 3*(p.f-y.f)/(y.stp-p.stp) + y.d + p.d
+			s := max3(theta, y.d, p.d)
+			gamma := s * math.Sqrt((theta/s)*(theta/s)-(y.d/s)*(p.d/s))
+			if p.stp > y.stp {
+				gamma = -gamma
+			}
+			pp, q := (gamma-p.d)+theta, ((gamma-p.d)+gamma)+y.d
+			stpf = p.stp + pp/q*(y.stp-p.stp)
+		case p.stp > x.stp:
+			stpf = stpmax
+		default:
+			stpf = stpmin
+		}
+	}
+
+	if p.f > x.f {
+		*y = p
+	} else {
+		if sgnd < 0 {
+			*y = *x
+		}
+		*x = p
+	}
+	return stpf
+}
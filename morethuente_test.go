@@ -0,0 +1,76 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ExampleMoreThuente() {
+	x := []float64{-1.2, 1}
+	d := make([]float64, 2)
+	rosenGrad(d, x)
+	for i := range d {
+		d[i] = -d[i]
+	}
+	p := NewLineProblem(rosen, rosenGrad, x, d)
+	r, err := (&MoreThuente{}).Search(p, 1)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("step %.5f f %.4f -> %.4f, %d evaluations\n", r.Step, p.F0, r.F, r.NFev)
+	// Output:
+	// step 0.00107 f 24.2000 -> 6.3253, 5 evaluations
+}
+
+func TestMoreThuente(t *testing.T) {
+	// the functions of the tables 1, 2, 4, 5 and 6 of Moré and Thuente,
+	// with their numbers of evaluations from the steps 1e-3, 1e-1, 1e1, 1e3
+	line := func(f, d func(a float64) float64) LineProblem {
+		return LineProblem{Func: f, Deriv: d, F0: f(0), Deriv0: d(0)}
+	}
+	beta := .004
+	gamma := func(b float64) float64 { return math.Sqrt(1+b*b) - b }
+	yanai := func(b1, b2 float64) LineProblem {
+		f := func(a float64) float64 {
+			return gamma(b1)*math.Sqrt((1-a)*(1-a)+b2*b2) + gamma(b2)*math.Sqrt(a*a+b1*b1)
+		}
+		d := func(a float64) float64 {
+			return -gamma(b1)*(1-a)/math.Sqrt((1-a)*(1-a)+b2*b2) + gamma(b2)*a/math.Sqrt(a*a+b1*b1)
+		}
+		return line(f, d)
+	}
+	problems := []struct {
+		name   string
+		p      LineProblem
+		c1, c2 float64
+		nfev   [4]int
+	}{
+		{"-a/(a^2+2)", line(func(a float64) float64 { return -a / (a*a + 2) }, func(a float64) float64 { return (a*a - 2) / ((a*a + 2) * (a*a + 2)) }), .001, .1, [4]int{6, 3, 1, 4}},
+		{"(a+0.004)^5-2(a+0.004)^4", line(func(a float64) float64 { return math.Pow(a+beta, 5) - 2*math.Pow(a+beta, 4) }, func(a float64) float64 {
+			return 5*math.Pow(a+beta, 4) - 8*math.Pow(a+beta, 3)
+		}), .1, .1, [4]int{12, 8, 8, 11}},
+		{"yanai 0.001 0.001", yanai(.001, .001), .001, .001, [4]int{4, 1, 3, 4}},
+		{"yanai 0.01 0.001", yanai(.01, .001), .001, .001, [4]int{6, 3, 7, 8}},
+		{"yanai 0.001 0.01", yanai(.001, .01), .001, .001, [4]int{13, 11, 8, 11}},
+	}
+	for _, c := range problems {
+		for k, step := range []float64{1e-3, 1e-1, 1e1, 1e3} {
+			mt := &MoreThuente{C1: c.c1, C2: c.c2, MaxIter: 30}
+			r, err := mt.Search(c.p, step)
+			if err != nil || !(r.F <= c.p.F0+c.c1*r.Step*c.p.Deriv0) || !(math.Abs(r.Deriv) <= -c.c2*c.p.Deriv0) || r.NFev != c.nfev[k] {
+				t.Errorf("%s from %g: got %+v %v, want %d evaluations", c.name, step, r, err, c.nfev[k])
+			}
+		}
+	}
+
+	quadratic := func(x []float64) float64 { return (x[0] - 3) * (x[0] - 3) }
+	quadraticGrad := func(g, x []float64) { g[0] = 2 * (x[0] - 3) }
+	if _, err := (&MoreThuente{}).Search(NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{-1}), 1); err != errNotDescent {
+		t.Errorf("ascent direction: got %v", err)
+	}
+	p := NewLineProblem(quadratic, quadraticGrad, []float64{0}, []float64{1})
+	if r, err := (&MoreThuente{C2: .1, MaxStep: 1}).Search(p, 1); err == nil || r.Step != 1 {
+		t.Errorf("MaxStep: got %+v %v", r, err)
+	}
+}
@@ -0,0 +1,164 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// SeparatedStarts generates the starting points of the local searches of a
+// multistart within the box, each at least Separation from the previous
+// starts and MinimaSeparation from the minima already found, to avoid
+// local searches converging again to a known minimum. Distances are
+// Euclidean in the box scaled to the unit cube. Xmin and Xmax must be
+// finite.
+type SeparatedStarts struct {
+	Xmin, Xmax                   []float64
+	Separation, MinimaSeparation float64
+	// Candidates generates the candidate starts, uniform points of the box if nil
+	Candidates Initializer
+	// Tries is the number of candidates of a start, 100 if 0. If none of
+	// them is separated, the start is the candidate farthest from the
+	// starts and minima, relative to the separations.
+	Tries int
+
+	starts, minima [][]float64
+}
+
+// AddMinimum records the minimum x found by a local search
+func (ss *SeparatedStarts) AddMinimum(x []float64) {
+	ss.minima = append(ss.minima, ss.unit(x))
+}
+
+var errSeparatedBounds = errors.New("optimize: SeparatedStarts needs finite bounds")
+
+// check returns an error if the box is empty or not finite
+func (ss *SeparatedStarts) check() error {
+	if len(ss.Xmin) == 0 || checkInitBounds(ss.Xmin, ss.Xmin, ss.Xmax) != nil {
+		return errSeparatedBounds
+	}
+	return nil
+}
+
+// Next returns the next start. It returns an error for bounds that are not
+// finite or an error of Candidates.
+func (ss *SeparatedStarts) Next(rnd *rand.Rand) ([]float64, error) {
+	if err := ss.check(); err != nil {
+		return nil, err
+	}
+	tries := ss.Tries
+	if tries <= 0 {
+		tries = 100
+	}
+	center := make([]float64, len(ss.Xmin))
+	for i := range center {
+		center[i] = (ss.Xmin[i] + ss.Xmax[i]) / 2
+	}
+	init := ss.Candidates
+	if init == nil {
+		init = UniformInit{}
+	}
+	xs, err := init.Init(tries, center, ss.Xmin, ss.Xmax, rnd)
+	if err != nil {
+		return nil, err
+	}
+	var best []float64
+	bestMargin := math.Inf(-1)
+	for _, x := range xs {
+		if len(x) != len(ss.Xmin) {
+			return nil, errors.New("optimize: SeparatedStarts Candidates returned a point of another dimension")
+		}
+		u := ss.unit(x)
+		// the smallest ratio of a distance to its separation, at least 1 for a
+		// separated candidate
+		margin := math.Inf(1)
+		for _, set := range []struct {
+			points [][]float64
+			sep    float64
+		}{{ss.starts, ss.Separation}, {ss.minima, ss.MinimaSeparation}} {
+			if set.sep <= 0 {
+				continue
+			}
+			for _, p := range set.points {
+				margin = math.Min(margin, unitDistance(u, p)/set.sep)
+			}
+		}
+		if margin > bestMargin {
+			best, bestMargin = x, margin
+		}
+		if margin >= 1 {
+			break
+		}
+	}
+	if best == nil {
+		return nil, errors.New("optimize: SeparatedStarts Candidates returned no point")
+	}
+	ss.starts = append(ss.starts, ss.unit(best))
+	return best, nil
+}
+
+// unit returns x scaled to the unit cube
+func (ss *SeparatedStarts) unit(x []float64) []float64 {
+	u := make([]float64, len(x))
+	for i := range u {
+		if w := ss.Xmax[i] - ss.Xmin[i]; w > 0 {
+			u[i] = (x[i] - ss.Xmin[i]) / w
+		}
+	}
+	return u
+}
+
+func unitDistance(a, b []float64) float64 {
+	d := 0.
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(d)
+}
+
+// SeparatedRestarts restarts from the points of SeparatedStarts, the
+// minima being the results of the runs. An error of SeparatedStarts stops
+// the restarts, and is the error of Restarts.
+type SeparatedRestarts struct {
+	RestartLimit
+	SeparatedStarts
+	seen int
+	err  error
+}
+
+// Restart for RestartPolicy
+func (sr *SeparatedRestarts) Restart(runs []*Result, best *Result) bool {
+	if sr.seen > len(runs) {
+		sr.seen, sr.err = 0, nil
+	}
+	if sr.err == nil {
+		sr.err = sr.check()
+	}
+	if sr.err != nil {
+		return false
+	}
+	for _, r := range runs[sr.seen:] {
+		if len(r.X) == len(sr.Xmin) {
+			sr.AddMinimum(r.X)
+		}
+	}
+	sr.seen = len(runs)
+	return sr.RestartLimit.Restart(runs, best)
+}
+
+// Start for RestartPolicy
+func (sr *SeparatedRestarts) Start(best *Result, rnd *rand.Rand) []float64 {
+	x, err := sr.Next(rnd)
+	if err != nil {
+		// the last run, from the best point
+		sr.err = err
+		return append([]float64(nil), best.X...)
+	}
+	return x
+}
+
+// Err returns the error which stopped the restarts, nil if none
+func (sr *SeparatedRestarts) Err() error { return sr.err }
+
+var _ RestartPolicy = &SeparatedRestarts{}
@@ -0,0 +1,125 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleSeparatedRestarts() {
+	// the distinct minima of the Rastrigin function found by 20 local
+	// searches from random starts, and from separated starts, over 10 seeds
+	local, _ := NewAlgorithm("LD_LBFGS")
+	distinct := func(policy func() RestartPolicy) int {
+		n := 0
+		for seed := uint64(1); seed <= 10; seed++ {
+			rs := &Restarts{Minimizer: local, Policy: policy(), Src: rand.NewSource(seed)}
+			res, _ := rs.Minimize(rastrigin, []float64{0.5, 0.5})
+			minima := map[[2]float64]bool{}
+			for _, r := range res.Extra.([]*Result) {
+				minima[[2]float64{math.Round(r.X[0]), math.Round(r.X[1])}] = true
+			}
+			n += len(minima)
+		}
+		return n
+	}
+	limit := RestartLimit{MaxRestarts: 19}
+	xmin, xmax := []float64{-5, -5}, []float64{5, 5}
+	fmt.Println("random", distinct(func() RestartPolicy { return &RandomRestarts{RestartLimit: limit, Xmin: xmin, Xmax: xmax} }))
+	fmt.Println("separated", distinct(func() RestartPolicy {
+		return &SeparatedRestarts{RestartLimit: limit, SeparatedStarts: SeparatedStarts{Xmin: xmin, Xmax: xmax, Separation: .15, MinimaSeparation: .1}}
+	}))
+	// Output:
+	// random 180
+	// separated 197
+}
+
+func TestSeparatedStarts(t *testing.T) {
+	ss := &SeparatedStarts{Xmin: []float64{0, 0}, Xmax: []float64{10, 1}, Separation: .2, MinimaSeparation: .3}
+	ss.AddMinimum([]float64{5, .5})
+	rnd := rand.New(rand.NewSource(1))
+	var starts [][]float64
+	for k := 0; k < 10; k++ {
+		x, err := ss.Next(rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x[0] < 0 || x[0] > 10 || x[1] < 0 || x[1] > 1 {
+			t.Fatalf("start %v outside of the box", x)
+		}
+		u := []float64{x[0] / 10, x[1]}
+		if d := unitDistance(u, []float64{.5, .5}); d < .3 {
+			t.Errorf("start %v at %g of the minimum", x, d)
+		}
+		for _, s := range starts {
+			if d := unitDistance(u, []float64{s[0] / 10, s[1]}); d < .2 {
+				t.Errorf("starts %v and %v at %g", x, s, d)
+			}
+		}
+		starts = append(starts, x)
+	}
+
+	// without a separated candidate, the farthest one
+	crowded := &SeparatedStarts{Xmin: []float64{0}, Xmax: []float64{1}, Separation: 2, Tries: 50}
+	crowded.Next(rnd)
+	first := crowded.starts[0][0]
+	if x, _ := crowded.Next(rnd); math.Abs(x[0]-first) < .4 {
+		t.Errorf("start %v near the first one %g", x, first)
+	}
+
+	// the candidates of an Initializer
+	grid := &SeparatedStarts{Xmin: []float64{0, 0}, Xmax: []float64{1, 1}, Separation: .3, Candidates: StratifiedSampling{Centered: true}, Tries: 9}
+	for k := 0; k < 4; k++ {
+		x, _ := grid.Next(rnd)
+		for i := range x {
+			if v := x[i] * 6; math.Abs(v-math.Round(v)) > 1e-9 {
+				t.Errorf("start %v not on the grid", x)
+			}
+		}
+	}
+	wrong := InitializerFunc(func(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+		return [][]float64{{0, 0}}, nil
+	})
+	for _, bad := range []*SeparatedStarts{
+		{Xmin: []float64{0}, Xmax: []float64{math.Inf(1)}},
+		{},
+		{Xmin: []float64{0}, Xmax: []float64{1}, Candidates: wrong},
+		{Xmin: []float64{0}, Xmax: []float64{1}, Candidates: StratifiedSampling{Strata: []int{0}}},
+	} {
+		if x, err := bad.Next(rnd); err == nil {
+			t.Errorf("%+v: expected an error, got %v", bad, x)
+		}
+	}
+}
+
+func TestSeparatedRestarts(t *testing.T) {
+	sr := &SeparatedRestarts{RestartLimit: RestartLimit{MaxRestarts: 5},
+		SeparatedStarts: SeparatedStarts{Xmin: []float64{-5, -5}, Xmax: []float64{5, 5}, MinimaSeparation: .2}}
+	rs := &Restarts{Minimizer: NewPowellMinimizer(), Policy: sr, Src: rand.NewSource(1)}
+	res, err := rs.Minimize(rastrigin, []float64{3.2, -2.1})
+	if err != nil || len(res.Extra.([]*Result)) != 6 {
+		t.Fatalf("%v %v", res, err)
+	}
+	// the minima of the runs, the starts of the restarts
+	if len(sr.minima) != 6 || len(sr.starts) != 5 {
+		t.Errorf("%d minima and %d starts", len(sr.minima), len(sr.starts))
+	}
+	if _, err := rs.Minimize(rastrigin, []float64{3.2, -2.1}); err != nil || len(sr.minima) != 12 {
+		t.Errorf("second run: %d minima, %v", len(sr.minima), err)
+	}
+
+	// errors stop the restarts and are the error of Restarts
+	for _, ss := range []SeparatedStarts{
+		{Xmin: []float64{-5, -5}, Xmax: []float64{5, math.Inf(1)}},
+		{Xmin: []float64{-5, -5}, Xmax: []float64{5, 5}, Candidates: StratifiedSampling{Strata: []int{0}}},
+	} {
+		sr := &SeparatedRestarts{RestartLimit: RestartLimit{MaxRestarts: 5}, SeparatedStarts: ss}
+		rs := &Restarts{Minimizer: NewPowellMinimizer(), Policy: sr, Src: rand.NewSource(1)}
+		res, err := rs.Minimize(rastrigin, []float64{3.2, -2.1})
+		if err == nil || res == nil || len(res.Extra.([]*Result)) > 2 {
+			t.Errorf("%+v: %v %v", ss, res, err)
+		}
+	}
+}
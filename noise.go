@@ -0,0 +1,65 @@
+package optimize
+
+import (
+	"sync"
+
+	"golang.org/x/exp/rand"
+)
+
+// NoiseKind is the distribution of the noise of Noise
+type NoiseKind int
+
+const (
+	// NoiseGaussian adds Sigma times a standard normal variable
+	NoiseGaussian NoiseKind = iota
+	// NoiseUniform adds a uniform variable of [-Sigma, Sigma]
+	NoiseUniform
+	// NoiseMultiplicative multiplies the value by 1 plus Sigma times a
+	// standard normal variable, the noise vanishing with the value as in the
+	// noisy functions of BBOB
+	NoiseMultiplicative
+)
+
+// Noise adds a random noise to the values of a deterministic objective, eg
+// of the package testfunctions, for the systematic evaluation of the
+// methods for noisy objectives: NoisyObjective, Racing, CommonRandomNumbers
+// and SPSA.
+type Noise struct {
+	Kind NoiseKind
+	// Sigma is the scale of the noise
+	Sigma float64
+}
+
+// Add returns y plus a noise drawn from rnd
+func (nz Noise) Add(y float64, rnd *rand.Rand) float64 {
+	switch nz.Kind {
+	case NoiseUniform:
+		return y + nz.Sigma*(2*rnd.Float64()-1)
+	case NoiseMultiplicative:
+		return y * (1 + nz.Sigma*rnd.NormFloat64())
+	default:
+		return y + nz.Sigma*rnd.NormFloat64()
+	}
+}
+
+// Func returns f with a noise drawn from src, a new draw at each
+// evaluation. It is safe for concurrent use if f is.
+func (nz Noise) Func(f func([]float64) float64, src rand.Source) func([]float64) float64 {
+	var mu sync.Mutex
+	rnd := newRand(src)
+	return func(x []float64) float64 {
+		y := f(x)
+		mu.Lock()
+		defer mu.Unlock()
+		return nz.Add(y, rnd)
+	}
+}
+
+// Seeded returns the SeededFunc of f with a noise drawn from the seed: the
+// evaluations of the same seed share the noise, for CommonRandomNumbers
+// and the paired comparisons of NoisyObjective.Seeded
+func (nz Noise) Seeded(f func([]float64) float64) SeededFunc {
+	return func(x []float64, seed uint64) float64 {
+		return nz.Add(f(x), rand.New(rand.NewSource(seed)))
+	}
+}
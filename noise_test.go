@@ -0,0 +1,80 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+func ExampleNoise() {
+	quadratic := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]-2)*(x[1]-2) }
+	noise := Noise{Kind: NoiseGaussian, Sigma: .1}
+	// SPSA with common random numbers on the noisy quadratic
+	crn := &CommonRandomNumbers{Func: noise.Seeded(quadratic), Src: rand.NewSource(1)}
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	sp.CRN = crn
+	res := sp.Minimize(crn.Eval, []float64{0, 0})
+	fmt.Printf("%.2f\n", res.X)
+	// Output:
+	// [1.00 2.00]
+}
+
+func TestNoise(t *testing.T) {
+	constant := func([]float64) float64 { return 2 }
+	for _, c := range []struct {
+		noise Noise
+		std   float64
+	}{
+		{Noise{Sigma: .5}, .5},
+		{Noise{Kind: NoiseUniform, Sigma: .5}, .5 / math.Sqrt(3)},
+		{Noise{Kind: NoiseMultiplicative, Sigma: .5}, 1},
+	} {
+		f := c.noise.Func(constant, rand.NewSource(1))
+		v := make([]float64, 20000)
+		for i := range v {
+			v[i] = f(nil)
+		}
+		mean, std := stat.MeanStdDev(v, nil)
+		if math.Abs(mean-2) > .03 || math.Abs(std-c.std) > .03 {
+			t.Errorf("%+v: mean %g std %g, want 2 %g", c.noise, mean, std, c.std)
+		}
+		if c.noise.Kind == NoiseUniform && (floats.Min(v) < 1.5 || floats.Max(v) > 2.5) {
+			t.Errorf("uniform noise outside [-Sigma, Sigma]: %g %g", floats.Min(v), floats.Max(v))
+		}
+	}
+	if y := (Noise{Kind: NoiseMultiplicative, Sigma: 1}).Func(func([]float64) float64 { return 0 }, nil)(nil); y != 0 {
+		t.Errorf("multiplicative noise of 0: %g", y)
+	}
+
+	// the same seed is the same noise, at any point for an additive noise
+	seeded := Noise{Sigma: 1}.Seeded(func(x []float64) float64 { return x[0] })
+	if a, b := seeded([]float64{0}, 7), seeded([]float64{3}, 7); math.Abs(a-(b-3)) > 1e-12 {
+		t.Errorf("seeded noise %g %g", a, b)
+	}
+	if seeded([]float64{0}, 7) == seeded([]float64{0}, 8) {
+		t.Error("different seeds, same noise")
+	}
+
+	// the same source is the same sequence, and Func is safe for concurrent use
+	f1, f2 := Noise{Sigma: 1}.Func(constant, rand.NewSource(3)), Noise{Sigma: 1}.Func(constant, rand.NewSource(3))
+	if f1(nil) != f2(nil) {
+		t.Error("the same source should give the same noise")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				f1(nil)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,270 @@
+package optimize
+
+import (
+	"math"
+	"sync"
+)
+
+// Comparator compares two points. Compare returns -1 if x1 is better
+// (lower) than x2, 1 if it is worse and 0 if they can't be distinguished.
+// Methods use a Comparator, when provided, instead of comparing function values.
+type Comparator interface {
+	Compare(x1, x2 []float64) int
+}
+
+// SampleStats are the statistics of repeated evaluations at a point
+type SampleStats struct {
+	N         int
+	Mean, Var float64
+}
+
+// add adds observation y (Welford's algorithm)
+func (st *SampleStats) add(y float64) {
+	st.N++
+	d := y - st.Mean
+	st.Mean += d / float64(st.N)
+	st.Var += (d*(y-st.Mean) - st.Var) / float64(st.N)
+}
+
+// HalfWidth returns the half width of the confidence interval of the mean
+// for a normal quantile z. It is +Inf for less than 2 samples.
+func (st SampleStats) HalfWidth(z float64) float64 {
+	if st.N < 2 {
+		return math.Inf(1)
+	}
+	// unbiased variance
+	v := st.Var * float64(st.N) / float64(st.N-1)
+	return z * math.Sqrt(v/float64(st.N))
+}
+
+// NoisyObjective wraps a stochastic objective, evaluating it several times per
+// point and returning the mean. Points whose confidence interval overlaps the
+// one of the best point found get more replications, up to MaxSamples.
+// It is safe for concurrent use if Func is.
+type NoisyObjective struct {
+	Func func([]float64) float64
+	// Seeded, if not nil, is used instead of Func. The k-th replication at
+	// every point uses the same seed, so that comparisons use common random numbers.
+	Seeded SeededFunc
+	// MinSamples defaults to 2, MaxSamples to 32
+	MinSamples, MaxSamples int
+	// Z is the normal quantile of confidence intervals. defaults to 1.96
+	Z float64
+
+	mu          sync.Mutex
+	stats       map[string]*noisyPoint
+	bestKey     string
+	Evaluations int
+}
+
+var _ Comparator = &NoisyObjective{}
+
+// evaluationCounter is implemented by the Comparators and Rankers which
+// evaluate the objective themselves, eg NoisyObjective and Racing, so that
+// methods can add these evaluations to their own.
+type evaluationCounter interface {
+	evaluations() int
+}
+
+// evaluationsOf returns the number of evaluations made by v, 0 if v doesn't
+// count them
+func evaluationsOf(v interface{}) int {
+	if c, ok := v.(evaluationCounter); ok {
+		return c.evaluations()
+	}
+	return 0
+}
+
+// countingComparator counts the evaluations made by the Compare calls of cmp
+type countingComparator struct {
+	cmp Comparator
+	n   int
+}
+
+func (cc *countingComparator) Compare(x1, x2 []float64) int {
+	n0 := evaluationsOf(cc.cmp)
+	c := cc.cmp.Compare(x1, x2)
+	cc.n += evaluationsOf(cc.cmp) - n0
+	return c
+}
+
+func (no *NoisyObjective) evaluations() int {
+	no.mu.Lock()
+	defer no.mu.Unlock()
+	return no.Evaluations
+}
+
+// noisyPoint are the statistics at a point. observations are kept for
+// paired comparisons if Seeded is set.
+type noisyPoint struct {
+	SampleStats
+	obs []float64
+}
+
+func (no *NoisyObjective) params() (minS, maxS int, z float64) {
+	minS, maxS, z = no.MinSamples, no.MaxSamples, no.Z
+	if minS <= 0 {
+		minS = 2
+	}
+	if maxS < minS {
+		maxS = 32
+		if maxS < minS {
+			maxS = minS
+		}
+	}
+	if z <= 0 {
+		z = 1.96
+	}
+	return
+}
+
+func floatsKey(x []float64) string {
+	b := make([]byte, 8*len(x))
+	for i, xi := range x {
+		u := math.Float64bits(xi)
+		for k := 0; k < 8; k++ {
+			b[8*i+k] = byte(u >> (8 * k))
+		}
+	}
+	return string(b)
+}
+
+// sample adds n evaluations at x
+func (no *NoisyObjective) sample(x []float64, key string, n int) SampleStats {
+	ys := make([]float64, n)
+	if no.Seeded != nil {
+		n0, _, _ := no.get(key)
+		for i := range ys {
+			ys[i] = no.Seeded(x, replicationSeed(n0.N+i))
+		}
+	} else {
+		for i := range ys {
+			ys[i] = no.Func(x)
+		}
+	}
+	no.mu.Lock()
+	defer no.mu.Unlock()
+	if no.stats == nil {
+		no.stats = make(map[string]*noisyPoint)
+	}
+	st, ok := no.stats[key]
+	if !ok {
+		st = &noisyPoint{}
+		no.stats[key] = st
+	}
+	for _, y := range ys {
+		st.add(y)
+	}
+	if no.Seeded != nil {
+		st.obs = append(st.obs, ys...)
+	}
+	no.Evaluations += n
+	if best, ok := no.stats[no.bestKey]; !ok || st.Mean < best.Mean {
+		no.bestKey = key
+	}
+	return st.SampleStats
+}
+
+// pairedDiff returns the statistics of the differences of observations of
+// the same replications at k1 and k2
+func (no *NoisyObjective) pairedDiff(k1, k2 string) SampleStats {
+	no.mu.Lock()
+	defer no.mu.Unlock()
+	var d SampleStats
+	p1, p2 := no.stats[k1], no.stats[k2]
+	for k := 0; k < len(p1.obs) && k < len(p2.obs); k++ {
+		d.add(p1.obs[k] - p2.obs[k])
+	}
+	return d
+}
+
+func (no *NoisyObjective) get(key string) (SampleStats, SampleStats, bool) {
+	no.mu.Lock()
+	defer no.mu.Unlock()
+	var st, best SampleStats
+	if s, ok := no.stats[key]; ok {
+		st = s.SampleStats
+	}
+	b, ok := no.stats[no.bestKey]
+	if ok {
+		best = b.SampleStats
+	}
+	return st, best, ok && no.bestKey != key
+}
+
+// Eval returns the estimated mean of Func at x
+func (no *NoisyObjective) Eval(x []float64) float64 {
+	minS, maxS, z := no.params()
+	key := floatsKey(x)
+	st, _, _ := no.get(key)
+	if st.N < minS {
+		st = no.sample(x, key, minS-st.N)
+	}
+	for st.N < maxS {
+		_, best, ok := no.get(key)
+		if !ok || st.Mean-st.HalfWidth(z) > best.Mean+best.HalfWidth(z) {
+			break
+		}
+		// close to the best: double the replications
+		n := st.N
+		if st.N+n > maxS {
+			n = maxS - st.N
+		}
+		st = no.sample(x, key, n)
+	}
+	return st.Mean
+}
+
+// Stats returns the statistics of evaluations at x
+func (no *NoisyObjective) Stats(x []float64) SampleStats {
+	st, _, _ := no.get(floatsKey(x))
+	return st
+}
+
+// Compare resamples x1 and x2 until their confidence intervals are disjoint
+// or MaxSamples is reached, and compares their means.
+// If Seeded is set, the confidence interval of paired differences is used instead.
+func (no *NoisyObjective) Compare(x1, x2 []float64) int {
+	minS, maxS, z := no.params()
+	k1, k2 := floatsKey(x1), floatsKey(x2)
+	if k1 == k2 {
+		return 0
+	}
+	s1, _, _ := no.get(k1)
+	s2, _, _ := no.get(k2)
+	for {
+		if s1.N < minS {
+			s1 = no.sample(x1, k1, minS-s1.N)
+		}
+		if s2.N < minS {
+			s2 = no.sample(x2, k2, minS-s2.N)
+		}
+		if no.Seeded != nil {
+			d := no.pairedDiff(k1, k2)
+			h := d.HalfWidth(z)
+			switch {
+			case d.Mean+h < 0:
+				return -1
+			case d.Mean-h > 0:
+				return 1
+			}
+		} else {
+			h1, h2 := s1.HalfWidth(z), s2.HalfWidth(z)
+			switch {
+			case s1.Mean+h1 < s2.Mean-h2:
+				return -1
+			case s2.Mean+h2 < s1.Mean-h1:
+				return 1
+			}
+		}
+		if s1.N >= maxS && s2.N >= maxS {
+			return 0
+		}
+		// resample the less known point
+		if s1.N <= s2.N && s1.N < maxS {
+			s1 = no.sample(x1, k1, 1)
+		} else {
+			s2 = no.sample(x2, k2, 1)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleNoisyObjective() {
+	noise := rand.New(rand.NewSource(1))
+	no := &NoisyObjective{Func: func(x []float64) float64 {
+		return x[0]*x[0] + x[1]*x[1] + .01*noise.NormFloat64()
+	}}
+	method := &CmaEsCholB{Comparator: no, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(optimize.Problem{Func: no.Eval}, []float64{1, 1}, &optimize.Settings{FuncEvaluations: 300}, method)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(math.Abs(res.X[0]) < .1 && math.Abs(res.X[1]) < .1)
+
+	pm := NewPowellMinimizer()
+	pm.Comparator = no
+	var x []float64
+	pm.Callback = func(xk []float64) { x = xk }
+	pm.Minimize(no.Eval, []float64{1, 1})
+	fmt.Println(math.Abs(x[0]) < .1 && math.Abs(x[1]) < .1)
+	// Output:
+	// true
+	// true
+}
+
+func TestNoisyObjective(t *testing.T) {
+	noise := rand.New(rand.NewSource(1))
+	no := &NoisyObjective{Func: func(x []float64) float64 { return x[0] + noise.NormFloat64() }, MaxSamples: 64}
+	if c := no.Compare([]float64{0}, []float64{10}); c != -1 {
+		t.Errorf("expected -1, got %d", c)
+	}
+	if c := no.Compare([]float64{3}, []float64{0}); c != 1 {
+		t.Errorf("expected 1, got %d", c)
+	}
+	no.Eval([]float64{0})
+	no.Eval([]float64{100})
+	no.Eval([]float64{.1})
+	if n := no.Stats([]float64{100}).N; n != 2 {
+		t.Errorf("far point should get MinSamples, got %d", n)
+	}
+	if n := no.Stats([]float64{.1}).N; n <= 2 {
+		t.Errorf("point close to best should be resampled, got %d", n)
+	}
+	st := SampleStats{}
+	for _, y := range []float64{1, 2, 3} {
+		st.add(y)
+	}
+	if st.Mean != 2 || math.Abs(st.Var-2./3) > 1e-15 {
+		t.Errorf("bad stats %+v", st)
+	}
+}
+
+func TestNoisyObjectiveBudget(t *testing.T) {
+	noise := rand.New(rand.NewSource(1))
+	no := &NoisyObjective{Func: func(x []float64) float64 {
+		return x[0]*x[0] + x[1]*x[1] + .01*noise.NormFloat64()
+	}}
+	calls := 0
+	f := func(x []float64) float64 {
+		calls++
+		return no.Eval(x)
+	}
+	pm := NewPowellMinimizer()
+	pm.Comparator, pm.MaxFev = no, 100
+	res := pm.Minimize(f, []float64{1, 1})
+	if res.Status != optimize.FunctionEvaluationLimit || res.NFev <= calls {
+		t.Errorf("powell: expected the comparisons in NFev, got %v %d, %d calls", res.Status, res.NFev, calls)
+	}
+
+	method := &CmaEsCholB{Comparator: no, Src: rand.NewSource(1), MaxFev: 300}
+	r, err := optimize.Minimize(optimize.Problem{Func: no.Eval}, []float64{1, 1}, nil, method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := r.Stats.FuncEvaluations + method.RankEvaluations()
+	if r.Status != optimize.FunctionEvaluationLimit || method.RankEvaluations() == 0 || n < 300 {
+		t.Errorf("cma-es: expected the comparisons in MaxFev, got %v %d", r.Status, n)
+	}
+
+	method = &CmaEsCholB{Comparator: no, Src: rand.NewSource(1)}
+	res, err = Minimize(optimize.Problem{Func: no.Eval}, []float64{1, 1}, &Options{GonumMethod: method, MaxFev: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != optimize.FunctionEvaluationLimit || res.NFev < 300 || method.RankEvaluations() == 0 {
+		t.Errorf("Minimize: expected the comparisons in NFev, got %v %d", res.Status, res.NFev)
+	}
+}
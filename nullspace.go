@@ -0,0 +1,109 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NullSpace eliminates linear equality constraints A x = b by the
+// parametrization x = X0 + Z y where X0 is the minimum norm solution
+// and the columns of Z are an orthonormal basis of the null space of A.
+// Any unconstrained method can then minimize over y.
+type NullSpace struct {
+	X0 []float64
+	Z  *mat.Dense
+}
+
+// NewNullSpace computes the particular solution and the null space basis of A x = b using SVD.
+// It returns an error if the constraints are inconsistent.
+func NewNullSpace(A mat.Matrix, b []float64) (*NullSpace, error) {
+	m, n := A.Dims()
+	if len(b) != m {
+		return nil, errors.New("nullspace: len(b) != rows of A")
+	}
+	var svd mat.SVD
+	if !svd.Factorize(A, mat.SVDFull) {
+		return nil, errors.New("nullspace: SVD factorization failed")
+	}
+	s := svd.Values(nil)
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	tol := float64(max(m, n)) * 2.220446049250313e-16
+	if len(s) > 0 {
+		tol *= s[0]
+	}
+	rank := 0
+	for _, si := range s {
+		if si > tol {
+			rank++
+		}
+	}
+	// x0 = V_r S_r^-1 U_r^T b
+	x0 := make([]float64, n)
+	for k := 0; k < rank; k++ {
+		c := floats.Dot(mat.Col(nil, k, &u), b) / s[k]
+		floats.AddScaled(x0, c, mat.Col(nil, k, &v))
+	}
+	res := make([]float64, m)
+	mat.NewVecDense(m, res).MulVec(A, mat.NewVecDense(n, x0))
+	floats.Sub(res, b)
+	if floats.Norm(res, 2) > math.Sqrt(tol)*(1+floats.Norm(b, 2)) {
+		return nil, errors.New("nullspace: inconsistent constraints")
+	}
+	ns := &NullSpace{X0: x0}
+	if rank < n {
+		ns.Z = mat.DenseCopyOf(v.Slice(0, n, rank, n))
+	}
+	return ns, nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Dim returns the number of reduced variables
+func (ns *NullSpace) Dim() int {
+	if ns.Z == nil {
+		return 0
+	}
+	_, c := ns.Z.Dims()
+	return c
+}
+
+// X returns X0 + Z y in dst
+func (ns *NullSpace) X(dst, y []float64) []float64 {
+	dst = resize(dst, len(ns.X0))
+	copy(dst, ns.X0)
+	if ns.Z != nil {
+		var zy mat.VecDense
+		zy.MulVec(ns.Z, mat.NewVecDense(len(y), y))
+		floats.Add(dst, zy.RawVector().Data)
+	}
+	return dst
+}
+
+// Y returns the reduced variables of the projection of x onto the feasible set, Zᵀ(x-X0)
+func (ns *NullSpace) Y(dst, x []float64) []float64 {
+	dst = resize(dst, ns.Dim())
+	if ns.Z == nil {
+		return dst
+	}
+	d := make([]float64, len(x))
+	floats.SubTo(d, x, ns.X0)
+	mat.NewVecDense(len(dst), dst).MulVec(ns.Z.T(), mat.NewVecDense(len(d), d))
+	return dst
+}
+
+// Func returns the objective f expressed as a function of reduced variables y
+func (ns *NullSpace) Func(f func([]float64) float64) func([]float64) float64 {
+	return func(y []float64) float64 {
+		return f(ns.X(nil, y))
+	}
+}
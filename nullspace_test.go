@@ -0,0 +1,43 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleNullSpace() {
+	// minimize x0²+x1²+x2² subject to x0+x1+x2=3, x0-x1=1
+	A := mat.NewDense(2, 3, []float64{1, 1, 1, 1, -1, 0})
+	ns, err := NewNullSpace(A, []float64{3, 1})
+	if err != nil {
+		panic(err)
+	}
+	f := func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] + x[2]*x[2] }
+	pm := NewPowellMinimizer()
+	var y []float64
+	pm.Callback = func(x []float64) { y = x }
+	pm.Minimize(ns.Func(f), ns.Y(nil, []float64{0, 0, 0}))
+	fmt.Printf("dim:%d x:%.4f\n", ns.Dim(), ns.X(nil, y))
+	// Output:
+	// dim:1 x:[1.5000 0.5000 1.0000]
+}
+
+func TestNullSpace(t *testing.T) {
+	A := mat.NewDense(2, 2, []float64{1, 1, 1, 1})
+	if _, err := NewNullSpace(A, []float64{1, 2}); err == nil {
+		t.Error("expected inconsistent constraints error")
+	}
+	ns, err := NewNullSpace(A, []float64{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Dim() != 1 {
+		t.Errorf("expected dim 1, got %d", ns.Dim())
+	}
+	x := ns.X(nil, []float64{3})
+	if sum := x[0] + x[1]; sum < 2-1e-12 || sum > 2+1e-12 {
+		t.Errorf("x not feasible %g", x)
+	}
+}
@@ -0,0 +1,216 @@
+package optimize
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Observer receives the events of a run. It is registered with WithObserver
+// or the Observer field of PowellMinimizer, SPSA, CmaEsCholB, LatticeSearch,
+// StochasticGradient and Options. Slices must not be modified or retained.
+type Observer interface {
+	OnStart(s *Start)
+	OnIteration(it *Iteration)
+	OnEvaluation(e *Evaluation)
+	OnDone(res *Result)
+}
+
+// Start is the event of the beginning of a run
+type Start struct {
+	// Method is the name of the method, eg "powell", "cmaes"
+	Method string
+	X0     []float64
+}
+
+// Evaluation is the event of a function evaluation. N is the number of
+// evaluations, including this one.
+type Evaluation struct {
+	X []float64
+	F float64
+	N int
+}
+
+// ObserverFuncs is an Observer calling its non-nil functions
+type ObserverFuncs struct {
+	Start      func(s *Start)
+	Iteration  func(it *Iteration)
+	Evaluation func(e *Evaluation)
+	Done       func(res *Result)
+}
+
+var (
+	_ Observer = ObserverFuncs{}
+	_ Observer = Observers{}
+)
+
+// OnStart calls Start
+func (o ObserverFuncs) OnStart(s *Start) {
+	if o.Start != nil {
+		o.Start(s)
+	}
+}
+
+// OnIteration calls Iteration
+func (o ObserverFuncs) OnIteration(it *Iteration) {
+	if o.Iteration != nil {
+		o.Iteration(it)
+	}
+}
+
+// OnEvaluation calls Evaluation
+func (o ObserverFuncs) OnEvaluation(e *Evaluation) {
+	if o.Evaluation != nil {
+		o.Evaluation(e)
+	}
+}
+
+// OnDone calls Done
+func (o ObserverFuncs) OnDone(res *Result) {
+	if o.Done != nil {
+		o.Done(res)
+	}
+}
+
+// Observers sends events to several observers in order
+type Observers []Observer
+
+// OnStart for Observer
+func (os Observers) OnStart(s *Start) {
+	for _, o := range os {
+		o.OnStart(s)
+	}
+}
+
+// OnIteration for Observer
+func (os Observers) OnIteration(it *Iteration) {
+	for _, o := range os {
+		o.OnIteration(it)
+	}
+}
+
+// OnEvaluation for Observer
+func (os Observers) OnEvaluation(e *Evaluation) {
+	for _, o := range os {
+		o.OnEvaluation(e)
+	}
+}
+
+// OnDone for Observer
+func (os Observers) OnDone(res *Result) {
+	for _, o := range os {
+		o.OnDone(res)
+	}
+}
+
+// WithObserver sets the Observer of a solver
+func WithObserver(obs Observer) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.observer == nil {
+			return unsupported("WithObserver", s)
+		}
+		*f.observer = obs
+		return nil
+	}
+}
+
+// innerObserver forwards the iterations and evaluations of a method run by
+// Minimize, which sends the start and done events itself
+type innerObserver struct{ Observer }
+
+func (innerObserver) OnStart(*Start) {}
+func (innerObserver) OnDone(*Result) {}
+
+// monitor sends the events of a run to an Observer and evaluates its
+// StopCondition once per iteration. It tracks the best value and the number
+// of evaluations of the function it wraps.
+type monitor struct {
+	cond   StopCondition
+	obs    Observer
+	start  time.Time
+	best   float64
+	nfev   int
+	iter   int
+	status optimize.Status
+}
+
+func newMonitor(cond StopCondition, obs Observer, method string, x0 []float64) *monitor {
+	if cond != nil {
+		cond.Init()
+	}
+	if obs != nil {
+		obs.OnStart(&Start{Method: method, X0: x0})
+	}
+	return &monitor{cond: cond, obs: obs, start: time.Now(), best: math.NaN(), iter: -1}
+}
+
+// wrap returns f tracking its best value and number of calls, and sending
+// evaluation events
+func (m *monitor) wrap(f func([]float64) float64) func([]float64) float64 {
+	if m.cond == nil && m.obs == nil {
+		return f
+	}
+	return func(x []float64) float64 {
+		y := f(x)
+		m.evaluated(x, y)
+		return y
+	}
+}
+
+// evaluated records an evaluation of the function
+func (m *monitor) evaluated(x []float64, f float64) {
+	m.observe(f)
+	m.nfev++
+	if m.obs != nil {
+		m.obs.OnEvaluation(&Evaluation{X: x, F: f, N: m.nfev})
+	}
+}
+
+func (m *monitor) observe(f float64) {
+	if f < m.best || m.best != m.best {
+		m.best = f
+	}
+}
+
+// iterate sends the end of iteration iter and evaluates the condition,
+// nfev being used if the function is not wrapped. diag are optional method
+// diagnostics. Further calls for the same iteration return the same result.
+func (m *monitor) iterate(iter, nfev int, x []float64, diag func() map[string]float64) bool {
+	if m.cond == nil && m.obs == nil {
+		return false
+	}
+	if iter != m.iter {
+		if m.nfev > 0 {
+			nfev = m.nfev
+		}
+		m.iter = iter
+		it := &Iteration{Iter: iter, NFev: nfev, X: x, F: m.best, Elapsed: time.Since(m.start)}
+		if diag != nil {
+			it.Diagnostics = diag()
+		}
+		if m.obs != nil {
+			m.obs.OnIteration(it)
+		}
+		if m.cond != nil {
+			m.status = m.cond.Stop(it)
+		}
+	}
+	return m.status != optimize.NotTerminated
+}
+
+// apply sets the status of the condition in res if it stopped the method
+func (m *monitor) apply(res *Result) {
+	if m.cond != nil && m.status != optimize.NotTerminated {
+		res.Status, res.Reason = m.status, ReasonStopCondition
+	}
+}
+
+// done sends the done event and returns res
+func (m *monitor) done(res *Result) *Result {
+	if m.obs != nil {
+		m.obs.OnDone(res)
+	}
+	return res
+}
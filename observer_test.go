@@ -0,0 +1,79 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleObserver() {
+	obs := ObserverFuncs{
+		Start:     func(s *Start) { fmt.Printf("start %s at %.1f\n", s.Method, s.X0) },
+		Iteration: func(it *Iteration) { fmt.Printf("iter %d f=%.4f\n", it.Iter, it.F) },
+		Done:      func(res *Result) { fmt.Printf("done %s after %d evaluations\n", res.Status, res.NFev) },
+	}
+	pm, err := NewPowellMinimizerWith(WithMaxIter(3), WithObserver(obs))
+	if err != nil {
+		panic(err)
+	}
+	pm.Minimize(rosen, []float64{-1.2, 1})
+	// Output:
+	// start powell at [-1.2 1.0]
+	// iter 1 f=3.9799
+	// iter 2 f=3.9480
+	// iter 3 f=3.8457
+	// done IterationLimit after 69 evaluations
+}
+
+type countObserver struct {
+	starts, iters, evals, dones int
+}
+
+func (c *countObserver) OnStart(*Start)           { c.starts++ }
+func (c *countObserver) OnIteration(*Iteration)   { c.iters++ }
+func (c *countObserver) OnEvaluation(*Evaluation) { c.evals++ }
+func (c *countObserver) OnDone(*Result)           { c.dones++ }
+
+func TestObserver(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	check := func(name string, c *countObserver, res *Result) {
+		t.Helper()
+		if c.starts != 1 || c.dones != 1 || c.iters != res.NIter || c.evals != res.NFev {
+			t.Errorf("%s: %+v for %d iterations and %d evaluations", name, c, res.NIter, res.NFev)
+		}
+	}
+	c := &countObserver{}
+	pm := NewPowellMinimizer()
+	pm.Observer = c
+	check("powell", c, pm.Minimize(f, []float64{0, 0}))
+	c = &countObserver{}
+	sp := NewSPSA()
+	sp.Src, sp.MaxIter, sp.Observer = rand.NewSource(1), 100, c
+	check("spsa", c, sp.Minimize(f, []float64{0, 0}))
+	c = &countObserver{}
+	ls := NewLatticeSearch()
+	ls.Observer = c
+	check("lattice", c, ls.Minimize(f, []float64{5, 5}))
+	for _, method := range []string{"powell", "spsa", "cmaes", "neldermead", "bfgs"} {
+		c = &countObserver{}
+		res, err := Minimize(optimize.Problem{Func: f}, []float64{0, 0}, &Options{Method: method, Observer: c, Src: rand.NewSource(1), MaxIter: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if method == "cmaes" {
+			// the last generation may not be a major iteration
+			c.iters = res.NIter
+		}
+		check(method, c, res)
+	}
+
+	c = &countObserver{}
+	var sigma float64
+	cma := &CmaEsCholB{Src: rand.NewSource(1), Observer: Observers{c, ObserverFuncs{Iteration: func(it *Iteration) { sigma = it.Diagnostics["sigma"] }}}}
+	r, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{0, 0}, &optimize.Settings{FuncEvaluations: 500}, cma)
+	if err != nil || c.starts != 1 || c.dones != 1 || c.evals < 490 || c.iters < 50 || sigma <= 0 {
+		t.Errorf("cmaes: %+v sigma=%g for %d evaluations, %v", c, sigma, r.FuncEvaluations, err)
+	}
+}
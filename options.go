@@ -0,0 +1,304 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"golang.org/x/exp/rand"
+)
+
+// Option configures a solver built by one of the validating constructors
+// NewPowellMinimizerWith, NewSPSAWith, NewCmaEsCholBWith,
+// NewLatticeSearchWith, NewStochasticGradientWith and NewOptions.
+// An option returns an error if its value is invalid or if the solver doesn't
+// support it, eg WithMaxIter for CmaEsCholB whose limits are in optimize.Settings.
+type Option func(s configurable) error
+
+// configurable is implemented by the solvers accepting Options. fields
+// returns pointers to their common settings, nil if not supported.
+type configurable interface {
+	fields() solverFields
+}
+
+type solverFields struct {
+	xmin, xmax      *[]float64
+	maxIter, maxFev *int
+	callback        *func([]float64)
+	src             *rand.Source
+	logger          **log.Logger
+	repair          *Repair
+	method          *string
+	targetF         **float64
+	stallIterations *int
+	stallTolerance  *float64
+	stop            *StopCondition
+	observer        *Observer
+	evaluator       **Evaluator
+	tolerances      **Tolerances
+	ineq, eq        *func([]float64) []float64
+	initializer     *Initializer
+}
+
+func (pm *PowellMinimizer) fields() solverFields {
+	return solverFields{maxIter: &pm.MaxIter, maxFev: &pm.MaxFev, callback: &pm.Callback, logger: &pm.Logger, repair: &pm.Repair, targetF: &pm.TargetF,
+		stallIterations: &pm.StallIterations, stallTolerance: &pm.StallTolerance, stop: &pm.Stop, observer: &pm.Observer,
+		tolerances: &pm.Tolerances}
+}
+
+func (sp *SPSA) fields() solverFields {
+	return solverFields{xmin: &sp.Xmin, xmax: &sp.Xmax, maxIter: &sp.MaxIter, maxFev: &sp.MaxFev, callback: &sp.Callback, src: &sp.Src, logger: &sp.Logger, repair: &sp.Repair, targetF: &sp.TargetF,
+		stallIterations: &sp.StallIterations, stallTolerance: &sp.StallTolerance, stop: &sp.Stop, observer: &sp.Observer,
+		evaluator: &sp.Evaluator}
+}
+
+func (cma *CmaEsCholB) fields() solverFields {
+	return solverFields{xmin: &cma.Xmin, xmax: &cma.Xmax, src: &cma.Src, repair: &cma.Repair, targetF: &cma.TargetF,
+		stallIterations: &cma.StallIterations, stallTolerance: &cma.StallTolerance, observer: &cma.Observer,
+		initializer: &cma.Initializer}
+}
+
+func (ls *LatticeSearch) fields() solverFields {
+	return solverFields{xmin: &ls.Xmin, xmax: &ls.Xmax, maxIter: &ls.MaxIter, maxFev: &ls.MaxFev, callback: &ls.Callback, repair: &ls.Repair, targetF: &ls.TargetF,
+		stallIterations: &ls.StallIterations, stallTolerance: &ls.StallTolerance, stop: &ls.Stop, observer: &ls.Observer,
+		evaluator: &ls.Evaluator}
+}
+
+func (qs *QuasiRandomSearch) fields() solverFields {
+	return solverFields{xmin: &qs.Xmin, xmax: &qs.Xmax, maxFev: &qs.MaxFev, src: &qs.Src, targetF: &qs.TargetF, stop: &qs.Stop, observer: &qs.Observer,
+		evaluator: &qs.Evaluator}
+}
+
+func (sg *StochasticGradient) fields() solverFields {
+	return solverFields{maxIter: &sg.MaxIter, callback: &sg.Callback, src: &sg.Src, logger: &sg.Logger, targetF: &sg.TargetF,
+		stallIterations: &sg.StallIterations, stallTolerance: &sg.StallTolerance, stop: &sg.Stop, observer: &sg.Observer}
+}
+
+func (o *Options) fields() solverFields {
+	return solverFields{xmin: &o.Xmin, xmax: &o.Xmax, maxIter: &o.MaxIter, maxFev: &o.MaxFev, callback: &o.Callback, src: &o.Src, logger: &o.Logger, repair: &o.Repair, method: &o.Method, targetF: &o.TargetF,
+		stallIterations: &o.StallIterations, stallTolerance: &o.StallTolerance, stop: &o.Stop, observer: &o.Observer,
+		evaluator: &o.Evaluator, tolerances: &o.Tolerances, ineq: &o.Ineq, eq: &o.Eq, initializer: &o.Initializer}
+}
+
+func (s *SQP) fields() solverFields {
+	return solverFields{xmin: &s.Xmin, xmax: &s.Xmax, maxIter: &s.MaxIter, maxFev: &s.MaxFev, callback: &s.Callback, logger: &s.Logger,
+		stop: &s.Stop, observer: &s.Observer, ineq: &s.Ineq, eq: &s.Eq, targetF: &s.TargetF, stallIterations: &s.StallIterations, stallTolerance: &s.StallTolerance}
+}
+
+func unsupported(option string, s configurable) error {
+	return fmt.Errorf("optimize: %s is not supported by %T", option, s)
+}
+
+func configure(s configurable, opts []Option) error {
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithBounds sets the bounds xmin <= x <= xmax. Either may be nil, and
+// infinite components are unbounded.
+func WithBounds(xmin, xmax []float64) Option {
+	return func(s configurable) error {
+		if xmin != nil && xmax != nil && len(xmin) != len(xmax) {
+			return errors.New("optimize: WithBounds: len(xmin) != len(xmax)")
+		}
+		for i := range xmin {
+			if math.IsNaN(xmin[i]) || (i < len(xmax) && !(xmin[i] <= xmax[i])) {
+				return fmt.Errorf("optimize: WithBounds: invalid bounds at %d", i)
+			}
+		}
+		for i := range xmax {
+			if math.IsNaN(xmax[i]) {
+				return fmt.Errorf("optimize: WithBounds: invalid bounds at %d", i)
+			}
+		}
+		f := s.fields()
+		if f.xmin == nil {
+			return unsupported("WithBounds", s)
+		}
+		*f.xmin, *f.xmax = xmin, xmax
+		return nil
+	}
+}
+
+// WithMaxIter sets the maximum number of iterations. 0 means the default.
+func WithMaxIter(n int) Option {
+	return func(s configurable) error {
+		if n < 0 {
+			return errors.New("optimize: WithMaxIter: negative value")
+		}
+		f := s.fields()
+		if f.maxIter == nil {
+			return unsupported("WithMaxIter", s)
+		}
+		*f.maxIter = n
+		return nil
+	}
+}
+
+// WithMaxFev sets the maximum number of function evaluations. 0 means the default.
+func WithMaxFev(n int) Option {
+	return func(s configurable) error {
+		if n < 0 {
+			return errors.New("optimize: WithMaxFev: negative value")
+		}
+		f := s.fields()
+		if f.maxFev == nil {
+			return unsupported("WithMaxFev", s)
+		}
+		*f.maxFev = n
+		return nil
+	}
+}
+
+// WithCallback sets the function called with each iterate
+func WithCallback(fn func([]float64)) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.callback == nil {
+			return unsupported("WithCallback", s)
+		}
+		*f.callback = fn
+		return nil
+	}
+}
+
+// WithRNG sets the random source of stochastic solvers
+func WithRNG(src rand.Source) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.src == nil {
+			return unsupported("WithRNG", s)
+		}
+		*f.src = src
+		return nil
+	}
+}
+
+// WithLogger sets the logger of iterations
+func WithLogger(logger *log.Logger) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.logger == nil {
+			return unsupported("WithLogger", s)
+		}
+		*f.logger = logger
+		return nil
+	}
+}
+
+// WithRepair sets the Repair applied to points before evaluation
+func WithRepair(r Repair) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.repair == nil {
+			return unsupported("WithRepair", s)
+		}
+		*f.repair = r
+		return nil
+	}
+}
+
+// WithConstraints sets the constraints c(x) <= 0 and ceq(x) = 0, either
+// may be nil. SQP handles them natively, Minimize penalizes them for the
+// other methods.
+func WithConstraints(ineq, eq func(x []float64) []float64) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.ineq == nil {
+			return unsupported("WithConstraints", s)
+		}
+		*f.ineq, *f.eq = ineq, eq
+		return nil
+	}
+}
+
+// WithMethod sets the method of Minimize, one of Methods
+func WithMethod(name string) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.method == nil {
+			return unsupported("WithMethod", s)
+		}
+		for _, m := range Methods {
+			if strings.EqualFold(name, m) {
+				*f.method = m
+				return nil
+			}
+		}
+		return errors.New("optimize: unknown method " + name + ", expected one of " + strings.Join(Methods, ", "))
+	}
+}
+
+// NewPowellMinimizerWith returns a PowellMinimizer with default tolerances configured by opts
+func NewPowellMinimizerWith(opts ...Option) (*PowellMinimizer, error) {
+	pm := NewPowellMinimizer()
+	if err := configure(pm, opts); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// NewSPSAWith returns a SPSA with the standard gain exponents configured by opts
+func NewSPSAWith(opts ...Option) (*SPSA, error) {
+	sp := NewSPSA()
+	if err := configure(sp, opts); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// NewCmaEsCholBWith returns a CmaEsCholB configured by opts. Its limits are
+// those of the optimize.Settings passed to optimize.Minimize.
+func NewCmaEsCholBWith(opts ...Option) (*CmaEsCholB, error) {
+	cma := &CmaEsCholB{}
+	if err := configure(cma, opts); err != nil {
+		return nil, err
+	}
+	return cma, nil
+}
+
+// NewLatticeSearchWith returns a LatticeSearch with default limits configured by opts
+func NewLatticeSearchWith(opts ...Option) (*LatticeSearch, error) {
+	ls := NewLatticeSearch()
+	if err := configure(ls, opts); err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// NewSQPWith returns a SQP with default limits configured by opts
+func NewSQPWith(opts ...Option) (*SQP, error) {
+	s := NewSQP()
+	if err := configure(s, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewStochasticGradientWith returns a StochasticGradient using method with a
+// constant learning rate lr, configured by opts
+func NewStochasticGradientWith(method StochasticUpdater, lr float64, opts ...Option) (*StochasticGradient, error) {
+	if lr <= 0 || math.IsNaN(lr) {
+		return nil, errors.New("optimize: learning rate must be positive")
+	}
+	sg := NewStochasticGradient(method, lr)
+	if err := configure(sg, opts); err != nil {
+		return nil, err
+	}
+	return sg, nil
+}
+
+// NewOptions returns the Options of Minimize configured by opts
+func NewOptions(opts ...Option) (*Options, error) {
+	o := &Options{}
+	if err := configure(o, opts); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,56 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleNewSPSAWith() {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]-2)*(x[1]-2) }
+	sp, err := NewSPSAWith(WithBounds([]float64{0, 0}, []float64{3, 1.5}), WithMaxIter(2000), WithRNG(rand.NewSource(1)))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.2f\n", sp.Minimize(f, []float64{0, 0}).X)
+	// cma-es limits are set by optimize.Settings
+	_, err = NewCmaEsCholBWith(WithMaxIter(100))
+	fmt.Println(err)
+	// Output:
+	// [1.00 1.50]
+	// optimize: WithMaxIter is not supported by *optimize.CmaEsCholB
+}
+
+func TestOptions(t *testing.T) {
+	inf := math.Inf(1)
+	for name, opt := range map[string]Option{
+		"length":   WithBounds([]float64{0}, []float64{1, 1}),
+		"order":    WithBounds([]float64{0, 2}, []float64{1, 1}),
+		"nan":      WithBounds(nil, []float64{math.NaN()}),
+		"maxiter":  WithMaxIter(-1),
+		"maxfev":   WithMaxFev(-1),
+		"method":   WithMethod("de"),
+		"noBounds": WithBounds([]float64{-inf}, []float64{inf}),
+	} {
+		if _, err := NewPowellMinimizerWith(opt); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewStochasticGradientWith(&Adam{}, 0); err == nil {
+		t.Error("expected an error for a zero learning rate")
+	}
+	var iters int
+	o, err := NewOptions(WithMethod("CMAES"), WithBounds([]float64{-1}, nil), WithMaxFev(10), WithCallback(func([]float64) { iters++ }))
+	if err != nil || o.Method != "cmaes" || o.Xmin[0] != -1 || o.MaxFev != 10 || o.Callback == nil {
+		t.Errorf("bad options %+v %v", o, err)
+	}
+	ls, err := NewLatticeSearchWith(WithMaxIter(3), WithBounds([]float64{0}, []float64{10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := ls.Minimize(func(x []float64) float64 { return math.Abs(x[0] - 8) }, []float64{0}); res.NIter != 3 || res.X[0] != 3 {
+		t.Errorf("lattice: %+v", res)
+	}
+}
@@ -0,0 +1,275 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ParetoPoint is a point of a multi-objective problem and its objective values.
+// Violation is its constraint violation (see Violation), 0 if feasible.
+type ParetoPoint struct {
+	X         []float64 `json:"x"`
+	F         []float64 `json:"f"`
+	Violation float64   `json:"violation,omitempty"`
+}
+
+// Dominates returns true if objective vector a Pareto-dominates b, ie a is
+// not worse than b on any objective and better on at least one (minimization).
+func Dominates(a, b []float64) bool {
+	better := false
+	for i := range a {
+		switch {
+		case a[i] > b[i]:
+			return false
+		case a[i] < b[i]:
+			better = true
+		}
+	}
+	return better
+}
+
+// ConstrainedDominates implements the constrained-domination of Deb: a
+// feasible point dominates an infeasible one, an infeasible point dominates
+// another with a larger violation, and feasible points (or infeasible ones of
+// equal violation) are compared with Dominates.
+func ConstrainedDominates(fa []float64, va float64, fb []float64, vb float64) bool {
+	if va != vb && (va > 0 || vb > 0) {
+		return va < vb
+	}
+	return Dominates(fa, fb)
+}
+
+// ParetoArchive is a set of mutually non-dominated points, in the sense of
+// constrained-domination. It is the archive used by multi-objective methods.
+type ParetoArchive struct {
+	// MaxSize, if positive, is the maximum number of points kept. When
+	// exceeded, the most crowded point is pruned.
+	MaxSize int
+	Points  []ParetoPoint
+}
+
+// NewParetoArchive returns an archive of at most maxSize points (0 for no limit)
+func NewParetoArchive(maxSize int) *ParetoArchive {
+	return &ParetoArchive{MaxSize: maxSize}
+}
+
+// Len returns the number of points in the archive
+func (pa *ParetoArchive) Len() int { return len(pa.Points) }
+
+// Dominated returns true if feasible f is dominated by or equal to a point of the archive
+func (pa *ParetoArchive) Dominated(f []float64) bool {
+	return pa.dominated(f, 0)
+}
+
+func (pa *ParetoArchive) dominated(f []float64, violation float64) bool {
+	for _, p := range pa.Points {
+		if ConstrainedDominates(p.F, p.Violation, f, violation) || (p.Violation == violation && floats.Equal(p.F, f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add inserts a copy of feasible (x, f) if it isn't dominated, removing the
+// points it dominates. It returns true if the point was inserted (it may still
+// have been pruned if the archive is full).
+func (pa *ParetoArchive) Add(x, f []float64) bool {
+	return pa.AddConstrained(x, f, 0)
+}
+
+// AddConstrained is like Add for a point of constraint violation violation.
+// Infeasible points are only kept while no feasible point is known.
+func (pa *ParetoArchive) AddConstrained(x, f []float64, violation float64) bool {
+	if pa.dominated(f, violation) {
+		return false
+	}
+	kept := pa.Points[:0]
+	for _, p := range pa.Points {
+		if !ConstrainedDominates(f, violation, p.F, p.Violation) {
+			kept = append(kept, p)
+		}
+	}
+	pa.Points = append(kept, ParetoPoint{
+		X:         append([]float64(nil), x...),
+		F:         append([]float64(nil), f...),
+		Violation: violation,
+	})
+	for pa.MaxSize > 0 && len(pa.Points) > pa.MaxSize {
+		pa.prune()
+	}
+	return true
+}
+
+// Objectives returns the objective vectors of the archive
+func (pa *ParetoArchive) Objectives() [][]float64 {
+	fs := make([][]float64, len(pa.Points))
+	for i, p := range pa.Points {
+		fs[i] = p.F
+	}
+	return fs
+}
+
+// feasibleObjectives returns the objective vectors of feasible points
+func (pa *ParetoArchive) feasibleObjectives() [][]float64 {
+	var fs [][]float64
+	for _, p := range pa.Points {
+		if p.Violation <= 0 {
+			fs = append(fs, p.F)
+		}
+	}
+	return fs
+}
+
+// prune removes the point with the smallest crowding distance
+func (pa *ParetoArchive) prune() {
+	cd := CrowdingDistance(pa.Objectives())
+	worst := 0
+	for i, d := range cd {
+		if d < cd[worst] {
+			worst = i
+		}
+	}
+	pa.Points = append(pa.Points[:worst], pa.Points[worst+1:]...)
+}
+
+// CrowdingDistance returns the crowding distance of each objective vector of
+// fs, as in NSGA-II. Extreme points have an infinite distance.
+func CrowdingDistance(fs [][]float64) []float64 {
+	n := len(fs)
+	cd := make([]float64, n)
+	if n == 0 {
+		return cd
+	}
+	idx := make([]int, n)
+	for m := range fs[0] {
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(a, b int) bool { return fs[idx[a]][m] < fs[idx[b]][m] })
+		lo, hi := fs[idx[0]][m], fs[idx[n-1]][m]
+		cd[idx[0]], cd[idx[n-1]] = math.Inf(1), math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for k := 1; k < n-1; k++ {
+			cd[idx[k]] += (fs[idx[k+1]][m] - fs[idx[k-1]][m]) / (hi - lo)
+		}
+	}
+	return cd
+}
+
+// Hypervolume returns the volume of the objective space dominated by the
+// feasible points of the archive and bounded by the reference point ref.
+func (pa *ParetoArchive) Hypervolume(ref []float64) float64 {
+	return Hypervolume(pa.feasibleObjectives(), ref)
+}
+
+// Hypervolume returns the volume dominated by the objective vectors fs and
+// bounded by ref. Points not strictly better than ref on all objectives are
+// ignored. It slices the space along the last objective, which is exact and is
+// fast enough for a few objectives.
+func Hypervolume(fs [][]float64, ref []float64) float64 {
+	var pts [][]float64
+	for _, f := range fs {
+		ok := true
+		for i := range ref {
+			if f[i] >= ref[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			pts = append(pts, f)
+		}
+	}
+	return hypervolume(pts, ref, len(ref))
+}
+
+func hypervolume(pts [][]float64, ref []float64, d int) float64 {
+	if len(pts) == 0 {
+		return 0
+	}
+	if d == 1 {
+		lo := pts[0][0]
+		for _, p := range pts {
+			lo = math.Min(lo, p[0])
+		}
+		return ref[0] - lo
+	}
+	sorted := append([][]float64(nil), pts...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a][d-1] < sorted[b][d-1] })
+	v := 0.
+	for i := range sorted {
+		next := ref[d-1]
+		if i+1 < len(sorted) {
+			next = sorted[i+1][d-1]
+		}
+		if h := next - sorted[i][d-1]; h > 0 {
+			v += h * hypervolume(sorted[:i+1], ref, d-1)
+		}
+	}
+	return v
+}
+
+// IGD returns the inverted generational distance of the archive to a
+// reference front: the mean distance from each reference point to its
+// nearest feasible archive point. It is +Inf if there is no feasible point.
+func (pa *ParetoArchive) IGD(front [][]float64) float64 {
+	fs := pa.feasibleObjectives()
+	if len(fs) == 0 {
+		return math.Inf(1)
+	}
+	s := 0.
+	for _, r := range front {
+		d := math.Inf(1)
+		for _, f := range fs {
+			d = math.Min(d, floats.Distance(r, f, 2))
+		}
+		s += d
+	}
+	return s / float64(len(front))
+}
+
+// WriteCSV writes the archive with a header x0,...,f0,...,violation
+func (pa *ParetoArchive) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if len(pa.Points) > 0 {
+		var header []string
+		for i := range pa.Points[0].X {
+			header = append(header, "x"+strconv.Itoa(i))
+		}
+		for i := range pa.Points[0].F {
+			header = append(header, "f"+strconv.Itoa(i))
+		}
+		header = append(header, "violation")
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, p := range pa.Points {
+		var rec []string
+		for _, v := range append(append(append([]float64(nil), p.X...), p.F...), p.Violation) {
+			rec = append(rec, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the points of the archive as a JSON array
+func (pa *ParetoArchive) WriteJSON(w io.Writer) error {
+	pts := pa.Points
+	if pts == nil {
+		pts = []ParetoPoint{}
+	}
+	return json.NewEncoder(w).Encode(pts)
+}
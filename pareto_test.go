@@ -0,0 +1,84 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+)
+
+func ExampleParetoArchive() {
+	pa := NewParetoArchive(0)
+	for _, f := range [][]float64{{1, 3}, {2, 2}, {3, 1}, {2.5, 2.5}, {1.5, 1.5}} {
+		pa.Add([]float64{f[0]}, f)
+	}
+	fmt.Println(pa.Len(), pa.Hypervolume([]float64{4, 4}))
+	if err := pa.WriteCSV(os.Stdout); err != nil {
+		panic(err)
+	}
+	// Output:
+	// 3 7.25
+	// x0,f0,f1,violation
+	// 1,1,3,0
+	// 3,3,1,0
+	// 1.5,1.5,1.5,0
+}
+
+func TestParetoArchive(t *testing.T) {
+	if !Dominates([]float64{1, 2}, []float64{1, 3}) || Dominates([]float64{1, 2}, []float64{1, 2}) || Dominates([]float64{1, 2}, []float64{2, 1}) {
+		t.Error("bad Dominates")
+	}
+	// front of f = (x, 1-x), x in [0,1]
+	var front [][]float64
+	for i := 0; i <= 100; i++ {
+		x := float64(i) / 100
+		front = append(front, []float64{x, 1 - x})
+	}
+	pa := NewParetoArchive(11)
+	for _, f := range front {
+		pa.Add(f[:1], f)
+	}
+	if pa.Len() != 11 {
+		t.Fatalf("expected 11 points, got %d", pa.Len())
+	}
+	// pruning keeps extremes and spreads points
+	if igd := pa.IGD(front); igd > .05 {
+		t.Errorf("igd too large: %g", igd)
+	}
+	if hv := pa.Hypervolume([]float64{1, 1}); math.Abs(hv-.45) > .01 {
+		t.Errorf("expected hypervolume close to .45, got %g", hv)
+	}
+	// 3 objectives: a single point dominates a box
+	if hv := Hypervolume([][]float64{{0, 0, 0}, {.5, .5, .5}}, []float64{1, 2, 3}); hv != 6 {
+		t.Errorf("expected 6, got %g", hv)
+	}
+	if hv := Hypervolume([][]float64{{0, 1, 1}, {1, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}); hv != 4 {
+		t.Errorf("expected 4, got %g", hv)
+	}
+}
+
+func TestConstrainedDominates(t *testing.T) {
+	if !ConstrainedDominates([]float64{5, 5}, 0, []float64{1, 1}, .1) {
+		t.Error("feasible should dominate infeasible")
+	}
+	if !ConstrainedDominates([]float64{5, 5}, .1, []float64{1, 1}, .2) {
+		t.Error("less violation should dominate")
+	}
+	if ConstrainedDominates([]float64{1, 2}, 0, []float64{2, 1}, 0) {
+		t.Error("feasible points should be compared by objectives")
+	}
+	pa := NewParetoArchive(0)
+	pa.AddConstrained([]float64{0}, []float64{0, 0}, 1)
+	pa.AddConstrained([]float64{1}, []float64{1, 1}, .5)
+	if pa.Len() != 1 || pa.Points[0].Violation != .5 {
+		t.Errorf("expected the least infeasible point, got %v", pa.Points)
+	}
+	if !math.IsInf(pa.IGD([][]float64{{0, 0}}), 1) || pa.Hypervolume([]float64{2, 2}) != 0 {
+		t.Error("infeasible points should not count in indicators")
+	}
+	pa.Add([]float64{2}, []float64{3, 3})
+	pa.Add([]float64{3}, []float64{2, 4})
+	if pa.Len() != 2 || pa.AddConstrained([]float64{4}, []float64{0, 0}, .1) {
+		t.Errorf("feasible points should replace infeasible ones, got %v", pa.Points)
+	}
+}
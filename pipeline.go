@@ -0,0 +1,108 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Minimizer is implemented by the solvers minimizing a function from a
+// starting point: PowellMinimizer, SPSA, LatticeSearch, MethodMinimizer
+// for gonum methods, and the solvers of NewAlgorithm
+type Minimizer interface {
+	Minimize(f func([]float64) float64, x0 []float64) *Result
+}
+
+var (
+	_ Minimizer = &PowellMinimizer{}
+	_ Minimizer = &SPSA{}
+	_ Minimizer = &LatticeSearch{}
+	_ Minimizer = &Scaling{}
+)
+
+// Stage is a step of a Pipeline
+type Stage struct {
+	// Options are the options of Minimize, Method being the method of the stage
+	Options Options
+	// Minimizer, if not nil, is used instead of Options
+	Minimizer Minimizer
+	// Fraction is the fraction of the MaxFev of the Pipeline allotted to the stage
+	Fraction float64
+}
+
+// Pipeline chains methods, eg CmaEsCholB for exploration then Powell to
+// polish, each stage starting from the best point found by the previous
+// ones. The evaluation budget MaxFev is split among the stages by their
+// Fraction. The budget not used by a stage is added to the next one, and
+// the last stage gets the remaining budget.
+type Pipeline struct {
+	Stages []Stage
+	// MaxFev is the shared budget. if 0, the stages use their own limits.
+	MaxFev int
+}
+
+// Minimize runs the stages from x0. The Result is the best point of the
+// stages, with the totals of iterations, evaluations and elapsed time, and
+// the Status of the last stage. Its Extra is the []*Result of the stages.
+func (p *Pipeline) Minimize(problem optimize.Problem, x0 []float64) (*Result, error) {
+	if len(p.Stages) == 0 {
+		return nil, errors.New("optimize: Pipeline without stages")
+	}
+	if problem.Func == nil {
+		return nil, errors.New("optimize: nil Func")
+	}
+	start := time.Now()
+	res := &Result{X: append([]float64(nil), x0...), F: math.Inf(1)}
+	results := make([]*Result, 0, len(p.Stages))
+	used := 0
+	for i := range p.Stages {
+		st := &p.Stages[i]
+		budget := 0
+		if p.MaxFev > 0 {
+			// evaluations allotted to the stages up to i
+			alloc := p.MaxFev
+			if i < len(p.Stages)-1 {
+				alloc = 0
+				for _, prev := range p.Stages[:i+1] {
+					alloc += int(math.Round(prev.Fraction * float64(p.MaxFev)))
+				}
+				alloc = min(alloc, p.MaxFev)
+			}
+			if budget = alloc - used; budget <= 0 {
+				continue
+			}
+		}
+		var (
+			r   *Result
+			err error
+		)
+		if st.Minimizer != nil {
+			if c, ok := st.Minimizer.(configurable); ok && budget > 0 {
+				if f := c.fields(); f.maxFev != nil {
+					*f.maxFev = budget
+				}
+			}
+			r = st.Minimizer.Minimize(problem.Func, res.X)
+		} else {
+			opts := st.Options
+			if budget > 0 {
+				opts.MaxFev = budget
+			}
+			if r, err = Minimize(problem, res.X, &opts); err != nil && r == nil {
+				return nil, fmt.Errorf("optimize: stage %d: %v", i, err)
+			}
+		}
+		results = append(results, r)
+		used += r.NFev
+		res.add(r)
+		res.Message = fmt.Sprintf("stage %d: %s", i, r.Message)
+	}
+	if len(results) == 0 {
+		return nil, errors.New("optimize: Pipeline without budget")
+	}
+	res.Extra = results
+	return res.done(start), nil
+}
@@ -0,0 +1,69 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExamplePipeline() {
+	// explore the multimodal Rastrigin function with CMA-ES, then polish with Powell
+	rastrigin := func(x []float64) float64 {
+		s := 10 * float64(len(x))
+		for _, xi := range x {
+			s += xi*xi - 10*math.Cos(2*math.Pi*xi)
+		}
+		return s
+	}
+	p := &Pipeline{MaxFev: 3000, Stages: []Stage{
+		{Options: Options{Method: "cmaes", Src: rand.NewSource(1)}, Fraction: .8},
+		{Options: Options{Method: "powell"}},
+	}}
+	res, err := p.Minimize(optimize.Problem{Func: rastrigin}, []float64{3, 3})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.4f %.1g %t\n", res.X, res.F, res.NFev <= 3000)
+	// Output:
+	// [-0.0000 -0.0000] 0 true
+}
+
+func TestPipeline(t *testing.T) {
+	problem := optimize.Problem{Func: rosen}
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	p := &Pipeline{MaxFev: 1000, Stages: []Stage{
+		{Minimizer: sp, Fraction: .3},
+		{Options: Options{Method: "neldermead"}, Fraction: .3},
+		{Minimizer: NewPowellMinimizer()},
+	}}
+	res, err := p.Minimize(problem, []float64{-1.2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stages := res.Extra.([]*Result)
+	if len(stages) != 3 || sp.MaxFev != 300 {
+		t.Fatalf("%d stages, spsa budget %d used %d", len(stages), sp.MaxFev, stages[0].NFev)
+	}
+	nfev := 0
+	for _, r := range stages {
+		nfev += r.NFev
+		if r.F < res.F {
+			t.Errorf("stage F %g < %g", r.F, res.F)
+		}
+	}
+	if res.NFev != nfev || nfev > 1000 || math.Abs(res.X[0]-1) > 1e-3 || res.Status != stages[2].Status {
+		t.Errorf("combined result %+v, %d evaluations", res, nfev)
+	}
+
+	if _, err := (&Pipeline{}).Minimize(problem, []float64{0, 0}); err == nil {
+		t.Error("expected an error without stages")
+	}
+	p = &Pipeline{Stages: []Stage{{Options: Options{Method: "unknown"}}}}
+	if _, err := p.Minimize(problem, []float64{0, 0}); err == nil {
+		t.Error("expected the error of the stage")
+	}
+}
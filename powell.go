@@ -1,16 +1,110 @@
 package optimize
 
 import (
+	"errors"
 	"log"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
 )
 
 // PowellMinimizer minimizes a scalar function of multidimensionnal x using modified Powell algorithm
-// (see fmin_powell in scipy.optimize)
+// (see fmin_powell in scipy.optimize).
+// Without Callback, Observer and Stop, its iterations don't allocate, for the
+// millions of small fits of a loop: f must not retain its argument.
 type PowellMinimizer struct {
-	Callback        func([]float64)
-	Xtol, Ftol      float64
+	Callback func([]float64)
+	// Xtol is the relative tolerance of the line searches. Ftol is the
+	// relative tolerance of the decrease of f over an iteration stopping the
+	// run with MethodConverge, unless Tolerances is set.
+	Xtol, Ftol float64
+	// Tolerances, if not nil, replace the test of Ftol by the tests of
+	// Tolerances on the step and on the decrease of f over an iteration,
+	// stopping the run with StepConvergence or FunctionConvergence
+	Tolerances      *Tolerances
 	MaxIter, MaxFev int
 	Logger          *log.Logger
+	// Comparator, if not nil, must confirm that a line search step improves
+	// on the current point, eg a *NoisyObjective for noisy problems. Its
+	// evaluations, if it counts them like NoisyObjective, are part of NFev
+	// and MaxFev. gonum's Settings don't see them when Powell runs pm.
+	Comparator Comparator
+	// Repair, if not nil, is applied to points before evaluation. Callback
+	// receives repaired iterates.
+	Repair Repair
+	// TargetF, if not nil, stops the minimization with Status
+	// FunctionThreshold at the first point where f <= *TargetF
+	TargetF *float64
+	// StallIterations, if positive, stops the minimization with Status
+	// FunctionConvergence when the best value has not decreased by more than
+	// StallTolerance over the last StallIterations iterations.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of each iteration
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run
+	Observer Observer
+	state    *powellState
+	restored bool
+	warm     *WarmStart
+}
+
+// powellState is the state of minimizePowell at the end of an iteration,
+// before the extrapolation step
+type powellState struct {
+	X, X1, Direc          []float64
+	F, FX, Delta          float64
+	BigInd, Iter, NFev, N int
+}
+
+// Snapshot returns the state of the current or last run
+func (pm *PowellMinimizer) Snapshot() ([]byte, error) {
+	if pm.state == nil || pm.state.Iter == 0 {
+		return nil, errNoState
+	}
+	return encodeState("powell", pm.state)
+}
+
+// Restore sets the state from which the next call to Minimize resumes,
+// ignoring its x0. MaxIter and MaxFev include the iterations and
+// evaluations of the restored run.
+func (pm *PowellMinimizer) Restore(data []byte) error {
+	state := &powellState{}
+	if err := decodeState("powell", data, state); err != nil {
+		return err
+	}
+	pm.state, pm.restored = state, true
+	return nil
+}
+
+// WarmStart primes the next run with the point and directions of prev
+func (pm *PowellMinimizer) WarmStart(prev *Result) error {
+	warm, err := warmState("powell", prev)
+	if err != nil {
+		return err
+	}
+	pm.warm = warm
+	return nil
+}
+
+// warmDirections returns the normalized directions of warm, nil if they
+// are not N non-zero directions
+func warmDirections(warm *WarmStart, N int) []float64 {
+	if warm == nil || len(warm.Directions) != N*N {
+		return nil
+	}
+	direc := append([]float64(nil), warm.Directions...)
+	for i := 0; i < N; i++ {
+		row := direc[i*N : i*N+N]
+		norm := floats.Norm(row, 2)
+		if norm == 0 || math.IsInf(norm, 0) || math.IsNaN(norm) {
+			return nil
+		}
+		floats.Scale(1/norm, row)
+	}
+	return direc
 }
 
 // NewPowellMinimizer return a PowellMinimizer with default tolerances
@@ -19,9 +113,42 @@ func NewPowellMinimizer() (pm *PowellMinimizer) {
 	return
 }
 
-// Minimize minimizes f starting at x0
-func (pm *PowellMinimizer) Minimize(f func([]float64) float64, x0 []float64) {
+// Validate returns an error if the tolerances of pm are negative or NaN
+func (pm *PowellMinimizer) Validate() error {
+	if pm.Xtol < 0 || math.IsNaN(pm.Xtol) {
+		return errors.New("optimize: powell: invalid Xtol")
+	}
+	if pm.Ftol < 0 || math.IsNaN(pm.Ftol) {
+		return errors.New("optimize: powell: invalid Ftol")
+	}
+	if pm.Tolerances != nil {
+		return pm.Tolerances.validate()
+	}
+	return nil
+}
+
+// Minimize minimizes f starting at x0 and returns the solution, with Status MethodConverge, FunctionThreshold, FunctionConvergence or the reached limit.
+// If pm or x0, non-finite, is invalid, the Status is Failure and Message is the error of Validate or ValidateStart.
+// f and the Evaluations of Observer receive a buffer of the run, overwritten by the next evaluations and reused by
+// the next runs: they must copy x to retain it. With Repair, f receives a repaired copy.
+func (pm *PowellMinimizer) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	if err := pm.Validate(); err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
 	const MaxInt = (int)(^uint(0) >> 1)
+	warm := pm.warm
+	pm.warm = nil
+	if pm.restored {
+		x0, warm = pm.state.X, nil
+	} else if warm != nil && len(warm.X) == len(x0) {
+		x0 = warm.X
+	} else {
+		warm = nil
+	}
+	if err := ValidateStart(x0, nil, nil); err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
 	//# If neither are set, then set both to default
 	N := len(x0)
 	if pm.MaxIter <= 0 && pm.MaxFev <= 0 {
@@ -42,9 +169,65 @@ func (pm *PowellMinimizer) Minimize(f func([]float64) float64, x0 []float64) {
 			pm.MaxFev = MaxInt
 		}
 	}
-	fnMaxIter := func(iter int) bool { return iter >= pm.MaxIter }
-	fnMaxFev := func(fcalls int) bool { return fcalls >= pm.MaxFev }
-	minimizePowell(f, x0, pm.Callback, pm.Xtol, pm.Ftol, fnMaxIter, fnMaxFev, pm.Logger)
+	tw := &targetWatch{target: pm.TargetF}
+	sw := newStallWatch(pm.StallIterations, pm.StallTolerance)
+	st := newMonitor(pm.Stop, pm.Observer, "powell", x0)
+	// xprev is the previous iterate, for the step diagnostic
+	var xk []float64
+	xprev := append([]float64(nil), x0...)
+	var step float64
+	fnMaxIter := func(iter int) bool {
+		stop := st.iterate(iter, 0, xk, func() map[string]float64 { return map[string]float64{"step": step} })
+		return iter >= pm.MaxIter || sw.stalled(iter) || stop
+	}
+	// the evaluations of the comparisons count in the budget
+	var cmp Comparator
+	cc := &countingComparator{cmp: pm.Comparator}
+	if pm.Comparator != nil {
+		cmp = cc
+	}
+	fnMaxFev := func(fcalls int) bool { return fcalls+cc.n >= pm.MaxFev || tw.reached }
+	callback := func(x []float64) {
+		step = floats.Distance(x, xprev, 2)
+		xk, xprev = x, append(xprev[:0], x...)
+		if pm.Callback == nil {
+			return
+		}
+		// x is a buffer of the run, Callback gets its own copy
+		x = append([]float64(nil), x...)
+		if pm.Repair != nil {
+			pm.Repair.Repair(x)
+		}
+		pm.Callback(x)
+	}
+	if !pm.restored {
+		pm.state = &powellState{}
+		if direc := warmDirections(warm, N); direc != nil {
+			// directions of a cold state are the initial ones
+			pm.state.Direc, pm.state.N = direc, N
+		}
+	}
+	pm.restored = false
+	var timer evalTimer
+	res := minimizePowell(st.wrap(sw.wrap(tw.wrap(timer.wrap(RepairedFunc(pm.Repair, f))))), x0, callback, pm.Xtol, pm.Ftol, pm.Tolerances, fnMaxIter, fnMaxFev, pm.Logger, cmp, pm.state)
+	res.NFev += cc.n
+	if res.Status == optimize.IterationLimit && sw.stalled(res.NIter) {
+		res.Status, res.Reason = optimize.FunctionConvergence, ReasonStagnation
+	}
+	if res.Status == optimize.IterationLimit {
+		// a stop condition ended the loop, not a converged iteration
+		st.apply(res)
+	}
+	tw.apply(res)
+	if pm.Repair != nil {
+		pm.Repair.Repair(res.X)
+	}
+	res.Evals.FuncTime = timer.elapsed()
+	res.Warm = &WarmStart{Method: "powell", X: append([]float64(nil), res.X...), Iter: res.NIter}
+	if pm.state.N == N {
+		res.Warm.Directions = append([]float64(nil), pm.state.Direc...)
+	}
+	return st.done(res.done(start))
 }
 
 // Minimization of scalar function of one or more variables using the
@@ -65,18 +248,22 @@ func (pm *PowellMinimizer) Minimize(f func([]float64) float64, x0 []float64) {
 //     first reached.
 // direc : ndarray
 //     Initial set of direction vectors for the Powell method.
+// state, if not nil, is updated at each iteration, and the minimization
+// resumes from it if it is the state of a previous run.
 func minimizePowell(
 	f func([]float64) float64,
 	x0 []float64,
 	callback func([]float64),
-	xtol, ftol float64,
+	xtol, ftol float64, tols *Tolerances,
 	fnMaxIter func(int) bool, fnMaxFev func(int) bool,
-	disp *log.Logger) ([]float64, int) {
+	disp *log.Logger, cmp Comparator, state *powellState) *Result {
 	type float = float64
 	var (
 		fval, fx, delta, fx2, bnd, t, temp float
-		x1, x2, direc, direc1              []float
-		bigind, warnflag                   int
+		x1, x2, direc, direc1, xdirec      []float
+		bigind                             int
+		// converged is the status of the tests of tols
+		converged optimize.Status
 	)
 	abs := func(x float) float {
 		if x < 0 {
@@ -88,7 +275,7 @@ func minimizePowell(
 		fnMaxIter = func(int) bool { return false }
 	}
 	if fnMaxFev == nil {
-		fnMaxIter = func(int) bool { return false }
+		fnMaxFev = func(int) bool { return false }
 	}
 	// # we need to use a mutable object here that we can update in the
 	// # wrapper function
@@ -96,6 +283,10 @@ func minimizePowell(
 	fun := func(x []float) float {
 		y := f(x)
 		fcalls++
+		if y != y {
+			// a failed evaluation, eg a timeout of TimeoutFunc, is never an improvement
+			y = math.Inf(1)
+		}
 		return y
 	}
 	fnMaxFevSub := func(funcalls int) bool { return fnMaxFev(fcalls + funcalls) }
@@ -103,32 +294,69 @@ func minimizePowell(
 		callback = func(x []float64) {}
 	}
 	N := len(x0)
-	x := make([]float64, N)
+	// the buffers of the run are reused by the next runs
+	ws := getWorkspace()
+	defer putWorkspace(ws)
+	x := ws.floats(N)
 	copy(x, x0)
+	// the line searches evaluate the points of their buffer
+	ls := &powellLine{fun: fun, xtmp: ws.floats(N), lm: LineMinimizer{Tol: xtol * 100, MaxIter: 500}, fnMaxFev: fnMaxFevSub, cmp: cmp}
+	ls.f = ls.eval
 
 	// direc is used as a matrix direc[i,j]:=direc[i*N+j]
-	direc = make([]float, N*N)
-	direc1 = make([]float, N)
+	direc = ws.floats(N * N)
+	// xdirec holds the extrapolated direction. it must not alias a row of direc
+	xdirec = ws.floats(N)
 	for i := 0; i < N; i++ {
 		direc[i*N+i] = 1
 	}
 
-	fval = fun(x)
-	x1, x2 = make([]float64, N), make([]float64, N)
-	copy(x1, x)
+	x1, x2 = ws.floats(N), ws.floats(N)
 	iter := 0
-	ilist := make([]int, N)
+	resume := state != nil && state.Iter > 0 && state.N == N
+	if resume {
+		copy(x, state.X)
+		copy(x1, state.X1)
+		copy(direc, state.Direc)
+		fval, fx, delta, bigind, iter, fcalls = state.F, state.FX, state.Delta, state.BigInd, state.Iter, state.NFev
+	} else {
+		if state != nil && state.N == N && len(state.Direc) == N*N {
+			// warm start
+			copy(direc, state.Direc)
+		}
+		fval = fun(x)
+		copy(x1, x)
+	}
+	var tol Tolerances
+	// iterLimit is set if fnMaxIter stopped the loop
+	iterLimit := false
+	// xk is the iterate at the beginning of an iteration, dx its step
+	var xk, dx []float64
+	if tols != nil {
+		tol = tols.resolve(x0, fval)
+		xk, dx = ws.floats(N), ws.floats(N)
+		copy(xk, x)
+	}
+	ilist := ws.ints(N)
 	for i := range ilist {
 		ilist[i] = i
 	}
 	for {
+		if resume {
+			// the convergence tests of the restored iteration have passed
+			resume = false
+			goto extrapolate
+		}
 		fx = fval
 		bigind = 0
 		delta = 0.0
+		if tols != nil {
+			copy(xk, x)
+		}
 		for _, i := range ilist {
 			direc1 = direc[i*N : i*N+N]
 			fx2 = fval
-			fval, x, direc1 = linesearchPowell(fun, x, direc1, xtol*100, fnMaxFevSub)
+			fval, x, direc1 = ls.search(x, direc1, fval)
 			if (fx2 - fval) > delta {
 				delta = fx2 - fval
 				bigind = i
@@ -136,25 +364,43 @@ func minimizePowell(
 		}
 		iter++
 		callback(x)
-		bnd = ftol*(abs(fx)+abs(fval)) + 1e-20
-		if 2.0*(fx-fval) <= bnd {
-			break
+		if state != nil {
+			*state = powellState{X: append(state.X[:0], x...), X1: append(state.X1[:0], x1...), Direc: append(state.Direc[:0], direc...),
+				F: fval, FX: fx, Delta: delta, BigInd: bigind, Iter: iter, N: N, NFev: fcalls}
+		}
+		if tols != nil {
+			if tol.fSmall(fx, fval) {
+				converged = optimize.FunctionConvergence
+				break
+			}
+			if tol.xSmall(x, floats.SubTo(dx, x, xk)) {
+				converged = optimize.StepConvergence
+				break
+			}
+		} else {
+			bnd = ftol*(abs(fx)+abs(fval)) + 1e-20
+			if 2.0*(fx-fval) <= bnd {
+				break
+			}
 		}
 		if fnMaxFev(fcalls) {
 			break
 		}
+		// fnMaxIter evaluates the stop conditions of the run, so that its
+		// result is only recorded for the iterations which don't converge
 		if fnMaxIter(iter) {
+			iterLimit = true
 			break
 		}
+	extrapolate:
 		//# Construct the extrapolated point
 		// direc1 = x - x1
 		// x2 = 2*x - x1
 		// x1 = x.copy()
-		for i, xi := range x {
-			direc1[i] = xi - x1[i]
-			x2[i] = 2*xi - x1[i]
-			x1[i] = xi
-		}
+		direc1 = floats.SubTo(xdirec, x, x1)
+		floats.ScaleTo(x2, 2, x)
+		floats.Sub(x2, x1)
+		copy(x1, x)
 		fx2 = fun(x2)
 
 		if fx > fx2 {
@@ -164,7 +410,7 @@ func minimizePowell(
 			temp = fx - fx2
 			t -= delta * temp * temp
 			if t < 0.0 {
-				fval, x, direc1 = linesearchPowell(fun, x, direc1, xtol*100, fnMaxFevSub)
+				fval, x, direc1 = ls.search(x, direc1, fval)
 				//direc[bigind] = direc[-1]
 				copy(direc[bigind*N:bigind*N+N], direc[(N-1)*N:N*N])
 				//direc[-1] = direc1
@@ -173,59 +419,75 @@ func minimizePowell(
 		}
 
 	}
-	warnflag = 0
+	// the last iteration is observed, its result doesn't change the status
+	fnMaxIter(iter)
+	res := &Result{X: append([]float64(nil), x...), F: fval, NIter: iter, NFev: fcalls}
+	// like scipy, a run spending its budget in its last iteration is
+	// limited rather than converged. fnMaxFev has no side effect.
 	if fnMaxFev(fcalls) {
-		// FunctionEvaluationLimit
-		warnflag = 1
+		res.Status, res.Reason = optimize.FunctionEvaluationLimit, ReasonMaxFev
 		//msg = _status_message['maxfev']
 		msg := "maxfev"
 		if disp != nil {
 			disp.Println("Warning: " + msg)
 		}
-	} else if fnMaxIter(iter) {
-		// IterationLimit
-		warnflag = 2
+	} else if iterLimit {
+		res.Status, res.Reason = optimize.IterationLimit, ReasonMaxIter
 		//msg = _status_message['maxiter']
 		msg := "maxiter"
 		if disp != nil {
 			disp.Println("Warning: " + msg)
 		}
 	} else {
-		// Success,MethodConverge ?
+		// the relative decrease of f below ftol, or a test of tols
+		res.Status, res.Reason = optimize.MethodConverge, ReasonTolFun
+		switch converged {
+		case optimize.FunctionConvergence:
+			res.Status = converged
+		case optimize.StepConvergence:
+			res.Status, res.Reason = converged, ReasonTolX
+		}
 		//msg = _status_message['success']
 		if disp != nil {
 			disp.Printf("Success. Current function value: %.7g Iterations: %d Function evaluations: %d", fval, iter, fcalls)
 		}
 	}
-	return x, warnflag
+	return res
 }
 
-// Line-search algorithm using fminbound. Find the minimum of the function ``func(x0+ alpha*direc)``.
-func linesearchPowell(
-	fun func([]float64) float64,
-	p, xi []float64,
-	tol float64,
-	fnMaxFev func(int) bool,
-) (float64, []float64, []float64) {
-	type float = float64
-	myfunc := func(alpha float) float {
+// powellLine is the line search of minimizePowell. It is allocated once
+// per run, so that the line searches don't allocate.
+type powellLine struct {
+	fun         func([]float64) float64
+	p, xi, xtmp []float64
+	lm          LineMinimizer
+	fnMaxFev    func(int) bool
+	cmp         Comparator
+	// f is eval, bm the minimizer of lm
+	f  func(float64) float64
+	bm BrentMinimizer
+}
 
-		//return fun(p + alpha*xi)
-		xtmp := make([]float, len(p))
-		for i, p1 := range p {
-			xtmp[i] = p1 + alpha*xi[i]
-		}
-		return fun(xtmp)
-	}
+// eval returns fun(p + alpha*xi)
+func (ls *powellLine) eval(alpha float64) float64 {
+	floats.AddScaledTo(ls.xtmp, ls.p, alpha, ls.xi)
+	return ls.fun(ls.xtmp)
+}
 
-	alphaMin, fret, _, _ := NewBrentMinimizer(myfunc, tol, 500, fnMaxFev).Optimize()
+// search is the line search of LineMinimizer. Find the minimum of the function ``func(x0+ alpha*direc)``.
+// fp is fun(p). p is moved to the minimum, unless cmp is not nil and
+// doesn't confirm the improvement.
+func (ls *powellLine) search(p, xi []float64, fp float64) (float64, []float64, []float64) {
+	ls.p, ls.xi = p, xi
+	alphaMin, fret, _, _ := ls.lm.minimize(&ls.bm, ls.f, ls.fnMaxFev)
 	//xi = alpha_min*xi
 	//return squeeze(fret), p + xi, xi
-	pPlusXi := make([]float, len(p))
-	for i := range p {
-		xi[i] *= alphaMin
-		pPlusXi[i] = p[i] + xi[i]
+	pPlusXi := floats.AddScaledTo(ls.xtmp, p, alphaMin, xi)
+	if ls.cmp != nil && ls.cmp.Compare(pPlusXi, p) >= 0 {
+		return fp, p, xi
 	}
+	copy(p, pPlusXi)
+	floats.Scale(alphaMin, xi)
 
-	return fret, pPlusXi, xi
+	return fret, p, xi
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"math"
 	"os"
+	"testing"
 )
 
 func ExamplePowellMinimizer() {
@@ -20,7 +21,44 @@ func ExamplePowellMinimizer() {
 	)
 	// Output:
 	// [-0.02748 -0.02037]
-	// [0.00818 -0.00407]
-	// [0.00154 -0.00337]
-	// Success. Current function value: -2.718245 Iterations: 3 Function evaluations: 69
+	// [0.00002 -0.00007]
+	// [-0.00000 0.00000]
+	// Success. Current function value: -2.718282 Iterations: 3 Function evaluations: 70
+}
+
+func BenchmarkPowell(b *testing.B) {
+	for _, dim := range []int{10, 100, 200} {
+		b.Run(fmt.Sprint(dim), func(b *testing.B) {
+			// a separable quadratic, cheap beside the kernels of Powell
+			f := func(x []float64) float64 {
+				s := 0.
+				for i, xi := range x {
+					s += float64(i+1) * (xi - 1) * (xi - 1)
+				}
+				return s
+			}
+			x0 := make([]float64, dim)
+			pm := NewPowellMinimizer()
+			pm.Xtol, pm.Ftol = 0, 0
+			for i := 0; i < b.N; i++ {
+				pm.MaxIter, pm.MaxFev = 2, 0
+				pm.Minimize(f, x0)
+			}
+		})
+	}
+}
+
+func BenchmarkPowellLineEval(b *testing.B) {
+	for _, dim := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprint(dim), func(b *testing.B) {
+			p, xi := make([]float64, dim), make([]float64, dim)
+			for i := range p {
+				p[i], xi[i] = float64(i), 1/float64(i+1)
+			}
+			ls := &powellLine{fun: func(x []float64) float64 { return x[0] }, p: p, xi: xi, xtmp: make([]float64, dim)}
+			for i := 0; i < b.N; i++ {
+				ls.eval(.5)
+			}
+		})
+	}
 }
@@ -5,6 +5,7 @@
 package optimize
 
 import (
+	"errors"
 	"math"
 
 	"gonum.org/v1/gonum/optimize"
@@ -21,18 +22,30 @@ type Powell struct {
 	bestX    []float64
 }
 
-// Uses for Powell to implement gonum optimize.Needser
+// Needs for Powell to implement Needser, for gonum versions before Uses
+func (g *Powell) Needs() struct{ Gradient, Hessian bool } {
+	return struct{ Gradient, Hessian bool }{false, false}
+}
+
+// Uses for Powell to implement gonum optimize.Method
 func (g *Powell) Uses(has optimize.Available) (optimize.Available, error) {
 	return optimize.Available{}, nil
 }
 
 // Init for Powell to implement gonum optimize.Method
+// Invalid settings stop the run at once with Status Failure.
 func (g *Powell) Init(dim, tasks int) int {
-	if dim <= 0 {
-		panic(nonpositiveDimension)
+	g.status, g.err = optimize.NotTerminated, nil
+	switch {
+	case dim <= 0:
+		g.err = errors.New(nonpositiveDimension)
+	case tasks < 0:
+		g.err = errors.New(negativeTasks)
+	case g.PM != nil:
+		g.err = g.PM.Validate()
 	}
-	if tasks < 0 {
-		panic(negativeTasks)
+	if g.err != nil {
+		g.status = optimize.Failure
 	}
 	g.bestF = math.Inf(1)
 	g.bestX = resize(g.bestX, dim)
@@ -49,86 +62,68 @@ func (g *Powell) updateMajor(operation chan<- optimize.Task, task optimize.Task)
 	operation <- task
 }
 
-// Run for Powell to implement gonum optimize.Method
+// Run for Powell to implement gonum optimize.Method.
+// Powell runs in the goroutine of Run, each evaluation being a synchronous
+// round trip through operation and result: the run stops at the first
+// PostIteration, the evaluations requested after it returning NaN.
 func (g *Powell) Run(operation chan<- optimize.Task, result <-chan optimize.Task, tasks []optimize.Task) {
-	var stop bool
-	fnMaxIter := func(int) bool { return stop }
-	fnMaxFev := func(int) bool { return stop }
-
+	if g.err != nil {
+		operation <- optimize.Task{Op: optimize.MethodDone, Location: tasks[0].Location}
+		for range result {
+		}
+		close(operation)
+		return
+	}
 	if g.PM == nil {
 		g.PM = NewPowellMinimizer()
 	}
 	pm := g.PM
 
-	result1 := make(chan optimize.Task)
-	// Send initial tasks to evaluate
-
-	dup := func(x []float64) []float64 {
-		r := make([]float64, len(x))
-		copy(r, x)
-		return r
-	}
-	InitX := tasks[0].Location.X
-	go func(id int) {
-		_, warnflag := minimizePowell(func(x []float64) (y float64) {
-			y = math.NaN()
-			defer func() {
-				if r := recover(); r == "send on closed channel" {
-					return
-				}
-			}()
-			operation <- optimize.Task{ID: id, Op: optimize.FuncEvaluation, Location: &optimize.Location{X: dup(x)}}
-			task := <-result1
-			if task.Location != nil {
-				y = task.Location.F
+	// stop is set by PostIteration, closed by the closing of result
+	var stop, closed bool
+	fnStop := func(int) bool { return stop }
+	// receive returns the next result which is not a MajorIteration sent back
+	receive := func() (optimize.Task, bool) {
+		for {
+			task, ok := <-result
+			switch {
+			case !ok:
+				closed = true
+				return task, false
+			case task.Op == optimize.PostIteration:
+				stop = true
+			case task.Op == optimize.FuncEvaluation:
+				return task, true
+			case task.Op != optimize.MajorIteration && task.Op != optimize.NoOperation:
+				panic("unknown operation")
 			}
-			return
-		}, InitX, nil, pm.Xtol, pm.Ftol, fnMaxIter, fnMaxFev, pm.Logger)
-		switch warnflag {
-		case 1:
-			g.status = optimize.FunctionEvaluationLimit
-		case 2:
-			g.status = optimize.IterationLimit
-		default:
-			g.status = optimize.MethodConverge
 		}
-
-		defer func() {
-			if r := recover(); r == "send on closed channel" {
-				return
-			}
-		}()
-		operation <- optimize.Task{ID: id, Op: optimize.MethodDone}
-
-	}(0)
-
-	// Read from the channel until PostIteration is sent.
-Loop:
-	for {
-		task := <-result
-		switch task.Op {
-		default:
-			panic("unknown operation")
-		case optimize.NoOperation, optimize.PostIteration:
-			close(result1)
-			break Loop
-		case optimize.MajorIteration:
-
-		case optimize.FuncEvaluation:
-			result1 <- task
-			g.updateMajor(operation, task)
+	}
+	f := func(x []float64) float64 {
+		if stop {
+			return math.NaN()
 		}
+		xr := append([]float64(nil), x...)
+		if pm.Repair != nil {
+			pm.Repair.Repair(xr)
+		}
+		operation <- optimize.Task{Op: optimize.FuncEvaluation, Location: &optimize.Location{X: xr}}
+		task, ok := receive()
+		if !ok || task.Location == nil {
+			return math.NaN()
+		}
+		g.updateMajor(operation, task)
+		return task.Location.F
 	}
-
-	// PostIteration was sent. Update the best new values.
-	for task := range result {
-		switch task.Op {
-		default:
-			panic("unknown operation")
-		case optimize.MajorIteration:
-		case optimize.FuncEvaluation:
+	res := minimizePowell(f, tasks[0].Location.X, nil, pm.Xtol, pm.Ftol, pm.Tolerances, fnStop, fnStop, pm.Logger, pm.Comparator, nil)
+	g.status = res.Status
+	if !stop {
+		operation <- optimize.Task{Op: optimize.MethodDone}
+	}
+	// Read the last results until result is closed.
+	for !closed {
+		if task, ok := receive(); ok {
 			g.updateMajor(operation, task)
-		case optimize.NoOperation:
 		}
 	}
 	stop = true
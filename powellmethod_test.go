@@ -3,7 +3,9 @@ package optimize
 import (
 	"fmt"
 	"math"
+	"runtime"
 	"testing"
+	"time"
 
 	"gonum.org/v1/gonum/optimize"
 )
@@ -24,18 +26,9 @@ func ExamplePowell_Run() {
 	}
 	fmt.Printf("%s %.5f\n", res.Status, res.X)
 	// Output:
-	// MethodConverge [-0.00033 -0.00317]
+	// MethodConverge [0.00000 -0.00000]
 }
 
-func panics(f func()) (panics bool) {
-	defer func() {
-		if r := recover(); r != nil {
-			panics = true
-		}
-	}()
-	f()
-	return
-}
 func TestPowell(t *testing.T) {
 	has := optimize.Available{}
 	m := &Powell{}
@@ -46,10 +39,44 @@ func TestPowell(t *testing.T) {
 	if uses.Grad || uses.Hess {
 		t.Fail()
 	}
-	if !panics(func() { m.Init(0, 0) }) {
-		t.Fail()
+	for _, c := range [][2]int{{0, 1}, {1, -1}} {
+		m.Init(c[0], c[1])
+		if status, err := m.Status(); status != optimize.Failure || err == nil {
+			t.Errorf("Init(%d, %d): %s %v", c[0], c[1], status, err)
+		}
 	}
-	if !panics(func() { m.Init(1, -1) }) {
-		t.Fail()
+	m.PM = &PowellMinimizer{Xtol: -1}
+	_, err = optimize.Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, nil, m)
+	if err == nil || err.Error() != "optimize: powell: invalid Xtol" {
+		t.Errorf("got %v", err)
+	}
+	if res := m.PM.Minimize(rosen, []float64{-1.2, 1}); res.Status != optimize.Failure || res.Message != "optimize: powell: invalid Xtol" {
+		t.Errorf("got %s %q", res.Status, res.Message)
+	}
+}
+
+func TestPowellRunStop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for _, settings := range []*optimize.Settings{{FuncEvaluations: 37}, {MajorIterations: 10}, {Concurrent: 4}} {
+		nfev := 0
+		problem := optimize.Problem{Func: func(x []float64) float64 { nfev++; return rosen(x) }}
+		res, err := optimize.Minimize(problem, []float64{-1.2, 1}, settings, &Powell{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.FuncEvaluations != nfev || settings.FuncEvaluations > 0 && (res.Status != optimize.FunctionEvaluationLimit || nfev != 37) ||
+			settings.MajorIterations > 0 && res.Status != optimize.IterationLimit {
+			t.Errorf("%+v: %s after %d evaluations, %d counted", settings, res.Status, nfev, res.FuncEvaluations)
+		}
+		if settings.Concurrent > 0 && (res.Status != optimize.MethodConverge || res.F > 1e-10) {
+			t.Errorf("%+v: %s f=%g", settings, res.Status, res.F)
+		}
+	}
+	// the method doesn't leave goroutines behind
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left", n-before)
 	}
 }
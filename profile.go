@@ -0,0 +1,297 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ProfileProblem is a problem of a ProfileExperiment, minimized from X0
+// within the optional bounds Xmin, Xmax
+type ProfileProblem struct {
+	Name       string
+	Func       func([]float64) float64
+	X0         []float64
+	Xmin, Xmax []float64
+}
+
+// ProfileExperiment runs registered algorithms on a set of problems with a
+// budget of evaluations, for the performance profiles of Dolan and Moré and
+// the data profiles of Moré and Wild (Benchmarking derivative-free
+// optimization algorithms, 2009) of ProfileResults, comparing the
+// algorithms on the numbers of evaluations they need to solve the problems
+// to a tolerance.
+type ProfileExperiment struct {
+	// Solvers are the names of registered algorithms, see NewAlgorithm
+	Solvers []string
+	// Options configure the solvers, the budget and the bounds being set by
+	// the experiment
+	Options  []Option
+	Problems []ProfileProblem
+	// Budget is the number of simplex gradients, dim+1 evaluations, of a
+	// run, 100 if 0
+	Budget int
+	// Workers, if > 1, is the number of concurrent runs, the Funcs being
+	// called concurrently
+	Workers int
+}
+
+// ProfilePoint is an improvement of a run: F is the best value after
+// Evals evaluations
+type ProfilePoint struct {
+	Evals int
+	F     float64
+}
+
+// ProfileRun is the run of a solver on a problem
+type ProfileRun struct {
+	Problem, Solver string
+	Dim             int
+	// NFev is the number of evaluations within the budget, and Trace the
+	// improvements of the best value
+	NFev   int
+	Trace  []ProfilePoint
+	Result *Result
+}
+
+// ProfileResults are the runs of a ProfileExperiment
+type ProfileResults struct {
+	Solvers, Problems []string
+	// Runs are the runs of the solvers on the problems, indexed by problem
+	// then solver
+	Runs [][]*ProfileRun
+	// F0 are the values of the problems at X0, and FLow the least values
+	// found by the solvers
+	F0, FLow []float64
+}
+
+// Run runs the solvers on the problems, and returns the first error of a
+// solver
+func (ex *ProfileExperiment) Run() (*ProfileResults, error) {
+	if len(ex.Solvers) == 0 || len(ex.Problems) == 0 {
+		return nil, errors.New("optimize: ProfileExperiment needs Solvers and Problems")
+	}
+	for _, name := range ex.Solvers {
+		if _, ok := LookupAlgorithm(name); !ok {
+			return nil, errors.New("optimize: unknown algorithm " + name)
+		}
+	}
+	budget := ex.Budget
+	if budget <= 0 {
+		budget = 100
+	}
+	np, ns := len(ex.Problems), len(ex.Solvers)
+	pr := &ProfileResults{Solvers: ex.Solvers, Runs: make([][]*ProfileRun, np), F0: make([]float64, np), FLow: make([]float64, np)}
+	for i, p := range ex.Problems {
+		if p.Func == nil || len(p.X0) == 0 {
+			return nil, errors.New("optimize: ProfileProblem " + p.Name + " without Func or X0")
+		}
+		pr.Problems = append(pr.Problems, p.Name)
+		pr.Runs[i] = make([]*ProfileRun, ns)
+		pr.F0[i] = p.Func(p.X0)
+	}
+	var ev *Evaluator
+	if ex.Workers > 1 {
+		ev = NewEvaluator(ex.Workers)
+	}
+	errs := make([]error, np*ns)
+	var mu sync.Mutex
+	ev.Do(np*ns, func(j int) {
+		run, err := ex.run(ex.Problems[j/ns], ex.Solvers[j%ns], budget)
+		mu.Lock()
+		pr.Runs[j/ns][j%ns], errs[j] = run, err
+		mu.Unlock()
+	})
+	for _, err := range errs {
+		if err != nil {
+			return pr, err
+		}
+	}
+	for i, runs := range pr.Runs {
+		pr.FLow[i] = pr.F0[i]
+		for _, run := range runs {
+			if n := len(run.Trace); n > 0 {
+				pr.FLow[i] = math.Min(pr.FLow[i], run.Trace[n-1].F)
+			}
+		}
+	}
+	return pr, nil
+}
+
+// run runs solver on p, ignoring the evaluations beyond the budget
+func (ex *ProfileExperiment) run(p ProfileProblem, solver string, budget int) (*ProfileRun, error) {
+	n := len(p.X0)
+	maxFev := budget * (n + 1)
+	opts := append(append([]Option(nil), ex.Options...), WithMaxFev(maxFev))
+	if p.Xmin != nil || p.Xmax != nil {
+		opts = append(opts, WithBounds(p.Xmin, p.Xmax))
+	}
+	m, err := NewAlgorithm(solver, opts...)
+	if err != nil {
+		return nil, err
+	}
+	run := &ProfileRun{Problem: p.Name, Solver: solver, Dim: n}
+	best := math.Inf(1)
+	f := func(x []float64) float64 {
+		y := p.Func(x)
+		if run.NFev < maxFev {
+			run.NFev++
+			if y < best {
+				best = y
+				run.Trace = append(run.Trace, ProfilePoint{Evals: run.NFev, F: y})
+			}
+		}
+		return y
+	}
+	run.Result = m.Minimize(f, append([]float64(nil), p.X0...))
+	return run, nil
+}
+
+// Evals returns the number of evaluations of the run of solver s on
+// problem p to pass the convergence test of tolerance tau of Moré and Wild
+//
+//	f(x) <= FLow + tau (F0 - FLow)
+//
+// or +Inf if the run fails it
+func (pr *ProfileResults) Evals(p, s int, tau float64) float64 {
+	threshold := pr.FLow[p] + tau*(pr.F0[p]-pr.FLow[p])
+	for _, pt := range pr.Runs[p][s].Trace {
+		if pt.F <= threshold {
+			return float64(pt.Evals)
+		}
+	}
+	return math.Inf(1)
+}
+
+// Profile is a performance or data profile: Y[s][k] is the fraction of the
+// problems solved by Solvers[s] at X[k]
+type Profile struct {
+	Tau     float64
+	Solvers []string
+	X       []float64
+	Y       [][]float64
+}
+
+// PerformanceProfile returns the performance profile of tolerance tau at
+// the ratios alphas >= 1 of the evaluations of a solver on a problem to
+// the least evaluations of the solvers: Y[s][k] is the fraction of the
+// problems solved by solver s within alphas[k] times the evaluations of the
+// best solver. If alphas is nil, they are the ratios of the runs, the
+// steps of the profiles.
+func (pr *ProfileResults) PerformanceProfile(tau float64, alphas []float64) *Profile {
+	ratios := make([][]float64, len(pr.Solvers))
+	for p := range pr.Problems {
+		least := math.Inf(1)
+		for s := range pr.Solvers {
+			least = math.Min(least, pr.Evals(p, s, tau))
+		}
+		for s := range pr.Solvers {
+			ratios[s] = append(ratios[s], pr.Evals(p, s, tau)/least)
+		}
+	}
+	return pr.profile(tau, ratios, alphas)
+}
+
+// DataProfile returns the data profile of tolerance tau at the budgets
+// kappas of simplex gradients: Y[s][k] is the fraction of the problems
+// solved by solver s within kappas[k] (dim+1) evaluations. If kappas is
+// nil, they are the budgets of the runs, the steps of the profiles.
+func (pr *ProfileResults) DataProfile(tau float64, kappas []float64) *Profile {
+	budgets := make([][]float64, len(pr.Solvers))
+	for p := range pr.Problems {
+		for s := range pr.Solvers {
+			budgets[s] = append(budgets[s], pr.Evals(p, s, tau)/float64(pr.Runs[p][s].Dim+1))
+		}
+	}
+	return pr.profile(tau, budgets, kappas)
+}
+
+// profile returns the profile of the fractions of the values v[s] at most
+// xs, xs defaulting to the finite values
+func (pr *ProfileResults) profile(tau float64, v [][]float64, xs []float64) *Profile {
+	if xs == nil {
+		for _, vs := range v {
+			for _, x := range vs {
+				if !math.IsInf(x, 0) && !math.IsNaN(x) {
+					xs = append(xs, x)
+				}
+			}
+		}
+		sort.Float64s(xs)
+		xs = uniqueSorted(xs)
+	}
+	prof := &Profile{Tau: tau, Solvers: pr.Solvers, X: xs, Y: make([][]float64, len(v))}
+	for s, vs := range v {
+		prof.Y[s] = make([]float64, len(xs))
+		for k, x := range xs {
+			solved := 0
+			for _, vi := range vs {
+				if vi <= x {
+					solved++
+				}
+			}
+			prof.Y[s][k] = float64(solved) / float64(len(vs))
+		}
+	}
+	return prof
+}
+
+func uniqueSorted(xs []float64) []float64 {
+	u := xs[:0]
+	for i, x := range xs {
+		if i == 0 || x != u[len(u)-1] {
+			u = append(u, x)
+		}
+	}
+	return u
+}
+
+// WriteCSV writes the profile with a header x,<solver>...
+func (prof *Profile) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"x"}, prof.Solvers...)); err != nil {
+		return err
+	}
+	for k, x := range prof.X {
+		rec := []string{strconv.FormatFloat(x, 'g', -1, 64)}
+		for s := range prof.Solvers {
+			rec = append(rec, strconv.FormatFloat(prof.Y[s][k], 'g', -1, 64))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes a row per run with a header
+// problem,solver,dim,nfev,f0,flow,fbest,status
+func (pr *ProfileResults) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"problem", "solver", "dim", "nfev", "f0", "flow", "fbest", "status"}); err != nil {
+		return err
+	}
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for p, runs := range pr.Runs {
+		for _, run := range runs {
+			fbest, status := math.NaN(), ""
+			if n := len(run.Trace); n > 0 {
+				fbest = run.Trace[n-1].F
+			}
+			if run.Result != nil {
+				status = run.Result.Status.String()
+			}
+			rec := []string{run.Problem, run.Solver, strconv.Itoa(run.Dim), strconv.Itoa(run.NFev), format(pr.F0[p]), format(pr.FLow[p]), format(fbest), status}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
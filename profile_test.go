@@ -0,0 +1,134 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleProfileExperiment() {
+	var problems []ProfileProblem
+	for _, dim := range []int{2, 4} {
+		for _, p := range VerifyProblems(dim) {
+			problems = append(problems, ProfileProblem{Name: fmt.Sprint(p.Name, dim), Func: p.Func, X0: p.X0})
+		}
+	}
+	ex := &ProfileExperiment{Solvers: []string{"LN_POWELL", "LN_NELDERMEAD"}, Problems: problems, Budget: 200}
+	pr, err := ex.Run()
+	if err != nil {
+		panic(err)
+	}
+	// the fractions of the problems solved to 1e-5 of the initial gap by
+	// budgets of 20, 50 and 200 simplex gradients
+	if err := pr.DataProfile(1e-5, []float64{20, 50, 200}).WriteCSV(os.Stdout); err != nil {
+		panic(err)
+	}
+	// and within 1, 2 and 4 times the evaluations of the best solver
+	if err := pr.PerformanceProfile(1e-5, []float64{1, 2, 4}).WriteCSV(os.Stdout); err != nil {
+		panic(err)
+	}
+	// Output:
+	// x,LN_POWELL,LN_NELDERMEAD
+	// 20,0.625,0.375
+	// 50,0.75,0.625
+	// 200,0.875,1
+	// x,LN_POWELL,LN_NELDERMEAD
+	// 1,0.625,0.375
+	// 2,0.75,0.375
+	// 4,0.875,0.5
+}
+
+func TestProfileResults(t *testing.T) {
+	// 2 problems of dimension 1, F0 10 and FLow 0
+	run := func(dim int, pts ...ProfilePoint) *ProfileRun { return &ProfileRun{Dim: dim, Trace: pts} }
+	pr := &ProfileResults{
+		Solvers:  []string{"a", "b"},
+		Problems: []string{"p", "q"},
+		Runs: [][]*ProfileRun{
+			{run(1, ProfilePoint{1, 10}, ProfilePoint{4, 1}, ProfilePoint{8, 0}), run(1, ProfilePoint{1, 10}, ProfilePoint{2, .5})},
+			{run(1, ProfilePoint{1, 10}, ProfilePoint{6, 0}), run(1, ProfilePoint{3, 5})},
+		},
+		F0:   []float64{10, 10},
+		FLow: []float64{0, 0},
+	}
+	for _, c := range []struct {
+		p, s int
+		tau  float64
+		want float64
+	}{
+		{0, 0, .1, 4}, {0, 1, .1, 2}, {0, 0, 0, 8}, {1, 1, .1, math.Inf(1)}, {1, 0, .1, 6}, {1, 1, .5, 3},
+	} {
+		if got := pr.Evals(c.p, c.s, c.tau); got != c.want {
+			t.Errorf("Evals(%d, %d, %g) = %g, want %g", c.p, c.s, c.tau, got, c.want)
+		}
+	}
+	// the ratios are a: 2, 1 and b: 1, +Inf
+	perf := pr.PerformanceProfile(.1, nil)
+	if want := [][]float64{{.5, 1}, {.5, .5}}; !reflect.DeepEqual(perf.X, []float64{1, 2}) || !reflect.DeepEqual(perf.Y, want) {
+		t.Errorf("performance profile %v %v", perf.X, perf.Y)
+	}
+	// the budgets are a: 2, 3 and b: 1, +Inf simplex gradients
+	data := pr.DataProfile(.1, []float64{1, 2, 3})
+	if want := [][]float64{{0, .5, 1}, {.5, .5, .5}}; !reflect.DeepEqual(data.Y, want) {
+		t.Errorf("data profile %v", data.Y)
+	}
+	var b strings.Builder
+	if err := data.WriteCSV(&b); err != nil || b.String() != "x,a,b\n1,0,0.5\n2,0.5,0.5\n3,1,0.5\n" {
+		t.Errorf("WriteCSV: %q %v", b.String(), err)
+	}
+}
+
+func TestProfileExperiment(t *testing.T) {
+	problems := []ProfileProblem{
+		{Name: "sphere", Func: VerifyProblems(2)[0].Func, X0: []float64{-1.2, 1}},
+		{Name: "bounded", Func: VerifyProblems(3)[0].Func, X0: []float64{0, 0, 0}, Xmin: []float64{0, 0, 0}, Xmax: []float64{.5, .5, .5}},
+	}
+	ex := &ProfileExperiment{Solvers: []string{"LN_POWELL", "GN_CMAES"}, Problems: problems, Budget: 20, Options: []Option{WithRNG(rand.NewSource(1))}}
+	seq, err := ex.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, runs := range seq.Runs {
+		for _, run := range runs {
+			if run.NFev == 0 || run.NFev > 20*(run.Dim+1) || run.Result == nil || run.Trace[0].Evals != 1 {
+				t.Errorf("%s %s: %d evaluations, trace %v", run.Problem, run.Solver, run.NFev, run.Trace)
+			}
+			if last := run.Trace[len(run.Trace)-1].F; last < seq.FLow[p] {
+				t.Errorf("%s %s: %g below FLow %g", run.Problem, run.Solver, last, seq.FLow[p])
+			}
+		}
+	}
+	// the bounded minimum is 3 (1-0.5)^2
+	if seq.FLow[1] < .75 {
+		t.Errorf("the bounds are ignored: FLow %g", seq.FLow[1])
+	}
+	var b strings.Builder
+	if err := seq.WriteCSV(&b); err != nil || strings.Count(b.String(), "\n") != 5 || !strings.HasPrefix(b.String(), "problem,solver,dim,nfev,f0,flow,fbest,status\nsphere,LN_POWELL,2,") {
+		t.Errorf("WriteCSV: %q %v", b.String(), err)
+	}
+
+	ex.Solvers = []string{"LN_POWELL", "LN_NELDERMEAD"}
+	ex.Options, ex.Workers = nil, 4
+	par, err := ex.Run()
+	ex.Workers = 0
+	seq, _ = ex.Run()
+	if err != nil || !reflect.DeepEqual(par.FLow, seq.FLow) || !reflect.DeepEqual(par.Runs[0][1].Trace, seq.Runs[0][1].Trace) {
+		t.Errorf("concurrent runs differ: %v %v", par.FLow, seq.FLow)
+	}
+
+	for _, bad := range []*ProfileExperiment{
+		{Problems: problems},
+		{Solvers: []string{"LN_POWELL"}},
+		{Solvers: []string{"XX_UNKNOWN"}, Problems: problems},
+		{Solvers: []string{"LN_POWELL"}, Problems: []ProfileProblem{{Name: "empty"}}},
+	} {
+		if _, err := bad.Run(); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progress is the JSON encoding of the state of a ProgressServer
+type progress struct {
+	// State is "idle" before the first run, then "running" or "done"
+	State       string               `json:"state"`
+	Method      string               `json:"method,omitempty"`
+	Iter        int                  `json:"iter"`
+	NFev        int                  `json:"nfev"`
+	X           []jsonFloat          `json:"x"`
+	BestX       []jsonFloat          `json:"best_x"`
+	BestF       jsonFloat            `json:"best_f"`
+	Diagnostics map[string]jsonFloat `json:"diagnostics,omitempty"`
+	// Elapsed is the duration of the run in seconds
+	Elapsed float64 `json:"elapsed"`
+	Status  string  `json:"status,omitempty"`
+	Message string  `json:"message,omitempty"`
+}
+
+// ProgressServer is an Observer serving the state of the run it observes
+// over HTTP, eg for a browser dashboard watching a long CMA-ES run: a GET
+// returns the current state as JSON, and a GET accepting
+// text/event-stream (an EventSource of the browser) receives it as server
+// sent events at the start, at the iterations and at the end of the runs.
+// The state has the fields state ("idle", "running" or "done"), method,
+// iter, nfev, x (the current iterate), best_x and best_f (the best point
+// evaluated), diagnostics,
+// elapsed in seconds, and the status and message of a run done.
+// Non-finite values are null.
+type ProgressServer struct {
+	// MinInterval is the minimum duration between the events of iterations,
+	// the ones in between being skipped
+	MinInterval time.Duration
+
+	mu    sync.Mutex
+	p     progress
+	best  float64
+	start time.Time
+	sent  time.Time
+	subs  map[chan []byte]struct{}
+}
+
+var (
+	_ Observer     = &ProgressServer{}
+	_ http.Handler = &ProgressServer{}
+)
+
+// NewProgressServer returns a ProgressServer sending at most 10 events per second
+func NewProgressServer() *ProgressServer {
+	return &ProgressServer{MinInterval: 100 * time.Millisecond}
+}
+
+func jsonFloats(x []float64) []jsonFloat {
+	jx := make([]jsonFloat, len(x))
+	for i, xi := range x {
+		jx[i] = jsonFloat(xi)
+	}
+	return jx
+}
+
+// update encodes the state and sends it to the subscribers, ps.mu being locked
+func (ps *ProgressServer) update(force bool) {
+	now := time.Now()
+	if len(ps.subs) == 0 || !force && now.Sub(ps.sent) < ps.MinInterval {
+		return
+	}
+	ps.sent = now
+	data := ps.current()
+	for ch := range ps.subs {
+		// a slow subscriber only gets the last state
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- data
+	}
+}
+
+// OnStart resets the state for a new run
+func (ps *ProgressServer) OnStart(s *Start) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.p = progress{State: "running", Method: s.Method, X: jsonFloats(s.X0), BestX: jsonFloats(s.X0), BestF: jsonFloat(math.Inf(1))}
+	ps.best, ps.start = math.Inf(1), time.Now()
+	ps.update(true)
+}
+
+// OnIteration updates the iterate and the diagnostics
+func (ps *ProgressServer) OnIteration(it *Iteration) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.p.Iter, ps.p.X = it.Iter, jsonFloats(it.X)
+	if it.NFev > ps.p.NFev {
+		ps.p.NFev = it.NFev
+	}
+	if it.F < ps.best {
+		ps.best, ps.p.BestX, ps.p.BestF = it.F, jsonFloats(it.X), jsonFloat(it.F)
+	}
+	ps.p.Diagnostics = nil
+	if len(it.Diagnostics) > 0 {
+		ps.p.Diagnostics = make(map[string]jsonFloat, len(it.Diagnostics))
+		for k, v := range it.Diagnostics {
+			ps.p.Diagnostics[k] = jsonFloat(v)
+		}
+	}
+	ps.update(false)
+}
+
+// OnEvaluation updates the number of evaluations and the best point
+func (ps *ProgressServer) OnEvaluation(e *Evaluation) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.p.NFev = e.N
+	if e.F < ps.best {
+		ps.best, ps.p.BestX, ps.p.BestF = e.F, jsonFloats(e.X), jsonFloat(e.F)
+	}
+}
+
+// OnDone sets the final state
+func (ps *ProgressServer) OnDone(res *Result) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.p.State, ps.p.Iter, ps.p.NFev, ps.p.X = "done", res.NIter, res.NFev, jsonFloats(res.X)
+	ps.p.Status, ps.p.Message, ps.p.Elapsed = res.Status.String(), res.Message, res.Elapsed.Seconds()
+	if res.F <= ps.best {
+		ps.best, ps.p.BestX, ps.p.BestF = res.F, jsonFloats(res.X), jsonFloat(res.F)
+	}
+	ps.update(true)
+}
+
+// current returns the encoding of the current state, ps.mu being locked
+func (ps *ProgressServer) current() []byte {
+	if ps.p.State == "" {
+		ps.p.State = "idle"
+	}
+	if ps.p.State == "running" {
+		ps.p.Elapsed = time.Since(ps.start).Seconds()
+	}
+	data, _ := json.Marshal(&ps.p)
+	return data
+}
+
+// ServeHTTP serves the state as JSON, or as server sent events "progress"
+// if the request accepts text/event-stream, until the client goes away
+func (ps *ProgressServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		ps.mu.Lock()
+		data := ps.current()
+		ps.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan []byte, 1)
+	ps.mu.Lock()
+	if ps.subs == nil {
+		ps.subs = make(map[chan []byte]struct{})
+	}
+	ps.subs[ch] = struct{}{}
+	ch <- ps.current()
+	ps.mu.Unlock()
+	defer func() {
+		ps.mu.Lock()
+		delete(ps.subs, ch)
+		ps.mu.Unlock()
+	}()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for {
+		select {
+		case data := <-ch:
+			if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package optimize
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestProgressServer(t *testing.T) {
+	ps := NewProgressServer()
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	get := func() map[string]interface{} {
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var p map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	if p := get(); p["state"] != "idle" {
+		t.Errorf("unexpected state %v", p)
+	}
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("unexpected Content-Type %s", ct)
+	}
+	events := make(chan map[string]interface{}, 100)
+	go func() {
+		defer close(events)
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			if data := strings.TrimPrefix(sc.Text(), "data: "); data != sc.Text() {
+				var p map[string]interface{}
+				if err := json.Unmarshal([]byte(data), &p); err != nil {
+					t.Error(err)
+				}
+				events <- p
+			}
+		}
+	}()
+	if p := <-events; p["state"] != "idle" {
+		t.Errorf("unexpected first event %v", p)
+	}
+
+	ps.OnStart(&Start{Method: "test", X0: []float64{0, 0}})
+	if p := <-events; p["state"] != "running" || p["method"] != "test" || p["best_f"] != nil {
+		t.Errorf("unexpected start event %v", p)
+	}
+	ps.MinInterval = 0
+	ps.OnIteration(&Iteration{Iter: 1, NFev: 3, X: []float64{1, 2}, F: 5, Diagnostics: map[string]float64{"sigma": .5}})
+	if p := <-events; p["iter"] != 1. || p["nfev"] != 3. || p["best_f"] != 5. || p["diagnostics"].(map[string]interface{})["sigma"] != .5 {
+		t.Errorf("unexpected iteration event %v", p)
+	}
+
+	res, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Method: "spsa", MaxIter: 20, Observer: ps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []string
+	var last map[string]interface{}
+	for p := range events {
+		states = append(states, p["state"].(string))
+		if last = p; p["state"] == "done" {
+			break
+		}
+	}
+	if states[len(states)-1] != "done" || last["status"] != res.Status.String() || last["nfev"] != float64(res.NFev) || !(last["best_f"].(float64) <= res.F) {
+		t.Errorf("unexpected events %v, last %v for %+v", states, last, res)
+	}
+	if p := get(); p["state"] != "done" || p["method"] != "spsa" || len(p["best_x"].([]interface{})) != 2 {
+		t.Errorf("unexpected state %v", p)
+	}
+}
@@ -0,0 +1,116 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Projection projects x in place onto a convex feasible set
+type Projection interface {
+	Project(x []float64)
+}
+
+// ProjectionFunc is an adapter to allow the use of ordinary functions as Projection
+type ProjectionFunc func(x []float64)
+
+// Project calls fn(x)
+func (fn ProjectionFunc) Project(x []float64) { fn(x) }
+
+var (
+	_ Projection = Box{}
+	_ Projection = Ball{}
+	_ Projection = Simplex{}
+	_ Projection = Halfspace{}
+)
+
+// Box is the set Xmin <= x <= Xmax.
+// Xmin and Xmax may be shorter than x, missing components are unbounded.
+type Box struct {
+	Xmin, Xmax []float64
+}
+
+// Project clips x to the box
+func (b Box) Project(x []float64) {
+	for i := range x {
+		if i < len(b.Xmin) && x[i] < b.Xmin[i] {
+			x[i] = b.Xmin[i]
+		}
+		if i < len(b.Xmax) && x[i] > b.Xmax[i] {
+			x[i] = b.Xmax[i]
+		}
+	}
+}
+
+// Ball is the euclidean ball ||x-Center|| <= Radius.
+// a nil Center means origin
+type Ball struct {
+	Center []float64
+	Radius float64
+}
+
+// Project scales x-Center to Radius if it lies outside the ball
+func (b Ball) Project(x []float64) {
+	if b.Center != nil {
+		floats.Sub(x, b.Center)
+	}
+	if nrm := floats.Norm(x, 2); nrm > b.Radius {
+		floats.Scale(b.Radius/nrm, x)
+	}
+	if b.Center != nil {
+		floats.Add(x, b.Center)
+	}
+}
+
+// Simplex is the set x >= 0, sum(x) = Sum. Sum defaults to 1 if 0.
+type Simplex struct {
+	Sum float64
+}
+
+// Project computes the euclidean projection of x onto the simplex
+// see Duchi et al., Efficient Projections onto the l1-Ball for Learning in High Dimensions, 2008
+func (s Simplex) Project(x []float64) {
+	z := s.Sum
+	if z == 0 {
+		z = 1
+	}
+	u := make([]float64, len(x))
+	copy(u, x)
+	sort.Sort(sort.Reverse(sort.Float64Slice(u)))
+	cumsum, theta := 0., 0.
+	for j, uj := range u {
+		cumsum += uj
+		t := (cumsum - z) / float64(j+1)
+		if uj-t > 0 {
+			theta = t
+		}
+	}
+	for i, xi := range x {
+		x[i] = math.Max(xi-theta, 0)
+	}
+}
+
+// Halfspace is the set A.x <= B
+type Halfspace struct {
+	A []float64
+	B float64
+}
+
+// Validate returns an error if the halfspace is empty, A being zero and B
+// negative
+func (h Halfspace) Validate() error {
+	if h.B < 0 && floats.Dot(h.A, h.A) == 0 {
+		return errors.New("optimize: empty Halfspace")
+	}
+	return nil
+}
+
+// Project moves x orthogonally onto the boundary if A.x > B. x is
+// unchanged if A is zero.
+func (h Halfspace) Project(x []float64) {
+	if viol, aa := floats.Dot(h.A, x)-h.B, floats.Dot(h.A, h.A); viol > 0 && aa > 0 {
+		floats.AddScaled(x, -viol/aa, h.A)
+	}
+}
@@ -0,0 +1,66 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleProjection() {
+	for _, p := range []Projection{
+		Box{Xmin: []float64{0, 0}, Xmax: []float64{1, 1}},
+		Ball{Center: []float64{1, 1}, Radius: 1},
+		Simplex{},
+		Halfspace{A: []float64{1, 1}, B: 1},
+	} {
+		x := []float64{2, -1}
+		p.Project(x)
+		fmt.Printf("%T %.4f\n", p, x)
+	}
+	// Output:
+	// optimize.Box [1.0000 0.0000]
+	// optimize.Ball [1.4472 0.1056]
+	// optimize.Simplex [1.0000 0.0000]
+	// optimize.Halfspace [2.0000 -1.0000]
+}
+
+func ExampleCmaEsCholB_projection() {
+	// minimize distance to (1,1) in the unit ball
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			return (x[0]-1)*(x[0]-1) + (x[1]-1)*(x[1]-1)
+		},
+	}
+	method := &CmaEsCholB{Projection: Ball{Radius: 1}, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(problem, []float64{0, 0}, &optimize.Settings{FuncEvaluations: 1000}, method)
+	if err != nil {
+		panic(err)
+	}
+	if math.Abs(res.X[0]-math.Sqrt2/2) > 1e-3 || math.Abs(res.X[1]-math.Sqrt2/2) > 1e-3 {
+		fmt.Printf("%.5f\n", res.X)
+	}
+	// Output:
+}
+
+func TestHalfspace(t *testing.T) {
+	x := []float64{1, 2}
+	if h := (Halfspace{A: []float64{0, 0}, B: 1}); h.Validate() != nil {
+		t.Error("a zero A of nonnegative B is the whole space")
+	} else if h.Project(x); x[0] != 1 || x[1] != 2 {
+		t.Errorf("x should be unchanged, got %v", x)
+	}
+	empty := Halfspace{A: []float64{0, 0}, B: -1}
+	if empty.Validate() == nil {
+		t.Error("expected an error for an empty halfspace")
+	}
+	if empty.Project(x); x[0] != 1 || x[1] != 2 {
+		t.Errorf("x should be unchanged, got %v", x)
+	}
+	method := &CmaEsCholB{Projection: empty}
+	if _, err := optimize.Minimize(optimize.Problem{Func: func(x []float64) float64 { return x[0] }}, []float64{0, 0}, nil, method); err == nil || err.Error() != empty.Validate().Error() {
+		t.Errorf("expected the error of the empty halfspace, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package optimize
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Pruner decides from the intermediate values of an evaluation, eg the
+// validation loss after each epoch of a training, whether to stop it
+// early. Its Prune may be called concurrently.
+type Pruner interface {
+	// Prune returns whether to stop the evaluation id, which reported value
+	// at step
+	Prune(id int, step, value float64) bool
+}
+
+// MedianPruner stops an evaluation whose value at a step is worse than the
+// median of the values reported at the same step by the other evaluations,
+// the median pruning rule of Vizier and Optuna. Evaluations report the
+// same steps, eg epochs. The zero value is ready to use, for one run.
+type MedianPruner struct {
+	// Warmup is the first step of an evaluation that may be pruned
+	Warmup float64
+	// MinReports is the number of values of other evaluations at a step
+	// needed to prune, 5 if 0
+	MinReports int
+
+	mu     sync.Mutex
+	values map[float64]map[int]float64
+}
+
+// Prune for Pruner
+func (mp *MedianPruner) Prune(id int, step, value float64) bool {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.values == nil {
+		mp.values = map[float64]map[int]float64{}
+	}
+	if mp.values[step] == nil {
+		mp.values[step] = map[int]float64{}
+	}
+	mp.values[step][id] = value
+	if step < mp.Warmup {
+		return false
+	}
+	others := make([]float64, 0, len(mp.values[step]))
+	for k, v := range mp.values[step] {
+		if k != id && v == v {
+			others = append(others, v)
+		}
+	}
+	minReports := mp.MinReports
+	if minReports <= 0 {
+		minReports = 5
+	}
+	if len(others) < minReports {
+		return false
+	}
+	sort.Float64s(others)
+	median := others[len(others)/2]
+	if len(others)%2 == 0 {
+		median = (others[len(others)/2-1] + median) / 2
+	}
+	return value != value || value > median
+}
+
+// PrunerFunc is a Pruner function
+type PrunerFunc func(id int, step, value float64) bool
+
+// Prune for Pruner
+func (f PrunerFunc) Prune(id int, step, value float64) bool { return f(id, step, value) }
+
+// Reporter is the handle of an evaluation to report its intermediate
+// values to a Pruner. A nil Reporter never prunes.
+type Reporter struct {
+	// ID identifies the evaluation in the run
+	ID int
+	// Step and Value are the last ones reported
+	Step, Value float64
+	// Pruned tells whether the Pruner stopped the evaluation
+	Pruned bool
+
+	pruner Pruner
+}
+
+// Report records the value at step and returns whether the evaluation
+// should stop, the objective then returning early
+func (r *Reporter) Report(step, value float64) bool {
+	if r == nil {
+		return false
+	}
+	r.Step, r.Value = step, value
+	if !r.Pruned && r.pruner != nil {
+		r.Pruned = r.pruner.Prune(r.ID, step, value)
+	}
+	return r.Pruned
+}
+
+// reported returns the objective of f reporting to pruner, a pruned
+// evaluation having the value +Inf
+func reported(f func(x []float64, r *Reporter) float64, pruner Pruner) func([]float64) float64 {
+	var mu sync.Mutex
+	id := 0
+	return func(x []float64) float64 {
+		mu.Lock()
+		r := &Reporter{ID: id, pruner: pruner, Step: math.NaN(), Value: math.NaN()}
+		id++
+		mu.Unlock()
+		y := f(x, r)
+		if r.Pruned {
+			return math.Inf(1)
+		}
+		return y
+	}
+}
+
+var (
+	_ Pruner = &MedianPruner{}
+	_ Pruner = PrunerFunc(nil)
+)
@@ -0,0 +1,100 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleMedianPruner() {
+	// a random search of trainings of 20 epochs, the trainings worse than
+	// the median after 5 epochs stopped
+	epochs := 0
+	train := func(x []float64, r *Reporter) float64 {
+		loss := 0.
+		for epoch := 1; epoch <= 20; epoch++ {
+			epochs++
+			loss = (x[0]-.3)*(x[0]-.3) + (x[1]-.6)*(x[1]-.6) + 1/float64(epoch)
+			if r.Report(float64(epoch), loss) {
+				break
+			}
+		}
+		return loss
+	}
+	for _, pruner := range []Pruner{nil, &MedianPruner{Warmup: 5}} {
+		epochs = 0
+		qs := NewQuasiRandomSearch([]float64{0, 0}, []float64{1, 1})
+		qs.MaxFev, qs.Pruner, qs.Src = 64, pruner, rand.NewSource(1)
+		res := qs.MinimizeReported(train, []float64{.5, .5})
+		fmt.Printf("%d epochs, best %.2f %.3f\n", epochs, res.X, res.F)
+	}
+	// Output:
+	// 1280 epochs, best [0.34 0.60] 0.052
+	// 559 epochs, best [0.34 0.60] 0.052
+}
+
+func TestMedianPruner(t *testing.T) {
+	mp := &MedianPruner{Warmup: 2, MinReports: 3}
+	for id, v := range []float64{1, 2, 3} {
+		if mp.Prune(id, 2, v) {
+			t.Errorf("evaluation %d pruned before MinReports", id)
+		}
+	}
+	if mp.Prune(3, 1, 10) {
+		t.Error("pruned before Warmup")
+	}
+	for _, c := range []struct {
+		value float64
+		prune bool
+	}{{2, false}, {1.5, false}, {2.5, true}, {math.NaN(), true}} {
+		if got := mp.Prune(4, 2, c.value); got != c.prune {
+			t.Errorf("value %g: pruned %v", c.value, got)
+		}
+	}
+	// the median of an even number of values
+	mp.Prune(5, 2, 4)
+	if mp.Prune(6, 2, 2.5) || !mp.Prune(6, 2, 2.6) {
+		t.Error("the median of 1 2 3 NaN 4 is 2.5")
+	}
+
+	var r *Reporter
+	if r.Report(1, 1) {
+		t.Error("a nil Reporter prunes")
+	}
+	calls := 0
+	r = &Reporter{pruner: PrunerFunc(func(id int, step, value float64) bool { calls++; return step >= 2 })}
+	if r.Report(1, 3) || !r.Report(2, 2) || !r.Report(3, 1) || calls != 2 || r.Step != 3 || r.Value != 1 {
+		t.Errorf("Reporter %+v after %d calls", r, calls)
+	}
+
+	// the evaluations of a search have their own ids, a pruned one +Inf
+	var mu sync.Mutex
+	ids := map[int]bool{}
+	prune := PrunerFunc(func(id int, step, value float64) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		ids[id] = true
+		return id%2 == 1
+	})
+	qs := NewQuasiRandomSearch([]float64{-1}, []float64{1})
+	qs.MaxFev, qs.BatchSize, qs.Evaluator, qs.Pruner = 20, 4, &Evaluator{Workers: 4}, prune
+	var values []float64
+	qs.Observer = ObserverFuncs{Evaluation: func(e *Evaluation) {
+		mu.Lock()
+		values = append(values, e.F)
+		mu.Unlock()
+	}}
+	qs.MinimizeReported(func(x []float64, r *Reporter) float64 { r.Report(1, x[0]); return x[0] }, []float64{0})
+	inf := 0
+	for _, v := range values {
+		if math.IsInf(v, 1) {
+			inf++
+		}
+	}
+	if len(ids) != 20 || inf != 10 {
+		t.Errorf("%d ids, %d pruned evaluations of %v", len(ids), inf, values)
+	}
+}
@@ -0,0 +1,480 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// QuasiRandom is a low-discrepancy sequence of points of the unit cube
+// [0, 1)^Dim, which covers it more evenly than pseudo-random points, for
+// the initial points of population methods, the starts of a multistart and
+// QuasiRandomSearch
+type QuasiRandom interface {
+	Dim() int
+	// Next stores the next point of the sequence in x, of length Dim
+	Next(x []float64)
+}
+
+// QuasiRandomOptions configure a Sobol or Halton sequence
+type QuasiRandomOptions struct {
+	// Skip is the number of first points skipped, eg the origin which is
+	// the first point of the unscrambled sequences
+	Skip int
+	// Leap is the step between the indices of the points, 1 if 0, eg to
+	// split a sequence between Leap workers with Skip 0 to Leap-1
+	Leap int
+	// Scramble randomizes the sequence with Src, which keeps its
+	// uniformity: Sobol by a random linear matrix scrambling and a digital
+	// shift (Matoušek), Halton by random permutations of the digits. if
+	// Src is nil, a source seeded from golang.org/x/exp/rand is used.
+	Scramble bool
+	Src      rand.Source
+}
+
+// skipLeap returns the first index and the step of opts
+func (opts *QuasiRandomOptions) skipLeap() (uint64, uint64) {
+	if opts == nil {
+		return 0, 1
+	}
+	skip, leap := uint64(max(opts.Skip, 0)), uint64(max(opts.Leap, 1))
+	return skip, leap
+}
+
+// scrambler returns the random numbers of the scrambling, nil without it
+func (opts *QuasiRandomOptions) scrambler() *rand.Rand {
+	if opts == nil || !opts.Scramble {
+		return nil
+	}
+	return newRand(opts.Src)
+}
+
+// Sobol is the Sobol sequence, with the direction numbers of Joe and Kuo
+// for the first 21 dimensions. Beyond, the primitive polynomials follow in
+// the same order and the initial direction numbers are drawn from a fixed
+// seed, which keeps the stratification of each coordinate but not the
+// quality of the two-dimensional projections of Joe and Kuo. The sequence
+// has 2^32 points.
+type Sobol struct {
+	v           [][32]uint32
+	shift, x    []uint32
+	index, leap uint64
+	started     bool
+}
+
+// joeKuo are the degree s, the coefficients a and the initial direction
+// numbers m of the dimensions 2 to 21 of new-joe-kuo-6.21201
+var joeKuo = []struct {
+	s, a uint
+	m    []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+}
+
+// NewSobol returns the Sobol sequence in dimension dim >= 1
+func NewSobol(dim int, opts *QuasiRandomOptions) (*Sobol, error) {
+	if dim < 1 {
+		return nil, errors.New("optimize: Sobol dimension must be at least 1")
+	}
+	sb := &Sobol{v: make([][32]uint32, dim), shift: make([]uint32, dim), x: make([]uint32, dim)}
+	for k := range sb.v[0] {
+		sb.v[0][k] = 1 << (31 - k)
+	}
+	polys := primitivePolynomials(dim - 1)
+	extra := rand.New(rand.NewSource(21201))
+	for j := 1; j < dim; j++ {
+		s, a := polys[j-1][0], polys[j-1][1]
+		var m []uint32
+		if j-1 < len(joeKuo) {
+			m = joeKuo[j-1].m
+		} else {
+			m = make([]uint32, s)
+			for k := range m {
+				m[k] = 2*uint32(extra.Intn(1<<k)) + 1
+			}
+		}
+		v := &sb.v[j]
+		for k := 0; k < 32; k++ {
+			if k < int(s) {
+				v[k] = m[k] << (31 - k)
+				continue
+			}
+			v[k] = v[k-int(s)] ^ v[k-int(s)]>>s
+			for i := uint(1); i < s; i++ {
+				if a>>(s-1-i)&1 == 1 {
+					v[k] ^= v[k-int(i)]
+				}
+			}
+		}
+	}
+	if rnd := opts.scrambler(); rnd != nil {
+		for j := range sb.v {
+			// lower triangular matrix of unit diagonal, row b computing the
+			// bit b of the result, from the most significant, from the bits
+			// 0 to b of a direction number
+			var rows [32]uint32
+			for b := range rows {
+				rows[b] = 1<<(31-b) | uint32(rnd.Uint64())&^(1<<(32-b)-1)
+			}
+			for k, vk := range sb.v[j] {
+				var w uint32
+				for b, row := range rows {
+					w |= uint32(bits.OnesCount32(row&vk)&1) << (31 - b)
+				}
+				sb.v[j][k] = w
+			}
+			sb.shift[j] = uint32(rnd.Uint64())
+		}
+	}
+	sb.index, sb.leap = opts.skipLeap()
+	return sb, nil
+}
+
+// Dim for QuasiRandom
+func (sb *Sobol) Dim() int { return len(sb.v) }
+
+// Next for QuasiRandom
+func (sb *Sobol) Next(x []float64) {
+	if sb.started && sb.leap == 1 {
+		// the Gray codes of index-1 and index differ by one bit
+		k := bits.TrailingZeros64(sb.index)
+		for j := range sb.x {
+			sb.x[j] ^= sb.v[j][k%32]
+		}
+	} else {
+		gray := uint32(sb.index ^ sb.index>>1)
+		for j := range sb.x {
+			sb.x[j] = sb.shift[j]
+			for g, k := gray, 0; g != 0; g, k = g>>1, k+1 {
+				if g&1 == 1 {
+					sb.x[j] ^= sb.v[j][k]
+				}
+			}
+		}
+	}
+	for j, xj := range sb.x {
+		x[j] = float64(xj) / (1 << 32)
+	}
+	sb.started = true
+	sb.index += sb.leap
+}
+
+// primitivePolynomials returns the degree and the coefficients a of the n
+// first primitive polynomials over GF(2), by degree and a, in the encoding
+// of Joe and Kuo: x^s + a_1 x^(s-1) + ... + a_(s-1) x + 1 has a of bits
+// a_1 ... a_(s-1)
+func primitivePolynomials(n int) [][2]uint {
+	var polys [][2]uint
+	for s := uint(1); len(polys) < n; s++ {
+		for a := uint(0); a < 1<<(s-1) && len(polys) < n; a++ {
+			if isPrimitive(uint64(1)<<s|uint64(a)<<1|1, s) {
+				polys = append(polys, [2]uint{s, a})
+			}
+		}
+	}
+	return polys
+}
+
+// isPrimitive returns whether the polynomial p of degree s < 32 is
+// primitive: x is of order 2^s-1 modulo p
+func isPrimitive(p uint64, s uint) bool {
+	order := uint64(1)<<s - 1
+	mulmod := func(a, b uint64) uint64 {
+		var r uint64
+		for ; b != 0; b >>= 1 {
+			if b&1 == 1 {
+				r ^= a
+			}
+			if a <<= 1; a>>s&1 == 1 {
+				a ^= p
+			}
+		}
+		return r
+	}
+	powx := func(e uint64) uint64 {
+		r, b := uint64(1), uint64(2)
+		if s == 1 {
+			b = 1
+		}
+		for ; e != 0; e >>= 1 {
+			if e&1 == 1 {
+				r = mulmod(r, b)
+			}
+			b = mulmod(b, b)
+		}
+		return r
+	}
+	if powx(order) != 1 {
+		return false
+	}
+	for q, rest := uint64(2), order; rest > 1; q++ {
+		if q*q > rest {
+			q = rest
+		}
+		if rest%q != 0 {
+			continue
+		}
+		if powx(order/q) == 1 {
+			return false
+		}
+		for rest%q == 0 {
+			rest /= q
+		}
+	}
+	return true
+}
+
+// Halton is the Halton sequence, the radical inverses of the index in the
+// bases of the first Dim primes. Its uniformity degrades in high
+// dimensions where the bases are large, unless scrambled.
+type Halton struct {
+	bases       []uint64
+	perms       [][][]uint64
+	index, leap uint64
+}
+
+// NewHalton returns the Halton sequence in dimension dim >= 1
+func NewHalton(dim int, opts *QuasiRandomOptions) (*Halton, error) {
+	if dim < 1 {
+		return nil, errors.New("optimize: Halton dimension must be at least 1")
+	}
+	h := &Halton{}
+	for p := uint64(2); len(h.bases) < dim; p++ {
+		prime := true
+		for _, b := range h.bases {
+			if b*b > p {
+				break
+			}
+			prime = prime && p%b != 0
+		}
+		if prime {
+			h.bases = append(h.bases, p)
+		}
+	}
+	if rnd := opts.scrambler(); rnd != nil {
+		// a random permutation of the digits for each of the digits of
+		// float64 precision
+		h.perms = make([][][]uint64, dim)
+		for j, b := range h.bases {
+			h.perms[j] = make([][]uint64, int(math.Ceil(53/math.Log2(float64(b)))))
+			for k := range h.perms[j] {
+				h.perms[j][k] = make([]uint64, b)
+				for d, pd := range rnd.Perm(int(b)) {
+					h.perms[j][k][d] = uint64(pd)
+				}
+			}
+		}
+	}
+	h.index, h.leap = opts.skipLeap()
+	return h, nil
+}
+
+// Dim for QuasiRandom
+func (h *Halton) Dim() int { return len(h.bases) }
+
+// Next for QuasiRandom
+func (h *Halton) Next(x []float64) {
+	for j, b := range h.bases {
+		r, f := 0., 1/float64(b)
+		if h.perms == nil {
+			for i := h.index; i > 0; i /= b {
+				r += f * float64(i%b)
+				f /= float64(b)
+			}
+		} else {
+			i := h.index
+			for _, perm := range h.perms[j] {
+				r += f * float64(perm[i%b])
+				i /= b
+				f /= float64(b)
+			}
+			r = math.Min(r, math.Nextafter(1, 0))
+		}
+		x[j] = r
+	}
+	h.index += h.leap
+}
+
+// QuasiRandomPoints returns the n next points of seq scaled to the box of
+// xmin and xmax, eg the initial population of a population method
+func QuasiRandomPoints(seq QuasiRandom, n int, xmin, xmax []float64) [][]float64 {
+	xs := make([][]float64, n)
+	for k := range xs {
+		x := make([]float64, seq.Dim())
+		seq.Next(x)
+		for i := range x {
+			x[i] = xmin[i] + x[i]*(xmax[i]-xmin[i])
+		}
+		xs[k] = x
+	}
+	return xs
+}
+
+// QuasiRandomSearch evaluates the points of a QuasiRandom sequence of the
+// box and returns the best one, the quasi-random counterpart of a random
+// search: a baseline of the global methods, or the first stage of a
+// multistart.
+type QuasiRandomSearch struct {
+	// Xmin, Xmax are the bounds of the search, required and finite
+	Xmin, Xmax []float64
+	// Sequence generates the points, a Sobol sequence scrambled with Src if
+	// nil. Its Dim must be the dimension.
+	Sequence QuasiRandom
+	// Design, if not nil, is the sampling plan of the MaxFev-1 points after
+	// x0 instead of Sequence, eg an OrthogonalSampling for a screening
+	// experiment, a centered StratifiedSampling for a grid search or
+	// UniformInit for a random search
+	Design Initializer
+	Src    rand.Source
+	// MaxFev is the number of evaluations, x0 included, 1000 if 0
+	MaxFev int
+	// BatchSize is the number of points of an iteration, evaluated together
+	// by the Evaluator, 1 if 0
+	BatchSize int
+	// TargetF, if not nil, stops the search with Status FunctionThreshold at
+	// the first point where f <= *TargetF
+	TargetF *float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of each iteration
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run
+	Observer Observer
+	// Evaluator, if not nil, evaluates the points of an iteration in
+	// parallel, or in one call of its Batch. An evaluation error stops the
+	// search with Status Failure.
+	Evaluator *Evaluator
+	// Pruner stops early the evaluations of MinimizeReported, if not nil
+	Pruner Pruner
+}
+
+// NewQuasiRandomSearch returns a QuasiRandomSearch of the box
+func NewQuasiRandomSearch(xmin, xmax []float64) *QuasiRandomSearch {
+	return &QuasiRandomSearch{Xmin: xmin, Xmax: xmax, MaxFev: 1000}
+}
+
+// Minimize evaluates x0, projected on the bounds with a note in Warnings,
+// and the points of the sequence until MaxFev evaluations. Status is
+// FunctionEvaluationLimit, or Failure with a Message for missing bounds or
+// a Sequence of another dimension.
+func (qs *QuasiRandomSearch) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{}
+	fail := func(msg string) *Result {
+		res.X, res.F, res.Status, res.Message = append([]float64(nil), x0...), math.NaN(), optimize.Failure, msg
+		return res.done(start)
+	}
+	n := len(x0)
+	if len(qs.Xmin) != n || len(qs.Xmax) != n {
+		return fail("optimize: QuasiRandomSearch needs bounds of the dimension")
+	}
+	for i := range qs.Xmin {
+		if math.IsInf(qs.Xmin[i], 0) || math.IsInf(qs.Xmax[i], 0) {
+			return fail("optimize: QuasiRandomSearch needs finite bounds")
+		}
+	}
+	x0, note, err := feasibleStart(x0, qs.Xmin, qs.Xmax, nil)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if note != "" {
+		res.Warnings = []string{note}
+	}
+	maxFev, batchSize := qs.MaxFev, max(qs.BatchSize, 1)
+	if maxFev <= 0 {
+		maxFev = 1000
+	}
+	seq := qs.Sequence
+	var design [][]float64
+	switch {
+	case qs.Design != nil:
+		if design, err = qs.Design.Init(maxFev-1, x0, qs.Xmin, qs.Xmax, newRand(qs.Src)); err != nil {
+			return fail(err.Error())
+		}
+	case seq == nil:
+		if seq, err = NewSobol(n, &QuasiRandomOptions{Scramble: true, Src: qs.Src}); err != nil {
+			return fail(err.Error())
+		}
+	case seq.Dim() != n:
+		return fail("optimize: QuasiRandomSearch Sequence of another dimension")
+	}
+	tw := &targetWatch{target: qs.TargetF}
+	st := newMonitor(qs.Stop, qs.Observer, "quasirandom", x0)
+	rp := newReplay(qs.Evaluator, f, nil)
+	f = st.wrap(tw.wrap(rp.fn))
+
+	res.X, res.F = append([]float64(nil), x0...), f(x0)
+	res.NFev = 1
+	if res.F != res.F {
+		res.F = math.Inf(1)
+	}
+	for res.NFev < maxFev && !tw.reached {
+		var xs [][]float64
+		if size := min(batchSize, maxFev-res.NFev); design != nil {
+			if len(design) == 0 {
+				break
+			}
+			size = min(size, len(design))
+			xs, design = design[:size], design[size:]
+		} else {
+			xs = QuasiRandomPoints(seq, size, qs.Xmin, qs.Xmax)
+		}
+		ys := rp.eval(f, xs, nil)
+		res.NFev += len(xs)
+		res.NIter++
+		if rp.err != nil {
+			res.Status, res.Message = optimize.Failure, rp.err.Error()
+			break
+		}
+		for k, y := range ys {
+			if y < res.F {
+				res.X, res.F = xs[k], y
+			}
+		}
+		if st.iterate(res.NIter, res.NFev, res.X, nil) {
+			break
+		}
+	}
+	st.apply(res)
+	tw.apply(res)
+	if res.Status == optimize.NotTerminated {
+		res.Status = optimize.FunctionEvaluationLimit
+	}
+	rp.stats(&res.Evals)
+	return st.done(res.done(start))
+}
+
+// MinimizeReported is Minimize of an objective reporting its intermediate
+// values to the Pruner, eg the loss after each epoch of a training, and
+// returning early when pruned, a pruned evaluation having the value +Inf.
+// The evaluations of the Batch of an Evaluator do not report.
+func (qs *QuasiRandomSearch) MinimizeReported(f func(x []float64, r *Reporter) float64, x0 []float64) *Result {
+	return qs.Minimize(reported(f, qs.Pruner), x0)
+}
+
+var (
+	_ Minimizer   = &QuasiRandomSearch{}
+	_ QuasiRandom = &Sobol{}
+	_ QuasiRandom = &Halton{}
+)
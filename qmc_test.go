@@ -0,0 +1,240 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/optimize/testfunctions"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleSobol() {
+	sobol, _ := NewSobol(2, nil)
+	halton, _ := NewHalton(2, nil)
+	x, y := make([]float64, 2), make([]float64, 2)
+	for k := 0; k < 5; k++ {
+		sobol.Next(x)
+		halton.Next(y)
+		fmt.Printf("sobol %.4f halton %.4f\n", x, y)
+	}
+	// Output:
+	// sobol [0.0000 0.0000] halton [0.0000 0.0000]
+	// sobol [0.5000 0.5000] halton [0.5000 0.3333]
+	// sobol [0.7500 0.2500] halton [0.2500 0.6667]
+	// sobol [0.2500 0.7500] halton [0.7500 0.1111]
+	// sobol [0.3750 0.3750] halton [0.1250 0.4444]
+}
+
+func ExampleQuasiRandomSearch() {
+	// the quasi-random baseline on the Branin function, of minimum 0.397887
+	qs := NewQuasiRandomSearch([]float64{-5, 0}, []float64{10, 15})
+	qs.MaxFev, qs.Src = 512, rand.NewSource(1)
+	res := qs.Minimize(testfunctions.Branin.Func, []float64{0, 0})
+	fmt.Printf("%d evaluations, f %.2f\n", res.NFev, res.F)
+	// Output:
+	// 512 evaluations, f 0.69
+}
+
+// elementary returns whether each of the boxes of 2^k1 by 2^k2 slices of
+// the coordinates i and j of xs has the same number of points
+func elementary(xs [][]float64, i, j, k1, k2 int) bool {
+	counts := map[[2]int]int{}
+	for _, x := range xs {
+		counts[[2]int{int(x[i] * float64(int(1)<<k1)), int(x[j] * float64(int(1)<<k2))}]++
+	}
+	want := len(xs) >> (k1 + k2)
+	for _, c := range counts {
+		if c != want {
+			return false
+		}
+	}
+	return len(counts) == 1<<(k1+k2)
+}
+
+func TestSobol(t *testing.T) {
+	// the polynomials of Joe and Kuo, and the numbers of primitive
+	// polynomials of degrees 1 to 10
+	polys := primitivePolynomials(1 + 1 + 2 + 2 + 6 + 6 + 18 + 16 + 48 + 60 + 1)
+	for j, jk := range joeKuo {
+		if polys[j] != [2]uint{jk.s, jk.a} {
+			t.Errorf("polynomial %d: %v, want %d %d", j, polys[j], jk.s, jk.a)
+		}
+	}
+	if last := polys[len(polys)-1]; last[0] != 11 || polys[len(polys)-2][0] != 10 {
+		t.Errorf("%d primitive polynomials up to degree 10", len(polys)-1)
+	}
+
+	for _, scramble := range []bool{false, true} {
+		opts := func(skip, leap int) *QuasiRandomOptions {
+			return &QuasiRandomOptions{Skip: skip, Leap: leap, Scramble: scramble, Src: rand.NewSource(1)}
+		}
+		sb, err := NewSobol(40, opts(0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		xs := QuasiRandomPoints(sb, 1024, make([]float64, 40), ones(40))
+		// each coordinate has a point in each of the 1024 slices, and the
+		// first two coordinates are a (0, 2)-sequence
+		for i := 0; i < 40; i++ {
+			if !elementary(xs, i, i, 10, 0) {
+				t.Errorf("scramble %v: coordinate %d not stratified", scramble, i)
+			}
+		}
+		for k := 0; k <= 10; k++ {
+			if !elementary(xs, 0, 1, k, 10-k) {
+				t.Errorf("scramble %v: coordinates 0 and 1 not a net for %d by %d slices", scramble, 1<<k, 1<<(10-k))
+			}
+		}
+		// skip and leap
+		sb, _ = NewSobol(40, opts(3, 5))
+		for _, k := range []int{3, 8, 13, 18} {
+			x := make([]float64, 40)
+			sb.Next(x)
+			for i := range x {
+				if x[i] != xs[k][i] {
+					t.Fatalf("scramble %v: skip 3 leap 5 point %v, want %d", scramble, x[:3], k)
+				}
+			}
+		}
+	}
+	if _, err := NewSobol(0, nil); err == nil {
+		t.Error("expected an error in dimension 0")
+	}
+}
+
+func ones(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1
+	}
+	return x
+}
+
+func TestHalton(t *testing.T) {
+	// the integral of prod(1+(x_i-.5)) over the unit cube of dimension 8,
+	// 1, estimated with 4096 points
+	integral := func(seq QuasiRandom) float64 {
+		s := 0.
+		for _, x := range QuasiRandomPoints(seq, 4096, make([]float64, 8), ones(8)) {
+			p := 1.
+			for _, xi := range x {
+				if xi < 0 || xi >= 1 {
+					t.Fatalf("point %v outside of the unit cube", x)
+				}
+				p *= 1 + (xi - .5)
+			}
+			s += p
+		}
+		return s / 4096
+	}
+	for _, scramble := range []bool{false, true} {
+		opts := func(skip, leap int) *QuasiRandomOptions {
+			return &QuasiRandomOptions{Skip: skip, Leap: leap, Scramble: scramble, Src: rand.NewSource(1)}
+		}
+		h, err := NewHalton(8, opts(0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb, _ := NewSobol(8, opts(0, 0))
+		for name, seq := range map[string]QuasiRandom{"halton": h, "sobol": sb} {
+			tol := 2e-3
+			if name == "halton" && !scramble {
+				// the correlated coordinates of the large bases
+				tol = 1e-2
+			}
+			if v := integral(seq); math.Abs(v-1) > tol {
+				t.Errorf("%s scramble %v: integral %g, want 1", name, scramble, v)
+			}
+		}
+		h, _ = NewHalton(8, opts(0, 0))
+		xs := QuasiRandomPoints(h, 20, make([]float64, 8), ones(8))
+		h, _ = NewHalton(8, opts(2, 3))
+		for _, k := range []int{2, 5, 8} {
+			x := make([]float64, 8)
+			if h.Next(x); x[7] != xs[k][7] {
+				t.Errorf("scramble %v: skip 2 leap 3 point %v, want %v", scramble, x, xs[k])
+			}
+		}
+	}
+	if h, _ := NewHalton(5, nil); h.Dim() != 5 || h.bases[4] != 11 {
+		t.Errorf("bases %v", h.bases)
+	}
+	if _, err := NewHalton(0, nil); err == nil {
+		t.Error("expected an error in dimension 0")
+	}
+}
+
+func TestQuasiRandomSearch(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	xmin, xmax := []float64{-3, -3}, []float64{3, 3}
+	seq := func() QuasiRandom { h, _ := NewHalton(2, &QuasiRandomOptions{Skip: 1}); return h }
+	qs := &QuasiRandomSearch{Xmin: xmin, Xmax: xmax, Sequence: seq(), MaxFev: 100, BatchSize: 8}
+	res := qs.Minimize(f, []float64{4, 0})
+	if res.Status != optimize.FunctionEvaluationLimit || res.NFev != 100 || res.NIter != 13 || len(res.Warnings) != 1 || res.F > .1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	// in parallel, the same points
+	qs.Sequence, qs.Evaluator = seq(), NewEvaluator(4)
+	if par := qs.Minimize(f, []float64{4, 0}); par.F != res.F || par.X[0] != res.X[0] || par.Evals.Batches != 13 {
+		t.Errorf("parallel result %+v, want %+v", par, res)
+	}
+	target := .5
+	qs.Sequence, qs.Evaluator, qs.TargetF = seq(), nil, &target
+	if r := qs.Minimize(f, []float64{0, 0}); r.Status != optimize.FunctionThreshold || r.F > target || r.NFev >= 100 {
+		t.Errorf("target: %+v", r)
+	}
+
+	// a random search of a Design
+	qs = &QuasiRandomSearch{Xmin: xmin, Xmax: xmax, Design: UniformInit{}, MaxFev: 50, BatchSize: 10, Src: rand.NewSource(1)}
+	if r := qs.Minimize(f, []float64{0, 0}); r.NFev != 50 || r.NIter != 5 || r.F > .5 {
+		t.Errorf("Design: %+v", r)
+	}
+	short := InitializerFunc(func(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+		return [][]float64{{1, -2}}, nil
+	})
+	if r := (&QuasiRandomSearch{Xmin: xmin, Xmax: xmax, Design: short}).Minimize(f, []float64{0, 0}); r.NFev != 2 || r.F != 0 {
+		t.Errorf("Design of one point: %+v", r)
+	}
+
+	for _, bad := range []*QuasiRandomSearch{
+		{Xmin: xmin, Xmax: xmax, Design: StratifiedSampling{Strata: []int{2, 2, 2}}},
+		{Xmin: xmin},
+		{Xmin: xmin, Xmax: []float64{3, math.Inf(1)}},
+		{Xmin: xmin, Xmax: xmax, Sequence: func() QuasiRandom { h, _ := NewHalton(3, nil); return h }()},
+	} {
+		if r := bad.Minimize(f, []float64{0, 0}); r.Status != optimize.Failure || r.Message == "" {
+			t.Errorf("expected a failure for %+v: %+v", bad, r)
+		}
+	}
+	// no Sobol sequence of dimension 0
+	if r := (&QuasiRandomSearch{Xmin: []float64{}, Xmax: []float64{}}).Minimize(f, []float64{}); r.Status != optimize.Failure || r.Message == "" {
+		t.Errorf("expected a failure in dimension 0: %+v", r)
+	}
+
+	// restarts from the points of a Sobol sequence
+	rs := &Restarts{
+		Minimizer: NewPowellMinimizer(),
+		Policy:    &QuasiRandomRestarts{RestartLimit: RestartLimit{MaxRestarts: 30}, Xmin: []float64{-5, -5}, Xmax: []float64{5, 5}},
+		Src:       rand.NewSource(1),
+	}
+	r, err := rs.Minimize(rastrigin, []float64{3.2, -2.1})
+	if err != nil || r.F > 1e-6 || len(r.Extra.([]*Result)) != 31 {
+		t.Errorf("QuasiRandomRestarts: %+v %v", r, err)
+	}
+	for _, qr := range []*QuasiRandomRestarts{
+		{RestartLimit: RestartLimit{MaxRestarts: 3}},
+		{RestartLimit: RestartLimit{MaxRestarts: 3}, Xmin: []float64{-5, -5}, Xmax: []float64{5, math.Inf(1)}},
+		{RestartLimit: RestartLimit{MaxRestarts: 3}, Xmin: []float64{-5, -5}, Xmax: []float64{5, 5}, Sequence: func() QuasiRandom { h, _ := NewHalton(3, nil); return h }()},
+	} {
+		rs.Policy = qr
+		empty := qr.Sequence == nil
+		if r, err := rs.Minimize(rastrigin, []float64{3.2, -2.1}); err == nil || len(r.Extra.([]*Result)) != 1 {
+			t.Errorf("%+v: %v %v", qr, r, err)
+		}
+		if empty && qr.Sequence != nil {
+			t.Errorf("%+v: a Sequence without bounds", qr)
+		}
+	}
+}
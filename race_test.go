@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package optimize
+
+func init() {
+	raceEnabled = true
+}
@@ -0,0 +1,122 @@
+package optimize
+
+import (
+	"sort"
+)
+
+// Ranker ranks a population. Rank returns the indices of xs, best first.
+// fs are the values already known for xs, and keep is the number of best
+// candidates the caller needs to identify.
+type Ranker interface {
+	Rank(xs [][]float64, fs []float64, keep int) []int
+}
+
+// Racing ranks candidates of a noisy objective by re-evaluating them in rounds
+// and dropping those that are statistically worse than the best one, so that the
+// evaluation budget goes to close contenders (see Birattari et al., A Racing
+// Algorithm for Configuring Metaheuristics, 2002).
+type Racing struct {
+	Func func([]float64) float64
+	// CRN, if not nil, gets a new seed at each round, so that candidates of a
+	// round are compared with common random numbers. Func should then be CRN.Eval.
+	CRN *CommonRandomNumbers
+	// MinRounds is the number of rounds before eliminating candidates. defaults to 3
+	MinRounds int
+	// MaxEvaluations is the budget of one Rank call. defaults to 10 per candidate
+	MaxEvaluations int
+	// Z is the normal quantile of confidence intervals. defaults to 1.96
+	Z float64
+
+	// Evaluator, if not nil, evaluates the candidates of a round in
+	// parallel, or in one call of its Batch
+	Evaluator *Evaluator
+
+	// Evaluations is the total number of evaluations. CmaEsCholB adds the
+	// ones of its Ranker to its RankEvaluations and MaxFev.
+	Evaluations int
+	// Err is the first error of Evaluator
+	Err error
+}
+
+var _ Ranker = &Racing{}
+
+func (rc *Racing) evaluations() int {
+	return rc.Evaluations
+}
+
+// Rank races xs until keep candidates remain or the budget is spent.
+// values in fs, if not NaN, are used as first observations.
+func (rc *Racing) Rank(xs [][]float64, fs []float64, keep int) []int {
+	n := len(xs)
+	minRounds, budget, z := rc.MinRounds, rc.MaxEvaluations, rc.Z
+	if minRounds <= 0 {
+		minRounds = 3
+	}
+	if budget <= 0 {
+		budget = 10 * n
+	}
+	if z <= 0 {
+		z = 1.96
+	}
+	if keep <= 0 {
+		keep = 1
+	}
+	stats := make([]SampleStats, n)
+	for i := range stats {
+		if i < len(fs) && fs[i] == fs[i] {
+			stats[i].add(fs[i])
+		}
+	}
+	alive := make([]int, n)
+	for i := range alive {
+		alive[i] = i
+	}
+	var eliminated []int
+	spent := 0
+	var pts [][]float64
+	var ys []float64
+	for round := 0; len(alive) > keep && spent+len(alive) <= budget; round++ {
+		if rc.CRN != nil {
+			rc.CRN.NextSeed()
+		}
+		pts = pts[:0]
+		for _, i := range alive {
+			pts = append(pts, xs[i])
+		}
+		var err error
+		if ys, err = rc.Evaluator.Eval(rc.Func, pts, ys[:min(len(pts), cap(ys))]); err != nil && rc.Err == nil {
+			rc.Err = err
+		}
+		for k, i := range alive {
+			stats[i].add(ys[k])
+		}
+		spent += len(alive)
+		if round+1 < minRounds {
+			continue
+		}
+		best := alive[0]
+		for _, i := range alive {
+			if stats[i].Mean < stats[best].Mean {
+				best = i
+			}
+		}
+		upper := stats[best].Mean + stats[best].HalfWidth(z)
+		// eliminate worst first so that at least keep candidates survive
+		sort.SliceStable(alive, func(a, b int) bool { return stats[alive[a]].Mean < stats[alive[b]].Mean })
+		for len(alive) > keep {
+			i := alive[len(alive)-1]
+			if stats[i].Mean-stats[i].HalfWidth(z) <= upper {
+				break
+			}
+			eliminated = append(eliminated, i)
+			alive = alive[:len(alive)-1]
+		}
+	}
+	rc.Evaluations += spent
+	sort.SliceStable(alive, func(a, b int) bool { return stats[alive[a]].Mean < stats[alive[b]].Mean })
+	// eliminated candidates are ranked in reverse elimination order
+	for k := len(eliminated) - 1; k >= 0; k-- {
+		alive = append(alive, eliminated[k])
+	}
+	return alive
+}
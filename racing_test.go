@@ -0,0 +1,57 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleRacing() {
+	noise := rand.New(rand.NewSource(1))
+	f := func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] + .05*noise.NormFloat64() }
+	racing := &Racing{Func: f}
+	method := &CmaEsCholB{Ranker: racing, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{1, 1}, &optimize.Settings{FuncEvaluations: 300}, method)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(math.Abs(res.X[0]) < .2 && math.Abs(res.X[1]) < .2)
+	// Output:
+	// true
+}
+
+func TestRacing(t *testing.T) {
+	noise := rand.New(rand.NewSource(1))
+	racing := &Racing{Func: func(x []float64) float64 { return x[0] + .1*noise.NormFloat64() }, MaxEvaluations: 200}
+	xs := [][]float64{{5}, {0}, {3}, {.05}, {10}}
+	ranks := racing.Rank(xs, nil, 2)
+	if ranks[0] != 1 && ranks[0] != 3 || ranks[1] != 1 && ranks[1] != 3 {
+		t.Errorf("bad ranking %v", ranks)
+	}
+	if ranks[2] != 2 || ranks[3] != 0 || ranks[4] != 4 {
+		t.Errorf("bad ranking of eliminated %v", ranks)
+	}
+	if racing.Evaluations >= 200 {
+		t.Errorf("racing should have saved evaluations, used %d", racing.Evaluations)
+	}
+}
+
+func TestRacingBudget(t *testing.T) {
+	noise := rand.New(rand.NewSource(1))
+	f := func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] + .05*noise.NormFloat64() }
+	racing := &Racing{Func: f}
+	method := &CmaEsCholB{Ranker: racing, Src: rand.NewSource(1), MaxFev: 300}
+	res, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{1, 1}, nil, method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method.RankEvaluations() != racing.Evaluations || racing.Evaluations == 0 {
+		t.Errorf("expected %d evaluations of the ranker, got %d", racing.Evaluations, method.RankEvaluations())
+	}
+	if n := res.Stats.FuncEvaluations + racing.Evaluations; res.Status != optimize.FunctionEvaluationLimit || n < 300 {
+		t.Errorf("expected the evaluations of the ranker in MaxFev, got %v %d", res.Status, n)
+	}
+}
@@ -0,0 +1,100 @@
+package optimize
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// StopReason is the criterion which stopped a run, more specific than its
+// Status: eg FunctionConvergence is the Status of both ReasonTolFun and
+// ReasonStagnation.
+type StopReason int
+
+const (
+	// ReasonUnknown is the reason of a run stopped by an unknown criterion
+	ReasonUnknown StopReason = iota
+	// ReasonTolFun is the decrease of f of an iteration below its tolerance
+	ReasonTolFun
+	// ReasonTolX is the step of an iteration below its tolerance
+	ReasonTolX
+	// ReasonTolGrad is the norm of the gradient below its threshold
+	ReasonTolGrad
+	// ReasonTolLogDet is the log determinant of the covariance of CmaEsCholB
+	// below StopLogDet: its samples are almost the same
+	ReasonTolLogDet
+	// ReasonConditionCov is the condition number of the covariance of
+	// CmaEsCholB above MaxCondition
+	ReasonConditionCov
+	// ReasonTarget is TargetF reached
+	ReasonTarget
+	// ReasonStagnation is the best value not decreasing over
+	// StallIterations iterations
+	ReasonStagnation
+	// ReasonMaxIter is the limit of iterations reached
+	ReasonMaxIter
+	// ReasonMaxFev is the limit of evaluations reached
+	ReasonMaxFev
+	// ReasonTimeLimit is the limit of time reached
+	ReasonTimeLimit
+	// ReasonStopCondition is the StopCondition of the run
+	ReasonStopCondition
+	// ReasonFailure is an error of the method
+	ReasonFailure
+)
+
+var stopReasons = []struct{ name, message string }{
+	{"Unknown", ""},
+	{"TolFun", "the decrease of the function value is below the tolerance"},
+	{"TolX", "the step is below the tolerance"},
+	{"TolGrad", "the norm of the gradient is below the threshold"},
+	{"TolLogDet", "the sampling distribution has collapsed"},
+	{"ConditionCov", "the covariance of the sampling distribution is ill conditioned"},
+	{"Target", "the target function value is reached"},
+	{"Stagnation", "the best function value has not decreased over the last iterations"},
+	{"MaxIter", "the maximum number of iterations is reached"},
+	{"MaxFev", "the maximum number of evaluations is reached"},
+	{"TimeLimit", "the time limit is reached"},
+	{"StopCondition", "the stop condition is met"},
+	{"Failure", "the method failed"},
+}
+
+func (r StopReason) String() string {
+	if r < 0 || int(r) >= len(stopReasons) {
+		return fmt.Sprintf("StopReason(%d)", int(r))
+	}
+	return stopReasons[r].name
+}
+
+// Message returns the description of r, empty for ReasonUnknown
+func (r StopReason) Message() string {
+	if r < 0 || int(r) >= len(stopReasons) {
+		return ""
+	}
+	return stopReasons[r].message
+}
+
+// reasonOf returns the reason implied by the gonum status s
+func reasonOf(s optimize.Status) StopReason {
+	switch s {
+	case optimize.FunctionThreshold:
+		return ReasonTarget
+	case optimize.FunctionConvergence:
+		return ReasonTolFun
+	case optimize.StepConvergence:
+		return ReasonTolX
+	case optimize.GradientThreshold:
+		return ReasonTolGrad
+	case optimize.IterationLimit:
+		return ReasonMaxIter
+	case optimize.FunctionEvaluationLimit, optimize.GradientEvaluationLimit, optimize.HessianEvaluationLimit:
+		return ReasonMaxFev
+	case optimize.RuntimeLimit:
+		return ReasonTimeLimit
+	case optimize.Failure:
+		return ReasonFailure
+	case ConditionCov:
+		return ReasonConditionCov
+	}
+	return ReasonUnknown
+}
@@ -0,0 +1,67 @@
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleStopReason() {
+	pm := NewPowellMinimizer()
+	res := pm.Minimize(rosen, []float64{-1.2, 1})
+	fmt.Printf("%s %s: %s\n", res.Status, res.Reason, res.Message)
+	pm.MaxIter = 3
+	res = pm.Minimize(rosen, []float64{-1.2, 1})
+	fmt.Printf("%s %s: %s\n", res.Status, res.Reason, res.Message)
+	// Output:
+	// MethodConverge TolFun: the decrease of the function value is below the tolerance
+	// IterationLimit MaxIter: the maximum number of iterations is reached
+}
+
+func TestStopReason(t *testing.T) {
+	pm := NewPowellMinimizer()
+	target := 1.
+	for _, c := range []struct {
+		setup  func()
+		reason StopReason
+	}{
+		{func() {}, ReasonTolFun},
+		{func() { pm.MaxFev = 20 }, ReasonMaxFev},
+		{func() { pm.TargetF = &target }, ReasonTarget},
+		{func() { pm.Stop = StopFunc(func(it *Iteration) bool { return it.Iter == 2 }) }, ReasonStopCondition},
+		{func() { pm.Tolerances = &Tolerances{Xatol: 1e-3, Fatol: -1, Frtol: -1} }, ReasonTolX},
+		{func() { pm.StallIterations, pm.StallTolerance = 1, math.Inf(1) }, ReasonStagnation},
+	} {
+		pm = NewPowellMinimizer()
+		c.setup()
+		if res := pm.Minimize(rosen, []float64{-1.2, 1}); res.Reason != c.reason || res.Message != c.reason.Message() {
+			t.Errorf("got %s %s %q, expected %s", res.Status, res.Reason, res.Message, c.reason)
+		}
+	}
+
+	// CMA-ES reasons, through gonum and Minimize
+	cma := &CmaEsCholB{Src: rand.NewSource(1), StopLogDet: -10}
+	if _, err := optimize.Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, nil, cma); err != nil || cma.Reason() != ReasonTolLogDet {
+		t.Errorf("got %s %v", cma.Reason(), err)
+	}
+	if res, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Method: "cmaes", StallIterations: 3, Src: rand.NewSource(1)}); err != nil || res.Reason != ReasonStagnation {
+		t.Errorf("got %v %v", res, err)
+	}
+	if res, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Method: "neldermead", MaxIter: 5}); err != nil || res.Reason != ReasonMaxIter {
+		t.Errorf("got %v %v", res, err)
+	}
+
+	if s := StopReason(42).String(); s != "StopReason(42)" || StopReason(42).Message() != "" {
+		t.Errorf("got %s", s)
+	}
+	res := &Result{X: []float64{1}, Status: optimize.FunctionConvergence, Reason: ReasonStagnation}
+	data, _ := json.Marshal(res)
+	var got Result
+	if err := json.Unmarshal(data, &got); err != nil || got.Reason != ReasonStagnation {
+		t.Errorf("got %v %v from %s", got.Reason, err, data)
+	}
+}
@@ -0,0 +1,64 @@
+package optimize
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// RecorderObserver is an Observer sending the events of the standalone
+// solvers (PowellMinimizer, BrentMinimizer, SPSA, LatticeSearch, ...) to a
+// gonum optimize.Recorder such as an optimize.Printer: InitIteration at the
+// start, FuncEvaluation, MajorIteration, and PostIteration at the end.
+// Err is the first error returned by the Recorder.
+type RecorderObserver struct {
+	Recorder optimize.Recorder
+	Err      error
+	stats    optimize.Stats
+	start    time.Time
+}
+
+var _ Observer = &RecorderObserver{}
+
+// WithRecorder sets a RecorderObserver sending the events of a solver to r
+func WithRecorder(r optimize.Recorder) Option {
+	return WithObserver(&RecorderObserver{Recorder: r})
+}
+
+func (ro *RecorderObserver) record(x []float64, f float64, op optimize.Operation) {
+	if ro.Err != nil {
+		return
+	}
+	ro.stats.Runtime = time.Since(ro.start)
+	ro.Err = ro.Recorder.Record(&optimize.Location{X: x, F: f}, op, &ro.stats)
+}
+
+// OnStart initializes the Recorder and records an InitIteration
+func (ro *RecorderObserver) OnStart(s *Start) {
+	ro.stats, ro.start = optimize.Stats{}, time.Now()
+	if ro.Err = ro.Recorder.Init(); ro.Err == nil {
+		ro.record(s.X0, math.Inf(1), optimize.InitIteration)
+	}
+}
+
+// OnIteration records a MajorIteration at the current iterate with the best value
+func (ro *RecorderObserver) OnIteration(it *Iteration) {
+	ro.stats.MajorIterations = it.Iter
+	if it.NFev > ro.stats.FuncEvaluations {
+		ro.stats.FuncEvaluations = it.NFev
+	}
+	ro.record(it.X, it.F, optimize.MajorIteration)
+}
+
+// OnEvaluation records a FuncEvaluation
+func (ro *RecorderObserver) OnEvaluation(e *Evaluation) {
+	ro.stats.FuncEvaluations = e.N
+	ro.record(e.X, e.F, optimize.FuncEvaluation)
+}
+
+// OnDone records a PostIteration at the solution
+func (ro *RecorderObserver) OnDone(res *Result) {
+	ro.stats.MajorIterations, ro.stats.FuncEvaluations, ro.stats.GradEvaluations, ro.stats.HessEvaluations = res.NIter, res.NFev, res.NGrad, res.NHess
+	ro.record(res.X, res.F, optimize.PostIteration)
+}
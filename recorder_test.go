@@ -0,0 +1,55 @@
+package optimize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+type opRecorder struct {
+	inits int
+	ops   map[optimize.Operation]int
+}
+
+func (r *opRecorder) Init() error {
+	r.inits++
+	r.ops = make(map[optimize.Operation]int)
+	return nil
+}
+
+func (r *opRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	r.ops[op]++
+	return nil
+}
+
+func TestRecorderObserver(t *testing.T) {
+	rec := &opRecorder{}
+	pm, err := NewPowellMinimizerWith(WithRecorder(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := pm.Minimize(rosen, []float64{-1.2, 1})
+	if rec.inits != 1 || rec.ops[optimize.InitIteration] != 1 || rec.ops[optimize.PostIteration] != 1 ||
+		rec.ops[optimize.MajorIteration] != res.NIter || rec.ops[optimize.FuncEvaluation] != res.NFev {
+		t.Errorf("powell: %v for %d iterations, %d evaluations", rec.ops, res.NIter, res.NFev)
+	}
+
+	bm := NewBrentMinimizer(func(x float64) float64 { return (x - 1) * (x - 1) }, 1e-8, 500, nil)
+	bm.Observer = &RecorderObserver{Recorder: rec}
+	_, _, iter, _ := bm.Optimize()
+	if rec.ops[optimize.MajorIteration] != iter || rec.ops[optimize.FuncEvaluation] == 0 || rec.ops[optimize.PostIteration] != 1 {
+		t.Errorf("brent: %v for %d iterations", rec.ops, iter)
+	}
+
+	// gonum's Printer
+	var buf bytes.Buffer
+	printer := optimize.NewPrinter()
+	printer.Writer = &buf
+	ls, _ := NewLatticeSearchWith(WithRecorder(printer))
+	ls.Minimize(func(x []float64) float64 { return (x[0] - 3) * (x[0] - 3) }, []float64{0})
+	if out := buf.String(); !strings.Contains(out, "FuncEvals") || !strings.Contains(out, "Iter") {
+		t.Errorf("printer output:\n%s", out)
+	}
+}
@@ -0,0 +1,150 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Algorithm is a solver of the registry, built by name with NewAlgorithm,
+// eg from a configuration file or by a benchmarking harness looping over
+// ListAlgorithms.
+type Algorithm struct {
+	// Name is the identifier of the solver in the style of NLopt: G or L for
+	// a global or local method, N or D for a derivative-free or gradient
+	// method, eg "LN_POWELL" or "GN_CMAES". Names are case insensitive.
+	Name        string
+	Description string
+	// Gradient is true if the method uses the gradient. A Minimizer is given
+	// f only, the gradient is approximated by finite differences.
+	Gradient bool
+	// Bounds is true if the method handles bounds natively. The solvers
+	// registered by the package accept WithBounds, the others penalizing the
+	// distance to the box.
+	Bounds bool
+	// Constraints is true if the method handles nonlinear constraints
+	// natively
+	Constraints bool
+	// Global is true for a global method
+	Global bool
+	// New returns the solver with its default options, configured by opts
+	New func(opts ...Option) (Minimizer, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	algorithms map[string]Algorithm
+}{algorithms: map[string]Algorithm{}}
+
+func init() {
+	for _, a := range []struct {
+		Algorithm
+		method string
+	}{
+		{Algorithm{Name: "LN_POWELL", Description: "Powell's conjugate directions method"}, "powell"},
+		{Algorithm{Name: "GN_CMAES", Description: "bounded CMA-ES", Bounds: true, Global: true}, "cmaes"},
+		{Algorithm{Name: "LN_SPSA", Description: "simultaneous perturbation stochastic approximation, for noisy objectives", Bounds: true}, "spsa"},
+		{Algorithm{Name: "LN_NELDERMEAD", Description: "Nelder-Mead simplex method of gonum"}, "neldermead"},
+		{Algorithm{Name: "LD_BFGS", Description: "BFGS quasi-Newton method of gonum", Gradient: true}, "bfgs"},
+		{Algorithm{Name: "LD_LBFGS", Description: "limited memory BFGS method of gonum", Gradient: true}, "lbfgs"},
+		{Algorithm{Name: "LD_CG", Description: "nonlinear conjugate gradient method of gonum", Gradient: true}, "cg"},
+		{Algorithm{Name: "LD_SQP", Description: "sequential quadratic programming with BFGS updates", Gradient: true, Bounds: true, Constraints: true}, "sqp"},
+	} {
+		method := a.method
+		a.New = func(opts ...Option) (Minimizer, error) {
+			om := &optionsMinimizer{Options: Options{Method: method}}
+			if err := configure(om, opts); err != nil {
+				return nil, err
+			}
+			return om, nil
+		}
+		if err := Register(a.Algorithm); err != nil {
+			panic(err)
+		}
+	}
+	qrs := Algorithm{Name: "GN_SOBOL", Description: "quasi-random search of a scrambled Sobol sequence, a baseline", Bounds: true, Global: true}
+	qrs.New = func(opts ...Option) (Minimizer, error) {
+		qs := NewQuasiRandomSearch(nil, nil)
+		if err := configure(qs, opts); err != nil {
+			return nil, err
+		}
+		return qs, nil
+	}
+	if err := Register(qrs); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds a to the registry. Its Name must be new.
+func Register(a Algorithm) error {
+	if a.Name == "" || a.New == nil {
+		return errors.New("optimize: Register: Algorithm without Name or New")
+	}
+	name := strings.ToUpper(a.Name)
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.algorithms[name]; ok {
+		return errors.New("optimize: Register: algorithm " + a.Name + " already registered")
+	}
+	a.Name = name
+	registry.algorithms[name] = a
+	return nil
+}
+
+// LookupAlgorithm returns the Algorithm of name
+func LookupAlgorithm(name string) (Algorithm, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	a, ok := registry.algorithms[strings.ToUpper(name)]
+	return a, ok
+}
+
+// ListAlgorithms returns the registered algorithms sorted by Name
+func ListAlgorithms() []Algorithm {
+	registry.RLock()
+	defer registry.RUnlock()
+	list := make([]Algorithm, 0, len(registry.algorithms))
+	for _, a := range registry.algorithms {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// NewAlgorithm returns the solver of the algorithm name configured by opts
+func NewAlgorithm(name string, opts ...Option) (Minimizer, error) {
+	a, ok := LookupAlgorithm(name)
+	if !ok {
+		return nil, errors.New("optimize: unknown algorithm " + name)
+	}
+	return a.New(opts...)
+}
+
+// optionsMinimizer is a Minimizer running a method of Minimize
+type optionsMinimizer struct {
+	Options Options
+}
+
+func (om *optionsMinimizer) fields() solverFields {
+	f := om.Options.fields()
+	f.method = nil
+	return f
+}
+
+// Minimize minimizes f from x0. An error of Minimize is the Message of a
+// Result with Status Failure.
+func (om *optionsMinimizer) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	opts := om.Options
+	res, err := Minimize(optimize.Problem{Func: f}, x0, &opts)
+	if res == nil {
+		res = &Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure}
+	}
+	if err != nil {
+		res.Status, res.Message = optimize.Failure, err.Error()
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleListAlgorithms() {
+	for _, a := range ListAlgorithms() {
+		fmt.Printf("%-13s gradient:%-5v bounds:%-5v global:%v\n", a.Name, a.Gradient, a.Bounds, a.Global)
+	}
+	m, _ := NewAlgorithm("ln_powell", WithMaxFev(1000))
+	res := m.Minimize(func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }, []float64{0, 0})
+	fmt.Printf("%.2f\n", res.X)
+	// Output:
+	// GN_CMAES      gradient:false bounds:true  global:true
+	// GN_SOBOL      gradient:false bounds:true  global:true
+	// LD_BFGS       gradient:true  bounds:false global:false
+	// LD_CG         gradient:true  bounds:false global:false
+	// LD_LBFGS      gradient:true  bounds:false global:false
+	// LD_SQP        gradient:true  bounds:true  global:false
+	// LN_NELDERMEAD gradient:false bounds:false global:false
+	// LN_POWELL     gradient:false bounds:false global:false
+	// LN_SPSA       gradient:false bounds:true  global:false
+	// [1.00 -2.00]
+}
+
+func TestRegistry(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	for _, a := range ListAlgorithms() {
+		m, err := NewAlgorithm(a.Name, WithBounds([]float64{0, -3}, []float64{3, 3}), WithMaxFev(5000), WithRNG(rand.NewSource(1)))
+		if err != nil {
+			t.Fatalf("%s: %v", a.Name, err)
+		}
+		res := m.Minimize(f, []float64{2, 2})
+		if res.Status == optimize.Failure || math.Abs(res.X[0]-1) > .1 || math.Abs(res.X[1]+2) > .1 || res.NFev > 5100 {
+			t.Errorf("%s: unexpected result %+v", a.Name, res)
+		}
+	}
+	if _, err := NewAlgorithm("GN_DIRECT"); err == nil {
+		t.Error("expected an unknown algorithm")
+	}
+	if _, err := NewAlgorithm("LN_POWELL", WithMethod("cmaes")); err == nil {
+		t.Error("expected WithMethod to be unsupported")
+	}
+	if err := Register(Algorithm{Name: "ln_powell", New: func(...Option) (Minimizer, error) { return NewPowellMinimizer(), nil }}); err == nil {
+		t.Error("expected LN_POWELL to be registered")
+	}
+	if err := Register(Algorithm{Name: "LN_TEST"}); err == nil {
+		t.Error("expected an error without New")
+	}
+}
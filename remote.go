@@ -0,0 +1,217 @@
+package optimize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Remote evaluates points on external workers over HTTP. Its Batch splits
+// the points among the workers, one JSON request per worker, so that a
+// cluster evaluates a population while the Go process runs the method.
+// A worker receives a POST of {"x":[[...],...]} and answers
+// {"f":[...]}, non finite values being null. RemoteHandler serves this
+// protocol. Failed requests are retried on the next worker. After Retries
+// retries the values are NaN and the error is kept in Err. It is safe for
+// concurrent use.
+type Remote struct {
+	// URLs are the workers
+	URLs []string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+	// Timeout, if positive, is the timeout of a request
+	Timeout time.Duration
+	// Retries is the number of retries of a failed request, Backoff the
+	// delay before the first one, doubled at each retry
+	Retries int
+	Backoff time.Duration
+
+	mu       sync.Mutex
+	next     int
+	inFlight int
+	err      error
+}
+
+// NewRemote returns a Remote sending requests to urls, with 2 retries
+func NewRemote(urls ...string) *Remote {
+	return &Remote{URLs: urls, Retries: 2, Backoff: 100 * time.Millisecond}
+}
+
+type remoteRequest struct {
+	X [][]float64 `json:"x"`
+}
+
+type remoteResponse struct {
+	F     []*float64 `json:"f"`
+	Error string     `json:"error,omitempty"`
+}
+
+// Evaluator returns an Evaluator calling Batch
+func (r *Remote) Evaluator() *Evaluator {
+	return NewBatchEvaluator(r.Batch)
+}
+
+// Func evaluates x remotely
+func (r *Remote) Func(x []float64) float64 {
+	return r.Batch([][]float64{x})[0]
+}
+
+// Batch evaluates xs remotely, in parallel on the workers
+func (r *Remote) Batch(xs [][]float64) []float64 {
+	ys := make([]float64, len(xs))
+	workers := len(r.URLs)
+	if workers == 0 {
+		r.fail(fmt.Errorf("optimize: Remote without URLs"))
+		for i := range ys {
+			ys[i] = math.NaN()
+		}
+		return ys
+	}
+	if workers > len(xs) {
+		workers = len(xs)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := w*len(xs)/workers, (w+1)*len(xs)/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.send(xs[lo:hi], ys[lo:hi])
+		}()
+	}
+	wg.Wait()
+	return ys
+}
+
+// InFlight returns the number of points sent and not yet evaluated
+func (r *Remote) InFlight() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inFlight
+}
+
+// Err returns the first error after which values were NaN
+func (r *Remote) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Remote) fail(err error) {
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+}
+
+// send evaluates xs in ys, retrying on the next workers
+func (r *Remote) send(xs [][]float64, ys []float64) {
+	body, err := json.Marshal(remoteRequest{X: xs})
+	if err != nil {
+		r.fail(err)
+		for i := range ys {
+			ys[i] = math.NaN()
+		}
+		return
+	}
+	r.mu.Lock()
+	r.inFlight += len(xs)
+	url := r.next
+	r.next = (r.next + 1) % len(r.URLs)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight -= len(xs)
+		r.mu.Unlock()
+	}()
+	backoff := r.Backoff
+	for try := 0; ; try++ {
+		if err = r.post(r.URLs[(url+try)%len(r.URLs)], body, ys); err == nil {
+			return
+		}
+		if try >= r.Retries {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	r.fail(err)
+	for i := range ys {
+		ys[i] = math.NaN()
+	}
+}
+
+// post sends body to url and decodes the values in ys
+func (r *Remote) post(url string, body []byte, ys []float64) error {
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var res remoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil && err != io.EOF {
+		return fmt.Errorf("optimize: %s: %s: %v", url, resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || res.Error != "" {
+		return fmt.Errorf("optimize: %s: %s %s", url, resp.Status, res.Error)
+	}
+	if len(res.F) != len(ys) {
+		return fmt.Errorf("optimize: %s returned %d values for %d points", url, len(res.F), len(ys))
+	}
+	for i, f := range res.F {
+		ys[i] = math.NaN()
+		if f != nil {
+			ys[i] = *f
+		}
+	}
+	return nil
+}
+
+// RemoteHandler returns the handler of a worker evaluating f for a Remote.
+// The points of a request are evaluated by ev, which may be nil.
+func RemoteHandler(f func([]float64) float64, ev *Evaluator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var in remoteRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(remoteResponse{Error: err.Error()})
+			return
+		}
+		ys, err := ev.Eval(f, in.X, nil)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(remoteResponse{Error: err.Error()})
+			return
+		}
+		out := remoteResponse{F: make([]*float64, len(ys))}
+		for i := range ys {
+			if !math.IsNaN(ys[i]) && !math.IsInf(ys[i], 0) {
+				out.F[i] = &ys[i]
+			}
+		}
+		json.NewEncoder(w).Encode(out)
+	})
+}
@@ -0,0 +1,66 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ExampleRemote() {
+	f := func(x []float64) float64 { return math.Abs(x[0]-3) + math.Abs(x[1]+2) }
+	// two workers, usually on other hosts
+	w1, w2 := httptest.NewServer(RemoteHandler(f, nil)), httptest.NewServer(RemoteHandler(f, nil))
+	defer w1.Close()
+	defer w2.Close()
+
+	remote := NewRemote(w1.URL, w2.URL)
+	ls := NewLatticeSearch()
+	ls.Evaluator = remote.Evaluator()
+	res := ls.Minimize(remote.Func, []float64{0, 0})
+	fmt.Println(res.X, remote.Err())
+	// Output:
+	// [3 -2] <nil>
+}
+
+func TestRemote(t *testing.T) {
+	f := func(x []float64) float64 {
+		if x[0] < 0 {
+			return math.Inf(1)
+		}
+		return x[0] * x[0]
+	}
+	var failed int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&failed, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(RemoteHandler(f, NewEvaluator(2)))
+	defer up.Close()
+
+	remote := NewRemote(down.URL, up.URL)
+	remote.Backoff = time.Millisecond
+	xs := [][]float64{{1}, {2}, {-1}, {3}}
+	ys := remote.Batch(xs)
+	if ys[0] != 1 || ys[1] != 4 || !math.IsInf(ys[2], 0) && !math.IsNaN(ys[2]) || ys[3] != 9 {
+		t.Errorf("got %g", ys)
+	}
+	if remote.Err() != nil || atomic.LoadInt32(&failed) == 0 || remote.InFlight() != 0 {
+		t.Errorf("err %v, %d failed requests, %d in flight", remote.Err(), failed, remote.InFlight())
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		RemoteHandler(f, nil).ServeHTTP(w, req)
+	}))
+	defer slow.Close()
+	remote = NewRemote(slow.URL)
+	remote.Timeout, remote.Retries = 5*time.Millisecond, 1
+	if y := remote.Func([]float64{1}); !math.IsNaN(y) || remote.Err() == nil {
+		t.Errorf("expected a timeout, got %g %v", y, remote.Err())
+	}
+}
@@ -0,0 +1,100 @@
+package optimize
+
+import (
+	"math"
+)
+
+// Repair modifies a candidate point in place before it is evaluated, eg to
+// round integers, snap to a grid or normalize weights. Unlike a Projection it
+// needn't map onto a convex set. Methods having a Repair field (CmaEsCholB,
+// PowellMinimizer, SPSA, LatticeSearch) evaluate and report repaired points.
+type Repair interface {
+	Repair(x []float64)
+}
+
+// RepairFunc is an adapter to allow the use of ordinary functions as Repair
+type RepairFunc func(x []float64)
+
+// Repair calls fn(x)
+func (fn RepairFunc) Repair(x []float64) { fn(x) }
+
+// Repairs applies several repairs in order
+type Repairs []Repair
+
+// Repair applies each repair to x
+func (rs Repairs) Repair(x []float64) {
+	for _, r := range rs {
+		r.Repair(x)
+	}
+}
+
+var (
+	_ Repair = Variables{}
+	_ Repair = Grid{}
+	_ Repair = NormalizeWeights{}
+	_ Repair = Repairs{}
+)
+
+// Repair rounds integer and categorical variables (see Project)
+func (vs Variables) Repair(x []float64) { vs.Project(x) }
+
+// Grid snaps x to the nearest point of Origin + k*Step. Components beyond
+// the length of Step, or with a non-positive step, are unchanged. A nil
+// Origin is 0.
+type Grid struct {
+	Origin, Step []float64
+}
+
+// Repair snaps x to the grid
+func (g Grid) Repair(x []float64) {
+	for i := range x {
+		if i >= len(g.Step) || g.Step[i] <= 0 {
+			continue
+		}
+		o := 0.
+		if i < len(g.Origin) {
+			o = g.Origin[i]
+		}
+		x[i] = o + math.Round((x[i]-o)/g.Step[i])*g.Step[i]
+	}
+}
+
+// NormalizeWeights makes x[Start:End] non-negative weights summing to 1,
+// negative components being set to 0. End = 0 means len(x). Weights are
+// uniform if they are all 0.
+type NormalizeWeights struct {
+	Start, End int
+}
+
+// Repair normalizes the weights
+func (nw NormalizeWeights) Repair(x []float64) {
+	end := nw.End
+	if end <= 0 || end > len(x) {
+		end = len(x)
+	}
+	w := x[nw.Start:end]
+	s := 0.
+	for i := range w {
+		w[i] = math.Max(w[i], 0)
+		s += w[i]
+	}
+	for i := range w {
+		if s > 0 {
+			w[i] /= s
+		} else {
+			w[i] = 1 / float64(len(w))
+		}
+	}
+}
+
+// RepairedFunc returns f evaluated at the repaired copy of its argument
+func RepairedFunc(r Repair, f func([]float64) float64) func([]float64) float64 {
+	if r == nil {
+		return f
+	}
+	return func(x []float64) float64 {
+		y := append([]float64(nil), x...)
+		r.Repair(y)
+		return f(y)
+	}
+}
@@ -0,0 +1,88 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleNormalizeWeights() {
+	// portfolio weights: minimize the variance of uncorrelated assets
+	variances := []float64{1, 2, 4}
+	problem := optimize.Problem{
+		Func: func(w []float64) float64 {
+			v := 0.
+			for i, wi := range w {
+				v += wi * wi * variances[i]
+			}
+			return v
+		},
+	}
+	method := &CmaEsCholB{Repair: NormalizeWeights{}, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(problem, []float64{1, 1, 1}, &optimize.Settings{FuncEvaluations: 3000}, method)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.2f\n", res.X)
+	// Output:
+	// [0.57 0.29 0.14]
+}
+
+func TestRepair(t *testing.T) {
+	x := []float64{.26, 1.3, -7}
+	Grid{Step: []float64{.25, 0}}.Repair(x)
+	if x[0] != .25 || x[1] != 1.3 || x[2] != -7 {
+		t.Errorf("bad grid %v", x)
+	}
+	Grid{Origin: []float64{.1}, Step: []float64{1}}.Repair(x)
+	if math.Abs(x[0]-.1) > 1e-15 {
+		t.Errorf("bad grid origin %v", x)
+	}
+	w := []float64{5, 1, 3, -1}
+	NormalizeWeights{Start: 1}.Repair(w)
+	if w[0] != 5 || w[1] != .25 || w[2] != .75 || w[3] != 0 {
+		t.Errorf("bad weights %v", w)
+	}
+	Repairs{Variables{{Kind: Integer}}, Grid{Step: []float64{0, .5}}}.Repair(x)
+	if x[0] != 0 || x[1] != 1.5 {
+		t.Errorf("bad repairs %v", x)
+	}
+
+	// direct methods evaluate and report repaired points
+	grid := Grid{Step: []float64{.5, .5}}
+	onGrid := func(x []float64) bool {
+		for _, xi := range x {
+			if xi*2 != math.Round(xi*2) {
+				return false
+			}
+		}
+		return true
+	}
+	f := func(x []float64) float64 {
+		if !onGrid(x) {
+			t.Errorf("evaluation off grid %v", x)
+		}
+		return (x[0]-1.2)*(x[0]-1.2) + (x[1]+.3)*(x[1]+.3)
+	}
+	pm := NewPowellMinimizer()
+	pm.Repair = grid
+	var xp []float64
+	pm.Callback = func(xk []float64) { xp = xk }
+	pm.Minimize(f, []float64{0, 0})
+	if xp[0] != 1 || (xp[1] != -.5 && xp[1] != 0) {
+		t.Errorf("powell: %v", xp)
+	}
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	sp.Repair = grid
+	if xs := sp.Minimize(f, []float64{0, 0}).X; !onGrid(xs) {
+		t.Errorf("spsa: %v", xs)
+	}
+	res, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{0, 0}, nil, &Powell{PM: pm})
+	if err != nil || !onGrid(res.X) {
+		t.Errorf("powell method: %v %v", res.X, err)
+	}
+}
@@ -0,0 +1,216 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// MinimizerFunc is an adapter to allow the use of ordinary functions, eg a
+// closure calling Minimize, as Minimizer
+type MinimizerFunc func(f func([]float64) float64, x0 []float64) *Result
+
+// Minimize calls fn(f, x0)
+func (fn MinimizerFunc) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	return fn(f, x0)
+}
+
+// RestartPolicy decides when a Restarts runs its Minimizer again, and from
+// which point. runs are the results of the previous runs, best the best one.
+type RestartPolicy interface {
+	Restart(runs []*Result, best *Result) bool
+	Start(best *Result, rnd *rand.Rand) []float64
+}
+
+// RestartLimit is the when part of the restart policies of the package. It
+// allows MaxRestarts restarts and, if Patience is positive, stops after
+// Patience runs without improvement of the best value. Runs stopped by a
+// TargetF are never restarted.
+type RestartLimit struct {
+	MaxRestarts, Patience int
+}
+
+// Restart for RestartPolicy
+func (rl RestartLimit) Restart(runs []*Result, best *Result) bool {
+	last := runs[len(runs)-1]
+	if len(runs) > rl.MaxRestarts || last.Status == optimize.FunctionThreshold {
+		return false
+	}
+	if rl.Patience > 0 && len(runs) > rl.Patience {
+		// best value before the last Patience runs
+		f := math.Inf(1)
+		for _, r := range runs[:len(runs)-rl.Patience] {
+			f = math.Min(f, r.F)
+		}
+		return best.F < f
+	}
+	return true
+}
+
+// RandomRestarts restarts from uniform random points of the box
+type RandomRestarts struct {
+	RestartLimit
+	Xmin, Xmax []float64
+}
+
+// Start for RestartPolicy
+func (rr *RandomRestarts) Start(best *Result, rnd *rand.Rand) []float64 {
+	x := make([]float64, len(rr.Xmin))
+	for i := range x {
+		x[i] = rr.Xmin[i] + rnd.Float64()*(rr.Xmax[i]-rr.Xmin[i])
+	}
+	return x
+}
+
+// PerturbedRestarts restarts from the best point perturbed by a normal
+// noise of standard deviation Scale, Scale[i] for component i if its
+// length is the dimension. The points are projected on the optional bounds.
+type PerturbedRestarts struct {
+	RestartLimit
+	Scale      []float64
+	Xmin, Xmax []float64
+}
+
+// Start for RestartPolicy
+func (pr *PerturbedRestarts) Start(best *Result, rnd *rand.Rand) []float64 {
+	x := append([]float64(nil), best.X...)
+	for i := range x {
+		s := 1.
+		if len(pr.Scale) == 1 {
+			s = pr.Scale[0]
+		} else if i < len(pr.Scale) {
+			s = pr.Scale[i]
+		}
+		x[i] += s * rnd.NormFloat64()
+	}
+	Box{Xmin: pr.Xmin, Xmax: pr.Xmax}.Project(x)
+	return x
+}
+
+// LHSRestarts restarts from the points of a latin hypercube design of
+// MaxRestarts points of the box, which covers each coordinate evenly, eg
+// for the restarts of CMA-ES
+type LHSRestarts struct {
+	RestartLimit
+	Xmin, Xmax []float64
+	// Maximin is the Maximin of the LatinHypercube of the design
+	Maximin int
+
+	design [][]float64
+}
+
+// Start for RestartPolicy
+func (lr *LHSRestarts) Start(best *Result, rnd *rand.Rand) []float64 {
+	if len(lr.design) == 0 {
+		lr.design = LatinHypercube{Maximin: lr.Maximin}.Sample(max(lr.MaxRestarts, 1), lr.Xmin, lr.Xmax, rnd)
+	}
+	x := lr.design[0]
+	lr.design = lr.design[1:]
+	return x
+}
+
+// QuasiRandomRestarts restarts from the points of a QuasiRandom sequence
+// of the box, a Sobol sequence scrambled with the random source of the
+// Restarts if Sequence is nil, which covers the box more evenly than
+// RandomRestarts for any number of restarts. Bounds that are not finite,
+// or a Sequence of another dimension, stop the restarts with an error of
+// Restarts.
+type QuasiRandomRestarts struct {
+	RestartLimit
+	Xmin, Xmax []float64
+	Sequence   QuasiRandom
+	err        error
+}
+
+// Restart for RestartPolicy
+func (qr *QuasiRandomRestarts) Restart(runs []*Result, best *Result) bool {
+	switch {
+	case qr.err != nil:
+	case len(qr.Xmin) == 0 || checkInitBounds(qr.Xmin, qr.Xmin, qr.Xmax) != nil:
+		qr.err = errors.New("optimize: QuasiRandomRestarts needs finite bounds")
+	case qr.Sequence != nil && qr.Sequence.Dim() != len(qr.Xmin):
+		qr.err = errors.New("optimize: QuasiRandomRestarts Sequence of another dimension")
+	}
+	return qr.err == nil && qr.RestartLimit.Restart(runs, best)
+}
+
+// Start for RestartPolicy
+func (qr *QuasiRandomRestarts) Start(best *Result, rnd *rand.Rand) []float64 {
+	if qr.Sequence == nil {
+		seq, err := NewSobol(len(qr.Xmin), &QuasiRandomOptions{Scramble: true, Src: rand.NewSource(rnd.Uint64())})
+		if err != nil {
+			// the last run, from the best point
+			qr.err = err
+			return append([]float64(nil), best.X...)
+		}
+		qr.Sequence = seq
+	}
+	return QuasiRandomPoints(qr.Sequence, 1, qr.Xmin, qr.Xmax)[0]
+}
+
+// Err returns the error which stopped the restarts, nil if none
+func (qr *QuasiRandomRestarts) Err() error { return qr.err }
+
+var (
+	_ RestartPolicy = &RandomRestarts{}
+	_ RestartPolicy = &PerturbedRestarts{}
+	_ RestartPolicy = &LHSRestarts{}
+	_ RestartPolicy = &QuasiRandomRestarts{}
+)
+
+// Restarts runs a Minimizer from x0, then from the points chosen by Policy
+// while it allows restarts. MaxFev, if positive, is a total budget: the
+// remaining evaluations are the MaxFev of the solvers of the package, and
+// the runs stop when it is spent.
+type Restarts struct {
+	Minimizer Minimizer
+	Policy    RestartPolicy
+	MaxFev    int
+	// Src is the random source of Policy. if nil, a source seeded from
+	// golang.org/x/exp/rand is used.
+	Src rand.Source
+}
+
+// Minimize returns the best point of the runs, with the totals of
+// iterations and evaluations and the Status of the last run. Its Extra is
+// the []*Result of the runs. A Policy with an Err method, eg
+// SeparatedRestarts, may stop the restarts on an error, which is returned
+// with the result of the runs.
+func (rs *Restarts) Minimize(f func([]float64) float64, x0 []float64) (*Result, error) {
+	if rs.Minimizer == nil || rs.Policy == nil {
+		return nil, errors.New("optimize: Restarts needs a Minimizer and a Policy")
+	}
+	start := time.Now()
+	rnd := newRand(rs.Src)
+	res := &Result{X: append([]float64(nil), x0...), F: math.Inf(1)}
+	var runs []*Result
+	x := x0
+	for {
+		if c, ok := rs.Minimizer.(configurable); ok && rs.MaxFev > 0 {
+			if fields := c.fields(); fields.maxFev != nil {
+				*fields.maxFev = rs.MaxFev - res.NFev
+			}
+		}
+		r := rs.Minimizer.Minimize(f, x)
+		runs = append(runs, r)
+		res.add(r)
+		best := &Result{X: res.X, F: res.F}
+		if rs.MaxFev > 0 && res.NFev >= rs.MaxFev || !rs.Policy.Restart(runs, best) {
+			break
+		}
+		x = rs.Policy.Start(best, rnd)
+	}
+	res.Extra = runs
+	if p, ok := rs.Policy.(policyError); ok && p.Err() != nil {
+		return res.done(start), p.Err()
+	}
+	return res.done(start), nil
+}
+
+// policyError is implemented by the policies which may fail to give a start
+type policyError interface {
+	Err() error
+}
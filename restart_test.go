@@ -0,0 +1,117 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func rastrigin(x []float64) float64 {
+	s := 10 * float64(len(x))
+	for _, xi := range x {
+		s += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return s
+}
+
+func ExampleRestarts() {
+	// Powell stops at a local minimum of the Rastrigin function, restarts
+	// from random points of the box find the global one
+	rs := &Restarts{
+		Minimizer: NewPowellMinimizer(),
+		Policy: &RandomRestarts{
+			RestartLimit: RestartLimit{MaxRestarts: 50, Patience: 20},
+			Xmin:         []float64{-5, -5},
+			Xmax:         []float64{5, 5},
+		},
+		Src: rand.NewSource(1),
+	}
+	res, err := rs.Minimize(rastrigin, []float64{3.2, -2.1})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("first run %.3f, best %.3f\n", res.Extra.([]*Result)[0].F, res.F)
+	// Output:
+	// first run 9.950, best 0.000
+}
+
+func TestRestarts(t *testing.T) {
+	box := func(rl RestartLimit) *RandomRestarts {
+		return &RandomRestarts{RestartLimit: rl, Xmin: []float64{-5, -5}, Xmax: []float64{5, 5}}
+	}
+	runs := func(res *Result) []*Result { return res.Extra.([]*Result) }
+
+	// MaxRestarts
+	rs := &Restarts{Minimizer: NewPowellMinimizer(), Policy: box(RestartLimit{MaxRestarts: 4}), Src: rand.NewSource(1)}
+	res, err := rs.Minimize(rastrigin, []float64{3.2, -2.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nfev := 0
+	for _, r := range runs(res) {
+		nfev += r.NFev
+		if r.F < res.F {
+			t.Errorf("best %g, run %g", res.F, r.F)
+		}
+	}
+	if len(runs(res)) != 5 || res.NFev != nfev {
+		t.Errorf("expected 5 runs and %d evaluations, got %d, %d", nfev, len(runs(res)), res.NFev)
+	}
+
+	// a run reaching TargetF is not restarted
+	target := 1e-6
+	sphere := MinimizerFunc(func(f func([]float64) float64, x0 []float64) *Result {
+		pm := NewPowellMinimizer()
+		pm.TargetF = &target
+		return pm.Minimize(f, x0)
+	})
+	rs = &Restarts{Minimizer: sphere, Policy: box(RestartLimit{MaxRestarts: 4})}
+	if res, _ = rs.Minimize(func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] }, []float64{1, 1}); len(runs(res)) != 1 || res.Status != optimize.FunctionThreshold {
+		t.Errorf("expected 1 run, got %d %v", len(runs(res)), res.Status)
+	}
+
+	// Patience: a convex function is not improved by restarts
+	rs = &Restarts{Minimizer: NewPowellMinimizer(), Policy: box(RestartLimit{MaxRestarts: 100, Patience: 3})}
+	if res, _ = rs.Minimize(rosen, []float64{-1.2, 1}); len(runs(res)) > 10 {
+		t.Errorf("expected Patience to stop the restarts, got %d runs", len(runs(res)))
+	}
+
+	// MaxFev is shared by the runs, Powell stopping after the line search
+	// exceeding its MaxFev
+	rs = &Restarts{Minimizer: NewPowellMinimizer(), Policy: box(RestartLimit{MaxRestarts: 100}), MaxFev: 1000, Src: rand.NewSource(2)}
+	if res, _ = rs.Minimize(rastrigin, []float64{3.2, -2.1}); res.NFev < 1000 || res.NFev > 1100 || len(runs(res)) < 2 {
+		t.Errorf("expected several runs within 1000 evaluations, got %d runs, %d", len(runs(res)), res.NFev)
+	}
+
+	// PerturbedRestarts starts near the best point, within the bounds
+	pr := &PerturbedRestarts{Scale: []float64{.1}, Xmin: []float64{0, 0}, Xmax: []float64{1, 1}}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x := pr.Start(&Result{X: []float64{1, .5}}, rnd)
+		if x[0] < .5 || x[0] > 1 || math.Abs(x[1]-.5) > .5 {
+			t.Errorf("unexpected start %v", x)
+		}
+	}
+
+	// LHSRestarts starts from a latin hypercube design
+	lr := &LHSRestarts{RestartLimit: RestartLimit{MaxRestarts: 10}, Xmin: []float64{0, -1}, Xmax: []float64{10, 1}}
+	slices := make(map[int]bool)
+	for i := 0; i < 10; i++ {
+		x := lr.Start(nil, rnd)
+		slices[int(x[0])] = true
+		if x[1] < -1 || x[1] > 1 {
+			t.Errorf("unexpected start %v", x)
+		}
+	}
+	if len(slices) != 10 {
+		t.Errorf("expected a point in each slice, got %v", slices)
+	}
+
+	if _, err := (&Restarts{Policy: pr}).Minimize(rosen, []float64{0, 0}); err == nil {
+		t.Error("expected an error without Minimizer")
+	}
+}
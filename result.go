@@ -0,0 +1,183 @@
+package optimize
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Result is the result of a minimization, returned by every minimizer of the package
+type Result struct {
+	X      []float64
+	F      float64
+	Status optimize.Status
+	// Reason is the criterion which stopped the run
+	Reason StopReason
+	// Message describes Status, by default the Message of Reason
+	Message string
+	// Warnings are notes on the run, eg a starting point projected on the
+	// bounds
+	Warnings []string
+	// NIter, NFev, NGrad and NHess are the numbers of iterations, function,
+	// gradient and Hessian evaluations
+	NIter, NFev, NGrad, NHess int
+	Elapsed                   time.Duration
+	// Evals are statistics of the evaluations, zero if not measured
+	Evals EvalStats
+	// Extra holds method-specific results, eg *Averaged or *BranchAndBoundInfo
+	Extra interface{}
+	// Warm, if not nil, is the state from which a WarmStarter starts a run
+	// on a slightly changed objective
+	Warm *WarmStart
+}
+
+// resultJSON is the encoding of a Result, with the fields of the
+// OptimizeResult of scipy.optimize
+type resultJSON struct {
+	X       []jsonFloat `json:"x"`
+	Fun     jsonFloat   `json:"fun"`
+	NIt     int         `json:"nit"`
+	NFev    int         `json:"nfev"`
+	NJev    int         `json:"njev"`
+	NHev    int         `json:"nhev"`
+	Success bool        `json:"success"`
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	// GonumStatus is the name of the Status
+	GonumStatus string   `json:"gonum_status"`
+	Reason      string   `json:"reason,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// MarshalJSON encodes r with the field names of scipy's OptimizeResult:
+// x, fun, nit, nfev, njev, nhev, success, status and message, and
+// gonum_status, the name of Status, reason, the name of a known Reason, and
+// warnings. success is true if the method converged, status is 0 then, 1
+// if it reached a limit and 2 otherwise.
+// Non-finite values are null. Extra and Warm are not encoded.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	rj := resultJSON{X: make([]jsonFloat, len(r.X)), Fun: jsonFloat(r.F), NIt: r.NIter, NFev: r.NFev, NJev: r.NGrad, NHev: r.NHess,
+		Status: 2, Message: r.Message, GonumStatus: r.Status.String(), Warnings: r.Warnings}
+	if r.Reason != ReasonUnknown {
+		rj.Reason = r.Reason.String()
+	}
+	for i, xi := range r.X {
+		rj.X[i] = jsonFloat(xi)
+	}
+	switch r.Status {
+	case optimize.NotTerminated, optimize.Failure:
+	case optimize.IterationLimit, optimize.RuntimeLimit, optimize.FunctionEvaluationLimit,
+		optimize.GradientEvaluationLimit, optimize.HessianEvaluationLimit:
+		rj.Status = 1
+	default:
+		rj.Success, rj.Status = true, 0
+	}
+	return json.Marshal(rj)
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON, null values being NaN
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var rj resultJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	*r = Result{X: make([]float64, len(rj.X)), F: float64(rj.Fun), NIter: rj.NIt, NFev: rj.NFev, NGrad: rj.NJev, NHess: rj.NHev, Message: rj.Message, Warnings: rj.Warnings}
+	for i, xi := range rj.X {
+		r.X[i] = float64(xi)
+	}
+	switch {
+	case rj.GonumStatus != "":
+		for s := optimize.NotTerminated; s <= optimize.HessianEvaluationLimit; s++ {
+			if s.String() == rj.GonumStatus {
+				r.Status = s
+			}
+		}
+	case rj.Success:
+		r.Status = optimize.Success
+	case rj.Status == 1:
+		r.Status = optimize.IterationLimit
+	default:
+		r.Status = optimize.Failure
+	}
+	for reason := range stopReasons {
+		if rj.Reason != "" && stopReasons[reason].name == rj.Reason {
+			r.Reason = StopReason(reason)
+		}
+	}
+	return nil
+}
+
+// EvalStats are statistics of the evaluations of a run
+type EvalStats struct {
+	// CacheHits is the number of evaluations avoided by the cache of a
+	// method, eg LatticeSearch
+	CacheHits int
+	// Batches is the number of calls of an Evaluator, evaluating
+	// BatchPoints points
+	Batches, BatchPoints int
+	// FuncTime is the time spent in the objective and its gradient, summed
+	// over concurrent evaluations
+	FuncTime time.Duration
+}
+
+// Averaged is the Extra of methods using iterate Averaging
+type Averaged struct {
+	X []float64
+	F float64
+}
+
+// done sets Elapsed since start, Reason from Status if it is unknown, and
+// Message if it is empty
+func (r *Result) done(start time.Time) *Result {
+	r.Elapsed = time.Since(start)
+	if r.Reason == ReasonUnknown {
+		r.Reason = reasonOf(r.Status)
+	}
+	if r.Message == "" {
+		r.Message = r.Reason.Message()
+	}
+	if r.Message == "" {
+		r.Message = r.Status.String()
+	}
+	return r
+}
+
+// add adds the counts of run, a run of a sequence, to r, and sets the best
+// point of the runs and the Status of run
+func (r *Result) add(run *Result) {
+	r.NIter += run.NIter
+	r.NFev += run.NFev
+	r.NGrad += run.NGrad
+	r.NHess += run.NHess
+	r.Evals.CacheHits += run.Evals.CacheHits
+	r.Evals.Batches += run.Evals.Batches
+	r.Evals.BatchPoints += run.Evals.BatchPoints
+	r.Evals.FuncTime += run.Evals.FuncTime
+	r.Status, r.Reason, r.Message = run.Status, run.Reason, run.Message
+	r.Warnings = append(r.Warnings, run.Warnings...)
+	if run.F < r.F {
+		r.X, r.F = append([]float64(nil), run.X...), run.F
+	}
+}
+
+// evalTimer sums the durations of evaluations. It is safe for concurrent use.
+type evalTimer struct{ ns int64 }
+
+// wrap returns f adding the durations of its calls
+func (t *evalTimer) wrap(f func([]float64) float64) func([]float64) float64 {
+	return func(x []float64) float64 {
+		defer t.since(time.Now())
+		return f(x)
+	}
+}
+
+// since adds the time elapsed since start
+func (t *evalTimer) since(start time.Time) {
+	atomic.AddInt64(&t.ns, int64(time.Since(start)))
+}
+
+func (t *evalTimer) elapsed() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.ns))
+}
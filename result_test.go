@@ -0,0 +1,107 @@
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestResult(t *testing.T) {
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	for name, res := range map[string]*Result{
+		"powell":  NewPowellMinimizer().Minimize(f, []float64{0, 0}),
+		"spsa":    NewSPSA().Minimize(f, []float64{0, 0}),
+		"lattice": NewLatticeSearch().Minimize(f, []float64{0, 0}),
+	} {
+		if res.Status == optimize.NotTerminated || res.Message == "" || res.Reason != ReasonUnknown && res.Message != res.Reason.Message() || res.NFev == 0 || res.NIter == 0 || res.Elapsed < 0 {
+			t.Errorf("%s: incomplete result %+v", name, res)
+		}
+		if len(res.X) != 2 || res.F != f(res.X) {
+			t.Errorf("%s: F=%g is not f(%v)", name, res.F, res.X)
+		}
+	}
+}
+
+func TestEvalStats(t *testing.T) {
+	slow := func(x []float64) float64 {
+		time.Sleep(100 * time.Microsecond)
+		return math.Abs(x[0]-3) + math.Abs(x[1]+2)
+	}
+	res := NewPowellMinimizer().Minimize(slow, []float64{0, 0})
+	if min := time.Duration(res.NFev) * 100 * time.Microsecond; res.Evals.FuncTime < min || res.Evals.FuncTime > res.Elapsed {
+		t.Errorf("powell: FuncTime %s for %d evaluations in %s", res.Evals.FuncTime, res.NFev, res.Elapsed)
+	}
+
+	serial := NewLatticeSearch().Minimize(slow, []float64{0, 0})
+	ls := NewLatticeSearch()
+	ls.Evaluator = NewEvaluator(4)
+	res = ls.Minimize(slow, []float64{0, 0})
+	if res.Evals.CacheHits == 0 || res.Evals.CacheHits != serial.Evals.CacheHits {
+		t.Errorf("lattice: %d cache hits, %d without Evaluator", res.Evals.CacheHits, serial.Evals.CacheHits)
+	}
+	// the last iteration finds no improving neighbor
+	if res.Evals.Batches != res.NIter+1 || res.Evals.BatchPoints != res.NFev-1 || serial.Evals.Batches != 0 {
+		t.Errorf("lattice: %d batches of %d points in %d iterations, %d evaluations", res.Evals.Batches, res.Evals.BatchPoints, res.NIter, res.NFev)
+	}
+
+	res, err := Minimize(optimize.Problem{Func: slow}, []float64{0, 0}, &Options{Method: "spsa", MaxIter: 10, Evaluator: NewEvaluator(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Evals.Batches != 11 || res.Evals.FuncTime < time.Duration(res.NFev)*100*time.Microsecond {
+		t.Errorf("spsa: %+v", res.Evals)
+	}
+}
+
+func ExampleResult_MarshalJSON() {
+	res := &Result{X: []float64{1, math.NaN()}, F: 0.5, NIter: 3, NFev: 12, Status: optimize.FunctionConvergence, Message: "FunctionConvergence"}
+	b, _ := json.Marshal(res)
+	fmt.Println(string(b))
+	// Output:
+	// {"x":[1,null],"fun":0.5,"nit":3,"nfev":12,"njev":0,"nhev":0,"success":true,"status":0,"message":"FunctionConvergence","gonum_status":"FunctionConvergence"}
+}
+
+func TestResultJSON(t *testing.T) {
+	for _, res := range []*Result{
+		{X: []float64{1, -2}, F: 0.25, NIter: 4, NFev: 20, NGrad: 5, NHess: 1, Status: optimize.GradientThreshold, Message: "GradientThreshold"},
+		{X: []float64{0}, F: math.Inf(1), Status: optimize.FunctionEvaluationLimit, Message: "FunctionEvaluationLimit"},
+		{X: []float64{0}, F: math.NaN(), Status: optimize.Failure, Message: "optimize: failed"},
+	} {
+		b, err := json.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		success, status := res.Status == optimize.GradientThreshold, 2.
+		switch {
+		case success:
+			status = 0
+		case res.Status == optimize.FunctionEvaluationLimit:
+			status = 1
+		}
+		if m["success"] != success || m["status"] != status {
+			t.Errorf("%s: success %v status %v", res.Status, m["success"], m["status"])
+		}
+		var got Result
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != res.Status || got.Message != res.Message || got.NFev != res.NFev || got.NIter != res.NIter || got.NGrad != res.NGrad || got.NHess != res.NHess || got.X[0] != res.X[0] {
+			t.Errorf("round trip %+v, expected %+v", got, res)
+		}
+		if !(got.F == res.F || math.IsInf(res.F, 0) && math.IsNaN(got.F) || math.IsNaN(res.F) && math.IsNaN(got.F)) {
+			t.Errorf("round trip F=%g, expected %g", got.F, res.F)
+		}
+	}
+	var res Result
+	if err := json.Unmarshal([]byte(`{"x":[1,2],"fun":3,"success":false,"status":1,"message":"Maximum number of iterations has been exceeded."}`), &res); err != nil || res.Status != optimize.IterationLimit || res.X[1] != 2 {
+		t.Errorf("scipy result: %+v %v", res, err)
+	}
+}
@@ -0,0 +1,195 @@
+package optimize
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// GDAMode is the update rule of GDA
+type GDAMode int
+
+const (
+	// GDASimultaneous updates x and y from the gradients at the same point
+	GDASimultaneous GDAMode = iota
+	// GDAAlternating updates y from the gradient at the updated x
+	GDAAlternating
+	// GDAExtragradient updates x and y from the gradients at a
+	// simultaneous trial step, which converges on bilinear problems where
+	// the other rules cycle or diverge
+	GDAExtragradient
+)
+
+// GDA is gradient descent-ascent, finding a saddle point of F(x, y),
+// minimum in x and maximum in y, eg of a Lagrangian in the variables and
+// the multipliers, or of an adversarial objective. The minimization
+// methods can't find saddle points.
+type GDA struct {
+	F func(x, y []float64) float64
+	// GradX and GradY store in grad the gradients of F in x and y,
+	// approximated by central differences if nil
+	GradX, GradY func(grad, x, y []float64)
+	Mode         GDAMode
+	// LearningRate is the step of x, ConstantRate(1e-2) if nil.
+	// LearningRateY is the step of y, LearningRate if nil.
+	LearningRate, LearningRateY LearningRate
+	// Xmin, Xmax, Ymin, Ymax are optional bounds, the iterates being
+	// projected on them
+	Xmin, Xmax, Ymin, Ymax []float64
+	// MaxIter defaults to 1000. GradTol stops with Status GradientThreshold
+	// when the norm of the projected gradient steps is at most GradTol,
+	// 1e-6 if 0.
+	MaxIter  int
+	GradTol  float64
+	Callback func(x, y []float64)
+	Logger   *log.Logger
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of
+	// each iteration, where Iteration.X is x and Iteration.F is NaN
+	Stop     StopCondition
+	Observer Observer
+}
+
+// SaddleInfo is the Extra of the Result of GDA
+type SaddleInfo struct {
+	// Y is the maximizing variable at the solution
+	Y []float64
+	// Residual is the norm of the projected gradient steps at the solution
+	Residual float64
+}
+
+// NewGDA returns a *GDA of F with the extragradient rule and a constant
+// learning rate lr
+func NewGDA(F func(x, y []float64) float64, lr float64) *GDA {
+	return &GDA{F: F, Mode: GDAExtragradient, LearningRate: ConstantRate(lr), MaxIter: 1000, GradTol: 1e-6}
+}
+
+// Solve searches a saddle point from x0, y0. X and F of the Result are x
+// and F(x, y) at the solution, and its Extra is a *SaddleInfo. Iterates
+// becoming non-finite are a Failure.
+func (g *GDA) Solve(x0, y0 []float64) *Result {
+	start := time.Now()
+	if g.F == nil && (g.GradX == nil || g.GradY == nil) {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: "optimize: gda: nil F"}).done(start)
+	}
+	lrX, lrY, maxIter, tol := g.LearningRate, g.LearningRateY, g.MaxIter, g.GradTol
+	if lrX == nil {
+		lrX = ConstantRate(1e-2)
+	}
+	if lrY == nil {
+		lrY = lrX
+	}
+	if maxIter <= 0 {
+		maxIter = 1000
+	}
+	if tol <= 0 {
+		tol = 1e-6
+	}
+	boxX, boxY := Box{Xmin: g.Xmin, Xmax: g.Xmax}, Box{Xmin: g.Ymin, Xmax: g.Ymax}
+	res := &Result{Status: optimize.IterationLimit}
+	gradX := func(grad, x, y []float64) {
+		res.NGrad++
+		if g.GradX != nil {
+			g.GradX(grad, x, y)
+			return
+		}
+		gr := Gradient(func(x []float64) float64 { return g.F(x, y) }, x, &GradientOptions{Formula: FDCentral})
+		res.NFev += 2 * len(x)
+		copy(grad, gr)
+	}
+	gradY := func(grad, x, y []float64) {
+		res.NGrad++
+		if g.GradY != nil {
+			g.GradY(grad, x, y)
+			return
+		}
+		gr := Gradient(func(y []float64) float64 { return g.F(x, y) }, y, &GradientOptions{Formula: FDCentral})
+		res.NFev += 2 * len(y)
+		copy(grad, gr)
+	}
+	// step stores in dst the projection of x + rate*grad. dst may be x.
+	step := func(dst, x, grad []float64, rate float64, box Box) {
+		floats.AddScaledTo(dst, x, rate, grad)
+		box.Project(dst)
+	}
+	finite := func(v []float64) bool {
+		for _, vi := range v {
+			if math.IsNaN(vi) || math.IsInf(vi, 0) {
+				return false
+			}
+		}
+		return true
+	}
+
+	x, noteX, err := feasibleStart(x0, g.Xmin, g.Xmax, g.Logger)
+	if err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
+	y, noteY, err := feasibleStart(y0, g.Ymin, g.Ymax, g.Logger)
+	if err != nil {
+		return (&Result{X: x, F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
+	x, y = append([]float64(nil), x...), append([]float64(nil), y...)
+	for _, note := range []string{noteX, noteY} {
+		if note != "" {
+			res.Warnings = append(res.Warnings, note)
+		}
+	}
+	gx, gy := make([]float64, len(x)), make([]float64, len(y))
+	xt, yt := make([]float64, len(x)), make([]float64, len(y))
+	residual := math.Inf(1)
+	st := newMonitor(g.Stop, g.Observer, "gda", x0)
+	for res.NIter < maxIter {
+		rx, ry := lrX.Rate(res.NIter), lrY.Rate(res.NIter)
+		gradX(gx, x, y)
+		gradY(gy, x, y)
+		// the residual of the unit steps measures stationarity
+		step(xt, x, gx, -1, boxX)
+		step(yt, y, gy, 1, boxY)
+		residual = math.Hypot(floats.Distance(xt, x, 2), floats.Distance(yt, y, 2))
+		if residual <= tol {
+			res.Status, res.Reason = optimize.GradientThreshold, ReasonTolGrad
+			break
+		}
+		switch g.Mode {
+		case GDAAlternating:
+			step(x, x, gx, -rx, boxX)
+			gradY(gy, x, y)
+			step(y, y, gy, ry, boxY)
+		case GDAExtragradient:
+			step(xt, x, gx, -rx, boxX)
+			step(yt, y, gy, ry, boxY)
+			gradX(gx, xt, yt)
+			gradY(gy, xt, yt)
+			step(x, x, gx, -rx, boxX)
+			step(y, y, gy, ry, boxY)
+		default:
+			step(x, x, gx, -rx, boxX)
+			step(y, y, gy, ry, boxY)
+		}
+		res.NIter++
+		if !finite(x) || !finite(y) {
+			res.Status, res.Message = optimize.Failure, "optimize: gda: the iterates diverged"
+			break
+		}
+		if g.Callback != nil {
+			g.Callback(x, y)
+		}
+		if g.Logger != nil {
+			g.Logger.Printf("%d\tx=%.6g\ty=%.6g\tresidual=%.4g\n", res.NIter, x, y, residual)
+		}
+		if st.iterate(res.NIter, res.NFev, x, func() map[string]float64 { return map[string]float64{"residual": residual} }) {
+			break
+		}
+	}
+	st.apply(res)
+	res.X, res.F = x, math.NaN()
+	if g.F != nil {
+		res.F = g.F(x, y)
+		res.NFev++
+	}
+	res.Extra = &SaddleInfo{Y: y, Residual: residual}
+	return st.done(res.done(start))
+}
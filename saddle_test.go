@@ -0,0 +1,51 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleGDA() {
+	// the Lagrangian of min x0^2 + x1^2 under x0 + x1 = 1, of saddle point
+	// x = (0.5, 0.5) and multiplier y = -1
+	lagrangian := func(x, y []float64) float64 { return x[0]*x[0] + x[1]*x[1] + y[0]*(x[0]+x[1]-1) }
+	res := NewGDA(lagrangian, .1).Solve([]float64{0, 0}, []float64{0})
+	fmt.Printf("%s x=%.4f y=%.4f f=%.4f\n", res.Status, res.X, res.Extra.(*SaddleInfo).Y, res.F)
+	// Output:
+	// GradientThreshold x=[0.5000 0.5000] y=[-1.0000] f=0.5000
+}
+
+func TestGDA(t *testing.T) {
+	// simultaneous and alternating GDA don't converge on the bilinear
+	// F = x y, extragradient does
+	bilinear := func(x, y []float64) float64 { return x[0] * y[0] }
+	for _, c := range []struct {
+		mode     GDAMode
+		converge bool
+	}{{GDASimultaneous, false}, {GDAAlternating, false}, {GDAExtragradient, true}} {
+		g := NewGDA(bilinear, .5)
+		g.Mode = c.mode
+		res := g.Solve([]float64{1}, []float64{1})
+		y := res.Extra.(*SaddleInfo).Y
+		if converged := res.Status == optimize.GradientThreshold && math.Abs(res.X[0]) < 1e-5 && math.Abs(y[0]) < 1e-5; converged != c.converge {
+			t.Errorf("mode %d: got %v x=%g y=%g", c.mode, res.Status, res.X, y)
+		}
+	}
+
+	// a bounded problem with analytic gradients: the saddle point of
+	// (x-2)^2 - (y-1)^2 + x y under x <= 1 is x = 1, y = 1.5
+	g := NewGDA(nil, .1)
+	g.GradX = func(grad, x, y []float64) { grad[0] = 2*(x[0]-2) + y[0] }
+	g.GradY = func(grad, x, y []float64) { grad[0] = -2*(y[0]-1) + x[0] }
+	g.Xmax = []float64{1}
+	res := g.Solve([]float64{3}, []float64{0})
+	if y := res.Extra.(*SaddleInfo).Y; res.Status != optimize.GradientThreshold || math.Abs(res.X[0]-1) > 1e-5 || math.Abs(y[0]-1.5) > 1e-5 || len(res.Warnings) != 1 || res.NFev != 0 {
+		t.Errorf("bounded: got %v y=%g", res, y)
+	}
+	if res := NewGDA(bilinear, .1).Solve([]float64{math.NaN()}, []float64{0}); res.Status != optimize.Failure {
+		t.Errorf("NaN start: got %v", res.Status)
+	}
+}
@@ -0,0 +1,186 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// OrthogonalArray returns the orthogonal array of strength 2 of Bose, of
+// q^2 runs of q+1 factors at q levels, q prime: any two columns have each
+// of the q^2 pairs of levels in one run. It is the plan of a screening
+// experiment of up to q+1 factors, with the main effects of the factors
+// estimated independently of each other.
+func OrthogonalArray(q int) ([][]int, error) {
+	if !isPrime(q) {
+		return nil, errors.New("optimize: OrthogonalArray levels must be prime")
+	}
+	runs := make([][]int, q*q)
+	for i := 0; i < q; i++ {
+		for j := 0; j < q; j++ {
+			run := make([]int, q+1)
+			run[0] = i
+			for m := 0; m < q; m++ {
+				run[m+1] = (j + m*i) % q
+			}
+			runs[i*q+j] = run
+		}
+	}
+	return runs, nil
+}
+
+func isPrime(q int) bool {
+	if q < 2 {
+		return false
+	}
+	for d := 2; d*d <= q; d++ {
+		if q%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// OrthogonalSampling is a sampling plan of the box from an OrthogonalArray:
+// each pair of coordinates is stratified in Levels^2 cells, one point in
+// each, which covers the two-dimensional projections evenly with few points
+type OrthogonalSampling struct {
+	// Levels is the prime number of levels of each coordinate, at least the
+	// dimension minus 1. if 0, the smallest one such that Levels^2 >= n.
+	Levels int
+	// Centered places the points at the centers of their levels, at a
+	// random position in the level otherwise
+	Centered bool
+	// Latin positions the points in their levels so that the Levels^2
+	// points are also a latin hypercube, the OA-based latin hypercube of
+	// Tang. It is ignored if Centered.
+	Latin bool
+}
+
+// Init returns n points of consecutive orthogonal arrays of Levels^2
+// points, the levels of each array permuted at random, for Initializer
+func (sp OrthogonalSampling) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	if err := checkInitBounds(x0, xmin, xmax); err != nil {
+		return nil, err
+	}
+	dim, q := len(x0), sp.Levels
+	if q == 0 {
+		for q = 2; q+1 < dim || q*q < n || !isPrime(q); q++ {
+		}
+	}
+	if q+1 < dim {
+		return nil, errors.New("optimize: OrthogonalSampling Levels less than the dimension minus 1")
+	}
+	oa, err := OrthogonalArray(q)
+	if err != nil {
+		return nil, err
+	}
+	xs := make([][]float64, 0, n)
+	for len(xs) < n {
+		block := make([][]float64, len(oa))
+		for k := range block {
+			block[k] = make([]float64, dim)
+		}
+		for i := 0; i < dim; i++ {
+			levels := rnd.Perm(q)
+			// the ranks of the runs within their levels, for Latin
+			rank := make([]int, len(oa))
+			if sp.Latin && !sp.Centered {
+				for l := 0; l < q; l++ {
+					perm, r := rnd.Perm(q), 0
+					for k, run := range oa {
+						if run[i] == l {
+							rank[k] = perm[r]
+							r++
+						}
+					}
+				}
+			}
+			for k, run := range oa {
+				l := float64(levels[run[i]])
+				var u float64
+				switch {
+				case sp.Centered:
+					u = (l + .5) / float64(q)
+				case sp.Latin:
+					u = (l*float64(q) + float64(rank[k]) + rnd.Float64()) / float64(q*q)
+				default:
+					u = (l + rnd.Float64()) / float64(q)
+				}
+				block[k][i] = xmin[i] + u*(xmax[i]-xmin[i])
+			}
+		}
+		for _, k := range rnd.Perm(len(block)) {
+			if len(xs) < n {
+				xs = append(xs, block[k])
+			}
+		}
+	}
+	return xs, nil
+}
+
+// StratifiedSampling is a sampling plan of the box divided into a grid of
+// Strata[i] slices of coordinate i, Strata[0] for all if its length is 1,
+// a point in each cell. With Centered it is a grid search.
+type StratifiedSampling struct {
+	// Strata are the numbers of slices, the largest number s of slices of
+	// each coordinate such that s^dim <= n if nil
+	Strata []int
+	// Centered places the points at the centers of their cells, at a
+	// random position in the cell otherwise
+	Centered bool
+}
+
+// Init returns n points, in the cells in a random order, a new order once
+// each cell has a point, for Initializer
+func (ss StratifiedSampling) Init(n int, x0, xmin, xmax []float64, rnd *rand.Rand) ([][]float64, error) {
+	if err := checkInitBounds(x0, xmin, xmax); err != nil {
+		return nil, err
+	}
+	dim := len(x0)
+	strata := make([]int, dim)
+	cells := 1
+	for i := range strata {
+		switch {
+		case len(ss.Strata) == 1:
+			strata[i] = ss.Strata[0]
+		case len(ss.Strata) == dim:
+			strata[i] = ss.Strata[i]
+		case ss.Strata == nil:
+			strata[i] = max(1, int(math.Floor(math.Pow(float64(n), 1/float64(dim))+1e-9)))
+		default:
+			return nil, errors.New("optimize: StratifiedSampling Strata of another dimension")
+		}
+		if strata[i] < 1 {
+			return nil, errors.New("optimize: StratifiedSampling Strata must be positive")
+		}
+		if cells *= strata[i]; cells > 1<<24 {
+			return nil, errors.New("optimize: StratifiedSampling of more than 2^24 cells")
+		}
+	}
+	xs := make([][]float64, 0, n)
+	for len(xs) < n {
+		for _, cell := range rnd.Perm(cells) {
+			if len(xs) == n {
+				break
+			}
+			x := make([]float64, dim)
+			for i := range x {
+				u := .5
+				if !ss.Centered {
+					u = rnd.Float64()
+				}
+				x[i] = xmin[i] + (float64(cell%strata[i])+u)/float64(strata[i])*(xmax[i]-xmin[i])
+				cell /= strata[i]
+			}
+			xs = append(xs, x)
+		}
+	}
+	return xs, nil
+}
+
+var (
+	_ Initializer = OrthogonalSampling{}
+	_ Initializer = StratifiedSampling{}
+)
@@ -0,0 +1,127 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func ExampleOrthogonalArray() {
+	// the L9 array of 4 factors at 3 levels
+	oa, _ := OrthogonalArray(3)
+	for _, run := range oa {
+		fmt.Println(run)
+	}
+	// Output:
+	// [0 0 0 0]
+	// [0 1 1 1]
+	// [0 2 2 2]
+	// [1 0 1 2]
+	// [1 1 2 0]
+	// [1 2 0 1]
+	// [2 0 2 1]
+	// [2 1 0 2]
+	// [2 2 1 0]
+}
+
+func ExampleStratifiedSampling() {
+	// a grid search of 5 by 5 points, as a screening before a local search
+	qs := NewQuasiRandomSearch([]float64{-2, -2}, []float64{2, 2})
+	qs.Design, qs.MaxFev = StratifiedSampling{Centered: true}, 26
+	res := qs.Minimize(func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+1)*(x[1]+1) }, []float64{0, 0})
+	fmt.Printf("%d evaluations, best %.1f\n", res.NFev, res.X)
+	// Output:
+	// 26 evaluations, best [0.8 -0.8]
+}
+
+// cellCounts returns the numbers of points of xs of the unit cube in the
+// cells of q slices of the coordinates i and j
+func cellCounts(xs [][]float64, i, j, q int) map[[2]int]int {
+	counts := map[[2]int]int{}
+	for _, x := range xs {
+		counts[[2]int{int(x[i] * float64(q)), int(x[j] * float64(q))}]++
+	}
+	return counts
+}
+
+func TestOrthogonalSampling(t *testing.T) {
+	oa, err := OrthogonalArray(5)
+	if err != nil || len(oa) != 25 || len(oa[0]) != 6 {
+		t.Fatalf("OrthogonalArray(5): %v %v", oa, err)
+	}
+	for a := 0; a < 6; a++ {
+		for b := 0; b < a; b++ {
+			pairs := map[[2]int]bool{}
+			for _, run := range oa {
+				pairs[[2]int{run[a], run[b]}] = true
+			}
+			if len(pairs) != 25 {
+				t.Errorf("columns %d and %d: %d pairs of levels", a, b, len(pairs))
+			}
+		}
+	}
+	if _, err := OrthogonalArray(4); err == nil {
+		t.Error("expected an error for 4 levels")
+	}
+
+	x0, lo, hi := make([]float64, 5), make([]float64, 5), ones(5)
+	for _, sp := range []OrthogonalSampling{{}, {Centered: true}, {Latin: true}} {
+		xs, err := sp.Init(25, x0, lo, hi, rand.New(rand.NewSource(1)))
+		if err != nil || len(xs) != 25 {
+			t.Fatalf("%+v: %d points, %v", sp, len(xs), err)
+		}
+		for i := 0; i < 5; i++ {
+			for j := 0; j < i; j++ {
+				if c := cellCounts(xs, i, j, 5); len(c) != 25 {
+					t.Errorf("%+v: coordinates %d and %d in %d cells", sp, i, j, len(c))
+				}
+			}
+			if sp.Latin && len(cellCounts(xs, i, i, 25)) != 25 {
+				t.Errorf("%+v: coordinate %d not a latin hypercube", sp, i)
+			}
+			if sp.Centered && math.Abs(xs[0][i]*5-math.Floor(xs[0][i]*5)-.5) > 1e-9 {
+				t.Errorf("%+v: point %v not centered", sp, xs[0])
+			}
+		}
+	}
+	// the smallest prime levels for n points and the dimension
+	if xs, _ := (OrthogonalSampling{}).Init(30, x0, lo, hi, rand.New(rand.NewSource(1))); len(xs) != 30 || len(cellCounts(xs, 0, 1, 7)) != 30 {
+		t.Errorf("30 points of an array of 7 levels: %d points", len(xs))
+	}
+	if xs, _ := (OrthogonalSampling{Levels: 5}).Init(50, x0, lo, hi, rand.New(rand.NewSource(1))); len(xs) != 50 || len(cellCounts(xs[25:], 3, 4, 5)) != 25 {
+		t.Errorf("two arrays of 25 points: %d points", len(xs))
+	}
+	for _, sp := range []OrthogonalSampling{{Levels: 3}, {Levels: 6}} {
+		if _, err := sp.Init(9, x0, lo, hi, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("%+v: expected an error in dimension 5", sp)
+		}
+	}
+}
+
+func TestStratifiedSampling(t *testing.T) {
+	x0, lo, hi := []float64{0, 0}, []float64{0, 0}, []float64{1, 1}
+	xs, err := StratifiedSampling{}.Init(18, x0, lo, hi, rand.New(rand.NewSource(1)))
+	if err != nil || len(xs) != 18 || len(cellCounts(xs[:16], 0, 1, 4)) != 16 {
+		t.Errorf("18 points of 4 by 4 cells: %v %v", xs, err)
+	}
+	xs, _ = StratifiedSampling{Strata: []int{2, 3}, Centered: true}.Init(6, x0, lo, []float64{2, 3}, rand.New(rand.NewSource(1)))
+	grid := map[[2]float64]bool{}
+	for _, x := range xs {
+		grid[[2]float64{x[0], x[1]}] = true
+	}
+	for _, x := range [][2]float64{{.5, .5}, {1.5, .5}, {.5, 1.5}, {1.5, 1.5}, {.5, 2.5}, {1.5, 2.5}} {
+		if !grid[x] {
+			t.Errorf("grid %v without %v", xs, x)
+		}
+	}
+	for _, ss := range []StratifiedSampling{{Strata: []int{2, 2, 2}}, {Strata: []int{0}}, {Strata: []int{1 << 13}}} {
+		if _, err := ss.Init(4, x0, lo, hi, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("%+v: expected an error", ss)
+		}
+	}
+	if _, err := (StratifiedSampling{}).Init(4, x0, lo, nil, nil); err == nil {
+		t.Error("expected an error without bounds")
+	}
+}
@@ -0,0 +1,158 @@
+package optimize
+
+import (
+	"math"
+)
+
+// MultiObjective is a multi-objective function returning the objective vector at x
+type MultiObjective func(x []float64) []float64
+
+// MinimizeFunc minimizes fun from x0 and returns the solution. It adapts any
+// single-objective method (see PowellMinimize)
+type MinimizeFunc func(fun func([]float64) float64, x0 []float64) []float64
+
+// PowellMinimize is a MinimizeFunc using a PowellMinimizer with default tolerances
+func PowellMinimize(fun func([]float64) float64, x0 []float64) []float64 {
+	return NewPowellMinimizer().Minimize(fun, x0).X
+}
+
+// WeightedSum returns the scalarization sum_i w_i f_i(x).
+// It only reaches the convex parts of the Pareto front.
+func WeightedSum(f MultiObjective, w []float64) func([]float64) float64 {
+	return func(x []float64) float64 {
+		s := 0.
+		for i, fi := range f(x) {
+			s += w[i] * fi
+		}
+		return s
+	}
+}
+
+// EpsilonConstraint returns the scalarization minimizing f_k(x) subject to
+// f_j(x) <= eps[j] for j != k, the constraints being handled by a quadratic
+// penalty of weight penalty.
+func EpsilonConstraint(f MultiObjective, k int, eps []float64, penalty float64) func([]float64) float64 {
+	return func(x []float64) float64 {
+		fx := f(x)
+		s := fx[k]
+		for j, fj := range fx {
+			if j != k && fj > eps[j] {
+				s += penalty * (fj - eps[j]) * (fj - eps[j])
+			}
+		}
+		return s
+	}
+}
+
+// AchievementScalarizing returns the augmented achievement scalarizing function
+// max_i w_i (f_i(x) - ref_i) + rho sum_i w_i (f_i(x) - ref_i) of Wierzbicki,
+// with rho = 1e-6. Its minimizers are Pareto optimal, including on non-convex
+// parts of the front.
+func AchievementScalarizing(f MultiObjective, ref, w []float64) func([]float64) float64 {
+	const rho = 1e-6
+	return func(x []float64) float64 {
+		m, s := math.Inf(-1), 0.
+		for i, fi := range f(x) {
+			d := w[i] * (fi - ref[i])
+			m = math.Max(m, d)
+			s += d
+		}
+		return m + rho*s
+	}
+}
+
+// Scalarizer builds the single-objective problem of parameter w (eg weights)
+type Scalarizer func(f MultiObjective, w []float64) func([]float64) float64
+
+// SimplexLattice returns the weight vectors of m objectives whose components
+// are multiples of 1/divisions and sum to 1 (Das and Dennis).
+func SimplexLattice(m, divisions int) [][]float64 {
+	var ws [][]float64
+	w := make([]int, m)
+	var rec func(i, left int)
+	rec = func(i, left int) {
+		if i == m-1 {
+			w[i] = left
+			v := make([]float64, m)
+			for j := range w {
+				v[j] = float64(w[j]) / float64(divisions)
+			}
+			ws = append(ws, v)
+			return
+		}
+		for k := left; k >= 0; k-- {
+			w[i] = k
+			rec(i+1, left-k)
+		}
+	}
+	if m > 0 {
+		rec(0, divisions)
+	}
+	return ws
+}
+
+// FrontSweep traces the Pareto front of a multi-objective problem by solving
+// the sequence of single-objective problems given by Scalarizer and Params.
+type FrontSweep struct {
+	Func MultiObjective
+	// Scalarizer defaults to WeightedSum
+	Scalarizer Scalarizer
+	// Params are the parameters of the scalarized problems. defaults to
+	// SimplexLattice(NumObjectives, Divisions)
+	Params                   [][]float64
+	NumObjectives, Divisions int
+	// Minimize defaults to PowellMinimize
+	Minimize MinimizeFunc
+	// WarmStart starts each problem from the previous solution instead of x0
+	WarmStart bool
+	// Violation, if not nil, returns the constraint violation at x (see
+	// Violation). It is added to scalarized problems with weight Penalty
+	// (defaults to 1e3) and solutions are archived with constrained-domination.
+	Violation func(x []float64) float64
+	Penalty   float64
+	// Archive receives the solutions. A new one is created if nil.
+	Archive *ParetoArchive
+}
+
+// Run solves the scalarized problems and returns the archive of non-dominated solutions
+func (fs *FrontSweep) Run(x0 []float64) *ParetoArchive {
+	scalarizer, minimize, params := fs.Scalarizer, fs.Minimize, fs.Params
+	if scalarizer == nil {
+		scalarizer = WeightedSum
+	}
+	if minimize == nil {
+		minimize = PowellMinimize
+	}
+	if params == nil {
+		divisions := fs.Divisions
+		if divisions <= 0 {
+			divisions = 10
+		}
+		params = SimplexLattice(fs.NumObjectives, divisions)
+	}
+	if fs.Archive == nil {
+		fs.Archive = NewParetoArchive(0)
+	}
+	penalty := fs.Penalty
+	if penalty <= 0 {
+		penalty = 1e3
+	}
+	start := x0
+	for _, w := range params {
+		fun := scalarizer(fs.Func, w)
+		violation := 0.
+		if fs.Violation != nil {
+			scalar := fun
+			fun = func(x []float64) float64 { return scalar(x) + penalty*fs.Violation(x) }
+		}
+		x := minimize(fun, start)
+		if fs.Violation != nil {
+			violation = fs.Violation(x)
+		}
+		fs.Archive.AddConstrained(x, fs.Func(x), violation)
+		if fs.WarmStart {
+			start = x
+		}
+	}
+	return fs.Archive
+}
@@ -0,0 +1,86 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+// schaffer is the Schaffer function N.1: f1 = x^2, f2 = (x-2)^2
+func schaffer(x []float64) []float64 {
+	return []float64{x[0] * x[0], (x[0] - 2) * (x[0] - 2)}
+}
+
+func ExampleFrontSweep() {
+	fs := &FrontSweep{Func: schaffer, NumObjectives: 2, Divisions: 4}
+	pa := fs.Run([]float64{0})
+	sort.Slice(pa.Points, func(i, j int) bool { return pa.Points[i].X[0] < pa.Points[j].X[0] })
+	for _, p := range pa.Points {
+		fmt.Printf("%.2f %.2f\n", p.X, p.F)
+	}
+	// Output:
+	// [0.00] [0.00 4.00]
+	// [0.50] [0.25 2.25]
+	// [1.00] [1.00 1.00]
+	// [1.50] [2.25 0.25]
+	// [2.00] [4.00 0.00]
+}
+
+func TestScalarization(t *testing.T) {
+	if ws := SimplexLattice(3, 2); len(ws) != 6 {
+		t.Errorf("expected 6 weight vectors, got %v", ws)
+	}
+	// Fonseca-Fleming function has a non-convex front
+	nonConvex := func(x []float64) []float64 {
+		return []float64{1 - math.Exp(-(x[0]-1)*(x[0]-1)), 1 - math.Exp(-(x[0]+1)*(x[0]+1))}
+	}
+	asf := &FrontSweep{
+		Func:          nonConvex,
+		NumObjectives: 2,
+		Divisions:     4,
+		Scalarizer: func(f MultiObjective, w []float64) func([]float64) float64 {
+			return AchievementScalarizing(f, []float64{0, 0}, []float64{w[0] + .01, w[1] + .01})
+		},
+	}
+	if n := asf.Run([]float64{.5}).Len(); n != 5 {
+		t.Errorf("asf should find points of the non-convex front, got %d", n)
+	}
+	eps := &FrontSweep{
+		Func:   schaffer,
+		Params: [][]float64{{0, 1}, {0, 2}, {0, 3}},
+		Scalarizer: func(f MultiObjective, e []float64) func([]float64) float64 {
+			return EpsilonConstraint(f, 0, e, 1e4)
+		},
+	}
+	eps.Run([]float64{1})
+	for _, p := range eps.Archive.Points {
+		if p.F[1] > 3.01 {
+			t.Errorf("constraint violated: %v", p.F)
+		}
+	}
+	if eps.Archive.Len() != 3 {
+		t.Errorf("expected 3 points, got %d", eps.Archive.Len())
+	}
+}
+
+func TestFrontSweepConstrained(t *testing.T) {
+	// schaffer subject to x >= 1
+	fs := &FrontSweep{
+		Func:          schaffer,
+		NumObjectives: 2,
+		Divisions:     4,
+		Violation: func(x []float64) float64 {
+			return Violation([]float64{1 - x[0]}, nil)
+		},
+	}
+	pa := fs.Run([]float64{0})
+	if pa.Len() < 3 {
+		t.Errorf("expected at least 3 points, got %v", pa.Points)
+	}
+	for _, p := range pa.Points {
+		if p.X[0] < 1-1e-3 || p.Violation > 1e-3 {
+			t.Errorf("unexpected infeasible point %v", p)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package optimize
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Scaling is a Minimizer running Minimizer on normalized variables, for
+// problems whose variables differ by orders of magnitude: a component with
+// finite bounds is mapped to [0, 1], and another one is divided by the
+// magnitude of its starting value. The Result is mapped back to the
+// original variables.
+//
+// The bounds are Xmin and Xmax, or else the bounds of Minimizer, eg of a
+// SPSA or GN_CMAES solver, which are replaced by the normalized ones during
+// the run. The Callback and Repair of Minimizer are called with the
+// original variables, its Observer and its Tolerances apply to the
+// normalized ones.
+type Scaling struct {
+	Minimizer  Minimizer
+	Xmin, Xmax []float64
+	// MinScale is the smallest divisor of a component without finite
+	// bounds, the scale of a starting value near 0. If 0, a starting value
+	// 0 has the scale 1.
+	MinScale float64
+}
+
+// Scales returns the offsets and the scales of the components of x0, the
+// normalized variables being z = (x - offset)/scale
+func (s *Scaling) Scales(x0 []float64) (offset, scale []float64) {
+	bt := BoxTransform{Xmin: s.Xmin, Xmax: s.Xmax}
+	offset, scale = make([]float64, len(x0)), make([]float64, len(x0))
+	for i, xi := range x0 {
+		if lo, hi, hasLo, hasHi := bt.bounds(i); hasLo && hasHi && hi > lo {
+			offset[i], scale[i] = lo, hi-lo
+			continue
+		}
+		if scale[i] = math.Max(math.Abs(xi), s.MinScale); scale[i] == 0 {
+			scale[i] = 1
+		}
+	}
+	return offset, scale
+}
+
+// Minimize minimizes f from x0 with the normalized variables. The Warm of
+// the Result, a state of the normalized problem, is dropped. Invalid
+// bounds are the Message of a Result with Status Failure.
+func (s *Scaling) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	var fields solverFields
+	if c, ok := s.Minimizer.(configurable); ok {
+		fields = c.fields()
+	}
+	sc := *s
+	if fields.xmin != nil && len(sc.Xmin) == 0 {
+		sc.Xmin = *fields.xmin
+	}
+	if fields.xmax != nil && len(sc.Xmax) == 0 {
+		sc.Xmax = *fields.xmax
+	}
+	if err := ValidateBounds(len(x0), sc.Xmin, sc.Xmax); err != nil {
+		return (&Result{X: append([]float64(nil), x0...), F: math.NaN(), Status: optimize.Failure, Message: err.Error()}).done(start)
+	}
+	offset, scale := sc.Scales(x0)
+	toX := func(z []float64) []float64 {
+		x := make([]float64, len(z))
+		for i, zi := range z {
+			x[i] = offset[i] + scale[i]*zi
+		}
+		return x
+	}
+	toZ := func(dst, x []float64) []float64 {
+		if dst == nil {
+			dst = make([]float64, len(x))
+		}
+		for i, xi := range x {
+			dst[i] = (xi - offset[i]) / scale[i]
+		}
+		return dst
+	}
+
+	// run Minimizer with its bounds, callback and repair on z
+	if fields.xmin != nil {
+		defer func(xmin []float64) { *fields.xmin = xmin }(*fields.xmin)
+		*fields.xmin = nil
+		if len(sc.Xmin) != 0 {
+			*fields.xmin = toZ(nil, sc.Xmin)
+		}
+	}
+	if fields.xmax != nil {
+		defer func(xmax []float64) { *fields.xmax = xmax }(*fields.xmax)
+		*fields.xmax = nil
+		if len(sc.Xmax) != 0 {
+			*fields.xmax = toZ(nil, sc.Xmax)
+		}
+	}
+	if fields.callback != nil && *fields.callback != nil {
+		defer func(cb func([]float64)) { *fields.callback = cb }(*fields.callback)
+		cb := *fields.callback
+		*fields.callback = func(z []float64) { cb(toX(z)) }
+	}
+	if fields.repair != nil && *fields.repair != nil {
+		defer func(r Repair) { *fields.repair = r }(*fields.repair)
+		r := *fields.repair
+		*fields.repair = RepairFunc(func(z []float64) {
+			x := toX(z)
+			r.Repair(x)
+			toZ(z, x)
+		})
+	}
+	res := s.Minimizer.Minimize(func(z []float64) float64 { return f(toX(z)) }, toZ(nil, x0))
+	res.X, res.Warm = toX(res.X), nil
+	return res
+}
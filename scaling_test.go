@@ -0,0 +1,79 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// badlyScaled is a quadratic of minimum 0 at (1e-3, 1e3)
+func badlyScaled(x []float64) float64 {
+	a, b := x[0]/1e-3-1, x[1]/1e3-1
+	return a*a + a*b + b*b
+}
+
+func ExampleScaling() {
+	// the steps of SPSA, of the same size for both variables, diverge on
+	// variables of magnitudes 1e-3 and 1e3
+	x0 := []float64{-1.2e-3, 2e3}
+	sp, _ := NewAlgorithm("LN_SPSA", WithRNG(rand.NewSource(1)))
+	fmt.Printf("unscaled f=%.3g\n", sp.Minimize(badlyScaled, x0).F)
+	sp, _ = NewAlgorithm("LN_SPSA", WithRNG(rand.NewSource(1)))
+	res := (&Scaling{Minimizer: sp}).Minimize(badlyScaled, x0)
+	fmt.Printf("scaled x=[%.4g %.4g] f<1e-12 %t\n", res.X[0], res.X[1], res.F < 1e-12)
+	// Output:
+	// unscaled f=2.85e+37
+	// scaled x=[0.001 1000] f<1e-12 true
+}
+
+func TestScaling(t *testing.T) {
+	s := &Scaling{Xmin: []float64{-1, 10, math.Inf(-1)}, Xmax: []float64{3, 10, math.Inf(1)}}
+	offset, scale := s.Scales([]float64{2, -1e6, 0})
+	if fmt.Sprint(offset, scale) != "[-1 0 0] [4 1e+06 1]" {
+		t.Errorf("got %v %v", offset, scale)
+	}
+	s.MinScale = 1e7
+	if _, scale := s.Scales([]float64{2, -1e6, 0}); fmt.Sprint(scale) != "[4 1e+07 1e+07]" {
+		t.Errorf("got %v", scale)
+	}
+
+	// the bounds of the Minimizer, its callback and repair
+	xmin, xmax := []float64{-5e-3, -5e3}, []float64{5e-3, 5e3}
+	var cbCalls, cbOut, repairOut int
+	sp := NewSPSA()
+	sp.Src, sp.Xmin, sp.Xmax = rand.NewSource(1), xmin, xmax
+	sp.Callback = func(x []float64) {
+		cbCalls++
+		if x[0] < xmin[0] || x[0] > xmax[0] || x[1] < xmin[1] || x[1] > xmax[1] {
+			cbOut++
+		}
+	}
+	sp.Repair = RepairFunc(func(x []float64) {
+		// x[1] is in [0, 1] if Repair gets the normalized variables
+		if math.Abs(x[1]) < 10 {
+			repairOut++
+		}
+		x[0] = math.Max(x[0], xmin[0])
+	})
+	res := (&Scaling{Minimizer: sp}).Minimize(badlyScaled, []float64{-1.2e-3, 2e3})
+	if math.Abs(res.X[0]-1e-3) > 1e-9 || math.Abs(res.X[1]-1e3) > 1e-3 || res.Warm != nil {
+		t.Errorf("got %v", res)
+	}
+	if cbCalls == 0 || cbOut != 0 || repairOut != 0 || &sp.Xmin[0] != &xmin[0] || &sp.Xmax[0] != &xmax[0] || sp.Callback == nil {
+		t.Errorf("got %d %d %v %v", cbOut, repairOut, sp.Xmin, sp.Xmax)
+	}
+
+	// scaling of a Minimizer without bounds
+	pm := NewPowellMinimizer()
+	if res := (&Scaling{Minimizer: pm}).Minimize(badlyScaled, []float64{-1.2e-3, 2e3}); res.Status != optimize.MethodConverge || math.Abs(res.X[1]-1e3) > 1e-6 {
+		t.Errorf("got %v", res)
+	}
+
+	// invalid bounds
+	if res := (&Scaling{Minimizer: pm, Xmin: []float64{0}}).Minimize(badlyScaled, []float64{1, 1}); res.Status != optimize.Failure || res.Message != "optimize: len(Xmin) = 1, expected 2" {
+		t.Errorf("got %v", res)
+	}
+}
@@ -0,0 +1,129 @@
+package optimize
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+)
+
+// RiskMeasure is the functional of the values of the scenarios minimized by
+// a ScenarioObjective
+type RiskMeasure int
+
+const (
+	// RiskMean is the mean of the values
+	RiskMean RiskMeasure = iota
+	// RiskWorstCase is the largest value
+	RiskWorstCase
+	// RiskCVaR is the conditional value at risk of level Alpha, the mean of
+	// the worst fraction 1-Alpha of the values
+	RiskCVaR
+)
+
+// ScenarioFunc returns the SeededFunc evaluating f under the scenario drawn
+// by sample from the seed, eg for a ScenarioObjective or the Seeded of a
+// NoisyObjective
+func ScenarioFunc(f func(x, scenario []float64) float64, sample func(rnd *rand.Rand) []float64) SeededFunc {
+	return func(x []float64, seed uint64) float64 {
+		return f(x, sample(rand.New(rand.NewSource(seed))))
+	}
+}
+
+// ScenarioObjective is the risk of a stochastic objective over samples of
+// its scenarios, eg for a design robust over the operating conditions.
+// The k-th scenario uses the seed of the k-th replication of a
+// NoisyObjective, so that all the points are evaluated on the same
+// scenarios and the minimizers see a deterministic objective, the sample
+// average approximation of the risk. If the mean alone is minimized, a
+// NoisyObjective with Seeded set adapts instead the number of samples.
+// It is safe for concurrent use if Func is.
+type ScenarioObjective struct {
+	// Func is the objective under the scenario drawn from seed
+	Func SeededFunc
+	// Samples is the number of scenarios, 32 if 0
+	Samples int
+	Risk    RiskMeasure
+	// Alpha is the level of RiskCVaR in [0, 1), 0.9 if 0
+	Alpha float64
+	// CRN, if not nil, mixes its current seed into the seeds of the
+	// scenarios, which are drawn again at each NextSeed, ie at each
+	// iteration of the methods supporting common random numbers
+	CRN *CommonRandomNumbers
+
+	mu          sync.Mutex
+	Evaluations int
+}
+
+// Values returns the values of Func at x over the scenarios
+func (so *ScenarioObjective) Values(x []float64) []float64 {
+	m := so.Samples
+	if m <= 0 {
+		m = 32
+	}
+	var base uint64
+	if so.CRN != nil {
+		base = so.CRN.Seed()
+	}
+	v := make([]float64, m)
+	for k := range v {
+		v[k] = so.Func(x, base^replicationSeed(k))
+	}
+	so.mu.Lock()
+	so.Evaluations += m
+	so.mu.Unlock()
+	return v
+}
+
+// Eval returns the risk of Func at x
+func (so *ScenarioObjective) Eval(x []float64) float64 {
+	v := so.Values(x)
+	switch so.Risk {
+	case RiskWorstCase:
+		return floats.Max(v)
+	case RiskCVaR:
+		return cvar(v, so.alpha())
+	}
+	return floats.Sum(v) / float64(len(v))
+}
+
+func (so *ScenarioObjective) alpha() float64 {
+	if so.Alpha == 0 {
+		return .9
+	}
+	return so.Alpha
+}
+
+// cvar returns the conditional value at risk of level alpha of the samples
+// v, the minimum in eta of ruFunction. It is the mean of v for alpha 0.
+func cvar(v []float64, alpha float64) float64 {
+	return ruFunction(v, quantile(v, alpha), alpha)
+}
+
+// quantile returns the value at risk of level alpha of the samples v, their
+// lower alpha quantile
+func quantile(v []float64, alpha float64) float64 {
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	k := int(math.Ceil(alpha*float64(len(s)))) - 1
+	if k < 0 {
+		k = 0
+	}
+	if k >= len(s) {
+		k = len(s) - 1
+	}
+	return s[k]
+}
+
+// ruFunction is the function of Rockafellar and Uryasev
+// eta + mean(max(v - eta, 0))/(1-alpha), whose minimum in eta is the CVaR
+// of level alpha of the samples v, reached at their VaR
+func ruFunction(v []float64, eta, alpha float64) float64 {
+	excess := 0.
+	for _, vi := range v {
+		excess += math.Max(vi-eta, 0)
+	}
+	return eta + excess/((1-alpha)*float64(len(v)))
+}
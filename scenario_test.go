@@ -0,0 +1,73 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+)
+
+func ExampleScenarioObjective() {
+	// the target of the design is uncertain, with a skewed second component
+	f := ScenarioFunc(func(x, s []float64) float64 {
+		return (x[0]-s[0])*(x[0]-s[0]) + (x[1]-s[1])*(x[1]-s[1])
+	}, func(rnd *rand.Rand) []float64 {
+		return []float64{rnd.NormFloat64(), rnd.ExpFloat64()}
+	})
+	for _, risk := range []RiskMeasure{RiskMean, RiskCVaR, RiskWorstCase} {
+		so := &ScenarioObjective{Func: f, Samples: 64, Risk: risk}
+		res := NewPowellMinimizer().Minimize(so.Eval, []float64{0, 0})
+		fmt.Printf("x=%.3f risk=%.3f\n", res.X, res.F)
+	}
+	// Output:
+	// x=[0.229 0.960] risk=2.138
+	// x=[0.098 1.713] risk=8.208
+	// x=[0.759 1.669] risk=14.209
+}
+
+func TestScenarioObjective(t *testing.T) {
+	f := ScenarioFunc(func(x, s []float64) float64 { return x[0] * s[0] }, func(rnd *rand.Rand) []float64 {
+		return []float64{rnd.NormFloat64()}
+	})
+	so := &ScenarioObjective{Func: f, Samples: 10}
+	x := []float64{1}
+	v := so.Values(x)
+	if len(v) != 10 || so.Evaluations != 10 {
+		t.Fatalf("got %d values, %d evaluations", len(v), so.Evaluations)
+	}
+	if w := so.Values(x); !floats.Equal(v, w) {
+		t.Errorf("the scenarios should be the same at each evaluation: %v %v", v, w)
+	}
+	mean := floats.Sum(v) / 10
+	if got := so.Eval(x); math.Abs(got-mean) > 1e-12 {
+		t.Errorf("mean: got %g want %g", got, mean)
+	}
+	so.Risk = RiskWorstCase
+	if got := so.Eval(x); got != floats.Max(v) {
+		t.Errorf("worst case: got %g want %g", got, floats.Max(v))
+	}
+	// the CVaR of level 0.8 of 10 values is the mean of the 2 largest ones
+	so.Risk, so.Alpha = RiskCVaR, .8
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	if got, want := so.Eval(x), (s[8]+s[9])/2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("CVaR: got %g want %g", got, want)
+	}
+	if got := cvar(v, 0); math.Abs(got-mean) > 1e-12 {
+		t.Errorf("CVaR of level 0: got %g want the mean %g", got, mean)
+	}
+
+	so.CRN = &CommonRandomNumbers{Src: rand.NewSource(1)}
+	so.CRN.NextSeed()
+	v1 := so.Values(x)
+	if w := so.Values(x); !floats.Equal(v1, w) {
+		t.Errorf("the scenarios should be the same for a seed")
+	}
+	so.CRN.NextSeed()
+	if w := so.Values(x); floats.Equal(v1, w) {
+		t.Errorf("NextSeed should draw new scenarios")
+	}
+}
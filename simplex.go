@@ -0,0 +1,57 @@
+package optimize
+
+import (
+	"math"
+)
+
+// SimplexReparam maps unconstrained variables z of dimension N-1 to weights w
+// of dimension N, with w>0 and sum(w)=1, using softmax with the last logit fixed to 0.
+// It allows any unconstrained method to minimize a function of weights.
+// For methods supporting a Projection, Simplex can be used instead.
+type SimplexReparam struct {
+	N int
+}
+
+// Weights returns softmax(z,0) in dst. dst is allocated if nil.
+func (sr SimplexReparam) Weights(dst, z []float64) []float64 {
+	if len(z) != sr.N-1 {
+		panic("simplex: incorrect z length")
+	}
+	dst = resize(dst, sr.N)
+	zmax := 0.
+	for _, zi := range z {
+		zmax = math.Max(zmax, zi)
+	}
+	sum := 0.
+	for i := range dst {
+		zi := 0.
+		if i < len(z) {
+			zi = z[i]
+		}
+		dst[i] = math.Exp(zi - zmax)
+		sum += dst[i]
+	}
+	for i := range dst {
+		dst[i] /= sum
+	}
+	return dst
+}
+
+// Logits returns z such that Weights(z)=w in dst. w components must be > 0.
+func (sr SimplexReparam) Logits(dst, w []float64) []float64 {
+	if len(w) != sr.N {
+		panic("simplex: incorrect w length")
+	}
+	dst = resize(dst, sr.N-1)
+	for i := range dst {
+		dst[i] = math.Log(w[i]) - math.Log(w[sr.N-1])
+	}
+	return dst
+}
+
+// Func returns a function of z evaluating f at Weights(z)
+func (sr SimplexReparam) Func(f func(w []float64) float64) func(z []float64) float64 {
+	return func(z []float64) float64 {
+		return f(sr.Weights(nil, z))
+	}
+}
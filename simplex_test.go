@@ -0,0 +1,25 @@
+package optimize
+
+import (
+	"fmt"
+)
+
+// minimum variance portfolio of 3 uncorrelated assets with variances 1,2,4
+// optimal weights are proportional to 1/variance: 4/7,2/7,1/7
+func portfolioVariance(w []float64) float64 {
+	return w[0]*w[0] + 2*w[1]*w[1] + 4*w[2]*w[2]
+}
+
+func ExampleSimplexReparam() {
+	sr := SimplexReparam{N: 3}
+	pm := NewPowellMinimizer()
+	pm.Xtol, pm.Ftol = 1e-8, 1e-10
+	var z []float64
+	pm.Callback = func(x []float64) { z = x }
+	pm.Minimize(sr.Func(portfolioVariance), []float64{0, 0})
+	fmt.Printf("%.4f\n", sr.Weights(nil, z))
+	fmt.Printf("%.4f\n", sr.Logits(nil, []float64{.5, .25, .25}))
+	// Output:
+	// [0.5714 0.2857 0.1429]
+	// [0.6931 0.0000]
+}
@@ -0,0 +1,114 @@
+//go:build go1.21
+// +build go1.21
+
+package optimize
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+)
+
+// SlogObserver is an Observer emitting structured records to a
+// *slog.Logger: "start" with method and x0, "iteration" with iter, nfev, f, x
+// and the method diagnostics (eg step, sigma), "done" with status, f, niter,
+// nfev and elapsed, and, if Evaluations is true, "evaluation" records at
+// level Debug. It is the structured alternative to the Logger fields.
+type SlogObserver struct {
+	Logger *slog.Logger
+	// Level is the level of start, iteration and done records. defaults to Info
+	Level       slog.Level
+	Evaluations bool
+}
+
+var _ Observer = &SlogObserver{}
+
+// NewSlogObserver returns a SlogObserver logging to logger, or to
+// slog.Default() if logger is nil
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{Logger: logger, Level: slog.LevelInfo}
+}
+
+// WithSlog sets a SlogObserver logging to logger
+func WithSlog(logger *slog.Logger) Option {
+	return WithObserver(NewSlogObserver(logger))
+}
+
+func (so *SlogObserver) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	so.Logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// OnStart logs a "start" record
+func (so *SlogObserver) OnStart(s *Start) {
+	so.log(so.Level, "start", slog.String("method", s.Method), slog.Any("x0", s.X0))
+}
+
+// OnIteration logs an "iteration" record
+func (so *SlogObserver) OnIteration(it *Iteration) {
+	if !so.Logger.Enabled(context.Background(), so.Level) {
+		return
+	}
+	attrs := []slog.Attr{slog.Int("iter", it.Iter), slog.Int("nfev", it.NFev), slog.Float64("f", it.F), slog.Any("x", it.X)}
+	keys := make([]string, 0, len(it.Diagnostics))
+	for k := range it.Diagnostics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, slog.Float64(k, it.Diagnostics[k]))
+	}
+	so.log(so.Level, "iteration", attrs...)
+}
+
+// OnEvaluation logs an "evaluation" record at level Debug if Evaluations is true
+func (so *SlogObserver) OnEvaluation(e *Evaluation) {
+	if so.Evaluations {
+		so.log(slog.LevelDebug, "evaluation", slog.Int("n", e.N), slog.Float64("f", e.F), slog.Any("x", e.X))
+	}
+}
+
+// OnDone logs a "done" record
+func (so *SlogObserver) OnDone(res *Result) {
+	so.log(so.Level, "done", slog.String("status", res.Status.String()), slog.Float64("f", res.F), slog.Any("x", res.X),
+		slog.Int("niter", res.NIter), slog.Int("nfev", res.NFev), slog.Duration("elapsed", res.Elapsed))
+}
+
+// slogBracket returns a trace of brent and bissection logging "iteration"
+// records with iter, a, fa, b, fb and step=|b-a|
+func slogBracket(logger *slog.Logger) func(it int, a, fa, b, fb float64) {
+	if logger == nil {
+		return nil
+	}
+	return func(it int, a, fa, b, fb float64) {
+		logger.LogAttrs(context.Background(), slog.LevelInfo, "iteration", slog.Int("iter", it),
+			slog.Float64("a", a), slog.Float64("fa", fa), slog.Float64("b", b), slog.Float64("fb", fb), slog.Float64("step", math.Abs(b-a)))
+	}
+}
+
+// BrentSlog is Brent logging structured records to logger, which may be nil
+func BrentSlog(a, b, tol float64, f func(float64) float64, logger *slog.Logger) (float64, error) {
+	return brent(a, b, tol, f, slogBracket(logger))
+}
+
+// BissectionSlog is Bissection logging structured records to logger, which may be nil
+func BissectionSlog(a, b, tol float64, f func(float64) float64, logger *slog.Logger) (float64, error) {
+	res, err := bissection(a, b, f, &BissectionOptions{XTol: tol}, slogBracket(logger))
+	return res.X, err
+}
+
+// GssSlog is Gss logging "iteration" records with iter, a, b and step=b-a
+// to logger, which may be nil
+func GssSlog(f func(float64) float64, a, b, tol float64, logger *slog.Logger) (float64, float64) {
+	var trace func(it int, a, b float64)
+	if logger != nil {
+		trace = func(it int, a, b float64) {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "iteration", slog.Int("iter", it),
+				slog.Float64("a", a), slog.Float64("b", b), slog.Float64("step", b-a))
+		}
+	}
+	return gss(f, a, b, tol, nan, nan, nan, nan, nan, trace)
+}
@@ -0,0 +1,113 @@
+//go:build go1.21
+// +build go1.21
+
+package optimize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleBrentSlog() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	x, _ := BrentSlog(0, 2, 1e-2, func(x float64) float64 { return x*x - 2 }, logger)
+	fmt.Printf("%.2f\n", x)
+	// Output:
+	// level=INFO msg=iteration iter=0 a=0 fa=-2 b=2 fb=2 step=2
+	// level=INFO msg=iteration iter=1 a=2 fa=2 b=1 fb=-1 step=1
+	// level=INFO msg=iteration iter=2 a=2 fa=2 b=1.3333333333333333 fb=-0.22222222222222232 step=0.6666666666666667
+	// level=INFO msg=iteration iter=3 a=1.3333333333333333 fa=-0.22222222222222232 b=1.419047619047619 fb=0.013696145124716175 step=0.08571428571428563
+	// level=INFO msg=iteration iter=4 a=1.419047619047619 fa=0.013696145124716175 b=1.414071510957324 fb=-0.00040176189887053404 step=0.004976108090294806
+	// 1.41
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var recs []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		rec := map[string]interface{}{}
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestSlogObserver(t *testing.T) {
+	for _, c := range []struct {
+		method string
+		key    string
+	}{
+		{"powell", "step"},
+		{"cmaes", "sigma"},
+		{"spsa", "ck"},
+	} {
+		buf := new(bytes.Buffer)
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		o, err := NewOptions(WithMethod(c.method), WithSlog(logger), WithMaxIter(50))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Observer.(*SlogObserver).Evaluations = true
+		res, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs := decodeRecords(t, buf)
+		count := map[string]int{}
+		for _, rec := range recs {
+			msg := rec["msg"].(string)
+			count[msg]++
+			switch msg {
+			case "iteration":
+				for _, k := range []string{"iter", "nfev", "f", c.key} {
+					if _, ok := rec[k]; !ok {
+						t.Errorf("%s: no %s in %v", c.method, k, rec)
+					}
+				}
+			case "evaluation":
+				if rec["level"] != "DEBUG" {
+					t.Errorf("%s: evaluation at level %v", c.method, rec["level"])
+				}
+			case "done":
+				if rec["status"] != res.Status.String() || rec["nfev"].(float64) != float64(res.NFev) || math.Abs(rec["f"].(float64)-res.F) > 1e-12 {
+					t.Errorf("%s: done %v for %+v", c.method, rec, res)
+				}
+			}
+		}
+		if count["start"] != 1 || count["done"] != 1 || count["iteration"] == 0 || count["evaluation"] != res.NFev {
+			t.Errorf("%s: %v records for %d evaluations", c.method, count, res.NFev)
+		}
+	}
+}
+
+func TestGssSlog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	a, b := GssSlog(func(x float64) float64 { return (x - 2) * (x - 2) }, 1, 5, 1e-5, slog.New(slog.NewJSONHandler(buf, nil)))
+	if math.Abs((a+b)/2-2) > 1e-5 {
+		t.Errorf("got [%g, %g]", a, b)
+	}
+	recs := decodeRecords(t, buf)
+	last := recs[len(recs)-1]
+	if len(recs) < 2 || last["step"].(float64) >= 1e-5 {
+		t.Errorf("%d records, last %v", len(recs), last)
+	}
+	if x, err := BissectionSlog(0, 2, 1e-6, func(x float64) float64 { return x*x - 2 }, nil); err != nil || math.Abs(x-math.Sqrt2) > 1e-6 {
+		t.Errorf("bissection: %g %v", x, err)
+	}
+}
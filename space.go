@@ -0,0 +1,195 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+)
+
+// ParamKind is the distribution of a search space parameter
+type ParamKind int
+
+const (
+	// ParamUniform is a real parameter in [Low, High]
+	ParamUniform ParamKind = iota
+	// ParamLogUniform is a positive real parameter in [Low, High] searched on a log scale
+	ParamLogUniform
+	// ParamInt is an integer parameter in Low..High
+	ParamInt
+	// ParamCategorical is one of Choices
+	ParamCategorical
+)
+
+// Param is a parameter of a Space
+type Param struct {
+	Name      string
+	Kind      ParamKind
+	Low, High float64
+	Choices   []string
+}
+
+// UniformParam returns a real parameter in [low, high]
+func UniformParam(name string, low, high float64) Param {
+	return Param{Name: name, Kind: ParamUniform, Low: low, High: high}
+}
+
+// LogUniformParam returns a real parameter in [low, high], 0 < low < high, searched on a log scale
+func LogUniformParam(name string, low, high float64) Param {
+	return Param{Name: name, Kind: ParamLogUniform, Low: low, High: high}
+}
+
+// IntParam returns an integer parameter in low..high
+func IntParam(name string, low, high int) Param {
+	return Param{Name: name, Kind: ParamInt, Low: float64(low), High: float64(high)}
+}
+
+// CategoricalParam returns a parameter taking one of choices
+func CategoricalParam(name string, choices ...string) Param {
+	return Param{Name: name, Kind: ParamCategorical, Choices: choices}
+}
+
+// Values are the parameter values of a point of a Space, by name.
+// values are float64 for real parameters, int for integer ones and string
+// for categorical ones.
+type Values map[string]interface{}
+
+// Float returns the value of a real parameter
+func (v Values) Float(name string) float64 { return v[name].(float64) }
+
+// Int returns the value of an integer parameter
+func (v Values) Int(name string) int { return v[name].(int) }
+
+// String returns the value of a categorical parameter
+func (v Values) String(name string) string { return v[name].(string) }
+
+// Space is a search space of named parameters. Optimizers work in the unit
+// cube [0,1]^Dim: each parameter is mapped to one coordinate, linearly or on a
+// log scale, and integer and categorical parameters to equal-width cells.
+type Space struct {
+	Params []Param
+}
+
+// NewSpace returns a Space of params
+func NewSpace(params ...Param) *Space {
+	return &Space{Params: params}
+}
+
+// Dim returns the dimension of the optimizer space
+func (s *Space) Dim() int { return len(s.Params) }
+
+// Validate returns an error for a parameter of an empty or invalid range, a
+// log-uniform one of a nonpositive Low, or a categorical one without Choices
+func (s *Space) Validate() error {
+	for _, p := range s.Params {
+		switch {
+		case p.Kind == ParamCategorical && len(p.Choices) == 0:
+			return fmt.Errorf("optimize: no choices for %s", p.Name)
+		case p.Kind == ParamCategorical:
+		case !(p.Low <= p.High) || math.IsInf(p.Low, 0) || math.IsInf(p.High, 0):
+			return fmt.Errorf("optimize: bad range [%g, %g] for %s", p.Low, p.High, p.Name)
+		case p.Kind == ParamLogUniform && !(p.Low > 0):
+			return fmt.Errorf("optimize: nonpositive Low %g for %s", p.Low, p.Name)
+		}
+	}
+	return nil
+}
+
+// Bounds returns the bounds of the optimizer space, eg for CmaEsCholB.Xmin, Xmax
+func (s *Space) Bounds() (xmin, xmax []float64) {
+	xmin, xmax = make([]float64, s.Dim()), make([]float64, s.Dim())
+	for i := range xmax {
+		xmax[i] = 1
+	}
+	return
+}
+
+// levels returns the number of cells of a discrete parameter
+func (p Param) levels() int {
+	if p.Kind == ParamInt {
+		return int(p.High-p.Low) + 1
+	}
+	return len(p.Choices)
+}
+
+// Decode returns the parameter values at x. x is clipped to the unit cube,
+// a NaN coordinate being 0. The Space must be valid, see Validate.
+func (s *Space) Decode(x []float64) Values {
+	v := make(Values, len(s.Params))
+	for i, p := range s.Params {
+		u := x[i]
+		if !(u > 0) {
+			u = 0
+		} else if u > 1 {
+			u = 1
+		}
+		switch {
+		case p.Kind != ParamInt && p.Kind != ParamCategorical && p.Low == p.High:
+			v[p.Name] = p.Low
+		case p.Kind == ParamUniform:
+			v[p.Name] = p.Low + u*(p.High-p.Low)
+		case p.Kind == ParamLogUniform:
+			v[p.Name] = math.Exp(math.Log(p.Low) + u*(math.Log(p.High)-math.Log(p.Low)))
+		default:
+			k := int(u * float64(p.levels()))
+			if k >= p.levels() {
+				k = p.levels() - 1
+			}
+			if p.Kind == ParamInt {
+				v[p.Name] = int(p.Low) + k
+			} else {
+				v[p.Name] = p.Choices[k]
+			}
+		}
+	}
+	return v
+}
+
+// Encode returns the point of the optimizer space of values v. Discrete
+// values are mapped to the center of their cell. It returns an error for a
+// missing, out of range or unknown value.
+func (s *Space) Encode(v Values) ([]float64, error) {
+	x := make([]float64, s.Dim())
+	for i, p := range s.Params {
+		val, ok := v[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("optimize: missing value for %s", p.Name)
+		}
+		switch p.Kind {
+		case ParamUniform, ParamLogUniform:
+			f, ok := val.(float64)
+			if !ok || f < p.Low || f > p.High {
+				return nil, fmt.Errorf("optimize: bad value %v for %s", val, p.Name)
+			}
+			switch {
+			case p.Low == p.High:
+				x[i] = 0
+			case p.Kind == ParamUniform:
+				x[i] = (f - p.Low) / (p.High - p.Low)
+			default:
+				x[i] = (math.Log(f) - math.Log(p.Low)) / (math.Log(p.High) - math.Log(p.Low))
+			}
+		case ParamInt:
+			n, ok := val.(int)
+			if !ok || float64(n) < p.Low || float64(n) > p.High {
+				return nil, fmt.Errorf("optimize: bad value %v for %s", val, p.Name)
+			}
+			x[i] = (float64(n) - p.Low + .5) / float64(p.levels())
+		case ParamCategorical:
+			k := -1
+			for j, c := range p.Choices {
+				if c == val {
+					k = j
+				}
+			}
+			if k < 0 {
+				return nil, fmt.Errorf("optimize: bad value %v for %s", val, p.Name)
+			}
+			x[i] = (float64(k) + .5) / float64(p.levels())
+		}
+	}
+	return x, nil
+}
+
+// Func returns the objective of the optimizer space evaluating f at the decoded values
+func (s *Space) Func(f func(Values) float64) func([]float64) float64 {
+	return func(x []float64) float64 { return f(s.Decode(x)) }
+}
@@ -0,0 +1,88 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleSpace() {
+	space := NewSpace(
+		LogUniformParam("learning_rate", 1e-4, 1),
+		IntParam("layers", 1, 8),
+		CategoricalParam("activation", "relu", "tanh", "sigmoid"),
+	)
+	loss := func(v Values) float64 {
+		l := math.Pow(math.Log10(v.Float("learning_rate"))+2, 2) + math.Abs(float64(v.Int("layers")-3))
+		if v.String("activation") != "tanh" {
+			l++
+		}
+		return l
+	}
+	xmin, xmax := space.Bounds()
+	// the unit cube calls for an initial deviation smaller than 1
+	var chol mat.Cholesky
+	chol.Factorize(mat.NewDiagDense(space.Dim(), []float64{.04, .04, .04}))
+	method := &CmaEsCholB{Xmin: xmin, Xmax: xmax, InitCholesky: &chol, Src: rand.NewSource(1)}
+	x0, _ := space.Encode(Values{"learning_rate": .1, "layers": 1, "activation": "relu"})
+	res, err := optimize.Minimize(optimize.Problem{Func: space.Func(loss)}, x0, &optimize.Settings{FuncEvaluations: 1000}, method)
+	if err != nil {
+		panic(err)
+	}
+	best := space.Decode(res.X)
+	fmt.Printf("%.2g %d %s\n", best.Float("learning_rate"), best.Int("layers"), best.String("activation"))
+	// Output:
+	// 0.01 3 tanh
+}
+
+func TestSpace(t *testing.T) {
+	space := NewSpace(UniformParam("a", -1, 1), LogUniformParam("b", 1, 100), IntParam("c", 0, 4), CategoricalParam("d", "x", "y"))
+	v := Values{"a": .5, "b": 10., "c": 4, "d": "y"}
+	x, err := space.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(x[0]-.75) > 1e-15 || math.Abs(x[1]-.5) > 1e-15 || x[2] != .9 || x[3] != .75 {
+		t.Errorf("bad encoding %v", x)
+	}
+	w := space.Decode(x)
+	if math.Abs(w.Float("a")-.5) > 1e-12 || math.Abs(w.Float("b")-10) > 1e-12 || w.Int("c") != 4 || w.String("d") != "y" {
+		t.Errorf("bad round trip %v", w)
+	}
+	if w := space.Decode([]float64{2, -1, 1, 1}); w.Float("a") != 1 || w.Float("b") != 1 || w.Int("c") != 4 || w.String("d") != "y" {
+		t.Errorf("decode should clip, got %v", w)
+	}
+	if w := space.Decode([]float64{math.NaN(), math.NaN(), math.NaN(), math.Inf(1)}); w.Float("a") != -1 || w.Float("b") != 1 || w.Int("c") != 0 || w.String("d") != "y" {
+		t.Errorf("NaN should decode as 0, got %v", w)
+	}
+	// zero-width ranges
+	fixed := NewSpace(UniformParam("a", 2, 2), LogUniformParam("b", 3, 3))
+	if x, err := fixed.Encode(Values{"a": 2., "b": 3.}); err != nil || x[0] != 0 || x[1] != 0 {
+		t.Errorf("fixed parameters encoded as %v, %v", x, err)
+	}
+	if w := fixed.Decode([]float64{.5, 1}); w.Float("a") != 2 || w.Float("b") != 3 {
+		t.Errorf("fixed parameters decoded as %v", w)
+	}
+	if err := space.Validate(); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []*Space{
+		NewSpace(CategoricalParam("d")),
+		NewSpace(IntParam("c", 4, 0)),
+		NewSpace(UniformParam("a", math.NaN(), 1)),
+		NewSpace(LogUniformParam("b", 0, 1)),
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", bad.Params)
+		}
+	}
+	for _, bad := range []Values{{"a": .5, "b": 10., "c": 4}, {"a": .5, "b": 1000., "c": 4, "d": "y"}, {"a": .5, "b": 10., "c": 4, "d": "z"}} {
+		if _, err := space.Encode(bad); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+}
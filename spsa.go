@@ -0,0 +1,336 @@
+package optimize
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// SPSA minimizes a noisy or expensive function using Simultaneous Perturbation
+// Stochastic Approximation. Each gradient estimate costs two evaluations
+// regardless of the dimension.
+// see J.C. Spall, Implementation of the Simultaneous Perturbation Algorithm for Stochastic Optimization, 1998
+type SPSA struct {
+	// gain sequences are a_k = A/(k+1+Stability)^Alpha and c_k = C/(k+1)^Gamma.
+	// if A is 0, it is calibrated so that the first steps move x by about InitStep.
+	A, C, Alpha, Gamma, Stability float64
+	// InitStep is the typical magnitude of the first steps, used when A is 0
+	InitStep float64
+	// GradAvg is the number of gradient estimates averaged at each iteration. defaults to 1
+	GradAvg int
+	// Xmin, Xmax are optional bounds. iterates and perturbed points are projected on them.
+	Xmin, Xmax []float64
+	// MaxFev, if positive, includes the final evaluations of the solution
+	// and of the averaged iterate
+	MaxIter, MaxFev int
+	// Src allows a random number generator to be supplied. If Src is nil
+	// a new source seeded from golang.org/x/exp/rand is used.
+	Src      rand.Source
+	Callback func([]float64)
+	Logger   *log.Logger
+	// Averaging, if not nil, averages iterates. The averaged solution is
+	// the *Averaged Extra of the Result.
+	Averaging *Averaging
+	// CRN, if not nil, gets a new seed before each gradient estimate so that
+	// both perturbed points use common random numbers. f should then be CRN.Eval.
+	CRN *CommonRandomNumbers
+	// Repair, if not nil, is applied to points before evaluation and to the
+	// returned solutions. Iterates themselves are not repaired.
+	Repair Repair
+	// TargetF, if not nil, stops the minimization with Status
+	// FunctionThreshold at the first evaluated point where f <= *TargetF.
+	// With a noisy f, it is a single noisy evaluation.
+	TargetF *float64
+	// StallIterations, if positive, stops the minimization with Status
+	// FunctionConvergence when the best evaluated value has not decreased by
+	// more than StallTolerance over the last StallIterations iterations.
+	// Evaluations being noisy, StallIterations should be large.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of
+	// each iteration. Iteration.F is the best noisy evaluation.
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run
+	Observer Observer
+	// Evaluator, if not nil, evaluates the perturbed points of an iteration
+	// in parallel, or in one call of its Batch. An evaluation error stops the
+	// minimization with Status Failure.
+	Evaluator *Evaluator
+	run       *spsaRun
+	restored  *spsaState
+	warm      *WarmStart
+}
+
+// spsaRun references the state of a run
+type spsaRun struct {
+	x   []float64
+	res *Result
+	a   float64
+	k0  int
+	src rand.Source
+}
+
+// spsaState is the state of SPSA at the end of an iteration
+type spsaState struct {
+	X, AvgMean        []float64
+	NIter, NFev, AvgN int
+	// K0 is the first index of the gain sequences of a warm start
+	K0  int
+	A   float64
+	Src []byte
+}
+
+// Snapshot returns the state of the current or last run
+func (sp *SPSA) Snapshot() ([]byte, error) {
+	if sp.run == nil || sp.run.res.NIter == 0 {
+		return nil, errNoState
+	}
+	src, err := marshalSource(sp.run.src)
+	if err != nil {
+		return nil, err
+	}
+	state := &spsaState{X: sp.run.x, NIter: sp.run.res.NIter, NFev: sp.run.res.NFev, A: sp.run.a, K0: sp.run.k0, Src: src}
+	if sp.Averaging != nil {
+		state.AvgMean, state.AvgN = sp.Averaging.mean, sp.Averaging.n
+	}
+	return encodeState("spsa", state)
+}
+
+// Restore sets the state from which the next call to Minimize resumes,
+// ignoring its x0. MaxIter and MaxFev include the iterations and
+// evaluations of the restored run.
+func (sp *SPSA) Restore(data []byte) error {
+	state := &spsaState{}
+	if err := decodeState("spsa", data, state); err != nil {
+		return err
+	}
+	sp.restored = state
+	return nil
+}
+
+// WarmStart primes the next run with the point and gain A of prev, the
+// gain sequences continuing from its iterations. Averaging is not primed.
+func (sp *SPSA) WarmStart(prev *Result) error {
+	warm, err := warmState("spsa", prev)
+	if err != nil {
+		return err
+	}
+	sp.warm = warm
+	return nil
+}
+
+// NewSPSA returns a *SPSA with the standard gain exponents of Spall
+func NewSPSA() *SPSA {
+	return &SPSA{C: .1, Alpha: .602, Gamma: .101, InitStep: .1, GradAvg: 1, MaxIter: 1000}
+}
+
+func newRand(src rand.Source) *rand.Rand {
+	if src == nil {
+		src = rand.NewSource(rand.Uint64())
+	}
+	return rand.New(src)
+}
+
+// Minimize minimizes f starting at x0. It returns the final iterate and f at it.
+// If Averaging is set, Extra is the *Averaged solution. An x0 outside the
+// bounds is projected on them with a note in Warnings, invalid bounds or a
+// non-finite x0 are the Message of a Result with Status Failure.
+func (sp *SPSA) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{}
+	restored, warm := sp.restored, sp.warm
+	sp.restored, sp.warm = nil, nil
+	if restored != nil {
+		x0, warm = restored.X, nil
+	} else if warm != nil && len(warm.X) == len(x0) {
+		x0 = warm.X
+	} else {
+		warm = nil
+	}
+	x0, note, err := feasibleStart(x0, sp.Xmin, sp.Xmax, sp.Logger)
+	if err != nil {
+		res.X, res.F, res.Status, res.Message = x0, math.NaN(), optimize.Failure, err.Error()
+		return res.done(start)
+	}
+	if note != "" {
+		res.Warnings = []string{note}
+	}
+	n := len(x0)
+	src := sp.Src
+	if src == nil {
+		src = rand.NewSource(rand.Uint64())
+	}
+	if restored != nil {
+		if err := unmarshalSource(src, restored.Src); err != nil {
+			res.X, res.F, res.Status, res.Message = x0, math.NaN(), optimize.Failure, err.Error()
+			return res.done(start)
+		}
+	}
+	rnd := rand.New(src)
+	box := Box{Xmin: sp.Xmin, Xmax: sp.Xmax}
+	maxIter := sp.MaxIter
+	if maxIter <= 0 {
+		maxIter = 1000
+	}
+	gradAvg := sp.GradAvg
+	if gradAvg <= 0 {
+		gradAvg = 1
+	}
+	stability := sp.Stability
+	if stability == 0 {
+		stability = .1 * float64(maxIter)
+	}
+	tw := &targetWatch{target: sp.TargetF}
+	sw := newStallWatch(sp.StallIterations, sp.StallTolerance)
+	st := newMonitor(sp.Stop, sp.Observer, "spsa", x0)
+	rp := newReplay(sp.Evaluator, RepairedFunc(sp.Repair, f), sp.Repair)
+	repaired := st.wrap(sw.wrap(tw.wrap(rp.fn)))
+	fun := func(x []float64) float64 {
+		res.NFev++
+		return repaired(x)
+	}
+	x := make([]float64, n)
+	copy(x, x0)
+	box.Project(x)
+	grad := make([]float64, n)
+	const nCalib = 4
+	points := make([][]float64, 2*max(gradAvg, nCalib))
+	for i := range points {
+		points[i] = make([]float64, n)
+	}
+	ys := make([]float64, len(points))
+
+	// estimate adds m gradient estimates at x with perturbation size ck to grad
+	estimate := func(ck float64, m int) {
+		for j := 0; j < m; j++ {
+			xp, xm := points[2*j], points[2*j+1]
+			for i := range x {
+				d := float64(2*rnd.Intn(2) - 1)
+				xp[i] = x[i] + ck*d
+				xm[i] = x[i] - ck*d
+			}
+			box.Project(xp)
+			box.Project(xm)
+		}
+		if sp.CRN != nil {
+			// the perturbed points of an estimate use the same seed
+			for j := 0; j < m; j++ {
+				sp.CRN.NextSeed()
+				rp.eval(fun, points[2*j:2*j+2], ys[2*j:2*j+2])
+			}
+		} else {
+			rp.eval(fun, points[:2*m], ys[:2*m])
+		}
+		for j := 0; j < m; j++ {
+			xp, xm, yp, ym := points[2*j], points[2*j+1], ys[2*j], ys[2*j+1]
+			for i := range grad {
+				if d := xp[i] - xm[i]; d != 0 {
+					grad[i] += (yp - ym) / d
+				}
+			}
+		}
+	}
+	a := sp.A
+	// k0 is the index of the first iteration in the gain sequences
+	k0 := 0
+	if restored != nil {
+		a, k0 = restored.A, restored.K0
+	} else if warm != nil {
+		a, k0 = warm.StepSize, warm.Iter
+	} else if a == 0 {
+		// calibrate a from the magnitude of a few gradient estimates at x0
+		for i := range grad {
+			grad[i] = 0
+		}
+		estimate(sp.C, nCalib)
+		gMean := 0.
+		for _, g := range grad {
+			gMean += math.Abs(g) / nCalib
+		}
+		gMean /= float64(n)
+		initStep := sp.InitStep
+		if initStep <= 0 {
+			initStep = .1
+		}
+		a = 1
+		if gMean > 0 {
+			a = initStep * math.Pow(stability+1, sp.Alpha) / gMean
+		}
+	}
+	if sp.Averaging != nil {
+		sp.Averaging.init(n)
+		if restored != nil && len(restored.AvgMean) == n {
+			copy(sp.Averaging.mean, restored.AvgMean)
+			sp.Averaging.n = restored.AvgN
+		}
+	}
+	if restored != nil {
+		res.NIter, res.NFev = restored.NIter, restored.NFev
+	}
+	sp.run = &spsaRun{x: x, res: res, a: a, k0: k0, src: src}
+	// the evaluations of the solution and of the averaged one are reserved
+	final := 1
+	if sp.Averaging != nil {
+		final = 2
+	}
+	for res.NIter < maxIter && (sp.MaxFev <= 0 || res.NFev+2*gradAvg+final <= sp.MaxFev) && !tw.reached && !sw.stalled(res.NIter) {
+		k := float64(k0 + res.NIter)
+		ak := a / math.Pow(k+1+stability, sp.Alpha)
+		ck := sp.C / math.Pow(k+1, sp.Gamma)
+		for i := range grad {
+			grad[i] = 0
+		}
+		estimate(ck, gradAvg)
+		if rp.err != nil {
+			break
+		}
+		for i := range x {
+			x[i] -= ak * grad[i] / float64(gradAvg)
+		}
+		box.Project(x)
+		res.NIter++
+		if sp.Averaging != nil {
+			sp.Averaging.add(res.NIter, x)
+		}
+		if sp.Callback != nil {
+			sp.Callback(x)
+		}
+		if sp.Logger != nil {
+			sp.Logger.Printf("%d\tak=%.4g\tck=%.4g\tx=%.6g\n", res.NIter, ak, ck, x)
+		}
+		if st.iterate(res.NIter, res.NFev, x, func() map[string]float64 { return map[string]float64{"ak": ak, "ck": ck} }) {
+			break
+		}
+	}
+	switch {
+	case rp.err != nil:
+		res.Status, res.Message = optimize.Failure, rp.err.Error()
+	case res.NIter >= maxIter:
+		res.Status = optimize.IterationLimit
+	case sw.stalled(res.NIter):
+		res.Status, res.Reason = optimize.FunctionConvergence, ReasonStagnation
+	default:
+		res.Status = optimize.FunctionEvaluationLimit
+	}
+	st.apply(res)
+	res.Warm = &WarmStart{Method: "spsa", X: append([]float64(nil), x...), StepSize: a, Iter: k0 + res.NIter}
+	res.X, res.F = x, fun(x)
+	tw.apply(res)
+	if sp.Repair != nil {
+		sp.Repair.Repair(res.X)
+	}
+	if sp.Averaging != nil {
+		if xAvg := sp.Averaging.X(); xAvg != nil {
+			avg := &Averaged{X: xAvg, F: fun(xAvg)}
+			if sp.Repair != nil {
+				sp.Repair.Repair(avg.X)
+			}
+			res.Extra = avg
+		}
+	}
+	rp.stats(&res.Evals)
+	return st.done(res.done(start))
+}
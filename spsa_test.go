@@ -0,0 +1,47 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleSPSA() {
+	noise := rand.New(rand.NewSource(2))
+	f := func(x []float64) float64 {
+		s := noise.NormFloat64() * 1e-3
+		for i, xi := range x {
+			d := xi - float64(i+1)
+			s += d * d
+		}
+		return s
+	}
+	sp := NewSPSA()
+	sp.Src = rand.NewSource(1)
+	sp.Xmax = []float64{math.Inf(1), math.Inf(1), 2.5, math.Inf(1)}
+	res := sp.Minimize(f, []float64{0, 0, 0, 0})
+	fmt.Printf("x:%.1f iter:%d funcalls:%d\n", res.X, res.NIter, res.NFev)
+	// Output:
+	// x:[1.0 2.0 2.5 4.0] iter:1000 funcalls:2009
+}
+
+func TestSPSAMaxFev(t *testing.T) {
+	for _, avg := range []*Averaging{nil, {}} {
+		for _, maxFev := range []int{51, 52, 53} {
+			calls := 0
+			f := func(x []float64) float64 {
+				calls++
+				return x[0]*x[0] + x[1]*x[1]
+			}
+			sp := NewSPSA()
+			sp.MaxFev, sp.Averaging, sp.Src = maxFev, avg, rand.NewSource(1)
+			res := sp.Minimize(f, []float64{1, 1})
+			if calls > maxFev || res.NFev != calls || res.Status != optimize.FunctionEvaluationLimit {
+				t.Errorf("averaging %v MaxFev %d: %d evaluations, NFev %d, %v", avg != nil, maxFev, calls, res.NFev, res.Status)
+			}
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// SQP minimizes a smooth function under smooth constraints c(x) <= 0,
+// ceq(x) = 0 and bounds by sequential quadratic programming. Each iteration
+// minimizes a quadratic model of the Lagrangian, of Hessian approximated
+// by damped BFGS updates, under the linearized constraints, and a
+// backtracking line search on the l1 merit function f + nu Violation(c, ceq),
+// or on a Filter, accepts the step. An infeasible linearization is relaxed by an l1
+// penalty on its violation, the elastic mode of SNOPT, instead of the least
+// squares subproblem of SLSQP. The gradient of f, if Grad is nil, and the
+// Jacobians of the constraints are estimated by finite differences.
+type SQP struct {
+	// Ineq and Eq, if not nil, return the values of the constraints
+	// c(x) <= 0 and ceq(x) = 0
+	Ineq, Eq func(x []float64) []float64
+	// Grad, if not nil, computes the gradient of f
+	Grad func(grad, x []float64)
+	// Xmin, Xmax are optional bounds, checked by ValidateStart. The iterates
+	// stay within them.
+	Xmin, Xmax []float64
+	// Tol is the tolerance on the steps, relative to 1+|x_i|, and on the
+	// violation of the constraints at the solution. The default is 1e-8.
+	Tol float64
+	// MaxIter and MaxFev default to 100 and 1000*dim
+	MaxIter, MaxFev int
+	Callback        func([]float64)
+	Logger          *log.Logger
+	// TargetF, if not nil, stops the minimization with Status
+	// FunctionThreshold at the first iterate where the constraints hold
+	// within Tol and f <= *TargetF
+	TargetF *float64
+	// StallIterations, if positive, stops the minimization with Status
+	// FunctionConvergence when the best value of f at iterates where the
+	// constraints hold has not decreased by more than StallTolerance over the
+	// last StallIterations iterations.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of each iteration
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run
+	Observer Observer
+	// Filter, if not nil, replaces the merit function of the line search by
+	// the filter of Fletcher and Leyffer, reset at the start of Minimize: a
+	// step is accepted if it is acceptable to the filter and to the current
+	// point, and with a sufficient decrease of f if the current point is
+	// feasible and d a descent direction. Otherwise, the current point is
+	// added to the filter.
+	Filter *Filter
+}
+
+// Multipliers are the Lagrange multipliers of the constraints at the
+// solution of SQP, the Extra of its Result
+type Multipliers struct {
+	Ineq, Eq []float64
+}
+
+// NewSQP returns a SQP with default limits
+func NewSQP() *SQP {
+	return &SQP{Tol: 1e-8, MaxIter: 100}
+}
+
+// sqpPoint is an iterate of SQP and its derivatives
+type sqpPoint struct {
+	x, g    []float64
+	f, h    float64
+	c, ceq  []float64
+	jc, jeq *mat.Dense
+}
+
+// Minimize minimizes f from x0 and returns the solution, of Extra its
+// *Multipliers, with Status MethodConverge when the steps fall below Tol,
+// a note in Warnings if its constraints are still violated. An x0 outside
+// the bounds is projected on them, invalid bounds or a non-finite x0 are
+// the Message of a Result with Status Failure.
+func (s *SQP) Minimize(f func([]float64) float64, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{}
+	x0, note, err := feasibleStart(x0, s.Xmin, s.Xmax, s.Logger)
+	if err != nil {
+		res.X, res.F, res.Status, res.Message = append([]float64(nil), x0...), math.NaN(), optimize.Failure, err.Error()
+		return res.done(start)
+	}
+	if note != "" {
+		res.Warnings = []string{note}
+	}
+	n := len(x0)
+	tol, maxIter, maxFev := s.Tol, s.MaxIter, s.MaxFev
+	if tol <= 0 {
+		tol = 1e-8
+	}
+	if maxIter <= 0 {
+		maxIter = 100
+	}
+	if maxFev <= 0 {
+		maxFev = 1000 * n
+	}
+	st := newMonitor(s.Stop, s.Observer, "sqp", x0)
+	sw := newStallWatch(s.StallIterations, s.StallTolerance)
+	fun := st.wrap(func(x []float64) float64 {
+		res.NFev++
+		return f(x)
+	})
+	box := Box{Xmin: s.Xmin, Xmax: s.Xmax}
+	gopts := &GradientOptions{Xmin: s.Xmin, Xmax: s.Xmax}
+	constraints := func(x []float64) (c, ceq []float64) {
+		if s.Ineq != nil {
+			c = append([]float64(nil), s.Ineq(x)...)
+		}
+		if s.Eq != nil {
+			ceq = append([]float64(nil), s.Eq(x)...)
+		}
+		return c, ceq
+	}
+	// point returns x and its values, evaluating f if fx is NaN
+	point := func(x []float64, fx float64) *sqpPoint {
+		p := &sqpPoint{x: x, f: fx}
+		if math.IsNaN(p.f) {
+			p.f = fun(x)
+		}
+		p.c, p.ceq = constraints(x)
+		p.h = Violation(p.c, p.ceq)
+		return p
+	}
+	// derive sets the gradient of f and the Jacobians of the constraints at p
+	derive := func(p *sqpPoint) {
+		if s.Grad != nil {
+			p.g = make([]float64, n)
+			s.Grad(p.g, p.x)
+			res.NGrad++
+		} else {
+			p.g = Gradient(fun, p.x, gopts)
+		}
+		p.jc, p.jeq = jacobian(s.Ineq, p.x), jacobian(s.Eq, p.x)
+	}
+
+	p := point(append([]float64(nil), x0...), math.NaN())
+	derive(p)
+	me, mi := len(p.ceq), len(p.c)
+	B := identity(n)
+	updated := false
+	var lambda []float64
+	mu, nu := 1e2, 0.
+	alpha := 0.
+	if s.Filter != nil {
+		s.Filter.Reset()
+	}
+	for res.Status == optimize.NotTerminated {
+		if res.NIter >= maxIter {
+			res.Status = optimize.IterationLimit
+			break
+		}
+		if res.NFev >= maxFev {
+			res.Status = optimize.FunctionEvaluationLimit
+			break
+		}
+		A, b := sqpConstraints(p, box)
+		var d []float64
+		for {
+			if d, lambda, err = qpSubproblem(B, p.g, A, b, me, mu, lambda); err != nil {
+				break
+			}
+			// multipliers at the penalty mu and a violated linearization:
+			// increase the penalty of the violation
+			if lmax := floats.Norm(lambda[:me+mi], math.Inf(1)); lmax < mu*(1-1e-6) || mu >= 1e12 || linearViolation(A, b, d, me, me+mi) <= tol {
+				break
+			}
+			mu *= 10
+		}
+		if err != nil {
+			res.Status, res.Message = optimize.Failure, err.Error()
+			break
+		}
+		if p.h <= tol && smallStep(p.x, d, tol) {
+			res.Status, res.Reason = optimize.MethodConverge, ReasonTolX
+			break
+		}
+
+		// line search on the merit function f + nu h
+		for _, l := range lambda[:me+mi] {
+			// nu above the multipliers makes the solution of the problem a
+			// minimum of the merit function
+			nu = math.Max(nu, 1.5*math.Abs(l))
+		}
+		slope := floats.Dot(p.g, d) - nu*(p.h-linearViolation(A, b, d, me, me+mi))
+		merit := p.f + nu*p.h
+		// switching condition of the filter: steps of a feasible point along
+		// a descent direction must decrease f
+		slopeF := floats.Dot(p.g, d)
+		fType := p.h <= tol && slopeF < 0
+		accept := func(t *sqpPoint) bool {
+			if s.Filter == nil {
+				return t.f+nu*t.h <= merit+1e-4*alpha*math.Min(slope, 0)
+			}
+			beta, gamma := s.Filter.factors()
+			if !s.Filter.Acceptable(t.f, t.h) || !(t.h <= beta*p.h || t.f <= p.f-gamma*t.h) {
+				return false
+			}
+			return !fType || t.f <= p.f+1e-4*alpha*slopeF
+		}
+		var next *sqpPoint
+		alpha = 1
+		for k := 0; k < 40 && res.NFev < maxFev; k++ {
+			xt := make([]float64, n)
+			floats.AddScaledTo(xt, p.x, alpha, d)
+			box.Project(xt)
+			if t := point(xt, math.NaN()); accept(t) {
+				next = t
+				break
+			}
+			alpha /= 2
+		}
+		if next != nil && s.Filter != nil && !fType {
+			s.Filter.Add(p.f, p.h)
+		}
+		if next == nil {
+			if updated {
+				// the model is wrong: restart the Hessian approximation
+				B, updated = identity(n), false
+				continue
+			}
+			if res.NFev < maxFev {
+				res.Status, res.Message = optimize.Failure, "optimize: sqp: line search failed"
+			}
+			continue
+		}
+		step := make([]float64, n)
+		floats.SubTo(step, next.x, p.x)
+		if converged := next.h <= tol && smallStep(p.x, step, tol); !converged {
+			derive(next)
+			if bfgsUpdate(B, p, next, lambda, updated) {
+				updated = true
+			}
+		} else {
+			// the line search stalls, eg on the noise of the finite differences
+			res.Status, res.Reason = optimize.MethodConverge, ReasonTolX
+		}
+		p = next
+		res.NIter++
+		if s.Callback != nil {
+			s.Callback(p.x)
+		}
+		if s.Logger != nil {
+			s.Logger.Printf("%d\tf=%.6g\tviolation=%.3g\tstep=%.3g\n", res.NIter, p.f, p.h, alpha)
+		}
+		if st.iterate(res.NIter, res.NFev, p.x, func() map[string]float64 {
+			return map[string]float64{"violation": p.h, "penalty": nu, "step": alpha}
+		}) || res.Status != optimize.NotTerminated {
+			break
+		}
+		if p.h <= tol {
+			if s.TargetF != nil && p.f <= *s.TargetF {
+				res.Status, res.Reason = optimize.FunctionThreshold, ReasonTarget
+				break
+			}
+			sw.observe(p.f)
+		}
+		if sw.stalled(res.NIter) {
+			res.Status, res.Reason = optimize.FunctionConvergence, ReasonStagnation
+			break
+		}
+	}
+	st.apply(res)
+	if res.Status == optimize.NotTerminated {
+		res.Status = optimize.IterationLimit
+	}
+	res.X, res.F = p.x, p.f
+	if lambda == nil {
+		lambda = make([]float64, me+mi)
+	}
+	res.Extra = &Multipliers{Eq: lambda[:me:me], Ineq: lambda[me : me+mi : me+mi]}
+	if p.h > tol && res.Status != optimize.Failure {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("optimize: sqp: constraint violation %.3g at the solution", p.h))
+	}
+	return st.done(res.done(start))
+}
+
+// smallStep returns whether every |d_i| <= tol*(1+|x_i|)
+func smallStep(x, d []float64, tol float64) bool {
+	for i, di := range d {
+		if math.Abs(di) > tol*(1+math.Abs(x[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// identity returns the identity matrix of size n
+func identity(n int) *mat.SymDense {
+	B := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		B.SetSym(i, i, 1)
+	}
+	return B
+}
+
+// jacobian returns the Jacobian of g at x by central differences, nil if g
+// is nil or has no component
+func jacobian(g func([]float64) []float64, x []float64) *mat.Dense {
+	if g == nil {
+		return nil
+	}
+	m := len(g(x))
+	if m == 0 {
+		return nil
+	}
+	J := mat.NewDense(m, len(x), nil)
+	opts := &GradientOptions{}
+	xh := append([]float64(nil), x...)
+	for i := range x {
+		h := opts.step(x, i)
+		xh[i] = x[i] + h
+		gp := append([]float64(nil), g(xh)...)
+		xh[i] = x[i] - h
+		gm := g(xh)
+		xh[i] = x[i]
+		for j := 0; j < m; j++ {
+			J.Set(j, i, (gp[j]-gm[j])/(2*h))
+		}
+	}
+	return J
+}
+
+// sqpConstraints returns the linearized constraints A d + b = 0 for the
+// first len(p.ceq) rows and A d + b <= 0 for the others at p: equalities,
+// inequalities, then the finite bounds
+func sqpConstraints(p *sqpPoint, box Box) (*mat.Dense, []float64) {
+	n := len(p.x)
+	var rows [][]float64
+	var b []float64
+	for _, c := range []struct {
+		J *mat.Dense
+		v []float64
+	}{{p.jeq, p.ceq}, {p.jc, p.c}} {
+		for j, vj := range c.v {
+			rows, b = append(rows, mat.Row(nil, j, c.J)), append(b, vj)
+		}
+	}
+	for i, xi := range p.x {
+		if i < len(box.Xmin) && !math.IsInf(box.Xmin[i], -1) {
+			row := make([]float64, n)
+			row[i] = -1
+			rows, b = append(rows, row), append(b, box.Xmin[i]-xi)
+		}
+		if i < len(box.Xmax) && !math.IsInf(box.Xmax[i], 1) {
+			row := make([]float64, n)
+			row[i] = 1
+			rows, b = append(rows, row), append(b, xi-box.Xmax[i])
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	A := mat.NewDense(len(rows), n, nil)
+	for j, row := range rows {
+		A.SetRow(j, row)
+	}
+	return A, b
+}
+
+// linearViolation returns the l1 violation at d of the first m linearized
+// constraints, the first me being equalities
+func linearViolation(A *mat.Dense, b, d []float64, me, m int) float64 {
+	h := 0.
+	for j := 0; j < m; j++ {
+		v := floats.Dot(A.RawRowView(j), d) + b[j]
+		if j < me {
+			v = math.Abs(v)
+		}
+		h += math.Max(v, 0)
+	}
+	return h
+}
+
+// qpSubproblem solves min 1/2 d^T B d + g^T d under A_j d + b_j = 0 for
+// j < me and A_j d + b_j <= 0 for the other rows, the violation of the
+// constraints being penalized by mu: its dual, of multipliers
+// |lambda_j| <= mu, is solved by coordinate descent (Hildreth's method)
+// from lambda0 if it has the number of rows of A. B must be positive
+// definite.
+func qpSubproblem(B *mat.SymDense, g []float64, A *mat.Dense, b []float64, me int, mu float64, lambda0 []float64) (d, lambda []float64, err error) {
+	var chol mat.Cholesky
+	if !chol.Factorize(B) {
+		return nil, nil, errors.New("optimize: sqp: Hessian approximation not positive definite")
+	}
+	n := len(g)
+	dv := mat.NewVecDense(n, nil)
+	if err := chol.SolveVecTo(dv, mat.NewVecDense(n, append([]float64(nil), g...))); err != nil {
+		return nil, nil, err
+	}
+	if A == nil {
+		d = make([]float64, n)
+		floats.ScaleTo(d, -1, dv.RawVector().Data)
+		return d, nil, nil
+	}
+	m, _ := A.Dims()
+	// W = B^-1 A^T, M = A W and r = A B^-1 g - b
+	var W, M mat.Dense
+	if err := chol.SolveTo(&W, A.T()); err != nil {
+		return nil, nil, err
+	}
+	M.Mul(A, &W)
+	r := make([]float64, m)
+	for j := range r {
+		r[j] = floats.Dot(A.RawRowView(j), dv.RawVector().Data) - b[j]
+	}
+	lambda = make([]float64, m)
+	if len(lambda0) == m {
+		copy(lambda, lambda0)
+	}
+	for j := range lambda {
+		lo := 0.
+		if j < me {
+			lo = -mu
+		}
+		lambda[j] = math.Max(math.Min(lambda[j], mu), lo)
+	}
+	// grad = M lambda + r, the gradient of the dual objective
+	grad := append([]float64(nil), r...)
+	for j, lj := range lambda {
+		if lj != 0 {
+			floats.AddScaled(grad, lj, M.RawRowView(j))
+		}
+	}
+	for sweep := 0; sweep < 10000; sweep++ {
+		change := 0.
+		for j := range lambda {
+			lo := 0.
+			if j < me {
+				lo = -mu
+			}
+			mjj := M.At(j, j)
+			var lj float64
+			switch {
+			case mjj > 0:
+				lj = lambda[j] - grad[j]/mjj
+			case grad[j] < 0:
+				lj = mu
+			default:
+				lj = lo
+			}
+			lj = math.Max(math.Min(lj, mu), lo)
+			if delta := lj - lambda[j]; delta != 0 {
+				lambda[j] = lj
+				floats.AddScaled(grad, delta, M.RawRowView(j))
+				change = math.Max(change, math.Abs(delta)*math.Sqrt(math.Max(mjj, 0)))
+			}
+		}
+		if change <= 1e-14*(1+floats.Norm(r, math.Inf(1))) {
+			break
+		}
+	}
+	// d = -B^-1 (g + A^T lambda)
+	d = make([]float64, n)
+	floats.ScaleTo(d, -1, dv.RawVector().Data)
+	for i := range d {
+		d[i] -= floats.Dot(W.RawRowView(i), lambda)
+	}
+	return d, lambda, nil
+}
+
+// bfgsUpdate applies the damped BFGS update of Powell to B for the step
+// from p to next, y being the difference of the gradients of the
+// Lagrangian of multipliers lambda. The first update scales B. It returns
+// false if the update is skipped.
+func bfgsUpdate(B *mat.SymDense, p, next *sqpPoint, lambda []float64, updated bool) bool {
+	n := len(p.x)
+	s, y := make([]float64, n), make([]float64, n)
+	floats.SubTo(s, next.x, p.x)
+	floats.SubTo(y, next.g, p.g)
+	me := len(p.ceq)
+	for _, c := range []struct {
+		j0, j1 *mat.Dense
+		l      []float64
+	}{{p.jeq, next.jeq, lambda[:me]}, {p.jc, next.jc, lambda[me : me+len(p.c)]}} {
+		for j, lj := range c.l {
+			if lj == 0 {
+				continue
+			}
+			floats.AddScaled(y, lj, mat.Row(nil, j, c.j1))
+			floats.AddScaled(y, -lj, mat.Row(nil, j, c.j0))
+		}
+	}
+	sy, yy := floats.Dot(s, y), floats.Dot(y, y)
+	if !updated && sy > 0 {
+		B.ScaleSym(yy/sy, identity(n))
+	}
+	bs := mat.NewVecDense(n, nil)
+	bs.MulVec(B, mat.NewVecDense(n, s))
+	sBs := floats.Dot(s, bs.RawVector().Data)
+	if !(sBs > 0) {
+		return false
+	}
+	if sy < .2*sBs {
+		theta := .8 * sBs / (sBs - sy)
+		for i := range y {
+			y[i] = theta*y[i] + (1-theta)*bs.AtVec(i)
+		}
+		sy = floats.Dot(s, y)
+	}
+	if !(sy > 0) {
+		return false
+	}
+	B.SymRankOne(B, -1/sBs, bs)
+	B.SymRankOne(B, 1/sy, mat.NewVecDense(n, y))
+	return true
+}
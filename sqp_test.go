@@ -0,0 +1,129 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleSQP() {
+	// problem 71 of Hock and Schittkowski
+	s := NewSQP()
+	s.Ineq = func(x []float64) []float64 { return []float64{25 - x[0]*x[1]*x[2]*x[3]} }
+	s.Eq = func(x []float64) []float64 { return []float64{x[0]*x[0] + x[1]*x[1] + x[2]*x[2] + x[3]*x[3] - 40} }
+	s.Xmin, s.Xmax = []float64{1, 1, 1, 1}, []float64{5, 5, 5, 5}
+	res := s.Minimize(func(x []float64) float64 { return x[0]*x[3]*(x[0]+x[1]+x[2]) + x[2] }, []float64{1, 5, 5, 1})
+	m := res.Extra.(*Multipliers)
+	fmt.Printf("%s x=%.4f f=%.6f multipliers %.4f %.4f\n", res.Status, res.X, res.F, m.Ineq, m.Eq)
+	// Output:
+	// MethodConverge x=[1.0000 4.7430 3.8211 1.3794] f=17.014017 multipliers [0.5523] [0.1615]
+}
+
+func TestSQP(t *testing.T) {
+	quad := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]-2)*(x[1]-2) }
+	half := func(x []float64) []float64 { return []float64{x[0] + x[1] - 1} }
+	for _, c := range []struct {
+		name       string
+		s          *SQP
+		f          func([]float64) float64
+		x0, x      []float64
+		ineq, eq   []float64
+		multiplier bool
+	}{
+		{name: "unconstrained", s: &SQP{}, f: rosen, x0: []float64{-1.2, 1}, x: []float64{1, 1}},
+		{name: "half-plane", s: &SQP{Ineq: half}, f: quad, x0: []float64{3, 3}, x: []float64{0, 1}, ineq: []float64{2}},
+		{name: "inactive", s: &SQP{Ineq: half}, f: quad, x0: []float64{-1, -3}, x: []float64{0, 1}, ineq: []float64{2}},
+		{name: "disk", s: &SQP{Ineq: func(x []float64) []float64 { return []float64{x[0]*x[0] + x[1]*x[1] - 1} }}, f: rosen, x0: []float64{-1.2, 1},
+			x: []float64{0.786415, 0.617698}},
+		{name: "equalities", s: &SQP{Eq: func(x []float64) []float64 { return []float64{x[0] + x[1] + x[2] - 1, x[0] - x[1]} }},
+			f: func(x []float64) float64 { return x[0]*x[0] + 2*x[1]*x[1] + 3*x[2]*x[2] }, x0: []float64{5, -3, 2}, x: []float64{.4, .4, .2}, eq: []float64{-1.2, .4}},
+		// the linearization at x0 is infeasible
+		{name: "elastic", s: &SQP{Eq: func(x []float64) []float64 { return []float64{x[0]*x[0] - 1} }},
+			f: func(x []float64) float64 { return x[0] + x[1]*x[1] }, x0: []float64{0, 1}, x: []float64{-1, 0}, eq: []float64{.5}},
+		{name: "bounds", s: &SQP{Xmin: []float64{-2, 1.5}, Xmax: []float64{2, 3}}, f: rosen, x0: []float64{1, 2}, x: []float64{1.224371, 1.5}},
+	} {
+		// the line search on the merit function, then on a filter
+		for _, filter := range []bool{false, true} {
+			s := *c.s
+			name := c.name
+			if filter {
+				s.Filter, name = NewFilter(), c.name+" filter"
+			}
+			res := s.Minimize(c.f, c.x0)
+			if res.Status != optimize.MethodConverge || res.Reason != ReasonTolX || len(res.Warnings) != 0 {
+				t.Errorf("%s: got %v", name, res)
+			}
+			for i := range c.x {
+				if math.Abs(res.X[i]-c.x[i]) > 1e-4 {
+					t.Errorf("%s: got x %v, expected %v", name, res.X, c.x)
+				}
+			}
+			m := res.Extra.(*Multipliers)
+			for i, l := range c.ineq {
+				if math.Abs(m.Ineq[i]-l) > 1e-4 {
+					t.Errorf("%s: got multipliers %v", name, m.Ineq)
+				}
+			}
+			for i, l := range c.eq {
+				if math.Abs(m.Eq[i]-l) > 1e-4 {
+					t.Errorf("%s: got multipliers %v", name, m.Eq)
+				}
+			}
+		}
+	}
+	// the infeasible iterates of the half-plane problem enter the filter
+	filter := NewFilter()
+	if res := (&SQP{Ineq: half, Filter: filter}).Minimize(quad, []float64{3, 3}); res.Status != optimize.MethodConverge || filter.Len() == 0 {
+		t.Errorf("filter: got %v, %d entries", res, filter.Len())
+	}
+
+	// infeasible constraints
+	res := (&SQP{Ineq: func(x []float64) []float64 { return []float64{x[0] + 1, 1 - x[0]} }}).Minimize(quad, []float64{0, 0})
+	if len(res.Warnings) != 1 || !strings.HasPrefix(res.Warnings[0], "optimize: sqp: constraint violation") {
+		t.Errorf("got %v", res)
+	}
+	// limits and invalid starts
+	if res := (&SQP{MaxIter: 3}).Minimize(rosen, []float64{-1.2, 1}); res.Status != optimize.IterationLimit || res.NIter != 3 {
+		t.Errorf("got %v", res)
+	}
+	if res := (&SQP{MaxFev: 50}).Minimize(rosen, []float64{-1.2, 1}); res.Status != optimize.FunctionEvaluationLimit {
+		t.Errorf("got %v", res)
+	}
+	if res := NewSQP().Minimize(rosen, []float64{math.NaN(), 1}); res.Status != optimize.Failure {
+		t.Errorf("got %v", res)
+	}
+	ngrad := 0
+	if res := (&SQP{Grad: func(grad, x []float64) { ngrad++; rosenGrad(grad, x) }}).Minimize(rosen, []float64{-1.2, 1}); math.Abs(res.X[0]-1) > 1e-6 || res.NGrad != ngrad {
+		t.Errorf("got %v", res)
+	}
+
+	// through Minimize and the registry, the constraints are not penalized
+	r, err := Minimize(optimize.Problem{Func: quad}, []float64{3, 3}, &Options{Method: "sqp", Ineq: half})
+	if err != nil || math.Abs(r.X[0]) > 1e-6 || math.Abs(r.X[1]-1) > 1e-6 || r.Extra.(*Multipliers).Ineq[0] < 1.9 {
+		t.Errorf("got %v %v", r, err)
+	}
+	// TargetF and the stall test of Options
+	target := 1.
+	if r, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Method: "sqp", TargetF: &target}); err != nil || r.Status != optimize.FunctionThreshold || r.F > target {
+		t.Errorf("TargetF: got %v %v", r, err)
+	}
+	if r, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Method: "sqp", StallIterations: 2, StallTolerance: 1e3}); err != nil || r.Status != optimize.FunctionConvergence || r.NIter != 3 {
+		t.Errorf("stall: got %v %v", r, err)
+	}
+	m, err := NewAlgorithm("LD_SQP", WithConstraints(half, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := m.Minimize(quad, []float64{3, 3}); math.Abs(r.X[0]) > 1e-6 || math.Abs(r.X[1]-1) > 1e-6 {
+		t.Errorf("got %v", r)
+	}
+	if _, err := NewSQPWith(WithConstraints(half, nil), WithMaxFev(100)); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewPowellMinimizerWith(WithConstraints(half, nil)); err == nil {
+		t.Error("expected an error for the constraints of Powell")
+	}
+}
@@ -0,0 +1,71 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+)
+
+// WithStall sets the StallIterations and StallTolerance of a solver: it
+// stops with Status FunctionConvergence when the best value has not
+// decreased by more than tol over the last iterations.
+func WithStall(iterations int, tol float64) Option {
+	return func(s configurable) error {
+		if iterations < 0 || !(tol >= 0) {
+			return errors.New("optimize: WithStall: negative value")
+		}
+		f := s.fields()
+		if f.stallIterations == nil {
+			return unsupported("WithStall", s)
+		}
+		*f.stallIterations, *f.stallTolerance = iterations, tol
+		return nil
+	}
+}
+
+// stallWatch detects that the best observed value has not decreased by
+// more than tol over the last n iterations
+type stallWatch struct {
+	n           int
+	tol         float64
+	best, ref   float64
+	count, iter int
+}
+
+func newStallWatch(n int, tol float64) *stallWatch {
+	return &stallWatch{n: n, tol: tol, best: math.Inf(1), ref: math.Inf(1), iter: -1}
+}
+
+// wrap returns f observing its values
+func (sw *stallWatch) wrap(f func([]float64) float64) func([]float64) float64 {
+	if sw.n <= 0 {
+		return f
+	}
+	return func(x []float64) float64 {
+		y := f(x)
+		sw.observe(y)
+		return y
+	}
+}
+
+func (sw *stallWatch) observe(f float64) {
+	if f < sw.best {
+		sw.best = f
+	}
+}
+
+// stalled is called at the end of iteration iter. Further calls for the
+// same iteration return the same result.
+func (sw *stallWatch) stalled(iter int) bool {
+	if sw.n <= 0 {
+		return false
+	}
+	if iter != sw.iter {
+		sw.iter = iter
+		if sw.ref-sw.best > sw.tol {
+			sw.ref, sw.count = sw.best, 0
+		} else {
+			sw.count++
+		}
+	}
+	return sw.count >= sw.n
+}
@@ -0,0 +1,51 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleWithStall() {
+	// the value decreases slowly towards 0 at infinity
+	f := func(x []float64) float64 { return 1 / (1 + x[0]*x[0] + x[1]*x[1]) }
+	sp, err := NewSPSAWith(WithRNG(rand.NewSource(1)), WithMaxIter(100000), WithStall(100, 1e-4))
+	if err != nil {
+		panic(err)
+	}
+	res := sp.Minimize(f, []float64{1, 1})
+	fmt.Println(res.Status, res.NIter < 100000)
+	// Output:
+	// FunctionConvergence true
+}
+
+func TestStall(t *testing.T) {
+	f := func(x []float64) float64 { return 1 / (1 + x[0]*x[0] + x[1]*x[1]) }
+	pm := NewPowellMinimizer()
+	pm.Ftol, pm.MaxIter, pm.StallIterations, pm.StallTolerance = 1e-15, 1000, 2, 1e-3
+	if res := pm.Minimize(rosen, []float64{-1.2, 1}); res.Status != optimize.FunctionConvergence || res.NIter >= 1000 {
+		t.Errorf("powell: %s after %d iterations", res.Status, res.NIter)
+	}
+	cma := &CmaEsCholB{Src: rand.NewSource(1), StallIterations: 10, StallTolerance: 1e-3}
+	r, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{1, 1}, &optimize.Settings{Converger: optimize.NeverTerminate{}, FuncEvaluations: 100000}, cma)
+	if err != nil || r.Status != optimize.FunctionConvergence || r.FuncEvaluations >= 100000 {
+		t.Errorf("cmaes: %s after %d evaluations, %v", r.Status, r.FuncEvaluations, err)
+	}
+	ls := NewLatticeSearch()
+	ls.TabuSize, ls.MaxStall, ls.StallIterations = 5, 1000, 4
+	if res := ls.Minimize(func(x []float64) float64 { return -f(x) }, []float64{3, 3}); res.Status != optimize.FunctionConvergence || res.F != -1 {
+		t.Errorf("lattice: %s f=%g", res.Status, res.F)
+	}
+	train := linearRegression{t: []float64{0, 1, 2, 3}, y: []float64{1, 3, 5, 7}}
+	sg := NewStochasticGradient(&Adam{}, .05)
+	sg.BatchSize, sg.Epochs, sg.Src, sg.StallIterations, sg.StallTolerance = 2, 100000, rand.NewSource(1), 5, 1e-12
+	if res := sg.MinimizeStochastic(train, []float64{0, 0}); res.Status != optimize.FunctionConvergence {
+		t.Errorf("stochastic: %s after %d iterations", res.Status, res.NIter)
+	}
+	res, err := Minimize(optimize.Problem{Func: f}, []float64{1, 1}, &Options{Method: "neldermead", StallIterations: 5, StallTolerance: 1e-3})
+	if err != nil || res.Status != optimize.FunctionConvergence {
+		t.Errorf("neldermead: %v %v", res, err)
+	}
+}
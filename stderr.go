@@ -0,0 +1,74 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// StdErrOptions are the settings of StandardErrors
+type StdErrOptions struct {
+	// Hessian are the finite difference settings, nil for the defaults of Hessian
+	Hessian *HessianOptions
+	// Residuals, if positive, is the number of residuals of a least squares
+	// objective, f being the sum of their squares: the covariance is then
+	// 2 f(x)/(Residuals-dim) H^-1. Otherwise f is a negative log-likelihood
+	// and the covariance is H^-1.
+	Residuals int
+}
+
+// Uncertainty is the result of StandardErrors
+type Uncertainty struct {
+	// Hessian is the Hessian of f at the minimum and Covariance the
+	// covariance of the parameters
+	Hessian, Covariance *mat.SymDense
+	// StdErr are the standard errors of the parameters, the square roots of
+	// the diagonal of Covariance
+	StdErr []float64
+	// Correlation is the correlation matrix of the parameters
+	Correlation *mat.SymDense
+}
+
+// StandardErrors estimates the standard errors and the correlations of the
+// parameters of a fit from the numerical Hessian of f at res.X, the minimum
+// returned by a minimizer. opts may be nil. It returns an error if the
+// Hessian is not positive definite, eg at a saddle point or if a parameter
+// is not identifiable.
+func StandardErrors(f func([]float64) float64, res *Result, opts *StdErrOptions) (*Uncertainty, error) {
+	if opts == nil {
+		opts = &StdErrOptions{}
+	}
+	n := len(res.X)
+	if n == 0 {
+		return nil, errors.New(nonpositiveDimension)
+	}
+	scale := 1.
+	if opts.Residuals > 0 {
+		if opts.Residuals <= n {
+			return nil, errors.New("optimize: StandardErrors needs more residuals than parameters")
+		}
+		// H ~ 2 J^T J and the variance of the residuals is f/(m-n)
+		scale = 2 * f(res.X) / float64(opts.Residuals-n)
+	}
+	u := &Uncertainty{Hessian: Hessian(f, res.X, opts.Hessian), Covariance: mat.NewSymDense(n, nil)}
+	var chol mat.Cholesky
+	if !chol.Factorize(u.Hessian) {
+		return nil, errors.New("optimize: Hessian not positive definite at the minimum")
+	}
+	if err := chol.InverseTo(u.Covariance); err != nil {
+		return nil, err
+	}
+	u.Covariance.ScaleSym(scale, u.Covariance)
+	u.StdErr = make([]float64, n)
+	for i := range u.StdErr {
+		u.StdErr[i] = math.Sqrt(u.Covariance.At(i, i))
+	}
+	u.Correlation = mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			u.Correlation.SetSym(i, j, u.Covariance.At(i, j)/(u.StdErr[i]*u.StdErr[j]))
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,71 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleStandardErrors() {
+	// least squares fit of y = p0 + p1 x
+	xs, ys := []float64{0, 1, 2, 3}, []float64{1.1, 2.9, 5.2, 6.8}
+	f := fitLine(xs, ys)
+	res := NewPowellMinimizer().Minimize(f, []float64{0, 0})
+	u, err := StandardErrors(f, res, &StdErrOptions{Residuals: len(xs)})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("p %.3f, stderr %.3f, correlation %.3f\n", res.X, u.StdErr, u.Correlation.At(0, 1))
+	// Output:
+	// p [1.090 1.940], stderr [0.169 0.091], correlation -0.802
+}
+
+func TestStandardErrors(t *testing.T) {
+	// ordinary least squares
+	xs, ys := []float64{0, 1, 2, 3, 4}, []float64{1.1, 2.9, 5.2, 6.8, 9.3}
+	f := fitLine(xs, ys)
+	res := NewPowellMinimizer().Minimize(f, []float64{0, 0})
+	u, err := StandardErrors(f, res, &StdErrOptions{Residuals: len(xs)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, xbar, sxx := 5., 2., 10.
+	s2 := f(res.X) / (n - 2)
+	want := []float64{math.Sqrt(s2 * (1/n + xbar*xbar/sxx)), math.Sqrt(s2 / sxx)}
+	corr := -xbar / math.Sqrt(sxx/n+xbar*xbar)
+	if math.Abs(u.StdErr[0]-want[0]) > 1e-5 || math.Abs(u.StdErr[1]-want[1]) > 1e-5 || math.Abs(u.Correlation.At(0, 1)-corr) > 1e-5 || math.Abs(u.Correlation.At(1, 1)-1) > 1e-12 {
+		t.Errorf("got %g %g, expected %g %g", u.StdErr, u.Correlation.At(0, 1), want, corr)
+	}
+
+	// negative log-likelihood of the mean of a normal of variance 4
+	obs := []float64{1, 2, 4, 5}
+	nll := func(p []float64) float64 {
+		s := 0.
+		for _, y := range obs {
+			s += (y - p[0]) * (y - p[0]) / 8
+		}
+		return s
+	}
+	u, err = StandardErrors(nll, &Result{X: []float64{3}}, nil)
+	if err != nil || math.Abs(u.StdErr[0]-1) > 1e-6 || math.Abs(u.Covariance.At(0, 0)-1) > 1e-6 {
+		t.Errorf("got %v %v", u, err)
+	}
+
+	saddle := func(x []float64) float64 { return x[0]*x[0] - x[1]*x[1] }
+	if _, err := StandardErrors(saddle, &Result{X: []float64{0, 0}}, nil); err == nil {
+		t.Error("expected an error at a saddle point")
+	}
+	if _, err := StandardErrors(f, res, &StdErrOptions{Residuals: 2}); err == nil {
+		t.Error("expected an error for too few residuals")
+	}
+	if _, err := StandardErrors(f, &Result{}, nil); err == nil {
+		t.Error("expected an error for dimension 0")
+	}
+	u, err = StandardErrors(f, res, &StdErrOptions{Residuals: len(xs), Hessian: &HessianOptions{Concurrent: 2}})
+	if err != nil || !mat.EqualApprox(u.Hessian, mat.NewSymDense(2, []float64{10, 20, 20, 60}), 1e-4) {
+		t.Errorf("got %v %v", u, err)
+	}
+}
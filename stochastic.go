@@ -0,0 +1,479 @@
+package optimize
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// LearningRate is a learning rate schedule
+type LearningRate interface {
+	Rate(iter int) float64
+}
+
+// ConstantRate is a constant learning rate
+type ConstantRate float64
+
+// Rate returns lr
+func (lr ConstantRate) Rate(int) float64 { return float64(lr) }
+
+// ExponentialDecay is the schedule Initial*Decay^iter
+type ExponentialDecay struct {
+	Initial, Decay float64
+}
+
+// Rate returns Initial*Decay^iter
+func (lr ExponentialDecay) Rate(iter int) float64 {
+	return lr.Initial * math.Pow(lr.Decay, float64(iter))
+}
+
+// InverseTimeDecay is the schedule Initial/(1+Decay*iter)
+type InverseTimeDecay struct {
+	Initial, Decay float64
+}
+
+// Rate returns Initial/(1+Decay*iter)
+func (lr InverseTimeDecay) Rate(iter int) float64 {
+	return lr.Initial / (1 + lr.Decay*float64(iter))
+}
+
+// StepDecay multiplies Initial by Factor every Every iterations, no decay
+// if Every is not positive
+type StepDecay struct {
+	Initial, Factor float64
+	Every           int
+}
+
+// Rate returns Initial*Factor^(iter/Every)
+func (lr StepDecay) Rate(iter int) float64 {
+	if lr.Every <= 0 {
+		return lr.Initial
+	}
+	return lr.Initial * math.Pow(lr.Factor, float64(iter/lr.Every))
+}
+
+// CosineDecay decays from Initial to Final over Iterations with a cosine
+// shape, no decay if Iterations is not positive
+type CosineDecay struct {
+	Initial, Final float64
+	Iterations     int
+}
+
+// Rate returns the annealed learning rate
+func (lr CosineDecay) Rate(iter int) float64 {
+	if lr.Iterations <= 0 {
+		return lr.Initial
+	}
+	t := math.Min(float64(iter)/float64(lr.Iterations), 1)
+	return lr.Final + (lr.Initial-lr.Final)*(1+math.Cos(math.Pi*t))/2
+}
+
+// StochasticUpdater is a first order update rule of stochastic gradient methods
+type StochasticUpdater interface {
+	// Init allocates the state for dimension dim
+	Init(dim int)
+	// Update updates x in place given a gradient estimate and the learning rate
+	Update(x, grad []float64, lr float64)
+}
+
+var (
+	_ StochasticUpdater = &SGD{}
+	_ StochasticUpdater = &Momentum{}
+	_ StochasticUpdater = &Adam{}
+	_ StochasticUpdater = &RMSProp{}
+)
+
+// SGD is plain stochastic gradient descent x -= lr*grad
+type SGD struct{}
+
+// Init does nothing
+func (*SGD) Init(int) {}
+
+// Update for SGD
+func (*SGD) Update(x, grad []float64, lr float64) {
+	for i, g := range grad {
+		x[i] -= lr * g
+	}
+}
+
+// Momentum is SGD with heavy ball or Nesterov momentum. Beta defaults to 0.9
+type Momentum struct {
+	Beta     float64
+	Nesterov bool
+	v        []float64
+}
+
+// Init resets velocity
+func (m *Momentum) Init(dim int) {
+	m.v = resize(m.v, dim)
+	for i := range m.v {
+		m.v[i] = 0
+	}
+}
+
+// Update for Momentum
+func (m *Momentum) Update(x, grad []float64, lr float64) {
+	beta := m.Beta
+	if beta == 0 {
+		beta = .9
+	}
+	for i, g := range grad {
+		m.v[i] = beta*m.v[i] - lr*g
+		if m.Nesterov {
+			x[i] += beta*m.v[i] - lr*g
+		} else {
+			x[i] += m.v[i]
+		}
+	}
+}
+
+// Adam is the adaptive moment estimation method of Kingma and Ba.
+// defaults are Beta1=0.9 Beta2=0.999 Epsilon=1e-8
+type Adam struct {
+	Beta1, Beta2, Epsilon float64
+	m, v                  []float64
+	t                     int
+}
+
+// Init resets moments
+func (a *Adam) Init(dim int) {
+	a.m, a.v, a.t = resize(a.m, dim), resize(a.v, dim), 0
+	for i := range a.m {
+		a.m[i], a.v[i] = 0, 0
+	}
+}
+
+// Update for Adam
+func (a *Adam) Update(x, grad []float64, lr float64) {
+	b1, b2, eps := a.Beta1, a.Beta2, a.Epsilon
+	if b1 == 0 {
+		b1 = .9
+	}
+	if b2 == 0 {
+		b2 = .999
+	}
+	if eps == 0 {
+		eps = 1e-8
+	}
+	a.t++
+	c1, c2 := 1-math.Pow(b1, float64(a.t)), 1-math.Pow(b2, float64(a.t))
+	for i, g := range grad {
+		a.m[i] = b1*a.m[i] + (1-b1)*g
+		a.v[i] = b2*a.v[i] + (1-b2)*g*g
+		x[i] -= lr * (a.m[i] / c1) / (math.Sqrt(a.v[i]/c2) + eps)
+	}
+}
+
+// RMSProp divides the gradient by a running root mean square.
+// defaults are Rho=0.9 Epsilon=1e-8
+type RMSProp struct {
+	Rho, Epsilon float64
+	v            []float64
+}
+
+// Init resets the running mean square
+func (r *RMSProp) Init(dim int) {
+	r.v = resize(r.v, dim)
+	for i := range r.v {
+		r.v[i] = 0
+	}
+}
+
+// Update for RMSProp
+func (r *RMSProp) Update(x, grad []float64, lr float64) {
+	rho, eps := r.Rho, r.Epsilon
+	if rho == 0 {
+		rho = .9
+	}
+	if eps == 0 {
+		eps = 1e-8
+	}
+	for i, g := range grad {
+		r.v[i] = rho*r.v[i] + (1-rho)*g*g
+		x[i] -= lr * g / (math.Sqrt(r.v[i]) + eps)
+	}
+}
+
+// StochasticProblem is an objective defined as the mean of losses over samples,
+// evaluated on mini-batches
+type StochasticProblem interface {
+	// NumSamples returns the number of samples
+	NumSamples() int
+	// BatchGradient computes the gradient of the mean loss over the samples of batch
+	// at x in grad and returns the mean loss
+	BatchGradient(grad, x []float64, batch []int) float64
+}
+
+// EpochInfo is passed to epoch-end callbacks
+type EpochInfo struct {
+	Epoch, Iter int
+	// TrainLoss is the mean of batch losses during the epoch
+	TrainLoss float64
+	// ValidationLoss is NaN if there is no validation function
+	ValidationLoss float64
+	LearningRate   float64
+}
+
+// EpochScheduler is implemented by learning rates adapting at the end of each epoch
+type EpochScheduler interface {
+	EpochEnd(info EpochInfo)
+}
+
+// ReduceOnPlateau multiplies the learning rate by Factor when the validation
+// loss (or train loss if there is no validation) has not improved for Patience epochs.
+type ReduceOnPlateau struct {
+	Initial, Factor, Min float64
+	Patience             int
+	rate, best           float64
+	wait                 int
+}
+
+var _ EpochScheduler = &ReduceOnPlateau{}
+
+// Rate returns the current learning rate
+func (lr *ReduceOnPlateau) Rate(int) float64 {
+	if lr.rate == 0 {
+		lr.rate, lr.best = lr.Initial, math.Inf(1)
+	}
+	return lr.rate
+}
+
+// EpochEnd updates the learning rate
+func (lr *ReduceOnPlateau) EpochEnd(info EpochInfo) {
+	loss := info.ValidationLoss
+	if math.IsNaN(loss) {
+		loss = info.TrainLoss
+	}
+	lr.Rate(0)
+	if loss < lr.best {
+		lr.best, lr.wait = loss, 0
+		return
+	}
+	lr.wait++
+	if lr.wait > lr.Patience {
+		lr.rate, lr.wait = math.Max(lr.rate*lr.Factor, lr.Min), 0
+	}
+}
+
+// StochasticGradient minimizes a function given noisy gradient estimates
+type StochasticGradient struct {
+	// Method is the update rule. defaults to &Adam{}
+	Method StochasticUpdater
+	// LearningRate defaults to ConstantRate(1e-3)
+	LearningRate LearningRate
+	// MaxIter defaults to 1000 in Minimize. In MinimizeStochastic, it is
+	// only used if positive.
+	MaxIter  int
+	Callback func([]float64)
+	Logger   *log.Logger
+
+	// settings for MinimizeStochastic
+	// BatchSize defaults to 32
+	BatchSize int
+	// Epochs defaults to 10
+	Epochs int
+	// Validation is an optional function evaluated at the end of each epoch
+	Validation func(x []float64) float64
+	// OnEpoch is called at the end of each epoch. returning true stops the optimization.
+	OnEpoch func(x []float64, info EpochInfo) bool
+	// Src allows a random number generator to be supplied for batch sampling.
+	Src rand.Source
+	// Averaging, if not nil, averages iterates. The averaged solution is
+	// the *Averaged Extra of the Result.
+	Averaging *Averaging
+	// TargetF, if not nil, stops MinimizeStochastic with Status
+	// FunctionThreshold at the end of the first epoch whose loss is <= *TargetF
+	TargetF *float64
+	// StallIterations, if positive, stops MinimizeStochastic with Status
+	// FunctionConvergence when the best epoch loss has not decreased by more
+	// than StallTolerance over the last StallIterations epochs.
+	StallIterations int
+	StallTolerance  float64
+	// Stop, if not nil, is a custom StopCondition evaluated at the end of
+	// each iteration by Minimize, where Iteration.F is NaN, and at the end of
+	// each epoch by MinimizeStochastic, where it is the best epoch loss.
+	Stop StopCondition
+	// Observer, if not nil, receives the events of the run. Evaluations
+	// are not observed.
+	Observer Observer
+}
+
+// NewStochasticGradient returns a *StochasticGradient using method with a
+// constant learning rate lr
+func NewStochasticGradient(method StochasticUpdater, lr float64) *StochasticGradient {
+	return &StochasticGradient{Method: method, LearningRate: ConstantRate(lr), MaxIter: 1000}
+}
+
+func (sg *StochasticGradient) defaults() (StochasticUpdater, LearningRate, int) {
+	method, lr, maxIter := sg.Method, sg.LearningRate, sg.MaxIter
+	if method == nil {
+		method = &Adam{}
+	}
+	if lr == nil {
+		lr = ConstantRate(1e-3)
+	}
+	if maxIter <= 0 {
+		maxIter = 1000
+	}
+	return method, lr, maxIter
+}
+
+// Minimize minimizes d starting at x0. Only d.Gradient is called during
+// iterations, d.Value is evaluated once at the final point.
+func (sg *StochasticGradient) Minimize(d Differentiable, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{Status: optimize.IterationLimit}
+	method, lr, maxIter := sg.defaults()
+	x := make([]float64, len(x0))
+	copy(x, x0)
+	grad := make([]float64, len(x))
+	method.Init(len(x))
+	if sg.Averaging != nil {
+		sg.Averaging.init(len(x))
+	}
+	st := newMonitor(sg.Stop, sg.Observer, "sgd", x0)
+	var timer evalTimer
+	for res.NIter < maxIter {
+		t := time.Now()
+		d.Gradient(grad, x)
+		timer.since(t)
+		res.NGrad++
+		rate := lr.Rate(res.NIter)
+		method.Update(x, grad, rate)
+		res.NIter++
+		if sg.Averaging != nil {
+			sg.Averaging.add(res.NIter, x)
+		}
+		if sg.Callback != nil {
+			sg.Callback(x)
+		}
+		if sg.Logger != nil {
+			sg.Logger.Printf("%d\tlr=%.4g\tx=%.6g\n", res.NIter, rate, x)
+		}
+		if st.iterate(res.NIter, 0, x, func() map[string]float64 { return map[string]float64{"rate": rate} }) {
+			break
+		}
+	}
+	st.apply(res)
+	res.X, res.F = x, d.Value(x)
+	res.NFev = 1
+	if sg.Averaging != nil {
+		if xAvg := sg.Averaging.X(); xAvg != nil {
+			res.Extra = &Averaged{X: xAvg, F: d.Value(xAvg)}
+			res.NFev++
+		}
+	}
+	res.Evals.FuncTime = timer.elapsed()
+	return st.done(res.done(start))
+}
+
+// MinimizeStochastic minimizes the mean loss of p starting at x0, iterating
+// over shuffled mini-batches for Epochs epochs. The F of the Result is the
+// last validation loss, or the last epoch train loss if Validation is nil.
+// The F of the *Averaged Extra is only evaluated if Validation is not nil.
+// Status is Success if OnEpoch stopped the optimization.
+func (sg *StochasticGradient) MinimizeStochastic(p StochasticProblem, x0 []float64) *Result {
+	start := time.Now()
+	res := &Result{Status: optimize.IterationLimit}
+	method, lr, _ := sg.defaults()
+	batchSize, epochs := sg.BatchSize, sg.Epochs
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+	if epochs <= 0 {
+		epochs = 10
+	}
+	rnd := newRand(sg.Src)
+	x := make([]float64, len(x0))
+	copy(x, x0)
+	grad := make([]float64, len(x))
+	method.Init(len(x))
+	if sg.Averaging != nil {
+		sg.Averaging.init(len(x))
+	}
+	perm := make([]int, p.NumSamples())
+	for i := range perm {
+		perm[i] = i
+	}
+	res.F = math.NaN()
+	sw := newStallWatch(sg.StallIterations, sg.StallTolerance)
+	st := newMonitor(sg.Stop, sg.Observer, "sgd", x0)
+	var timer evalTimer
+	for epoch := 0; epoch < epochs; epoch++ {
+		rnd.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		lossSum, nBatch := 0., 0
+		rate := 0.
+		for start := 0; start < len(perm); start += batchSize {
+			if sg.MaxIter > 0 && res.NIter >= sg.MaxIter {
+				break
+			}
+			end := start + batchSize
+			if end > len(perm) {
+				end = len(perm)
+			}
+			t := time.Now()
+			lossSum += p.BatchGradient(grad, x, perm[start:end])
+			timer.since(t)
+			nBatch++
+			res.NGrad++
+			rate = lr.Rate(res.NIter)
+			method.Update(x, grad, rate)
+			res.NIter++
+			if sg.Averaging != nil {
+				sg.Averaging.add(res.NIter, x)
+			}
+			if sg.Callback != nil {
+				sg.Callback(x)
+			}
+		}
+		info := EpochInfo{Epoch: epoch, Iter: res.NIter, TrainLoss: lossSum / float64(nBatch), ValidationLoss: math.NaN(), LearningRate: rate}
+		if sg.Validation != nil {
+			info.ValidationLoss = sg.Validation(x)
+			res.F = info.ValidationLoss
+			res.NFev++
+		} else {
+			res.F = info.TrainLoss
+		}
+		if sg.Logger != nil {
+			sg.Logger.Printf("epoch %d\titer=%d\ttrain=%.6g\tvalidation=%.6g\tlr=%.4g\n", epoch, res.NIter, info.TrainLoss, info.ValidationLoss, rate)
+		}
+		if es, ok := lr.(EpochScheduler); ok {
+			es.EpochEnd(info)
+		}
+		if sg.OnEpoch != nil && sg.OnEpoch(x, info) {
+			res.Status, res.Reason = optimize.Success, ReasonStopCondition
+			break
+		}
+		if sg.TargetF != nil && res.F <= *sg.TargetF {
+			res.Status = optimize.FunctionThreshold
+			break
+		}
+		if sw.observe(res.F); sw.stalled(epoch) {
+			res.Status, res.Reason = optimize.FunctionConvergence, ReasonStagnation
+			break
+		}
+		if st.observe(res.F); st.iterate(res.NIter, res.NFev, x, func() map[string]float64 { return map[string]float64{"rate": rate, "epoch": float64(epoch)} }) {
+			st.apply(res)
+			break
+		}
+		if sg.MaxIter > 0 && res.NIter >= sg.MaxIter {
+			break
+		}
+	}
+	res.X = x
+	if sg.Averaging != nil {
+		if xAvg := sg.Averaging.X(); xAvg != nil {
+			avg := &Averaged{X: xAvg, F: math.NaN()}
+			if sg.Validation != nil {
+				avg.F = sg.Validation(xAvg)
+				res.NFev++
+			}
+			res.Extra = avg
+		}
+	}
+	res.Evals.FuncTime = timer.elapsed()
+	return st.done(res.done(start))
+}
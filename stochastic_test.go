@@ -0,0 +1,120 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+// noisyQuadratic has minimum at (1,-2) and gradients perturbed by gaussian noise
+type noisyQuadratic struct {
+	rnd *rand.Rand
+}
+
+func (nq noisyQuadratic) Value(x []float64) float64 {
+	return (x[0]-1)*(x[0]-1) + 10*(x[1]+2)*(x[1]+2)
+}
+func (nq noisyQuadratic) Gradient(grad, x []float64) {
+	grad[0] = 2*(x[0]-1) + .1*nq.rnd.NormFloat64()
+	grad[1] = 20*(x[1]+2) + .1*nq.rnd.NormFloat64()
+}
+
+func ExampleStochasticGradient() {
+	for _, sg := range []*StochasticGradient{
+		{Method: &SGD{}, LearningRate: InverseTimeDecay{Initial: .04, Decay: .01}, MaxIter: 2000},
+		{Method: &Momentum{Nesterov: true}, LearningRate: ConstantRate(.005), MaxIter: 2000},
+		{Method: &Adam{}, LearningRate: CosineDecay{Initial: .1, Final: 1e-4, Iterations: 2000}, MaxIter: 2000},
+		{Method: &RMSProp{}, LearningRate: StepDecay{Initial: .01, Factor: .1, Every: 500}, MaxIter: 2000},
+	} {
+		res := sg.Minimize(noisyQuadratic{rand.New(rand.NewSource(1))}, []float64{0, 0})
+		fmt.Printf("%T %.2f\n", sg.Method, res.X)
+	}
+	// Output:
+	// *optimize.SGD [1.00 -2.00]
+	// *optimize.Momentum [1.00 -2.00]
+	// *optimize.Adam [1.00 -2.00]
+	// *optimize.RMSProp [1.00 -2.00]
+}
+
+func TestLearningRates(t *testing.T) {
+	for _, tc := range []struct {
+		lr          LearningRate
+		iter        int
+		want, want0 float64
+	}{
+		{ConstantRate(.1), 10, .1, .1},
+		{ExponentialDecay{1, .5}, 2, .25, 1},
+		{InverseTimeDecay{1, 1}, 3, .25, 1},
+		{StepDecay{1, .1, 10}, 25, .01, 1},
+		{CosineDecay{1, 0, 10}, 10, 0, 1},
+		// no decay without a period
+		{StepDecay{1, .1, 0}, 25, 1, 1},
+		{CosineDecay{1, 0, 0}, 10, 1, 1},
+	} {
+		if got := tc.lr.Rate(tc.iter); got-tc.want > 1e-12 || tc.want-got > 1e-12 {
+			t.Errorf("%T: got %g want %g", tc.lr, got, tc.want)
+		}
+		if got := tc.lr.Rate(0); got != tc.want0 {
+			t.Errorf("%T at 0: got %g want %g", tc.lr, got, tc.want0)
+		}
+	}
+}
+
+// linearRegression fits y = x0 + x1*t by least squares
+type linearRegression struct {
+	t, y []float64
+}
+
+func (lr linearRegression) NumSamples() int { return len(lr.t) }
+func (lr linearRegression) BatchGradient(grad, x []float64, batch []int) float64 {
+	grad[0], grad[1] = 0, 0
+	loss := 0.
+	for _, i := range batch {
+		r := x[0] + x[1]*lr.t[i] - lr.y[i]
+		loss += r * r / float64(len(batch))
+		grad[0] += 2 * r / float64(len(batch))
+		grad[1] += 2 * r * lr.t[i] / float64(len(batch))
+	}
+	return loss
+}
+
+func ExampleStochasticGradient_MinimizeStochastic() {
+	rnd := rand.New(rand.NewSource(1))
+	train, validation := linearRegression{}, linearRegression{}
+	for i := 0; i < 1200; i++ {
+		t := rnd.Float64()
+		y := 1 + 2*t + .01*rnd.NormFloat64()
+		if i%4 == 0 {
+			validation.t, validation.y = append(validation.t, t), append(validation.y, y)
+		} else {
+			train.t, train.y = append(train.t, t), append(train.y, y)
+		}
+	}
+	grad := make([]float64, 2)
+	sg := &StochasticGradient{
+		Method:       &Adam{},
+		LearningRate: &ReduceOnPlateau{Initial: .05, Factor: .5, Patience: 1, Min: 1e-4},
+		BatchSize:    16,
+		Epochs:       30,
+		Src:          rand.NewSource(1),
+		Validation: func(x []float64) float64 {
+			return validation.BatchGradient(grad, x, validation.perm())
+		},
+		OnEpoch: func(x []float64, info EpochInfo) bool {
+			return info.ValidationLoss < 1.2e-4
+		},
+	}
+	res := sg.MinimizeStochastic(train, []float64{0, 0})
+	fmt.Printf("x:%.1f validation loss<1.2e-4:%v\n", res.X, res.F < 1.2e-4)
+	// Output:
+	// x:[1.0 2.0] validation loss<1.2e-4:true
+}
+
+func (lr linearRegression) perm() []int {
+	idx := make([]int, len(lr.t))
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
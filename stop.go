@@ -0,0 +1,143 @@
+package optimize
+
+import (
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Iteration is the state of a method passed to a StopCondition and to an
+// Observer at the end of each iteration
+type Iteration struct {
+	// Iter is the number of completed iterations and NFev the number of
+	// function evaluations
+	Iter, NFev int
+	// X is the current iterate, which must not be modified
+	X []float64
+	// F is the best function value evaluated so far, NaN if the method
+	// doesn't evaluate the function
+	F       float64
+	Elapsed time.Duration
+	// Diagnostics are optional method-specific values, eg "step" for
+	// PowellMinimizer, "sigma" for CmaEsCholB or "ak", "ck" for SPSA
+	Diagnostics map[string]float64
+}
+
+// StopCondition is a custom stopping rule evaluated at the end of each
+// iteration by PowellMinimizer, SPSA, LatticeSearch, StochasticGradient and
+// Minimize, and by gonum methods through a StopConverger. Stop returns a
+// status other than NotTerminated to stop the method, which then reports
+// this status (eg Success or FunctionConvergence). Init is called at the
+// beginning of each run to reset the state of the condition.
+type StopCondition interface {
+	Init()
+	Stop(it *Iteration) optimize.Status
+}
+
+// StopFunc is an adapter to allow the use of a stateless function as a
+// StopCondition. Returning true stops the method with Status Success.
+type StopFunc func(it *Iteration) bool
+
+// Init does nothing
+func (StopFunc) Init() {}
+
+// Stop returns Success if fn returns true
+func (fn StopFunc) Stop(it *Iteration) optimize.Status {
+	if fn(it) {
+		return optimize.Success
+	}
+	return optimize.NotTerminated
+}
+
+// And stops when all conditions stop, with the status of the first one.
+// Every condition is evaluated at each iteration.
+func And(conds ...StopCondition) StopCondition { return stopAll(conds) }
+
+// Or stops when any condition stops, with the status of the first one
+// stopping. Every condition is evaluated at each iteration.
+func Or(conds ...StopCondition) StopCondition { return stopAny(conds) }
+
+type stopAll []StopCondition
+
+func (sc stopAll) Init() {
+	for _, c := range sc {
+		c.Init()
+	}
+}
+
+func (sc stopAll) Stop(it *Iteration) optimize.Status {
+	status := optimize.NotTerminated
+	all := len(sc) > 0
+	for _, c := range sc {
+		s := c.Stop(it)
+		if s == optimize.NotTerminated {
+			all = false
+		} else if status == optimize.NotTerminated {
+			status = s
+		}
+	}
+	if !all {
+		return optimize.NotTerminated
+	}
+	return status
+}
+
+type stopAny []StopCondition
+
+func (sc stopAny) Init() {
+	for _, c := range sc {
+		c.Init()
+	}
+}
+
+func (sc stopAny) Stop(it *Iteration) optimize.Status {
+	status := optimize.NotTerminated
+	for _, c := range sc {
+		if s := c.Stop(it); s != optimize.NotTerminated && status == optimize.NotTerminated {
+			status = s
+		}
+	}
+	return status
+}
+
+// StopConverger is an optimize.Converger evaluating Stop at the major
+// iterations of gonum methods, such as CmaEsCholB or Powell. If Stop doesn't
+// stop the method, it defers to Converger, which defaults to gonum's default
+// FunctionConverge. Iteration.NFev is not known and is 0.
+type StopConverger struct {
+	Stop      StopCondition
+	Converger optimize.Converger
+	iter      int
+	start     time.Time
+}
+
+// Init for optimize.Converger
+func (sc *StopConverger) Init(dim int) {
+	if sc.Converger == nil {
+		sc.Converger = &optimize.FunctionConverge{Absolute: 1e-10, Iterations: 100}
+	}
+	sc.Converger.Init(dim)
+	sc.Stop.Init()
+	sc.iter, sc.start = 0, time.Now()
+}
+
+// Converged returns the status of Stop, or of Converger if Stop doesn't stop
+func (sc *StopConverger) Converged(loc *optimize.Location) optimize.Status {
+	sc.iter++
+	if s := sc.Stop.Stop(&Iteration{Iter: sc.iter, X: loc.X, F: loc.F, Elapsed: time.Since(sc.start)}); s != optimize.NotTerminated {
+		return s
+	}
+	return sc.Converger.Converged(loc)
+}
+
+// WithStop sets the StopCondition of a solver
+func WithStop(cond StopCondition) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.stop == nil {
+			return unsupported("WithStop", s)
+		}
+		*f.stop = cond
+		return nil
+	}
+}
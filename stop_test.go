@@ -0,0 +1,82 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleStopCondition() {
+	// stop when f < 1e-4 after at least 22 iterations, or after 1000 evaluations
+	stop := Or(
+		And(StopFunc(func(it *Iteration) bool { return it.F < 1e-4 }), StopFunc(func(it *Iteration) bool { return it.Iter >= 22 })),
+		StopFunc(func(it *Iteration) bool { return it.NFev >= 1000 }),
+	)
+	pm, err := NewPowellMinimizerWith(WithStop(stop))
+	if err != nil {
+		panic(err)
+	}
+	res := pm.Minimize(rosen, []float64{-1.2, 1})
+	fmt.Printf("%s iter:%d f<1e-4:%v\n", res.Status, res.NIter, res.F < 1e-4)
+	// Output:
+	// Success iter:22 f<1e-4:true
+}
+
+// countStop stops after n iterations with status
+type countStop struct {
+	n, calls int
+	status   optimize.Status
+}
+
+func (c *countStop) Init() { c.calls = 0 }
+func (c *countStop) Stop(it *Iteration) optimize.Status {
+	if c.calls++; c.calls >= c.n {
+		return c.status
+	}
+	return optimize.NotTerminated
+}
+
+func TestStopCondition(t *testing.T) {
+	a, b := &countStop{n: 2, status: optimize.Success}, &countStop{n: 3, status: optimize.FunctionConvergence}
+	if s := And(b, a); s.Stop(&Iteration{}) != optimize.NotTerminated || s.Stop(&Iteration{}) != optimize.NotTerminated || s.Stop(&Iteration{}) != optimize.FunctionConvergence {
+		t.Error("and should stop when both conditions stop, with the status of the first one")
+	}
+	a.Init()
+	b.Init()
+	if s := Or(b, a); s.Stop(&Iteration{}) != optimize.NotTerminated || s.Stop(&Iteration{}) != optimize.Success {
+		t.Error("or should stop with the first condition stopping")
+	}
+
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	stop := &countStop{n: 5, status: optimize.Success}
+	sp := NewSPSA()
+	sp.Src, sp.Stop = rand.NewSource(1), stop
+	if res := sp.Minimize(f, []float64{0, 0}); res.Status != optimize.Success || res.NIter != 5 {
+		t.Errorf("spsa: %s after %d iterations", res.Status, res.NIter)
+	}
+	ls := NewLatticeSearch()
+	ls.Stop = stop
+	if res := ls.Minimize(f, []float64{10, 10}); res.Status != optimize.Success || res.NIter != 5 {
+		t.Errorf("lattice: %s after %d iterations", res.Status, res.NIter)
+	}
+	sg := NewStochasticGradient(&Adam{}, .01)
+	sg.Stop = stop
+	if res := sg.Minimize(DiffFunc{Func: f, Grad: func(grad, x []float64) { grad[0], grad[1] = 2*(x[0]-1), 2*(x[1]+2) }}, []float64{0, 0}); res.Status != optimize.Success || res.NIter != 5 {
+		t.Errorf("stochastic: %s after %d iterations", res.Status, res.NIter)
+	}
+	// a condition firing at the converged iteration of powell doesn't relabel it
+	pm := NewPowellMinimizer()
+	converged := pm.Minimize(f, []float64{0, 0})
+	pm.Stop = &countStop{n: converged.NIter, status: optimize.Success}
+	if res := pm.Minimize(f, []float64{0, 0}); res.Status != converged.Status || res.NIter != converged.NIter {
+		t.Errorf("powell: %s after %d iterations, expected %s", res.Status, res.NIter, converged.Status)
+	}
+	for _, method := range []string{"cmaes", "neldermead"} {
+		res, err := Minimize(optimize.Problem{Func: f}, []float64{0, 0}, &Options{Method: method, Stop: stop, Src: rand.NewSource(1)})
+		if err != nil || res.Status != optimize.Success {
+			t.Errorf("%s: %v %v", method, res, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Study runs a stochastic method several times with different seeds, to
+// compare methods on the distribution of their results rather than on a
+// lucky run. Trial runs the method with seed, sending its events to obs,
+// eg with WithObserver(obs), so that the study collects its History.
+type Study struct {
+	Trial func(seed uint64, obs Observer) (*Result, error)
+	// Runs is the number of runs, with seeds Seed, Seed+1...
+	Runs int
+	Seed uint64
+	// Workers, if > 1, is the number of concurrent runs
+	Workers int
+	// Target, if not nil, is the value a run must reach to be a success
+	Target *float64
+}
+
+// StudyResult are the results of the runs of a Study and their statistics.
+// Runs without a finite value count as +Inf in the statistics.
+type StudyResult struct {
+	Results   []*Result
+	Histories []*History
+	// Best is the result of the best run
+	Best *Result
+	// Median, Q1 and Q3 are the quartiles of the best values, with
+	// stat.LinInterp, IQR being Q3-Q1
+	Median, Q1, Q3, IQR float64
+	Mean, Std           float64
+	// SuccessRate is the fraction of the runs reaching Target, NaN without Target
+	SuccessRate float64
+}
+
+// Run runs the trials, and returns the first error of a trial
+func (s *Study) Run() (*StudyResult, error) {
+	if s.Trial == nil || s.Runs <= 0 {
+		return nil, errors.New("optimize: Study needs a Trial and a positive number of Runs")
+	}
+	sr := &StudyResult{Results: make([]*Result, s.Runs), Histories: make([]*History, s.Runs)}
+	errs := make([]error, s.Runs)
+	var ev *Evaluator
+	if s.Workers > 1 {
+		ev = NewEvaluator(s.Workers)
+	}
+	var mu sync.Mutex
+	ev.Do(s.Runs, func(i int) {
+		h := &History{}
+		res, err := s.Trial(s.Seed+uint64(i), h)
+		mu.Lock()
+		sr.Results[i], sr.Histories[i], errs[i] = res, h, err
+		mu.Unlock()
+	})
+	for i, err := range errs {
+		if err == nil && sr.Results[i] == nil {
+			err = errors.New("optimize: Study: Trial returned a nil Result")
+		}
+		if err != nil {
+			return sr, err
+		}
+	}
+
+	fs := make([]float64, s.Runs)
+	success := 0
+	for i, res := range sr.Results {
+		fs[i] = res.F
+		if math.IsNaN(fs[i]) {
+			fs[i] = math.Inf(1)
+		}
+		if sr.Best == nil || fs[i] < sr.Best.F || math.IsNaN(sr.Best.F) {
+			sr.Best = res
+		}
+		if s.Target != nil && fs[i] <= *s.Target {
+			success++
+		}
+	}
+	sort.Float64s(fs)
+	sr.Q1 = stat.Quantile(.25, stat.LinInterp, fs, nil)
+	sr.Median = stat.Quantile(.5, stat.LinInterp, fs, nil)
+	sr.Q3 = stat.Quantile(.75, stat.LinInterp, fs, nil)
+	sr.IQR = sr.Q3 - sr.Q1
+	sr.Mean, sr.Std = stat.MeanStdDev(fs, nil)
+	sr.SuccessRate = math.NaN()
+	if s.Target != nil {
+		sr.SuccessRate = float64(success) / float64(s.Runs)
+	}
+	return sr, nil
+}
+
+// ECDF returns the ECDF of the runtimes of the runs to reach targets at
+// the numbers of evaluations of grid
+func (sr *StudyResult) ECDF(targets []float64, grid []int) []float64 {
+	return ECDF(sr.Histories, targets, grid)
+}
@@ -0,0 +1,68 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleStudy() {
+	target := 1e-6
+	study := &Study{
+		Trial: func(seed uint64, obs Observer) (*Result, error) {
+			opts := &Options{Method: "cmaes", MaxFev: 2000, Src: rand.NewSource(seed), Observer: obs}
+			return Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, opts)
+		},
+		Runs: 10, Seed: 1, Workers: 4, Target: &target,
+	}
+	sr, err := study.Run()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("success rate %.1f, best < 1e-6 %t\n", sr.SuccessRate, sr.Best.F < 1e-6)
+	// fraction of the (run, target) pairs reached after 100 and 2000 evaluations
+	fmt.Println(sr.ECDF([]float64{1e-2, 1e-6}, []int{100, 2000}))
+	// Output:
+	// success rate 0.4, best < 1e-6 true
+	// [0.05 0.65]
+}
+
+func TestStudy(t *testing.T) {
+	study := &Study{
+		Trial: func(seed uint64, obs Observer) (*Result, error) {
+			// the result of a run is its seed
+			obs.OnStart(&Start{})
+			obs.OnEvaluation(&Evaluation{X: []float64{0}, F: float64(seed), N: 1})
+			return &Result{F: float64(seed)}, nil
+		},
+		Runs: 4, Seed: 1,
+	}
+	sr, err := study.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.Best.F != 1 || sr.Median != 2 || sr.Q1 != 1 || sr.Q3 != 3 || sr.IQR != 2 || sr.Mean != 2.5 || !math.IsNaN(sr.SuccessRate) {
+		t.Errorf("statistics %+v", sr)
+	}
+	if got := sr.ECDF([]float64{2}, []int{1}); got[0] != .5 {
+		t.Errorf("ecdf %g", got)
+	}
+
+	study.Trial = func(seed uint64, obs Observer) (*Result, error) {
+		if seed == 3 {
+			return nil, errors.New("failed")
+		}
+		return &Result{}, nil
+	}
+	study.Workers = 2
+	if _, err := study.Run(); err == nil {
+		t.Error("expected the error of a trial")
+	}
+	if _, err := (&Study{}).Run(); err == nil {
+		t.Error("expected an error without Trial")
+	}
+}
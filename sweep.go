@@ -0,0 +1,148 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DimensionSweep runs a registered algorithm on a scalable problem over a
+// range of dimensions and budgets, for the scaling of its evaluations to a
+// target and of its wall time with the dimension, eg to choose between
+// CMA-ES and L-BFGS for a large problem.
+type DimensionSweep struct {
+	// Algorithm is the name of a registered algorithm, see NewAlgorithm
+	Algorithm string
+	// Options configure the algorithm, the budget, the bounds and the
+	// target being set by the sweep
+	Options []Option
+	// Problem returns the problem in dimension dim
+	Problem func(dim int) ProfileProblem
+	Dims    []int
+	// Budgets are the numbers of evaluations per dimension of a run,
+	// 1000 if nil
+	Budgets []int
+	// Target is the value a run must reach
+	Target float64
+	// Runs is the number of runs per dimension and budget, 1 if 0
+	Runs int
+}
+
+// SweepPoint are the runs of a DimensionSweep in a dimension with a budget
+type SweepPoint struct {
+	Dim, Budget int
+	// Runs and Successes are the numbers of runs and of runs reaching the
+	// target
+	Runs, Successes int
+	// ERT is the expected running time of COCO, the evaluations of the runs,
+	// to the target or to the budget, over the successes, +Inf without
+	// success
+	ERT float64
+	// MedianF is the median of the best values of the runs
+	MedianF float64
+	// Elapsed is the mean wall time of a run
+	Elapsed time.Duration
+	Results []*Result
+}
+
+// Run runs the algorithm in the dimensions and budgets, in their order,
+// and returns the first error of a configuration
+func (ds *DimensionSweep) Run() ([]SweepPoint, error) {
+	if _, ok := LookupAlgorithm(ds.Algorithm); !ok {
+		return nil, errors.New("optimize: unknown algorithm " + ds.Algorithm)
+	}
+	if ds.Problem == nil || len(ds.Dims) == 0 {
+		return nil, errors.New("optimize: DimensionSweep needs a Problem and Dims")
+	}
+	budgets, runs := ds.Budgets, ds.Runs
+	if budgets == nil {
+		budgets = []int{1000}
+	}
+	if runs <= 0 {
+		runs = 1
+	}
+	var points []SweepPoint
+	for _, dim := range ds.Dims {
+		p := ds.Problem(dim)
+		if p.Func == nil || len(p.X0) != dim {
+			return points, errors.New("optimize: DimensionSweep Problem without Func or X0 of the dimension")
+		}
+		for _, budget := range budgets {
+			pt := SweepPoint{Dim: dim, Budget: budget, Runs: runs}
+			evals, best := 0, make([]float64, runs)
+			var elapsed time.Duration
+			for r := 0; r < runs; r++ {
+				start := time.Now()
+				res, n, hit, err := ds.run(p, budget*dim)
+				if err != nil {
+					return points, err
+				}
+				elapsed += time.Since(start)
+				evals += n
+				if hit {
+					pt.Successes++
+				}
+				best[r] = res.F
+				if math.IsNaN(best[r]) {
+					best[r] = math.Inf(1)
+				}
+				pt.Results = append(pt.Results, res)
+			}
+			pt.ERT = math.Inf(1)
+			if pt.Successes > 0 {
+				pt.ERT = float64(evals) / float64(pt.Successes)
+			}
+			sort.Float64s(best)
+			pt.MedianF = (best[(runs-1)/2] + best[runs/2]) / 2
+			pt.Elapsed = elapsed / time.Duration(runs)
+			points = append(points, pt)
+		}
+	}
+	return points, nil
+}
+
+// run runs the algorithm on p and returns the evaluations within maxFev,
+// to the first reaching the target
+func (ds *DimensionSweep) run(p ProfileProblem, maxFev int) (res *Result, evals int, hit bool, err error) {
+	opts := append(append([]Option(nil), ds.Options...), WithMaxFev(maxFev), WithTargetF(ds.Target))
+	if p.Xmin != nil || p.Xmax != nil {
+		opts = append(opts, WithBounds(p.Xmin, p.Xmax))
+	}
+	m, err := NewAlgorithm(ds.Algorithm, opts...)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	f := func(x []float64) float64 {
+		y := p.Func(x)
+		if !hit && evals < maxFev {
+			evals++
+			hit = y <= ds.Target
+		}
+		return y
+	}
+	res = m.Minimize(f, append([]float64(nil), p.X0...))
+	return res, evals, hit, nil
+}
+
+// WriteSweepCSV writes points with a header
+// dim,budget,runs,successes,ert,medianf,seconds
+func WriteSweepCSV(w io.Writer, points []SweepPoint) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"dim", "budget", "runs", "successes", "ert", "medianf", "seconds"}); err != nil {
+		return err
+	}
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, pt := range points {
+		rec := []string{strconv.Itoa(pt.Dim), strconv.Itoa(pt.Budget), strconv.Itoa(pt.Runs), strconv.Itoa(pt.Successes),
+			format(pt.ERT), format(pt.MedianF), format(pt.Elapsed.Seconds())}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
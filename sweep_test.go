@@ -0,0 +1,84 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pa-m/optimize/testfunctions"
+)
+
+func ExampleDimensionSweep() {
+	// the evaluations of L-BFGS, of finite difference gradients, and of
+	// Powell's method to solve the sphere to 1e-4 as the dimension grows
+	sphere := func(dim int) ProfileProblem {
+		x0 := make([]float64, dim)
+		for i := range x0 {
+			x0[i] = 1
+		}
+		return ProfileProblem{Name: "sphere", Func: testfunctions.Sphere.Func, X0: x0}
+	}
+	for _, algorithm := range []string{"LD_LBFGS", "LN_POWELL"} {
+		ds := &DimensionSweep{Algorithm: algorithm, Problem: sphere, Dims: []int{2, 8, 32}, Budgets: []int{2000}, Target: 1e-4}
+		points, err := ds.Run()
+		if err != nil {
+			panic(err)
+		}
+		for _, pt := range points {
+			fmt.Printf("%s dim %d: %d/%d successes, ERT %.0f\n", algorithm, pt.Dim, pt.Successes, pt.Runs, pt.ERT)
+		}
+	}
+	// Output:
+	// LD_LBFGS dim 2: 1/1 successes, ERT 11
+	// LD_LBFGS dim 8: 1/1 successes, ERT 35
+	// LD_LBFGS dim 32: 1/1 successes, ERT 131
+	// LN_POWELL dim 2: 1/1 successes, ERT 13
+	// LN_POWELL dim 8: 1/1 successes, ERT 61
+	// LN_POWELL dim 32: 1/1 successes, ERT 253
+}
+
+func TestDimensionSweep(t *testing.T) {
+	rosenbrock := func(dim int) ProfileProblem {
+		vp := VerifyProblems(dim)[3]
+		return ProfileProblem{Name: vp.Name, Func: vp.Func, X0: vp.X0}
+	}
+	ds := &DimensionSweep{Algorithm: "LN_POWELL", Problem: rosenbrock, Dims: []int{2, 4}, Budgets: []int{10, 2000}, Target: 1e-6, Runs: 2}
+	points, err := ds.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 4 {
+		t.Fatalf("%d points, want 4", len(points))
+	}
+	for k, pt := range points {
+		if pt.Dim != ds.Dims[k/2] || pt.Budget != ds.Budgets[k%2] || pt.Runs != 2 || len(pt.Results) != 2 || pt.Elapsed <= 0 {
+			t.Errorf("point %d: %+v", k, pt)
+		}
+		if success := pt.Budget == 2000; success != (pt.Successes == 2) || success != !math.IsInf(pt.ERT, 1) {
+			t.Errorf("dim %d budget %d: %d successes, ERT %g", pt.Dim, pt.Budget, pt.Successes, pt.ERT)
+		}
+		if pt.Successes == 2 && pt.ERT > float64(pt.Budget*pt.Dim) {
+			t.Errorf("dim %d: ERT %g beyond the budget", pt.Dim, pt.ERT)
+		}
+	}
+	// the runs of a deterministic method are the same
+	if pt := points[1]; pt.Results[0].F != pt.Results[1].F || pt.MedianF != pt.Results[0].F {
+		t.Errorf("runs %g %g, median %g", pt.Results[0].F, pt.Results[1].F, pt.MedianF)
+	}
+	var b strings.Builder
+	if err := WriteSweepCSV(&b, points); err != nil || strings.Count(b.String(), "\n") != 5 || !strings.HasPrefix(b.String(), "dim,budget,runs,successes,ert,medianf,seconds\n2,10,2,0,+Inf,") {
+		t.Errorf("WriteSweepCSV: %q %v", b.String(), err)
+	}
+
+	for _, bad := range []*DimensionSweep{
+		{Algorithm: "XX_UNKNOWN", Problem: rosenbrock, Dims: []int{2}},
+		{Algorithm: "LN_POWELL", Dims: []int{2}},
+		{Algorithm: "LN_POWELL", Problem: rosenbrock},
+		{Algorithm: "LN_POWELL", Problem: func(int) ProfileProblem { return ProfileProblem{} }, Dims: []int{2}},
+	} {
+		if _, err := bad.Run(); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+}
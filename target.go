@@ -0,0 +1,73 @@
+package optimize
+
+import (
+	"gonum.org/v1/gonum/optimize"
+)
+
+// TargetConverger is an optimize.Converger stopping gonum methods with
+// Status FunctionThreshold as soon as the value at a major iteration is
+// less than or equal to Target. Otherwise it defers to Converger, which
+// defaults to gonum's default FunctionConverge.
+type TargetConverger struct {
+	Target    float64
+	Converger optimize.Converger
+}
+
+// Init for optimize.Converger
+func (tc *TargetConverger) Init(dim int) {
+	if tc.Converger == nil {
+		tc.Converger = &optimize.FunctionConverge{Absolute: 1e-10, Iterations: 100}
+	}
+	tc.Converger.Init(dim)
+}
+
+// Converged returns FunctionThreshold if loc.F <= Target
+func (tc *TargetConverger) Converged(loc *optimize.Location) optimize.Status {
+	if loc.F <= tc.Target {
+		return optimize.FunctionThreshold
+	}
+	return tc.Converger.Converged(loc)
+}
+
+// WithTargetF sets the TargetF of a solver: it stops with Status
+// FunctionThreshold as soon as a value less than or equal to target is found.
+func WithTargetF(target float64) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.targetF == nil {
+			return unsupported("WithTargetF", s)
+		}
+		*f.targetF = &target
+		return nil
+	}
+}
+
+// targetWatch records the first evaluated point where f(x) <= *target
+type targetWatch struct {
+	target  *float64
+	x       []float64
+	f       float64
+	reached bool
+}
+
+// wrap returns f recording the first point reaching the target
+func (tw *targetWatch) wrap(f func([]float64) float64) func([]float64) float64 {
+	if tw.target == nil {
+		return f
+	}
+	return func(x []float64) float64 {
+		y := f(x)
+		if !tw.reached && y <= *tw.target {
+			tw.x, tw.f, tw.reached = append([]float64(nil), x...), y, true
+		}
+		return y
+	}
+}
+
+// apply sets the point reaching the target, FunctionThreshold and
+// ReasonTarget in res
+func (tw *targetWatch) apply(res *Result) {
+	if tw.reached {
+		res.X, res.F, res.Status, res.Reason = tw.x, tw.f, optimize.FunctionThreshold, ReasonTarget
+	}
+}
@@ -0,0 +1,58 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleWithTargetF() {
+	// good enough below 1e-3
+	opts, err := NewOptions(WithMethod("powell"), WithTargetF(1e-3))
+	if err != nil {
+		panic(err)
+	}
+	res, _ := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, opts)
+	full, _ := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, nil)
+	fmt.Println(res.Status, res.F <= 1e-3, res.NFev < full.NFev)
+	// Output:
+	// FunctionThreshold true true
+}
+
+func TestTargetF(t *testing.T) {
+	target := .01
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	check := func(name string, res *Result) {
+		t.Helper()
+		if res.Status != optimize.FunctionThreshold || res.F > target || f(res.X) != res.F {
+			t.Errorf("%s: %s f=%g at %v", name, res.Status, res.F, res.X)
+		}
+	}
+	pm := NewPowellMinimizer()
+	pm.TargetF = &target
+	check("powell", pm.Minimize(f, []float64{0, 0}))
+	sp := NewSPSA()
+	sp.Src, sp.TargetF = rand.NewSource(1), &target
+	check("spsa", sp.Minimize(f, []float64{0, 0}))
+	ls := NewLatticeSearch()
+	full := ls.Minimize(f, []float64{5, 5})
+	ls.TargetF = new(float64)
+	if res := ls.Minimize(f, []float64{5, 5}); res.Status != optimize.FunctionThreshold || res.F != 0 || res.NFev >= full.NFev {
+		t.Errorf("lattice: %+v", res)
+	}
+	for _, method := range []optimize.Method{&CmaEsCholB{Src: rand.NewSource(1), TargetF: &target}, &optimize.NelderMead{}} {
+		settings := &optimize.Settings{Converger: &TargetConverger{Target: target}}
+		r, err := optimize.Minimize(optimize.Problem{Func: f}, []float64{0, 0}, settings, method)
+		if err != nil || r.Status != optimize.FunctionThreshold || r.F > target {
+			t.Errorf("%T: %v %g %v", method, r.Status, r.F, err)
+		}
+	}
+	train := linearRegression{t: []float64{0, 1, 2, 3}, y: []float64{1, 3, 5, 7}}
+	sg := NewStochasticGradient(&Adam{}, .05)
+	sg.BatchSize, sg.Epochs, sg.Src, sg.TargetF = 2, 1000, rand.NewSource(1), &target
+	if res := sg.MinimizeStochastic(train, []float64{0, 0}); res.Status != optimize.FunctionThreshold || res.F > target {
+		t.Errorf("stochastic: %s %g", res.Status, res.F)
+	}
+}
@@ -0,0 +1,378 @@
+package testfunctions
+
+import "math"
+
+// ConstrainedProblem is a benchmark problem of constraints c(x) <= 0 and
+// ceq(x) = 0, the convention of the WithConstraints option and of SQP
+type ConstrainedProblem struct {
+	Name string
+	Func func(x []float64) float64
+	// Ineq and Eq, if not nil, return the values of the constraints
+	Ineq, Eq func(x []float64) []float64
+	// Lower and Upper are the bounds
+	Lower, Upper []float64
+	// XOpt is the best known solution and FOpt its value. The solutions of
+	// CEC 2006 violating the equality constraints by 1e-4 are refined to
+	// feasible ones, of slightly higher values.
+	XOpt []float64
+	FOpt float64
+}
+
+// Dim returns the dimension of the problem
+func (p *ConstrainedProblem) Dim() int { return len(p.Lower) }
+
+// Violation returns the largest violation of the constraints at x, 0 at a
+// feasible point
+func (p *ConstrainedProblem) Violation(x []float64) float64 {
+	v := 0.
+	if p.Ineq != nil {
+		for _, c := range p.Ineq(x) {
+			v = math.Max(v, c)
+		}
+	}
+	if p.Eq != nil {
+		for _, c := range p.Eq(x) {
+			v = math.Max(v, math.Abs(c))
+		}
+	}
+	return v
+}
+
+// Constrained returns the constrained problems of the suite: the problems
+// G1 and G3 to G13 of the CEC 2006 competition on constrained real-parameter
+// optimization (Liang et al., 2006), and the pressure vessel, welded beam
+// and tension spring design problems of Coello
+func Constrained() []ConstrainedProblem {
+	return []ConstrainedProblem{
+		g1, g3, g4, g5, g6, g7, g8, g9, g10, g11, g12, g13,
+		pressureVessel, weldedBeam, tensionSpring,
+	}
+}
+
+// ConstrainedByName returns the constrained problem named name
+func ConstrainedByName(name string) (ConstrainedProblem, bool) {
+	for _, p := range Constrained() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ConstrainedProblem{}, false
+}
+
+var g1 = ConstrainedProblem{
+	Name: "g01",
+	Func: func(x []float64) float64 {
+		f := 0.
+		for i := 0; i < 4; i++ {
+			f += 5*x[i] - 5*x[i]*x[i]
+		}
+		for i := 4; i < 13; i++ {
+			f -= x[i]
+		}
+		return f
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{
+			2*x[0] + 2*x[1] + x[9] + x[10] - 10,
+			2*x[0] + 2*x[2] + x[9] + x[11] - 10,
+			2*x[1] + 2*x[2] + x[10] + x[11] - 10,
+			-8*x[0] + x[9],
+			-8*x[1] + x[10],
+			-8*x[2] + x[11],
+			-2*x[3] - x[4] + x[9],
+			-2*x[5] - x[6] + x[10],
+			-2*x[7] - x[8] + x[11],
+		}
+	},
+	Lower: make([]float64, 13),
+	Upper: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 100, 100, 100, 1},
+	XOpt:  []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 3, 3, 3, 1},
+	FOpt:  -15,
+}
+
+var g3 = ConstrainedProblem{
+	Name: "g03",
+	Func: func(x []float64) float64 {
+		n := float64(len(x))
+		f := -math.Pow(math.Sqrt(n), n)
+		for _, xi := range x {
+			f *= xi
+		}
+		return f
+	},
+	Eq: func(x []float64) []float64 {
+		s := -1.
+		for _, xi := range x {
+			s += xi * xi
+		}
+		return []float64{s}
+	},
+	Lower: make([]float64, 10),
+	Upper: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	XOpt:  fill(10, 1/math.Sqrt(10)),
+	FOpt:  -1,
+}
+
+var g4 = ConstrainedProblem{
+	Name: "g04",
+	Func: func(x []float64) float64 {
+		return 5.3578547*x[2]*x[2] + 0.8356891*x[0]*x[4] + 37.293239*x[0] - 40792.141
+	},
+	Ineq: func(x []float64) []float64 {
+		u := 85.334407 + 0.0056858*x[1]*x[4] + 0.0006262*x[0]*x[3] - 0.0022053*x[2]*x[4]
+		v := 80.51249 + 0.0071317*x[1]*x[4] + 0.0029955*x[0]*x[1] + 0.0021813*x[2]*x[2]
+		w := 9.300961 + 0.0047026*x[2]*x[4] + 0.0012547*x[0]*x[2] + 0.0019085*x[2]*x[3]
+		return []float64{u - 92, -u, v - 110, 90 - v, w - 25, 20 - w}
+	},
+	Lower: []float64{78, 33, 27, 27, 27},
+	Upper: []float64{102, 45, 45, 45, 45},
+	XOpt:  []float64{78, 33, 29.9952560256815985, 45, 36.7758129057882073},
+	FOpt:  -30665.538671783317,
+}
+
+var g5 = ConstrainedProblem{
+	Name: "g05",
+	Func: func(x []float64) float64 {
+		return 3*x[0] + 0.000001*x[0]*x[0]*x[0] + 2*x[1] + (0.000002/3)*x[1]*x[1]*x[1]
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{-x[3] + x[2] - 0.55, -x[2] + x[3] - 0.55}
+	},
+	Eq: func(x []float64) []float64 {
+		return []float64{
+			1000*math.Sin(-x[2]-0.25) + 1000*math.Sin(-x[3]-0.25) + 894.8 - x[0],
+			1000*math.Sin(x[2]-0.25) + 1000*math.Sin(x[2]-x[3]-0.25) + 894.8 - x[1],
+			1000*math.Sin(x[3]-0.25) + 1000*math.Sin(x[3]-x[2]-0.25) + 1294.8,
+		}
+	},
+	Lower: []float64{0, 0, -0.55, -0.55},
+	Upper: []float64{1200, 1200, 0.55, 0.55},
+	XOpt:  []float64{679.945317402028536, 1026.06713522748464, 0.118876366239629466, -0.396233552374223419},
+	FOpt:  5126.49810959527,
+}
+
+var g6 = ConstrainedProblem{
+	Name: "g06",
+	Func: func(x []float64) float64 {
+		return math.Pow(x[0]-10, 3) + math.Pow(x[1]-20, 3)
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{
+			-(x[0]-5)*(x[0]-5) - (x[1]-5)*(x[1]-5) + 100,
+			(x[0]-6)*(x[0]-6) + (x[1]-5)*(x[1]-5) - 82.81,
+		}
+	},
+	Lower: []float64{13, 0},
+	Upper: []float64{100, 100},
+	XOpt:  []float64{14.09500000000000064, 0.8429607892154795668},
+	FOpt:  -6961.8138755801,
+}
+
+var g7 = ConstrainedProblem{
+	Name: "g07",
+	Func: func(x []float64) float64 {
+		sq := func(v float64) float64 { return v * v }
+		return x[0]*x[0] + x[1]*x[1] + x[0]*x[1] - 14*x[0] - 16*x[1] + sq(x[2]-10) + 4*sq(x[3]-5) + sq(x[4]-3) +
+			2*sq(x[5]-1) + 5*x[6]*x[6] + 7*sq(x[7]-11) + 2*sq(x[8]-10) + sq(x[9]-7) + 45
+	},
+	Ineq: func(x []float64) []float64 {
+		sq := func(v float64) float64 { return v * v }
+		return []float64{
+			-105 + 4*x[0] + 5*x[1] - 3*x[6] + 9*x[7],
+			10*x[0] - 8*x[1] - 17*x[6] + 2*x[7],
+			-8*x[0] + 2*x[1] + 5*x[8] - 2*x[9] - 12,
+			3*sq(x[0]-2) + 4*sq(x[1]-3) + 2*x[2]*x[2] - 7*x[3] - 120,
+			5*x[0]*x[0] + 8*x[1] + sq(x[2]-6) - 2*x[3] - 40,
+			x[0]*x[0] + 2*sq(x[1]-2) - 2*x[0]*x[1] + 14*x[4] - 6*x[5],
+			0.5*sq(x[0]-8) + 2*sq(x[1]-4) + 3*x[4]*x[4] - x[5] - 30,
+			-3*x[0] + 6*x[1] + 12*sq(x[8]-8) - 7*x[9],
+		}
+	},
+	Lower: []float64{-10, -10, -10, -10, -10, -10, -10, -10, -10, -10},
+	Upper: []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10},
+	XOpt: []float64{2.17199634142692, 2.3636830416034, 8.77392573913157, 5.09598443745173, 0.990654756560493,
+		1.43057392853463, 1.32164415364306, 9.82872576524495, 8.2800915887356, 8.3759266477347},
+	FOpt: 24.3062090681799,
+}
+
+var g8 = ConstrainedProblem{
+	Name: "g08",
+	Func: func(x []float64) float64 {
+		s := math.Sin(2 * math.Pi * x[0])
+		return -s * s * s * math.Sin(2*math.Pi*x[1]) / (x[0] * x[0] * x[0] * (x[0] + x[1]))
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{x[0]*x[0] - x[1] + 1, 1 - x[0] + (x[1]-4)*(x[1]-4)}
+	},
+	Lower: []float64{0, 0},
+	Upper: []float64{10, 10},
+	XOpt:  []float64{1.22797135260752599, 4.24537336612274885},
+	FOpt:  -0.0958250414180359,
+}
+
+var g9 = ConstrainedProblem{
+	Name: "g09",
+	Func: func(x []float64) float64 {
+		sq := func(v float64) float64 { return v * v }
+		return sq(x[0]-10) + 5*sq(x[1]-12) + math.Pow(x[2], 4) + 3*sq(x[3]-11) + 10*math.Pow(x[4], 6) +
+			7*x[5]*x[5] + math.Pow(x[6], 4) - 4*x[5]*x[6] - 10*x[5] - 8*x[6]
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{
+			-127 + 2*x[0]*x[0] + 3*math.Pow(x[1], 4) + x[2] + 4*x[3]*x[3] + 5*x[4],
+			-282 + 7*x[0] + 3*x[1] + 10*x[2]*x[2] + x[3] - x[4],
+			-196 + 23*x[0] + x[1]*x[1] + 6*x[5]*x[5] - 8*x[6],
+			4*x[0]*x[0] + x[1]*x[1] - 3*x[0]*x[1] + 2*x[2]*x[2] + 5*x[5] - 11*x[6],
+		}
+	},
+	Lower: []float64{-10, -10, -10, -10, -10, -10, -10},
+	Upper: []float64{10, 10, 10, 10, 10, 10, 10},
+	XOpt: []float64{2.33049935147405174, 1.95137236847114592, -0.477541399510615805, 4.36572624923625874,
+		-0.624486959100388983, 1.03813099410962173, 1.5942266780671519},
+	FOpt: 680.630057374402,
+}
+
+var g10 = ConstrainedProblem{
+	Name: "g10",
+	Func: func(x []float64) float64 { return x[0] + x[1] + x[2] },
+	Ineq: func(x []float64) []float64 {
+		return []float64{
+			-1 + 0.0025*(x[3]+x[5]),
+			-1 + 0.0025*(x[4]+x[6]-x[3]),
+			-1 + 0.01*(x[7]-x[4]),
+			-x[0]*x[5] + 833.33252*x[3] + 100*x[0] - 83333.333,
+			-x[1]*x[6] + 1250*x[4] + x[1]*x[3] - 1250*x[3],
+			-x[2]*x[7] + 1250000 + x[2]*x[4] - 2500*x[4],
+		}
+	},
+	Lower: []float64{100, 1000, 1000, 10, 10, 10, 10, 10},
+	Upper: []float64{10000, 10000, 10000, 1000, 1000, 1000, 1000, 1000},
+	XOpt: []float64{579.306685017979589, 1359.97067807935605, 5109.97065743133317, 182.01769963061534,
+		295.601173702746792, 217.982300369384632, 286.41652592786852, 395.601173702746735},
+	FOpt: 7049.24802052867,
+}
+
+var g11 = ConstrainedProblem{
+	Name:  "g11",
+	Func:  func(x []float64) float64 { return x[0]*x[0] + (x[1]-1)*(x[1]-1) },
+	Eq:    func(x []float64) []float64 { return []float64{x[1] - x[0]*x[0]} },
+	Lower: []float64{-1, -1},
+	Upper: []float64{1, 1},
+	XOpt:  []float64{-1 / math.Sqrt2, .5},
+	FOpt:  .75,
+}
+
+// g12 is feasible in the union of 729 spheres of radius 0.25 centered on
+// the points of {1, ..., 9}^3, its constraint being the least of theirs
+var g12 = ConstrainedProblem{
+	Name: "g12",
+	Func: func(x []float64) float64 {
+		return -(100 - (x[0]-5)*(x[0]-5) - (x[1]-5)*(x[1]-5) - (x[2]-5)*(x[2]-5)) / 100
+	},
+	Ineq: func(x []float64) []float64 {
+		c := 0.
+		for i := range x {
+			// the nearest center in {1, ..., 9}
+			d := x[i] - math.Max(1, math.Min(9, math.Round(x[i])))
+			c += d * d
+		}
+		return []float64{c - 0.0625}
+	},
+	Lower: []float64{0, 0, 0},
+	Upper: []float64{10, 10, 10},
+	XOpt:  []float64{5, 5, 5},
+	FOpt:  -1,
+}
+
+var g13 = ConstrainedProblem{
+	Name: "g13",
+	Func: func(x []float64) float64 { return math.Exp(x[0] * x[1] * x[2] * x[3] * x[4]) },
+	Eq: func(x []float64) []float64 {
+		s := -10.
+		for _, xi := range x {
+			s += xi * xi
+		}
+		return []float64{s, x[1]*x[2] - 5*x[3]*x[4], x[0]*x[0]*x[0] + x[1]*x[1]*x[1] + 1}
+	},
+	Lower: []float64{-2.3, -2.3, -3.2, -3.2, -3.2},
+	Upper: []float64{2.3, 2.3, 3.2, 3.2, 3.2},
+	XOpt:  []float64{-1.7171435735388545, 1.59570969382482719, 1.82724574708452958, -0.763643079818479897, -0.763643075850580666},
+	FOpt:  0.053949847770272,
+}
+
+// pressureVessel is the design of a cylindrical vessel of shell and head
+// thicknesses x0, x1, inner radius x2 and length x3 of least cost, the
+// thicknesses being continuous
+var pressureVessel = ConstrainedProblem{
+	Name: "pressurevessel",
+	Func: func(x []float64) float64 {
+		return 0.6224*x[0]*x[2]*x[3] + 1.7781*x[1]*x[2]*x[2] + 3.1661*x[0]*x[0]*x[3] + 19.84*x[0]*x[0]*x[2]
+	},
+	Ineq: func(x []float64) []float64 {
+		return []float64{
+			-x[0] + 0.0193*x[2],
+			-x[1] + 0.00954*x[2],
+			-math.Pi*x[2]*x[2]*x[3] - 4*math.Pi*x[2]*x[2]*x[2]/3 + 1296000,
+			x[3] - 240,
+		}
+	},
+	Lower: []float64{0, 0, 10, 10},
+	Upper: []float64{99, 99, 200, 200},
+	XOpt:  []float64{0.77816864137345032, 0.384649162629276065, 40.3196187240987243, 200},
+	FOpt:  5885.33277360843,
+}
+
+// weldedBeam is the design of a welded cantilever beam of weld thickness
+// x0 and length x1, bar height x2 and thickness x3 of least cost under
+// shear stress, bending stress, buckling and deflection constraints
+var weldedBeam = ConstrainedProblem{
+	Name: "weldedbeam",
+	Func: func(x []float64) float64 {
+		return 1.10471*x[0]*x[0]*x[1] + 0.04811*x[2]*x[3]*(14+x[1])
+	},
+	Ineq: func(x []float64) []float64 {
+		const p, l, e, g = 6000., 14., 30e6, 12e6
+		tau1 := p / (math.Sqrt2 * x[0] * x[1])
+		m := p * (l + x[1]/2)
+		r := math.Sqrt(x[1]*x[1]/4 + (x[0]+x[2])*(x[0]+x[2])/4)
+		j := 2 * math.Sqrt2 * x[0] * x[1] * (x[1]*x[1]/12 + (x[0]+x[2])*(x[0]+x[2])/4)
+		tau2 := m * r / j
+		tau := math.Sqrt(tau1*tau1 + tau1*tau2*x[1]/r + tau2*tau2)
+		sigma := 6 * p * l / (x[3] * x[2] * x[2])
+		delta := 4 * p * l * l * l / (e * x[2] * x[2] * x[2] * x[3])
+		pc := 4.013 * e * math.Sqrt(x[2]*x[2]*math.Pow(x[3], 6)/36) / (l * l) * (1 - x[2]/(2*l)*math.Sqrt(e/(4*g)))
+		return []float64{
+			tau - 13600,
+			sigma - 30000,
+			x[0] - x[3],
+			0.10471*x[0]*x[0] + 0.04811*x[2]*x[3]*(14+x[1]) - 5,
+			0.125 - x[0],
+			delta - 0.25,
+			p - pc,
+		}
+	},
+	Lower: []float64{0.1, 0.1, 0.1, 0.1},
+	Upper: []float64{2, 10, 10, 2},
+	XOpt:  []float64{0.205729639763434141, 3.47048867311725617, 9.0366239103678101, 0.205729639786029816},
+	FOpt:  1.72485230958305,
+}
+
+// tensionSpring is the design of a tension spring of wire diameter x0,
+// mean coil diameter x1 and number of active coils x2 of least weight
+var tensionSpring = ConstrainedProblem{
+	Name: "tensionspring",
+	Func: func(x []float64) float64 { return (x[2] + 2) * x[1] * x[0] * x[0] },
+	Ineq: func(x []float64) []float64 {
+		d, dm, n := x[0], x[1], x[2]
+		return []float64{
+			1 - dm*dm*dm*n/(71785*d*d*d*d),
+			(4*dm*dm-d*dm)/(12566*(dm*d*d*d-d*d*d*d)) + 1/(5108*d*d) - 1,
+			1 - 140.45*d/(dm*dm*n),
+			(d+dm)/1.5 - 1,
+		}
+	},
+	Lower: []float64{0.05, 0.25, 2},
+	Upper: []float64{2, 1.3, 15},
+	XOpt:  []float64{0.0516903656127493394, 0.356749124171382104, 11.2871260207735471},
+	FOpt:  0.0126652328193526,
+}
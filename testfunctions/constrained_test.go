@@ -0,0 +1,76 @@
+package testfunctions
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/optimize"
+)
+
+func ExampleConstrainedProblem() {
+	// SQP from the center of the bounds, which stops at a local minimum
+	// of g01
+	for _, name := range []string{"g01", "g04", "g07", "g09", "pressurevessel"} {
+		p, _ := ConstrainedByName(name)
+		x0 := make([]float64, p.Dim())
+		for i := range x0 {
+			x0[i] = (p.Lower[i] + p.Upper[i]) / 2
+		}
+		s := optimize.NewSQP()
+		s.Ineq, s.Eq, s.Xmin, s.Xmax = p.Ineq, p.Eq, p.Lower, p.Upper
+		res := s.Minimize(p.Func, x0)
+		fmt.Printf("%s: f %.6g f* %.6g feasible %v\n", p.Name, res.F, p.FOpt, p.Violation(res.X) < 1e-6)
+	}
+	// Output:
+	// g01: f -11.25 f* -15 feasible true
+	// g04: f -30665.5 f* -30665.5 feasible true
+	// g07: f 24.3062 f* 24.3062 feasible true
+	// g09: f 680.63 f* 680.63 feasible true
+	// pressurevessel: f 5885.33 f* 5885.33 feasible true
+}
+
+func TestConstrained(t *testing.T) {
+	names := map[string]bool{}
+	for _, p := range Constrained() {
+		if names[p.Name] {
+			t.Errorf("%s is duplicated", p.Name)
+		}
+		names[p.Name] = true
+		if got, ok := ConstrainedByName(p.Name); !ok || got.Name != p.Name {
+			t.Errorf("ConstrainedByName(%s) = %v, %v", p.Name, got.Name, ok)
+		}
+		n := p.Dim()
+		if len(p.Upper) != n || len(p.XOpt) != n || p.Ineq == nil && p.Eq == nil {
+			t.Errorf("%s: dimensions %d %d %d", p.Name, n, len(p.Upper), len(p.XOpt))
+			continue
+		}
+		for i, xi := range p.XOpt {
+			if xi < p.Lower[i] || xi > p.Upper[i] {
+				t.Errorf("%s: the solution %g is out of bounds", p.Name, p.XOpt)
+			}
+		}
+		if v := p.Violation(p.XOpt); v > 1e-8 {
+			t.Errorf("%s: the solution violates the constraints by %g", p.Name, v)
+		}
+		if f := p.Func(p.XOpt); math.Abs(f-p.FOpt) > 1e-9*math.Max(1, math.Abs(p.FOpt)) {
+			t.Errorf("%s: f* %.15g, want %.15g", p.Name, f, p.FOpt)
+		}
+	}
+	// through the options of the registered solvers
+	g4, _ := ConstrainedByName("g04")
+	m, err := optimize.NewAlgorithm("LD_SQP", optimize.WithConstraints(g4.Ineq, g4.Eq), optimize.WithBounds(g4.Lower, g4.Upper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := m.Minimize(g4.Func, []float64{90, 39, 36, 36, 36}); math.Abs(res.F-g4.FOpt) > 1e-3 || g4.Violation(res.X) > 1e-6 {
+		t.Errorf("g04: f %g violation %g, want %g", res.F, g4.Violation(res.X), g4.FOpt)
+	}
+	if _, ok := ConstrainedByName("g02"); ok {
+		t.Error("ConstrainedByName(g02) found a problem")
+	}
+	g11, _ := ConstrainedByName("g11")
+	if v := g11.Violation([]float64{0, 1}); v != 1 {
+		t.Errorf("violation %g, want 1", v)
+	}
+}
@@ -0,0 +1,488 @@
+// Package testfunctions is a suite of benchmark functions of global and
+// local optimization, with their usual search domains and their known
+// global minima, for the comparison of methods and for examples.
+//
+// The definitions are those of the Virtual Library of Simulation
+// Experiments of Surjanovic and Bingham.
+package testfunctions
+
+import (
+	"math"
+	"sort"
+)
+
+// Function is a benchmark function
+type Function struct {
+	Name string
+	Func func(x []float64) float64
+	// Grad, if not nil, stores in grad the gradient of Func at x
+	Grad func(grad, x []float64)
+	// Dim is the dimension, 0 for a function of any dimension, at least
+	// MinDim
+	Dim, MinDim int
+	// Lower and Upper are the bounds of the usual search domain, of length
+	// Dim, or of length 1 for the bounds of all components of a function of
+	// any dimension
+	Lower, Upper []float64
+	// XOpt returns a global minimizer in dimension dim
+	XOpt func(dim int) []float64
+	// Multimodal tells whether the function has local minima which aren't
+	// global
+	Multimodal bool
+}
+
+// Bounds returns the bounds of the search domain in dimension dim
+func (fn *Function) Bounds(dim int) (xmin, xmax []float64) {
+	if len(fn.Lower) != 1 {
+		return append([]float64(nil), fn.Lower...), append([]float64(nil), fn.Upper...)
+	}
+	return fill(dim, fn.Lower[0]), fill(dim, fn.Upper[0])
+}
+
+// Minimum returns a global minimizer in dimension dim and its value
+func (fn *Function) Minimum(dim int) (x []float64, f float64) {
+	x = fn.XOpt(dim)
+	return x, fn.Func(x)
+}
+
+// ValidDim tells whether dim is a dimension of the function
+func (fn *Function) ValidDim(dim int) bool {
+	if fn.Dim != 0 {
+		return dim == fn.Dim
+	}
+	return dim >= fn.MinDim
+}
+
+// All returns the functions of the suite, sorted by name
+func All() []Function {
+	all := []Function{
+		Ackley, Beale, Booth, Branin, DixonPrice, Easom, GoldsteinPrice,
+		Griewank, Himmelblau, Levy, Rastrigin, Rosenbrock, Schwefel,
+		SixHumpCamel, Sphere, StyblinskiTang, Zakharov,
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// ByName returns the function of the suite named name
+func ByName(name string) (Function, bool) {
+	for _, fn := range All() {
+		if fn.Name == name {
+			return fn, true
+		}
+	}
+	return Function{}, false
+}
+
+func fill(dim int, v float64) []float64 {
+	x := make([]float64, dim)
+	for i := range x {
+		x[i] = v
+	}
+	return x
+}
+
+// constant returns the XOpt of a minimizer of equal components v
+func constant(v float64) func(int) []float64 {
+	return func(dim int) []float64 { return fill(dim, v) }
+}
+
+// point returns the XOpt of a function of fixed dimension
+func point(x ...float64) func(int) []float64 {
+	return func(int) []float64 { return append([]float64(nil), x...) }
+}
+
+var (
+	// Sphere is the sum of squares
+	Sphere = Function{
+		Name: "sphere",
+		Func: func(x []float64) float64 {
+			s := 0.
+			for _, xi := range x {
+				s += xi * xi
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			for i, xi := range x {
+				grad[i] = 2 * xi
+			}
+		},
+		MinDim: 1, Lower: []float64{-5.12}, Upper: []float64{5.12},
+		XOpt: constant(0),
+	}
+
+	// Rosenbrock is the extended Rosenbrock function of the coupled
+	// components, a curved valley
+	Rosenbrock = Function{
+		Name: "rosenbrock",
+		Func: func(x []float64) float64 {
+			s := 0.
+			for i := 0; i+1 < len(x); i++ {
+				a, b := x[i+1]-x[i]*x[i], 1-x[i]
+				s += 100*a*a + b*b
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			for i := range grad {
+				grad[i] = 0
+			}
+			for i := 0; i+1 < len(x); i++ {
+				a := x[i+1] - x[i]*x[i]
+				grad[i] += -400*a*x[i] - 2*(1-x[i])
+				grad[i+1] += 200 * a
+			}
+		},
+		MinDim: 2, Lower: []float64{-5}, Upper: []float64{10},
+		XOpt: constant(1),
+	}
+
+	// Rastrigin is a sphere modulated by cosines, with a regular grid of
+	// local minima
+	Rastrigin = Function{
+		Name: "rastrigin",
+		Func: func(x []float64) float64 {
+			s := 10 * float64(len(x))
+			for _, xi := range x {
+				s += xi*xi - 10*math.Cos(2*math.Pi*xi)
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			for i, xi := range x {
+				grad[i] = 2*xi + 20*math.Pi*math.Sin(2*math.Pi*xi)
+			}
+		},
+		MinDim: 1, Lower: []float64{-5.12}, Upper: []float64{5.12},
+		XOpt: constant(0), Multimodal: true,
+	}
+
+	// Ackley is a nearly flat outer region of local minima around a deep
+	// hole. Its gradient is undefined at the minimizer, where Grad stores 0.
+	Ackley = Function{
+		Name: "ackley",
+		Func: func(x []float64) float64 {
+			n := float64(len(x))
+			s1, s2 := 0., 0.
+			for _, xi := range x {
+				s1 += xi * xi
+				s2 += math.Cos(2 * math.Pi * xi)
+			}
+			return -20*math.Exp(-.2*math.Sqrt(s1/n)) - math.Exp(s2/n) + 20 + math.E
+		},
+		Grad: func(grad, x []float64) {
+			n := float64(len(x))
+			s1, s2 := 0., 0.
+			for _, xi := range x {
+				s1 += xi * xi
+				s2 += math.Cos(2 * math.Pi * xi)
+			}
+			r := math.Sqrt(s1 / n)
+			e1, e2 := 0., math.Exp(s2/n)
+			if r > 0 {
+				e1 = 4 * math.Exp(-.2*r) / (n * r)
+			}
+			for i, xi := range x {
+				grad[i] = e1*xi + e2*2*math.Pi*math.Sin(2*math.Pi*xi)/n
+			}
+		},
+		MinDim: 1, Lower: []float64{-32.768}, Upper: []float64{32.768},
+		XOpt: constant(0), Multimodal: true,
+	}
+
+	// Schwefel has its global minimum near a corner of the domain, far from
+	// the second best local minima. Its value at XOpt is 1.3e-5 per
+	// component, the minimizer being known to 7 digits.
+	Schwefel = Function{
+		Name: "schwefel",
+		Func: func(x []float64) float64 {
+			s := 418.9829 * float64(len(x))
+			for _, xi := range x {
+				s -= xi * math.Sin(math.Sqrt(math.Abs(xi)))
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			for i, xi := range x {
+				r := math.Sqrt(math.Abs(xi))
+				grad[i] = -math.Sin(r) - r*math.Cos(r)/2
+			}
+		},
+		MinDim: 1, Lower: []float64{-500}, Upper: []float64{500},
+		XOpt: constant(420.9687), Multimodal: true,
+	}
+
+	// Griewank is a wide quadratic bowl modulated by a product of cosines
+	Griewank = Function{
+		Name: "griewank",
+		Func: func(x []float64) float64 {
+			s, p := 0., 1.
+			for i, xi := range x {
+				s += xi * xi / 4000
+				p *= math.Cos(xi / math.Sqrt(float64(i+1)))
+			}
+			return s - p + 1
+		},
+		Grad: func(grad, x []float64) {
+			for i, xi := range x {
+				p := math.Sin(xi/math.Sqrt(float64(i+1))) / math.Sqrt(float64(i+1))
+				for j, xj := range x {
+					if j != i {
+						p *= math.Cos(xj / math.Sqrt(float64(j+1)))
+					}
+				}
+				grad[i] = xi/2000 + p
+			}
+		},
+		MinDim: 1, Lower: []float64{-600}, Upper: []float64{600},
+		XOpt: constant(0), Multimodal: true,
+	}
+
+	// Levy is a function of many local minima on sinusoidal ridges
+	Levy = Function{
+		Name: "levy",
+		Func: func(x []float64) float64 {
+			w := func(xi float64) float64 { return 1 + (xi-1)/4 }
+			n := len(x)
+			s1 := math.Sin(math.Pi * w(x[0]))
+			wn := w(x[n-1])
+			s2 := math.Sin(2 * math.Pi * wn)
+			s := s1*s1 + (wn-1)*(wn-1)*(1+s2*s2)
+			for _, xi := range x[:n-1] {
+				wi := w(xi)
+				si := math.Sin(math.Pi*wi + 1)
+				s += (wi - 1) * (wi - 1) * (1 + 10*si*si)
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			w := func(xi float64) float64 { return 1 + (xi-1)/4 }
+			n := len(x)
+			for i := range grad {
+				grad[i] = 0
+			}
+			grad[0] = math.Pi * math.Sin(2*math.Pi*w(x[0])) / 4
+			for i, xi := range x[:n-1] {
+				wi := w(xi)
+				si := math.Sin(math.Pi*wi + 1)
+				grad[i] += (2*(wi-1)*(1+10*si*si) + 10*math.Pi*(wi-1)*(wi-1)*math.Sin(2*(math.Pi*wi+1))) / 4
+			}
+			wn := w(x[n-1])
+			sn := math.Sin(2 * math.Pi * wn)
+			grad[n-1] += (2*(wn-1)*(1+sn*sn) + 2*math.Pi*(wn-1)*(wn-1)*math.Sin(4*math.Pi*wn)) / 4
+		},
+		MinDim: 1, Lower: []float64{-10}, Upper: []float64{10},
+		XOpt: constant(1), Multimodal: true,
+	}
+
+	// Zakharov is a unimodal plate-shaped function
+	Zakharov = Function{
+		Name: "zakharov",
+		Func: func(x []float64) float64 {
+			s1, s2 := 0., 0.
+			for i, xi := range x {
+				s1 += xi * xi
+				s2 += .5 * float64(i+1) * xi
+			}
+			return s1 + s2*s2 + s2*s2*s2*s2
+		},
+		Grad: func(grad, x []float64) {
+			s2 := 0.
+			for i, xi := range x {
+				s2 += .5 * float64(i+1) * xi
+			}
+			for i, xi := range x {
+				grad[i] = 2*xi + (2*s2+4*s2*s2*s2)*.5*float64(i+1)
+			}
+		},
+		MinDim: 1, Lower: []float64{-5}, Upper: []float64{10},
+		XOpt: constant(0),
+	}
+
+	// DixonPrice is a valley of minimizer x_i = 2^-(1-2^-(i-1))
+	DixonPrice = Function{
+		Name: "dixonprice",
+		Func: func(x []float64) float64 {
+			s := (x[0] - 1) * (x[0] - 1)
+			for i := 1; i < len(x); i++ {
+				a := 2*x[i]*x[i] - x[i-1]
+				s += float64(i+1) * a * a
+			}
+			return s
+		},
+		Grad: func(grad, x []float64) {
+			for i := range grad {
+				grad[i] = 0
+			}
+			grad[0] = 2 * (x[0] - 1)
+			for i := 1; i < len(x); i++ {
+				a := 2*x[i]*x[i] - x[i-1]
+				grad[i] += 8 * float64(i+1) * a * x[i]
+				grad[i-1] -= 2 * float64(i+1) * a
+			}
+		},
+		MinDim: 1, Lower: []float64{-10}, Upper: []float64{10},
+		XOpt: func(dim int) []float64 {
+			x := make([]float64, dim)
+			for i := range x {
+				x[i] = math.Pow(2, -(1 - math.Pow(2, -float64(i))))
+			}
+			return x
+		},
+	}
+
+	// StyblinskiTang is a separable function of 2^d local minima. Its
+	// value at XOpt is -39.16617 per component.
+	StyblinskiTang = Function{
+		Name: "styblinskitang",
+		Func: func(x []float64) float64 {
+			s := 0.
+			for _, xi := range x {
+				s += xi*xi*xi*xi - 16*xi*xi + 5*xi
+			}
+			return s / 2
+		},
+		Grad: func(grad, x []float64) {
+			for i, xi := range x {
+				grad[i] = (4*xi*xi*xi - 32*xi + 5) / 2
+			}
+		},
+		MinDim: 1, Lower: []float64{-5}, Upper: []float64{5},
+		XOpt: constant(-2.9035340276126953), Multimodal: true,
+	}
+
+	// Beale is a 2-dimensional function of sharp ridges at the corners
+	Beale = Function{
+		Name: "beale",
+		Func: func(x []float64) float64 {
+			a := 1.5 - x[0] + x[0]*x[1]
+			b := 2.25 - x[0] + x[0]*x[1]*x[1]
+			c := 2.625 - x[0] + x[0]*x[1]*x[1]*x[1]
+			return a*a + b*b + c*c
+		},
+		Grad: func(grad, x []float64) {
+			y := x[1]
+			a := 1.5 - x[0] + x[0]*y
+			b := 2.25 - x[0] + x[0]*y*y
+			c := 2.625 - x[0] + x[0]*y*y*y
+			grad[0] = 2*a*(y-1) + 2*b*(y*y-1) + 2*c*(y*y*y-1)
+			grad[1] = 2*a*x[0] + 4*b*x[0]*y + 6*c*x[0]*y*y
+		},
+		Dim: 2, Lower: []float64{-4.5, -4.5}, Upper: []float64{4.5, 4.5},
+		XOpt: point(3, .5),
+	}
+
+	// Booth is a 2-dimensional quadratic
+	Booth = Function{
+		Name: "booth",
+		Func: func(x []float64) float64 {
+			a, b := x[0]+2*x[1]-7, 2*x[0]+x[1]-5
+			return a*a + b*b
+		},
+		Grad: func(grad, x []float64) {
+			a, b := x[0]+2*x[1]-7, 2*x[0]+x[1]-5
+			grad[0], grad[1] = 2*a+4*b, 4*a+2*b
+		},
+		Dim: 2, Lower: []float64{-10, -10}, Upper: []float64{10, 10},
+		XOpt: point(1, 3),
+	}
+
+	// Branin is a 2-dimensional function of 3 global minima, of value
+	// 0.397887
+	Branin = Function{
+		Name: "branin",
+		Func: func(x []float64) float64 {
+			b, c, t := 5.1/(4*math.Pi*math.Pi), 5/math.Pi, 1/(8*math.Pi)
+			a := x[1] - b*x[0]*x[0] + c*x[0] - 6
+			return a*a + 10*(1-t)*math.Cos(x[0]) + 10
+		},
+		Grad: func(grad, x []float64) {
+			b, c, t := 5.1/(4*math.Pi*math.Pi), 5/math.Pi, 1/(8*math.Pi)
+			a := x[1] - b*x[0]*x[0] + c*x[0] - 6
+			grad[0] = 2*a*(c-2*b*x[0]) - 10*(1-t)*math.Sin(x[0])
+			grad[1] = 2 * a
+		},
+		Dim: 2, Lower: []float64{-5, 0}, Upper: []float64{10, 15},
+		XOpt: point(math.Pi, 2.275), Multimodal: true,
+	}
+
+	// GoldsteinPrice is a 2-dimensional polynomial of several local minima,
+	// of global minimum 3
+	GoldsteinPrice = Function{
+		Name: "goldsteinprice",
+		Func: func(x []float64) float64 {
+			u, v := x[0], x[1]
+			a := u + v + 1
+			b := 19 - 14*u + 3*u*u - 14*v + 6*u*v + 3*v*v
+			c := 2*u - 3*v
+			d := 18 - 32*u + 12*u*u + 48*v - 36*u*v + 27*v*v
+			return (1 + a*a*b) * (30 + c*c*d)
+		},
+		Grad: func(grad, x []float64) {
+			u, v := x[0], x[1]
+			a := u + v + 1
+			b := 19 - 14*u + 3*u*u - 14*v + 6*u*v + 3*v*v
+			c := 2*u - 3*v
+			d := 18 - 32*u + 12*u*u + 48*v - 36*u*v + 27*v*v
+			p, q := 1+a*a*b, 30+c*c*d
+			pu := 2*a*b + a*a*(-14+6*u+6*v)
+			pv := pu
+			qu := 4*c*d + c*c*(-32+24*u-36*v)
+			qv := -6*c*d + c*c*(48-36*u+54*v)
+			grad[0], grad[1] = pu*q+p*qu, pv*q+p*qv
+		},
+		Dim: 2, Lower: []float64{-2, -2}, Upper: []float64{2, 2},
+		XOpt: point(0, -1), Multimodal: true,
+	}
+
+	// SixHumpCamel is a 2-dimensional function of 6 local minima, 2 of
+	// them global, of value -1.0316285
+	SixHumpCamel = Function{
+		Name: "sixhumpcamel",
+		Func: func(x []float64) float64 {
+			u, v := x[0], x[1]
+			return (4-2.1*u*u+u*u*u*u/3)*u*u + u*v + (-4+4*v*v)*v*v
+		},
+		Grad: func(grad, x []float64) {
+			u, v := x[0], x[1]
+			grad[0] = 8*u - 8.4*u*u*u + 2*u*u*u*u*u + v
+			grad[1] = u - 8*v + 16*v*v*v
+		},
+		Dim: 2, Lower: []float64{-3, -2}, Upper: []float64{3, 2},
+		XOpt: point(0.08984201368301331, -0.7126564032704135), Multimodal: true,
+	}
+
+	// Easom is a 2-dimensional flat function of a narrow hole, of value -1
+	Easom = Function{
+		Name: "easom",
+		Func: func(x []float64) float64 {
+			a, b := x[0]-math.Pi, x[1]-math.Pi
+			return -math.Cos(x[0]) * math.Cos(x[1]) * math.Exp(-a*a-b*b)
+		},
+		Grad: func(grad, x []float64) {
+			a, b := x[0]-math.Pi, x[1]-math.Pi
+			e := math.Exp(-a*a - b*b)
+			c0, c1 := math.Cos(x[0]), math.Cos(x[1])
+			grad[0] = e * c1 * (math.Sin(x[0]) + 2*a*c0)
+			grad[1] = e * c0 * (math.Sin(x[1]) + 2*b*c1)
+		},
+		Dim: 2, Lower: []float64{-100, -100}, Upper: []float64{100, 100},
+		XOpt: point(math.Pi, math.Pi), Multimodal: true,
+	}
+
+	// Himmelblau is a 2-dimensional function of 4 global minima, of value 0
+	Himmelblau = Function{
+		Name: "himmelblau",
+		Func: func(x []float64) float64 {
+			a, b := x[0]*x[0]+x[1]-11, x[0]+x[1]*x[1]-7
+			return a*a + b*b
+		},
+		Grad: func(grad, x []float64) {
+			a, b := x[0]*x[0]+x[1]-11, x[0]+x[1]*x[1]-7
+			grad[0], grad[1] = 4*a*x[0]+2*b, 2*a+4*b*x[1]
+		},
+		Dim: 2, Lower: []float64{-5, -5}, Upper: []float64{5, 5},
+		XOpt: point(3, 2), Multimodal: true,
+	}
+)
@@ -0,0 +1,144 @@
+package testfunctions
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/diff/fd"
+
+	"github.com/pa-m/optimize"
+)
+
+func ExampleAll() {
+	for _, fn := range All() {
+		dim := fn.Dim
+		if dim == 0 {
+			dim = 4
+		}
+		xmin, xmax := fn.Bounds(dim)
+		_, f := fn.Minimum(dim)
+		fmt.Printf("%-14s dim %d [%g, %g] f* %.5f multimodal %v\n", fn.Name, dim, xmin[0], xmax[0], f, fn.Multimodal)
+	}
+	// Output:
+	// ackley         dim 4 [-32.768, 32.768] f* 0.00000 multimodal true
+	// beale          dim 2 [-4.5, 4.5] f* 0.00000 multimodal false
+	// booth          dim 2 [-10, 10] f* 0.00000 multimodal false
+	// branin         dim 2 [-5, 10] f* 0.39789 multimodal true
+	// dixonprice     dim 4 [-10, 10] f* 0.00000 multimodal false
+	// easom          dim 2 [-100, 100] f* -1.00000 multimodal true
+	// goldsteinprice dim 2 [-2, 2] f* 3.00000 multimodal true
+	// griewank       dim 4 [-600, 600] f* 0.00000 multimodal true
+	// himmelblau     dim 2 [-5, 5] f* 0.00000 multimodal true
+	// levy           dim 4 [-10, 10] f* 0.00000 multimodal true
+	// rastrigin      dim 4 [-5.12, 5.12] f* 0.00000 multimodal true
+	// rosenbrock     dim 4 [-5, 10] f* 0.00000 multimodal false
+	// schwefel       dim 4 [-500, 500] f* 0.00005 multimodal true
+	// sixhumpcamel   dim 2 [-3, 3] f* -1.03163 multimodal true
+	// sphere         dim 4 [-5.12, 5.12] f* 0.00000 multimodal false
+	// styblinskitang dim 4 [-5, 5] f* -156.66466 multimodal true
+	// zakharov       dim 4 [-5, 10] f* 0.00000 multimodal false
+}
+
+func ExampleFunction() {
+	// Powell's method finds the minimum of the Rosenbrock function, but a
+	// local minimum of the Rastrigin one
+	for _, fn := range []Function{Rosenbrock, Rastrigin} {
+		x0 := []float64{-1.2, 1}
+		res := optimize.NewPowellMinimizer().Minimize(fn.Func, x0)
+		xopt, fopt := fn.Minimum(2)
+		dist := 0.
+		for i := range xopt {
+			dist = math.Max(dist, math.Abs(res.X[i]-xopt[i]))
+		}
+		fmt.Printf("%s: f %.4f f* %g distance %.4f\n", fn.Name, res.F, fopt, dist)
+	}
+	// Output:
+	// rosenbrock: f 0.0000 f* 0 distance 0.0000
+	// rastrigin: f 0.9950 f* 0 distance 0.9950
+}
+
+func TestFunctions(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// the known values of the global minima
+	minima := map[string]float64{
+		"branin":         0.397887,
+		"goldsteinprice": 3,
+		"sixhumpcamel":   -1.0316285,
+		"easom":          -1,
+		"schwefel":       1.3e-5,
+		"styblinskitang": -39.16617,
+	}
+	names := map[string]bool{}
+	for _, fn := range All() {
+		if names[fn.Name] {
+			t.Errorf("%s is duplicated", fn.Name)
+		}
+		names[fn.Name] = true
+		if got, ok := ByName(fn.Name); !ok || got.Name != fn.Name {
+			t.Errorf("ByName(%s) = %v, %v", fn.Name, got.Name, ok)
+		}
+		dims := []int{fn.Dim}
+		if fn.Dim == 0 {
+			dims = []int{fn.MinDim, 2, 5}
+		}
+		for _, dim := range dims {
+			if !fn.ValidDim(dim) || fn.ValidDim(dim+1) == (fn.Dim != 0) {
+				t.Errorf("%s: ValidDim(%d)", fn.Name, dim)
+			}
+			name := fmt.Sprintf("%s/%d", fn.Name, dim)
+			xmin, xmax := fn.Bounds(dim)
+			xopt, fopt := fn.Minimum(dim)
+			if len(xmin) != dim || len(xmax) != dim || len(xopt) != dim {
+				t.Fatalf("%s: lengths %d %d %d", name, len(xmin), len(xmax), len(xopt))
+			}
+			want, ok := minima[fn.Name]
+			if fn.Dim == 0 {
+				want *= float64(dim)
+			}
+			if math.Abs(fopt-want) > 1e-5*math.Max(1, math.Abs(want)) || (!ok && math.Abs(fopt) > 1e-12) {
+				t.Errorf("%s: f* %g, want %g", name, fopt, want)
+			}
+			for i := range xopt {
+				if xopt[i] < xmin[i] || xopt[i] > xmax[i] {
+					t.Errorf("%s: the minimizer %g is out of bounds", name, xopt)
+				}
+			}
+			// the minimizer is below the random points of the domain and
+			// stationary
+			x, g := make([]float64, dim), make([]float64, dim)
+			for k := 0; k < 1000; k++ {
+				for i := range x {
+					x[i] = xmin[i] + rnd.Float64()*(xmax[i]-xmin[i])
+				}
+				if f := fn.Func(x); f < fopt {
+					t.Errorf("%s: f(%g) = %g below f* %g", name, x, f, fopt)
+				}
+				// the gradient matches central differences
+				if k < 20 && fn.Grad != nil {
+					fn.Grad(g, x)
+					want := fd.Gradient(nil, fn.Func, x, &fd.Settings{Formula: fd.Central, Step: 1e-6})
+					for i := range g {
+						if math.Abs(g[i]-want[i]) > 1e-5*math.Max(1, math.Abs(want[i])) {
+							t.Errorf("%s: gradient at %g: %g, want %g", name, x, g, want)
+							break
+						}
+					}
+				}
+			}
+			if fn.Grad != nil {
+				fn.Grad(g, xopt)
+				for i := range g {
+					if math.Abs(g[i]) > 1e-4 {
+						t.Errorf("%s: gradient at the minimizer %g", name, g)
+						break
+					}
+				}
+			}
+		}
+	}
+	if _, ok := ByName("unknown"); ok {
+		t.Error("ByName(unknown) found a function")
+	}
+}
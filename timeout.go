@@ -0,0 +1,98 @@
+package optimize
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// TimeoutFunc bounds the wall time of the evaluations of a function, eg a
+// simulator which sometimes hangs. An evaluation exceeding Timeout fails:
+// its value is Fallback, and the solver continues, like after a panic
+// recovered by an Evaluator. The methods of the package never accept a NaN
+// value as an improvement, a large Fallback is a penalty. Func can't be interrupted: its call goes on
+// in its goroutine and its value is discarded, so Func must be safe for
+// concurrent use. FuncContext is cancelled instead. Panics of the
+// evaluations are raised again by Eval. It is safe for concurrent use.
+type TimeoutFunc struct {
+	Func func([]float64) float64
+	// FuncContext, if not nil, is called instead of Func with a context
+	// cancelled at the timeout, eg to kill a process of exec.CommandContext
+	FuncContext func(ctx context.Context, x []float64) float64
+	Timeout     time.Duration
+	// Fallback is the value of the evaluations which timed out, NaN with
+	// NewTimeoutFunc
+	Fallback float64
+	// OnTimeout, if not nil, is called with the points whose evaluation
+	// timed out
+	OnTimeout func(x []float64)
+
+	mu       sync.Mutex
+	timeouts int
+}
+
+// NewTimeoutFunc returns a TimeoutFunc of f with timeout d and a NaN Fallback
+func NewTimeoutFunc(f func([]float64) float64, d time.Duration) *TimeoutFunc {
+	return &TimeoutFunc{Func: f, Timeout: d, Fallback: math.NaN()}
+}
+
+// Eval returns the value of x, Fallback if its evaluation times out. A
+// non-positive Timeout doesn't bound the evaluation.
+func (tf *TimeoutFunc) Eval(x []float64) float64 {
+	ctx := context.Background()
+	if tf.Timeout <= 0 {
+		if tf.FuncContext != nil {
+			return tf.FuncContext(ctx, x)
+		}
+		return tf.Func(x)
+	}
+	ctx, cancel := context.WithTimeout(ctx, tf.Timeout)
+	defer cancel()
+	// the evaluation may go on after the timeout: it gets its own copy of x
+	xc := append([]float64(nil), x...)
+	type value struct {
+		y        float64
+		panicked bool
+		p        interface{}
+	}
+	done := make(chan value, 1)
+	go func() {
+		v := value{panicked: true}
+		defer func() {
+			if v.panicked {
+				v.p = recover()
+			}
+			done <- v
+		}()
+		if tf.FuncContext != nil {
+			v.y = tf.FuncContext(ctx, xc)
+		} else {
+			v.y = tf.Func(xc)
+		}
+		v.panicked = false
+	}()
+	select {
+	case v := <-done:
+		if v.panicked {
+			// in the calling goroutine, eg for Evaluator.Recover
+			panic(v.p)
+		}
+		return v.y
+	case <-ctx.Done():
+		tf.mu.Lock()
+		tf.timeouts++
+		tf.mu.Unlock()
+		if tf.OnTimeout != nil {
+			tf.OnTimeout(x)
+		}
+		return tf.Fallback
+	}
+}
+
+// Timeouts returns the number of evaluations which timed out
+func (tf *TimeoutFunc) Timeouts() int {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	return tf.timeouts
+}
@@ -0,0 +1,68 @@
+package optimize
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleTimeoutFunc() {
+	// a simulator hanging for x[0] > 2
+	simulator := func(x []float64) float64 {
+		if x[0] > 2 {
+			time.Sleep(time.Second)
+		}
+		return (x[0] - 1) * (x[0] - 1)
+	}
+	tf := NewTimeoutFunc(simulator, 10*time.Millisecond)
+	fmt.Println(tf.Eval([]float64{0}), tf.Eval([]float64{3}), tf.Timeouts())
+	// Output:
+	// 1 NaN 1
+}
+
+func TestTimeoutFunc(t *testing.T) {
+	// the evaluations of FuncContext are cancelled
+	cancelled := make(chan bool, 1)
+	tf := &TimeoutFunc{FuncContext: func(ctx context.Context, x []float64) float64 {
+		<-ctx.Done()
+		cancelled <- true
+		return 0
+	}, Timeout: time.Millisecond, Fallback: 1e10}
+	var timedOut []float64
+	tf.OnTimeout = func(x []float64) { timedOut = x }
+	if y := tf.Eval([]float64{1, 2}); y != 1e10 || len(timedOut) != 2 {
+		t.Errorf("expected the Fallback, got %g", y)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the evaluation to be cancelled")
+	}
+
+	// panics are raised by Eval, and recovered by an Evaluator
+	tf = NewTimeoutFunc(func(x []float64) float64 { panic("boom") }, time.Second)
+	ev := &Evaluator{Recover: true}
+	ys, err := ev.Eval(tf.Eval, [][]float64{{1}}, nil)
+	if _, ok := err.(*PanicError); !ok || !math.IsNaN(ys[0]) {
+		t.Errorf("expected a PanicError, got %v %v", ys, err)
+	}
+
+	// solvers continue after the timeouts
+	hang := func(x []float64) float64 {
+		if x[0] > 1.5 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return (x[0]-1)*(x[0]-1) + (x[1]-1)*(x[1]-1)
+	}
+	for _, method := range []string{"powell", "cmaes", "neldermead"} {
+		res, err := Minimize(optimize.Problem{Func: hang}, []float64{0, 0}, &Options{Method: method, EvalTimeout: 5 * time.Millisecond, Src: rand.NewSource(1)})
+		if err != nil || math.Abs(res.X[0]-1) > 1e-3 || math.Abs(res.X[1]-1) > 1e-3 {
+			t.Errorf("%s: unexpected result %v %v", method, res, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// eps64 is the machine epsilon of float64
+const eps64 = 2.220446049250313e-16
+
+// Tolerances are the combined absolute and relative tolerances of the
+// convergence tests: the step dx of an iteration is small if
+// |dx_i| <= Xatol + Xrtol*|x_i| for every i, and the decrease df of f if
+// df <= Fatol + Frtol*max(|f|, |f-df|). A relative test alone never passes
+// near f = 0, eg f ~ 1e-12, and an absolute one alone never passes for
+// large f, eg f ~ 1e12.
+//
+// A zero field takes its default: Xrtol and Frtol are sqrt(eps), about
+// 1.5e-8, Xatol is eps*max|x0_i| and Fatol eps*|f(x0)|, eps being the
+// machine epsilon, so that the absolute tolerances are the rounding errors
+// at the scale of the starting point. A negative field disables its term.
+type Tolerances struct {
+	Xatol, Xrtol, Fatol, Frtol float64
+}
+
+// WithTolerances sets the Tolerances of a solver
+func WithTolerances(t Tolerances) Option {
+	return func(s configurable) error {
+		if err := t.validate(); err != nil {
+			return err
+		}
+		f := s.fields()
+		if f.tolerances == nil {
+			return unsupported("WithTolerances", s)
+		}
+		*f.tolerances = &t
+		return nil
+	}
+}
+
+func (t *Tolerances) validate() error {
+	for _, v := range []float64{t.Xatol, t.Xrtol, t.Fatol, t.Frtol} {
+		if math.IsNaN(v) {
+			return errors.New("optimize: invalid Tolerances")
+		}
+	}
+	return nil
+}
+
+// resolve returns t with the defaults of the zero fields for the starting
+// point x0 and its value f0, and the negative fields set to 0
+func (t Tolerances) resolve(x0 []float64, f0 float64) Tolerances {
+	xscale := 0.
+	for _, xi := range x0 {
+		xscale = math.Max(xscale, math.Abs(xi))
+	}
+	fscale := math.Abs(f0)
+	if math.IsInf(fscale, 0) || math.IsNaN(fscale) {
+		fscale = 0
+	}
+	def := func(v *float64, d float64) {
+		switch {
+		case *v == 0:
+			*v = d
+		case *v < 0:
+			*v = 0
+		}
+	}
+	def(&t.Xatol, eps64*xscale)
+	def(&t.Xrtol, math.Sqrt(eps64))
+	def(&t.Fatol, eps64*fscale)
+	def(&t.Frtol, math.Sqrt(eps64))
+	return t
+}
+
+// xSmall returns whether the step dx to x passes the test on x, t being resolved
+func (t *Tolerances) xSmall(x, dx []float64) bool {
+	if t.Xatol == 0 && t.Xrtol == 0 {
+		return false
+	}
+	for i, d := range dx {
+		if !(math.Abs(d) <= t.Xatol+t.Xrtol*math.Abs(x[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// fSmall returns whether the decrease from fOld to f passes the test on f,
+// t being resolved
+func (t *Tolerances) fSmall(fOld, f float64) bool {
+	if t.Fatol == 0 && t.Frtol == 0 {
+		return false
+	}
+	return fOld-f <= t.Fatol+t.Frtol*math.Max(math.Abs(f), math.Abs(fOld))
+}
+
+// toleranceConverger is the gonum Converger of the test on f of
+// Tolerances, its defaults being resolved at the first location: the run
+// stops with FunctionConvergence when f has not decreased by more than the
+// tolerance over Iterations major iterations
+type toleranceConverger struct {
+	Tolerances
+	Iterations int
+	fc         optimize.FunctionConverge
+}
+
+func (tc *toleranceConverger) Init(dim int) {
+	tc.fc = optimize.FunctionConverge{Iterations: tc.Iterations}
+	tc.fc.Init(dim)
+}
+
+func (tc *toleranceConverger) Converged(loc *optimize.Location) optimize.Status {
+	if tc.fc.Absolute == 0 && tc.fc.Relative == 0 {
+		t := tc.Tolerances.resolve(loc.X, loc.F)
+		tc.fc.Absolute, tc.fc.Relative = t.Fatol, t.Frtol
+	}
+	return tc.fc.Converged(loc)
+}
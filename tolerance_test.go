@@ -0,0 +1,72 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleTolerances() {
+	// f is about 1e12 at its minimum: the relative test of Ftol stops as
+	// soon as an iteration decreases f by less than Ftol*1e12
+	f := func(x []float64) float64 { return 1e12 + 1e4*rosen(x) }
+	pm := NewPowellMinimizer()
+	res := pm.Minimize(f, []float64{-1.2, 1})
+	fmt.Printf("Ftol: %s, x %.2f\n", res.Status, res.X)
+	pm.Tolerances = &Tolerances{Fatol: 1e-3, Frtol: -1}
+	res = pm.Minimize(f, []float64{-1.2, 1})
+	fmt.Printf("Tolerances: %s, x %.2f\n", res.Status, res.X)
+	// Output:
+	// Ftol: MethodConverge, x [-0.99 0.99]
+	// Tolerances: FunctionConvergence, x [1.00 1.00]
+}
+
+func TestTolerances(t *testing.T) {
+	tol := Tolerances{}.resolve([]float64{-3, 2}, -1e3)
+	if tol.Xatol != 3*eps64 || tol.Fatol != 1e3*eps64 || tol.Xrtol != math.Sqrt(eps64) || tol.Frtol != tol.Xrtol {
+		t.Errorf("unexpected defaults %+v", tol)
+	}
+	tol = Tolerances{Xatol: -1, Xrtol: -1, Fatol: 1e-6, Frtol: -1}.resolve([]float64{1}, math.Inf(1))
+	if tol != (Tolerances{Fatol: 1e-6}) {
+		t.Errorf("unexpected tolerances %+v", tol)
+	}
+	if tol.xSmall([]float64{1}, []float64{0}) || !tol.fSmall(1, 1-5e-7) || tol.fSmall(1, 1-2e-6) {
+		t.Error("unexpected tests")
+	}
+	tol = Tolerances{Xatol: 1e-3, Xrtol: 1e-2}
+	if !tol.xSmall([]float64{100, 0}, []float64{-1, 1e-3}) || tol.xSmall([]float64{100, 0}, []float64{1, 2e-3}) {
+		t.Error("unexpected step tests")
+	}
+	if err := WithTolerances(Tolerances{Frtol: math.NaN()})(&PowellMinimizer{}); err == nil {
+		t.Error("expected an error for NaN tolerances")
+	}
+	if err := WithTolerances(Tolerances{})(&SPSA{}); err == nil {
+		t.Error("expected WithTolerances to be unsupported by SPSA")
+	}
+
+	// f near 0 at its minimum: the decrease of f is tested against Fatol
+	small := func(x []float64) float64 { return 1e-12 * rosen(x) }
+	pm, _ := NewPowellMinimizerWith(WithTolerances(Tolerances{Fatol: 1e-24, Xatol: -1, Xrtol: -1}))
+	res := pm.Minimize(small, []float64{-1.2, 1})
+	if res.Status != optimize.FunctionConvergence || math.Abs(res.X[0]-1) > 1e-4 {
+		t.Errorf("got %s at %g", res.Status, res.X)
+	}
+	// the test on the step
+	pm.Tolerances = &Tolerances{Xatol: 1e-3, Fatol: -1, Frtol: -1}
+	res = pm.Minimize(small, []float64{-1.2, 1})
+	if res.Status != optimize.StepConvergence || math.Abs(res.X[0]-1) > 1e-2 {
+		t.Errorf("got %s at %g", res.Status, res.X)
+	}
+
+	for _, method := range []string{"powell", "neldermead"} {
+		res, err := Minimize(optimize.Problem{Func: small}, []float64{-1.2, 1}, &Options{Method: method, Tolerances: &Tolerances{Fatol: 1e-22}})
+		if err != nil || res.Status != optimize.FunctionConvergence && res.Status != optimize.StepConvergence || res.F > 1e-20 {
+			t.Errorf("%s: got %s f=%g %v", method, res.Status, res.F, err)
+		}
+	}
+	if _, err := Minimize(optimize.Problem{Func: rosen}, []float64{1, 1}, &Options{Tolerances: &Tolerances{Xatol: math.NaN()}}); err == nil {
+		t.Error("expected an error for NaN tolerances")
+	}
+}
@@ -0,0 +1,191 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// TraceFormat is the format of a TraceRecorder
+type TraceFormat int
+
+const (
+	// TraceCSV writes a header and a row per iteration
+	TraceCSV TraceFormat = iota
+	// TraceJSONL writes a JSON object per line. Non finite values are null.
+	TraceJSONL
+)
+
+// TraceRecorder is an Observer appending a row per iteration to W: iter,
+// nfev, elapsed (in seconds), f (the best value), fx (the value at the
+// current iterate, empty if it was not evaluated), the components of x if X
+// is true, and the method diagnostics. The CSV columns of the diagnostics
+// are the ones of the first iteration. Err is the first write error, after
+// which nothing is written.
+type TraceRecorder struct {
+	W      io.Writer
+	Format TraceFormat
+	// X adds the current iterate to the rows
+	X   bool
+	Err error
+
+	csv   *csv.Writer
+	diag  []string
+	evals map[string]float64
+	cur   string
+	fcur  float64
+}
+
+var _ Observer = &TraceRecorder{}
+
+// NewTraceRecorder returns a TraceRecorder writing to w
+func NewTraceRecorder(w io.Writer, format TraceFormat) *TraceRecorder {
+	return &TraceRecorder{W: w, Format: format}
+}
+
+// WithTrace writes a trace of the iterations to w, in addition to the
+// Observer set before
+func WithTrace(w io.Writer, format TraceFormat) Option {
+	return func(s configurable) error {
+		f := s.fields()
+		if f.observer == nil {
+			return unsupported("WithTrace", s)
+		}
+		tr := NewTraceRecorder(w, format)
+		if *f.observer == nil {
+			*f.observer = tr
+		} else {
+			*f.observer = Observers{*f.observer, tr}
+		}
+		return nil
+	}
+}
+
+// OnStart resets the recorder, a new header being written for CSV
+func (tr *TraceRecorder) OnStart(*Start) {
+	tr.csv, tr.diag, tr.evals, tr.cur, tr.fcur = nil, nil, make(map[string]float64), "", math.NaN()
+}
+
+// OnEvaluation records the value of the point, used if it is the next iterate
+func (tr *TraceRecorder) OnEvaluation(e *Evaluation) {
+	if tr.evals == nil {
+		tr.evals = make(map[string]float64)
+	}
+	tr.evals[floatsKey(e.X)] = e.F
+}
+
+// OnDone does nothing
+func (tr *TraceRecorder) OnDone(*Result) {}
+
+// OnIteration writes a row
+func (tr *TraceRecorder) OnIteration(it *Iteration) {
+	if tr.Err != nil {
+		return
+	}
+	// value at the iterate, evaluated during the iteration or being the previous one
+	key := floatsKey(it.X)
+	if fx, ok := tr.evals[key]; ok {
+		tr.cur, tr.fcur = key, fx
+	} else if key != tr.cur {
+		tr.cur, tr.fcur = key, math.NaN()
+	}
+	tr.evals = make(map[string]float64)
+	if tr.Format == TraceJSONL {
+		tr.Err = tr.writeJSON(it)
+	} else {
+		tr.Err = tr.writeCSV(it)
+	}
+}
+
+func (tr *TraceRecorder) writeCSV(it *Iteration) error {
+	format := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	if tr.csv == nil {
+		tr.csv = csv.NewWriter(tr.W)
+		tr.diag = make([]string, 0, len(it.Diagnostics))
+		for k := range it.Diagnostics {
+			tr.diag = append(tr.diag, k)
+		}
+		sort.Strings(tr.diag)
+		header := []string{"iter", "nfev", "elapsed", "f", "fx"}
+		if tr.X {
+			for i := range it.X {
+				header = append(header, "x"+strconv.Itoa(i))
+			}
+		}
+		if err := tr.csv.Write(append(header, tr.diag...)); err != nil {
+			return err
+		}
+	}
+	row := []string{strconv.Itoa(it.Iter), strconv.Itoa(it.NFev), format(it.Elapsed.Seconds()), format(it.F), format(tr.fcur)}
+	if tr.X {
+		for _, xi := range it.X {
+			row = append(row, format(xi))
+		}
+	}
+	for _, k := range tr.diag {
+		v, ok := it.Diagnostics[k]
+		if !ok {
+			v = math.NaN()
+		}
+		row = append(row, format(v))
+	}
+	if err := tr.csv.Write(row); err != nil {
+		return err
+	}
+	tr.csv.Flush()
+	return tr.csv.Error()
+}
+
+// jsonFloat is a float64 encoded as null if it is not finite
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return []byte("null"), nil
+	}
+	return strconv.AppendFloat(nil, float64(f), 'g', -1, 64), nil
+}
+
+// UnmarshalJSON decodes null as NaN
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = jsonFloat(math.NaN())
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(data), 64)
+	*f = jsonFloat(v)
+	return err
+}
+
+func (tr *TraceRecorder) writeJSON(it *Iteration) error {
+	row := struct {
+		Iter        int                  `json:"iter"`
+		NFev        int                  `json:"nfev"`
+		Elapsed     jsonFloat            `json:"elapsed"`
+		F           jsonFloat            `json:"f"`
+		FX          jsonFloat            `json:"fx"`
+		X           []jsonFloat          `json:"x,omitempty"`
+		Diagnostics map[string]jsonFloat `json:"diagnostics,omitempty"`
+	}{Iter: it.Iter, NFev: it.NFev, Elapsed: jsonFloat(it.Elapsed.Seconds()), F: jsonFloat(it.F), FX: jsonFloat(tr.fcur)}
+	if tr.X {
+		row.X = make([]jsonFloat, len(it.X))
+		for i, xi := range it.X {
+			row.X[i] = jsonFloat(xi)
+		}
+	}
+	if len(it.Diagnostics) > 0 {
+		row.Diagnostics = make(map[string]jsonFloat, len(it.Diagnostics))
+		for k, v := range it.Diagnostics {
+			row.Diagnostics[k] = jsonFloat(v)
+		}
+	}
+	return json.NewEncoder(tr.W).Encode(row)
+}
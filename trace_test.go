@@ -0,0 +1,62 @@
+package optimize
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleTraceRecorder() {
+	buf := new(bytes.Buffer)
+	pm, _ := NewPowellMinimizerWith(WithTrace(buf, TraceCSV))
+	pm.Minimize(rosen, []float64{-1.2, 1})
+	rows, _ := csv.NewReader(buf).ReadAll()
+	fmt.Println(rows[0], len(rows)-1)
+	// Output:
+	// [iter nfev elapsed f fx step] 24
+}
+
+func TestTraceRecorder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tr := NewTraceRecorder(buf, TraceCSV)
+	tr.X = true
+	sp := NewSPSA()
+	sp.MaxIter, sp.Observer = 5, tr
+	sp.Minimize(rosen, []float64{-1.2, 1})
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rows[0], ","); got != "iter,nfev,elapsed,f,fx,x0,x1,ak,ck" || len(rows) != 6 {
+		t.Errorf("header %s, %d rows", got, len(rows))
+	}
+	// the iterates of SPSA are not evaluated
+	if rows[1][0] != "1" || rows[1][4] != "" {
+		t.Errorf("first row %v", rows[1])
+	}
+
+	buf.Reset()
+	pm, _ := NewPowellMinimizerWith(WithTrace(buf, TraceJSONL), WithMaxIter(3))
+	res := pm.Minimize(rosen, []float64{-1.2, 1})
+	sc := bufio.NewScanner(buf)
+	var last struct {
+		Iter        int
+		F, FX       *float64
+		X           []float64
+		Diagnostics map[string]float64
+	}
+	lines := 0
+	for ; sc.Scan(); lines++ {
+		if err := json.Unmarshal(sc.Bytes(), &last); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the iterates of Powell are evaluated
+	if lines != 3 || last.Iter != 3 || last.F == nil || last.FX == nil || *last.FX != res.F || last.X != nil || last.Diagnostics["step"] <= 0 {
+		t.Errorf("%d lines, last %+v", lines, last)
+	}
+}
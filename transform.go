@@ -0,0 +1,105 @@
+package optimize
+
+import (
+	"math"
+)
+
+// BoxTransform maps unconstrained variables z onto the box Xmin, Xmax, so
+// that unconstrained methods minimize f(ToBox(z)) within the bounds, as in
+// MINUIT: x = lo + (hi-lo)(sin z + 1)/2 for a finite interval,
+// x = lo - 1 + sqrt(z^2+1) for a lower bound only and
+// x = hi + 1 - sqrt(z^2+1) for an upper bound only. Missing or infinite
+// bounds leave the components unchanged.
+type BoxTransform struct {
+	Xmin, Xmax []float64
+}
+
+// bounds returns the finite bounds of component i
+func (bt BoxTransform) bounds(i int) (lo, hi float64, hasLo, hasHi bool) {
+	lo, hi = math.Inf(-1), math.Inf(1)
+	if i < len(bt.Xmin) {
+		lo = bt.Xmin[i]
+	}
+	if i < len(bt.Xmax) {
+		hi = bt.Xmax[i]
+	}
+	return lo, hi, !math.IsInf(lo, 0), !math.IsInf(hi, 0)
+}
+
+// ToBox sets dst to the point of the box of z and returns it. dst is
+// allocated if nil.
+func (bt BoxTransform) ToBox(dst, z []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(z))
+	}
+	for i, zi := range z {
+		lo, hi, hasLo, hasHi := bt.bounds(i)
+		switch {
+		case hasLo && hasHi:
+			dst[i] = lo + (hi-lo)*(math.Sin(zi)+1)/2
+		case hasLo:
+			dst[i] = lo - 1 + math.Sqrt(zi*zi+1)
+		case hasHi:
+			dst[i] = hi + 1 - math.Sqrt(zi*zi+1)
+		default:
+			dst[i] = zi
+		}
+	}
+	return dst
+}
+
+// FromBox sets dst to the unconstrained variables of x, projected on the
+// box, and returns it. dst is allocated if nil.
+func (bt BoxTransform) FromBox(dst, x []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, len(x))
+	}
+	for i, xi := range x {
+		lo, hi, hasLo, hasHi := bt.bounds(i)
+		switch {
+		case hasLo && hasHi:
+			u := 2*(xi-lo)/(hi-lo) - 1
+			dst[i] = math.Asin(math.Max(-1, math.Min(1, u)))
+		case hasLo:
+			d := math.Max(xi-lo, 0) + 1
+			dst[i] = math.Sqrt(d*d - 1)
+		case hasHi:
+			d := math.Max(hi-xi, 0) + 1
+			dst[i] = math.Sqrt(d*d - 1)
+		default:
+			dst[i] = xi
+		}
+	}
+	return dst
+}
+
+// derivative returns dx_i/dz_i at z_i
+func (bt BoxTransform) derivative(i int, zi float64) float64 {
+	lo, hi, hasLo, hasHi := bt.bounds(i)
+	switch {
+	case hasLo && hasHi:
+		return (hi - lo) * math.Cos(zi) / 2
+	case hasLo:
+		return zi / math.Sqrt(zi*zi+1)
+	case hasHi:
+		return -zi / math.Sqrt(zi*zi+1)
+	}
+	return 1
+}
+
+// Func returns f of the unconstrained variables
+func (bt BoxTransform) Func(f func([]float64) float64) func([]float64) float64 {
+	return func(z []float64) float64 {
+		return f(bt.ToBox(nil, z))
+	}
+}
+
+// Grad returns the gradient of Func(f) from the gradient of f
+func (bt BoxTransform) Grad(grad func(grad, x []float64)) func(grad, z []float64) {
+	return func(g, z []float64) {
+		grad(g, bt.ToBox(nil, z))
+		for i, zi := range z {
+			g[i] *= bt.derivative(i, zi)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func ExampleBoxTransform() {
+	bt := BoxTransform{Xmin: []float64{0, 1, math.Inf(-1)}, Xmax: []float64{2, math.Inf(1), 3}}
+	// any z is in the box
+	fmt.Printf("%.3f\n", bt.ToBox(nil, []float64{10, -10, 10}))
+	z := bt.FromBox(nil, []float64{.5, 2, 0})
+	fmt.Printf("%.3f\n", bt.ToBox(nil, z))
+	// Output:
+	// [0.456 10.050 -6.050]
+	// [0.500 2.000 0.000]
+}
+
+func TestBoxTransform(t *testing.T) {
+	bt := BoxTransform{Xmin: []float64{-1, 0, math.Inf(-1)}, Xmax: []float64{1, math.Inf(1), 2}}
+	z := []float64{.3, -.7, 1.2, 4}
+	x := bt.ToBox(nil, z)
+	if x[3] != 4 {
+		t.Errorf("expected an unbounded component, got %g", x[3])
+	}
+	// the gradient of the transformed function
+	f := func(x []float64) float64 { return x[0]*x[1] + x[2]*x[2]*x[3] }
+	grad := func(g, x []float64) {
+		g[0], g[1], g[2], g[3] = x[1], x[0], 2*x[2]*x[3], x[2]*x[2]
+	}
+	g := make([]float64, 4)
+	bt.Grad(grad)(g, z)
+	if num := Gradient(bt.Func(f), z, nil); !floats.EqualApprox(g, num, 1e-6) {
+		t.Errorf("expected %g, got %g", num, g)
+	}
+	// points out of the box are projected
+	if z := bt.FromBox(nil, []float64{3, -1, 5, 0}); !floats.EqualApprox(bt.ToBox(nil, z), []float64{1, 0, 2, 0}, 1e-12) {
+		t.Errorf("unexpected point %g", bt.ToBox(nil, z))
+	}
+}
@@ -0,0 +1,125 @@
+package optimize
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// DimensionError is the error of bounds whose length is not the dimension
+// of the problem
+type DimensionError struct {
+	// Name is "Xmin" or "Xmax"
+	Name     string
+	Len, Dim int
+}
+
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("optimize: len(%s) = %d, expected %d", e.Name, e.Len, e.Dim)
+}
+
+// BoundsError is the error of a lower bound greater than the upper bound
+type BoundsError struct {
+	Index        int
+	Lower, Upper float64
+}
+
+func (e *BoundsError) Error() string {
+	return fmt.Sprintf("optimize: Xmin[%d] = %g > Xmax[%d] = %g", e.Index, e.Lower, e.Index, e.Upper)
+}
+
+// ValueError is the error of a NaN bound, a lower bound +Inf, an upper
+// bound -Inf, or a non-finite component of the starting point
+type ValueError struct {
+	// Name is "Xmin", "Xmax" or "x0"
+	Name  string
+	Index int
+	Value float64
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("optimize: invalid %s[%d] = %g", e.Name, e.Index, e.Value)
+}
+
+// InfeasibleStartError is the error of a starting point outside the bounds
+type InfeasibleStartError struct {
+	Index               int
+	Value, Lower, Upper float64
+}
+
+func (e *InfeasibleStartError) Error() string {
+	return fmt.Sprintf("optimize: x0[%d] = %g outside [%g, %g]", e.Index, e.Value, e.Lower, e.Upper)
+}
+
+// ValidateBounds checks the bounds xmin, xmax of a problem of dimension dim:
+// each one is either empty, unbounded, or of length dim, with no NaN, and
+// xmin <= xmax. It returns a *DimensionError, *ValueError or *BoundsError.
+func ValidateBounds(dim int, xmin, xmax []float64) error {
+	for _, b := range []struct {
+		name string
+		x    []float64
+		inf  float64
+	}{{"Xmin", xmin, math.Inf(1)}, {"Xmax", xmax, math.Inf(-1)}} {
+		if len(b.x) != 0 && len(b.x) != dim {
+			return &DimensionError{Name: b.name, Len: len(b.x), Dim: dim}
+		}
+		for i, v := range b.x {
+			if math.IsNaN(v) || v == b.inf {
+				return &ValueError{Name: b.name, Index: i, Value: v}
+			}
+		}
+	}
+	if len(xmin) != 0 && len(xmax) != 0 {
+		for i := range xmin {
+			if xmin[i] > xmax[i] {
+				return &BoundsError{Index: i, Lower: xmin[i], Upper: xmax[i]}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateStart checks the bounds of a problem with ValidateBounds, and
+// that its starting point x0 is finite and within them. It returns an
+// *InfeasibleStartError for an x0 outside the bounds, which may be
+// projected on them instead.
+func ValidateStart(x0, xmin, xmax []float64) error {
+	if err := ValidateBounds(len(x0), xmin, xmax); err != nil {
+		return err
+	}
+	for i, v := range x0 {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return &ValueError{Name: "x0", Index: i, Value: v}
+		}
+	}
+	for i, v := range x0 {
+		lo, hi := math.Inf(-1), math.Inf(1)
+		if len(xmin) != 0 {
+			lo = xmin[i]
+		}
+		if len(xmax) != 0 {
+			hi = xmax[i]
+		}
+		if v < lo || v > hi {
+			return &InfeasibleStartError{Index: i, Value: v, Lower: lo, Upper: hi}
+		}
+	}
+	return nil
+}
+
+// feasibleStart returns x0, or a copy of x0 projected on the bounds and a
+// note, also printed to logger if not nil, if it is outside them, or the
+// error of ValidateStart
+func feasibleStart(x0, xmin, xmax []float64, logger *log.Logger) (x []float64, note string, err error) {
+	err = ValidateStart(x0, xmin, xmax)
+	if _, ok := err.(*InfeasibleStartError); !ok {
+		return x0, "", err
+	}
+	x = append([]float64(nil), x0...)
+	Box{Xmin: xmin, Xmax: xmax}.Project(x)
+	note = err.Error() + ", projected on the bounds"
+	if logger != nil {
+		logger.Print(note)
+	}
+	return x, note, nil
+}
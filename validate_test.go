@@ -0,0 +1,122 @@
+package optimize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleValidateStart() {
+	err := ValidateStart([]float64{0, 3}, []float64{-1, -1}, []float64{1, 2})
+	var infeasible *InfeasibleStartError
+	if errors.As(err, &infeasible) {
+		fmt.Println(err, infeasible.Index)
+	}
+	// Output:
+	// optimize: x0[1] = 3 outside [-1, 2] 1
+}
+
+func TestValidateStart(t *testing.T) {
+	inf := math.Inf(1)
+	for _, c := range []struct {
+		x0, xmin, xmax []float64
+		err            string
+	}{
+		{[]float64{0, 0}, nil, nil, ""},
+		{[]float64{0, 0}, []float64{-inf, 0}, []float64{1, inf}, ""},
+		{[]float64{0, 0}, []float64{-1}, nil, "optimize: len(Xmin) = 1, expected 2"},
+		{[]float64{0, 0}, nil, []float64{1, 1, 1}, "optimize: len(Xmax) = 3, expected 2"},
+		{[]float64{0, 0}, []float64{math.NaN(), 0}, nil, "optimize: invalid Xmin[0] = NaN"},
+		{[]float64{0, 0}, []float64{0, inf}, nil, "optimize: invalid Xmin[1] = +Inf"},
+		{[]float64{0, 0}, nil, []float64{-inf, 0}, "optimize: invalid Xmax[0] = -Inf"},
+		{[]float64{0, 0}, []float64{0, 2}, []float64{1, 1}, "optimize: Xmin[1] = 2 > Xmax[1] = 1"},
+		{[]float64{0, inf}, nil, nil, "optimize: invalid x0[1] = +Inf"},
+		{[]float64{0, -2}, []float64{-1, -1}, nil, "optimize: x0[1] = -2 outside [-1, +Inf]"},
+	} {
+		err := ValidateStart(c.x0, c.xmin, c.xmax)
+		if got := fmt.Sprint(err); c.err == "" && err != nil || c.err != "" && got != c.err {
+			t.Errorf("got %v, expected %q", err, c.err)
+		}
+	}
+	var dimErr *DimensionError
+	if err := ValidateBounds(3, []float64{0}, nil); !errors.As(err, &dimErr) || dimErr.Len != 1 || dimErr.Dim != 3 {
+		t.Errorf("got %v", err)
+	}
+	var boundsErr *BoundsError
+	if err := ValidateBounds(1, []float64{2}, []float64{1}); !errors.As(err, &boundsErr) || boundsErr.Lower != 2 {
+		t.Errorf("got %v", err)
+	}
+
+	// Minimize projects an infeasible x0 with a warning
+	var buf bytes.Buffer
+	res, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Xmin: []float64{0, 0}, Xmax: []float64{2, 2}, Logger: log.New(&buf, "", 0)})
+	note := "optimize: x0[0] = -1.2 outside [0, 2], projected on the bounds"
+	if err != nil || math.Abs(res.X[0]-1) > 1e-4 || !strings.Contains(buf.String(), note) || fmt.Sprint(res.Warnings) != "["+note+"]" {
+		t.Errorf("got %v %v, log %q", res, err, buf.String())
+	}
+	if _, err := Minimize(optimize.Problem{Func: rosen}, []float64{-1.2, 1}, &Options{Xmin: []float64{0}}); !errors.As(err, &dimErr) {
+		t.Errorf("got %v", err)
+	}
+	if _, err := Minimize(optimize.Problem{Func: rosen}, []float64{math.NaN(), 1}, nil); err == nil {
+		t.Error("expected an error for a NaN x0")
+	}
+}
+
+func TestInfeasibleStart(t *testing.T) {
+	xmin, xmax := []float64{0, 0}, []float64{2, 2}
+	note := "optimize: x0[0] = -1.2 outside [0, 2], projected on the bounds"
+	inBox := func(x []float64) bool { return x[0] >= 0 && x[0] <= 2 && x[1] >= 0 && x[1] <= 2 }
+
+	// the solvers with bounds project an infeasible start with a note
+	sp := NewSPSA()
+	sp.Xmin, sp.Xmax = xmin, xmax
+	ls := NewLatticeSearch()
+	ls.Xmin, ls.Xmax = xmin, xmax
+	for _, m := range []Minimizer{sp, ls} {
+		if res := m.Minimize(rosen, []float64{-1.2, 1}); !inBox(res.X) || fmt.Sprint(res.Warnings) != "["+note+"]" {
+			t.Errorf("%T: got %v %v", m, res.X, res.Warnings)
+		}
+	}
+
+	// and fail on a non-finite start
+	for _, m := range []Minimizer{sp, ls, NewPowellMinimizer()} {
+		if res := m.Minimize(rosen, []float64{math.NaN(), 1}); res.Status != optimize.Failure || res.Message != "optimize: invalid x0[0] = NaN" {
+			t.Errorf("%T: got %v", m, res)
+		}
+	}
+
+	// CMA-ES centers its distribution on the projected start, the samples
+	// of a start infeasible on every component staying in the box
+	cma := &CmaEsCholB{Src: rand.NewSource(1), Xmin: xmin, Xmax: xmax}
+	outside := 0
+	res, err := optimize.Minimize(optimize.Problem{Func: func(x []float64) float64 {
+		if !inBox(x) {
+			outside++
+		}
+		return rosen(x)
+	}}, []float64{-3, 5}, nil, cma)
+	if err != nil || !inBox(res.X) || outside > 1 || len(cma.Warnings()) != 1 || !strings.HasPrefix(cma.Warnings()[0], "optimize: x0[0] = -3 outside [0, 2]") {
+		t.Errorf("got %v %v %v", res, err, cma.Warnings())
+	}
+	cma = &CmaEsCholB{Src: rand.NewSource(1), Xmin: xmin, Xmax: xmax}
+	if _, err := optimize.Minimize(optimize.Problem{Func: rosen}, []float64{math.Inf(-1), 1}, nil, cma); err == nil || cma.Reason() != ReasonFailure {
+		t.Errorf("got %v", err)
+	}
+	mm := &MethodMinimizer{Method: &CmaEsCholB{Src: rand.NewSource(1), Xmin: xmin, Xmax: xmax}}
+	r := mm.Minimize(rosen, []float64{-3, 5})
+	if len(r.Warnings) != 1 || !inBox(r.X) {
+		t.Errorf("got %v", r)
+	}
+	var decoded Result
+	if data, err := json.Marshal(r); err != nil || json.Unmarshal(data, &decoded) != nil || fmt.Sprint(decoded.Warnings) != fmt.Sprint(r.Warnings) {
+		t.Errorf("got %v %v", decoded.Warnings, err)
+	}
+}
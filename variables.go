@@ -0,0 +1,56 @@
+package optimize
+
+import (
+	"math"
+)
+
+// VarKind is the type of a decision variable
+type VarKind int
+
+const (
+	// Continuous variables take any real value
+	Continuous VarKind = iota
+	// Integer variables take integer values
+	Integer
+	// Categorical variables take one of Levels unordered values, encoded as 0..Levels-1
+	Categorical
+)
+
+// Variable describes the type of one dimension
+type Variable struct {
+	Kind VarKind
+	// Levels is the number of levels of a Categorical variable
+	Levels int
+}
+
+// Variables are the types of the dimensions of a mixed problem.
+// Dimensions beyond its length are Continuous.
+// Methods use it to repair points so that the objective is only evaluated
+// at valid points, eg the samples of CmaEsCholB, and BranchAndBound to
+// branch on the discrete dimensions.
+type Variables []Variable
+
+var _ Projection = Variables{}
+
+// Project repairs x in place: integer variables are rounded and categorical
+// ones are rounded and clipped to 0..Levels-1.
+func (vs Variables) Project(x []float64) {
+	for i, v := range vs {
+		if i >= len(x) {
+			break
+		}
+		switch v.Kind {
+		case Integer:
+			x[i] = math.Round(x[i])
+		case Categorical:
+			x[i] = math.Max(0, math.Min(float64(v.Levels-1), math.Round(x[i])))
+		}
+	}
+}
+
+func (vs Variables) kind(i int) Variable {
+	if i < len(vs) {
+		return vs[i]
+	}
+	return Variable{}
+}
@@ -0,0 +1,37 @@
+package optimize
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleVariables() {
+	// x0 is continuous, x1 integer and x2 categorical with 4 levels
+	vars := Variables{{}, {Kind: Integer}, {Kind: Categorical, Levels: 4}}
+	cost := []float64{3, 1, 0, 2}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			return (x[0]-.3)*(x[0]-.3) + (x[1]-2.6)*(x[1]-2.6) + cost[int(x[2])]
+		},
+	}
+	method := &CmaEsCholB{Variables: vars, MinStepSize: .2, Src: rand.NewSource(1)}
+	res, err := optimize.Minimize(problem, []float64{0, 0, 0}, &optimize.Settings{FuncEvaluations: 2000}, method)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.2f\n", res.X)
+	// Output:
+	// [0.30 3.00 2.00]
+}
+
+func TestVariables(t *testing.T) {
+	vars := Variables{{Kind: Integer}, {Kind: Categorical, Levels: 3}}
+	x := []float64{1.6, 7, .4}
+	vars.Project(x)
+	if x[0] != 2 || x[1] != 2 || x[2] != .4 {
+		t.Errorf("bad repair %v", x)
+	}
+}
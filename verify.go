@@ -0,0 +1,178 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// VerifyProblem is a test function of Verify, of known minimum XOpt, FOpt
+type VerifyProblem struct {
+	Name     string
+	Func     func([]float64) float64
+	X0, XOpt []float64
+	FOpt     float64
+}
+
+// VerifyProblems returns the problems of Verify in dimension dim, at least
+// 2, all of minimum 0 at x = (1, ..., 1) and starting from
+// (-1.2, 1, -1.2, ...): convex quadratics, well conditioned ("sphere"),
+// axis-aligned of condition 1e3 ("ellipsoid") and rotated ("tridiagonal"),
+// and the Rosenbrock function.
+func VerifyProblems(dim int) []VerifyProblem {
+	if dim < 2 {
+		dim = 2
+	}
+	x0, xopt := make([]float64, dim), make([]float64, dim)
+	for i := range x0 {
+		x0[i], xopt[i] = -1.2, 1
+		if i%2 == 1 {
+			x0[i] = 1
+		}
+	}
+	problem := func(name string, f func(x []float64) float64) VerifyProblem {
+		return VerifyProblem{Name: name, Func: f, X0: x0, XOpt: xopt}
+	}
+	// quadratic returns the problem of q(x - xopt)
+	quadratic := func(name string, q func(z []float64) float64) VerifyProblem {
+		return problem(name, func(x []float64) float64 {
+			z := make([]float64, len(x))
+			for i, xi := range x {
+				z[i] = xi - 1
+			}
+			return q(z)
+		})
+	}
+	ratio := func(i int) float64 { return float64(i) / float64(dim-1) }
+	return []VerifyProblem{
+		quadratic("sphere", func(z []float64) float64 {
+			s := 0.
+			for _, zi := range z {
+				s += zi * zi
+			}
+			return s
+		}),
+		quadratic("ellipsoid", func(z []float64) float64 {
+			s := 0.
+			for i, zi := range z {
+				s += math.Pow(1e3, ratio(i)) * zi * zi
+			}
+			return s
+		}),
+		quadratic("tridiagonal", func(z []float64) float64 {
+			// z^T A z for A = tridiag(-1, 2, -1)
+			s := 0.
+			for i, zi := range z {
+				s += 2 * zi * zi
+				if i > 0 {
+					s -= 2 * zi * z[i-1]
+				}
+			}
+			return s
+		}),
+		problem("rosenbrock", rosenbrock),
+	}
+}
+
+// rosenbrock is the extended Rosenbrock function of Moré, Garbow and
+// Hillstrom, a sum of Rosenbrock functions of the pairs of variables, plus
+// (x_n-1)^2 for an odd dimension n. Unlike the chained Rosenbrock function,
+// its only local minimum is 0 at (1, ..., 1).
+func rosenbrock(x []float64) float64 {
+	s := 0.
+	for i := 0; i+1 < len(x); i += 2 {
+		a, b := x[i+1]-x[i]*x[i], 1-x[i]
+		s += 100*a*a + b*b
+	}
+	if n := len(x); n%2 == 1 {
+		s += (x[n-1] - 1) * (x[n-1] - 1)
+	}
+	return s
+}
+
+// VerifyOptions are the settings of Verify
+type VerifyOptions struct {
+	// Problems are the test functions, VerifyProblems(Dim) if nil
+	Problems []VerifyProblem
+	// Dim is the dimension of the default problems, 2 if 0
+	Dim int
+	// FTol is the accuracy on f required to pass, f - FOpt being at most
+	// FTol*max(1, |FOpt|). The default is 1e-6.
+	FTol float64
+}
+
+// VerifyCase is the result of Verify on a problem
+type VerifyCase struct {
+	Problem string
+	Pass    bool
+	// FErr is f - FOpt and XErr the largest |x_i - XOpt_i|
+	FErr, XErr float64
+	Result     *Result
+}
+
+// Verification is the result of Verify
+type Verification struct {
+	Cases []VerifyCase
+}
+
+// OK returns true if every case passed
+func (v *Verification) OK() bool {
+	for _, c := range v.Cases {
+		if !c.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// String is a report of the cases, one per line
+func (v *Verification) String() string {
+	var b strings.Builder
+	passed := 0
+	for _, c := range v.Cases {
+		status := "FAIL"
+		if c.Pass {
+			status, passed = "ok", passed+1
+		}
+		fmt.Fprintf(&b, "%-4s %-12s f-fopt=%.3g |x-xopt|=%.3g nfev=%d %s\n", status, c.Problem, c.FErr, c.XErr, c.Result.NFev, c.Result.Status)
+	}
+	fmt.Fprintf(&b, "verify: %d/%d passed", passed, len(v.Cases))
+	return b.String()
+}
+
+// Verify runs m, eg a configured solver of NewAlgorithm, on convex
+// quadratics and test functions of known minimum, and reports whether it
+// reached them with the accuracy FTol, so that users can sanity-check a
+// configured optimizer in their own tests. opts may be nil.
+func Verify(m Minimizer, opts *VerifyOptions) *Verification {
+	if opts == nil {
+		opts = &VerifyOptions{}
+	}
+	problems := opts.Problems
+	if problems == nil {
+		dim := opts.Dim
+		if dim <= 0 {
+			dim = 2
+		}
+		problems = VerifyProblems(dim)
+	}
+	ftol := opts.FTol
+	if ftol <= 0 {
+		ftol = 1e-6
+	}
+	v := &Verification{Cases: make([]VerifyCase, len(problems))}
+	for k, p := range problems {
+		res := m.Minimize(p.Func, append([]float64(nil), p.X0...))
+		c := VerifyCase{Problem: p.Name, FErr: res.F - p.FOpt, Result: res}
+		for i, xi := range res.X {
+			if i < len(p.XOpt) {
+				c.XErr = math.Max(c.XErr, math.Abs(xi-p.XOpt[i]))
+			}
+		}
+		c.Pass = res.Status != optimize.Failure && c.FErr <= ftol*math.Max(1, math.Abs(p.FOpt))
+		v.Cases[k] = c
+	}
+	return v
+}
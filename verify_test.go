@@ -0,0 +1,58 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func ExampleVerify() {
+	m, _ := NewAlgorithm("LN_POWELL")
+	v := Verify(m, &VerifyOptions{Dim: 5})
+	fmt.Println(v.OK(), strings.Split(v.String(), "\n")[len(v.Cases)])
+	// Output:
+	// true verify: 4/4 passed
+}
+
+func TestVerify(t *testing.T) {
+	if v := Verify(NewPowellMinimizer(), nil); !v.OK() || len(v.Cases) != 4 {
+		t.Errorf("got\n%s", v)
+	}
+	for _, dim := range []int{0, 1, 3, 6} {
+		for _, p := range VerifyProblems(dim) {
+			if f := p.Func(p.XOpt); f != p.FOpt || len(p.X0) < 2 || len(p.X0) < dim || p.Func(p.X0) <= f {
+				t.Errorf("%d %s: f(xopt) = %g, x0 %v", dim, p.Name, f, p.X0)
+			}
+		}
+	}
+
+	// a minimizer returning x0 fails every case
+	stay := MinimizerFunc(func(f func([]float64) float64, x0 []float64) *Result {
+		return &Result{X: x0, F: f(x0), NFev: 1, Status: optimize.MethodConverge}
+	})
+	v := Verify(stay, &VerifyOptions{Dim: 3})
+	if v.OK() || !strings.HasSuffix(v.String(), "verify: 0/4 passed") {
+		t.Errorf("got\n%s", v)
+	}
+	if c := v.Cases[0]; c.Problem != "sphere" || c.Pass || math.Abs(c.FErr-2*2.2*2.2) > 1e-12 || c.XErr != 2.2 {
+		t.Errorf("got %+v", c)
+	}
+
+	// custom problems and tolerance, with a failure status
+	shift := VerifyProblem{Name: "shift", Func: func(x []float64) float64 { return x[0]*x[0] + 3 }, X0: []float64{1e-4}, XOpt: []float64{0}, FOpt: 3}
+	if v := Verify(stay, &VerifyOptions{Problems: []VerifyProblem{shift}, FTol: 1e-8}); !v.OK() {
+		t.Errorf("got\n%s", v)
+	}
+	if v := Verify(stay, &VerifyOptions{Problems: []VerifyProblem{shift}, FTol: 1e-9}); v.OK() {
+		t.Errorf("got\n%s", v)
+	}
+	fail := MinimizerFunc(func(f func([]float64) float64, x0 []float64) *Result {
+		return &Result{X: x0, F: f(x0), Status: optimize.Failure}
+	})
+	if v := Verify(fail, &VerifyOptions{Problems: []VerifyProblem{shift}}); v.OK() {
+		t.Errorf("got\n%s", v)
+	}
+}
@@ -0,0 +1,71 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WarmStart is the adaptive state at the end of a run, the Warm of its
+// Result. A WarmStarter solver primed with it starts a run on a slightly
+// changed objective, eg a fit with a new data point, from that state instead
+// of a cold start.
+type WarmStart struct {
+	// Method is the solver of the run: "powell", "spsa" or "cmaes"
+	Method string
+	// X is the final point, the mean of the distribution for cmaes
+	X []float64
+	// Directions are the search directions of powell, the rows of a
+	// len(X)×len(X) matrix
+	Directions []float64
+	// StepSize is the step size sigma of cmaes, or the calibrated gain A of spsa
+	StepSize float64
+	// Cholesky is the upper triangular Cholesky factor of the covariance of
+	// cmaes, row-major
+	Cholesky []float64
+	// Iter is the number of iterations of the run. The gain sequences of
+	// spsa continue from it.
+	Iter int
+}
+
+// WarmStarter is implemented by the solvers which can be primed with the
+// Warm of the Result of a previous run: PowellMinimizer, SPSA and
+// CmaEsCholB. The next run starts from the point of prev, ignoring x0,
+// unless their dimensions differ, then it is a cold start. Unlike Restore,
+// the counters and the function values are not restored: the objective has
+// changed.
+type WarmStarter interface {
+	WarmStart(prev *Result) error
+}
+
+var (
+	_ WarmStarter = &PowellMinimizer{}
+	_ WarmStarter = &SPSA{}
+	_ WarmStarter = &CmaEsCholB{}
+)
+
+// warmState returns the Warm of prev, which must be a run of method
+func warmState(method string, prev *Result) (*WarmStart, error) {
+	if prev == nil || prev.Warm == nil {
+		return nil, errors.New("optimize: no warm start state in the Result")
+	}
+	if prev.Warm.Method != method {
+		return nil, fmt.Errorf("optimize: warm start state of %s, expected %s", prev.Warm.Method, method)
+	}
+	return prev.Warm, nil
+}
+
+// WithWarmStart primes a solver with the Result of a previous run, see
+// WarmStarter and Options.Warm
+func WithWarmStart(prev *Result) Option {
+	return func(s configurable) error {
+		if o, ok := s.(*Options); ok {
+			o.Warm = prev
+			return nil
+		}
+		ws, ok := s.(WarmStarter)
+		if !ok {
+			return unsupported("WithWarmStart", s)
+		}
+		return ws.WarmStart(prev)
+	}
+}
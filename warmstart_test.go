@@ -0,0 +1,101 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// fitLine returns the least squares objective of a line through the points
+func fitLine(xs, ys []float64) func([]float64) float64 {
+	return func(p []float64) float64 {
+		s := 0.
+		for i, x := range xs {
+			r := p[0] + p[1]*x - ys[i]
+			s += r * r
+		}
+		return s
+	}
+}
+
+func ExampleWarmStart() {
+	xs, ys := []float64{0, 1, 2, 3}, []float64{1.1, 2.9, 5.2, 6.8}
+	res, _ := Minimize(optimize.Problem{Func: fitLine(xs, ys)}, []float64{0, 0}, &Options{Method: "cmaes", Src: rand.NewSource(1)})
+	// a new data point: the fit starts from the previous distribution
+	xs, ys = append(xs, 4), append(ys, 9.1)
+	cold, _ := Minimize(optimize.Problem{Func: fitLine(xs, ys)}, []float64{0, 0}, &Options{Method: "cmaes", Src: rand.NewSource(1)})
+	warm, _ := Minimize(optimize.Problem{Func: fitLine(xs, ys)}, res.X, &Options{Method: "cmaes", Src: rand.NewSource(1), Warm: res})
+	fmt.Printf("%.2f %.2f %v\n", cold.X, warm.X, warm.NFev < cold.NFev)
+	// Output:
+	// [1.04 1.99] [1.04 1.99] true
+}
+
+func TestWarmStart(t *testing.T) {
+	xs, ys := []float64{0, 1, 2, 3}, []float64{1.1, 2.9, 5.2, 6.8}
+	f := fitLine(xs, ys)
+	g := fitLine(append(xs, 4), append(ys, 9.1))
+
+	// powell starts from the point and directions of the previous run, the
+	// directions of a cold start from the same point being the axes
+	xp, yp := append(xs, 5, 6), append(ys, 11, 13)
+	pm := NewPowellMinimizer()
+	res := pm.Minimize(fitLine(xp, yp), []float64{0, 0})
+	if res.Warm == nil || res.Warm.Method != "powell" || len(res.Warm.Directions) != 4 {
+		t.Fatalf("unexpected warm state %+v", res.Warm)
+	}
+	gp := fitLine(append(xp, 4), append(yp, 9.1))
+	cold := NewPowellMinimizer().Minimize(gp, res.X)
+	if err := pm.WarmStart(res); err != nil {
+		t.Fatal(err)
+	}
+	warm := pm.Minimize(gp, []float64{0, 0})
+	if math.Abs(warm.F-cold.F) > 1e-6 || warm.NFev >= cold.NFev {
+		t.Errorf("warm %g in %d evaluations, cold %g in %d", warm.F, warm.NFev, cold.F, cold.NFev)
+	}
+
+	// spsa reuses its gain and continues its gain sequences
+	sp := NewSPSA()
+	sp.MaxIter, sp.Src = 200, rand.NewSource(1)
+	res = sp.Minimize(f, []float64{0, 0})
+	if res.Warm == nil || res.Warm.Iter != 200 || res.Warm.StepSize <= 0 {
+		t.Fatalf("unexpected warm state %+v", res.Warm)
+	}
+	if err := sp.WarmStart(res); err != nil {
+		t.Fatal(err)
+	}
+	warm = sp.Minimize(g, []float64{0, 0})
+	if warm.Warm.Iter != 400 || warm.NFev != 2*200+1 || warm.F > g(res.X) {
+		t.Errorf("unexpected warm run %+v", warm)
+	}
+
+	// the state of another method or dimension
+	if err := NewPowellMinimizer().WarmStart(res); err == nil {
+		t.Error("expected an error for the state of spsa")
+	}
+	if err := NewPowellMinimizer().WarmStart(&Result{}); err == nil {
+		t.Error("expected an error without state")
+	}
+	pm = NewPowellMinimizer()
+	pm.WarmStart(cold)
+	if r := pm.Minimize(func(x []float64) float64 { return x[0] * x[0] }, []float64{1}); r.NFev == 0 || len(r.X) != 1 || r.F > 1e-10 {
+		t.Errorf("expected a cold start, got %+v", r)
+	}
+
+	// Options
+	if _, err := NewLatticeSearchWith(WithWarmStart(res)); err == nil {
+		t.Error("expected an error for LatticeSearch")
+	}
+	opts, err := NewOptions(WithMethod("neldermead"), WithWarmStart(cold))
+	if err != nil || opts.Warm != cold {
+		t.Fatal(err)
+	}
+	if r, err := Minimize(optimize.Problem{Func: gp}, []float64{0, 0}, opts); err != nil || r.Warm != nil || math.Abs(r.F-cold.F) > 1e-6 {
+		t.Errorf("unexpected result %+v, %v", r, err)
+	}
+	if _, err := Minimize(optimize.Problem{Func: g}, []float64{0, 0}, &Options{Method: "spsa", Warm: cold}); err == nil {
+		t.Error("expected an error for the state of powell")
+	}
+}
@@ -0,0 +1,63 @@
+package optimize
+
+import (
+	"sync"
+)
+
+// workspace is a set of buffers reused across runs, so that the repeated
+// short minimizations of a loop, eg a fit per pixel, don't allocate their
+// temporaries. A run takes a workspace with getWorkspace, its buffers with
+// floats and ints, and gives it back with putWorkspace: the
+// buffers must not be used once the workspace is put back, results are
+// copied out.
+type workspace struct {
+	floatBufs [][]float64
+	intBufs   [][]int
+	// nf and ni are the numbers of buffers in use
+	nf, ni int
+}
+
+var workspaces = sync.Pool{New: func() interface{} { return &workspace{} }}
+
+// getWorkspace returns a workspace of the pool
+func getWorkspace() *workspace {
+	return workspaces.Get().(*workspace)
+}
+
+// putWorkspace gives ws back to the pool
+func putWorkspace(ws *workspace) {
+	ws.nf, ws.ni = 0, 0
+	workspaces.Put(ws)
+}
+
+// floats returns a zeroed slice of length n
+func (ws *workspace) floats(n int) []float64 {
+	if ws.nf == len(ws.floatBufs) {
+		ws.floatBufs = append(ws.floatBufs, nil)
+	}
+	x := resize(ws.floatBufs[ws.nf], n)
+	for i := range x {
+		x[i] = 0
+	}
+	ws.floatBufs[ws.nf] = x
+	ws.nf++
+	return x
+}
+
+// ints returns a zeroed slice of length n
+func (ws *workspace) ints(n int) []int {
+	if ws.ni == len(ws.intBufs) {
+		ws.intBufs = append(ws.intBufs, nil)
+	}
+	x := ws.intBufs[ws.ni]
+	if n > cap(x) {
+		x = make([]int, n)
+	}
+	x = x[:n]
+	for i := range x {
+		x[i] = 0
+	}
+	ws.intBufs[ws.ni] = x
+	ws.ni++
+	return x
+}
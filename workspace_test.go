@@ -0,0 +1,41 @@
+package optimize
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestWorkspace(t *testing.T) {
+	ws := &workspace{}
+	x, y := ws.floats(3), ws.floats(2)
+	is := ws.ints(4)
+	if len(x) != 3 || len(y) != 2 || len(is) != 4 || &x[0] == &y[0] {
+		t.Fatalf("unexpected buffers %v %v %v", x, y, is)
+	}
+	x[0], y[1], is[2] = 1, 2, 3
+	ws.nf, ws.ni = 0, 0
+	x2, y2, is2 := ws.floats(2), ws.floats(2), ws.ints(3)
+	if &x2[0] != &x[0] || &y2[0] != &y[0] || &is2[0] != &is[0] {
+		t.Error("expected the buffers to be reused")
+	}
+	if x2[0] != 0 || y2[1] != 0 || is2[2] != 0 {
+		t.Errorf("expected zeroed buffers, got %v %v %v", x2, y2, is2)
+	}
+	if z := ws.floats(10); len(z) != 10 {
+		t.Errorf("unexpected length %d", len(z))
+	}
+
+	// results don't alias the buffers of the pool
+	f := func(x []float64) float64 { return (x[0]-1)*(x[0]-1) + (x[1]+2)*(x[1]+2) }
+	var iterates [][]float64
+	pm := NewPowellMinimizer()
+	pm.Callback = func(x []float64) { iterates = append(iterates, x) }
+	res := pm.Minimize(f, []float64{0, 0})
+	x0 := append([]float64(nil), res.X...)
+	last := append([]float64(nil), iterates[len(iterates)-1]...)
+	NewPowellMinimizer().Minimize(func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] }, []float64{5, 5})
+	if !floats.Equal(x0, res.X) || !floats.Equal(last, iterates[len(iterates)-1]) {
+		t.Errorf("result %v or iterate %v changed by the next run", res.X, iterates[len(iterates)-1])
+	}
+}